@@ -0,0 +1,279 @@
+// Package auth obtains OrderKuota credentials through the username, password, and OTP login flow.
+// Package auth mendapatkan kredensial OrderKuota melalui alur login username, password, dan OTP.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// DefaultBaseURL is the OrderKuota app API used for logging in.
+// DefaultBaseURL adalah API aplikasi OrderKuota yang dipakai untuk login.
+const DefaultBaseURL = "https://app.orderkuota.com/api/v2"
+
+// Login errors. The gateway message is kept in the wrapping error.
+// Error login. Pesan dari gateway disimpan di error pembungkusnya.
+var (
+	ErrWrongPassword    = errors.New("wrong username or password / username atau password salah")
+	ErrOTPExpired       = errors.New("OTP has expired / OTP sudah kedaluwarsa")
+	ErrInvalidOTP       = errors.New("invalid OTP / OTP tidak valid")
+	ErrTooManyAttempts  = errors.New("too many login attempts / terlalu banyak percobaan login")
+	ErrUnexpectedResult = errors.New("unexpected login response / response login tidak terduga")
+)
+
+// Credentials are the long-lived credentials returned after a successful login.
+// Credentials adalah kredensial jangka panjang yang dikembalikan setelah login berhasil.
+type Credentials struct {
+	Username string // Value for QRISConfig.AuthUsername / Nilai untuk QRISConfig.AuthUsername
+	Token    string // Value for QRISConfig.AuthToken / Nilai untuk QRISConfig.AuthToken
+
+	Profile *Profile // Member info, if the login response included it / Info member, jika response login menyertakannya
+}
+
+// Apply copies the credentials into a QRISConfig.
+// Apply menyalin kredensial ke dalam QRISConfig.
+func (c Credentials) Apply(config *qris.QRISConfig) {
+	config.AuthUsername = c.Username
+	config.AuthToken = c.Token
+}
+
+// OTPSession is a pending login waiting for the OTP sent to the user.
+// OTPSession adalah login yang menunggu OTP yang dikirim ke pengguna.
+//
+// The password is not kept; only the username is needed to verify the OTP.
+// Password tidak disimpan; hanya username yang dibutuhkan untuk verifikasi OTP.
+type OTPSession struct {
+	Username    string // Username that requested the OTP / Username yang meminta OTP
+	Channel     string // Where the OTP was sent, e.g. "email" / Tujuan pengiriman OTP, misalnya "email"
+	Destination string // Masked destination, e.g. "a***@gmail.com" / Tujuan yang disamarkan, misalnya "a***@gmail.com"
+
+	opts options
+}
+
+// Option configures the login requests.
+// Option mengatur request login.
+type Option func(*options)
+
+type options struct {
+	baseURL string
+	client  *http.Client
+}
+
+func defaultOptions() options {
+	return options{
+		baseURL: DefaultBaseURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithBaseURL overrides DefaultBaseURL, for example to point at a test server.
+// WithBaseURL mengganti DefaultBaseURL, misalnya untuk mengarah ke server tes.
+func WithBaseURL(url string) Option {
+	return func(o *options) {
+		if url != "" {
+			o.baseURL = strings.TrimRight(url, "/")
+		}
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used for the login requests.
+// WithHTTPClient mengganti HTTP client yang dipakai untuk request login.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// RequestOTP starts a login and asks OrderKuota to send an OTP to the user.
+// RequestOTP memulai login dan meminta OrderKuota mengirim OTP ke pengguna.
+func RequestOTP(ctx context.Context, username, password string, opts ...Option) (*OTPSession, error) {
+	if username == "" || password == "" {
+		return nil, errors.New("username and password must be filled / username dan password harus diisi")
+	}
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var results struct {
+		OTP      string `json:"otp"`
+		OTPValue string `json:"otp_value"`
+	}
+	if err := login(ctx, o, username, password, &results); err != nil {
+		return nil, err
+	}
+	if results.OTP == "" {
+		return nil, fmt.Errorf("%w: no OTP requested / OTP tidak diminta", ErrUnexpectedResult)
+	}
+
+	return &OTPSession{
+		Username:    username,
+		Channel:     results.OTP,
+		Destination: results.OTPValue,
+		opts:        o,
+	}, nil
+}
+
+// OTPFunc supplies the OTP sent for session, for example by asking an operator.
+// OTPFunc menyediakan OTP yang dikirim untuk session, misalnya dengan menanyakan ke operator.
+type OTPFunc func(ctx context.Context, session *OTPSession) (string, error)
+
+// Login runs the whole login flow. otp is only called when the gateway demands an OTP;
+// without it such a login fails.
+// Login menjalankan seluruh alur login. otp hanya dipanggil jika gateway meminta OTP;
+// tanpanya login tersebut gagal.
+func Login(ctx context.Context, username, password string, otp OTPFunc, opts ...Option) (*Credentials, error) {
+	if username == "" || password == "" {
+		return nil, errors.New("username and password must be filled / username dan password harus diisi")
+	}
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var results struct {
+		OTP      string   `json:"otp"`
+		OTPValue string   `json:"otp_value"`
+		Token    string   `json:"token"`
+		Username string   `json:"username"`
+		Profile  *Profile `json:"profile"`
+	}
+	if err := login(ctx, o, username, password, &results); err != nil {
+		return nil, err
+	}
+
+	// Trusted devices may receive a token without an OTP
+	if results.Token != "" {
+		if results.Username == "" {
+			results.Username = username
+		}
+		return &Credentials{Username: results.Username, Token: results.Token, Profile: results.Profile}, nil
+	}
+	if results.OTP == "" {
+		return nil, fmt.Errorf("%w: neither token nor OTP returned / token maupun OTP tidak dikembalikan", ErrUnexpectedResult)
+	}
+	if otp == nil {
+		return nil, errors.New("login requires an OTP but no OTP callback was given / login membutuhkan OTP tetapi callback OTP tidak diberikan")
+	}
+
+	session := &OTPSession{Username: username, Channel: results.OTP, Destination: results.OTPValue, opts: o}
+	code, err := otp(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OTP / gagal mendapatkan OTP: %w", err)
+	}
+	return session.Verify(ctx, code)
+}
+
+// Verify exchanges the OTP for long-lived credentials.
+// Verify menukar OTP dengan kredensial jangka panjang.
+func (s *OTPSession) Verify(ctx context.Context, otp string) (*Credentials, error) {
+	otp = strings.TrimSpace(otp)
+	if otp == "" {
+		return nil, errors.New("otp must be filled / otp harus diisi")
+	}
+
+	// The gateway expects the OTP in place of the password on the second call
+	var results struct {
+		Token    string   `json:"token"`
+		Username string   `json:"username"`
+		Profile  *Profile `json:"profile"`
+	}
+	if err := login(ctx, s.opts, s.Username, otp, &results); err != nil {
+		return nil, err
+	}
+	if results.Token == "" {
+		return nil, fmt.Errorf("%w: no token returned / token tidak dikembalikan", ErrUnexpectedResult)
+	}
+
+	username := results.Username
+	if username == "" {
+		username = s.Username
+	}
+	return &Credentials{Username: username, Token: results.Token, Profile: results.Profile}, nil
+}
+
+// login posts to the login endpoint and decodes the results of a successful response into out.
+// login mengirim ke endpoint login dan men-decode results dari response yang berhasil ke out.
+func login(ctx context.Context, o options, username, secret string, out interface{}) error {
+	jsonBody, err := json.Marshal(map[string]string{
+		"username": username,
+		"password": secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body / gagal marshal request body: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/login", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return fmt.Errorf("failed to create request / gagal membuat request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		// The URL never contains the password, so the error is safe to return
+		return fmt.Errorf("failed to send request / gagal mengirim request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return ErrTooManyAttempts
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response / gagal membaca response: %v", err)
+	}
+
+	var response struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected gateway status %d / status gateway tidak terduga %d", resp.StatusCode, resp.StatusCode)
+		}
+		return fmt.Errorf("failed to parse response / gagal parse response: %v", err)
+	}
+
+	if !response.Success {
+		return classify(response.Message)
+	}
+	if err := json.Unmarshal(response.Results, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnexpectedResult, err)
+	}
+	return nil
+}
+
+// classify maps a gateway failure message to one of the typed login errors.
+// classify memetakan pesan kegagalan gateway ke salah satu error login bertipe.
+func classify(message string) error {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "terlalu banyak") || strings.Contains(m, "too many") || strings.Contains(m, "coba lagi nanti"):
+		return fmt.Errorf("%w: %s", ErrTooManyAttempts, message)
+	case strings.Contains(m, "otp") && (strings.Contains(m, "kadaluarsa") || strings.Contains(m, "kedaluwarsa") || strings.Contains(m, "expired")):
+		return fmt.Errorf("%w: %s", ErrOTPExpired, message)
+	case strings.Contains(m, "otp"):
+		return fmt.Errorf("%w: %s", ErrInvalidOTP, message)
+	case strings.Contains(m, "password") || strings.Contains(m, "username"):
+		return fmt.Errorf("%w: %s", ErrWrongPassword, message)
+	}
+	if message == "" {
+		message = "login failed / login gagal"
+	}
+	return fmt.Errorf("%w: %s", ErrUnexpectedResult, message)
+}
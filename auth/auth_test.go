@@ -0,0 +1,255 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// loginAnswer is the response of the fake login endpoint to one password.
+type loginAnswer struct {
+	status int    // 200 if zero / 200 jika nol
+	body   string // response body / body response
+}
+
+// loginServer is a fake OrderKuota login endpoint answering by password.
+type loginServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	answers  map[string]loginAnswer
+	requests []map[string]string
+}
+
+func newLoginServer(t *testing.T, answers map[string]loginAnswer) *loginServer {
+	t.Helper()
+	s := &loginServer{answers: answers}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if r.Method != http.MethodPost || r.URL.Path != "/login" || r.Header.Get("Content-Type") != "application/json" ||
+			json.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, body)
+		answer, ok := s.answers[body["password"]]
+		s.mu.Unlock()
+		if !ok {
+			answer = loginAnswer{body: `{"success":false,"message":"Password salah"}`}
+		}
+		if answer.status != 0 {
+			w.WriteHeader(answer.status)
+		}
+		w.Write([]byte(answer.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *loginServer) received() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]string(nil), s.requests...)
+}
+
+// Answers of the login endpoint.
+const (
+	otpSent   = `{"success":true,"results":{"otp":"email","otp_value":"a***@gmail.com"}}`
+	tokenSent = `{"success":true,"results":{"token":"1234:secret-token","username":"merchant",` +
+		`"profile":{"id":1234,"name":"Toko A","level":"gold","registered_at":"2024-01-15 10:00:00"}}}`
+)
+
+func TestRequestOTPAndVerify(t *testing.T) {
+	srv := newLoginServer(t, map[string]loginAnswer{"secret": {body: otpSent}, "123456": {body: tokenSent}})
+	ctx := context.Background()
+
+	session, err := auth.RequestOTP(ctx, "merchant", "secret", auth.WithBaseURL(srv.URL+"/"))
+	if err != nil {
+		t.Fatalf("RequestOTP: %v", err)
+	}
+	if session.Username != "merchant" || session.Channel != "email" || session.Destination != "a***@gmail.com" {
+		t.Fatalf("session = %+v", session)
+	}
+
+	creds, err := session.Verify(ctx, " 123456\n")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if creds.Username != "merchant" || creds.Token != "1234:secret-token" || creds.Profile == nil ||
+		creds.Profile.MemberID != "1234" || !creds.Profile.RegisteredAt.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, qris.WIB)) {
+		t.Fatalf("credentials = %+v, profile %+v", creds, creds.Profile)
+	}
+
+	// The OTP replaces the password on the second call
+	want := []map[string]string{{"username": "merchant", "password": "secret"}, {"username": "merchant", "password": "123456"}}
+	if got := srv.received(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+
+	if _, err := session.Verify(ctx, "  "); err == nil {
+		t.Fatal("Verify of an empty OTP succeeded")
+	}
+	if n := len(srv.received()); n != 2 {
+		t.Fatalf("%d requests sent, want 2", n)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	var errOperator = errors.New("operator gone")
+	tests := []struct {
+		name      string
+		answers   map[string]loginAnswer
+		otp       auth.OTPFunc
+		wantToken string
+		wantErr   error // nil with wantFail for an untyped error / nil dengan wantFail untuk error tanpa tipe
+		wantFail  bool
+		calls     int // calls of otp / panggilan otp
+	}{
+		{
+			name:      "trusted device",
+			answers:   map[string]loginAnswer{"secret": {body: tokenSent}},
+			otp:       func(context.Context, *auth.OTPSession) (string, error) { return "123456", nil },
+			wantToken: "1234:secret-token",
+		},
+		{
+			name:      "OTP",
+			answers:   map[string]loginAnswer{"secret": {body: otpSent}, "123456": {body: tokenSent}},
+			otp:       func(context.Context, *auth.OTPSession) (string, error) { return "123456", nil },
+			wantToken: "1234:secret-token",
+			calls:     1,
+		},
+		{
+			name:     "OTP without a callback",
+			answers:  map[string]loginAnswer{"secret": {body: otpSent}},
+			wantFail: true,
+		},
+		{
+			name:    "callback failed",
+			answers: map[string]loginAnswer{"secret": {body: otpSent}},
+			otp:     func(context.Context, *auth.OTPSession) (string, error) { return "", errOperator },
+			wantErr: errOperator,
+			calls:   1,
+		},
+		{
+			name:    "wrong OTP",
+			answers: map[string]loginAnswer{"secret": {body: otpSent}, "000000": {body: `{"success":false,"message":"Kode OTP salah"}`}},
+			otp:     func(context.Context, *auth.OTPSession) (string, error) { return "000000", nil },
+			wantErr: auth.ErrInvalidOTP,
+			calls:   1,
+		},
+		{
+			name:    "neither token nor OTP",
+			answers: map[string]loginAnswer{"secret": {body: `{"success":true,"results":{}}`}},
+			wantErr: auth.ErrUnexpectedResult,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newLoginServer(t, tt.answers)
+			calls := 0
+			otp := tt.otp
+			if otp != nil {
+				otp = func(ctx context.Context, s *auth.OTPSession) (string, error) {
+					calls++
+					if s.Username != "merchant" || s.Channel != "email" {
+						t.Errorf("OTP session = %+v", s)
+					}
+					return tt.otp(ctx, s)
+				}
+			}
+
+			creds, err := auth.Login(context.Background(), "merchant", "secret", otp, auth.WithBaseURL(srv.URL))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Login err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantFail:
+				if err == nil {
+					t.Fatalf("Login = %+v, want an error", creds)
+				}
+			default:
+				if err != nil || creds.Token != tt.wantToken || creds.Username != "merchant" {
+					t.Fatalf("Login = %+v, %v, want token %s", creds, err, tt.wantToken)
+				}
+			}
+			if calls != tt.calls {
+				t.Fatalf("OTP callback called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+
+	if _, err := auth.Login(context.Background(), "merchant", "", nil); err == nil {
+		t.Fatal("Login without a password succeeded")
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	sentinels := []error{auth.ErrWrongPassword, auth.ErrOTPExpired, auth.ErrInvalidOTP, auth.ErrTooManyAttempts, auth.ErrUnexpectedResult}
+	tests := []struct {
+		name    string
+		answer  loginAnswer
+		wantErr error // nil for an error that is none of the sentinels / nil untuk error yang bukan sentinel mana pun
+	}{
+		{"wrong password", loginAnswer{body: `{"success":false,"message":"Password salah"}`}, auth.ErrWrongPassword},
+		{"unknown username", loginAnswer{body: `{"success":false,"message":"Username tidak terdaftar"}`}, auth.ErrWrongPassword},
+		{"OTP expired", loginAnswer{body: `{"success":false,"message":"Kode OTP sudah kadaluarsa"}`}, auth.ErrOTPExpired},
+		{"OTP expired in English", loginAnswer{body: `{"success":false,"message":"OTP expired"}`}, auth.ErrOTPExpired},
+		{"invalid OTP", loginAnswer{body: `{"success":false,"message":"OTP tidak sesuai"}`}, auth.ErrInvalidOTP},
+		{"too many attempts", loginAnswer{body: `{"success":false,"message":"Terlalu banyak percobaan login"}`}, auth.ErrTooManyAttempts},
+		{"try again later", loginAnswer{body: `{"success":false,"message":"Silakan coba lagi nanti"}`}, auth.ErrTooManyAttempts},
+		{"HTTP 429", loginAnswer{status: http.StatusTooManyRequests, body: "slow down"}, auth.ErrTooManyAttempts},
+		{"unknown message", loginAnswer{body: `{"success":false,"message":"Server sedang maintenance"}`}, auth.ErrUnexpectedResult},
+		{"no message", loginAnswer{body: `{"success":false}`}, auth.ErrUnexpectedResult},
+		{"results not an object", loginAnswer{body: `{"success":true,"results":"ok"}`}, auth.ErrUnexpectedResult},
+		{"HTTP 502", loginAnswer{status: http.StatusBadGateway, body: "<html>Bad Gateway</html>"}, nil},
+		{"not JSON", loginAnswer{body: "<html>ok</html>"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newLoginServer(t, map[string]loginAnswer{"secret": tt.answer, "123456": tt.answer})
+			ctx := context.Background()
+
+			_, reqErr := auth.RequestOTP(ctx, "merchant", "secret", auth.WithBaseURL(srv.URL))
+			_, loginErr := auth.Login(ctx, "merchant", "secret", nil, auth.WithBaseURL(srv.URL))
+			for call, err := range map[string]error{"RequestOTP": reqErr, "Login": loginErr} {
+				if err == nil {
+					t.Fatalf("%s succeeded", call)
+				}
+				for _, sentinel := range sentinels {
+					if is := errors.Is(err, sentinel); is != (sentinel == tt.wantErr) {
+						t.Fatalf("%s err = %v, errors.Is(%v) = %v", call, err, sentinel, is)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	srv := newLoginServer(t, map[string]loginAnswer{
+		"secret": {body: otpSent},
+		"111111": {body: `{"success":false,"message":"OTP sudah expired"}`},
+		"222222": {body: `{"success":false,"message":"OTP salah"}`},
+		"333333": {body: `{"success":true,"results":{"username":"merchant"}}`},
+	})
+	session, err := auth.RequestOTP(context.Background(), "merchant", "secret", auth.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for otp, want := range map[string]error{"111111": auth.ErrOTPExpired, "222222": auth.ErrInvalidOTP, "333333": auth.ErrUnexpectedResult} {
+		if _, err := session.Verify(context.Background(), otp); !errors.Is(err, want) {
+			t.Errorf("Verify(%s) err = %v, want %v", otp, err, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/sealed"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// SaveCredentialsFile writes creds to path, encrypted with the first key of keyring (see qris.Keyring).
+// A nil keyring writes plaintext JSON. The file is replaced atomically and readable by the owner only.
+// SaveCredentialsFile menulis creds ke path, dienkripsi dengan kunci pertama dari keyring (lihat qris.Keyring).
+// Keyring nil menulis JSON plaintext. File diganti secara atomik dan hanya dapat dibaca pemiliknya.
+func SaveCredentialsFile(path string, creds Credentials, keyring qris.Keyring) error {
+	keys, err := sealed.KeysOf(keyring)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials / gagal marshal kredensial: %v", err)
+	}
+	if data, err = sealed.Encode(keys, data); err != nil {
+		return fmt.Errorf("failed to encrypt credentials / gagal mengenkripsi kredensial: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials / gagal menulis kredensial: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials / gagal menulis kredensial: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials / gagal menulis kredensial: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write credentials / gagal menulis kredensial: %v", err)
+	}
+	return nil
+}
+
+// LoadCredentialsFile reads credentials written by SaveCredentialsFile. Plaintext files load
+// without a key; encrypted ones fail with qris.ErrDecryptionFailed when no key of keyring opens them.
+// LoadCredentialsFile membaca kredensial yang ditulis SaveCredentialsFile. File plaintext dimuat
+// tanpa kunci; file terenkripsi gagal dengan qris.ErrDecryptionFailed jika tidak ada kunci keyring yang dapat membukanya.
+func LoadCredentialsFile(path string, keyring qris.Keyring) (*Credentials, error) {
+	keys, err := sealed.KeysOf(keyring)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials / gagal membaca kredensial: %v", err)
+	}
+	if data, err = sealed.Decode(keys, data); err != nil {
+		return nil, fmt.Errorf("failed to open credentials / gagal membuka kredensial: %w", err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials / gagal parse kredensial: %v", err)
+	}
+	return &creds, nil
+}
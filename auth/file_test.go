@@ -0,0 +1,81 @@
+package auth_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestCredentialsFile(t *testing.T) {
+	key1 := qris.StaticKeyring{bytes.Repeat([]byte{1}, qris.EncryptionKeySize)}
+	key2 := qris.StaticKeyring{bytes.Repeat([]byte{2}, qris.EncryptionKeySize)}
+	rotated := qris.StaticKeyring{key2[0], key1[0]}
+
+	tests := []struct {
+		name      string
+		save      qris.Keyring
+		load      qris.Keyring
+		encrypted bool
+		wantErr   error
+	}{
+		{name: "plaintext"},
+		{name: "plaintext loaded with a key", load: key1},
+		{name: "encrypted", save: key1, load: key1, encrypted: true},
+		{name: "old key in the keyring", save: key1, load: rotated, encrypted: true},
+		{name: "wrong key", save: key1, load: key2, encrypted: true, wantErr: qris.ErrDecryptionFailed},
+		{name: "no key", save: key1, encrypted: true, wantErr: qris.ErrDecryptionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials.json")
+			creds := auth.Credentials{Username: "merchant", Token: "1234:secret-token"}
+			if err := auth.SaveCredentialsFile(path, creds, tt.save); err != nil {
+				t.Fatalf("SaveCredentialsFile: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if leaked := bytes.Contains(data, []byte("secret-token")); leaked == tt.encrypted {
+				t.Fatalf("file holds the plaintext token = %v, want %v", leaked, !tt.encrypted)
+			}
+			if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0o077 != 0 {
+				t.Fatalf("file mode = %v, %v, want owner-only", info.Mode(), err)
+			}
+
+			got, err := auth.LoadCredentialsFile(path, tt.load)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("LoadCredentialsFile err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCredentialsFile: %v", err)
+			}
+			if got.Username != creds.Username || got.Token != creds.Token {
+				t.Fatalf("loaded %+v, want %+v", got, creds)
+			}
+		})
+	}
+}
+
+func TestCredentialsFileInvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	short := qris.StaticKeyring{make([]byte, 16)}
+	if err := auth.SaveCredentialsFile(path, auth.Credentials{Token: "t"}, short); err == nil {
+		t.Fatal("SaveCredentialsFile with a 16-byte key succeeded")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stat = %v, want no file written", err)
+	}
+	if _, err := auth.LoadCredentialsFile(path, short); err == nil || errors.Is(err, qris.ErrDecryptionFailed) {
+		t.Fatalf("LoadCredentialsFile with a 16-byte key = %v, want a key size error", err)
+	}
+}
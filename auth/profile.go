@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// profileDateLayout is the layout of the registration date.
+// profileDateLayout adalah format tanggal registrasi.
+const profileDateLayout = "2006-01-02 15:04:05"
+
+// Profile is the member information of an OrderKuota account.
+// Profile adalah informasi member dari akun OrderKuota.
+type Profile struct {
+	MemberID     string    // Member ID / ID member
+	Name         string    // Account name / Nama akun
+	Phone        string    // Registered phone number / Nomor HP terdaftar
+	Email        string    // Registered email / Email terdaftar
+	Level        string    // Membership level / Level keanggotaan
+	RegisteredAt time.Time // Registration time in WIB, zero if unknown / Waktu registrasi dalam WIB, nol jika tidak diketahui
+}
+
+// UnmarshalJSON decodes the gateway's profile object, where IDs may be numbers and dates use WIB.
+// UnmarshalJSON men-decode objek profil dari gateway, di mana ID bisa berupa angka dan tanggal memakai WIB.
+func (p *Profile) UnmarshalJSON(b []byte) error {
+	var row struct {
+		ID           json.RawMessage `json:"id"`
+		Name         string          `json:"name"`
+		Phone        string          `json:"phone"`
+		Email        string          `json:"email"`
+		Level        string          `json:"level"`
+		RegisteredAt string          `json:"registered_at"`
+	}
+	if err := json.Unmarshal(b, &row); err != nil {
+		return err
+	}
+
+	id := strings.TrimSpace(string(row.ID))
+	if strings.HasPrefix(id, `"`) {
+		if err := json.Unmarshal(row.ID, &id); err != nil {
+			return err
+		}
+	}
+	if id == "null" {
+		id = ""
+	}
+
+	registeredAt, _ := time.ParseInLocation(profileDateLayout, row.RegisteredAt, qris.WIB)
+	*p = Profile{
+		MemberID:     id,
+		Name:         row.Name,
+		Phone:        row.Phone,
+		Email:        row.Email,
+		Level:        row.Level,
+		RegisteredAt: registeredAt,
+	}
+	return nil
+}
@@ -0,0 +1,497 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// configFlags are the connection flags shared by the commands that need a QRIS instance.
+// configFlags adalah flag koneksi yang dipakai bersama oleh perintah yang membutuhkan instance QRIS.
+type configFlags struct {
+	baseQR     string
+	baseQRFile string
+	token      string
+	username   string
+}
+
+func (c *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.baseQR, "base-qr", "", "base QRIS string (default $QRIS_BASE_QR_STRING)")
+	fs.StringVar(&c.baseQRFile, "base-qr-file", "", "file containing the base QRIS string")
+	fs.StringVar(&c.token, "token", "", "auth token (default $QRIS_AUTH_TOKEN)")
+	fs.StringVar(&c.username, "username", "", "auth username (default $QRIS_AUTH_USERNAME)")
+}
+
+// newQRIS builds a QRIS instance from the environment, with flags taking precedence.
+// newQRIS membuat instance QRIS dari environment, dengan flag sebagai prioritas.
+func (c *configFlags) newQRIS() (*qris.QRIS, error) {
+	baseQR := c.baseQR
+	if c.baseQRFile != "" {
+		b, err := os.ReadFile(c.baseQRFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read base QR file / gagal membaca file base QR: %v", err)
+		}
+		baseQR = strings.TrimSpace(string(b))
+	}
+
+	// Flags override the environment so ConfigFromEnv still parses and validates everything
+	overrides := map[string]string{
+		"BASE_QR_STRING": baseQR,
+		"AUTH_TOKEN":     c.token,
+		"AUTH_USERNAME":  c.username,
+	}
+	for name, value := range overrides {
+		if value != "" {
+			os.Setenv(envPrefix+"_"+name, value)
+		}
+	}
+
+	config, err := qris.ConfigFromEnv(envPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return qris.NewQRIS(config)
+}
+
+// outputFlags are the output flags shared by every command.
+// outputFlags adalah flag output yang dipakai bersama oleh semua perintah.
+type outputFlags struct {
+	json    bool
+	verbose bool
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.json, "json", false, "print machine-readable JSON")
+	fs.BoolVar(&o.verbose, "v", false, "print library logs to stderr")
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("qris "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses args and reports whether the command should continue.
+// parseFlags mem-parse args dan melaporkan apakah perintah harus dilanjutkan.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitError, false
+	}
+	return exitOK, true
+}
+
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "error: %v\n", err)
+	return exitError
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// readPayload reads a payload from the file named by the first argument, or stdin if absent or "-".
+// readPayload membaca payload dari file pada argumen pertama, atau stdin jika kosong atau "-".
+func readPayload(args []string) (string, error) {
+	var b []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read payload / gagal membaca payload: %v", err)
+	}
+
+	payload := strings.TrimSpace(string(b))
+	if payload == "" {
+		return "", errors.New("empty payload / payload kosong")
+	}
+	return payload, nil
+}
+
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("generate", stderr)
+	var cf configFlags
+	var of outputFlags
+	cf.register(fs)
+	of.register(fs)
+	var amount idrValue
+	fs.Var(&amount, "amount", "payment `amount` in rupiah such as 150000, 150k or 1,5jt (required)")
+	id := fs.String("id", "", "transaction ID (default generated from the current time)")
+	out := fs.String("out", "qris.png", "PNG output file, empty to skip")
+	size := fs.Int("size", 256, "PNG size in pixels")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+
+	q, err := cf.newQRIS()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	data := qris.QRISData{Amount: int64(amount), TransactionID: *id}
+	if data.TransactionID == "" {
+		data.TransactionID = fmt.Sprintf("CLI%d", time.Now().Unix())
+	}
+
+	qrCode, err := q.GenerateQRCode(data)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if *out != "" {
+		if err := qrCode.WriteFile(*size, *out); err != nil {
+			return fail(stderr, fmt.Errorf("failed to save QR code / gagal menyimpan QR code: %v", err))
+		}
+	}
+
+	if of.json {
+		if err := writeJSON(stdout, map[string]interface{}{
+			"transaction_id": data.TransactionID,
+			"amount":         data.Amount,
+			"qr_string":      qrCode.Content,
+			"file":           *out,
+		}); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+
+	fmt.Fprintf(stdout, "Transaction ID: %s\n", data.TransactionID)
+	fmt.Fprintf(stdout, "Amount:         %s\n", qris.FormatIDR(data.Amount))
+	fmt.Fprintf(stdout, "QRIS string:    %s\n", qrCode.Content)
+	if *out != "" {
+		fmt.Fprintf(stdout, "Saved to:       %s\n", *out)
+	}
+	return exitOK
+}
+
+// jsonField is the JSON form of qris.QRISField printed by parse.
+// jsonField adalah bentuk JSON dari qris.QRISField yang dicetak oleh parse.
+type jsonField struct {
+	Tag       string      `json:"tag"`
+	Length    int         `json:"length"`
+	Value     string      `json:"value"`
+	Offset    int         `json:"offset"`
+	SubFields []jsonField `json:"sub_fields,omitempty"`
+}
+
+func toJSONFields(fields []qris.QRISField) []jsonField {
+	var out []jsonField
+	for _, f := range fields {
+		out = append(out, jsonField{
+			Tag:       f.Tag,
+			Length:    f.Length,
+			Value:     f.Value,
+			Offset:    f.Offset,
+			SubFields: toJSONFields(f.SubFields),
+		})
+	}
+	return out
+}
+
+func runParse(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("parse", stderr)
+	var of outputFlags
+	of.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+
+	payload, err := readPayload(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+	parsed, err := qris.ParseQRISString(payload)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if of.json {
+		if err := writeJSON(stdout, map[string]interface{}{"fields": toJSONFields(parsed.Fields)}); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+	parsed.Dump(stdout)
+	return exitOK
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("validate", stderr)
+	var of outputFlags
+	of.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+
+	payload, err := readPayload(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	// Validation does not depend on credentials, so a sandbox instance is enough
+	q, err := qris.NewQRIS(qris.QRISConfig{BaseQrString: payload, Sandbox: true})
+	if err == nil {
+		err = q.ValidateQRISString(payload)
+	}
+
+	if of.json {
+		result := map[string]interface{}{"valid": err == nil}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		if werr := writeJSON(stdout, result); werr != nil {
+			return fail(stderr, werr)
+		}
+		if err != nil {
+			return exitError
+		}
+		return exitOK
+	}
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+	fmt.Fprintln(stdout, "valid")
+	return exitOK
+}
+
+// idrValue is an amount flag accepting the formats of qris.ParseIDR.
+// idrValue adalah flag nominal yang menerima format qris.ParseIDR.
+type idrValue int64
+
+func (v *idrValue) String() string {
+	return strconv.FormatInt(int64(*v), 10)
+}
+
+func (v *idrValue) Set(s string) error {
+	n, err := qris.ParseIDR(s)
+	if err != nil {
+		return err
+	}
+	*v = idrValue(n)
+	return nil
+}
+
+// statusFlags are the flags shared by check and watch.
+// statusFlags adalah flag yang dipakai bersama oleh check dan watch.
+type statusFlags struct {
+	amount    idrValue
+	reference string
+}
+
+func (s *statusFlags) register(fs *flag.FlagSet) {
+	fs.Var(&s.amount, "amount", "expected payment `amount` in rupiah such as 150000 or 150k (required)")
+	fs.StringVar(&s.reference, "ref", "CLI", "reference reported in logs")
+}
+
+func (s *statusFlags) validate() error {
+	if s.amount <= 0 {
+		return errors.New("--amount must be greater than 0 / --amount harus lebih besar dari 0")
+	}
+	return nil
+}
+
+func printStatus(w io.Writer, status *qris.PaymentStatus, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, status)
+	}
+
+	fmt.Fprintf(w, "Status:    %s\n", status.Status)
+	fmt.Fprintf(w, "Amount:    %s\n", qris.FormatIDR(status.Amount))
+	if status.Status == qris.StatusPaid {
+		fmt.Fprintf(w, "Reference: %s\n", status.Reference)
+		fmt.Fprintf(w, "Date:      %s\n", status.Date)
+		if !status.PaidAt.IsZero() {
+			fmt.Fprintf(w, "Paid At:   %s\n", status.PaidAt.Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "Brand:     %s\n", status.BrandName)
+		fmt.Fprintf(w, "Issuer:    %s\n", status.Issuer)
+		fmt.Fprintf(w, "Buyer Ref: %s\n", status.BuyerRef)
+	}
+	return nil
+}
+
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("check", stderr)
+	var cf configFlags
+	var of outputFlags
+	var sf statusFlags
+	cf.register(fs)
+	of.register(fs)
+	sf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+	if err := sf.validate(); err != nil {
+		return fail(stderr, err)
+	}
+
+	q, err := cf.newQRIS()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	status, err := q.CheckPaymentStatusContext(context.Background(), sf.reference, int64(sf.amount))
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if err := printStatus(stdout, status, of.json); err != nil {
+		return fail(stderr, err)
+	}
+	if status.Status != qris.StatusPaid {
+		return exitUnpaid
+	}
+	return exitOK
+}
+
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("watch", stderr)
+	var cf configFlags
+	var of outputFlags
+	var sf statusFlags
+	cf.register(fs)
+	of.register(fs)
+	sf.register(fs)
+	timeout := fs.Duration("timeout", 10*time.Minute, "give up after this long")
+	interval := fs.Duration("interval", qris.DefaultPollInterval, "time between checks")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+	if err := sf.validate(); err != nil {
+		return fail(stderr, err)
+	}
+
+	q, err := cf.newQRIS()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if !of.json {
+		fmt.Fprintf(stderr, "Waiting for a payment of %s (timeout %s)...\n", qris.FormatIDR(int64(sf.amount)), *timeout)
+	}
+	status, err := q.WaitForPayment(ctx, sf.reference, int64(sf.amount), qris.WithPollInterval(*interval))
+	if errors.Is(err, context.DeadlineExceeded) {
+		if of.json {
+			if werr := writeJSON(stdout, &qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: int64(sf.amount), Reference: sf.reference}); werr != nil {
+				return fail(stderr, werr)
+			}
+		} else {
+			fmt.Fprintln(stderr, "timeout: no payment received / tidak ada pembayaran yang diterima")
+		}
+		return exitUnpaid
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if err := printStatus(stdout, status, of.json); err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
+
+func runFind(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("find", stderr)
+	var cf configFlags
+	var of outputFlags
+	cf.register(fs)
+	of.register(fs)
+	ref := fs.String("ref", "", "issuer or buyer `reference` from the customer's app (required)")
+	lookback := fs.Duration("lookback", qris.DefaultLookback, "how far back to search")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+	if strings.TrimSpace(*ref) == "" {
+		return fail(stderr, errors.New("--ref must be filled / --ref harus diisi"))
+	}
+
+	q, err := cf.newQRIS()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	status, err := q.FindPaymentByReference(context.Background(), *ref, *lookback)
+	if errors.Is(err, qris.ErrNotFound) {
+		if of.json {
+			if werr := writeJSON(stdout, &qris.PaymentStatus{Status: qris.StatusUnpaid, Reference: *ref}); werr != nil {
+				return fail(stderr, werr)
+			}
+		} else {
+			fmt.Fprintf(stderr, "not found: no payment with reference %s in the last %s / tidak ada pembayaran dengan referensi %s dalam %s terakhir\n", *ref, *lookback, *ref, *lookback)
+		}
+		return exitUnpaid
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if err := printStatus(stdout, status, of.json); err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
+
+func runSummary(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("summary", stderr)
+	var cf configFlags
+	var of outputFlags
+	cf.register(fs)
+	of.register(fs)
+	day := fs.String("day", "", "`day` as YYYY-MM-DD in the configured timezone (default today)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+
+	q, err := cf.newQRIS()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	t := time.Now()
+	if *day != "" {
+		if t, err = time.Parse("2006-01-02", *day); err != nil {
+			return fail(stderr, fmt.Errorf("invalid --day %q, want YYYY-MM-DD / --day %q tidak valid, seharusnya YYYY-MM-DD", *day, *day))
+		}
+		// Keep the calendar date whatever the configured timezone
+		t = t.Add(12 * time.Hour)
+	}
+
+	summary, err := q.DailySummary(context.Background(), t)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if of.json {
+		if err := writeJSON(stdout, summary); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+	fmt.Fprint(stdout, summary)
+	return exitOK
+}
@@ -0,0 +1,106 @@
+// Command qris generates, inspects, and checks QRIS payments from the command line.
+// Command qris membuat, memeriksa, dan mengecek pembayaran QRIS dari command line.
+//
+// Usage / Penggunaan:
+//
+//	qris generate --amount 150000 --base-qr-file base.txt --out qris.png
+//	qris parse payload.txt
+//	qris validate payload.txt
+//	qris check --amount 150000
+//	qris watch --amount 150000 --timeout 10m
+//	qris find --ref 1234567890 --lookback 48h
+//	qris summary --day 2024-05-01
+//	qris webhooks retry --store deliveries.json --since 1h
+//
+// Credentials are read from QRIS_BASE_QR_STRING, QRIS_AUTH_TOKEN, and QRIS_AUTH_USERNAME
+// (see qris.ConfigFromEnv) and can be overridden with flags.
+// Kredensial dibaca dari QRIS_BASE_QR_STRING, QRIS_AUTH_TOKEN, dan QRIS_AUTH_USERNAME
+// (lihat qris.ConfigFromEnv) dan dapat ditimpa dengan flag.
+//
+// Exit codes / Kode keluar:
+//
+//	0  success, or PAID for check and watch / sukses, atau PAID untuk check dan watch
+//	1  error / error
+//	2  UNPAID for check, timeout for watch, not found for find / UNPAID untuk check, timeout untuk watch, tidak ditemukan untuk find
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Stable exit codes for scripting.
+// Kode keluar yang stabil untuk scripting.
+const (
+	exitOK     = 0
+	exitError  = 1
+	exitUnpaid = 2
+)
+
+// envPrefix is the prefix passed to qris.ConfigFromEnv.
+// envPrefix adalah prefix yang diberikan ke qris.ConfigFromEnv.
+const envPrefix = "QRIS"
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"generate", "generate a QRIS payload and PNG / generate payload QRIS dan PNG", runGenerate},
+	{"parse", "pretty-print the TLV tree of a payload / tampilkan struktur TLV payload", runParse},
+	{"validate", "validate a payload / validasi payload", runValidate},
+	{"check", "check the payment status once / cek status pembayaran sekali", runCheck},
+	{"watch", "poll until PAID or timeout / polling sampai PAID atau timeout", runWatch},
+	{"find", "find a payment by issuer or buyer reference / cari pembayaran berdasarkan referensi issuer atau pembeli", runFind},
+	{"summary", "daily settlement summary / ringkasan settlement harian", runSummary},
+	{"webhooks", "list or retry failed webhook deliveries / tampilkan atau ulangi pengiriman webhook yang gagal", runWebhooks},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitError
+		}
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q / perintah %q tidak dikenal\n\n", args[0], args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: qris <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'qris <command> -h' for the flags of a command.")
+}
+
+// setupLogging hides the library's progress logs unless verbose is set.
+// setupLogging menyembunyikan log proses dari library kecuali verbose diaktifkan.
+func setupLogging(verbose bool, stderr io.Writer) {
+	if verbose {
+		log.SetOutput(stderr)
+		return
+	}
+	log.SetOutput(io.Discard)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// runWebhooks runs "qris webhooks list" and "qris webhooks retry" on a qris.JSONDeliveryStore file.
+// runWebhooks menjalankan "qris webhooks list" dan "qris webhooks retry" pada file qris.JSONDeliveryStore.
+func runWebhooks(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "retry") {
+		fmt.Fprintln(stderr, "Usage: qris webhooks list|retry --store deliveries.json [flags]")
+		return exitError
+	}
+	sub := args[0]
+
+	fs := newFlagSet("webhooks "+sub, stderr)
+	var of outputFlags
+	of.register(fs)
+	path := fs.String("store", "", "`file` of the qris.JSONDeliveryStore (required)")
+	since := fs.Duration("since", 24*time.Hour, "list or retry the deliveries created within this duration")
+	id := fs.String("id", "", "retry only the delivery with this event `ID`")
+	if code, ok := parseFlags(fs, args[1:]); !ok {
+		return code
+	}
+	setupLogging(of.verbose, stderr)
+	if *path == "" {
+		return fail(stderr, errors.New("--store must be filled / --store harus diisi"))
+	}
+
+	store, err := qris.NewJSONDeliveryStore(*path)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	// Without a URL the webhook only replays what the store holds
+	webhook, err := qris.NewWebhook(qris.WebhookConfig{Store: store})
+	if err != nil {
+		return fail(stderr, err)
+	}
+	defer webhook.Close()
+
+	ctx := context.Background()
+	var deliveries []qris.Delivery
+	if *id != "" {
+		d, err := store.Get(ctx, *id)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		deliveries = []qris.Delivery{d}
+	} else if deliveries, err = webhook.ListFailedDeliveries(ctx, time.Now().Add(-*since)); err != nil {
+		return fail(stderr, err)
+	}
+
+	failed := 0
+	if sub == "retry" {
+		for i, d := range deliveries {
+			if deliveries[i], err = webhook.Redeliver(ctx, d.ID); err != nil {
+				failed++
+			}
+		}
+	}
+	if code := printDeliveries(stdout, stderr, deliveries, of.json); code != exitOK {
+		return code
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d deliveries failed again / %d dari %d pengiriman gagal lagi\n", failed, len(deliveries), failed, len(deliveries))
+		return exitError
+	}
+	return exitOK
+}
+
+func printDeliveries(stdout, stderr io.Writer, deliveries []qris.Delivery, asJSON bool) int {
+	if asJSON {
+		if deliveries == nil {
+			deliveries = []qris.Delivery{}
+		}
+		if err := writeJSON(stdout, deliveries); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+	for _, d := range deliveries {
+		fmt.Fprintf(stdout, "%s  %-9s  %-17s  %s  attempts=%d  %s\n", d.CreatedAt.Format(time.RFC3339), d.Status, d.Type, d.ID, d.Attempts, d.LastError)
+	}
+	return exitOK
+}
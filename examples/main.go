@@ -1,74 +1,79 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
 )
 
+// demoBaseQR is a sample static QRIS used with -demo.
+// demoBaseQR adalah contoh QRIS statis yang dipakai dengan -demo.
+const demoBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
 func main() {
-	// Inisialisasi QRIS dengan konfigurasi
-	config := qris.QRISConfig{
-		BaseQrString: "BASE_QR_STRING",
-		AuthToken:    "YOUR_AUTH_TOKEN",
-		AuthUsername: "YOUR_AUTH_USERNAME",
+	demo := flag.Bool("demo", false, "run against a local fake gateway that pays after 2 seconds / jalankan dengan gateway palsu lokal yang membayar setelah 2 detik")
+	amount := flag.Int64("amount", 1000, "amount to charge / nominal yang ditagih")
+	out := flag.String("out", "qris.png", "QR code output file / file output QR code")
+	flag.Parse()
+
+	// Read QRIS_BASE_QR_STRING, QRIS_AUTH_TOKEN, QRIS_AUTH_USERNAME, ... from the environment
+	// Baca QRIS_BASE_QR_STRING, QRIS_AUTH_TOKEN, QRIS_AUTH_USERNAME, ... dari environment
+	var config qris.QRISConfig
+	var gateway *qristest.Server
+	if *demo {
+		gateway = qristest.NewServer()
+		defer gateway.Close()
+		config = qris.QRISConfig{
+			BaseQrString: demoBaseQR,
+			AuthToken:    "demo",
+			AuthUsername: "demo",
+			GatewayURL:   gateway.URL,
+		}
+	} else {
+		var err error
+		if config, err = qris.ConfigFromEnv("QRIS"); err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
 	}
 
-	// Buat instance QRIS
 	qrisInstance, err := qris.NewQRIS(config)
 	if err != nil {
-		panic(err)
+		log.Fatalf("Error creating QRIS instance: %v", err)
 	}
+	defer qrisInstance.Close(context.Background())
 
-	// Generate QR Code
+	// Generate the QR code and save it as PNG
+	// Generate QR code dan simpan sebagai PNG
 	data := qris.QRISData{
-		Amount:        150,
-		TransactionID: "TRX123",
+		Amount:        *amount,
+		TransactionID: fmt.Sprintf("TRX%d", time.Now().Unix()),
 	}
-
 	qrCode, err := qrisInstance.GenerateQRCode(data)
 	if err != nil {
 		log.Fatalf("Error generating QR code: %v", err)
 	}
-
-	// Simpan QR code ke file
-	err = qrCode.WriteFile(256, "qris.png")
-	if err != nil {
+	if err := qrCode.WriteFile(256, *out); err != nil {
 		log.Fatalf("Error saving QR code: %v", err)
 	}
+	fmt.Printf("QR code saved as %s, scan it to pay Rp %d\n", *out, data.Amount)
 
-	fmt.Println("QR Code berhasil dibuat dan disimpan sebagai qris.png")
-	fmt.Println("Silahkan scan QR code untuk melakukan pembayaran...")
-
-	// Cek status pembayaran secara berulang
-	for {
-		fmt.Println("\nMengecek status pembayaran...")
-		status, err := qrisInstance.CheckPaymentStatus("TRX123", 150)
-		if err != nil {
-			log.Printf("Error checking payment status: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+	if gateway != nil {
+		time.AfterFunc(2*time.Second, func() { gateway.AddMutation(data.Amount, time.Now()) })
+	}
 
-		// Tampilkan detail status
-		fmt.Printf("Status Pembayaran: %s\n", status.Status)
-		fmt.Printf("Amount yang diharapkan: %d\n", 150)
-		fmt.Printf("Amount yang diterima: %d\n", status.Amount)
-		fmt.Printf("Reference: %s\n", status.Reference)
-		
-		if status.Status == "PAID" {
-			fmt.Printf("Pembayaran berhasil!\n")
-			fmt.Printf("Date: %s\n", status.Date)
-			fmt.Printf("Brand: %s\n", status.BrandName)
-			fmt.Printf("Buyer Ref: %s\n", status.BuyerRef)
-			break
-		} else {
-			fmt.Println("Menunggu pembayaran...")
-		}
+	// Poll until the payment arrives or 10 minutes pass
+	// Polling sampai pembayaran masuk atau 10 menit berlalu
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
 
-		// Tunggu 5 detik sebelum cek lagi
-		time.Sleep(5 * time.Second)
+	status, err := qrisInstance.WaitForPayment(ctx, data.TransactionID, data.Amount, qris.WithPollInterval(time.Second))
+	if err != nil {
+		log.Fatalf("Payment not received: %v", err)
 	}
-} 
\ No newline at end of file
+	fmt.Printf("Payment received: Rp %d from %s at %s\n", status.Amount, status.BrandName, status.Date)
+}
@@ -0,0 +1,62 @@
+// Command chi serves the qrishttp invoice routes under /qris with go-chi/chi. It lives in the
+// routers example module, so the library does not depend on chi:
+// Command chi melayani route invoice qrishttp di bawah /qris dengan go-chi/chi. Command ini berada
+// di modul contoh routers, sehingga library tidak bergantung pada chi:
+//
+//	cd examples/routers && go run ./chi -demo
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// demoBaseQR is a sample static QRIS used with -demo.
+// demoBaseQR adalah contoh QRIS statis yang dipakai dengan -demo.
+const demoBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+func main() {
+	demo := flag.Bool("demo", false, "run against a local fake gateway / jalankan dengan gateway palsu lokal")
+	addr := flag.String("addr", ":8080", "listen address / alamat listen")
+	flag.Parse()
+
+	config, err := qris.ConfigFromEnv("QRIS")
+	if *demo {
+		gateway := qristest.NewServer()
+		defer gateway.Close()
+		config, err = qris.QRISConfig{BaseQrString: demoBaseQR, AuthToken: "demo", AuthUsername: "demo", GatewayURL: gateway.URL}, nil
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	qrisInstance, err := qris.NewQRIS(config)
+	if err != nil {
+		log.Fatalf("Error creating QRIS instance: %v", err)
+	}
+	defer qrisInstance.Close(context.Background())
+
+	log.Printf("Listening on %s, try POST /qris/payments / Mendengarkan di %s, coba POST /qris/payments", *addr, *addr)
+	log.Fatal(http.ListenAndServe(*addr, newRouter(qrisInstance)))
+}
+
+// newRouter mounts the routes of q under /qris on a chi router.
+// newRouter memasang route dari q di bawah /qris pada router chi.
+func newRouter(q *qris.QRIS) http.Handler {
+	// chi takes the {id} patterns as they are; PathParam reads them from the end of the path
+	// chi menerima pattern {id} apa adanya; PathParam membacanya dari akhir path
+	r := chi.NewRouter()
+	r.Route("/qris", func(r chi.Router) {
+		for _, rt := range qrishttp.Routes(q) {
+			r.Method(rt.Method, rt.Pattern, rt.Handler)
+		}
+	})
+	return r
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/examples/routers/internal/routertest"
+)
+
+func TestRouter(t *testing.T) {
+	routertest.Run(t, newRouter)
+}
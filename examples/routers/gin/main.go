@@ -0,0 +1,67 @@
+// Command gin serves the qrishttp invoice routes under /qris with gin. It lives in the routers
+// example module, so the library does not depend on gin:
+// Command gin melayani route invoice qrishttp di bawah /qris dengan gin. Command ini berada di
+// modul contoh routers, sehingga library tidak bergantung pada gin:
+//
+//	cd examples/routers && go run ./gin -demo
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// demoBaseQR is a sample static QRIS used with -demo.
+// demoBaseQR adalah contoh QRIS statis yang dipakai dengan -demo.
+const demoBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+func main() {
+	demo := flag.Bool("demo", false, "run against a local fake gateway / jalankan dengan gateway palsu lokal")
+	addr := flag.String("addr", ":8080", "listen address / alamat listen")
+	flag.Parse()
+
+	config, err := qris.ConfigFromEnv("QRIS")
+	if *demo {
+		gateway := qristest.NewServer()
+		defer gateway.Close()
+		config, err = qris.QRISConfig{BaseQrString: demoBaseQR, AuthToken: "demo", AuthUsername: "demo", GatewayURL: gateway.URL}, nil
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	qrisInstance, err := qris.NewQRIS(config)
+	if err != nil {
+		log.Fatalf("Error creating QRIS instance: %v", err)
+	}
+	defer qrisInstance.Close(context.Background())
+
+	log.Printf("Listening on %s, try POST /qris/payments / Mendengarkan di %s, coba POST /qris/payments", *addr, *addr)
+	log.Fatal(newRouter(qrisInstance).Run(*addr))
+}
+
+// newRouter mounts the routes of q under /qris on a gin engine.
+// newRouter memasang route dari q di bawah /qris pada engine gin.
+func newRouter(q *qris.QRIS) *gin.Engine {
+	// gin wants :id instead of {id}; the parameter is passed on explicitly, as gin.WrapF drops it
+	// gin membutuhkan :id sebagai ganti {id}; parameter diteruskan secara eksplisit, karena gin.WrapF membuangnya
+	engine := gin.Default()
+	g := engine.Group("/qris")
+	for _, rt := range qrishttp.Routes(q) {
+		rt := rt
+		g.Handle(rt.Method, rt.ColonPattern(), func(c *gin.Context) {
+			r := c.Request
+			for _, p := range c.Params {
+				r = qrishttp.WithPathParam(r, p.Key, p.Value)
+			}
+			rt.Handler(c.Writer, r)
+		})
+	}
+	return engine
+}
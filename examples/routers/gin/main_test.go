@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AutoFTbot/OrderKuota-go/examples/routers/internal/routertest"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	routertest.Run(t, func(q *qris.QRIS) http.Handler { return newRouter(q) })
+}
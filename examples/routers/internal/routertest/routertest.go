@@ -0,0 +1,118 @@
+// Package routertest checks that a router serves the qrishttp routes the way the library expects.
+// Package routertest memeriksa bahwa sebuah router melayani route qrishttp seperti yang diharapkan library.
+package routertest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// testBaseQR is a static QRIS of a test merchant.
+const testBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+// Run mounts an instance reading a fake gateway with mount, which must serve its routes under
+// /qris, then creates, shows, pays and cancels payments through the router.
+// Run memasang instance yang membaca gateway palsu dengan mount, yang harus melayani route-nya di
+// bawah /qris, lalu membuat, menampilkan, membayar, dan membatalkan pembayaran lewat router.
+func Run(t *testing.T, mount func(q *qris.QRIS) http.Handler) {
+	t.Helper()
+	gw := qristest.NewServer()
+	t.Cleanup(gw.Close)
+	q, err := qris.NewQRIS(qris.QRISConfig{
+		BaseQrString: testBaseQR,
+		AuthToken:    "token",
+		AuthUsername: "user",
+		GatewayURL:   gw.URL,
+		PollSchedule: qris.FixedSchedule(10 * time.Millisecond),
+		RandSource:   qristest.SeededRand(1),
+	})
+	if err != nil {
+		t.Fatalf("NewQRIS: %v", err)
+	}
+	t.Cleanup(func() { q.Close(context.Background()) })
+	srv := httptest.NewServer(mount(q))
+	t.Cleanup(srv.Close)
+
+	resp := do(t, http.MethodPost, srv.URL+"/qris/payments", `{"amount":25000,"transaction_id":"INV-1"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /payments = %d, want 201", resp.StatusCode)
+	}
+	var created struct {
+		TransactionID string     `json:"transaction_id"`
+		Amount        qris.Money `json:"amount"`
+		Code          string     `json:"code"`
+	}
+	decode(t, resp, &created)
+	if created.TransactionID != "INV-1" || created.Code == "" {
+		t.Fatalf("created = %+v, want INV-1 with a code", created)
+	}
+
+	requests := []struct {
+		method, path string
+		status       int
+		contentType  string
+	}{
+		{http.MethodGet, "/qris/payments/INV-1", http.StatusOK, "application/json"},
+		{http.MethodGet, "/qris/payments/INV-1/qr.png", http.StatusOK, "image/png"},
+		{http.MethodGet, "/qris/pay/" + created.Code, http.StatusOK, "text/html"},
+		{http.MethodGet, "/qris/payments/INV-2", http.StatusNotFound, "application/json"},
+		{http.MethodGet, "/qris/qr.png?amount=1000&transaction_id=INV-3", http.StatusOK, "image/png"},
+	}
+	for _, req := range requests {
+		resp := do(t, req.method, srv.URL+req.path, "")
+		resp.Body.Close()
+		if resp.StatusCode != req.status {
+			t.Errorf("%s %s = %d, want %d", req.method, req.path, resp.StatusCode, req.status)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, req.contentType) {
+			t.Errorf("%s %s Content-Type = %q, want %s", req.method, req.path, ct, req.contentType)
+		}
+	}
+
+	gw.AddMutation(created.Amount.Value, time.Now())
+	var view qris.PaymentStatusView
+	deadline := time.Now().Add(5 * time.Second)
+	for view.Status != qris.StatusPaid && time.Now().Before(deadline) {
+		decode(t, do(t, http.MethodGet, srv.URL+"/qris/payments/INV-1", ""), &view)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if view.Status != qris.StatusPaid || view.Amount.Value != created.Amount.Value {
+		t.Fatalf("status = %s %d, want PAID %d", view.Status, view.Amount.Value, created.Amount.Value)
+	}
+
+	resp = do(t, http.MethodPost, srv.URL+"/qris/payments", `{"amount":30000,"transaction_id":"INV-4"}`)
+	resp.Body.Close()
+	decode(t, do(t, http.MethodDelete, srv.URL+"/qris/payments/INV-4", ""), &view)
+	if view.Status != qris.StatusCancelled {
+		t.Fatalf("DELETE /payments/INV-4 status = %s, want CANCELLED", view.Status)
+	}
+}
+
+func do(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %s: %v", resp.Request.URL.Path, err)
+	}
+}
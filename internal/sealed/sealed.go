@@ -0,0 +1,141 @@
+// Package sealed encrypts files at rest with AES-256-GCM.
+// Package sealed mengenkripsi file yang tersimpan dengan AES-256-GCM.
+package sealed
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of an encryption key in bytes.
+// KeySize adalah panjang kunci enkripsi dalam byte.
+const KeySize = 32
+
+// prefix marks sealed data, so plaintext files written before encryption was enabled still load.
+// prefix menandai data tersegel, sehingga file plaintext yang ditulis sebelum enkripsi diaktifkan tetap bisa dimuat.
+var prefix = []byte("okgcm1:")
+
+// ErrDecrypt is returned when sealed data cannot be opened with any of the given keys.
+// ErrDecrypt dikembalikan jika data tersegel tidak dapat dibuka dengan kunci mana pun yang diberikan.
+var ErrDecrypt = errors.New("failed to decrypt, wrong key or corrupted data / gagal mendekripsi, kunci salah atau data rusak")
+
+// CheckKey reports an error if key is not KeySize bytes long.
+// CheckKey melaporkan error jika panjang key bukan KeySize byte.
+func CheckKey(key []byte) error {
+	if len(key) != KeySize {
+		return fmt.Errorf("encryption key must be %d bytes, got %d / kunci enkripsi harus %d byte, didapat %d", KeySize, len(key), KeySize, len(key))
+	}
+	return nil
+}
+
+// IsSealed reports whether data was produced by Seal.
+// IsSealed melaporkan apakah data dihasilkan oleh Seal.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, prefix)
+}
+
+// Seal encrypts plaintext with key under a random nonce.
+// Seal mengenkripsi plaintext dengan key memakai nonce acak.
+func Seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce / gagal generate nonce: %v", err)
+	}
+	box := aead.Seal(nonce, nonce, plaintext, prefix)
+
+	out := make([]byte, len(prefix)+base64.StdEncoding.EncodedLen(len(box)))
+	copy(out, prefix)
+	base64.StdEncoding.Encode(out[len(prefix):], box)
+	return out, nil
+}
+
+// Open decrypts data sealed with any of keys, trying them in order.
+// Open mendekripsi data yang disegel dengan salah satu keys, mencobanya secara berurutan.
+func Open(keys [][]byte, data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, fmt.Errorf("%w: data is not sealed / data tidak tersegel", ErrDecrypt)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: data is encrypted but no key was given / data terenkripsi tetapi tidak ada kunci yang diberikan", ErrDecrypt)
+	}
+	box, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(prefix):])))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	for _, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(box) < aead.NonceSize() {
+			return nil, ErrDecrypt
+		}
+		nonce, ciphertext := box[:aead.NonceSize()], box[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, prefix); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrDecrypt
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encode seals data with the first of keys, or returns it unchanged when keys is empty.
+// Encode menyegel data dengan kunci pertama dari keys, atau mengembalikannya apa adanya jika keys kosong.
+func Encode(keys [][]byte, data []byte) ([]byte, error) {
+	if len(keys) == 0 {
+		return data, nil
+	}
+	return Seal(keys[0], data)
+}
+
+// Decode opens sealed data with keys; data that is not sealed is returned unchanged.
+// Decode membuka data tersegel dengan keys; data yang tidak tersegel dikembalikan apa adanya.
+func Decode(keys [][]byte, data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	return Open(keys, data)
+}
+
+// Keyring supplies encryption keys, current key first.
+// Keyring menyediakan kunci enkripsi, kunci saat ini lebih dulu.
+type Keyring interface {
+	Keys() ([][]byte, error)
+}
+
+// KeysOf returns the checked keys of kr; a nil keyring has no keys, which means plaintext.
+// KeysOf mengembalikan kunci kr yang sudah diperiksa; keyring nil tidak punya kunci, yang berarti plaintext.
+func KeysOf(kr Keyring) ([][]byte, error) {
+	if kr == nil {
+		return nil, nil
+	}
+	keys, err := kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := CheckKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
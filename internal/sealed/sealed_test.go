@@ -0,0 +1,205 @@
+package sealed_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/sealed"
+)
+
+// key returns a test key filled with b.
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, sealed.KeySize)
+}
+
+func TestSealOpen(t *testing.T) {
+	plaintext := []byte(`{"transaction_id":"INV-1","amount":25000}`)
+	sealedWith := func(k []byte) []byte {
+		data, err := sealed.Seal(k, plaintext)
+		if err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+		return data
+	}
+	// flip changes byte i of the decoded box, counted from the end if negative, and re-encodes it
+	flip := func(data []byte, i int) []byte {
+		box, err := base64.StdEncoding.DecodeString(string(data[len("okgcm1:"):]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 0 {
+			i += len(box)
+		}
+		box[i] ^= 1
+		return append([]byte("okgcm1:"), base64.StdEncoding.EncodeToString(box)...)
+	}
+	a := sealedWith(key(1))
+
+	tests := []struct {
+		name    string
+		keys    [][]byte
+		data    []byte
+		wantErr error
+	}{
+		{name: "same key", keys: [][]byte{key(1)}, data: a},
+		{name: "trailing newline", keys: [][]byte{key(1)}, data: append(append([]byte(nil), a...), '\n')},
+		{name: "old key second", keys: [][]byte{key(2), key(1)}, data: a},
+		{name: "old key first", keys: [][]byte{key(1), key(2)}, data: a},
+		{name: "wrong key", keys: [][]byte{key(2)}, data: a, wantErr: sealed.ErrDecrypt},
+		{name: "no key", data: a, wantErr: sealed.ErrDecrypt},
+		{name: "nonce tampered", keys: [][]byte{key(1)}, data: flip(a, 0), wantErr: sealed.ErrDecrypt},
+		{name: "ciphertext tampered", keys: [][]byte{key(1)}, data: flip(a, 20), wantErr: sealed.ErrDecrypt},
+		{name: "tag tampered", keys: [][]byte{key(1)}, data: flip(a, -1), wantErr: sealed.ErrDecrypt},
+		{name: "truncated", keys: [][]byte{key(1)}, data: a[:len(a)-8], wantErr: sealed.ErrDecrypt},
+		{name: "shorter than a nonce", keys: [][]byte{key(1)}, data: []byte("okgcm1:AAAA"), wantErr: sealed.ErrDecrypt},
+		{name: "not base64", keys: [][]byte{key(1)}, data: []byte("okgcm1:not base64!"), wantErr: sealed.ErrDecrypt},
+		{name: "not sealed", keys: [][]byte{key(1)}, data: plaintext, wantErr: sealed.ErrDecrypt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sealed.Open(tt.keys, tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || got != nil {
+					t.Fatalf("Open = %q, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("Open = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestSealFormat(t *testing.T) {
+	plaintext := []byte("secret token")
+	a, err := sealed.Seal(key(1), plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sealed.Seal(key(1), plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sealed.IsSealed(a) || bytes.Contains(a, plaintext) {
+		t.Fatalf("Seal = %q, want an okgcm1: prefix and no plaintext", a)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("two Seals of the same plaintext are equal, want fresh nonces")
+	}
+
+	// Files written by earlier versions must keep opening: prefix, then base64 of nonce,
+	// ciphertext and tag, with the prefix as additional data
+	block, err := aes.NewCipher(key(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	box := aead.Seal(nonce, nonce, plaintext, []byte("okgcm1:"))
+	data := append([]byte("okgcm1:"), base64.StdEncoding.EncodeToString(box)...)
+	got, err := sealed.Open([][]byte{key(3)}, data)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open of a hand-sealed file = %q, %v, want %q", got, err, plaintext)
+	}
+
+	// Without the prefix as additional data the tag does not match
+	box = aead.Seal(nonce, nonce, plaintext, nil)
+	data = append([]byte("okgcm1:"), base64.StdEncoding.EncodeToString(box)...)
+	if _, err := sealed.Open([][]byte{key(3)}, data); !errors.Is(err, sealed.ErrDecrypt) {
+		t.Fatalf("Open without additional data = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	plaintext := []byte(`{"token":"abc"}`)
+	tests := []struct {
+		name       string
+		encodeKeys [][]byte
+		decodeKeys [][]byte
+		sealed     bool
+		wantErr    error
+	}{
+		{name: "plaintext", sealed: false},
+		{name: "plaintext read with a key", decodeKeys: [][]byte{key(1)}},
+		{name: "encrypted", encodeKeys: [][]byte{key(1)}, decodeKeys: [][]byte{key(1)}, sealed: true},
+		{name: "encrypted with the first key", encodeKeys: [][]byte{key(2), key(1)}, decodeKeys: [][]byte{key(2)}, sealed: true},
+		{name: "rotated", encodeKeys: [][]byte{key(1)}, decodeKeys: [][]byte{key(2), key(1)}, sealed: true},
+		{name: "encrypted read without a key", encodeKeys: [][]byte{key(1)}, sealed: true, wantErr: sealed.ErrDecrypt},
+		{name: "encrypted read with another key", encodeKeys: [][]byte{key(2), key(1)}, decodeKeys: [][]byte{key(1)}, sealed: true, wantErr: sealed.ErrDecrypt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := sealed.Encode(tt.encodeKeys, plaintext)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if sealed.IsSealed(data) != tt.sealed {
+				t.Fatalf("Encode = %q, want sealed = %v", data, tt.sealed)
+			}
+			got, err := sealed.Decode(tt.decodeKeys, data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decode = %q, %v, want %q", got, err, plaintext)
+			}
+		})
+	}
+}
+
+// keyring is a sealed.Keyring returning fixed keys or an error.
+type keyring struct {
+	keys [][]byte
+	err  error
+}
+
+func (k keyring) Keys() ([][]byte, error) { return k.keys, k.err }
+
+func TestKeysOf(t *testing.T) {
+	errVault := errors.New("vault unavailable")
+	tests := []struct {
+		name    string
+		kr      sealed.Keyring
+		want    int
+		wantErr bool
+	}{
+		{name: "nil", kr: nil},
+		{name: "empty", kr: keyring{}},
+		{name: "two keys", kr: keyring{keys: [][]byte{key(1), key(2)}}, want: 2},
+		{name: "short key", kr: keyring{keys: [][]byte{key(1), key(2)[:16]}}, wantErr: true},
+		{name: "long key", kr: keyring{keys: [][]byte{append(key(1), 0)}}, wantErr: true},
+		{name: "keyring error", kr: keyring{err: errVault}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := sealed.KeysOf(tt.kr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KeysOf err = %v, want error = %v", err, tt.wantErr)
+			}
+			if len(keys) != tt.want {
+				t.Fatalf("KeysOf = %d keys, want %d", len(keys), tt.want)
+			}
+		})
+	}
+
+	if _, err := sealed.Seal(key(1)[:16], []byte("x")); err == nil {
+		t.Error("Seal with a 16-byte key succeeded, want AES-256 only")
+	}
+	if _, err := sealed.Open([][]byte{key(1)[:16]}, []byte("okgcm1:AAAA")); err == nil || errors.Is(err, sealed.ErrDecrypt) {
+		t.Errorf("Open with a 16-byte key = %v, want a key size error", err)
+	}
+}
@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// RoundTripFunc sends one HTTP request.
+// RoundTripFunc mengirim satu request HTTP.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the sending of a request, e.g. to add headers or log it.
+// Interceptor membungkus pengiriman request, misalnya untuk menambah header atau mencatatnya di log.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+// Chain wraps send with interceptors; the first interceptor is the outermost.
+// Chain membungkus send dengan interceptors; interceptor pertama adalah yang paling luar.
+func Chain(send RoundTripFunc, interceptors []Interceptor) RoundTripFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] != nil {
+			send = interceptors[i](send)
+		}
+	}
+	return send
+}
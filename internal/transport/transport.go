@@ -0,0 +1,63 @@
+// Package transport builds the HTTP clients used to reach the gateways.
+// Package transport membuat HTTP client yang dipakai untuk menghubungi gateway.
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Config describes how to reach a gateway.
+// Config menjelaskan cara menghubungi gateway.
+type Config struct {
+	ProxyURL  string         // Egress proxy, empty for the environment's proxy settings / Proxy keluar, kosong untuk pengaturan proxy dari environment
+	TLSConfig *tls.Config    // Custom TLS settings, e.g. for certificate pinning / Pengaturan TLS khusus, misalnya untuk pinning sertifikat
+	RootCAs   *x509.CertPool // Trusted roots, replacing the system pool / Root yang dipercaya, menggantikan pool sistem
+}
+
+// IsZero reports whether c asks for nothing beyond the defaults.
+// IsZero melaporkan apakah c tidak meminta apa pun selain default.
+func (c Config) IsZero() bool {
+	return c.ProxyURL == "" && c.TLSConfig == nil && c.RootCAs == nil
+}
+
+// ParseProxyURL checks that a proxy URL has a supported scheme and a host.
+// ParseProxyURL memeriksa bahwa URL proxy memiliki skema yang didukung dan host.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+		return nil, fmt.Errorf("invalid proxy URL %q / URL proxy %q tidak valid", raw, raw)
+	}
+	return u, nil
+}
+
+// NewClient returns an HTTP client honoring c. Timeouts are left to request contexts.
+// NewClient mengembalikan HTTP client yang mengikuti c. Batas waktu diserahkan ke context request.
+func NewClient(c Config) (*http.Client, error) {
+	if c.IsZero() {
+		return &http.Client{}, nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		u, err := ParseProxyURL(c.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+
+	if c.TLSConfig != nil {
+		t.TLSClientConfig = c.TLSConfig.Clone()
+	}
+	if c.RootCAs != nil {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		t.TLSClientConfig.RootCAs = c.RootCAs
+	}
+	return &http.Client{Transport: t}, nil
+}
@@ -0,0 +1,75 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrProductUnavailable is returned by Purchase with EnsureAvailable when the product cannot be sold now.
+// ErrProductUnavailable dikembalikan oleh Purchase dengan EnsureAvailable jika produk tidak dapat dijual saat ini.
+var ErrProductUnavailable = errors.New("product unavailable / produk tidak tersedia")
+
+// UnknownStock is the Stock value when the gateway does not report stock.
+// UnknownStock adalah nilai Stock jika gateway tidak melaporkan stok.
+const UnknownStock = -1
+
+// Availability is the current sale status of a product.
+// Availability adalah status penjualan produk saat ini.
+type Availability struct {
+	ProductCode string // Product code / Kode produk
+	Status      string // One of the Product status constants / Salah satu konstanta status Product
+	Stock       int64  // Remaining stock, or UnknownStock / Sisa stok, atau UnknownStock
+}
+
+// Available reports whether the product can be purchased now.
+// Available melaporkan apakah produk dapat dibeli sekarang.
+func (a *Availability) Available() bool {
+	return a.Status == ProductOpen && a.Stock != 0
+}
+
+// CheckAvailability asks the gateway whether a product is open, closed, or disrupted (gangguan)
+// and, where exposed, how much stock is left.
+// CheckAvailability menanyakan ke gateway apakah produk buka, tutup, atau gangguan
+// dan, jika tersedia, berapa sisa stoknya.
+func (c *Client) CheckAvailability(ctx context.Context, productCode string) (*Availability, error) {
+	if productCode == "" {
+		return nil, errors.New("productCode must be filled / productCode harus diisi")
+	}
+
+	var results struct {
+		Status text  `json:"status"`
+		Stock  *text `json:"stock"`
+	}
+	if err := c.do(ctx, "/product/status", map[string]string{"product_code": productCode}, &results); err != nil {
+		return nil, err
+	}
+
+	a := &Availability{
+		ProductCode: productCode,
+		Status:      normalizeProductStatus(string(results.Status)),
+		Stock:       UnknownStock,
+	}
+	if results.Stock != nil {
+		// Values such as "unlimited" leave the stock unknown
+		if stock, err := strconv.ParseInt(strings.TrimSpace(string(*results.Stock)), 10, 64); err == nil && stock >= 0 {
+			a.Stock = stock
+		}
+	}
+	return a, nil
+}
+
+// ensureAvailable fails with ErrProductUnavailable unless productCode can be purchased now.
+// ensureAvailable gagal dengan ErrProductUnavailable kecuali productCode dapat dibeli sekarang.
+func (c *Client) ensureAvailable(ctx context.Context, productCode string) error {
+	a, err := c.CheckAvailability(ctx, productCode)
+	if err != nil {
+		return err
+	}
+	if !a.Available() {
+		return fmt.Errorf("%w: %s is %s / %s sedang %s", ErrProductUnavailable, productCode, a.Status, productCode, a.Status)
+	}
+	return nil
+}
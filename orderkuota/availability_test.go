@@ -0,0 +1,54 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		status    string
+		stock     int64
+		available bool
+	}{
+		{"product_status_open.json", orderkuota.ProductOpen, 12, true},
+		{"product_status_closed.json", orderkuota.ProductClosed, orderkuota.UnknownStock, false},
+		{"product_status_gangguan.json", orderkuota.ProductDisrupted, orderkuota.UnknownStock, false},
+		{"product_status_sold_out.json", orderkuota.ProductOpen, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/product/status": tt.fixture, "/purchase": "purchase.json"})
+			c := newTestClient(t, srv)
+			ctx := context.Background()
+
+			a, err := c.CheckAvailability(ctx, "ML86")
+			if err != nil {
+				t.Fatalf("CheckAvailability: %v", err)
+			}
+			if a.ProductCode != "ML86" || a.Status != tt.status || a.Stock != tt.stock || a.Available() != tt.available {
+				t.Fatalf("CheckAvailability = %+v, want %s with stock %d", a, tt.status, tt.stock)
+			}
+			if got := srv.received()[0].Params["product_code"]; got != "ML86" {
+				t.Fatalf("sent product_code %q, want ML86", got)
+			}
+
+			// EnsureAvailable fails fast instead of buying
+			_, err = c.Purchase(ctx, orderkuota.PurchaseRequest{ProductCode: "ML86", Destination: "12345678", EnsureAvailable: true})
+			if tt.available != (err == nil) || !tt.available && !errors.Is(err, orderkuota.ErrProductUnavailable) {
+				t.Fatalf("Purchase err = %v, want available %v", err, tt.available)
+			}
+			wantPurchases := 0
+			if tt.available {
+				wantPurchases = 1
+			}
+			if n := srv.count("/purchase"); n != wantPurchases {
+				t.Fatalf("%d purchases sent, want %d", n, wantPurchases)
+			}
+		})
+	}
+}
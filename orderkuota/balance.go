@@ -0,0 +1,33 @@
+package orderkuota
+
+import "context"
+
+// Balance is the deposit (saldo) of the account.
+// Balance adalah saldo deposit akun.
+type Balance struct {
+	Amount      int64  // Available saldo in rupiah / Saldo tersedia dalam rupiah
+	AccountName string // Account name / Nama akun
+	MemberID    string // Member ID / ID member
+}
+
+// GetBalance returns the available saldo of the account.
+// GetBalance mengembalikan saldo yang tersedia di akun.
+//
+// A rejected or expired token returns ErrUnauthorized.
+// Token yang ditolak atau kedaluwarsa mengembalikan ErrUnauthorized.
+func (c *Client) GetBalance(ctx context.Context) (*Balance, error) {
+	var results struct {
+		Balance  amount `json:"balance"`
+		Name     string `json:"name"`
+		MemberID text   `json:"id"`
+	}
+	if err := c.do(ctx, "/balance", nil, &results); err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		Amount:      int64(results.Balance),
+		AccountName: results.Name,
+		MemberID:    string(results.MemberID),
+	}, nil
+}
@@ -0,0 +1,219 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Defaults of BalanceMonitorConfig.
+// Nilai default dari BalanceMonitorConfig.
+const (
+	DefaultBalanceCheck = time.Minute // Interval / Interval
+	DefaultDropWindow   = time.Hour   // DropWindow / DropWindow
+)
+
+// Kinds of BalanceAlert.
+// Jenis BalanceAlert.
+const (
+	BalanceLow       BalanceAlertKind = "low"       // Saldo fell below Threshold / Saldo turun di bawah Threshold
+	BalanceRecovered BalanceAlertKind = "recovered" // Saldo is back above Threshold+Hysteresis / Saldo kembali di atas Threshold+Hysteresis
+	BalanceDrop      BalanceAlertKind = "drop"      // Saldo fell by DropPercent within DropWindow / Saldo turun sebesar DropPercent dalam DropWindow
+)
+
+// BalanceAlertKind is the reason of a BalanceAlert.
+// BalanceAlertKind adalah alasan dari BalanceAlert.
+type BalanceAlertKind string
+
+// BalanceAlert is raised by a BalanceMonitor.
+// BalanceAlert dimunculkan oleh BalanceMonitor.
+type BalanceAlert struct {
+	Kind     BalanceAlertKind
+	Balance  int64     // Saldo at the check / Saldo saat pemeriksaan
+	Previous int64     // Threshold, or the highest saldo in DropWindow for BalanceDrop / Threshold, atau saldo tertinggi dalam DropWindow untuk BalanceDrop
+	At       time.Time // Time of the check / Waktu pemeriksaan
+}
+
+// BalanceMonitorConfig configures MonitorBalance. Threshold and DropPercent are both optional;
+// leaving both zero only reports the saldo to OnBalance.
+// BalanceMonitorConfig mengatur MonitorBalance. Threshold dan DropPercent sama-sama opsional;
+// jika keduanya nol, saldo hanya dilaporkan ke OnBalance.
+type BalanceMonitorConfig struct {
+	Interval    time.Duration      // Time between checks, default DefaultBalanceCheck / Jeda antar pemeriksaan, default DefaultBalanceCheck
+	Threshold   int64              // Saldo in rupiah below which BalanceLow is raised / Saldo dalam rupiah yang di bawahnya BalanceLow dimunculkan
+	Hysteresis  int64              // Saldo above Threshold needed to raise BalanceLow again, default Threshold/10 / Saldo di atas Threshold yang diperlukan untuk memunculkan BalanceLow lagi, default Threshold/10
+	DropPercent float64            // Drop from the highest saldo in DropWindow that raises BalanceDrop / Penurunan dari saldo tertinggi dalam DropWindow yang memunculkan BalanceDrop
+	DropWindow  time.Duration      // Default DefaultDropWindow / Default DefaultDropWindow
+	OnAlert     func(BalanceAlert) // Called for every alert, from the monitor goroutine / Dipanggil untuk setiap peringatan, dari goroutine monitor
+	OnBalance   func(int64)        // Called with every checked saldo, e.g. to set a gauge / Dipanggil dengan setiap saldo yang diperiksa, misalnya untuk mengatur gauge
+	Logger      *log.Logger        // Receives check failures, default log.Default() / Menerima kegagalan pemeriksaan, default log.Default()
+}
+
+// BalanceMonitor checks the saldo in the background and raises alerts when it runs low or falls
+// unusually fast. A BalanceLow alert is raised once and again only after the saldo recovered above
+// Threshold+Hysteresis; a BalanceDrop alert is raised at most once per DropWindow.
+// BalanceMonitor memeriksa saldo di latar belakang dan memunculkan peringatan jika saldo menipis atau
+// turun dengan cepat secara tidak wajar. Peringatan BalanceLow dimunculkan sekali dan baru lagi setelah
+// saldo pulih di atas Threshold+Hysteresis; peringatan BalanceDrop dimunculkan paling banyak sekali per DropWindow.
+type BalanceMonitor struct {
+	client *Client
+	config BalanceMonitorConfig
+	logger *log.Logger
+
+	checkMu   sync.Mutex // Serializes checks / Mengurutkan pemeriksaan
+	low       bool       // BalanceLow raised and not yet recovered / BalanceLow sudah dimunculkan dan belum pulih
+	lastDrop  time.Time
+	samples   []balanceSample
+	closeOnce sync.Once
+
+	mu      sync.RWMutex
+	balance int64
+	checked time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type balanceSample struct {
+	amount int64
+	at     time.Time
+}
+
+// MonitorBalance checks the saldo and keeps checking it every config.Interval until ctx is done or
+// Close is called. It returns an error only when the first check fails.
+// MonitorBalance memeriksa saldo dan terus memeriksanya setiap config.Interval sampai ctx selesai atau
+// Close dipanggil. Error hanya dikembalikan jika pemeriksaan pertama gagal.
+func (c *Client) MonitorBalance(ctx context.Context, config BalanceMonitorConfig) (*BalanceMonitor, error) {
+	if config.Threshold < 0 || config.Hysteresis < 0 || config.DropPercent < 0 || config.DropPercent > 100 {
+		return nil, errors.New("invalid balance monitor config / konfigurasi monitor saldo tidak valid")
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultBalanceCheck
+	}
+	if config.Hysteresis == 0 {
+		config.Hysteresis = config.Threshold / 10
+	}
+	if config.DropWindow <= 0 {
+		config.DropWindow = DefaultDropWindow
+	}
+	m := &BalanceMonitor{
+		client: c,
+		config: config,
+		logger: config.Logger,
+		done:   make(chan struct{}),
+	}
+	if m.logger == nil {
+		m.logger = log.Default()
+	}
+	if err := m.Check(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	go m.run(ctx)
+	return m, nil
+}
+
+// Balance returns the saldo of the last successful check and when it was made.
+// Balance mengembalikan saldo dari pemeriksaan terakhir yang berhasil dan waktunya.
+func (m *BalanceMonitor) Balance() (int64, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.balance, m.checked
+}
+
+// Check fetches the saldo now and raises the alerts it calls for.
+// Check mengambil saldo sekarang dan memunculkan peringatan yang diperlukan.
+func (m *BalanceMonitor) Check(ctx context.Context) error {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	b, err := m.client.GetBalance(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	m.balance = b.Amount
+	m.checked = now
+	m.mu.Unlock()
+
+	if m.config.OnBalance != nil {
+		m.config.OnBalance(b.Amount)
+	}
+	for _, alert := range m.evaluate(b.Amount, now) {
+		if m.config.OnAlert != nil {
+			m.config.OnAlert(alert)
+		}
+	}
+	return nil
+}
+
+// Close stops the background checks and waits for a check in progress to finish.
+// Close menghentikan pemeriksaan latar belakang dan menunggu pemeriksaan yang sedang berjalan selesai.
+func (m *BalanceMonitor) Close() error {
+	m.closeOnce.Do(m.cancel)
+	<-m.done
+	return nil
+}
+
+// run checks the saldo every interval until ctx is done.
+// run memeriksa saldo setiap interval sampai ctx selesai.
+func (m *BalanceMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Check(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Printf("Error checking balance: %v", err)
+			}
+		}
+	}
+}
+
+// evaluate records amount and returns the alerts it raises. Called with checkMu held.
+// evaluate mencatat amount dan mengembalikan peringatan yang dimunculkannya. Dipanggil dengan checkMu terkunci.
+func (m *BalanceMonitor) evaluate(amount int64, now time.Time) []BalanceAlert {
+	var alerts []BalanceAlert
+
+	if m.config.Threshold > 0 {
+		switch {
+		case !m.low && amount < m.config.Threshold:
+			m.low = true
+			alerts = append(alerts, BalanceAlert{Kind: BalanceLow, Balance: amount, Previous: m.config.Threshold, At: now})
+		case m.low && amount >= m.config.Threshold+m.config.Hysteresis:
+			m.low = false
+			alerts = append(alerts, BalanceAlert{Kind: BalanceRecovered, Balance: amount, Previous: m.config.Threshold, At: now})
+		}
+	}
+
+	if m.config.DropPercent > 0 {
+		cutoff := now.Add(-m.config.DropWindow)
+		samples := m.samples[:0]
+		var peak int64
+		for _, s := range m.samples {
+			if s.at.Before(cutoff) {
+				continue
+			}
+			samples = append(samples, s)
+			peak = max(peak, s.amount)
+		}
+		m.samples = append(samples, balanceSample{amount: amount, at: now})
+
+		recent := !m.lastDrop.IsZero() && now.Sub(m.lastDrop) < m.config.DropWindow
+		if peak > 0 && !recent && float64(peak-amount)*100 >= m.config.DropPercent*float64(peak) {
+			m.lastDrop = now
+			alerts = append(alerts, BalanceAlert{Kind: BalanceDrop, Balance: amount, Previous: peak, At: now})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,109 @@
+package orderkuota_test
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestBalanceMonitor(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/balance": "balance.json"})
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	var alerts []orderkuota.BalanceAlert
+	var gauge []int64
+	m, err := c.MonitorBalance(ctx, orderkuota.BalanceMonitorConfig{
+		Interval:    time.Hour,
+		Threshold:   50000,
+		DropPercent: 50,
+		OnAlert:     func(a orderkuota.BalanceAlert) { alerts = append(alerts, a) },
+		OnBalance:   func(b int64) { gauge = append(gauge, b) },
+	})
+	if err != nil {
+		t.Fatalf("MonitorBalance: %v", err)
+	}
+	defer m.Close()
+
+	// Hysteresis of 5.000 by default: low once, recovered only at 55.000, then low again.
+	// The drop from 125.000 is raised once within the window.
+	tests := []struct {
+		fixture string
+		want    []orderkuota.BalanceAlert
+	}{
+		{"balance_low.json", []orderkuota.BalanceAlert{
+			{Kind: orderkuota.BalanceLow, Balance: 40000, Previous: 50000},
+			{Kind: orderkuota.BalanceDrop, Balance: 40000, Previous: 125000},
+		}},
+		{"balance_still_low.json", nil},
+		{"balance_hysteresis.json", nil},
+		{"balance_recovered.json", []orderkuota.BalanceAlert{{Kind: orderkuota.BalanceRecovered, Balance: 60000, Previous: 50000}}},
+		{"balance_lower.json", []orderkuota.BalanceAlert{{Kind: orderkuota.BalanceLow, Balance: 30000, Previous: 50000}}},
+	}
+	for _, tt := range tests {
+		alerts = nil
+		srv.setFixture("/balance", tt.fixture)
+		if err := m.Check(ctx); err != nil {
+			t.Fatalf("Check %s: %v", tt.fixture, err)
+		}
+		for i := range alerts {
+			if alerts[i].At.IsZero() {
+				t.Fatalf("alert %+v without a time", alerts[i])
+			}
+			alerts[i].At = time.Time{}
+		}
+		if !reflect.DeepEqual(alerts, tt.want) {
+			t.Fatalf("alerts after %s = %+v, want %+v", tt.fixture, alerts, tt.want)
+		}
+	}
+	if want := []int64{125000, 40000, 45000, 54000, 60000, 30000}; !reflect.DeepEqual(gauge, want) {
+		t.Fatalf("OnBalance got %v, want %v", gauge, want)
+	}
+	if balance, checked := m.Balance(); balance != 30000 || checked.IsZero() {
+		t.Fatalf("Balance = %d at %v, want the last check", balance, checked)
+	}
+}
+
+func TestBalanceMonitorFailures(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/balance": "balance.json"})
+	c := newTestClient(t, srv)
+	var logs syncBuffer
+	m, err := c.MonitorBalance(context.Background(), orderkuota.BalanceMonitorConfig{Interval: 10 * time.Millisecond, Logger: log.New(&logs, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Failed checks are logged and keep the last saldo
+	srv.setFixture("/balance", "401")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), "Error checking balance") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "Error checking balance") {
+		t.Fatalf("logs = %q, want the check failure", logs.String())
+	}
+	if balance, _ := m.Balance(); balance != 125000 {
+		t.Fatalf("Balance = %d after failures, want 125000", balance)
+	}
+
+	// Close stops the checks
+	m.Close()
+	m.Close()
+	n := srv.count("/balance")
+	time.Sleep(50 * time.Millisecond)
+	if got := srv.count("/balance"); got != n {
+		t.Fatalf("%d checks after Close", got-n)
+	}
+
+	if _, err := c.MonitorBalance(context.Background(), orderkuota.BalanceMonitorConfig{DropPercent: 150}); err == nil {
+		t.Fatal("MonitorBalance with a drop over 100% succeeded")
+	}
+	if _, err := c.MonitorBalance(context.Background(), orderkuota.BalanceMonitorConfig{Threshold: 1000}); err == nil {
+		t.Fatal("MonitorBalance with the gateway rejecting the token succeeded")
+	}
+}
@@ -0,0 +1,60 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestGetBalance(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    orderkuota.Balance
+		wantErr error
+	}{
+		{fixture: "balance.json", want: orderkuota.Balance{Amount: 125000, AccountName: "AutoFTbot Store", MemberID: "12345"}},
+		{fixture: "balance_number.json", want: orderkuota.Balance{Amount: 98500, AccountName: "AutoFTbot Store", MemberID: "OK12345"}},
+		{fixture: "balance_token_expired.json", wantErr: orderkuota.ErrUnauthorized},
+		{fixture: "401", wantErr: orderkuota.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/balance": tt.fixture})
+			c := newTestClient(t, srv)
+
+			balance, err := c.GetBalance(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBalance: %v", err)
+			}
+			if *balance != tt.want {
+				t.Fatalf("balance = %+v, want %+v", *balance, tt.want)
+			}
+			req := srv.received()[0]
+			if req.Params["auth_username"] != "user" || req.Params["auth_token"] != "token" {
+				t.Fatalf("credentials sent = %v", req.Params)
+			}
+		})
+	}
+}
+
+func TestGetBalanceAPIError(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/balance": "balance_error.json"})
+	c := newTestClient(t, srv)
+
+	_, err := c.GetBalance(context.Background())
+	var apiErr *orderkuota.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Server sedang sibuk" {
+		t.Fatalf("err = %v, want an APIError with the gateway message", err)
+	}
+	if errors.Is(err, orderkuota.ErrUnauthorized) {
+		t.Fatal("a busy gateway was reported as ErrUnauthorized")
+	}
+}
@@ -0,0 +1,222 @@
+package orderkuota
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// catalogColumns are the columns of ExportCatalog in output order; sell_price is added by WithSellPrices.
+// catalogColumns adalah kolom ExportCatalog sesuai urutan output; sell_price ditambahkan oleh WithSellPrices.
+var catalogColumns = []string{"code", "name", "category", "brand", "price", "status"}
+
+// CatalogDiff lists the differences between two catalogs, each ordered by product code.
+// CatalogDiff berisi perbedaan antara dua katalog, masing-masing berurutan sesuai kode produk.
+type CatalogDiff struct {
+	Added         []Product            // Products only in the new catalog / Produk yang hanya ada di katalog baru
+	Removed       []Product            // Products only in the old catalog / Produk yang hanya ada di katalog lama
+	PriceChanged  []PriceChange        // Products whose base price changed / Produk yang harga dasarnya berubah
+	StatusChanged []AvailabilityChange // Products whose status changed / Produk yang statusnya berubah
+}
+
+// Empty reports whether the catalogs are the same.
+// Empty melaporkan apakah kedua katalog sama.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.PriceChanged) == 0 && len(d.StatusChanged) == 0
+}
+
+// DiffCatalogs compares two snapshots of the price list, e.g. yesterday's export read back with
+// ImportCatalog and today's GetPriceList.
+// DiffCatalogs membandingkan dua snapshot daftar harga, misalnya ekspor kemarin yang dibaca ulang
+// dengan ImportCatalog dan GetPriceList hari ini.
+func DiffCatalogs(old, new []Product) CatalogDiff {
+	prev := make(map[string]Product, len(old))
+	for _, p := range old {
+		prev[p.Code] = p
+	}
+	next := make(map[string]Product, len(new))
+	for _, p := range new {
+		next[p.Code] = p
+	}
+
+	var d CatalogDiff
+	for _, p := range sortedProducts(new) {
+		o, ok := prev[p.Code]
+		if !ok {
+			d.Added = append(d.Added, p)
+			continue
+		}
+		if o.Price != p.Price {
+			d.PriceChanged = append(d.PriceChanged, PriceChange{Code: p.Code, OldPrice: o.Price, NewPrice: p.Price})
+		}
+		if o.Status != p.Status {
+			d.StatusChanged = append(d.StatusChanged, AvailabilityChange{Code: p.Code, OldStatus: o.Status, NewStatus: p.Status})
+		}
+	}
+	for _, p := range sortedProducts(old) {
+		if _, ok := next[p.Code]; !ok {
+			d.Removed = append(d.Removed, p)
+		}
+	}
+	return d
+}
+
+// CatalogExportOption customizes a single ExportCatalog call.
+// CatalogExportOption mengatur satu panggilan ExportCatalog.
+type CatalogExportOption func(*catalogExportOptions)
+
+type catalogExportOptions struct {
+	rules *PricingRules
+}
+
+// WithSellPrices adds a sell_price column computed with rules.
+// WithSellPrices menambahkan kolom sell_price yang dihitung dengan rules.
+func WithSellPrices(rules PricingRules) CatalogExportOption {
+	return func(o *catalogExportOptions) {
+		o.rules = &rules
+	}
+}
+
+// catalogRow is a product as exported, in column order.
+// catalogRow adalah produk seperti yang diekspor, sesuai urutan kolom.
+type catalogRow struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	Brand     string `json:"brand"`
+	Price     int64  `json:"price"`
+	Status    string `json:"status"`
+	SellPrice *int64 `json:"sell_price,omitempty"`
+}
+
+// ExportCatalog writes products to w as CSV or JSON, ordered by product code with the columns
+// code, name, category, brand, price and status, so exports of the same catalog are identical.
+// ExportCatalog menulis products ke w dalam format CSV atau JSON, berurutan sesuai kode produk dengan
+// kolom code, name, category, brand, price dan status, sehingga ekspor dari katalog yang sama identik.
+func ExportCatalog(w io.Writer, products []Product, format qris.ExportFormat, opts ...CatalogExportOption) error {
+	var o catalogExportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.rules != nil {
+		if err := o.rules.Validate(); err != nil {
+			return err
+		}
+	}
+
+	rows := make([]catalogRow, 0, len(products))
+	for _, p := range sortedProducts(products) {
+		row := catalogRow{Code: p.Code, Name: p.Name, Category: p.Category, Brand: p.Brand, Price: p.Price, Status: p.Status}
+		if o.rules != nil {
+			sell := o.rules.Apply(p)
+			row.SellPrice = &sell
+		}
+		rows = append(rows, row)
+	}
+
+	switch format {
+	case qris.ExportCSV, "":
+		return writeCatalogCSV(w, rows, o.rules != nil)
+	case qris.ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported export format %q / format ekspor %q tidak didukung", format, format)
+}
+
+func writeCatalogCSV(w io.Writer, rows []catalogRow, sell bool) error {
+	cw := csv.NewWriter(w)
+	header := catalogColumns
+	if sell {
+		header = append(header[:len(header):len(header)], "sell_price")
+	}
+	cw.Write(header)
+	for _, r := range rows {
+		record := []string{r.Code, r.Name, r.Category, r.Brand, strconv.FormatInt(r.Price, 10), r.Status}
+		if sell {
+			record = append(record, strconv.FormatInt(*r.SellPrice, 10))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+	}
+	return nil
+}
+
+// ImportCatalog reads back an export of ExportCatalog. Columns are matched by name and the
+// sell_price column is ignored.
+// ImportCatalog membaca kembali ekspor dari ExportCatalog. Kolom dicocokkan berdasarkan nama dan
+// kolom sell_price diabaikan.
+func ImportCatalog(r io.Reader, format qris.ExportFormat) ([]Product, error) {
+	switch format {
+	case qris.ExportCSV, "":
+		return readCatalogCSV(r)
+	case qris.ExportJSON:
+		var rows []catalogRow
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("failed to read catalog / gagal membaca katalog: %v", err)
+		}
+		products := make([]Product, len(rows))
+		for i, row := range rows {
+			products[i] = Product{Code: row.Code, Name: row.Name, Category: row.Category, Brand: row.Brand, Price: row.Price, Status: row.Status}
+		}
+		return products, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q / format ekspor %q tidak didukung", format, format)
+}
+
+func readCatalogCSV(r io.Reader) ([]Product, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog / gagal membaca katalog: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[0] {
+		index[name] = i
+	}
+	for _, name := range catalogColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing catalog column %q / kolom katalog %q tidak ada", name, name)
+		}
+	}
+
+	products := make([]Product, 0, len(records)-1)
+	for line, rec := range records[1:] {
+		price, err := strconv.ParseInt(rec[index["price"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price on row %d / harga tidak valid di baris %d: %v", line+2, line+2, err)
+		}
+		products = append(products, Product{
+			Code:     rec[index["code"]],
+			Name:     rec[index["name"]],
+			Category: rec[index["category"]],
+			Brand:    rec[index["brand"]],
+			Price:    price,
+			Status:   rec[index["status"]],
+		})
+	}
+	return products, nil
+}
+
+// sortedProducts returns a copy of products ordered by code.
+// sortedProducts mengembalikan salinan products berurutan sesuai kode.
+func sortedProducts(products []Product) []Product {
+	sorted := append([]Product(nil), products...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+	return sorted
+}
@@ -0,0 +1,177 @@
+package orderkuota_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// catalog is out of code order on purpose; exports and diffs must sort it.
+var catalog = []orderkuota.Product{
+	{Code: "S10", Name: "Telkomsel 10.000", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 10450, Status: orderkuota.ProductOpen},
+	{Code: "PLN20", Name: "Token PLN 20.000", Category: "pln", Brand: "PLN", Price: 20150, Status: orderkuota.ProductOpen},
+	{Code: "S5", Name: "Telkomsel 5.000, promo", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 5475, Status: orderkuota.ProductDisrupted},
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	next := []orderkuota.Product{
+		{Code: "X5", Name: "XL 5.000", Category: orderkuota.CategoryPulsa, Brand: "XL", Price: 5600, Status: orderkuota.ProductOpen},
+		{Code: "S5", Name: "Telkomsel 5.000, promo", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 5475, Status: orderkuota.ProductOpen},
+		{Code: "S10", Name: "Telkomsel 10.000", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 10500, Status: orderkuota.ProductClosed},
+		{Code: "A5", Name: "Axis 5.000", Category: orderkuota.CategoryPulsa, Brand: "Axis", Price: 5550, Status: orderkuota.ProductOpen},
+	}
+
+	d := orderkuota.DiffCatalogs(catalog, next)
+	if d.Empty() {
+		t.Fatal("Empty = true, want differences")
+	}
+	if got := productCodes(d.Added); !reflect.DeepEqual(got, []string{"A5", "X5"}) {
+		t.Errorf("Added = %v, want [A5 X5]", got)
+	}
+	if got := productCodes(d.Removed); !reflect.DeepEqual(got, []string{"PLN20"}) {
+		t.Errorf("Removed = %v, want [PLN20]", got)
+	}
+	wantPrice := []orderkuota.PriceChange{{Code: "S10", OldPrice: 10450, NewPrice: 10500}}
+	if !reflect.DeepEqual(d.PriceChanged, wantPrice) {
+		t.Errorf("PriceChanged = %+v, want %+v", d.PriceChanged, wantPrice)
+	}
+	wantStatus := []orderkuota.AvailabilityChange{
+		{Code: "S10", OldStatus: orderkuota.ProductOpen, NewStatus: orderkuota.ProductClosed},
+		{Code: "S5", OldStatus: orderkuota.ProductDisrupted, NewStatus: orderkuota.ProductOpen},
+	}
+	if !reflect.DeepEqual(d.StatusChanged, wantStatus) {
+		t.Errorf("StatusChanged = %+v, want %+v", d.StatusChanged, wantStatus)
+	}
+
+	reordered := []orderkuota.Product{catalog[2], catalog[0], catalog[1]}
+	if d := orderkuota.DiffCatalogs(catalog, reordered); !d.Empty() {
+		t.Errorf("reordered catalog diff = %+v, want empty", d)
+	}
+}
+
+func TestExportCatalogCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := orderkuota.ExportCatalog(&buf, catalog, qris.ExportCSV); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	want := "code,name,category,brand,price,status\n" +
+		"PLN20,Token PLN 20.000,pln,PLN,20150,open\n" +
+		"S10,Telkomsel 10.000,pulsa,Telkomsel,10450,open\n" +
+		"S5,\"Telkomsel 5.000, promo\",pulsa,Telkomsel,5475,gangguan\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("CSV export =\n%s\nwant\n%s", got, want)
+	}
+
+	var again bytes.Buffer
+	reordered := []orderkuota.Product{catalog[1], catalog[2], catalog[0]}
+	if err := orderkuota.ExportCatalog(&again, reordered, ""); err != nil {
+		t.Fatalf("ExportCatalog default format: %v", err)
+	}
+	if again.String() != want {
+		t.Errorf("default format export of reordered catalog differs:\n%s", again.String())
+	}
+}
+
+func TestExportCatalogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	rules := orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 500}, RoundTo: 100}
+	if err := orderkuota.ExportCatalog(&buf, catalog[:1], qris.ExportJSON, orderkuota.WithSellPrices(rules)); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	want := `[
+  {
+    "code": "S10",
+    "name": "Telkomsel 10.000",
+    "category": "pulsa",
+    "brand": "Telkomsel",
+    "price": 10450,
+    "status": "open",
+    "sell_price": 11000
+  }
+]
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("JSON export =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportCatalogSellPrices(t *testing.T) {
+	var buf bytes.Buffer
+	rules := orderkuota.PricingRules{
+		Default:    orderkuota.Markup{Flat: 500},
+		Categories: map[string]orderkuota.Markup{"pln": {Flat: 1500}},
+		RoundTo:    100,
+	}
+	if err := orderkuota.ExportCatalog(&buf, catalog, qris.ExportCSV, orderkuota.WithSellPrices(rules)); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	want := "code,name,category,brand,price,status,sell_price\n" +
+		"PLN20,Token PLN 20.000,pln,PLN,20150,open,21700\n" +
+		"S10,Telkomsel 10.000,pulsa,Telkomsel,10450,open,11000\n" +
+		"S5,\"Telkomsel 5.000, promo\",pulsa,Telkomsel,5475,gangguan,6000\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("CSV export =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	bad := orderkuota.PricingRules{Default: orderkuota.Markup{Flat: -1}}
+	if err := orderkuota.ExportCatalog(&buf, catalog, qris.ExportCSV, orderkuota.WithSellPrices(bad)); err == nil {
+		t.Fatal("ExportCatalog with negative markup returned nil error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("rejected export wrote %q", buf.String())
+	}
+}
+
+func TestExportCatalogUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := orderkuota.ExportCatalog(&buf, catalog, qris.ExportFormat("xml"))
+	if err == nil || !strings.Contains(err.Error(), "unsupported export format") {
+		t.Fatalf("ExportCatalog(xml) error = %v, want unsupported export format", err)
+	}
+	if _, err := orderkuota.ImportCatalog(&buf, qris.ExportFormat("xml")); err == nil {
+		t.Fatal("ImportCatalog(xml) returned nil error")
+	}
+}
+
+func TestImportCatalogRoundTrip(t *testing.T) {
+	rules := orderkuota.PricingRules{Default: orderkuota.Markup{Percent: 5}}
+	for _, format := range []qris.ExportFormat{qris.ExportCSV, qris.ExportJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := orderkuota.ExportCatalog(&buf, catalog, format, orderkuota.WithSellPrices(rules)); err != nil {
+				t.Fatalf("ExportCatalog: %v", err)
+			}
+			got, err := orderkuota.ImportCatalog(&buf, format)
+			if err != nil {
+				t.Fatalf("ImportCatalog: %v", err)
+			}
+			if d := orderkuota.DiffCatalogs(catalog, got); !d.Empty() {
+				t.Fatalf("round trip diff = %+v, want empty", d)
+			}
+			if !reflect.DeepEqual(got[1], catalog[0]) {
+				t.Errorf("imported %+v, want %+v", got[1], catalog[0])
+			}
+		})
+	}
+}
+
+func TestImportCatalogMissingColumn(t *testing.T) {
+	csv := "code,name,category,brand,price\nS5,Telkomsel 5.000,pulsa,Telkomsel,5475\n"
+	_, err := orderkuota.ImportCatalog(strings.NewReader(csv), qris.ExportCSV)
+	if err == nil || !strings.Contains(err.Error(), `"status"`) {
+		t.Fatalf("ImportCatalog error = %v, want missing status column", err)
+	}
+}
+
+func productCodes(products []orderkuota.Product) []string {
+	codes := make([]string, len(products))
+	for i, p := range products {
+		codes[i] = p.Code
+	}
+	return codes
+}
@@ -0,0 +1,266 @@
+package orderkuota
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCatalogRefresh is how often a CatalogWatcher refreshes the price list when Interval is not set.
+// DefaultCatalogRefresh adalah seberapa sering CatalogWatcher memperbarui daftar harga jika Interval tidak diatur.
+const DefaultCatalogRefresh = 5 * time.Minute
+
+// DefaultCatalogEventBuffer is the number of events buffered by CatalogWatcher.Events.
+// DefaultCatalogEventBuffer adalah jumlah event yang di-buffer oleh CatalogWatcher.Events.
+const DefaultCatalogEventBuffer = 256
+
+// PriceChange reports a product whose price changed between two refreshes.
+// PriceChange melaporkan produk yang harganya berubah di antara dua pembaruan.
+type PriceChange struct {
+	Code     string // Product code / Kode produk
+	OldPrice int64  // Previous price in rupiah / Harga sebelumnya dalam rupiah
+	NewPrice int64  // Current price in rupiah / Harga saat ini dalam rupiah
+}
+
+// AvailabilityChange reports a product whose status changed between two refreshes. OldStatus is
+// empty for a product new to the catalog and NewStatus is empty for one that left it.
+// AvailabilityChange melaporkan produk yang statusnya berubah di antara dua pembaruan. OldStatus
+// kosong untuk produk baru di katalog dan NewStatus kosong untuk produk yang keluar dari katalog.
+type AvailabilityChange struct {
+	Code      string // Product code / Kode produk
+	OldStatus string // Previous Product status / Status Product sebelumnya
+	NewStatus string // Current Product status / Status Product saat ini
+}
+
+// CatalogEvent is one change found by a CatalogWatcher; exactly one of its fields is set.
+// CatalogEvent adalah satu perubahan yang ditemukan CatalogWatcher; tepat satu field-nya terisi.
+type CatalogEvent struct {
+	Price        *PriceChange
+	Availability *AvailabilityChange
+}
+
+// CatalogWatcherConfig configures WatchCatalog.
+// CatalogWatcherConfig mengatur WatchCatalog.
+type CatalogWatcherConfig struct {
+	Interval time.Duration      // Time between refreshes, default DefaultCatalogRefresh / Jeda antar pembaruan, default DefaultCatalogRefresh
+	OnChange func(CatalogEvent) // Called for every change, from the refresh goroutine / Dipanggil untuk setiap perubahan, dari goroutine pembaruan
+	Logger   *log.Logger        // Receives refresh failures, default log.Default() / Menerima kegagalan pembaruan, default log.Default()
+}
+
+// CatalogWatcher keeps a snapshot of the price list current by refreshing it in the background
+// and reports the differences between consecutive snapshots.
+// CatalogWatcher menjaga snapshot daftar harga tetap terkini dengan memperbaruinya di latar belakang
+// dan melaporkan perbedaan antara snapshot yang berurutan.
+//
+// A failed refresh is logged and the last good snapshot keeps being served.
+// Pembaruan yang gagal dicatat di log dan snapshot terakhir yang berhasil tetap dipakai.
+type CatalogWatcher struct {
+	client *Client
+	config CatalogWatcherConfig
+	logger *log.Logger
+
+	mu        sync.RWMutex
+	products  map[string]Product
+	refreshed time.Time
+	dropped   uint64
+
+	refreshMu sync.Mutex // Serializes refreshes so diffs apply in order / Mengurutkan pembaruan agar diff diterapkan berurutan
+	stopped   bool       // Events is closed, guarded by refreshMu / Events sudah ditutup, dijaga oleh refreshMu
+	events    chan CatalogEvent
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// WatchCatalog fetches the price list and keeps refreshing it every config.Interval until ctx is done
+// or Stop is called. It returns an error only when the first fetch fails.
+// WatchCatalog mengambil daftar harga dan terus memperbaruinya setiap config.Interval sampai ctx selesai
+// atau Stop dipanggil. Error hanya dikembalikan jika pengambilan pertama gagal.
+//
+// Each refresh also replaces the catalog cached by GetPriceList.
+// Setiap pembaruan juga mengganti katalog yang disimpan oleh GetPriceList.
+func (c *Client) WatchCatalog(ctx context.Context, config CatalogWatcherConfig) (*CatalogWatcher, error) {
+	if config.Interval <= 0 {
+		config.Interval = DefaultCatalogRefresh
+	}
+	w := &CatalogWatcher{
+		client: c,
+		config: config,
+		logger: config.Logger,
+		events: make(chan CatalogEvent, DefaultCatalogEventBuffer),
+		done:   make(chan struct{}),
+	}
+	if w.logger == nil {
+		w.logger = log.Default()
+	}
+	if err := w.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.run(ctx)
+	return w, nil
+}
+
+// Get returns the product with code from the current snapshot, without waiting for a refresh.
+// Get mengembalikan produk dengan code dari snapshot saat ini, tanpa menunggu pembaruan.
+func (w *CatalogWatcher) Get(code string) (Product, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	p, ok := w.products[code]
+	return p, ok
+}
+
+// Products returns the current snapshot ordered by product code.
+// Products mengembalikan snapshot saat ini berurutan sesuai kode produk.
+func (w *CatalogWatcher) Products() []Product {
+	w.mu.RLock()
+	products := make([]Product, 0, len(w.products))
+	for _, p := range w.products {
+		products = append(products, p)
+	}
+	w.mu.RUnlock()
+
+	sort.Slice(products, func(i, j int) bool { return products[i].Code < products[j].Code })
+	return products
+}
+
+// RefreshedAt returns when the current snapshot was fetched.
+// RefreshedAt mengembalikan waktu snapshot saat ini diambil.
+func (w *CatalogWatcher) RefreshedAt() time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.refreshed
+}
+
+// Events returns the changes found by every refresh, closed after Stop. Events are dropped when
+// the buffer (DefaultCatalogEventBuffer) is full; see DroppedEvents.
+// Events mengembalikan perubahan yang ditemukan setiap pembaruan, ditutup setelah Stop. Event dibuang
+// jika buffer (DefaultCatalogEventBuffer) penuh; lihat DroppedEvents.
+func (w *CatalogWatcher) Events() <-chan CatalogEvent {
+	return w.events
+}
+
+// DroppedEvents returns how many events were dropped because the Events buffer was full.
+// DroppedEvents mengembalikan jumlah event yang dibuang karena buffer Events penuh.
+func (w *CatalogWatcher) DroppedEvents() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.dropped
+}
+
+// Refresh fetches the price list now and reports its changes. On error the snapshot is kept.
+// Refresh mengambil daftar harga sekarang dan melaporkan perubahannya. Jika error, snapshot dipertahankan.
+func (w *CatalogWatcher) Refresh(ctx context.Context) error {
+	w.refreshMu.Lock()
+	defer w.refreshMu.Unlock()
+
+	products, err := w.client.fetchCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	w.client.catalog.mu.Lock()
+	w.client.catalog.products = products
+	w.client.catalog.fetchedAt = now
+	w.client.catalog.mu.Unlock()
+
+	next := make(map[string]Product, len(products))
+	for _, p := range products {
+		next[p.Code] = p
+	}
+
+	w.mu.Lock()
+	prev := w.products
+	w.products = next
+	w.refreshed = now
+	w.mu.Unlock()
+
+	if prev == nil || w.stopped {
+		return nil
+	}
+	for _, ev := range diffCatalog(prev, next) {
+		w.emit(ev)
+	}
+	return nil
+}
+
+// Stop stops the background refresh and closes Events.
+// Stop menghentikan pembaruan latar belakang dan menutup Events.
+func (w *CatalogWatcher) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// run refreshes the snapshot every interval until ctx is done.
+// run memperbarui snapshot setiap interval sampai ctx selesai.
+func (w *CatalogWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	defer func() {
+		w.refreshMu.Lock()
+		w.stopped = true
+		close(w.events)
+		w.refreshMu.Unlock()
+	}()
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Refresh(ctx); err != nil && ctx.Err() == nil {
+				w.logger.Printf("Error refreshing price list, keeping the snapshot of %s: %v", w.RefreshedAt().Format(time.RFC3339), err)
+			}
+		}
+	}
+}
+
+// emit hands ev to OnChange and queues it on Events without blocking.
+// emit meneruskan ev ke OnChange dan mengantrekannya di Events tanpa memblokir.
+func (w *CatalogWatcher) emit(ev CatalogEvent) {
+	if w.config.OnChange != nil {
+		w.config.OnChange(ev)
+	}
+	select {
+	case w.events <- ev:
+	default:
+		w.mu.Lock()
+		w.dropped++
+		w.mu.Unlock()
+	}
+}
+
+// diffCatalog returns the changes from prev to next ordered by product code.
+// diffCatalog mengembalikan perubahan dari prev ke next berurutan sesuai kode produk.
+func diffCatalog(prev, next map[string]Product) []CatalogEvent {
+	codes := make([]string, 0, len(next))
+	for code := range next {
+		codes = append(codes, code)
+	}
+	for code := range prev {
+		if _, ok := next[code]; !ok {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	var events []CatalogEvent
+	for _, code := range codes {
+		old, hadOld := prev[code]
+		cur, hasCur := next[code]
+		if hadOld && hasCur && old.Price != cur.Price {
+			events = append(events, CatalogEvent{Price: &PriceChange{Code: code, OldPrice: old.Price, NewPrice: cur.Price}})
+		}
+		if old.Status != cur.Status || hadOld != hasCur {
+			events = append(events, CatalogEvent{Availability: &AvailabilityChange{Code: code, OldStatus: old.Status, NewStatus: cur.Status}})
+		}
+	}
+	return events
+}
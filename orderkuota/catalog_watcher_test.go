@@ -0,0 +1,106 @@
+package orderkuota_test
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestCatalogWatcher(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pricelist": "pricelist.json"})
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var changes []orderkuota.CatalogEvent
+	w, err := c.WatchCatalog(ctx, orderkuota.CatalogWatcherConfig{
+		Interval: time.Hour,
+		OnChange: func(ev orderkuota.CatalogEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, ev)
+		},
+	})
+	if err != nil {
+		t.Fatalf("WatchCatalog: %v", err)
+	}
+	defer w.Stop()
+	if p, ok := w.Get("S10"); !ok || p.Price != 10425 {
+		t.Fatalf("Get(S10) = %+v, %v", p, ok)
+	}
+
+	// A refresh reports the differences ordered by product code
+	srv.setFixture("/pricelist", "pricelist_changed.json")
+	if err := w.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	want := []orderkuota.CatalogEvent{
+		{Availability: &orderkuota.AvailabilityChange{Code: "ML86", NewStatus: orderkuota.ProductOpen}},
+		{Availability: &orderkuota.AvailabilityChange{Code: "PLN20", OldStatus: orderkuota.ProductDisrupted}},
+		{Availability: &orderkuota.AvailabilityChange{Code: "S10", OldStatus: orderkuota.ProductOpen, NewStatus: orderkuota.ProductClosed}},
+		{Price: &orderkuota.PriceChange{Code: "S5", OldPrice: 5475, NewPrice: 5600}},
+	}
+	var events []orderkuota.CatalogEvent
+	for len(events) < len(want) {
+		select {
+		case ev := <-w.Events():
+			events = append(events, ev)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got events %+v, want %d", events, len(want))
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, want) || !reflect.DeepEqual(changes, want) {
+		t.Fatalf("events %+v, OnChange %+v, want %+v", events, changes, want)
+	}
+
+	// The snapshot and the GetPriceList cache follow the refresh
+	if p, ok := w.Get("S5"); !ok || p.Price != 5600 {
+		t.Fatalf("Get(S5) = %+v, %v, want the new price", p, ok)
+	}
+	if _, ok := w.Get("PLN20"); ok {
+		t.Fatal("Get(PLN20) found a product that left the catalog")
+	}
+	requests := srv.count("/pricelist")
+	if products, err := c.GetPriceList(ctx, orderkuota.ProductFilter{}); err != nil || len(products) != 3 || srv.count("/pricelist") != requests {
+		t.Fatalf("GetPriceList = %d products, %v, want the refreshed catalog from the cache", len(products), err)
+	}
+}
+
+func TestCatalogWatcherRefreshFailure(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pricelist": "pricelist.json"})
+	var logs syncBuffer
+	w, err := newTestClient(t, srv).WatchCatalog(context.Background(), orderkuota.CatalogWatcherConfig{
+		Interval: 10 * time.Millisecond,
+		Logger:   log.New(&logs, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed := w.RefreshedAt()
+
+	// Failed refreshes are logged and the last good snapshot is kept
+	srv.setFixture("/pricelist", "401")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), "Error refreshing price list") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "Error refreshing price list") {
+		t.Fatalf("logs = %q, want the refresh failure", logs.String())
+	}
+	if p, ok := w.Get("S5"); !ok || p.Price != 5475 || !w.RefreshedAt().Equal(refreshed) {
+		t.Fatalf("Get(S5) = %+v, %v after a failure, want the last snapshot", p, ok)
+	}
+
+	w.Stop()
+	if _, ok := <-w.Events(); ok {
+		t.Fatal("Events not closed after Stop")
+	}
+}
@@ -0,0 +1,421 @@
+// Package orderkuota is a client for the OrderKuota reseller API: balance, products, and purchases.
+// Package orderkuota adalah client untuk API reseller OrderKuota: saldo, produk, dan pembelian.
+package orderkuota
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// DefaultBaseURL is the OrderKuota app API.
+// DefaultBaseURL adalah API aplikasi OrderKuota.
+const DefaultBaseURL = auth.DefaultBaseURL
+
+// DefaultRequestTimeout bounds a single request when WithRequestTimeout is not given.
+// DefaultRequestTimeout membatasi satu request jika WithRequestTimeout tidak diberikan.
+const DefaultRequestTimeout = qris.DefaultRequestTimeout
+
+// ErrGatewayTimeout is wrapped by errors of requests that ran out of time.
+// ErrGatewayTimeout dibungkus oleh error request yang kehabisan waktu.
+var ErrGatewayTimeout = qris.ErrGatewayTimeout
+
+// ErrUnauthorized is returned when the gateway rejects the credentials; log in again to continue.
+// ErrUnauthorized dikembalikan saat gateway menolak kredensial; login ulang untuk melanjutkan.
+var ErrUnauthorized = errors.New("credentials rejected, please log in again / kredensial ditolak, silakan login ulang")
+
+// Client calls the OrderKuota API with one account's credentials.
+// Client memanggil API OrderKuota dengan kredensial satu akun.
+//
+// A Client is safe for concurrent use by multiple goroutines.
+// Client aman dipakai bersamaan oleh banyak goroutine.
+type Client struct {
+	tokens  TokenSource
+	baseURL string
+	client  *http.Client
+	loc     *time.Location
+	timeout time.Duration
+
+	userAgent    string
+	transport    transport.Config
+	customClient bool
+
+	catalogTTL time.Duration
+	catalog    catalogCache
+
+	requireInquiry bool
+	inquiries      inquiryLog
+
+	idempotency    IdempotencyStore
+	idempotencyTTL time.Duration
+	purchases      purchaseCalls
+	attempts       attemptLog
+
+	sn                  *SNExtractor
+	destinationPolicies []DestinationPolicy
+
+	interceptors []transport.Interceptor
+	signer       *Signer
+}
+
+// Option configures a Client.
+// Option mengatur Client.
+type Option func(*Client)
+
+// WithBaseURL overrides DefaultBaseURL, for example to point at a test server.
+// WithBaseURL mengganti DefaultBaseURL, misalnya untuk mengarah ke server tes.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		if url != "" {
+			c.baseURL = strings.TrimRight(url, "/")
+		}
+	}
+}
+
+// WithHTTPClient overrides the HTTP client. It cannot be combined with WithProxy, WithTLSConfig, or WithRootCAs.
+// WithHTTPClient mengganti HTTP client. Tidak dapat digabung dengan WithProxy, WithTLSConfig, atau WithRootCAs.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		if client != nil {
+			c.client = client
+			c.customClient = true
+		}
+	}
+}
+
+// WithProxy sends every request through an egress proxy (http, https, or socks5 URL).
+// WithProxy mengirim setiap request melalui proxy keluar (URL http, https, atau socks5).
+func WithProxy(url string) Option {
+	return func(c *Client) {
+		c.transport.ProxyURL = url
+	}
+}
+
+// WithTLSConfig uses custom TLS settings, for example to pin the gateway certificate.
+// WithTLSConfig memakai pengaturan TLS khusus, misalnya untuk pinning sertifikat gateway.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.transport.TLSConfig = config
+	}
+}
+
+// WithRootCAs trusts only the given root certificates.
+// WithRootCAs hanya mempercayai sertifikat root yang diberikan.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		c.transport.RootCAs = pool
+	}
+}
+
+// WithUserAgent overrides the User-Agent header, default qris.DefaultUserAgent.
+// WithUserAgent mengganti header User-Agent, default qris.DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+// WithInterceptor wraps every request with interceptors, including the retry after a relogin,
+// so each attempt passes through them. Interceptors run in the order they are given across all
+// WithInterceptor options, the first one being the outermost; see qris.HeaderInterceptor and
+// qris.RequestIDInterceptor for built-in ones.
+// WithInterceptor membungkus setiap request dengan interceptors, termasuk pengulangan setelah login ulang,
+// sehingga setiap percobaan melewatinya. Interceptor dijalankan sesuai urutan pemberiannya di semua opsi
+// WithInterceptor, yang pertama adalah yang paling luar; lihat qris.HeaderInterceptor dan
+// qris.RequestIDInterceptor untuk interceptor bawaan.
+func WithInterceptor(interceptors ...qris.Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithRequestTimeout limits every request, default DefaultRequestTimeout. The limit is applied
+// through the request context, so a shared HTTP client is never modified.
+// WithRequestTimeout membatasi setiap request, default DefaultRequestTimeout. Batas diterapkan
+// melalui context request, sehingga HTTP client bersama tidak pernah diubah.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithLocation sets the timezone of gateway dates, default qris.WIB.
+// WithLocation mengatur zona waktu tanggal gateway, default qris.WIB.
+func WithLocation(loc *time.Location) Option {
+	return func(c *Client) {
+		if loc != nil {
+			c.loc = loc
+		}
+	}
+}
+
+// NewClient creates a Client for the account identified by creds.
+// creds may be left empty when WithTokenSource is given.
+// NewClient membuat Client untuk akun yang diidentifikasi oleh creds.
+// creds boleh dikosongkan jika WithTokenSource diberikan.
+func NewClient(creds auth.Credentials, opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		loc:     qris.WIB,
+		timeout: DefaultRequestTimeout,
+
+		userAgent: qris.DefaultUserAgent,
+
+		catalogTTL: DefaultCatalogTTL,
+
+		sn: NewSNExtractor(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.customClient && !c.transport.IsZero() {
+		return nil, errors.New("WithHTTPClient cannot be combined with transport options / WithHTTPClient tidak dapat digabung dengan opsi transport")
+	}
+	if !c.customClient {
+		client, err := transport.NewClient(c.transport)
+		if err != nil {
+			return nil, err
+		}
+		c.client = client
+	}
+
+	if c.tokens == nil {
+		if creds.Username == "" || creds.Token == "" {
+			return nil, errors.New("username and token must be filled / username dan token harus diisi")
+		}
+		c.tokens = staticTokenSource{creds: creds}
+	}
+	return c, nil
+}
+
+// do posts params plus the credentials to path and decodes the results of a successful response into out.
+// When the token source can refresh rejected credentials, a request failing with ErrUnauthorized is retried once.
+// do mengirim params beserta kredensial ke path dan men-decode results dari response yang berhasil ke out.
+// Jika token source dapat memperbarui kredensial yang ditolak, request yang gagal dengan ErrUnauthorized diulang sekali.
+func (c *Client) do(ctx context.Context, path string, params map[string]string, out interface{}) error {
+	if _, ok := signedEndpoints[path]; ok {
+		if c.signer == nil {
+			return fmt.Errorf("%s requires a signature, use WithSigner / %s memerlukan tanda tangan, gunakan WithSigner", path, path)
+		}
+		params = c.signer.sign(path, params)
+	}
+
+	creds, err := c.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials / gagal mendapatkan kredensial: %w", err)
+	}
+
+	err = c.send(ctx, creds, path, params, out)
+	r, ok := c.tokens.(refresher)
+	if !ok || !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	if creds, err = r.refresh(ctx, creds); err != nil {
+		return fmt.Errorf("%w: relogin failed / login ulang gagal: %v", ErrUnauthorized, err)
+	}
+	return c.send(ctx, creds, path, params, out)
+}
+
+// send performs a single request with creds.
+// send menjalankan satu request dengan creds.
+func (c *Client) send(ctx context.Context, creds auth.Credentials, path string, params map[string]string, out interface{}) error {
+	requestBody := map[string]string{
+		"auth_username": creds.Username,
+		"auth_token":    creds.Token,
+	}
+	for k, v := range params {
+		requestBody[k] = v
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body / gagal marshal request body: %v", err)
+	}
+
+	timeout := c.timeout
+	if override, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok && override > 0 {
+		timeout = override
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", c.baseURL+path, strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return fmt.Errorf("failed to create request / gagal membuat request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := transport.Chain(c.client.Do, c.interceptors)(req)
+	if err != nil {
+		return timeoutError(ctx, reqCtx, fmt.Errorf("failed to send request / gagal mengirim request: %v", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return ErrUnauthorized
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return timeoutError(ctx, reqCtx, fmt.Errorf("failed to read response / gagal membaca response: %v", err))
+	}
+
+	var response struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected gateway status %d / status gateway tidak terduga %d", resp.StatusCode, resp.StatusCode)
+		}
+		return fmt.Errorf("failed to parse response / gagal parse response: %v", err)
+	}
+
+	if !response.Success {
+		if isUnauthorized(response.Message) {
+			return fmt.Errorf("%w: %s", ErrUnauthorized, response.Message)
+		}
+		return &APIError{Message: response.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(response.Results, out); err != nil {
+		return fmt.Errorf("failed to parse results / gagal parse results: %v", err)
+	}
+	return nil
+}
+
+type requestTimeoutKey struct{}
+
+// withRequestTimeout overrides the client's request timeout for requests made with ctx.
+// withRequestTimeout mengganti batas waktu request client untuk request yang memakai ctx.
+func withRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, d)
+}
+
+// timeoutError wraps err in ErrGatewayTimeout when the request deadline, not the caller, stopped it.
+// timeoutError membungkus err dengan ErrGatewayTimeout jika yang menghentikannya adalah deadline request, bukan pemanggil.
+func timeoutError(ctx, reqCtx context.Context, err error) error {
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("%w: %v", ErrGatewayTimeout, err)
+	}
+	return err
+}
+
+// isUnauthorized reports whether a failure message means the token was rejected.
+// isUnauthorized melaporkan apakah pesan kegagalan berarti token ditolak.
+func isUnauthorized(message string) bool {
+	m := strings.ToLower(message)
+	if !strings.Contains(m, "token") && !strings.Contains(m, "login") && !strings.Contains(m, "sesi") && !strings.Contains(m, "session") {
+		return false
+	}
+	for _, s := range []string{"expired", "kadaluarsa", "kedaluwarsa", "invalid", "tidak valid", "silahkan login", "silakan login", "login ulang", "berakhir"} {
+		if strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIError is a failure reported by the gateway that has no more specific error.
+// APIError adalah kegagalan yang dilaporkan gateway dan tidak memiliki error yang lebih spesifik.
+type APIError struct {
+	Message string // Gateway message / Pesan dari gateway
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return "request failed / request gagal"
+	}
+	return "gateway error / error gateway: " + e.Message
+}
+
+// amount decodes a rupiah amount sent either as a JSON string or a JSON number.
+// Unparsable amounts decode as 0 so a single bad field never fails the whole response.
+// amount men-decode nominal rupiah yang dikirim sebagai string JSON atau angka JSON.
+// Nominal yang tidak valid di-decode sebagai 0 agar satu field rusak tidak menggagalkan seluruh response.
+type amount int64
+
+func (a *amount) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "null" {
+		*a = 0
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		// Amounts are sometimes formatted as "Rp 125.000"
+		s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "Rp"))
+		if isGrouped(s) {
+			s = strings.ReplaceAll(s, ".", "")
+		}
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*a = amount(n)
+		return nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		*a = amount(f)
+		return nil
+	}
+	*a = 0
+	return nil
+}
+
+// isGrouped reports whether s uses dots as thousand separators, as in "125.000".
+// isGrouped melaporkan apakah s memakai titik sebagai pemisah ribuan, seperti "125.000".
+func isGrouped(s string) bool {
+	groups := strings.Split(s, ".")
+	if len(groups) < 2 || len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// text decodes a value sent either as a JSON string or a JSON number, such as numeric IDs.
+// text men-decode nilai yang dikirim sebagai string JSON atau angka JSON, seperti ID numerik.
+type text string
+
+func (t *text) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "null" {
+		*t = ""
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	*t = text(s)
+	return nil
+}
@@ -0,0 +1,109 @@
+package orderkuota_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// newProxy starts a forward proxy that records the URL of every request it relays.
+func newProxy(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var urls []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		urls = append(urls, r.URL.String())
+		mu.Unlock()
+
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), urls...)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/balance": "balance.json"})
+	proxy, relayed := newProxy(t)
+	c := newTestClient(t, srv, orderkuota.WithProxy(proxy.URL))
+
+	if _, err := c.GetBalance(context.Background()); err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	urls := relayed()
+	if len(urls) != 1 || urls[0] != srv.URL+"/balance" {
+		t.Fatalf("proxy relayed %q, want %s/balance", urls, srv.URL)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []orderkuota.Option
+		want string
+	}{
+		{"default", nil, qris.DefaultUserAgent},
+		{"custom", []orderkuota.Option{orderkuota.WithUserAgent("toko-bot/2.0")}, "toko-bot/2.0"},
+		{"empty keeps default", []orderkuota.Option{orderkuota.WithUserAgent("")}, qris.DefaultUserAgent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/balance": "balance.json"})
+			c := newTestClient(t, srv, tt.opts...)
+			if _, err := c.GetBalance(context.Background()); err != nil {
+				t.Fatalf("GetBalance: %v", err)
+			}
+			if got := srv.received()[0].Header.Get("User-Agent"); got != tt.want {
+				t.Fatalf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if !strings.HasPrefix(qris.DefaultUserAgent, "orderkuota-go/") {
+		t.Fatalf("DefaultUserAgent = %q", qris.DefaultUserAgent)
+	}
+}
+
+func TestNewClientTransportOptions(t *testing.T) {
+	creds := auth.Credentials{Username: "user", Token: "token"}
+	tests := []struct {
+		name    string
+		opts    []orderkuota.Option
+		wantErr bool
+	}{
+		{"proxy", []orderkuota.Option{orderkuota.WithProxy("http://proxy.local:3128")}, false},
+		{"socks5 proxy", []orderkuota.Option{orderkuota.WithProxy("socks5://proxy.local:1080")}, false},
+		{"invalid proxy", []orderkuota.Option{orderkuota.WithProxy("proxy.local")}, true},
+		{"custom client with proxy", []orderkuota.Option{orderkuota.WithHTTPClient(&http.Client{}), orderkuota.WithProxy("http://proxy.local:3128")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := orderkuota.NewClient(creds, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
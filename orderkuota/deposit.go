@@ -0,0 +1,194 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/skip2/go-qrcode"
+)
+
+// DepositMethod is how a deposit is paid.
+// DepositMethod adalah cara pembayaran deposit.
+type DepositMethod string
+
+// Supported deposit methods.
+// Metode deposit yang didukung.
+const (
+	DepositQRIS DepositMethod = "qris"
+)
+
+// DepositStatus is the state of a deposit.
+// DepositStatus adalah status sebuah deposit.
+type DepositStatus string
+
+// Deposit statuses. Everything except pending is terminal.
+// Status deposit. Semua selain pending adalah status akhir.
+const (
+	DepositPending   DepositStatus = "pending"
+	DepositPaid      DepositStatus = "paid"
+	DepositExpired   DepositStatus = "expired"
+	DepositCancelled DepositStatus = "cancelled"
+)
+
+// parseDepositStatus maps the gateway's status spellings to a DepositStatus.
+// parseDepositStatus memetakan berbagai penulisan status gateway ke DepositStatus.
+func parseDepositStatus(s string) DepositStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "paid", "success", "sukses", "berhasil", "lunas":
+		return DepositPaid
+	case "expired", "kadaluarsa", "kedaluwarsa":
+		return DepositExpired
+	case "cancelled", "canceled", "batal", "dibatalkan":
+		return DepositCancelled
+	}
+	return DepositPending
+}
+
+// Deposit errors returned by WaitForDeposit.
+// Error deposit yang dikembalikan oleh WaitForDeposit.
+var (
+	ErrDepositExpired   = errors.New("deposit expired / deposit kedaluwarsa")
+	ErrDepositCancelled = errors.New("deposit cancelled / deposit dibatalkan")
+)
+
+// Deposit is a saldo top-up.
+// Deposit adalah pengisian saldo.
+type Deposit struct {
+	ID             string        // Deposit ID / ID deposit
+	Method         DepositMethod // Payment method / Metode pembayaran
+	Amount         int64         // Requested amount / Nominal yang diminta
+	TransferAmount int64         // Exact amount to pay, including the unique suffix / Nominal persis yang harus dibayar, termasuk kode unik
+	QRString       string        // QRIS payload for DepositQRIS / Payload QRIS untuk DepositQRIS
+	Status         DepositStatus // Current status / Status saat ini
+	ExpiresAt      time.Time     // Expiry time, zero if unknown / Waktu kedaluwarsa, nol jika tidak diketahui
+}
+
+// QRCode renders QRString with the same settings as qris.QRIS.GenerateQRCode.
+// QRCode me-render QRString dengan pengaturan yang sama seperti qris.QRIS.GenerateQRCode.
+func (d *Deposit) QRCode() (*qrcode.QRCode, error) {
+	if d.QRString == "" {
+		return nil, errors.New("deposit has no QRIS payload / deposit tidak memiliki payload QRIS")
+	}
+
+	return qris.NewQRCode(d.QRString, qrcode.Low)
+}
+
+// depositRow is the gateway form of a Deposit.
+// depositRow adalah bentuk Deposit dari gateway.
+type depositRow struct {
+	ID             text   `json:"deposit_id"`
+	Method         string `json:"method"`
+	Amount         amount `json:"amount"`
+	TransferAmount amount `json:"total_transfer"`
+	QRString       string `json:"qris"`
+	Status         text   `json:"status"`
+	ExpiresAt      string `json:"expired_at"`
+}
+
+func (r depositRow) deposit(loc *time.Location) *Deposit {
+	expiresAt, _ := time.ParseInLocation(dateLayout, r.ExpiresAt, loc)
+	d := &Deposit{
+		ID:             string(r.ID),
+		Method:         DepositMethod(strings.ToLower(r.Method)),
+		Amount:         int64(r.Amount),
+		TransferAmount: int64(r.TransferAmount),
+		QRString:       r.QRString,
+		Status:         parseDepositStatus(string(r.Status)),
+		ExpiresAt:      expiresAt,
+	}
+	if d.TransferAmount == 0 {
+		d.TransferAmount = d.Amount
+	}
+	return d
+}
+
+// CreateDeposit requests a saldo top-up. Pay exactly TransferAmount before ExpiresAt.
+// CreateDeposit meminta pengisian saldo. Bayar persis TransferAmount sebelum ExpiresAt.
+func (c *Client) CreateDeposit(ctx context.Context, amount int64, method DepositMethod) (*Deposit, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be greater than 0 / nominal harus lebih besar dari 0")
+	}
+	if method != DepositQRIS {
+		return nil, fmt.Errorf("unsupported deposit method %q / metode deposit %q tidak didukung", method, method)
+	}
+
+	var row depositRow
+	if err := c.do(ctx, "/deposit", map[string]string{
+		"amount": strconv.FormatInt(amount, 10),
+		"method": string(method),
+	}, &row); err != nil {
+		return nil, err
+	}
+
+	d := row.deposit(c.loc)
+	if d.Method == "" {
+		d.Method = method
+	}
+	if d.Amount == 0 {
+		d.Amount = amount
+	}
+	return d, nil
+}
+
+// GetDepositStatus returns the current state of a deposit.
+// GetDepositStatus mengembalikan status terkini sebuah deposit.
+func (c *Client) GetDepositStatus(ctx context.Context, depositID string) (*Deposit, error) {
+	if depositID == "" {
+		return nil, errors.New("depositID must be filled / depositID harus diisi")
+	}
+
+	var row depositRow
+	if err := c.do(ctx, "/deposit/status", map[string]string{"deposit_id": depositID}, &row); err != nil {
+		return nil, err
+	}
+
+	d := row.deposit(c.loc)
+	if d.ID == "" {
+		d.ID = depositID
+	}
+	return d, nil
+}
+
+// WaitForDeposit polls a deposit until it is paid, like qris.QRIS.WaitForPayment.
+// WaitForDeposit melakukan polling deposit sampai dibayar, seperti qris.QRIS.WaitForPayment.
+//
+// An expired or cancelled deposit returns ErrDepositExpired or ErrDepositCancelled with the deposit.
+// Failed checks are retried on the next tick, except ErrUnauthorized.
+// Deposit yang kedaluwarsa atau dibatalkan mengembalikan ErrDepositExpired atau ErrDepositCancelled beserta depositnya.
+// Pengecekan yang gagal diulang pada tick berikutnya, kecuali ErrUnauthorized.
+func (c *Client) WaitForDeposit(ctx context.Context, depositID string, interval time.Duration) (*Deposit, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d, err := c.GetDepositStatus(ctx, depositID)
+		switch {
+		case errors.Is(err, ErrUnauthorized):
+			return nil, err
+		case err == nil:
+			switch d.Status {
+			case DepositPaid:
+				return d, nil
+			case DepositExpired:
+				return d, ErrDepositExpired
+			case DepositCancelled:
+				return d, ErrDepositCancelled
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
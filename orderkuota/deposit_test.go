@@ -0,0 +1,125 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+func TestCreateDeposit(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/deposit": "deposit_pending.json"})
+	c := newTestClient(t, srv)
+
+	d, err := c.CreateDeposit(context.Background(), 50000, orderkuota.DepositQRIS)
+	if err != nil {
+		t.Fatalf("CreateDeposit: %v", err)
+	}
+	if d.ID != "7001" || d.Method != orderkuota.DepositQRIS || d.Amount != 50000 || d.TransferAmount != 50123 ||
+		d.Status != orderkuota.DepositPending || !d.ExpiresAt.Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, qris.WIB)) {
+		t.Fatalf("CreateDeposit = %+v", d)
+	}
+	if params := srv.received()[0].Params; params["amount"] != "50000" || params["method"] != "qris" {
+		t.Fatalf("sent %v, want 50000 by qris", params)
+	}
+
+	// The payload is the QRIS of the transfer amount, ready for the QR renderer
+	if err := emv.VerifyCRC(d.QRString); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := emv.Parse(d.QRString); err != nil || p.Value("54") != "50123" {
+		t.Fatalf("amount of the payload = %q, %v, want 50123", p.Value("54"), err)
+	}
+	if qr, err := d.QRCode(); err != nil || qr.Content != d.QRString {
+		t.Fatalf("QRCode = %v, %v", qr, err)
+	}
+	if _, err := (&orderkuota.Deposit{}).QRCode(); err == nil {
+		t.Fatal("QRCode of a deposit without a payload succeeded")
+	}
+
+	for _, tt := range []struct {
+		amount int64
+		method orderkuota.DepositMethod
+	}{{0, orderkuota.DepositQRIS}, {-1, orderkuota.DepositQRIS}, {50000, "va_bca"}} {
+		if _, err := c.CreateDeposit(context.Background(), tt.amount, tt.method); err == nil {
+			t.Errorf("CreateDeposit(%d, %s) succeeded", tt.amount, tt.method)
+		}
+	}
+	if n := len(srv.received()); n != 1 {
+		t.Fatalf("%d requests sent, want 1", n)
+	}
+}
+
+func TestWaitForDeposit(t *testing.T) {
+	tests := []struct {
+		name       string
+		answers    []string // answers of /deposit/status in order, the last one repeated / jawaban /deposit/status berurutan, yang terakhir diulang
+		timeout    time.Duration
+		wantStatus orderkuota.DepositStatus // empty for no deposit / kosong jika tidak ada deposit
+		wantErr    error
+	}{
+		{
+			name:       "paid",
+			answers:    []string{"deposit_pending.json", "rate_limited.json", "deposit_paid.json"},
+			wantStatus: orderkuota.DepositPaid,
+		},
+		{
+			name:       "expired",
+			answers:    []string{"deposit_pending.json", "deposit_expired.json"},
+			wantStatus: orderkuota.DepositExpired,
+			wantErr:    orderkuota.ErrDepositExpired,
+		},
+		{
+			name:       "cancelled",
+			answers:    []string{"deposit_cancelled.json"},
+			wantStatus: orderkuota.DepositCancelled,
+			wantErr:    orderkuota.ErrDepositCancelled,
+		},
+		{
+			name:    "pending until the context is done",
+			answers: []string{"deposit_pending.json"},
+			timeout: 50 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "unauthorized",
+			answers: []string{"401"},
+			wantErr: orderkuota.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/deposit/status": tt.answers[len(tt.answers)-1]})
+			srv.queue("/deposit/status", tt.answers[:len(tt.answers)-1]...)
+			c := newTestClient(t, srv)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			d, err := c.WaitForDeposit(ctx, "7001", time.Millisecond)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("WaitForDeposit err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantStatus == "" && d != nil || tt.wantStatus != "" && (d == nil || d.Status != tt.wantStatus || d.ID != "7001") {
+				t.Fatalf("WaitForDeposit = %+v, want status %q", d, tt.wantStatus)
+			}
+			if tt.timeout == 0 && srv.count("/deposit/status") != len(tt.answers) {
+				t.Fatalf("%d checks, want %d", srv.count("/deposit/status"), len(tt.answers))
+			}
+			if srv.received()[0].Params["deposit_id"] != "7001" {
+				t.Fatalf("checked %v, want deposit_id 7001", srv.received()[0].Params)
+			}
+		})
+	}
+
+	if _, err := newTestClient(t, newFixtureServer(t, nil)).GetDepositStatus(context.Background(), ""); err == nil {
+		t.Fatal("GetDepositStatus without an ID succeeded")
+	}
+}
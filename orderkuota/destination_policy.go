@@ -0,0 +1,239 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDestinationBlocked is wrapped by the DestinationBlockedError of a rejected purchase.
+// ErrDestinationBlocked dibungkus oleh DestinationBlockedError dari pembelian yang ditolak.
+var ErrDestinationBlocked = errors.New("destination blocked / nomor tujuan diblokir")
+
+// DestinationBlockedError is returned by Purchase when a DestinationPolicy rejects the destination.
+// DestinationBlockedError dikembalikan oleh Purchase jika DestinationPolicy menolak nomor tujuan.
+type DestinationBlockedError struct {
+	Rule        string // Rule that fired, e.g. "blacklist" / Aturan yang menolak, misalnya "blacklist"
+	Destination string // Normalized destination / Nomor tujuan yang dinormalisasi
+	Reason      string // Details, may be empty / Rincian, boleh kosong
+}
+
+func (e *DestinationBlockedError) Error() string {
+	reason := ""
+	if e.Reason != "" {
+		reason = " (" + e.Reason + ")"
+	}
+	return fmt.Sprintf("destination %s blocked by %s%s / nomor tujuan %s diblokir oleh %s%s",
+		e.Destination, e.Rule, reason, e.Destination, e.Rule, reason)
+}
+
+// Unwrap returns ErrDestinationBlocked.
+// Unwrap mengembalikan ErrDestinationBlocked.
+func (e *DestinationBlockedError) Unwrap() error {
+	return ErrDestinationBlocked
+}
+
+// DestinationCheck is the purchase handed to a DestinationPolicy.
+// DestinationCheck adalah pembelian yang diteruskan ke DestinationPolicy.
+type DestinationCheck struct {
+	ProductCode string // Product being bought / Produk yang dibeli
+	Category    string // Category by the cached catalog, empty if unknown / Kategori menurut katalog tersimpan, kosong jika tidak diketahui
+	Destination string // Normalized destination, 08 format for phone numbers / Nomor tujuan yang dinormalisasi, format 08 untuk nomor HP
+}
+
+// DestinationPolicy decides whether a purchase may be sent to its destination. Purchase checks
+// the policies of WithDestinationPolicy in order before any network call; a non-nil error,
+// normally a *DestinationBlockedError, rejects the purchase.
+// DestinationPolicy menentukan apakah pembelian boleh dikirim ke nomor tujuannya. Purchase memeriksa
+// policy dari WithDestinationPolicy secara berurutan sebelum panggilan jaringan apa pun; error yang
+// tidak nil, biasanya *DestinationBlockedError, menolak pembelian.
+type DestinationPolicy interface {
+	Check(ctx context.Context, d DestinationCheck) error
+}
+
+// DestinationPolicyFunc adapts a function to a DestinationPolicy.
+// DestinationPolicyFunc mengadaptasi sebuah fungsi menjadi DestinationPolicy.
+type DestinationPolicyFunc func(ctx context.Context, d DestinationCheck) error
+
+// Check calls f(ctx, d).
+// Check memanggil f(ctx, d).
+func (f DestinationPolicyFunc) Check(ctx context.Context, d DestinationCheck) error {
+	return f(ctx, d)
+}
+
+// WithDestinationPolicy makes Purchase check every destination against policies, in order.
+// WithDestinationPolicy membuat Purchase memeriksa setiap nomor tujuan terhadap policies, secara berurutan.
+func WithDestinationPolicy(policies ...DestinationPolicy) Option {
+	return func(c *Client) {
+		c.destinationPolicies = append(c.destinationPolicies, policies...)
+	}
+}
+
+// checkDestination runs the destination policies for a purchase.
+// checkDestination menjalankan policy nomor tujuan untuk sebuah pembelian.
+func (c *Client) checkDestination(ctx context.Context, productCode, destination string) error {
+	if len(c.destinationPolicies) == 0 {
+		return nil
+	}
+	d := DestinationCheck{ProductCode: productCode, Category: c.productCategory(productCode), Destination: destination}
+	for _, p := range c.destinationPolicies {
+		if err := p.Check(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Blacklist rejects purchases to the given numbers. Phone numbers may be written in any format
+// NormalizeMSISDN accepts.
+// Blacklist menolak pembelian ke nomor yang diberikan. Nomor HP boleh ditulis dalam format apa
+// pun yang diterima NormalizeMSISDN.
+func Blacklist(numbers ...string) DestinationPolicy {
+	blocked := make(map[string]bool, len(numbers))
+	for _, n := range numbers {
+		if m, err := NormalizeMSISDN(n); err == nil {
+			n = m
+		} else if d, err := validateDestination(n); err == nil {
+			n = d
+		}
+		blocked[n] = true
+	}
+	return DestinationPolicyFunc(func(_ context.Context, d DestinationCheck) error {
+		if blocked[d.Destination] {
+			return &DestinationBlockedError{Rule: "blacklist", Destination: d.Destination}
+		}
+		return nil
+	})
+}
+
+// PrefixWhitelist accepts only destinations starting with one of prefixes, for products of
+// categories; products of other known categories are not checked. Products of unknown category
+// are always checked.
+// PrefixWhitelist hanya menerima nomor tujuan yang diawali salah satu prefixes, untuk produk dari
+// categories; produk dari kategori lain yang dikenal tidak diperiksa. Produk yang kategorinya tidak
+// diketahui selalu diperiksa.
+func PrefixWhitelist(prefixes []string, categories ...string) DestinationPolicy {
+	prefixes = append([]string(nil), prefixes...)
+	return DestinationPolicyFunc(func(_ context.Context, d DestinationCheck) error {
+		if d.Category != "" && len(categories) > 0 && !containsFold(categories, d.Category) {
+			return nil
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(d.Destination, p) {
+				return nil
+			}
+		}
+		return &DestinationBlockedError{Rule: "prefix whitelist", Destination: d.Destination}
+	})
+}
+
+// MobileWhitelist accepts only numbers of the prefixes in OperatorPrefixes for pulsa, data and
+// emoney products. OperatorPrefixes is read once, when MobileWhitelist is called.
+// MobileWhitelist hanya menerima nomor dengan prefix di OperatorPrefixes untuk produk pulsa, data
+// dan emoney. OperatorPrefixes dibaca sekali, saat MobileWhitelist dipanggil.
+func MobileWhitelist() DestinationPolicy {
+	prefixes := make([]string, 0, len(OperatorPrefixes))
+	for p := range OperatorPrefixes {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	return PrefixWhitelist(prefixes, CategoryPulsa, CategoryData, CategoryEMoney)
+}
+
+// RateStore counts purchases per destination for RateLimit. Share one store between processes
+// to limit them all.
+// RateStore menghitung pembelian per nomor tujuan untuk RateLimit. Pakai satu store bersama antar
+// proses untuk membatasi semuanya.
+type RateStore interface {
+	// Allow records a purchase to key at now and returns true, unless limit purchases were already
+	// recorded since now-window, in which case nothing is recorded and false is returned.
+	// Allow mencatat pembelian ke key pada now dan mengembalikan true, kecuali sudah tercatat limit
+	// pembelian sejak now-window, dalam hal ini tidak ada yang dicatat dan false dikembalikan.
+	Allow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (bool, error)
+}
+
+// RateLimit allows at most limit purchases to a destination within any window (one hour if 0),
+// counted in store. Every purchase checked counts, including those the gateway then fails.
+// RateLimit mengizinkan paling banyak limit pembelian ke satu nomor tujuan dalam setiap window
+// (satu jam jika 0), dihitung di store. Setiap pembelian yang diperiksa dihitung, termasuk yang
+// kemudian gagal di gateway.
+func RateLimit(store RateStore, limit int, window time.Duration) DestinationPolicy {
+	if window <= 0 {
+		window = time.Hour
+	}
+	return DestinationPolicyFunc(func(ctx context.Context, d DestinationCheck) error {
+		ok, err := store.Allow(ctx, d.Destination, limit, window, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to check purchase rate / gagal memeriksa laju pembelian: %w", err)
+		}
+		if !ok {
+			return &DestinationBlockedError{Rule: "rate limit", Destination: d.Destination,
+				Reason: fmt.Sprintf("%d purchases per %s", limit, window)}
+		}
+		return nil
+	})
+}
+
+// MemoryRateStore is a RateStore kept in memory, limiting a single process.
+// MemoryRateStore adalah RateStore yang disimpan di memori, membatasi satu proses.
+type MemoryRateStore struct {
+	mu   sync.Mutex
+	keys map[string]*memoryRateEntry
+}
+
+type memoryRateEntry struct {
+	hits   []time.Time
+	window time.Duration // Window of the last Allow, to expire the hits / Window dari Allow terakhir, untuk mengedaluwarsakan hit
+}
+
+// NewMemoryRateStore creates an empty MemoryRateStore.
+// NewMemoryRateStore membuat MemoryRateStore kosong.
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{keys: make(map[string]*memoryRateEntry)}
+}
+
+// Allow implements RateStore.
+// Allow mengimplementasikan RateStore.
+func (s *MemoryRateStore) Allow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.keys[key]
+	if !ok {
+		e = &memoryRateEntry{}
+		s.keys[key] = e
+	}
+	e.window = window
+	for k, e := range s.keys {
+		since := now.Add(-e.window)
+		kept := e.hits[:0]
+		for _, t := range e.hits {
+			if t.After(since) {
+				kept = append(kept, t)
+			}
+		}
+		e.hits = kept
+		if len(kept) == 0 && k != key {
+			delete(s.keys, k)
+		}
+	}
+
+	if len(e.hits) >= limit {
+		return false, nil
+	}
+	e.hits = append(e.hits, now)
+	return true, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
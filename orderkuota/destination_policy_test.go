@@ -0,0 +1,165 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestDestinationPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      orderkuota.DestinationPolicy
+		product     string
+		destination string
+		wantRule    string // Empty if the purchase is allowed / Kosong jika pembelian diizinkan
+	}{
+		{"blacklist", orderkuota.Blacklist("081234567890"), "S10", "081234567890", "blacklist"},
+		{"blacklist +62 entry", orderkuota.Blacklist("+62 812-3456-7890"), "S10", "081234567890", "blacklist"},
+		{"blacklist 62 destination", orderkuota.Blacklist("081234567890"), "S10", "6281234567890", "blacklist"},
+		{"blacklist spaced +62 destination", orderkuota.Blacklist("081234567890"), "S10", "+62 812 3456 7890", "blacklist"},
+		{"blacklist meter number", orderkuota.Blacklist("1234 5678 901"), "PLN20", "12345678901", "blacklist"},
+		{"blacklist other number", orderkuota.Blacklist("081234567890"), "S10", "081234567891", ""},
+
+		{"mobile whitelist Telkomsel", orderkuota.MobileWhitelist(), "S10", "081234567890", ""},
+		{"mobile whitelist Tri", orderkuota.MobileWhitelist(), "T10", "089812345678", ""},
+		{"mobile whitelist unknown prefix", orderkuota.MobileWhitelist(), "S10", "080012345678", "prefix whitelist"},
+		{"mobile whitelist skips PLN", orderkuota.MobileWhitelist(), "PLN20", "12345678901", ""},
+		{"prefix whitelist", orderkuota.PrefixWhitelist([]string{"0812"}), "S10", "081212345678", ""},
+		{"prefix whitelist rejects", orderkuota.PrefixWhitelist([]string{"0812"}), "S10", "081312345678", "prefix whitelist"},
+		{"prefix whitelist of other category", orderkuota.PrefixWhitelist([]string{"0812"}, orderkuota.CategoryGame), "PLN20", "12345678901", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+			c := newTestClient(t, srv, orderkuota.WithDestinationPolicy(tt.policy))
+
+			_, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: tt.product, Destination: tt.destination})
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Fatalf("Purchase: %v", err)
+				}
+				return
+			}
+			var blocked *orderkuota.DestinationBlockedError
+			if !errors.As(err, &blocked) || !errors.Is(err, orderkuota.ErrDestinationBlocked) {
+				t.Fatalf("err = %v, want a DestinationBlockedError", err)
+			}
+			if blocked.Rule != tt.wantRule {
+				t.Errorf("Rule = %q, want %q", blocked.Rule, tt.wantRule)
+			}
+			if n := len(srv.received()); n != 0 {
+				t.Errorf("%d requests sent, want none before the policy check", n)
+			}
+		})
+	}
+}
+
+func TestDestinationPoliciesInOrder(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) orderkuota.DestinationPolicy {
+		return orderkuota.DestinationPolicyFunc(func(_ context.Context, d orderkuota.DestinationCheck) error {
+			calls = append(calls, name+" "+d.ProductCode+" "+d.Destination)
+			return err
+		})
+	}
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv, orderkuota.WithDestinationPolicy(
+		record("first", nil),
+		record("second", &orderkuota.DestinationBlockedError{Rule: "custom"}),
+		record("third", nil),
+	))
+
+	_, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "+6281234567890"})
+	if !errors.Is(err, orderkuota.ErrDestinationBlocked) {
+		t.Fatalf("err = %v, want ErrDestinationBlocked", err)
+	}
+	want := []string{"first S10 081234567890", "second S10 081234567890"}
+	if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestMemoryRateStoreWindow(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	steps := []struct {
+		at   time.Duration
+		key  string
+		want bool
+	}{
+		{0, "0812", true},
+		{10 * time.Minute, "0812", true},
+		{20 * time.Minute, "0812", false}, // Limit reached / Batas tercapai
+		{20 * time.Minute, "0813", true},  // Counted per destination / Dihitung per nomor tujuan
+		{59*time.Minute + 59*time.Second, "0812", false},
+		{time.Hour, "0812", true}, // The purchase at 0 left the window / Pembelian pada 0 keluar dari window
+		{time.Hour + time.Minute, "0812", false},
+		{time.Hour + 9*time.Minute + 59*time.Second, "0812", false},
+		{time.Hour + 10*time.Minute, "0812", true}, // The one at 10m is exactly one window old / Yang pada 10m tepat satu window
+		{time.Hour + 10*time.Minute + time.Second, "0812", false},
+		{3 * time.Hour, "0812", true},
+		{3 * time.Hour, "0812", true},
+		{3 * time.Hour, "0812", false},
+	}
+	store := orderkuota.NewMemoryRateStore()
+	for i, s := range steps {
+		got, err := store.Allow(context.Background(), s.key, 2, time.Hour, t0.Add(s.at))
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if got != s.want {
+			t.Fatalf("step %d: Allow(%s at +%s) = %v, want %v", i, s.key, s.at, got, s.want)
+		}
+	}
+}
+
+func TestRateLimitPurchases(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv, orderkuota.WithDestinationPolicy(orderkuota.RateLimit(orderkuota.NewMemoryRateStore(), 2, 0)))
+
+	purchases := []struct {
+		destination string
+		blocked     bool
+	}{
+		{"081234567890", false},
+		{"+62 812 3456 7890", false},
+		{"6281234567890", true}, // Same number in another format / Nomor yang sama dalam format lain
+		{"081234567891", false},
+	}
+	for _, p := range purchases {
+		_, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: "S10", Destination: p.destination})
+		var blocked *orderkuota.DestinationBlockedError
+		if errors.As(err, &blocked) != p.blocked {
+			t.Fatalf("Purchase(%s) err = %v, want blocked = %v", p.destination, err, p.blocked)
+		}
+		if p.blocked && (blocked.Rule != "rate limit" || blocked.Destination != "081234567890" || blocked.Reason != "2 purchases per 1h0m0s") {
+			t.Fatalf("blocked = %+v", blocked)
+		}
+	}
+	if n := len(srv.received()); n != 3 {
+		t.Fatalf("%d requests sent, want 3", n)
+	}
+}
+
+// failingRateStore is a RateStore that cannot be reached.
+type failingRateStore struct{}
+
+func (failingRateStore) Allow(context.Context, string, int, time.Duration, time.Time) (bool, error) {
+	return false, errors.New("store down")
+}
+
+func TestRateLimitStoreError(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv, orderkuota.WithDestinationPolicy(orderkuota.RateLimit(failingRateStore{}, 1, 0)))
+
+	_, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "081234567890"})
+	if err == nil || errors.Is(err, orderkuota.ErrDestinationBlocked) {
+		t.Fatalf("err = %v, want the store error", err)
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Fatalf("%d requests sent, want none", n)
+	}
+}
@@ -0,0 +1,155 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ewallet is an Indonesian e-wallet topped up through emoney products.
+// Ewallet adalah e-wallet Indonesia yang diisi melalui produk emoney.
+type Ewallet string
+
+// Known e-wallets.
+// E-wallet yang dikenal.
+const (
+	EwalletDANA      Ewallet = "DANA"
+	EwalletOVO       Ewallet = "OVO"
+	EwalletGoPay     Ewallet = "GoPay"
+	EwalletShopeePay Ewallet = "ShopeePay"
+	EwalletLinkAja   Ewallet = "LinkAja"
+)
+
+// E-wallet inquiry errors.
+// Error inquiry e-wallet.
+var (
+	ErrEwalletAccountNotFound = errors.New("e-wallet account not found / akun e-wallet tidak ditemukan")
+	ErrUnknownEwallet         = errors.New("product is not a known e-wallet / produk bukan e-wallet yang dikenal")
+)
+
+// EwalletOperators limits the operators whose numbers can own an e-wallet. Wallets without an
+// entry accept every operator, which is the case of the known ones today. Add entries during
+// program initialization when a wallet is tied to an operator.
+// EwalletOperators membatasi operator yang nomornya dapat memiliki e-wallet. Wallet tanpa entri
+// menerima semua operator, seperti semua wallet yang dikenal saat ini. Tambahkan entri saat
+// inisialisasi program jika sebuah wallet terikat ke operator tertentu.
+var EwalletOperators = map[Ewallet][]Operator{}
+
+// ewalletNames maps the spellings of wallets in product brands and codes to Ewallet.
+// ewalletNames memetakan penulisan wallet di brand dan kode produk ke Ewallet.
+var ewalletNames = []struct {
+	prefix string
+	wallet Ewallet
+}{
+	{"DANA", EwalletDANA},
+	{"OVO", EwalletOVO},
+	{"GOPAY", EwalletGoPay},
+	{"GOJEK", EwalletGoPay},
+	{"SHOPEE", EwalletShopeePay},
+	{"LINKAJA", EwalletLinkAja},
+}
+
+// EwalletAccount is the result of an e-wallet inquiry.
+// EwalletAccount adalah hasil inquiry e-wallet.
+type EwalletAccount struct {
+	Wallet      Ewallet // E-wallet of the product / E-wallet dari produk
+	ProductCode string  // Product code asked about / Kode produk yang ditanyakan
+	Phone       string  // Normalized phone number / Nomor HP yang dinormalisasi
+	Name        string  // Registered name, masked as the gateway returns it / Nama terdaftar, disamarkan sesuai kiriman gateway
+}
+
+// EwalletInquiry returns the account registered to phone on the e-wallet of productCode, so the
+// name can be confirmed before Purchase. The number is checked against EwalletOperators first.
+// EwalletInquiry mengembalikan akun yang terdaftar untuk phone di e-wallet dari productCode, sehingga
+// nama dapat dikonfirmasi sebelum Purchase. Nomor diperiksa terhadap EwalletOperators terlebih dahulu.
+//
+// An unregistered number fails with ErrEwalletAccountNotFound; network and gateway failures keep their own errors.
+// Nomor yang tidak terdaftar gagal dengan ErrEwalletAccountNotFound; kegagalan jaringan dan gateway tetap dengan error-nya sendiri.
+func (c *Client) EwalletInquiry(ctx context.Context, productCode, phone string) (*EwalletAccount, error) {
+	if productCode == "" {
+		return nil, errors.New("productCode must be filled / productCode harus diisi")
+	}
+	wallet, ok := c.ewalletOf(productCode)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEwallet, productCode)
+	}
+	msisdn, err := validateEwalletNumber(wallet, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	var results struct {
+		Name        string `json:"name"`
+		Destination string `json:"destination"`
+	}
+	err = c.do(ctx, "/ewallet/inquiry", map[string]string{"product_code": productCode, "destination": msisdn}, &results)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		m := strings.ToLower(apiErr.Message)
+		if strings.Contains(m, "tidak ditemukan") || strings.Contains(m, "not found") ||
+			strings.Contains(m, "tidak terdaftar") || strings.Contains(m, "not registered") {
+			return nil, fmt.Errorf("%w: %s", ErrEwalletAccountNotFound, apiErr.Message)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(results.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: no name returned / tidak ada nama yang dikembalikan", ErrEwalletAccountNotFound)
+	}
+	return &EwalletAccount{Wallet: wallet, ProductCode: productCode, Phone: msisdn, Name: name}, nil
+}
+
+// validateEwalletNumber normalizes phone and checks that its operator can own wallet.
+// validateEwalletNumber menormalisasi phone dan memeriksa bahwa operatornya dapat memiliki wallet.
+func validateEwalletNumber(wallet Ewallet, phone string) (string, error) {
+	msisdn, err := NormalizeMSISDN(phone)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+	}
+	allowed := EwalletOperators[wallet]
+	if len(allowed) == 0 {
+		return msisdn, nil
+	}
+
+	op, err := DetectOperator(msisdn)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+	}
+	for _, a := range allowed {
+		if a == op {
+			return msisdn, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s numbers cannot own %s / nomor %s tidak dapat memiliki %s", ErrInvalidDestination, op, wallet, op, wallet)
+}
+
+// ewalletOf returns the e-wallet of a product, by the brand in the cached catalog or by its code.
+// ewalletOf mengembalikan e-wallet dari produk, berdasarkan brand di katalog tersimpan atau kodenya.
+func (c *Client) ewalletOf(code string) (Ewallet, bool) {
+	name := code
+	c.catalog.mu.Lock()
+	for _, p := range c.catalog.products {
+		if p.Code == code {
+			if p.Category != CategoryEMoney {
+				c.catalog.mu.Unlock()
+				return "", false
+			}
+			name = p.Brand
+			break
+		}
+	}
+	c.catalog.mu.Unlock()
+
+	name = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(name))
+	for _, n := range ewalletNames {
+		if strings.HasPrefix(name, n.prefix) {
+			return n.wallet, true
+		}
+	}
+	return "", false
+}
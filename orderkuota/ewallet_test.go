@@ -0,0 +1,91 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestEwalletInquiry(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/ewallet/inquiry": "ewallet_inquiry.json", "/pricelist": "pricelist_emoney.json"})
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	account, err := c.EwalletInquiry(ctx, "DANA50", "+62 812-3456-7890")
+	if err != nil {
+		t.Fatalf("EwalletInquiry: %v", err)
+	}
+	want := &orderkuota.EwalletAccount{Wallet: orderkuota.EwalletDANA, ProductCode: "DANA50", Phone: "081234567890", Name: "BUDI S****O"}
+	if !reflect.DeepEqual(account, want) {
+		t.Fatalf("EwalletInquiry = %+v, want %+v", account, want)
+	}
+	if params := srv.received()[0].Params; params["product_code"] != "DANA50" || params["destination"] != "081234567890" {
+		t.Fatalf("sent %v, want DANA50 to 081234567890", params)
+	}
+
+	// With the catalog loaded, the brand decides the wallet rather than the code
+	if _, err := c.GetPriceList(ctx, orderkuota.ProductFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if account, err := c.EwalletInquiry(ctx, "EM25", "081234567890"); err != nil || account.Wallet != orderkuota.EwalletOVO {
+		t.Fatalf("EwalletInquiry(EM25) = %+v, %v, want OVO", account, err)
+	}
+	if _, err := c.EwalletInquiry(ctx, "DANAPULSA", "081234567890"); !errors.Is(err, orderkuota.ErrUnknownEwallet) {
+		t.Fatalf("EwalletInquiry of a pulsa product err = %v, want ErrUnknownEwallet", err)
+	}
+}
+
+func TestEwalletInquiryErrors(t *testing.T) {
+	orderkuota.EwalletOperators[orderkuota.EwalletOVO] = []orderkuota.Operator{orderkuota.OperatorTelkomsel}
+	t.Cleanup(func() { delete(orderkuota.EwalletOperators, orderkuota.EwalletOVO) })
+
+	tests := []struct {
+		name    string
+		fixture string
+		product string
+		phone   string
+		wantErr error
+		sent    bool
+	}{
+		{name: "not registered", fixture: "ewallet_not_found.json", product: "DANA50", phone: "081234567890", wantErr: orderkuota.ErrEwalletAccountNotFound, sent: true},
+		{name: "gateway failure", fixture: "rate_limited.json", product: "DANA50", phone: "081234567890", sent: true},
+		{name: "unknown wallet", fixture: "ewallet_inquiry.json", product: "S10", phone: "081234567890", wantErr: orderkuota.ErrUnknownEwallet},
+		{name: "invalid number", fixture: "ewallet_inquiry.json", product: "DANA50", phone: "0812", wantErr: orderkuota.ErrInvalidDestination},
+		{name: "operator cannot own the wallet", fixture: "ewallet_inquiry.json", product: "OVO50", phone: "081712345678", wantErr: orderkuota.ErrInvalidDestination},
+		{name: "operator owning the wallet", fixture: "ewallet_inquiry.json", product: "OVO50", phone: "081234567890", sent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/ewallet/inquiry": tt.fixture})
+			_, err := newTestClient(t, srv).EwalletInquiry(context.Background(), tt.product, tt.phone)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("EwalletInquiry err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.fixture == "ewallet_inquiry.json":
+				if err != nil {
+					t.Fatalf("EwalletInquiry: %v", err)
+				}
+			default:
+				if err == nil || errors.Is(err, orderkuota.ErrEwalletAccountNotFound) {
+					t.Fatalf("EwalletInquiry err = %v, want a failure other than ErrEwalletAccountNotFound", err)
+				}
+			}
+			if sent := srv.count("/ewallet/inquiry") > 0; sent != tt.sent {
+				t.Fatalf("inquiry sent = %v, want %v", sent, tt.sent)
+			}
+		})
+	}
+
+	// An unreachable gateway is not a missing account
+	srv := newFixtureServer(t, map[string]string{"/ewallet/inquiry": "ewallet_inquiry.json"})
+	c := newTestClient(t, srv)
+	srv.Close()
+	if _, err := c.EwalletInquiry(context.Background(), "DANA50", "081234567890"); err == nil || errors.Is(err, orderkuota.ErrEwalletAccountNotFound) {
+		t.Fatalf("EwalletInquiry with the gateway down err = %v, want a network error", err)
+	}
+}
@@ -0,0 +1,233 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// attemptTTL is how long AttemptsFor remembers the attempts of a purchase.
+// attemptTTL adalah lama AttemptsFor mengingat percobaan sebuah pembelian.
+const attemptTTL = 24 * time.Hour
+
+// ErrRefundRequired is returned by PurchaseWithFallback when every attempt failed, so the customer
+// who already paid has to be refunded.
+// ErrRefundRequired dikembalikan oleh PurchaseWithFallback jika semua percobaan gagal, sehingga
+// dana pelanggan yang sudah membayar harus dikembalikan.
+var ErrRefundRequired = errors.New("every purchase attempt failed, refund required / semua percobaan pembelian gagal, perlu refund")
+
+// FallbackPolicy controls PurchaseWithFallback.
+// FallbackPolicy mengatur PurchaseWithFallback.
+type FallbackPolicy struct {
+	RetrySame   int                       // Extra attempts of each product before the next one / Percobaan tambahan setiap produk sebelum produk berikutnya
+	MaxAttempts int                       // Total attempts across products, 0 for no limit / Total percobaan untuk semua produk, 0 tanpa batas
+	Retryable   func(reason string) bool  // Whether a failure reason may be tried again, nil for every reason / Apakah alasan gagal boleh dicoba lagi, nil untuk semua alasan
+	Rejected    func(message string) bool // Whether a gateway error means nothing was charged, nil for IsRejection / Apakah error gateway berarti tidak ada yang dipotong, nil untuk IsRejection
+	Interval    time.Duration             // Polling interval of pending attempts, default DefaultPollInterval / Interval polling percobaan pending, default DefaultPollInterval
+}
+
+// PurchaseAttempt is one attempt of PurchaseWithFallback, as returned by AttemptsFor.
+// PurchaseAttempt adalah satu percobaan PurchaseWithFallback, seperti yang dikembalikan AttemptsFor.
+type PurchaseAttempt struct {
+	Attempt       int               // 1 for the first attempt / 1 untuk percobaan pertama
+	RefID         string            // RefID sent for this attempt / RefID yang dikirim untuk percobaan ini
+	ProductCode   string            // Product tried / Produk yang dicoba
+	TransactionID string            // Gateway transaction ID, if accepted / ID transaksi gateway, jika diterima
+	Status        TransactionStatus // Final status, pending if unknown / Status akhir, pending jika tidak diketahui
+	Message       string            // Gateway message or error / Pesan gateway atau error
+	At            time.Time         // When the attempt was sent / Waktu percobaan dikirim
+}
+
+// PurchaseWithFallback buys req and, when the purchase fails, tries it again and then each product
+// of fallbacks in turn, as policy allows. Each attempt waits for its transaction to finish.
+// PurchaseWithFallback membeli req dan, jika pembelian gagal, mencobanya lagi lalu setiap produk
+// di fallbacks secara bergantian, sesuai policy. Setiap percobaan menunggu transaksinya selesai.
+//
+// Attempt n is sent with the RefID req.RefID for n = 1 and req.RefID-n after that, so calling
+// PurchaseWithFallback again with the same RefID never buys twice. Every attempt is recorded for
+// AttemptsFor(req.RefID).
+// Percobaan ke-n dikirim dengan RefID req.RefID untuk n = 1 dan req.RefID-n setelahnya, sehingga
+// memanggil PurchaseWithFallback lagi dengan RefID yang sama tidak pernah membeli dua kali. Setiap
+// percobaan dicatat untuk AttemptsFor(req.RefID).
+//
+// An attempt fails when its transaction ends failed, or when the gateway refuses it with a message
+// that policy.Rejected (IsRejection by default) knows to mean nothing was charged. Any other gateway
+// error, such as a rate limit or maintenance, may come after the charge, so it stops the fallback.
+// Sebuah percobaan gagal jika transaksinya berakhir gagal, atau jika gateway menolaknya dengan pesan
+// yang diketahui policy.Rejected (default IsRejection) berarti tidak ada yang dipotong. Error gateway
+// lain, seperti rate limit atau maintenance, bisa terjadi setelah pemotongan, sehingga menghentikan fallback.
+//
+// When every allowed attempt failed, the last result is returned with an error wrapping
+// ErrRefundRequired. Any other error stops the fallback: past request validation, it leaves the
+// outcome of the last attempt unknown, so look the transaction up before refunding.
+// Jika semua percobaan yang diizinkan gagal, hasil terakhir dikembalikan dengan error yang membungkus
+// ErrRefundRequired. Error lain menghentikan fallback: di luar validasi request, hasil percobaan
+// terakhir menjadi tidak diketahui, jadi periksa transaksinya sebelum melakukan refund.
+func (c *Client) PurchaseWithFallback(ctx context.Context, req PurchaseRequest, fallbacks []string, policy FallbackPolicy, opts ...PurchaseOption) (*PurchaseResult, error) {
+	if policy.RetrySame < 0 || policy.MaxAttempts < 0 {
+		return nil, errors.New("invalid fallback policy / fallback policy tidak valid")
+	}
+	baseRef := req.RefID
+	if baseRef == "" {
+		var err error
+		if baseRef, err = newRefID(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		last    *PurchaseResult
+		reason  string
+		attempt int
+	)
+	for _, code := range append([]string{req.ProductCode}, fallbacks...) {
+		for try := 0; try <= policy.RetrySame; try++ {
+			if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+				return last, refundRequired(reason)
+			}
+			attempt++
+
+			r := req
+			r.ProductCode = code
+			r.RefID = baseRef
+			if attempt > 1 {
+				r.RefID = baseRef + "-" + strconv.Itoa(attempt)
+			}
+			result, failure, failed, err := c.purchaseAttempt(ctx, baseRef, attempt, r, policy, opts)
+			if err != nil {
+				return result, err
+			}
+			if !failed {
+				return result, nil
+			}
+
+			last, reason = result, failure
+			if policy.Retryable != nil && !policy.Retryable(failure) {
+				return last, refundRequired(reason)
+			}
+		}
+	}
+	return last, refundRequired(reason)
+}
+
+// refundRequired wraps ErrRefundRequired with the last failure reason.
+// refundRequired membungkus ErrRefundRequired dengan alasan gagal terakhir.
+func refundRequired(reason string) error {
+	if reason == "" {
+		return ErrRefundRequired
+	}
+	return fmt.Errorf("%w: %s", ErrRefundRequired, reason)
+}
+
+// AttemptsFor returns the attempts of PurchaseWithFallback for refID in order, remembered for a day.
+// AttemptsFor mengembalikan percobaan PurchaseWithFallback untuk refID secara berurutan, diingat selama sehari.
+func (c *Client) AttemptsFor(refID string) []PurchaseAttempt {
+	return c.attempts.get(refID)
+}
+
+// purchaseAttempt sends r, waits for it to finish and records it under baseRef. It returns the
+// failure reason and true if the purchase failed, or an error if its outcome is unknown.
+// purchaseAttempt mengirim r, menunggunya selesai dan mencatatnya di bawah baseRef. Alasan gagal dan
+// true dikembalikan jika pembelian gagal, atau error jika hasilnya tidak diketahui.
+func (c *Client) purchaseAttempt(ctx context.Context, baseRef string, attempt int, r PurchaseRequest, policy FallbackPolicy, opts []PurchaseOption) (*PurchaseResult, string, bool, error) {
+	a := PurchaseAttempt{Attempt: attempt, RefID: r.RefID, ProductCode: r.ProductCode, Status: TransactionPending, At: time.Now()}
+	defer func() { c.attempts.add(baseRef, a) }()
+
+	rejected := policy.Rejected
+	if rejected == nil {
+		rejected = IsRejection
+	}
+	result, err := c.Purchase(ctx, r, opts...)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && rejected(apiErr.Message) {
+		// Refused by the gateway before charging
+		a.Status, a.Message = TransactionFailed, apiErr.Message
+		return nil, apiErr.Message, true, nil
+	}
+	if err != nil {
+		a.Message = err.Error()
+		return result, "", false, err
+	}
+	a.TransactionID, a.Message = result.TransactionID, result.Message
+
+	if result.Status == TransactionPending {
+		tx, err := c.WaitForCompletion(ctx, result.TransactionID, policy.Interval)
+		if tx != nil {
+			result.Status, result.SerialNumber, result.Message = tx.Status, tx.SerialNumber, tx.Message
+			a.Message = tx.Message
+		}
+		if err != nil && !errors.Is(err, ErrTransactionFailed) {
+			return result, "", false, err
+		}
+	}
+
+	a.Status = result.Status
+	if result.Status == TransactionFailed {
+		return result, result.Message, true, nil
+	}
+	return result, "", false, nil
+}
+
+// IsRejection reports whether a gateway message refusing a purchase is known to mean nothing was
+// charged: insufficient saldo, a closed, disrupted or sold-out product, or an invalid destination.
+// IsRejection melaporkan apakah pesan gateway yang menolak pembelian diketahui berarti tidak ada yang
+// dipotong: saldo tidak cukup, produk tutup, gangguan atau habis, atau nomor tujuan tidak valid.
+func IsRejection(message string) bool {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "saldo") && containsAny(m, "tidak cukup", "tidak mencukupi", "kurang"),
+		strings.Contains(m, "balance") && strings.Contains(m, "insufficient"):
+		return true
+	case containsAny(m, "produk", "product") &&
+		containsAny(m, "gangguan", "tidak tersedia", "unavailable", "tutup", "closed", "nonaktif", "tidak ditemukan", "not found"):
+		return true
+	case containsAny(m, "stok habis", "stok kosong", "out of stock"):
+		return true
+	case containsAny(m, "tujuan", "destination") && containsAny(m, "salah", "tidak valid", "invalid"):
+		return true
+	}
+	return false
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// attemptLog remembers the attempts of PurchaseWithFallback by the original RefID.
+// attemptLog menyimpan percobaan PurchaseWithFallback berdasarkan RefID asli.
+type attemptLog struct {
+	mu      sync.Mutex
+	entries map[string][]PurchaseAttempt
+}
+
+func (l *attemptLog) add(refID string, a PurchaseAttempt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.entries == nil {
+		l.entries = make(map[string][]PurchaseAttempt)
+	}
+	now := time.Now()
+	for k, e := range l.entries {
+		if now.Sub(e[len(e)-1].At) > attemptTTL {
+			delete(l.entries, k)
+		}
+	}
+	l.entries[refID] = append(l.entries[refID], a)
+}
+
+func (l *attemptLog) get(refID string) []PurchaseAttempt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]PurchaseAttempt(nil), l.entries[refID]...)
+}
@@ -0,0 +1,192 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestPurchaseWithFallback(t *testing.T) {
+	// attempt is the expected part of a PurchaseAttempt / bagian PurchaseAttempt yang diharapkan
+	type attempt struct {
+		RefID, ProductCode string
+		Status             orderkuota.TransactionStatus
+		Message            string
+	}
+	tests := []struct {
+		name         string
+		purchases    []string // queued answers of /purchase, then purchase.json / jawaban /purchase berurutan, lalu purchase.json
+		transactions []string // queued answers of /transaction, then transaction_success.json / jawaban /transaction berurutan, lalu transaction_success.json
+		fallbacks    []string
+		policy       orderkuota.FallbackPolicy
+		wantErr      error
+		wantAPIError bool
+		attempts     []attempt
+	}{
+		{
+			name:     "first attempt",
+			attempts: []attempt{{"R1", "S10", orderkuota.TransactionSuccess, "Trx S10 081234567890 SUKSES"}},
+		},
+		{
+			name:      "rejected, next product",
+			purchases: []string{"purchase_unavailable.json"},
+			fallbacks: []string{"S10B"},
+			attempts: []attempt{
+				{"R1", "S10", orderkuota.TransactionFailed, "Produk S10 sedang gangguan"},
+				{"R1-2", "S10B", orderkuota.TransactionSuccess, "Trx S10 081234567890 SUKSES"},
+			},
+		},
+		{
+			name:         "failed, same product again",
+			transactions: []string{"transaction_failed.json"},
+			fallbacks:    []string{"S10B"},
+			policy:       orderkuota.FallbackPolicy{RetrySame: 1},
+			attempts: []attempt{
+				{"R1", "S10", orderkuota.TransactionFailed, "Nomor tujuan tidak aktif"},
+				{"R1-2", "S10", orderkuota.TransactionSuccess, "Trx S10 081234567890 SUKSES"},
+			},
+		},
+		{
+			name:      "every attempt failed",
+			purchases: []string{"purchase_unavailable.json", "purchase_rejected.json", "purchase_unavailable.json"},
+			fallbacks: []string{"S10B", "S10C"},
+			wantErr:   orderkuota.ErrRefundRequired,
+			attempts: []attempt{
+				{"R1", "S10", orderkuota.TransactionFailed, "Produk S10 sedang gangguan"},
+				{"R1-2", "S10B", orderkuota.TransactionFailed, "Saldo tidak mencukupi"},
+				{"R1-3", "S10C", orderkuota.TransactionFailed, "Produk S10 sedang gangguan"},
+			},
+		},
+		{
+			name:      "attempts capped",
+			purchases: []string{"purchase_unavailable.json", "purchase_unavailable.json"},
+			fallbacks: []string{"S10B", "S10C"},
+			policy:    orderkuota.FallbackPolicy{MaxAttempts: 2},
+			wantErr:   orderkuota.ErrRefundRequired,
+			attempts: []attempt{
+				{"R1", "S10", orderkuota.TransactionFailed, "Produk S10 sedang gangguan"},
+				{"R1-2", "S10B", orderkuota.TransactionFailed, "Produk S10 sedang gangguan"},
+			},
+		},
+		{
+			name:         "reason not retryable",
+			transactions: []string{"transaction_failed.json"},
+			fallbacks:    []string{"S10B"},
+			policy:       orderkuota.FallbackPolicy{Retryable: func(reason string) bool { return reason != "Nomor tujuan tidak aktif" }},
+			wantErr:      orderkuota.ErrRefundRequired,
+			attempts:     []attempt{{"R1", "S10", orderkuota.TransactionFailed, "Nomor tujuan tidak aktif"}},
+		},
+		{
+			// A rate limit may come after the charge: never buy the next product
+			name:         "ambiguous gateway error",
+			purchases:    []string{"rate_limited.json"},
+			fallbacks:    []string{"S10B"},
+			wantAPIError: true,
+			attempts:     []attempt{{"R1", "S10", orderkuota.TransactionPending, "gateway error / error gateway: Terlalu banyak request, coba lagi dalam 1 menit"}},
+		},
+		{
+			name:      "custom rejections",
+			purchases: []string{"rate_limited.json"},
+			fallbacks: []string{"S10B"},
+			policy:    orderkuota.FallbackPolicy{Rejected: func(string) bool { return true }},
+			attempts: []attempt{
+				{"R1", "S10", orderkuota.TransactionFailed, "Terlalu banyak request, coba lagi dalam 1 menit"},
+				{"R1-2", "S10B", orderkuota.TransactionSuccess, "Trx S10 081234567890 SUKSES"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json", "/transaction": "transaction_success.json"})
+			srv.queue("/purchase", tt.purchases...)
+			srv.queue("/transaction", tt.transactions...)
+			c := newTestClient(t, srv)
+
+			policy := tt.policy
+			policy.Interval = time.Millisecond
+			req := orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "081234567890", RefID: "R1"}
+			result, err := c.PurchaseWithFallback(context.Background(), req, tt.fallbacks, policy)
+
+			var apiErr *orderkuota.APIError
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAPIError:
+				if !errors.As(err, &apiErr) || errors.Is(err, orderkuota.ErrRefundRequired) {
+					t.Fatalf("err = %v, want the gateway error without ErrRefundRequired", err)
+				}
+			default:
+				if err != nil || result.Status != orderkuota.TransactionSuccess || result.SerialNumber == "" {
+					t.Fatalf("PurchaseWithFallback = %+v, %v, want success with a serial number", result, err)
+				}
+			}
+
+			var got []attempt
+			for i, a := range c.AttemptsFor("R1") {
+				if a.Attempt != i+1 || a.At.IsZero() {
+					t.Fatalf("attempt %d = %+v", i+1, a)
+				}
+				got = append(got, attempt{a.RefID, a.ProductCode, a.Status, a.Message})
+			}
+			if !reflect.DeepEqual(got, tt.attempts) {
+				t.Fatalf("AttemptsFor = %+v, want %+v", got, tt.attempts)
+			}
+
+			// Each attempt was sent once with its own RefID and product
+			var sent []attempt
+			for _, r := range srv.received() {
+				if r.Path == "/purchase" {
+					sent = append(sent, attempt{RefID: r.Params["ref_id"], ProductCode: r.Params["product_code"]})
+				}
+			}
+			if len(sent) != len(tt.attempts) {
+				t.Fatalf("%d purchases sent, want %d", len(sent), len(tt.attempts))
+			}
+			for i := range sent {
+				if sent[i].RefID != tt.attempts[i].RefID || sent[i].ProductCode != tt.attempts[i].ProductCode {
+					t.Fatalf("purchase %d sent %+v, want %s of %s", i+1, sent[i], tt.attempts[i].RefID, tt.attempts[i].ProductCode)
+				}
+			}
+		})
+	}
+}
+
+func TestPurchaseWithFallbackInvalidPolicy(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv)
+	req := orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "081234567890", RefID: "R1"}
+	for _, policy := range []orderkuota.FallbackPolicy{{RetrySame: -1}, {MaxAttempts: -1}} {
+		if _, err := c.PurchaseWithFallback(context.Background(), req, nil, policy); err == nil {
+			t.Errorf("policy %+v accepted", policy)
+		}
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Fatalf("%d requests sent, want none", n)
+	}
+}
+
+func TestIsRejection(t *testing.T) {
+	tests := map[string]bool{
+		"Saldo tidak mencukupi":                           true,
+		"Saldo Anda tidak cukup untuk transaksi ini":      true,
+		"Produk S10 sedang gangguan":                      true,
+		"Stok habis":                                      true,
+		"Nomor tujuan salah":                              true,
+		"Terlalu banyak request, coba lagi dalam 1 menit": false,
+		"Server sedang maintenance":                       false,
+		"Server sedang sibuk":                             false,
+		"Server sedang gangguan, coba lagi nanti":         false,
+		"Transaksi sedang diproses, silakan cek riwayat":  false,
+	}
+	for message, want := range tests {
+		if got := orderkuota.IsRejection(message); got != want {
+			t.Errorf("IsRejection(%q) = %v, want %v", message, got, want)
+		}
+	}
+}
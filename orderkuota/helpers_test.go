@@ -0,0 +1,158 @@
+package orderkuota_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+// request is a request received by a fixtureServer.
+type request struct {
+	Path   string
+	Params map[string]string
+	Header http.Header
+}
+
+// fixtureServer answers each path with a JSON file of testdata and records the requests.
+type fixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	fixtures map[string]string
+	queued   map[string][]string
+	held     map[string]chan struct{}
+	requests []request
+}
+
+// newFixtureServer starts a server answering path with testdata/fixtures[path], stopped when the test ends.
+// A fixture name starting with a status code, like "401", is answered with that status and an empty object.
+func newFixtureServer(t testing.TB, fixtures map[string]string) *fixtureServer {
+	t.Helper()
+	s := &fixtureServer{fixtures: fixtures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, request{Path: r.URL.Path, Params: params, Header: r.Header.Clone()})
+		name, ok := s.fixtures[r.URL.Path]
+		if queue := s.queued[r.URL.Path]; len(queue) > 0 {
+			name, ok = queue[0], true
+			s.queued[r.URL.Path] = queue[1:]
+		}
+		held := s.held[r.URL.Path]
+		s.mu.Unlock()
+
+		if held != nil {
+			select {
+			case <-held:
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case name == "401":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{}`))
+		default:
+			body, err := os.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Errorf("fixture %s: %v", name, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// setFixture answers path with testdata/name from now on.
+func (s *fixtureServer) setFixture(path, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixtures[path] = name
+}
+
+// queue answers the next requests of path with testdata/names in order, then with its fixture again.
+func (s *fixtureServer) queue(path string, names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queued == nil {
+		s.queued = make(map[string][]string)
+	}
+	s.queued[path] = append(s.queued[path], names...)
+}
+
+// hold keeps the requests of path waiting until the returned function is called.
+func (s *fixtureServer) hold(path string) (release func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held == nil {
+		s.held = make(map[string]chan struct{})
+	}
+	ch := make(chan struct{})
+	s.held[path] = ch
+	var once sync.Once
+	return func() { once.Do(func() { close(ch) }) }
+}
+
+// count returns the number of requests received for path.
+func (s *fixtureServer) count(path string) int {
+	n := 0
+	for _, r := range s.received() {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// received returns the requests received so far.
+func (s *fixtureServer) received() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+// newTestClient returns a client of srv logged in as user with token.
+func newTestClient(t testing.TB, srv *fixtureServer, opts ...orderkuota.Option) *orderkuota.Client {
+	t.Helper()
+	c, err := orderkuota.NewClient(auth.Credentials{Username: "user", Token: "token"},
+		append([]orderkuota.Option{orderkuota.WithBaseURL(srv.URL)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+// syncBuffer is a bytes.Buffer safe for a logger writing while the test reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
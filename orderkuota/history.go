@@ -0,0 +1,165 @@
+package orderkuota
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DefaultTransactionsPerPage is the page size used by TransactionsPager when none is set.
+// DefaultTransactionsPerPage adalah ukuran halaman yang dipakai TransactionsPager jika tidak diatur.
+const DefaultTransactionsPerPage = 50
+
+// TransactionQuery narrows the purchase history fetched from the gateway.
+// TransactionQuery mempersempit riwayat pembelian yang diambil dari gateway.
+//
+// Zero values mean "gateway default". Filters are also applied locally,
+// so results are correct even if the gateway ignores them.
+// Nilai nol berarti "default gateway". Filter juga diterapkan secara lokal,
+// sehingga hasil tetap benar walaupun gateway mengabaikannya.
+type TransactionQuery struct {
+	From        time.Time         // Inclusive start / Awal (inklusif)
+	To          time.Time         // Exclusive end / Akhir (eksklusif)
+	Status      TransactionStatus // Empty for every status / Kosong untuk semua status
+	ProductCode string            // Empty for every product / Kosong untuk semua produk
+	Page        int               // Page number starting at 1 / Nomor halaman mulai dari 1
+	PerPage     int               // Rows per page / Jumlah baris per halaman
+}
+
+// matches reports whether tx passes the filters of the query.
+// matches melaporkan apakah tx lolos filter dari query.
+func (tq TransactionQuery) matches(tx Transaction) bool {
+	if tq.Status != "" && tx.Status != tq.Status {
+		return false
+	}
+	if tq.ProductCode != "" && tx.ProductCode != tq.ProductCode {
+		return false
+	}
+	if !tq.From.IsZero() && (tx.Date.IsZero() || tx.Date.Before(tq.From)) {
+		return false
+	}
+	if !tq.To.IsZero() && (tx.Date.IsZero() || !tx.Date.Before(tq.To)) {
+		return false
+	}
+	return true
+}
+
+// ListTransactions fetches one page of the purchase history.
+// ListTransactions mengambil satu halaman riwayat pembelian.
+func (c *Client) ListTransactions(ctx context.Context, q TransactionQuery) ([]Transaction, error) {
+	txs, _, _, err := c.listTransactions(ctx, q)
+	return txs, err
+}
+
+// listTransactions fetches one page and also returns the unfiltered row count and the total page count.
+// listTransactions mengambil satu halaman dan juga mengembalikan jumlah baris sebelum filter dan jumlah halaman.
+func (c *Client) listTransactions(ctx context.Context, q TransactionQuery) (txs []Transaction, rows, totalPages int, err error) {
+	params := map[string]string{}
+	if !q.From.IsZero() {
+		params["date_from"] = q.From.In(c.loc).Format(dateLayout)
+	}
+	if !q.To.IsZero() {
+		params["date_to"] = q.To.In(c.loc).Format(dateLayout)
+	}
+	if q.Status != "" {
+		params["status"] = string(q.Status)
+	}
+	if q.ProductCode != "" {
+		params["product_code"] = q.ProductCode
+	}
+	if q.Page > 0 {
+		params["page"] = strconv.Itoa(q.Page)
+	}
+	if q.PerPage > 0 {
+		params["per_page"] = strconv.Itoa(q.PerPage)
+	}
+
+	var results struct {
+		TotalPages int              `json:"total_pages"`
+		Data       []transactionRow `json:"data"`
+	}
+	if err := c.do(ctx, "/transactions", params, &results); err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, row := range results.Data {
+		tx := *row.transaction(c.loc)
+		tx.SerialNumber = c.serialNumber(tx.ProductCode, tx.SerialNumber, tx.Message)
+		if q.matches(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, len(results.Data), results.TotalPages, nil
+}
+
+// TransactionsPager walks the whole purchase history matching a query, one transaction at a time.
+// TransactionsPager menelusuri seluruh riwayat pembelian yang cocok dengan query, satu per satu.
+//
+//	pager := client.Transactions(orderkuota.TransactionQuery{From: from})
+//	for pager.Next(ctx) {
+//		tx := pager.Transaction()
+//	}
+//	if err := pager.Err(); err != nil {
+//		// handle error
+//	}
+type TransactionsPager struct {
+	c     *Client
+	query TransactionQuery
+
+	buf     []Transaction
+	current Transaction
+	done    bool
+	err     error
+}
+
+// Transactions returns a pager over every transaction matching q, starting at q.Page (default 1).
+// Transactions mengembalikan pager untuk semua transaksi yang cocok dengan q, mulai dari q.Page (default 1).
+func (c *Client) Transactions(q TransactionQuery) *TransactionsPager {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PerPage <= 0 {
+		q.PerPage = DefaultTransactionsPerPage
+	}
+	return &TransactionsPager{c: c, query: q}
+}
+
+// Next advances to the next transaction, fetching pages as needed.
+// It returns false at the end of the history or on error; check Err afterwards.
+// Next maju ke transaksi berikutnya dan mengambil halaman bila perlu.
+// Mengembalikan false di akhir riwayat atau saat error; periksa Err setelahnya.
+func (p *TransactionsPager) Next(ctx context.Context) bool {
+	for len(p.buf) == 0 {
+		if p.done || p.err != nil {
+			return false
+		}
+
+		txs, rows, totalPages, err := p.c.listTransactions(ctx, p.query)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.buf = txs
+
+		// Stop on a short page or after the last page the gateway reported
+		if rows < p.query.PerPage || (totalPages > 0 && p.query.Page >= totalPages) {
+			p.done = true
+		}
+		p.query.Page++
+	}
+
+	p.current, p.buf = p.buf[0], p.buf[1:]
+	return true
+}
+
+// Transaction returns the transaction Next advanced to.
+// Transaction mengembalikan transaksi yang dituju oleh Next.
+func (p *TransactionsPager) Transaction() Transaction {
+	return p.current
+}
+
+// Err returns the error that stopped Next, if any.
+// Err mengembalikan error yang menghentikan Next, jika ada.
+func (p *TransactionsPager) Err() error {
+	return p.err
+}
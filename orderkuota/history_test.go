@@ -0,0 +1,139 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestListTransactions(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/transactions": "transactions_page1.json"})
+	c := newTestClient(t, srv)
+
+	all, err := c.ListTransactions(context.Background(), orderkuota.TransactionQuery{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	want := orderkuota.Transaction{
+		TransactionID: "2",
+		RefID:         "R2",
+		ProductCode:   "S20",
+		Destination:   "081234567891",
+		Status:        orderkuota.TransactionFailed,
+		Price:         20500,
+		Message:       "Nomor tujuan tidak aktif",
+		Date:          time.Date(2024, 5, 1, 10, 0, 0, 0, qris.WIB),
+	}
+	if len(all) != 2 || !reflect.DeepEqual(all[1], want) {
+		t.Fatalf("ListTransactions = %+v, want T1 then %+v", all, want)
+	}
+
+	// Filters are sent, in the client location, and applied again locally
+	utc := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	got, err := c.ListTransactions(context.Background(), orderkuota.TransactionQuery{
+		From:    utc,
+		To:      utc.Add(24 * time.Hour),
+		Status:  orderkuota.TransactionSuccess,
+		Page:    3,
+		PerPage: 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TransactionID != "T1" {
+		t.Fatalf("filtered ListTransactions = %+v, want only T1", got)
+	}
+	params := srv.received()[1].Params
+	wantParams := map[string]string{
+		"date_from": "2024-05-01 07:00:00",
+		"date_to":   "2024-05-02 07:00:00",
+		"status":    string(orderkuota.TransactionSuccess),
+		"page":      "3",
+		"per_page":  "20",
+	}
+	for k, v := range wantParams {
+		if params[k] != v {
+			t.Fatalf("param %s = %q, want %q (sent %v)", k, params[k], v, params)
+		}
+	}
+
+	// T1 at 09:00 WIB is before 03:00 UTC
+	if got, _ := c.ListTransactions(context.Background(), orderkuota.TransactionQuery{From: utc.Add(3 * time.Hour)}); len(got) != 1 || got[0].TransactionID != "2" {
+		t.Fatalf("ListTransactions from 03:00 UTC = %+v, want only 2", got)
+	}
+}
+
+func TestTransactionsPager(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   orderkuota.TransactionQuery
+		pages   []string // answers of /transactions in order / jawaban /transactions berurutan
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "every page",
+			query: orderkuota.TransactionQuery{PerPage: 2},
+			pages: []string{"transactions_page1.json", "transactions_page2.json"},
+			want:  []string{"T1", "2", "T3"},
+		},
+		{
+			name:  "filtered",
+			query: orderkuota.TransactionQuery{PerPage: 2, ProductCode: "S10"},
+			pages: []string{"transactions_page1.json", "transactions_page2.json"},
+			want:  []string{"T1", "T3"},
+		},
+		{
+			name:  "short page ends the history",
+			query: orderkuota.TransactionQuery{PerPage: 5},
+			pages: []string{"transactions_page1.json"},
+			want:  []string{"T1", "2"},
+		},
+		{
+			name:    "error on the second page",
+			query:   orderkuota.TransactionQuery{PerPage: 2},
+			pages:   []string{"transactions_page1.json", "401"},
+			want:    []string{"T1", "2"},
+			wantErr: orderkuota.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/transactions": "transactions_page2.json"})
+			srv.queue("/transactions", tt.pages...)
+			c := newTestClient(t, srv)
+
+			pager := c.Transactions(tt.query)
+			var got []string
+			for pager.Next(context.Background()) {
+				got = append(got, pager.Transaction().TransactionID)
+			}
+			if !errors.Is(pager.Err(), tt.wantErr) || tt.wantErr == nil && pager.Err() != nil {
+				t.Fatalf("Err = %v, want %v", pager.Err(), tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("transactions %v, want %v", got, tt.want)
+			}
+			if pager.Next(context.Background()) {
+				t.Fatal("Next after the end returned true")
+			}
+
+			// One request per page, numbered from 1
+			requests := srv.received()
+			if len(requests) != len(tt.pages) {
+				t.Fatalf("%d pages fetched, want %d", len(requests), len(tt.pages))
+			}
+			for i, r := range requests {
+				if want := strconv.Itoa(i + 1); r.Params["page"] != want {
+					t.Fatalf("request %d asked page %q, want %s", i, r.Params["page"], want)
+				}
+			}
+		})
+	}
+}
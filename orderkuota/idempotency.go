@@ -0,0 +1,259 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long completed purchases are replayed when WithIdempotencyStore is given no TTL.
+// DefaultIdempotencyTTL adalah lama pembelian yang selesai diputar ulang jika WithIdempotencyStore tidak diberi TTL.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyPoll is how often Purchase looks at a purchase in progress in another process.
+// idempotencyPoll adalah seberapa sering Purchase memeriksa pembelian yang sedang berjalan di proses lain.
+const idempotencyPoll = 500 * time.Millisecond
+
+// IdempotencyRecord is the state of a purchase kept by an IdempotencyStore. Result is nil while
+// the purchase is in progress.
+// IdempotencyRecord adalah status pembelian yang disimpan IdempotencyStore. Result bernilai nil
+// selama pembelian masih berjalan.
+type IdempotencyRecord struct {
+	RefID     string          // Client reference ID / ID referensi client
+	StartedAt time.Time       // When the purchase was started / Waktu pembelian dimulai
+	Result    *PurchaseResult // Gateway answer once completed / Jawaban gateway setelah selesai
+}
+
+// IdempotencyStore records purchases by RefID so a repeated Purchase returns the first result
+// instead of ordering again. Share one store between processes to protect them all.
+// IdempotencyStore mencatat pembelian berdasarkan RefID sehingga Purchase yang diulang mengembalikan
+// hasil pertama alih-alih memesan lagi. Pakai satu store bersama antar proses untuk melindungi semuanya.
+type IdempotencyStore interface {
+	// Reserve records refID as in progress since startedAt and returns nil, unless a record that
+	// has not expired exists, which is returned instead.
+	// Reserve mencatat refID sebagai sedang berjalan sejak startedAt dan mengembalikan nil, kecuali
+	// sudah ada record yang belum kedaluwarsa, yang dikembalikan sebagai gantinya.
+	Reserve(ctx context.Context, refID string, startedAt time.Time) (*IdempotencyRecord, error)
+	// Complete stores the result of refID, replayed until expiresAt.
+	// Complete menyimpan hasil dari refID, yang diputar ulang sampai expiresAt.
+	Complete(ctx context.Context, refID string, result *PurchaseResult, expiresAt time.Time) error
+	// Release forgets refID, after the gateway rejected the purchase.
+	// Release melupakan refID, setelah gateway menolak pembelian.
+	Release(ctx context.Context, refID string) error
+}
+
+// WithIdempotencyStore makes Purchase consult store for every request with a RefID: concurrent
+// calls with the same RefID wait for the first one and return its result, and completed results
+// are replayed for ttl (DefaultIdempotencyTTL if 0).
+// WithIdempotencyStore membuat Purchase memeriksa store untuk setiap request yang memiliki RefID:
+// panggilan bersamaan dengan RefID yang sama menunggu panggilan pertama dan mengembalikan hasilnya,
+// dan hasil yang selesai diputar ulang selama ttl (DefaultIdempotencyTTL jika 0).
+//
+// A purchase left in progress, e.g. by a crash between the gateway call and the store write, is
+// recovered by looking the transaction up by RefID once the purchase timeout has passed. It is sent
+// again only when the gateway answers that the RefID is unknown; any other lookup failure is
+// returned and the purchase stays in progress.
+// Pembelian yang tertinggal dalam status berjalan, misalnya karena crash di antara panggilan gateway
+// dan penulisan store, dipulihkan dengan mencari transaksinya berdasarkan RefID setelah timeout pembelian lewat.
+// Pembelian hanya dikirim ulang jika gateway menjawab bahwa RefID tidak dikenal; kegagalan pencarian
+// lainnya dikembalikan dan pembelian tetap dalam status berjalan.
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = DefaultIdempotencyTTL
+		}
+		c.idempotency = store
+		c.idempotencyTTL = ttl
+	}
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore kept in memory, protecting a single process.
+// MemoryIdempotencyStore adalah IdempotencyStore yang disimpan di memori, melindungi satu proses.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyEntry
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time // Zero while in progress / Nol selama masih berjalan
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore.
+// NewMemoryIdempotencyStore membuat MemoryIdempotencyStore kosong.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]memoryIdempotencyEntry)}
+}
+
+// Reserve implements IdempotencyStore.
+// Reserve mengimplementasikan IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, refID string, startedAt time.Time) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range s.records {
+		if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+			delete(s.records, k)
+		}
+	}
+	if e, ok := s.records[refID]; ok {
+		record := e.record
+		return &record, nil
+	}
+	s.records[refID] = memoryIdempotencyEntry{record: IdempotencyRecord{RefID: refID, StartedAt: startedAt}}
+	return nil, nil
+}
+
+// Complete implements IdempotencyStore.
+// Complete mengimplementasikan IdempotencyStore.
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, refID string, result *PurchaseResult, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.records[refID]
+	e.record.RefID = refID
+	e.record.Result = result
+	e.expiresAt = expiresAt
+	s.records[refID] = e
+	return nil
+}
+
+// Release implements IdempotencyStore.
+// Release mengimplementasikan IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, refID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, refID)
+	return nil
+}
+
+// purchaseCall is a Purchase in flight in this process, awaited by later calls with the same RefID.
+// purchaseCall adalah Purchase yang sedang berjalan di proses ini, ditunggu oleh panggilan berikutnya dengan RefID yang sama.
+type purchaseCall struct {
+	done   chan struct{}
+	result *PurchaseResult
+	err    error
+}
+
+// purchaseCalls tracks the Purchase calls in flight by RefID.
+// purchaseCalls melacak panggilan Purchase yang sedang berjalan berdasarkan RefID.
+type purchaseCalls struct {
+	mu    sync.Mutex
+	calls map[string]*purchaseCall
+}
+
+// idempotentPurchase runs send for refID at most once across the callers sharing the store.
+// idempotentPurchase menjalankan send untuk refID paling banyak satu kali di antara pemanggil yang memakai store yang sama.
+func (c *Client) idempotentPurchase(ctx context.Context, refID string, timeout time.Duration, send func() (*PurchaseResult, error)) (*PurchaseResult, error) {
+	c.purchases.mu.Lock()
+	if call, ok := c.purchases.calls[refID]; ok {
+		c.purchases.mu.Unlock()
+		select {
+		case <-call.done:
+			return copyPurchaseResult(call.result), call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if c.purchases.calls == nil {
+		c.purchases.calls = make(map[string]*purchaseCall)
+	}
+	call := &purchaseCall{done: make(chan struct{})}
+	c.purchases.calls[refID] = call
+	c.purchases.mu.Unlock()
+
+	call.result, call.err = c.reservePurchase(ctx, refID, timeout, send)
+
+	c.purchases.mu.Lock()
+	delete(c.purchases.calls, refID)
+	c.purchases.mu.Unlock()
+	close(call.done)
+	return copyPurchaseResult(call.result), call.err
+}
+
+// reservePurchase reserves refID in the store, sends the purchase and records its result, or
+// returns the result of an earlier purchase with refID.
+// reservePurchase memesan refID di store, mengirim pembelian dan mencatat hasilnya, atau
+// mengembalikan hasil pembelian sebelumnya dengan refID.
+func (c *Client) reservePurchase(ctx context.Context, refID string, timeout time.Duration, send func() (*PurchaseResult, error)) (*PurchaseResult, error) {
+	for {
+		record, err := c.idempotency.Reserve(ctx, refID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve ref ID / gagal memesan ref ID: %w", err)
+		}
+		if record == nil {
+			break
+		}
+		if record.Result != nil {
+			return record.Result, nil
+		}
+
+		// In progress elsewhere: wait for it, then recover it from the gateway once it is overdue
+		if wait := timeout - time.Since(record.StartedAt); wait > 0 {
+			select {
+			case <-time.After(min(wait, idempotencyPoll)):
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		result, err := c.transactionByRef(ctx, refID)
+		if errors.Is(err, ErrTransactionNotFound) {
+			// The gateway never saw it; start over
+			if err := c.releasePurchase(ctx, refID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			// Busy, rate-limited or down: the purchase may have been charged, so it stays in progress
+			return nil, fmt.Errorf("purchase %s in progress, lookup failed / pembelian %s sedang berjalan, pencarian gagal: %w", refID, refID, err)
+		}
+		return result, c.completePurchase(ctx, refID, result)
+	}
+
+	result, err := send()
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		// Rejected by the gateway, so the purchase can be tried again
+		if releaseErr := c.releasePurchase(ctx, refID); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+	if err != nil {
+		// The outcome is unknown; the record stays in progress and is recovered later
+		return nil, err
+	}
+	return result, c.completePurchase(ctx, refID, result)
+}
+
+// releasePurchase forgets refID in the store so the purchase can be sent again.
+// releasePurchase melupakan refID di store sehingga pembelian dapat dikirim lagi.
+func (c *Client) releasePurchase(ctx context.Context, refID string) error {
+	if err := c.idempotency.Release(ctx, refID); err != nil {
+		return fmt.Errorf("failed to release ref ID / gagal melepas ref ID: %w", err)
+	}
+	return nil
+}
+
+// completePurchase records result in the store.
+// completePurchase mencatat result di store.
+func (c *Client) completePurchase(ctx context.Context, refID string, result *PurchaseResult) error {
+	if err := c.idempotency.Complete(ctx, refID, result, time.Now().Add(c.idempotencyTTL)); err != nil {
+		return fmt.Errorf("purchase done but not recorded / pembelian selesai tetapi tidak tercatat: %w", err)
+	}
+	return nil
+}
+
+func copyPurchaseResult(r *PurchaseResult) *PurchaseResult {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
@@ -0,0 +1,242 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+// testPurchase is the purchase sent by the idempotency tests.
+var testPurchase = orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "081234567890", RefID: "R1"}
+
+// recordOf returns the record of refID in store without changing it, nil if there is none.
+func recordOf(t *testing.T, store *orderkuota.MemoryIdempotencyStore, refID string) *orderkuota.IdempotencyRecord {
+	t.Helper()
+	record, err := store.Reserve(context.Background(), refID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil {
+		store.Release(context.Background(), refID)
+	}
+	return record
+}
+
+func TestIdempotentPurchaseConcurrent(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	release := srv.hold("/purchase")
+	defer release()
+
+	// Two clients sharing a store stand for two processes
+	store := orderkuota.NewMemoryIdempotencyStore()
+	clients := []*orderkuota.Client{
+		newTestClient(t, srv, orderkuota.WithIdempotencyStore(store, 0)),
+		newTestClient(t, srv, orderkuota.WithIdempotencyStore(store, 0)),
+	}
+
+	const calls = 6
+	var wg sync.WaitGroup
+	results := make([]*orderkuota.PurchaseResult, calls)
+	errs := make([]error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = clients[i%2].Purchase(context.Background(), testPurchase)
+		}(i)
+	}
+	for srv.count("/purchase") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	release()
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i] == nil || results[i].TransactionID != "T1" {
+			t.Fatalf("call %d = %+v, %v, want T1", i, results[i], errs[i])
+		}
+	}
+	if n := srv.count("/purchase"); n != 1 {
+		t.Fatalf("%d purchases sent, want 1", n)
+	}
+
+	// Results are copies: changing one does not change the replay
+	results[0].Status = orderkuota.TransactionFailed
+	replay, err := clients[1].Purchase(context.Background(), testPurchase)
+	if err != nil || replay.Status != orderkuota.TransactionPending {
+		t.Fatalf("replay = %+v, %v, want the pending result", replay, err)
+	}
+	if n := srv.count("/purchase"); n != 1 {
+		t.Fatalf("%d purchases sent after the replay, want 1", n)
+	}
+}
+
+func TestIdempotentPurchaseRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		lookup     string // fixture of /transaction / fixture dari /transaction
+		want       string // transaction ID, "" for an error / ID transaksi, "" untuk error
+		wantStatus orderkuota.TransactionStatus
+		wantErr    error // nil with apiErr for any *APIError / nil dengan apiErr untuk *APIError apa pun
+		apiErr     bool
+		paths      []string
+		completed  bool
+	}{
+		{
+			name:       "charged before the crash",
+			lookup:     "transaction_sn.json",
+			want:       "T1",
+			wantStatus: orderkuota.TransactionSuccess,
+			paths:      []string{"/transaction"},
+			completed:  true,
+		},
+		{
+			name:       "never reached the gateway",
+			lookup:     "transaction_not_found.json",
+			want:       "T1",
+			wantStatus: orderkuota.TransactionPending,
+			paths:      []string{"/transaction", "/purchase"},
+			completed:  true,
+		},
+		{
+			name:   "lookup rate-limited",
+			lookup: "rate_limited.json",
+			apiErr: true,
+			paths:  []string{"/transaction"},
+		},
+		{
+			name:    "lookup unauthorized",
+			lookup:  "401",
+			wantErr: orderkuota.ErrUnauthorized,
+			paths:   []string{"/transaction"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/transaction": tt.lookup, "/purchase": "purchase.json"})
+			store := orderkuota.NewMemoryIdempotencyStore()
+			c := newTestClient(t, srv, orderkuota.WithIdempotencyStore(store, 0))
+
+			// A process crashed a minute ago while sending R1
+			if _, err := store.Reserve(context.Background(), "R1", time.Now().Add(-time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			result, err := c.Purchase(context.Background(), testPurchase, orderkuota.WithPurchaseTimeout(time.Second))
+
+			var apiErr *orderkuota.APIError
+			if tt.wantErr != nil || tt.apiErr {
+				if tt.apiErr && !errors.As(err, &apiErr) || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v (APIError %v)", err, tt.wantErr, tt.apiErr)
+				}
+				if errors.Is(err, orderkuota.ErrTransactionNotFound) {
+					t.Fatalf("err = %v reported as not found", err)
+				}
+			} else if err != nil || result.TransactionID != tt.want || result.Status != tt.wantStatus {
+				t.Fatalf("Purchase = %+v, %v, want %s %s", result, err, tt.want, tt.wantStatus)
+			}
+
+			var paths []string
+			for _, r := range srv.received() {
+				paths = append(paths, r.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Fatalf("requests %v, want %v", paths, tt.paths)
+			}
+
+			// An unknown outcome keeps R1 in progress, so it is never sent again blindly
+			record := recordOf(t, store, "R1")
+			if record == nil || (record.Result != nil) != tt.completed {
+				t.Fatalf("record = %+v, want completed = %v", record, tt.completed)
+			}
+		})
+	}
+}
+
+func TestIdempotentPurchaseInProgress(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/transaction": "transaction_sn.json", "/purchase": "purchase.json"})
+	store := orderkuota.NewMemoryIdempotencyStore()
+	c := newTestClient(t, srv, orderkuota.WithIdempotencyStore(store, 0))
+	if _, err := store.Reserve(context.Background(), "R1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not overdue yet: wait for the other process without calling the gateway
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.Purchase(ctx, testPurchase, orderkuota.WithPurchaseTimeout(time.Hour)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Fatalf("%d requests sent, want none", n)
+	}
+}
+
+func TestIdempotentPurchaseRejected(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	srv.queue("/purchase", "purchase_rejected.json")
+	store := orderkuota.NewMemoryIdempotencyStore()
+	c := newTestClient(t, srv, orderkuota.WithIdempotencyStore(store, 0))
+
+	_, err := c.Purchase(context.Background(), testPurchase)
+	var apiErr *orderkuota.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Saldo tidak mencukupi" {
+		t.Fatalf("err = %v, want the rejection", err)
+	}
+	if record := recordOf(t, store, "R1"); record != nil {
+		t.Fatalf("record = %+v after the rejection, want it released", record)
+	}
+
+	// The rejected RefID can be sent again, then it is replayed
+	for i := 0; i < 2; i++ {
+		if result, err := c.Purchase(context.Background(), testPurchase); err != nil || result.TransactionID != "T1" {
+			t.Fatalf("Purchase %d = %+v, %v", i, result, err)
+		}
+	}
+	if n := srv.count("/purchase"); n != 2 {
+		t.Fatalf("%d purchases sent, want 2", n)
+	}
+}
+
+var errStoreDown = errors.New("store down")
+
+// unreleasableStore is a MemoryIdempotencyStore whose Release fails.
+type unreleasableStore struct {
+	*orderkuota.MemoryIdempotencyStore
+}
+
+func (unreleasableStore) Release(context.Context, string) error { return errStoreDown }
+
+func TestIdempotentPurchaseReleaseError(t *testing.T) {
+	t.Run("rejected send", func(t *testing.T) {
+		srv := newFixtureServer(t, map[string]string{"/purchase": "purchase_rejected.json"})
+		c := newTestClient(t, srv, orderkuota.WithIdempotencyStore(unreleasableStore{orderkuota.NewMemoryIdempotencyStore()}, 0))
+
+		_, err := c.Purchase(context.Background(), testPurchase)
+		var apiErr *orderkuota.APIError
+		if !errors.As(err, &apiErr) || !errors.Is(err, errStoreDown) {
+			t.Fatalf("err = %v, want the rejection and the release failure", err)
+		}
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		srv := newFixtureServer(t, map[string]string{"/transaction": "transaction_not_found.json", "/purchase": "purchase.json"})
+		store := orderkuota.NewMemoryIdempotencyStore()
+		c := newTestClient(t, srv, orderkuota.WithIdempotencyStore(unreleasableStore{store}, 0))
+		if _, err := store.Reserve(context.Background(), "R1", time.Now().Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := c.Purchase(context.Background(), testPurchase, orderkuota.WithPurchaseTimeout(time.Second)); !errors.Is(err, errStoreDown) {
+			t.Fatalf("err = %v, want the release failure", err)
+		}
+		if n := srv.count("/purchase"); n != 0 {
+			t.Fatalf("%d purchases sent without releasing the ref ID, want none", n)
+		}
+	})
+}
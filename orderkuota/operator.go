@@ -0,0 +1,98 @@
+package orderkuota
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operator is an Indonesian mobile operator.
+// Operator adalah operator seluler Indonesia.
+type Operator string
+
+// Known operators.
+// Operator yang dikenal.
+const (
+	OperatorTelkomsel Operator = "Telkomsel"
+	OperatorIndosat   Operator = "Indosat"
+	OperatorXL        Operator = "XL"
+	OperatorAxis      Operator = "Axis"
+	OperatorTri       Operator = "Tri"
+	OperatorSmartfren Operator = "Smartfren"
+)
+
+// Phone number errors.
+// Error nomor HP.
+var (
+	ErrInvalidMSISDN   = errors.New("invalid phone number / nomor HP tidak valid")
+	ErrUnknownOperator = errors.New("unknown operator prefix / prefix operator tidak dikenal")
+)
+
+// OperatorPrefixes maps four-digit local prefixes to operators. Add or override entries
+// during program initialization when operators release new prefixes.
+// OperatorPrefixes memetakan prefix lokal empat digit ke operator. Tambah atau ganti entri
+// saat inisialisasi program ketika operator merilis prefix baru.
+var OperatorPrefixes = map[string]Operator{
+	"0811": OperatorTelkomsel, "0812": OperatorTelkomsel, "0813": OperatorTelkomsel,
+	"0821": OperatorTelkomsel, "0822": OperatorTelkomsel, "0823": OperatorTelkomsel,
+	"0851": OperatorTelkomsel, "0852": OperatorTelkomsel, "0853": OperatorTelkomsel,
+
+	"0814": OperatorIndosat, "0815": OperatorIndosat, "0816": OperatorIndosat,
+	"0855": OperatorIndosat, "0856": OperatorIndosat, "0857": OperatorIndosat, "0858": OperatorIndosat,
+
+	"0817": OperatorXL, "0818": OperatorXL, "0819": OperatorXL,
+	"0859": OperatorXL, "0877": OperatorXL, "0878": OperatorXL,
+
+	"0831": OperatorAxis, "0832": OperatorAxis, "0833": OperatorAxis, "0838": OperatorAxis,
+
+	"0895": OperatorTri, "0896": OperatorTri, "0897": OperatorTri, "0898": OperatorTri, "0899": OperatorTri,
+
+	"0881": OperatorSmartfren, "0882": OperatorSmartfren, "0883": OperatorSmartfren,
+	"0884": OperatorSmartfren, "0885": OperatorSmartfren, "0886": OperatorSmartfren,
+	"0887": OperatorSmartfren, "0888": OperatorSmartfren, "0889": OperatorSmartfren,
+}
+
+// msisdnSeparators removes the separators people write phone numbers with.
+// msisdnSeparators menghapus pemisah yang biasa dipakai saat menulis nomor HP.
+var msisdnSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// NormalizeMSISDN converts +62, 62, and 0 formats into the local 08 format and checks the length.
+// NormalizeMSISDN mengubah format +62, 62, dan 0 menjadi format lokal 08 dan memeriksa panjangnya.
+func NormalizeMSISDN(s string) (string, error) {
+	n := strings.TrimPrefix(msisdnSeparators.Replace(strings.TrimSpace(s)), "+")
+	switch {
+	case strings.HasPrefix(n, "62"):
+		n = "0" + n[2:]
+	case strings.HasPrefix(n, "8"):
+		n = "0" + n
+	}
+
+	if !strings.HasPrefix(n, "08") || len(n) < 10 || len(n) > 13 || !isNumeric(n) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMSISDN, s)
+	}
+	return n, nil
+}
+
+// DetectOperator returns the operator of a phone number using OperatorPrefixes.
+// DetectOperator mengembalikan operator dari nomor HP berdasarkan OperatorPrefixes.
+func DetectOperator(msisdn string) (Operator, error) {
+	n, err := NormalizeMSISDN(msisdn)
+	if err != nil {
+		return "", err
+	}
+
+	op, ok := OperatorPrefixes[n[:4]]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownOperator, n[:4])
+	}
+	return op, nil
+}
+
+// isPhoneNumber reports whether a destination is written as a phone number rather than
+// a meter, customer, or game account number.
+// isPhoneNumber melaporkan apakah nomor tujuan ditulis sebagai nomor HP, bukan nomor
+// meter, pelanggan, atau akun game.
+func isPhoneNumber(s string) bool {
+	s = strings.TrimPrefix(msisdnSeparators.Replace(strings.TrimSpace(s)), "+")
+	return strings.HasPrefix(s, "08") || strings.HasPrefix(s, "628")
+}
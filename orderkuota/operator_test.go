@@ -0,0 +1,68 @@
+package orderkuota_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestNormalizeMSISDN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // "" for ErrInvalidMSISDN / "" untuk ErrInvalidMSISDN
+	}{
+		{"081234567890", "081234567890"},
+		{"+62 812-3456-7890", "081234567890"},
+		{"62812.3456.7890", "081234567890"},
+		{"812 3456 7890", "081234567890"},
+		{"(0812) 345678", "0812345678"},
+		{"0812345", ""},
+		{"08123456789012", ""},
+		{"0212345678", ""},
+		{"0812345678a", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := orderkuota.NormalizeMSISDN(tt.in)
+		if tt.want == "" {
+			if !errors.Is(err, orderkuota.ErrInvalidMSISDN) {
+				t.Errorf("NormalizeMSISDN(%q) = %q, %v, want ErrInvalidMSISDN", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeMSISDN(%q) = %q, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestDetectOperator(t *testing.T) {
+	tests := []struct {
+		msisdn  string
+		want    orderkuota.Operator
+		wantErr error
+	}{
+		{msisdn: "081234567890", want: orderkuota.OperatorTelkomsel},
+		{msisdn: "+6285612345678", want: orderkuota.OperatorIndosat},
+		{msisdn: "0877-1234-5678", want: orderkuota.OperatorXL},
+		{msisdn: "083812345678", want: orderkuota.OperatorAxis},
+		{msisdn: "62896 1234 5678", want: orderkuota.OperatorTri},
+		{msisdn: "088112345678", want: orderkuota.OperatorSmartfren},
+		{msisdn: "080012345678", wantErr: orderkuota.ErrUnknownOperator},
+		{msisdn: "0812", wantErr: orderkuota.ErrInvalidMSISDN},
+	}
+	for _, tt := range tests {
+		got, err := orderkuota.DetectOperator(tt.msisdn)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("DetectOperator(%q) = %q, %v, want %q, %v", tt.msisdn, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	// New prefixes are added to the exported table
+	orderkuota.OperatorPrefixes["0800"] = orderkuota.OperatorTelkomsel
+	t.Cleanup(func() { delete(orderkuota.OperatorPrefixes, "0800") })
+	if got, err := orderkuota.DetectOperator("080012345678"); err != nil || got != orderkuota.OperatorTelkomsel {
+		t.Fatalf("DetectOperator of an added prefix = %q, %v, want Telkomsel", got, err)
+	}
+}
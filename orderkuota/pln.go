@@ -0,0 +1,161 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PLN inquiry errors.
+// Error inquiry PLN.
+var (
+	ErrCustomerNotFound = errors.New("PLN customer not found / pelanggan PLN tidak ditemukan")
+	ErrMeterBlocked     = errors.New("PLN meter is blocked / meteran PLN diblokir")
+	ErrInquiryRequired  = errors.New("PLN purchase requires a matching inquiry / pembelian PLN membutuhkan inquiry yang cocok")
+)
+
+// inquiryTTL is how long an inquiry reference can be used for a purchase.
+// inquiryTTL adalah lama referensi inquiry dapat dipakai untuk pembelian.
+const inquiryTTL = 30 * time.Minute
+
+// PLNCustomer is the result of a PLN prepaid inquiry.
+// PLNCustomer adalah hasil inquiry PLN prabayar.
+type PLNCustomer struct {
+	CustomerNo string // Normalized meter or customer number / Nomor meter atau pelanggan yang dinormalisasi
+	Name       string // Customer name / Nama pelanggan
+	Tariff     string // Tariff class, e.g. "R1" / Golongan tarif, misalnya "R1"
+	Power      int64  // Power (daya) in VA / Daya dalam VA
+	InquiryRef string // Pass as PurchaseRequest.InquiryRef / Kirim sebagai PurchaseRequest.InquiryRef
+}
+
+// WithPLNInquiryRequired makes Purchase reject PLN products without an InquiryRef from PLNInquiry
+// for the same customer number.
+// WithPLNInquiryRequired membuat Purchase menolak produk PLN tanpa InquiryRef dari PLNInquiry
+// untuk nomor pelanggan yang sama.
+func WithPLNInquiryRequired() Option {
+	return func(c *Client) {
+		c.requireInquiry = true
+	}
+}
+
+// inquiryLog remembers the inquiries made by a Client.
+// inquiryLog menyimpan inquiry yang dilakukan oleh Client.
+type inquiryLog struct {
+	mu      sync.Mutex
+	entries map[string]inquiryEntry
+}
+
+type inquiryEntry struct {
+	customerNo string
+	at         time.Time
+}
+
+func (l *inquiryLog) add(ref, customerNo string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.entries == nil {
+		l.entries = make(map[string]inquiryEntry)
+	}
+	now := time.Now()
+	for k, e := range l.entries {
+		if now.Sub(e.at) > inquiryTTL {
+			delete(l.entries, k)
+		}
+	}
+	l.entries[ref] = inquiryEntry{customerNo: customerNo, at: now}
+}
+
+// matches reports whether ref was issued for customerNo and is still fresh.
+// matches melaporkan apakah ref diterbitkan untuk customerNo dan masih berlaku.
+func (l *inquiryLog) matches(ref, customerNo string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[ref]
+	return ok && e.customerNo == customerNo && time.Since(e.at) <= inquiryTTL
+}
+
+// PLNInquiry resolves a meter or customer number into the customer's name and tariff.
+// PLNInquiry mengubah nomor meter atau pelanggan menjadi nama dan tarif pelanggan.
+func (c *Client) PLNInquiry(ctx context.Context, customerNo string) (*PLNCustomer, error) {
+	normalized, err := normalizePLNNumber(customerNo)
+	if err != nil {
+		return nil, err
+	}
+
+	var results struct {
+		CustomerNo string `json:"customer_no"`
+		Name       string `json:"name"`
+		Tariff     string `json:"tariff"`
+		Power      amount `json:"power"`
+		InquiryRef text   `json:"inquiry_ref"`
+	}
+	err = c.do(ctx, "/pln/inquiry", map[string]string{"customer_no": normalized}, &results)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		m := strings.ToLower(apiErr.Message)
+		switch {
+		case strings.Contains(m, "blokir") || strings.Contains(m, "blocked"):
+			return nil, fmt.Errorf("%w: %s", ErrMeterBlocked, apiErr.Message)
+		case strings.Contains(m, "tidak ditemukan") || strings.Contains(m, "not found") || strings.Contains(m, "salah"):
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, apiErr.Message)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	customer := &PLNCustomer{
+		CustomerNo: normalized,
+		Name:       strings.TrimSpace(results.Name),
+		Tariff:     strings.TrimSpace(results.Tariff),
+		Power:      int64(results.Power),
+		InquiryRef: string(results.InquiryRef),
+	}
+	if results.CustomerNo != "" {
+		customer.CustomerNo = results.CustomerNo
+	}
+	if customer.InquiryRef == "" {
+		// Some responses carry no reference; a local one still proves the inquiry happened
+		if customer.InquiryRef, err = newRefID(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.inquiries.add(customer.InquiryRef, normalized)
+	return customer, nil
+}
+
+// normalizePLNNumber strips separators and checks the length of a meter (11 digits)
+// or customer (12 digits) number.
+// normalizePLNNumber menghapus pemisah dan memeriksa panjang nomor meter (11 digit)
+// atau nomor pelanggan (12 digit).
+func normalizePLNNumber(s string) (string, error) {
+	n := strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(s))
+	if (len(n) != 11 && len(n) != 12) || !isNumeric(n) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDestination, s)
+	}
+	return n, nil
+}
+
+// isPLNProduct reports whether code is a PLN product, by the cached catalog or by its "PLN" prefix.
+// isPLNProduct melaporkan apakah code adalah produk PLN, berdasarkan katalog tersimpan atau awalan "PLN".
+func (c *Client) isPLNProduct(code string) bool {
+	if strings.HasPrefix(strings.ToUpper(code), "PLN") {
+		return true
+	}
+
+	c.catalog.mu.Lock()
+	defer c.catalog.mu.Unlock()
+	for _, p := range c.catalog.products {
+		if p.Code == code {
+			return p.Category == CategoryPLN
+		}
+	}
+	return false
+}
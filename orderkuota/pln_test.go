@@ -0,0 +1,81 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestPLNInquiry(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pln/inquiry": "pln_inquiry.json", "/purchase": "purchase.json"})
+	c := newTestClient(t, srv, orderkuota.WithPLNInquiryRequired())
+	ctx := context.Background()
+
+	customer, err := c.PLNInquiry(ctx, "1234-5678-901")
+	if err != nil {
+		t.Fatalf("PLNInquiry: %v", err)
+	}
+	want := &orderkuota.PLNCustomer{CustomerNo: "12345678901", Name: "BUDI SANTOSO", Tariff: "R1", Power: 1300, InquiryRef: "INQ-1"}
+	if !reflect.DeepEqual(customer, want) {
+		t.Fatalf("PLNInquiry = %+v, want %+v", customer, want)
+	}
+	if got := srv.received()[0].Params["customer_no"]; got != "12345678901" {
+		t.Fatalf("sent customer_no %q, want the normalized number", got)
+	}
+
+	// Purchases of PLN products need the reference of an inquiry of the same number
+	tests := []struct {
+		name    string
+		req     orderkuota.PurchaseRequest
+		wantErr error
+	}{
+		{name: "no inquiry", req: orderkuota.PurchaseRequest{ProductCode: "PLN20", Destination: "12345678901"}, wantErr: orderkuota.ErrInquiryRequired},
+		{name: "unknown reference", req: orderkuota.PurchaseRequest{ProductCode: "PLN20", Destination: "12345678901", InquiryRef: "INQ-2"}, wantErr: orderkuota.ErrInquiryRequired},
+		{name: "other number", req: orderkuota.PurchaseRequest{ProductCode: "PLN20", Destination: "109876543210", InquiryRef: "INQ-1"}, wantErr: orderkuota.ErrInquiryRequired},
+		{name: "inquired", req: orderkuota.PurchaseRequest{ProductCode: "PLN20", Destination: "12345678901", InquiryRef: "INQ-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := srv.count("/purchase")
+			_, err := c.Purchase(ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Purchase err = %v, want %v", err, tt.wantErr)
+			}
+			sent := srv.count("/purchase") - before
+			if tt.wantErr != nil {
+				if sent != 0 {
+					t.Fatalf("%d purchases sent, want none", sent)
+				}
+				return
+			}
+			if last := srv.received()[len(srv.received())-1]; sent != 1 || last.Params["inquiry_ref"] != "INQ-1" {
+				t.Fatalf("sent %d purchases, last %v, want one with inquiry_ref INQ-1", sent, last.Params)
+			}
+		})
+	}
+}
+
+func TestPLNInquiryErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		customerNo string
+		fixture    string
+		wantErr    error
+	}{
+		{name: "not found", customerNo: "12345678901", fixture: "pln_not_found.json", wantErr: orderkuota.ErrCustomerNotFound},
+		{name: "blocked", customerNo: "12345678901", fixture: "pln_blocked.json", wantErr: orderkuota.ErrMeterBlocked},
+		{name: "too short", customerNo: "1234567", fixture: "pln_inquiry.json", wantErr: orderkuota.ErrInvalidDestination},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/pln/inquiry": tt.fixture})
+			customer, err := newTestClient(t, srv).PLNInquiry(context.Background(), tt.customerNo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PLNInquiry = %+v, %v, want %v", customer, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package orderkuota
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCatalogTTL is how long GetPriceList reuses a fetched catalog.
+// DefaultCatalogTTL adalah lama GetPriceList memakai ulang katalog yang sudah diambil.
+const DefaultCatalogTTL = 10 * time.Minute
+
+// Product categories as reported by the gateway.
+// Kategori produk sesuai yang dilaporkan gateway.
+const (
+	CategoryPulsa  = "pulsa"
+	CategoryData   = "data"
+	CategoryPLN    = "pln"
+	CategoryEMoney = "emoney"
+	CategoryGame   = "game"
+)
+
+// Product statuses.
+// Status produk.
+const (
+	ProductOpen      = "open"     // Can be purchased / Dapat dibeli
+	ProductClosed    = "closed"   // Not sold at the moment / Sedang tidak dijual
+	ProductDisrupted = "gangguan" // Supplier disruption / Gangguan dari supplier
+)
+
+// Product is an item of the OrderKuota catalog.
+// Product adalah item dari katalog OrderKuota.
+type Product struct {
+	Code     string // Product code used for purchases / Kode produk untuk pembelian
+	Name     string // Description / Deskripsi
+	Category string // One of the Category constants / Salah satu konstanta Category
+	Brand    string // Operator or brand, e.g. "Telkomsel" / Operator atau brand, misalnya "Telkomsel"
+	Price    int64  // Base price in rupiah / Harga dasar dalam rupiah
+	Status   string // One of the Product status constants / Salah satu konstanta status Product
+}
+
+// Available reports whether the product can be purchased now.
+// Available melaporkan apakah produk dapat dibeli sekarang.
+func (p Product) Available() bool {
+	return p.Status == ProductOpen
+}
+
+// ProductFilter narrows GetPriceList results. Empty fields match everything.
+// ProductFilter mempersempit hasil GetPriceList. Field kosong cocok dengan semua.
+type ProductFilter struct {
+	Category      string // Category, case-insensitive / Kategori, tidak peka huruf besar-kecil
+	Brand         string // Operator or brand, case-insensitive / Operator atau brand, tidak peka huruf besar-kecil
+	AvailableOnly bool   // Only products with status open / Hanya produk dengan status open
+}
+
+func (f ProductFilter) matches(p Product) bool {
+	if f.Category != "" && !strings.EqualFold(f.Category, p.Category) {
+		return false
+	}
+	if f.Brand != "" && !strings.EqualFold(f.Brand, p.Brand) {
+		return false
+	}
+	if f.AvailableOnly && !p.Available() {
+		return false
+	}
+	return true
+}
+
+// WithCatalogTTL sets how long GetPriceList caches the catalog; 0 disables caching.
+// WithCatalogTTL mengatur lama GetPriceList menyimpan katalog; 0 menonaktifkan cache.
+func WithCatalogTTL(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.catalogTTL = d
+		}
+	}
+}
+
+// catalogCache holds the last fetched catalog.
+// catalogCache menyimpan katalog yang terakhir diambil.
+type catalogCache struct {
+	mu        sync.Mutex
+	products  []Product
+	fetchedAt time.Time
+}
+
+// GetPriceList returns the catalog products matching filter, flattened across categories and brands.
+// GetPriceList mengembalikan produk katalog yang cocok dengan filter, diratakan dari semua kategori dan brand.
+//
+// The full catalog is cached for the configured TTL (DefaultCatalogTTL) and filtered locally.
+// Katalog lengkap disimpan selama TTL yang dikonfigurasi (DefaultCatalogTTL) dan difilter secara lokal.
+func (c *Client) GetPriceList(ctx context.Context, filter ProductFilter) ([]Product, error) {
+	catalog, err := c.loadCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []Product
+	for _, p := range catalog {
+		if filter.matches(p) {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
+
+// InvalidatePriceList drops the cached catalog so the next GetPriceList fetches it again.
+// InvalidatePriceList menghapus katalog yang tersimpan agar GetPriceList berikutnya mengambil ulang.
+func (c *Client) InvalidatePriceList() {
+	c.catalog.mu.Lock()
+	defer c.catalog.mu.Unlock()
+
+	c.catalog.products = nil
+	c.catalog.fetchedAt = time.Time{}
+}
+
+// loadCatalog returns the cached catalog or fetches a fresh one.
+// loadCatalog mengembalikan katalog yang tersimpan atau mengambil yang baru.
+func (c *Client) loadCatalog(ctx context.Context) ([]Product, error) {
+	c.catalog.mu.Lock()
+	defer c.catalog.mu.Unlock()
+
+	if c.catalog.products != nil && c.catalogTTL > 0 && time.Since(c.catalog.fetchedAt) < c.catalogTTL {
+		return c.catalog.products, nil
+	}
+
+	products, err := c.fetchCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.catalog.products = products
+	c.catalog.fetchedAt = time.Now()
+	return products, nil
+}
+
+// catalogGroup is one category/brand group of the price list response.
+// catalogGroup adalah satu grup kategori/brand dari response daftar harga.
+type catalogGroup struct {
+	Category string           `json:"category"`
+	Brand    string           `json:"brand"`
+	Products []catalogProduct `json:"products"`
+}
+
+type catalogProduct struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Brand    string `json:"brand"`
+	Price    amount `json:"price"`
+	Status   text   `json:"status"`
+}
+
+// fetchCatalog fetches every page of the price list.
+// fetchCatalog mengambil semua halaman daftar harga.
+func (c *Client) fetchCatalog(ctx context.Context) ([]Product, error) {
+	products := []Product{}
+	for page := 1; ; page++ {
+		var results struct {
+			TotalPages int            `json:"total_pages"`
+			Data       []catalogGroup `json:"data"`
+		}
+		if err := c.do(ctx, "/pricelist", map[string]string{"page": strconv.Itoa(page)}, &results); err != nil {
+			return nil, err
+		}
+
+		for _, g := range results.Data {
+			for _, p := range g.Products {
+				product := Product{
+					Code:     p.Code,
+					Name:     p.Name,
+					Category: strings.ToLower(firstNonEmpty(p.Category, g.Category)),
+					Brand:    firstNonEmpty(p.Brand, g.Brand),
+					Price:    int64(p.Price),
+					Status:   normalizeProductStatus(string(p.Status)),
+				}
+				products = append(products, product)
+			}
+		}
+
+		if len(results.Data) == 0 || page >= results.TotalPages {
+			return products, nil
+		}
+	}
+}
+
+// normalizeProductStatus maps the gateway's status spellings to the Product status constants.
+// normalizeProductStatus memetakan berbagai penulisan status gateway ke konstanta status Product.
+func normalizeProductStatus(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "open", "tersedia", "aktif", "active", "normal":
+		return ProductOpen
+	case "gangguan", "trouble", "disrupted":
+		return ProductDisrupted
+	}
+	return ProductClosed
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
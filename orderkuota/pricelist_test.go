@@ -0,0 +1,86 @@
+package orderkuota_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestGetPriceList(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pricelist": "pricelist_page2.json"})
+	srv.queue("/pricelist", "pricelist_page1.json")
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	// Both pages are flattened, product fields winning over their group
+	products, err := c.GetPriceList(ctx, orderkuota.ProductFilter{})
+	if err != nil {
+		t.Fatalf("GetPriceList: %v", err)
+	}
+	want := []orderkuota.Product{
+		{Code: "S5", Name: "Telkomsel 5.000", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 5475, Status: orderkuota.ProductOpen},
+		{Code: "S10", Name: "Telkomsel 10.000", Category: orderkuota.CategoryPulsa, Brand: "Telkomsel", Price: 10425, Status: orderkuota.ProductOpen},
+		{Code: "X10", Name: "XL 10.000", Category: orderkuota.CategoryPulsa, Brand: "XL", Price: 10600, Status: orderkuota.ProductClosed},
+		{Code: "ML86", Name: "Mobile Legends 86 Diamond", Category: orderkuota.CategoryGame, Brand: "Mobile Legends", Price: 19800, Status: orderkuota.ProductDisrupted},
+	}
+	if !reflect.DeepEqual(products, want) {
+		t.Fatalf("GetPriceList = %+v, want %+v", products, want)
+	}
+	var pages []string
+	for _, r := range srv.received() {
+		pages = append(pages, r.Params["page"])
+	}
+	if !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Fatalf("pages requested %q, want 1 and 2", pages)
+	}
+
+	// Filters apply to the cached catalog
+	tests := []struct {
+		filter orderkuota.ProductFilter
+		codes  []string
+	}{
+		{orderkuota.ProductFilter{Category: "PULSA"}, []string{"S5", "S10", "X10"}},
+		{orderkuota.ProductFilter{Brand: "xl"}, []string{"X10"}},
+		{orderkuota.ProductFilter{Category: "pulsa", AvailableOnly: true}, []string{"S5", "S10"}},
+		{orderkuota.ProductFilter{Category: orderkuota.CategoryPLN}, nil},
+	}
+	for _, tt := range tests {
+		products, err := c.GetPriceList(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var codes []string
+		for _, p := range products {
+			codes = append(codes, p.Code)
+		}
+		if !reflect.DeepEqual(codes, tt.codes) {
+			t.Errorf("GetPriceList(%+v) = %q, want %q", tt.filter, codes, tt.codes)
+		}
+	}
+	if n := srv.count("/pricelist"); n != 2 {
+		t.Fatalf("%d price list requests, want the catalog fetched once", n)
+	}
+
+	// Invalidating fetches it again
+	srv.setFixture("/pricelist", "pricelist.json")
+	c.InvalidatePriceList()
+	if products, err := c.GetPriceList(ctx, orderkuota.ProductFilter{}); err != nil || len(products) != 3 {
+		t.Fatalf("GetPriceList after InvalidatePriceList = %+v, %v, want the new catalog", products, err)
+	}
+}
+
+func TestGetPriceListWithoutCache(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pricelist": "pricelist.json"})
+	c := newTestClient(t, srv, orderkuota.WithCatalogTTL(0))
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetPriceList(context.Background(), orderkuota.ProductFilter{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := srv.count("/pricelist"); n != 2 {
+		t.Fatalf("%d price list requests, want one per call", n)
+	}
+}
@@ -0,0 +1,163 @@
+package orderkuota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrProductNotFound is returned when a product code is not in the catalog.
+// ErrProductNotFound dikembalikan jika kode produk tidak ada di katalog.
+var ErrProductNotFound = errors.New("product not found / produk tidak ditemukan")
+
+// Markup is a price adjustment. Price, when set, replaces the computed sell price entirely.
+// Markup adalah penyesuaian harga. Price, jika diisi, menggantikan seluruh harga jual yang dihitung.
+type Markup struct {
+	Flat    int64   `json:"flat,omitempty"`    // Rupiah added to the base price / Rupiah yang ditambahkan ke harga dasar
+	Percent float64 `json:"percent,omitempty"` // Percentage of the base price added / Persentase harga dasar yang ditambahkan
+	Price   int64   `json:"price,omitempty"`   // Fixed sell price / Harga jual tetap
+}
+
+// PricingRules decide the sell price of every product. The most specific markup wins:
+// Products, then Categories, then Default. They can be stored as JSON in a config file.
+// PricingRules menentukan harga jual setiap produk. Markup paling spesifik yang dipakai:
+// Products, lalu Categories, lalu Default. Dapat disimpan sebagai JSON di file konfigurasi.
+type PricingRules struct {
+	Default    Markup            `json:"default"`              // Markup for every product / Markup untuk semua produk
+	Categories map[string]Markup `json:"categories,omitempty"` // Markup per category / Markup per kategori
+	Products   map[string]Markup `json:"products,omitempty"`   // Markup per product code / Markup per kode produk
+	RoundTo    int64             `json:"round_to,omitempty"`   // Round to the nearest multiple, e.g. 100 or 500 / Bulatkan ke kelipatan terdekat, misalnya 100 atau 500
+}
+
+// ParsePricingRules decodes and validates rules from JSON.
+// ParsePricingRules men-decode dan memvalidasi aturan dari JSON.
+func ParsePricingRules(data []byte) (PricingRules, error) {
+	var r PricingRules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse pricing rules / gagal parse aturan harga: %v", err)
+	}
+	return r, r.Validate()
+}
+
+// Validate rejects negative markups and rounding steps.
+// Validate menolak markup dan langkah pembulatan yang negatif.
+func (r PricingRules) Validate() error {
+	if r.RoundTo < 0 {
+		return errors.New("round_to must not be negative / round_to tidak boleh negatif")
+	}
+
+	check := func(name string, m Markup) error {
+		if m.Flat < 0 || m.Percent < 0 || m.Price < 0 {
+			return fmt.Errorf("markup %s must not be negative / markup %s tidak boleh negatif", name, name)
+		}
+		return nil
+	}
+	if err := check("default", r.Default); err != nil {
+		return err
+	}
+	for name, m := range r.Categories {
+		if err := check("category "+name, m); err != nil {
+			return err
+		}
+	}
+	for code, m := range r.Products {
+		if err := check("product "+code, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markup returns the most specific markup for p.
+// markup mengembalikan markup paling spesifik untuk p.
+func (r PricingRules) markup(p Product) Markup {
+	if m, ok := r.Products[p.Code]; ok {
+		return m
+	}
+	for category, m := range r.Categories {
+		if strings.EqualFold(category, p.Category) {
+			return m
+		}
+	}
+	return r.Default
+}
+
+// Apply returns the sell price of p. Rounding never goes below the base price.
+// Apply mengembalikan harga jual p. Pembulatan tidak pernah di bawah harga dasar.
+func (r PricingRules) Apply(p Product) int64 {
+	m := r.markup(p)
+	if m.Price > 0 {
+		return m.Price
+	}
+
+	price := p.Price + m.Flat + int64(math.Round(float64(p.Price)*m.Percent/100))
+	if r.RoundTo <= 1 {
+		return price
+	}
+
+	rounded := (price + r.RoundTo/2) / r.RoundTo * r.RoundTo
+	if rounded < p.Price {
+		// Nearest rounding must not sell below cost
+		rounded += r.RoundTo
+	}
+	return rounded
+}
+
+// PricedProduct is a catalog product with its sell price.
+// PricedProduct adalah produk katalog beserta harga jualnya.
+type PricedProduct struct {
+	Product
+	SellPrice int64 // Price charged to the buyer / Harga yang dibebankan ke pembeli
+}
+
+// Pricing layers PricingRules over a Client's price list.
+// Pricing menerapkan PricingRules di atas daftar harga Client.
+type Pricing struct {
+	client *Client
+	rules  PricingRules
+}
+
+// NewPricing validates rules and creates a Pricing for the catalog of c.
+// NewPricing memvalidasi rules dan membuat Pricing untuk katalog c.
+func NewPricing(c *Client, rules PricingRules) (*Pricing, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &Pricing{client: c, rules: rules}, nil
+}
+
+// PriceList returns the products matching filter with base and sell prices.
+// PriceList mengembalikan produk yang cocok dengan filter beserta harga dasar dan harga jual.
+func (p *Pricing) PriceList(ctx context.Context, filter ProductFilter) ([]PricedProduct, error) {
+	products, err := p.client.GetPriceList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	priced := make([]PricedProduct, 0, len(products))
+	for _, product := range products {
+		priced = append(priced, PricedProduct{Product: product, SellPrice: p.rules.Apply(product)})
+	}
+	return priced, nil
+}
+
+// SellPrice returns the sell price of a product code from the cached catalog,
+// fetching the catalog first if needed.
+// SellPrice mengembalikan harga jual kode produk dari katalog yang tersimpan,
+// dengan mengambil katalog terlebih dahulu bila perlu.
+func (p *Pricing) SellPrice(productCode string) (int64, error) {
+	catalog, err := p.client.loadCatalog(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, product := range catalog {
+		if product.Code == productCode {
+			return p.rules.Apply(product), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrProductNotFound, productCode)
+}
@@ -0,0 +1,134 @@
+package orderkuota_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestPricingRulesApply(t *testing.T) {
+	pulsa := orderkuota.Product{Code: "S5", Category: orderkuota.CategoryPulsa, Price: 5475}
+	tests := []struct {
+		name    string
+		rules   orderkuota.PricingRules
+		product orderkuota.Product
+		want    int64
+	}{
+		{"no markup", orderkuota.PricingRules{}, pulsa, 5475},
+		{"flat", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 1000}}, pulsa, 6475},
+		{"percent", orderkuota.PricingRules{Default: orderkuota.Markup{Percent: 10}}, pulsa, 6023},
+		{"flat and percent", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 500, Percent: 2}}, pulsa, 6085},
+		{"round to 100", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 500}, RoundTo: 100}, pulsa, 6000},
+		{"round to 500", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 500}, RoundTo: 500}, pulsa, 6000},
+		{"round half up", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 25}, RoundTo: 100}, pulsa, 5500},
+		{"already round", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 525}, RoundTo: 500}, pulsa, 6000},
+		{"tiny percent", orderkuota.PricingRules{Default: orderkuota.Markup{Percent: 0.001}}, pulsa, 5475},
+		{"tiny percent rounded", orderkuota.PricingRules{Default: orderkuota.Markup{Percent: 0.01}, RoundTo: 100}, pulsa, 5500},
+		{"never below cost", orderkuota.PricingRules{RoundTo: 500}, orderkuota.Product{Price: 5100}, 5500},
+		{"round to 1", orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 1}, RoundTo: 1}, pulsa, 5476},
+		{"fixed price", orderkuota.PricingRules{Default: orderkuota.Markup{Price: 7000, Flat: 1000}, RoundTo: 500}, pulsa, 7000},
+		{
+			"category",
+			orderkuota.PricingRules{Default: orderkuota.Markup{Flat: 100}, Categories: map[string]orderkuota.Markup{"PULSA": {Flat: 200}}},
+			pulsa, 5675,
+		},
+		{
+			"product beats category",
+			orderkuota.PricingRules{
+				Categories: map[string]orderkuota.Markup{"pulsa": {Flat: 200}},
+				Products:   map[string]orderkuota.Markup{"S5": {Flat: 300}},
+			},
+			pulsa, 5775,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Apply(tt.product); got != tt.want {
+				t.Fatalf("Apply = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPricingRulesJSON(t *testing.T) {
+	rules := orderkuota.PricingRules{
+		Default:    orderkuota.Markup{Flat: 500, Percent: 1.5},
+		Categories: map[string]orderkuota.Markup{"pln": {Percent: 2}},
+		Products:   map[string]orderkuota.Markup{"S5": {Price: 6000}},
+		RoundTo:    500,
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := orderkuota.ParsePricingRules(data)
+	if err != nil {
+		t.Fatalf("ParsePricingRules: %v", err)
+	}
+	if !reflect.DeepEqual(got, rules) {
+		t.Fatalf("round trip = %+v, want %+v", got, rules)
+	}
+}
+
+func TestParsePricingRulesInvalid(t *testing.T) {
+	tests := []string{
+		`{"default": {"flat": "lots"}}`,
+		`{"round_to": -100}`,
+		`{"default": {"percent": -1}}`,
+		`{"categories": {"pulsa": {"flat": -500}}}`,
+		`{"products": {"S5": {"price": -1}}}`,
+	}
+	for _, data := range tests {
+		if _, err := orderkuota.ParsePricingRules([]byte(data)); err == nil {
+			t.Errorf("ParsePricingRules(%s) succeeded", data)
+		}
+	}
+}
+
+func TestPricing(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/pricelist": "pricelist.json"})
+	c := newTestClient(t, srv)
+	p, err := orderkuota.NewPricing(c, orderkuota.PricingRules{
+		Default:    orderkuota.Markup{Flat: 500},
+		Categories: map[string]orderkuota.Markup{orderkuota.CategoryPLN: {Flat: 2000}},
+		RoundTo:    100,
+	})
+	if err != nil {
+		t.Fatalf("NewPricing: %v", err)
+	}
+
+	priced, err := p.PriceList(context.Background(), orderkuota.ProductFilter{})
+	if err != nil {
+		t.Fatalf("PriceList: %v", err)
+	}
+	want := map[string][2]int64{"S5": {5475, 6000}, "S10": {10425, 10900}, "PLN20": {20150, 22200}}
+	if len(priced) != len(want) {
+		t.Fatalf("PriceList returned %d products, want %d", len(priced), len(want))
+	}
+	for _, pp := range priced {
+		if got := [2]int64{pp.Price, pp.SellPrice}; got != want[pp.Code] {
+			t.Errorf("%s: base and sell price = %v, want %v", pp.Code, got, want[pp.Code])
+		}
+	}
+
+	if price, err := p.SellPrice("S10"); err != nil || price != 10900 {
+		t.Fatalf("SellPrice(S10) = %d, %v, want 10900", price, err)
+	}
+	if _, err := p.SellPrice("X1"); !errors.Is(err, orderkuota.ErrProductNotFound) {
+		t.Fatalf("SellPrice(X1) err = %v, want ErrProductNotFound", err)
+	}
+	if n := len(srv.received()); n != 1 {
+		t.Fatalf("catalog fetched %d times, want once", n)
+	}
+}
+
+func TestNewPricingInvalid(t *testing.T) {
+	c := newTestClient(t, newFixtureServer(t, nil))
+	if _, err := orderkuota.NewPricing(c, orderkuota.PricingRules{RoundTo: -1}); err == nil {
+		t.Fatal("NewPricing accepted a negative round_to")
+	}
+}
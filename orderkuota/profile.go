@@ -0,0 +1,24 @@
+package orderkuota
+
+import (
+	"context"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+)
+
+// Profile is the member information of an OrderKuota account.
+// Profile adalah informasi member dari akun OrderKuota.
+type Profile = auth.Profile
+
+// GetProfile returns the member information of the account the credentials belong to.
+// GetProfile mengembalikan informasi member dari akun pemilik kredensial.
+//
+// A rejected or expired token returns ErrUnauthorized.
+// Token yang ditolak atau kedaluwarsa mengembalikan ErrUnauthorized.
+func (c *Client) GetProfile(ctx context.Context) (*Profile, error) {
+	var profile Profile
+	if err := c.do(ctx, "/profile", nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
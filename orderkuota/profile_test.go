@@ -0,0 +1,47 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestGetProfile(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    orderkuota.Profile
+		wantErr error
+	}{
+		{
+			fixture: "profile.json",
+			want: orderkuota.Profile{MemberID: "12345", Name: "AutoFTbot Store", Phone: "081234567890", Email: "toko@example.com",
+				Level: "gold", RegisteredAt: time.Date(2024, 1, 15, 10, 0, 0, 0, qris.WIB)},
+		},
+		{fixture: "profile_string_id.json", want: orderkuota.Profile{MemberID: "OK12345", Name: "AutoFTbot Store", Level: "silver"}},
+		{fixture: "balance_token_expired.json", wantErr: orderkuota.ErrUnauthorized},
+		{fixture: "401", wantErr: orderkuota.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/profile": tt.fixture})
+
+			profile, err := newTestClient(t, srv).GetProfile(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProfile: %v", err)
+			}
+			if *profile != tt.want {
+				t.Fatalf("profile = %+v, want %+v", *profile, tt.want)
+			}
+		})
+	}
+}
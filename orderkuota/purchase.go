@@ -0,0 +1,272 @@
+package orderkuota
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TransactionStatus is the state of a purchase.
+// TransactionStatus adalah status sebuah pembelian.
+type TransactionStatus string
+
+// Transaction statuses. Success and failed are terminal.
+// Status transaksi. Success dan failed adalah status akhir.
+const (
+	TransactionPending TransactionStatus = "pending"
+	TransactionSuccess TransactionStatus = "success"
+	TransactionFailed  TransactionStatus = "failed"
+)
+
+// Terminal reports whether the status will not change anymore.
+// Terminal melaporkan apakah status tidak akan berubah lagi.
+func (s TransactionStatus) Terminal() bool {
+	return s == TransactionSuccess || s == TransactionFailed
+}
+
+// parseTransactionStatus maps the gateway's status spellings to a TransactionStatus.
+// parseTransactionStatus memetakan berbagai penulisan status gateway ke TransactionStatus.
+func parseTransactionStatus(s string) TransactionStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "success", "sukses", "berhasil", "1":
+		return TransactionSuccess
+	case "failed", "gagal", "refund", "2":
+		return TransactionFailed
+	}
+	return TransactionPending
+}
+
+// ErrInvalidDestination is returned before calling the gateway when a destination is malformed.
+// ErrInvalidDestination dikembalikan sebelum memanggil gateway jika nomor tujuan tidak valid.
+var ErrInvalidDestination = errors.New("invalid destination number / nomor tujuan tidak valid")
+
+// PurchaseRequest describes a product order.
+// PurchaseRequest menjelaskan pesanan produk.
+type PurchaseRequest struct {
+	ProductCode string // Product code from GetPriceList / Kode produk dari GetPriceList
+	Destination string // Phone or customer number / Nomor HP atau nomor pelanggan
+	RefID       string // Optional idempotency key, generated if empty / Kunci idempotensi opsional, dibuat otomatis jika kosong
+	InquiryRef  string // PLNCustomer.InquiryRef for PLN products / PLNCustomer.InquiryRef untuk produk PLN
+
+	EnsureAvailable bool // Check availability first and fail fast with ErrProductUnavailable / Cek ketersediaan dulu dan langsung gagal dengan ErrProductUnavailable
+}
+
+// PurchaseResult is the gateway's answer to a purchase.
+// PurchaseResult adalah jawaban gateway atas sebuah pembelian.
+type PurchaseResult struct {
+	TransactionID string            // Gateway transaction ID / ID transaksi gateway
+	RefID         string            // Client reference ID / ID referensi client
+	Status        TransactionStatus // Initial status / Status awal
+	Price         int64             // Price charged in rupiah / Harga yang dipotong dalam rupiah
+	SerialNumber  string            // Serial number, if delivered synchronously / Nomor seri, jika langsung terkirim
+	Message       string            // Gateway message / Pesan dari gateway
+	Duplicate     bool              // RefID was used before; this is the original transaction / RefID sudah pernah dipakai; ini transaksi aslinya
+}
+
+// DefaultPurchaseTimeout bounds the purchase request, which can legitimately take much longer than other calls.
+// DefaultPurchaseTimeout membatasi request pembelian, yang memang bisa jauh lebih lama dari panggilan lain.
+const DefaultPurchaseTimeout = 30 * time.Second
+
+// PurchaseOption customizes a single Purchase call.
+// PurchaseOption mengatur satu panggilan Purchase.
+type PurchaseOption func(*purchaseOptions)
+
+type purchaseOptions struct {
+	timeout time.Duration
+}
+
+// WithPurchaseTimeout overrides DefaultPurchaseTimeout for the purchase request.
+// WithPurchaseTimeout mengganti DefaultPurchaseTimeout untuk request pembelian.
+func WithPurchaseTimeout(d time.Duration) PurchaseOption {
+	return func(o *purchaseOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// Purchase orders a product for a destination number.
+// Purchase memesan produk untuk nomor tujuan.
+//
+// Sending the same RefID twice never buys twice: the original transaction is returned with Duplicate set.
+// Mengirim RefID yang sama dua kali tidak pernah membeli dua kali: transaksi asli dikembalikan dengan Duplicate bernilai true.
+//
+// With WithIdempotencyStore, a RefID already in the store is answered from it without calling the
+// gateway. When the result cannot be recorded, it is returned together with the error.
+// Dengan WithIdempotencyStore, RefID yang sudah ada di store dijawab dari store tanpa memanggil
+// gateway. Jika hasil tidak dapat dicatat, hasil dikembalikan bersama error-nya.
+func (c *Client) Purchase(ctx context.Context, req PurchaseRequest, opts ...PurchaseOption) (*PurchaseResult, error) {
+	o := purchaseOptions{timeout: DefaultPurchaseTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if req.ProductCode == "" {
+		return nil, errors.New("productCode must be filled / productCode harus diisi")
+	}
+	destination, err := validateDestination(req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		"product_code": req.ProductCode,
+		"destination":  destination,
+	}
+	if c.isPLNProduct(req.ProductCode) {
+		if c.requireInquiry {
+			plnNo, err := normalizePLNNumber(req.Destination)
+			if err != nil {
+				return nil, err
+			}
+			if req.InquiryRef == "" || !c.inquiries.matches(req.InquiryRef, plnNo) {
+				return nil, ErrInquiryRequired
+			}
+		}
+		if req.InquiryRef != "" {
+			params["inquiry_ref"] = req.InquiryRef
+		}
+	}
+
+	if err := c.checkDestination(ctx, req.ProductCode, destination); err != nil {
+		return nil, err
+	}
+
+	if req.EnsureAvailable {
+		if err := c.ensureAvailable(ctx, req.ProductCode); err != nil {
+			return nil, err
+		}
+	}
+
+	refID := req.RefID
+	if refID == "" {
+		if refID, err = newRefID(); err != nil {
+			return nil, err
+		}
+	}
+	params["ref_id"] = refID
+
+	send := func() (*PurchaseResult, error) {
+		return c.sendPurchase(ctx, params, o.timeout)
+	}
+	if c.idempotency != nil && req.RefID != "" {
+		return c.idempotentPurchase(ctx, refID, o.timeout, send)
+	}
+	return send()
+}
+
+// sendPurchase posts a purchase with the ref_id of params.
+// sendPurchase mengirim pembelian dengan ref_id dari params.
+func (c *Client) sendPurchase(ctx context.Context, params map[string]string, timeout time.Duration) (*PurchaseResult, error) {
+	refID := params["ref_id"]
+
+	var row transactionRow
+	err := c.do(withRequestTimeout(ctx, timeout), "/purchase", params, &row)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && isDuplicate(apiErr.Message) {
+		// The gateway refused the repeated RefID; report the original order instead
+		original, lookupErr := c.transactionByRef(ctx, refID)
+		if lookupErr != nil {
+			return nil, fmt.Errorf("duplicate ref ID %s, lookup failed / ref ID %s duplikat, pencarian gagal: %w", refID, refID, lookupErr)
+		}
+		original.Duplicate = true
+		return original, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := row.result()
+	result.SerialNumber = c.serialNumber(row.ProductCode, result.SerialNumber, row.Message)
+	if result.RefID == "" {
+		result.RefID = refID
+	}
+	return result, nil
+}
+
+// transactionByRef looks up a transaction by its client reference ID. An unknown refID fails with
+// ErrTransactionNotFound.
+// transactionByRef mencari transaksi berdasarkan ID referensi client. refID yang tidak dikenal gagal
+// dengan ErrTransactionNotFound.
+func (c *Client) transactionByRef(ctx context.Context, refID string) (*PurchaseResult, error) {
+	var row transactionRow
+	if err := c.do(ctx, "/transaction", map[string]string{"ref_id": refID}, &row); err != nil {
+		return nil, notFoundError(err)
+	}
+
+	result := row.result()
+	result.SerialNumber = c.serialNumber(row.ProductCode, result.SerialNumber, row.Message)
+	if result.RefID == "" {
+		result.RefID = refID
+	}
+	return result, nil
+}
+
+// notFoundError wraps err in ErrTransactionNotFound when the gateway answered that the transaction
+// does not exist, and returns any other error as is.
+// notFoundError membungkus err dengan ErrTransactionNotFound jika gateway menjawab bahwa transaksi
+// tidak ada, dan mengembalikan error lain apa adanya.
+func notFoundError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	m := strings.ToLower(apiErr.Message)
+	if strings.Contains(m, "tidak ditemukan") || strings.Contains(m, "not found") || strings.Contains(m, "tidak ada") {
+		return fmt.Errorf("%w: %w", ErrTransactionNotFound, err)
+	}
+	return err
+}
+
+// isDuplicate reports whether a failure message means the RefID was already used.
+// isDuplicate melaporkan apakah pesan kegagalan berarti RefID sudah pernah dipakai.
+func isDuplicate(message string) bool {
+	m := strings.ToLower(message)
+	return strings.Contains(m, "duplicate") || strings.Contains(m, "duplikat") ||
+		(strings.Contains(m, "ref") && (strings.Contains(m, "sudah") || strings.Contains(m, "already")))
+}
+
+// validateDestination rejects obviously malformed destinations and strips separators.
+// Phone numbers are canonicalized with NormalizeMSISDN.
+// validateDestination menolak nomor tujuan yang jelas tidak valid dan menghapus pemisah.
+// Nomor HP diseragamkan dengan NormalizeMSISDN.
+func validateDestination(s string) (string, error) {
+	if isPhoneNumber(s) {
+		n, err := NormalizeMSISDN(s)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+		}
+		return n, nil
+	}
+
+	d := strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(s))
+	digits := strings.TrimPrefix(d, "+")
+	if len(digits) < 5 || len(digits) > 20 || !isNumeric(digits) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDestination, s)
+	}
+	return d, nil
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// newRefID generates a random client reference ID.
+// newRefID membuat ID referensi client secara acak.
+func newRefID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ref ID / gagal membuat ref ID: %v", err)
+	}
+	return "REF" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
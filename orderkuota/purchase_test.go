@@ -0,0 +1,109 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestPurchase(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv)
+
+	result, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "+62 812-3456-7890", RefID: "R1"})
+	if err != nil {
+		t.Fatalf("Purchase: %v", err)
+	}
+	want := &orderkuota.PurchaseResult{
+		TransactionID: "T1",
+		RefID:         "R1",
+		Status:        orderkuota.TransactionPending,
+		Price:         10500,
+		Message:       "Transaksi sedang diproses",
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("Purchase = %+v, want %+v", result, want)
+	}
+	params := srv.received()[0].Params
+	if params["product_code"] != "S10" || params["destination"] != "081234567890" || params["ref_id"] != "R1" {
+		t.Fatalf("sent %v, want S10 to 081234567890 as R1", params)
+	}
+
+	// Without a RefID every purchase gets a new one
+	var refs []string
+	for i := 0; i < 2; i++ {
+		if _, err := c.Purchase(context.Background(), orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "081234567890"}); err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, srv.received()[i+1].Params["ref_id"])
+	}
+	if !strings.HasPrefix(refs[0], "REF") || refs[0] == refs[1] {
+		t.Fatalf("generated ref IDs %q, want two different ones", refs)
+	}
+}
+
+func TestPurchaseInvalid(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/purchase": "purchase.json"})
+	c := newTestClient(t, srv)
+
+	tests := []struct {
+		name    string
+		req     orderkuota.PurchaseRequest
+		wantErr error
+	}{
+		{name: "no product", req: orderkuota.PurchaseRequest{Destination: "081234567890"}},
+		{name: "no destination", req: orderkuota.PurchaseRequest{ProductCode: "S10"}, wantErr: orderkuota.ErrInvalidDestination},
+		{name: "letters", req: orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "nomor saya"}, wantErr: orderkuota.ErrInvalidDestination},
+		{name: "too short", req: orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "1234"}, wantErr: orderkuota.ErrInvalidDestination},
+		{name: "phone number too short", req: orderkuota.PurchaseRequest{ProductCode: "S10", Destination: "0812345"}, wantErr: orderkuota.ErrInvalidDestination},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Purchase(context.Background(), tt.req)
+			if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Purchase err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Fatalf("%d requests sent, want none", n)
+	}
+}
+
+func TestPurchaseDuplicateRefID(t *testing.T) {
+	tests := []struct {
+		name    string
+		lookup  string // fixture of /transaction / fixture dari /transaction
+		wantErr error
+	}{
+		{name: "original returned", lookup: "transaction_success.json"},
+		{name: "original not found", lookup: "transaction_not_found.json", wantErr: orderkuota.ErrTransactionNotFound},
+		{name: "lookup unauthorized", lookup: "401", wantErr: orderkuota.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/purchase": "purchase_duplicate.json", "/transaction": tt.lookup})
+			c := newTestClient(t, srv)
+
+			result, err := c.Purchase(context.Background(), testPurchase)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || result != nil {
+					t.Fatalf("Purchase = %+v, %v, want %v", result, err, tt.wantErr)
+				}
+			} else if err != nil || !result.Duplicate || result.TransactionID != "T1" || result.Status != orderkuota.TransactionSuccess ||
+				result.SerialNumber != "0412345678901234" {
+				t.Fatalf("Purchase = %+v, %v, want the original T1 marked duplicate", result, err)
+			}
+
+			// The original is looked up by RefID, and the purchase is never sent again
+			got := srv.received()
+			if len(got) != 2 || got[0].Path != "/purchase" || got[1].Path != "/transaction" || got[1].Params["ref_id"] != "R1" {
+				t.Fatalf("requests %+v, want /purchase then /transaction of R1", got)
+			}
+		})
+	}
+}
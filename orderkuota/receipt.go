@@ -0,0 +1,39 @@
+package orderkuota
+
+import (
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// PurchaseReceipt returns the receipt of a purchase for qris.RenderReceipt, dated now.
+// PurchaseReceipt mengembalikan struk dari pembelian untuk qris.RenderReceipt, bertanggal sekarang.
+func PurchaseReceipt(req PurchaseRequest, result *PurchaseResult) qris.ReceiptData {
+	return qris.ReceiptData{
+		Title:        "Struk Pembelian",
+		Product:      req.ProductCode,
+		Destination:  req.Destination,
+		SerialNumber: result.SerialNumber,
+		Amount:       result.Price,
+		Date:         time.Now(),
+		Reference:    firstNonEmpty(result.TransactionID, result.RefID),
+		Status:       string(result.Status),
+	}
+}
+
+// TransactionReceipt returns the receipt of a transaction for qris.RenderReceipt, e.g. once
+// WaitForCompletion delivered the serial number.
+// TransactionReceipt mengembalikan struk dari transaksi untuk qris.RenderReceipt, misalnya setelah
+// WaitForCompletion mengirimkan nomor seri.
+func TransactionReceipt(tx *Transaction) qris.ReceiptData {
+	return qris.ReceiptData{
+		Title:        "Struk Pembelian",
+		Product:      tx.ProductCode,
+		Destination:  tx.Destination,
+		SerialNumber: tx.SerialNumber,
+		Amount:       tx.Price,
+		Date:         tx.Date,
+		Reference:    firstNonEmpty(tx.TransactionID, tx.RefID),
+		Status:       string(tx.Status),
+	}
+}
@@ -0,0 +1,165 @@
+package orderkuota
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SignatureAlgorithm is the hash used to sign a request.
+// SignatureAlgorithm adalah hash yang dipakai untuk menandatangani request.
+type SignatureAlgorithm int
+
+const (
+	// SignatureMD5 signs with hex-encoded MD5, the default.
+	// SignatureMD5 menandatangani dengan MD5 ber-encode hex, default.
+	SignatureMD5 SignatureAlgorithm = iota
+
+	// SignatureSHA256 signs with hex-encoded SHA-256.
+	// SignatureSHA256 menandatangani dengan SHA-256 ber-encode hex.
+	SignatureSHA256
+)
+
+// Parameters added to signed requests.
+// Parameter yang ditambahkan ke request yang ditandatangani.
+const (
+	MerchantIDParam = "merchant_id"
+	SignatureParam  = "signature"
+)
+
+// signedEndpoints lists the endpoints that require a signature, with their algorithm.
+// signedEndpoints berisi endpoint yang mewajibkan tanda tangan, beserta algoritmanya.
+var signedEndpoints = map[string]SignatureAlgorithm{
+	withdrawPath:        SignatureMD5,
+	withdrawStatusPath:  SignatureMD5,
+	withdrawHistoryPath: SignatureMD5,
+}
+
+// Signer computes the signatures of endpoints that authenticate with the merchant ID and API key
+// instead of the account token. A signature is the hex hash of merchantID + apiKey + the
+// parameters sorted by name and joined as "name=value&name=value", without URL escaping and
+// without the signature parameter itself.
+// Signer menghitung tanda tangan untuk endpoint yang mengautentikasi dengan ID merchant dan API key
+// alih-alih token akun. Tanda tangan adalah hash hex dari merchantID + apiKey + parameter yang
+// diurutkan berdasarkan nama dan digabung sebagai "nama=nilai&nama=nilai", tanpa URL escaping dan
+// tanpa parameter tanda tangan itu sendiri.
+//
+// A Signer is safe for concurrent use.
+// Signer aman dipakai secara bersamaan.
+type Signer struct {
+	merchantID string
+	apiKey     string
+	algorithm  SignatureAlgorithm
+	endpoints  map[string]SignatureAlgorithm
+}
+
+// SignerOption configures a Signer.
+// SignerOption mengatur Signer.
+type SignerOption func(*Signer)
+
+// WithSignatureAlgorithm sets the algorithm of endpoints without a known one, default SignatureMD5.
+// WithSignatureAlgorithm mengatur algoritma untuk endpoint yang algoritmanya tidak diketahui, default SignatureMD5.
+func WithSignatureAlgorithm(algorithm SignatureAlgorithm) SignerOption {
+	return func(s *Signer) {
+		s.algorithm = algorithm
+	}
+}
+
+// WithEndpointAlgorithm sets the algorithm of one endpoint path, e.g. an undocumented one.
+// WithEndpointAlgorithm mengatur algoritma untuk satu path endpoint, misalnya yang tidak terdokumentasi.
+func WithEndpointAlgorithm(endpoint string, algorithm SignatureAlgorithm) SignerOption {
+	return func(s *Signer) {
+		s.endpoints[endpoint] = algorithm
+	}
+}
+
+// NewSigner creates a Signer for the merchant ID and API key of the merchant dashboard.
+// NewSigner membuat Signer untuk ID merchant dan API key dari dashboard merchant.
+func NewSigner(merchantID, apiKey string, opts ...SignerOption) (*Signer, error) {
+	if merchantID == "" || apiKey == "" {
+		return nil, errors.New("merchantID and apiKey must be filled / merchantID dan apiKey harus diisi")
+	}
+
+	s := &Signer{
+		merchantID: merchantID,
+		apiKey:     apiKey,
+		endpoints:  make(map[string]SignatureAlgorithm),
+	}
+	for endpoint, algorithm := range signedEndpoints {
+		s.endpoints[endpoint] = algorithm
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// SignParams returns the signature of params for endpoint.
+// SignParams mengembalikan tanda tangan params untuk endpoint.
+func (s *Signer) SignParams(endpoint string, params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != SignatureParam {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(s.merchantID)
+	b.WriteString(s.apiKey)
+	first := true
+	for _, name := range names {
+		for _, v := range params[name] {
+			if !first {
+				b.WriteByte('&')
+			}
+			first = false
+			b.WriteString(name)
+			b.WriteByte('=')
+			b.WriteString(v)
+		}
+	}
+
+	var h hash.Hash
+	algorithm, ok := s.endpoints[endpoint]
+	if !ok {
+		algorithm = s.algorithm
+	}
+	switch algorithm {
+	case SignatureSHA256:
+		h = sha256.New()
+	default:
+		h = md5.New()
+	}
+	h.Write([]byte(b.String()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sign adds the merchant ID and the signature to params of endpoint.
+// sign menambahkan ID merchant dan tanda tangan ke params dari endpoint.
+func (s *Signer) sign(endpoint string, params map[string]string) map[string]string {
+	signed := make(map[string]string, len(params)+2)
+	values := make(url.Values, len(params)+1)
+	for k, v := range params {
+		signed[k] = v
+		values.Set(k, v)
+	}
+	signed[MerchantIDParam] = s.merchantID
+	values.Set(MerchantIDParam, s.merchantID)
+	signed[SignatureParam] = s.SignParams(endpoint, values)
+	return signed
+}
+
+// WithSigner signs the requests of endpoints that require a signature, such as withdrawals.
+// WithSigner menandatangani request ke endpoint yang mewajibkan tanda tangan, seperti penarikan saldo.
+func WithSigner(s *Signer) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
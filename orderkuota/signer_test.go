@@ -0,0 +1,142 @@
+package orderkuota_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+// Known vectors, computed with an independent MD5/SHA-256 implementation over
+// merchantID + apiKey + the sorted "name=value" pairs.
+func TestSignParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []orderkuota.SignerOption
+		endpoint string
+		params   url.Values
+		want     string
+	}{
+		{
+			name:     "withdraw",
+			endpoint: "/qris/withdraw",
+			params:   url.Values{"amount": {"50000"}, "merchant_id": {"M123"}},
+			want:     "dc3cd8b41a91bfbf02e2371329cf8d32",
+		},
+		{
+			name:     "withdraw status",
+			endpoint: "/qris/withdraw/status",
+			params:   url.Values{"merchant_id": {"M123"}, "id": {"WD1"}},
+			want:     "c72eae16407e80d33e44c99652680fdc",
+		},
+		{
+			name:     "signature parameter is ignored",
+			endpoint: "/qris/withdraw",
+			params:   url.Values{"amount": {"50000"}, "merchant_id": {"M123"}, "signature": {"stale"}},
+			want:     "dc3cd8b41a91bfbf02e2371329cf8d32",
+		},
+		{
+			name:     "no parameters",
+			endpoint: "/undocumented",
+			want:     "5b80c4c493919aaadf16bfe4c6044a7b",
+		},
+		{
+			name:     "repeated and unescaped values",
+			endpoint: "/undocumented",
+			params:   url.Values{"c": {"Rp 1.000"}, "a": {"1", "2"}, "b": {"x y"}},
+			want:     "3bf9a74647ca758c652f7171003da479",
+		},
+		{
+			name:     "sha256 default",
+			opts:     []orderkuota.SignerOption{orderkuota.WithSignatureAlgorithm(orderkuota.SignatureSHA256)},
+			endpoint: "/undocumented",
+			params:   url.Values{"amount": {"50000"}, "merchant_id": {"M123"}},
+			want:     "f919db515601631bb83765a3f95502b826a665c3b439f5bbc8790f1f2eda1429",
+		},
+		{
+			name:     "sha256 default keeps known endpoints",
+			opts:     []orderkuota.SignerOption{orderkuota.WithSignatureAlgorithm(orderkuota.SignatureSHA256)},
+			endpoint: "/qris/withdraw",
+			params:   url.Values{"amount": {"50000"}, "merchant_id": {"M123"}},
+			want:     "dc3cd8b41a91bfbf02e2371329cf8d32",
+		},
+		{
+			name:     "sha256 endpoint",
+			opts:     []orderkuota.SignerOption{orderkuota.WithEndpointAlgorithm("/qris/withdraw/status", orderkuota.SignatureSHA256)},
+			endpoint: "/qris/withdraw/status",
+			params:   url.Values{"merchant_id": {"M123"}, "id": {"WD1"}},
+			want:     "4a4d6090291fa7f8cd3bb5af3554193c8ff8c1c3f5dc5672ffd397cabbae7d30",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := orderkuota.NewSigner("M123", "KEY", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.SignParams(tt.endpoint, tt.params); got != tt.want {
+				t.Errorf("SignParams = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSignerRequiresCredentials(t *testing.T) {
+	for _, c := range [][2]string{{"", "KEY"}, {"M123", ""}} {
+		if _, err := orderkuota.NewSigner(c[0], c[1]); err == nil {
+			t.Errorf("NewSigner(%q, %q) succeeded, want an error", c[0], c[1])
+		}
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/qris/withdraw":        "withdrawal.json",
+		"/qris/withdraw/status": "withdrawal.json",
+	})
+	signer, err := orderkuota.NewSigner("M123", "KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, srv, orderkuota.WithSigner(signer))
+	ctx := context.Background()
+
+	if _, err := client.RequestWithdrawal(ctx, 50000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetWithdrawalStatus(ctx, "WD1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"/qris/withdraw":        "dc3cd8b41a91bfbf02e2371329cf8d32",
+		"/qris/withdraw/status": "c72eae16407e80d33e44c99652680fdc",
+	}
+	reqs := srv.received()
+	if len(reqs) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(reqs), len(want))
+	}
+	for _, req := range reqs {
+		if got := req.Params[orderkuota.MerchantIDParam]; got != "M123" {
+			t.Errorf("%s: merchant_id = %q, want M123", req.Path, got)
+		}
+		if got := req.Params[orderkuota.SignatureParam]; got != want[req.Path] {
+			t.Errorf("%s: signature = %q, want %q", req.Path, got, want[req.Path])
+		}
+	}
+}
+
+func TestSignedEndpointWithoutSigner(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/qris/withdraw": "withdrawal.json"})
+	client := newTestClient(t, srv)
+
+	_, err := client.RequestWithdrawal(context.Background(), 50000)
+	if err == nil || !strings.Contains(err.Error(), "WithSigner") {
+		t.Fatalf("RequestWithdrawal = %v, want an error asking for WithSigner", err)
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Errorf("got %d requests, want none", n)
+	}
+}
@@ -0,0 +1,138 @@
+package orderkuota
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SNExtractor finds the serial number (voucher code, PLN token, pulsa SN) in the free-text message
+// of a transaction, for gateways that do not report it in its own field.
+// SNExtractor mencari nomor seri (kode voucher, token PLN, SN pulsa) di pesan teks bebas dari
+// transaksi, untuk gateway yang tidak melaporkannya di field tersendiri.
+//
+// Patterns of the product code are tried first, then those of its category, then the general ones.
+// The first capture group of the first matching pattern is the serial number, or the whole match
+// when the pattern has no group. An SNExtractor is safe for concurrent use.
+// Pattern dari kode produk dicoba lebih dulu, lalu pattern kategorinya, lalu pattern umum. Grup
+// tangkapan pertama dari pattern pertama yang cocok adalah nomor serinya, atau seluruh kecocokan
+// jika pattern tidak memiliki grup. SNExtractor aman dipakai bersamaan.
+type SNExtractor struct {
+	mu         sync.RWMutex
+	products   map[string][]*regexp.Regexp
+	categories map[string][]*regexp.Regexp
+}
+
+// Built-in patterns of NewSNExtractor.
+// Pattern bawaan dari NewSNExtractor.
+var (
+	// plnTokenPattern matches a 20-digit token, written in groups of four or not
+	plnTokenPattern = regexp.MustCompile(`\b(\d{4}[-\s]?\d{4}[-\s]?\d{4}[-\s]?\d{4}[-\s]?\d{4})\b`)
+	// voucherPattern matches a labelled voucher code
+	voucherPattern = regexp.MustCompile(`(?i)\b(?:sn|kode voucher|voucher|kode|code|pin)\s*[:=]\s*([A-Z0-9][A-Z0-9-]{4,})`)
+	// snPattern matches a labelled serial number
+	snPattern = regexp.MustCompile(`(?i)\bsn\s*[:=]?\s*([A-Z0-9][A-Z0-9./-]{4,}[A-Z0-9])`)
+)
+
+// NewSNExtractor returns an SNExtractor with built-in patterns for PLN tokens (CategoryPLN),
+// game voucher codes (CategoryGame) and labelled serial numbers ("SN: ...") of every product.
+// NewSNExtractor mengembalikan SNExtractor dengan pattern bawaan untuk token PLN (CategoryPLN),
+// kode voucher game (CategoryGame) dan nomor seri berlabel ("SN: ...") dari setiap produk.
+func NewSNExtractor() *SNExtractor {
+	e := &SNExtractor{
+		products:   make(map[string][]*regexp.Regexp),
+		categories: make(map[string][]*regexp.Regexp),
+	}
+	e.RegisterCategory(CategoryPLN, plnTokenPattern)
+	e.RegisterCategory(CategoryGame, voucherPattern)
+	e.RegisterCategory("", snPattern)
+	return e
+}
+
+// Register adds pattern for productCode, tried before the patterns registered earlier.
+// Register menambahkan pattern untuk productCode, dicoba sebelum pattern yang didaftarkan sebelumnya.
+func (e *SNExtractor) Register(productCode string, pattern *regexp.Regexp) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.products[productCode] = append([]*regexp.Regexp{pattern}, e.products[productCode]...)
+}
+
+// RegisterCategory adds pattern for the products of category, tried before the patterns registered
+// earlier; the empty category holds the general patterns tried for every product.
+// RegisterCategory menambahkan pattern untuk produk dari category, dicoba sebelum pattern yang
+// didaftarkan sebelumnya; kategori kosong berisi pattern umum yang dicoba untuk setiap produk.
+func (e *SNExtractor) RegisterCategory(category string, pattern *regexp.Regexp) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	category = strings.ToLower(category)
+	e.categories[category] = append([]*regexp.Regexp{pattern}, e.categories[category]...)
+}
+
+// Extract returns the serial number in message for a product of category, or "" if none is found.
+// Extract mengembalikan nomor seri di message untuk produk dari category, atau "" jika tidak ditemukan.
+func (e *SNExtractor) Extract(category, productCode, message string) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	category = strings.ToLower(category)
+	var patterns []*regexp.Regexp
+	patterns = append(patterns, e.products[productCode]...)
+	if category != "" {
+		patterns = append(patterns, e.categories[category]...)
+	}
+	patterns = append(patterns, e.categories[""]...)
+
+	for _, p := range patterns {
+		m := p.FindStringSubmatch(message)
+		if m == nil {
+			continue
+		}
+		sn := m[0]
+		if len(m) > 1 {
+			sn = m[1]
+		}
+		if sn = strings.TrimSpace(sn); sn != "" {
+			return sn
+		}
+	}
+	return ""
+}
+
+// WithSNExtractor replaces the SNExtractor that fills the serial number of transactions whose
+// gateway row has none, by default NewSNExtractor(); nil disables extraction.
+// WithSNExtractor mengganti SNExtractor yang mengisi nomor seri transaksi yang baris gateway-nya
+// tidak memilikinya, default NewSNExtractor(); nil menonaktifkan ekstraksi.
+func WithSNExtractor(e *SNExtractor) Option {
+	return func(c *Client) {
+		c.sn = e
+	}
+}
+
+// serialNumber returns sn, or the serial number extracted from message when sn is empty.
+// serialNumber mengembalikan sn, atau nomor seri yang diekstrak dari message jika sn kosong.
+func (c *Client) serialNumber(productCode, sn, message string) string {
+	if sn != "" || c.sn == nil || message == "" {
+		return sn
+	}
+	return c.sn.Extract(c.productCategory(productCode), productCode, message)
+}
+
+// productCategory returns the category of code by the cached catalog or its "PLN" prefix, or "".
+// productCategory mengembalikan kategori dari code berdasarkan katalog tersimpan atau awalan "PLN", atau "".
+func (c *Client) productCategory(code string) string {
+	c.catalog.mu.Lock()
+	for _, p := range c.catalog.products {
+		if p.Code == code {
+			c.catalog.mu.Unlock()
+			return p.Category
+		}
+	}
+	c.catalog.mu.Unlock()
+
+	if strings.HasPrefix(strings.ToUpper(code), "PLN") {
+		return CategoryPLN
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package orderkuota_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+// snMessage is one redacted status message of testdata/sn/messages.json.
+type snMessage struct {
+	Category string `json:"category"`
+	Product  string `json:"product"`
+	Message  string `json:"message"`
+	Want     string `json:"want"`
+}
+
+func TestSNExtractorCorpus(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "sn", "messages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corpus []snMessage
+	if err := json.Unmarshal(body, &corpus); err != nil {
+		t.Fatal(err)
+	}
+
+	e := orderkuota.NewSNExtractor()
+	for _, tt := range corpus {
+		t.Run(tt.Category+"/"+tt.Product, func(t *testing.T) {
+			if got := e.Extract(tt.Category, tt.Product, tt.Message); got != tt.Want {
+				t.Errorf("Extract(%q) = %q, want %q", tt.Message, got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestSNExtractorRegister(t *testing.T) {
+	const message = "Ref.998877 TSEL5 SUKSES SN: 111122223333 VC:ZZ-9"
+	tests := []struct {
+		name     string
+		register func(e *orderkuota.SNExtractor)
+		category string
+		product  string
+		want     string
+	}{
+		{
+			name:    "built-in",
+			product: "TSEL5",
+			want:    "111122223333",
+		},
+		{
+			name:     "product pattern",
+			register: func(e *orderkuota.SNExtractor) { e.Register("TSEL5", regexp.MustCompile(`Ref\.(\d+)`)) },
+			product:  "TSEL5",
+			want:     "998877",
+		},
+		{
+			name:     "other product",
+			register: func(e *orderkuota.SNExtractor) { e.Register("TSEL10", regexp.MustCompile(`Ref\.(\d+)`)) },
+			product:  "TSEL5",
+			want:     "111122223333",
+		},
+		{
+			name: "later product pattern first",
+			register: func(e *orderkuota.SNExtractor) {
+				e.Register("TSEL5", regexp.MustCompile(`Ref\.(\d+)`))
+				e.Register("TSEL5", regexp.MustCompile(`VC:\S+`))
+			},
+			product: "TSEL5",
+			want:    "VC:ZZ-9",
+		},
+		{
+			name:     "category pattern",
+			register: func(e *orderkuota.SNExtractor) { e.RegisterCategory("PULSA", regexp.MustCompile(`VC:(\S+)`)) },
+			category: orderkuota.CategoryPulsa,
+			product:  "TSEL5",
+			want:     "ZZ-9",
+		},
+		{
+			name: "product before category",
+			register: func(e *orderkuota.SNExtractor) {
+				e.Register("TSEL5", regexp.MustCompile(`Ref\.(\d+)`))
+				e.RegisterCategory(orderkuota.CategoryPulsa, regexp.MustCompile(`VC:(\S+)`))
+			},
+			category: orderkuota.CategoryPulsa,
+			product:  "TSEL5",
+			want:     "998877",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := orderkuota.NewSNExtractor()
+			if tt.register != nil {
+				tt.register(e)
+			}
+			if got := e.Extract(tt.category, tt.product, message); got != tt.want {
+				t.Errorf("Extract = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTransactionSerialNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		opts    []orderkuota.Option
+		want    string
+	}{
+		{"extracted", "transaction_sn.json", nil, "1234-5678-9012-3456-7890"},
+		{"reported", "transaction_sn_field.json", nil, "0000-1111-2222-3333-4444"},
+		{"extraction disabled", "transaction_sn.json", []orderkuota.Option{orderkuota.WithSNExtractor(nil)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/transaction": tt.fixture})
+			c := newTestClient(t, srv, tt.opts...)
+
+			tx, err := c.GetTransaction(context.Background(), "T1")
+			if err != nil {
+				t.Fatalf("GetTransaction: %v", err)
+			}
+			if tx.SerialNumber != tt.want {
+				t.Errorf("SerialNumber = %q, want %q", tx.SerialNumber, tt.want)
+			}
+			if !strings.HasPrefix(tx.Message, "Trx PLN20 01234567xxxx SUKSES. SN: ") {
+				t.Errorf("Message = %q, want the raw gateway message", tx.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+)
+
+// TokenSource supplies the credentials of every request.
+// TokenSource menyediakan kredensial untuk setiap request.
+//
+// Implementations must be safe for concurrent use.
+// Implementasi harus aman dipakai secara bersamaan.
+type TokenSource interface {
+	Token(ctx context.Context) (auth.Credentials, error)
+}
+
+// refresher is implemented by token sources that can replace rejected credentials.
+// refresher diimplementasikan oleh token source yang dapat mengganti kredensial yang ditolak.
+type refresher interface {
+	// refresh returns credentials newer than stale, logging in again only if nobody else already did.
+	// refresh mengembalikan kredensial yang lebih baru dari stale, login ulang hanya jika belum ada yang melakukannya.
+	refresh(ctx context.Context, stale auth.Credentials) (auth.Credentials, error)
+}
+
+// staticTokenSource always returns the same credentials.
+// staticTokenSource selalu mengembalikan kredensial yang sama.
+type staticTokenSource struct {
+	creds auth.Credentials
+}
+
+func (s staticTokenSource) Token(context.Context) (auth.Credentials, error) {
+	return s.creds, nil
+}
+
+// WithTokenSource makes the client ask ts for credentials on every request instead of
+// using the credentials passed to NewClient.
+// WithTokenSource membuat client meminta kredensial ke ts pada setiap request alih-alih
+// memakai kredensial yang diberikan ke NewClient.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		if ts != nil {
+			c.tokens = ts
+		}
+	}
+}
+
+// ReloginTokenSource logs in again with a stored username and password when the gateway
+// rejects the current token, after which the rejected request is retried once.
+// ReloginTokenSource login ulang dengan username dan password yang tersimpan saat gateway
+// menolak token saat ini, lalu request yang ditolak diulang sekali.
+//
+// Concurrent rejections trigger a single login; every waiting request uses its result.
+// Penolakan yang terjadi bersamaan hanya memicu satu login; semua request yang menunggu memakai hasilnya.
+type ReloginTokenSource struct {
+	username  string
+	password  string
+	otp       auth.OTPFunc
+	onRefresh func(auth.Credentials)
+	authOpts  []auth.Option
+
+	mu    sync.Mutex
+	creds auth.Credentials
+}
+
+// ReloginOption configures a ReloginTokenSource.
+// ReloginOption mengatur ReloginTokenSource.
+type ReloginOption func(*ReloginTokenSource)
+
+// WithInitialCredentials starts with stored credentials instead of logging in on the first request.
+// WithInitialCredentials memulai dengan kredensial tersimpan alih-alih login pada request pertama.
+func WithInitialCredentials(creds auth.Credentials) ReloginOption {
+	return func(s *ReloginTokenSource) {
+		s.creds = creds
+	}
+}
+
+// WithOTPCallback supplies the OTP when the gateway demands one during a login.
+// WithOTPCallback menyediakan OTP saat gateway memintanya ketika login.
+func WithOTPCallback(fn auth.OTPFunc) ReloginOption {
+	return func(s *ReloginTokenSource) {
+		s.otp = fn
+	}
+}
+
+// WithRefreshCallback reports new credentials after every login so the app can persist them.
+// WithRefreshCallback melaporkan kredensial baru setelah setiap login agar aplikasi dapat menyimpannya.
+func WithRefreshCallback(fn func(auth.Credentials)) ReloginOption {
+	return func(s *ReloginTokenSource) {
+		s.onRefresh = fn
+	}
+}
+
+// WithAuthOptions passes options such as auth.WithBaseURL to the login requests.
+// WithAuthOptions meneruskan opsi seperti auth.WithBaseURL ke request login.
+func WithAuthOptions(opts ...auth.Option) ReloginOption {
+	return func(s *ReloginTokenSource) {
+		s.authOpts = append(s.authOpts, opts...)
+	}
+}
+
+// NewReloginTokenSource creates a ReloginTokenSource. The password is kept in memory only.
+// NewReloginTokenSource membuat ReloginTokenSource. Password hanya disimpan di memori.
+func NewReloginTokenSource(username, password string, opts ...ReloginOption) (*ReloginTokenSource, error) {
+	if username == "" || password == "" {
+		return nil, errors.New("username and password must be filled / username dan password harus diisi")
+	}
+
+	s := &ReloginTokenSource{username: username, password: password}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Token returns the current credentials, logging in first if there are none yet.
+// Token mengembalikan kredensial saat ini, login terlebih dahulu jika belum ada.
+func (s *ReloginTokenSource) Token(ctx context.Context) (auth.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.creds.Token != "" {
+		return s.creds, nil
+	}
+	return s.loginLocked(ctx)
+}
+
+func (s *ReloginTokenSource) refresh(ctx context.Context, stale auth.Credentials) (auth.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another request already replaced the rejected token
+	if s.creds.Token != "" && s.creds.Token != stale.Token {
+		return s.creds, nil
+	}
+	return s.loginLocked(ctx)
+}
+
+func (s *ReloginTokenSource) loginLocked(ctx context.Context) (auth.Credentials, error) {
+	creds, err := auth.Login(ctx, s.username, s.password, s.otp, s.authOpts...)
+	if err != nil {
+		return auth.Credentials{}, err
+	}
+
+	s.creds = *creds
+	if s.onRefresh != nil {
+		s.onRefresh(s.creds)
+	}
+	return s.creds, nil
+}
@@ -0,0 +1,142 @@
+package orderkuota_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/auth"
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+// reloginServers is a fake login endpoint handing out token "fresh" and a gateway accepting
+// only that token.
+type reloginServers struct {
+	login, gateway *httptest.Server
+	logins         atomic.Int32
+}
+
+// newReloginServers starts the servers. The login succeeds with password only, and the gateway answers
+// rejected requests once stale of them have arrived.
+func newReloginServers(t *testing.T, password string, stale int) *reloginServers {
+	t.Helper()
+	s := &reloginServers{}
+	s.login = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		s.logins.Add(1)
+		if body["password"] != password {
+			w.Write([]byte(`{"success":false,"message":"Password salah"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"results":{"token":"fresh","username":"user"}}`))
+	}))
+	t.Cleanup(s.login.Close)
+
+	var mu sync.Mutex
+	rejected := 0
+	burst := make(chan struct{})
+	s.gateway = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["auth_token"] == "fresh" {
+			w.Write([]byte(`{"success":true,"results":{"balance":"125000"}}`))
+			return
+		}
+		// Reject the burst together so every request holds the stale token
+		mu.Lock()
+		if rejected++; rejected == stale {
+			close(burst)
+		}
+		mu.Unlock()
+		select {
+		case <-burst:
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(s.gateway.Close)
+	return s
+}
+
+func TestReloginTokenSource(t *testing.T) {
+	const burst = 5
+	srv := newReloginServers(t, "secret", burst)
+	var refreshed []auth.Credentials
+	ts, err := orderkuota.NewReloginTokenSource("user", "secret",
+		orderkuota.WithInitialCredentials(auth.Credentials{Username: "user", Token: "expired"}),
+		orderkuota.WithRefreshCallback(func(c auth.Credentials) { refreshed = append(refreshed, c) }),
+		orderkuota.WithAuthOptions(auth.WithBaseURL(srv.login.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := orderkuota.NewClient(auth.Credentials{}, orderkuota.WithBaseURL(srv.gateway.URL), orderkuota.WithTokenSource(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of rejected requests logs in once and every request is retried with the new token
+	var wg sync.WaitGroup
+	errs := make(chan error, burst)
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balance, err := c.GetBalance(context.Background())
+			if err == nil && balance.Amount != 125000 {
+				err = errors.New("wrong balance")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+	}
+	if n := srv.logins.Load(); n != 1 {
+		t.Fatalf("%d logins, want 1", n)
+	}
+	if len(refreshed) != 1 || refreshed[0].Token != "fresh" {
+		t.Fatalf("refresh callback got %+v, want the fresh token once", refreshed)
+	}
+	if creds, err := ts.Token(context.Background()); err != nil || creds.Token != "fresh" {
+		t.Fatalf("Token = %+v, %v, want the fresh token", creds, err)
+	}
+}
+
+func TestReloginTokenSourceFailure(t *testing.T) {
+	srv := newReloginServers(t, "other", 1)
+	ts, err := orderkuota.NewReloginTokenSource("user", "secret",
+		orderkuota.WithInitialCredentials(auth.Credentials{Username: "user", Token: "expired"}),
+		orderkuota.WithAuthOptions(auth.WithBaseURL(srv.login.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := orderkuota.NewClient(auth.Credentials{}, orderkuota.WithBaseURL(srv.gateway.URL), orderkuota.WithTokenSource(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed login reports the original rejection
+	_, err = c.GetBalance(context.Background())
+	if !errors.Is(err, orderkuota.ErrUnauthorized) {
+		t.Fatalf("GetBalance err = %v, want ErrUnauthorized", err)
+	}
+	if n := srv.logins.Load(); n != 1 {
+		t.Fatalf("%d logins, want 1", n)
+	}
+
+	if _, err := orderkuota.NewReloginTokenSource("user", ""); err == nil {
+		t.Fatal("NewReloginTokenSource without a password succeeded")
+	}
+}
@@ -0,0 +1,171 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of gateway dates.
+// dateLayout adalah format tanggal dari gateway.
+const dateLayout = "2006-01-02 15:04:05"
+
+// DefaultPollInterval is the starting interval of WaitForCompletion.
+// DefaultPollInterval adalah interval awal WaitForCompletion.
+const DefaultPollInterval = 3 * time.Second
+
+// maxPollInterval caps the backoff of WaitForCompletion.
+// maxPollInterval membatasi backoff WaitForCompletion.
+const maxPollInterval = time.Minute
+
+// ErrTransactionFailed is returned by WaitForCompletion when the purchase failed.
+// ErrTransactionFailed dikembalikan oleh WaitForCompletion jika pembelian gagal.
+var ErrTransactionFailed = errors.New("transaction failed / transaksi gagal")
+
+// ErrTransactionNotFound is returned when the gateway has no transaction with the requested ID.
+// The returned error also wraps the *APIError of the gateway answer.
+// ErrTransactionNotFound dikembalikan jika gateway tidak memiliki transaksi dengan ID yang diminta.
+// Error yang dikembalikan juga membungkus *APIError dari jawaban gateway.
+var ErrTransactionNotFound = errors.New("transaction not found / transaksi tidak ditemukan")
+
+// Transaction is a purchase as recorded by the gateway.
+// Transaction adalah pembelian sesuai catatan gateway.
+type Transaction struct {
+	TransactionID string            // Gateway transaction ID / ID transaksi gateway
+	RefID         string            // Client reference ID / ID referensi client
+	ProductCode   string            // Product code / Kode produk
+	Destination   string            // Destination number / Nomor tujuan
+	Status        TransactionStatus // Current status / Status saat ini
+	Price         int64             // Price charged in rupiah / Harga yang dipotong dalam rupiah
+	SerialNumber  string            // Voucher code or PLN token (if success) / Kode voucher atau token PLN (jika success)
+	Message       string            // Gateway message, the failure reason if failed / Pesan gateway, alasan gagal jika failed
+	Date          time.Time         // Creation time, zero if unknown / Waktu pembuatan, nol jika tidak diketahui
+}
+
+// transactionRow is the gateway form of a Transaction.
+// transactionRow adalah bentuk Transaction dari gateway.
+type transactionRow struct {
+	TransactionID text   `json:"trx_id"`
+	RefID         string `json:"ref_id"`
+	ProductCode   string `json:"product_code"`
+	Destination   string `json:"destination"`
+	Status        text   `json:"status"`
+	Price         amount `json:"price"`
+	SerialNumber  string `json:"sn"`
+	Message       string `json:"message"`
+	Date          string `json:"date"`
+	Duplicate     bool   `json:"duplicate"`
+}
+
+func (r transactionRow) transaction(loc *time.Location) *Transaction {
+	date, _ := time.ParseInLocation(dateLayout, r.Date, loc)
+	return &Transaction{
+		TransactionID: string(r.TransactionID),
+		RefID:         r.RefID,
+		ProductCode:   r.ProductCode,
+		Destination:   r.Destination,
+		Status:        parseTransactionStatus(string(r.Status)),
+		Price:         int64(r.Price),
+		SerialNumber:  r.SerialNumber,
+		Message:       r.Message,
+		Date:          date,
+	}
+}
+
+func (r transactionRow) result() *PurchaseResult {
+	return &PurchaseResult{
+		TransactionID: string(r.TransactionID),
+		RefID:         r.RefID,
+		Status:        parseTransactionStatus(string(r.Status)),
+		Price:         int64(r.Price),
+		SerialNumber:  r.SerialNumber,
+		Message:       r.Message,
+		Duplicate:     r.Duplicate,
+	}
+}
+
+// GetTransaction returns the current state of a purchase, or an error wrapping ErrTransactionNotFound
+// if the gateway does not know trxID.
+// GetTransaction mengembalikan status terkini sebuah pembelian, atau error yang membungkus
+// ErrTransactionNotFound jika gateway tidak mengenal trxID.
+func (c *Client) GetTransaction(ctx context.Context, trxID string) (*Transaction, error) {
+	if trxID == "" {
+		return nil, errors.New("trxID must be filled / trxID harus diisi")
+	}
+
+	var row transactionRow
+	if err := c.do(ctx, "/transaction", map[string]string{"trx_id": trxID}, &row); err != nil {
+		return nil, notFoundError(err)
+	}
+	tx := row.transaction(c.loc)
+	tx.SerialNumber = c.serialNumber(tx.ProductCode, tx.SerialNumber, tx.Message)
+	return tx, nil
+}
+
+// WaitForCompletion polls a purchase until it succeeds or fails.
+// WaitForCompletion melakukan polling pembelian sampai berhasil atau gagal.
+//
+// On success the transaction carries the serial number. On failure the transaction is returned
+// together with an error wrapping ErrTransactionFailed and the reason. A pending transaction is only
+// returned when ctx is done, together with ctx.Err(). Polling backs off as the transaction ages.
+// Jika berhasil, transaksi membawa nomor seri. Jika gagal, transaksi dikembalikan bersama error yang
+// membungkus ErrTransactionFailed dan alasannya. Transaksi pending hanya dikembalikan saat ctx selesai,
+// bersama ctx.Err(). Interval polling semakin panjang seiring bertambahnya umur transaksi.
+func (c *Client) WaitForCompletion(ctx context.Context, trxID string, interval time.Duration) (*Transaction, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	start := time.Now()
+	var last *Transaction
+	for {
+		tx, err := c.GetTransaction(ctx, trxID)
+		switch {
+		case errors.Is(err, ErrUnauthorized):
+			return nil, err
+		case err == nil:
+			last = tx
+			switch tx.Status {
+			case TransactionSuccess:
+				return tx, nil
+			case TransactionFailed:
+				return tx, fmt.Errorf("%w: %s", ErrTransactionFailed, tx.Message)
+			}
+		}
+
+		// Other errors are retried on the next tick
+		created := start
+		if last != nil && !last.Date.IsZero() && last.Date.Before(start) {
+			created = last.Date
+		}
+
+		timer := time.NewTimer(backoff(interval, time.Since(created)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff stretches interval as a transaction ages: most purchases finish in the first minute,
+// the rest can take much longer.
+// backoff memperpanjang interval seiring umur transaksi: sebagian besar pembelian selesai di menit
+// pertama, sisanya bisa jauh lebih lama.
+func backoff(interval, age time.Duration) time.Duration {
+	switch {
+	case age < time.Minute:
+	case age < 5*time.Minute:
+		interval *= 2
+	case age < 15*time.Minute:
+		interval *= 4
+	default:
+		interval *= 8
+	}
+	if interval > maxPollInterval {
+		return maxPollInterval
+	}
+	return interval
+}
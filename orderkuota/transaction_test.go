@@ -0,0 +1,119 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+)
+
+func TestWaitForCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		answers    []string // answers of /transaction in order, the last one repeated / jawaban /transaction berurutan, yang terakhir diulang
+		timeout    time.Duration
+		wantStatus orderkuota.TransactionStatus
+		wantErr    error
+		wantSN     string
+		wantPolls  int // 0 for at least two / 0 untuk minimal dua
+	}{
+		{
+			name:       "pending then success",
+			answers:    []string{"transaction_pending.json", "transaction_pending.json", "transaction_success.json"},
+			wantStatus: orderkuota.TransactionSuccess,
+			wantSN:     "0412345678901234",
+			wantPolls:  3,
+		},
+		{
+			name:       "pending then failed",
+			answers:    []string{"transaction_pending.json", "transaction_failed.json"},
+			wantStatus: orderkuota.TransactionFailed,
+			wantErr:    orderkuota.ErrTransactionFailed,
+			wantPolls:  2,
+		},
+		{
+			name:       "gateway error retried",
+			answers:    []string{"rate_limited.json", "transaction_success.json"},
+			wantStatus: orderkuota.TransactionSuccess,
+			wantSN:     "0412345678901234",
+			wantPolls:  2,
+		},
+		{
+			name:       "pending until the context is done",
+			answers:    []string{"transaction_pending.json"},
+			timeout:    50 * time.Millisecond,
+			wantStatus: orderkuota.TransactionPending,
+			wantErr:    context.DeadlineExceeded,
+		},
+		{
+			name:      "unauthorized",
+			answers:   []string{"401"},
+			wantErr:   orderkuota.ErrUnauthorized,
+			wantPolls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := tt.answers[len(tt.answers)-1]
+			srv := newFixtureServer(t, map[string]string{"/transaction": last})
+			srv.queue("/transaction", tt.answers[:len(tt.answers)-1]...)
+			c := newTestClient(t, srv)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			tx, err := c.WaitForCompletion(ctx, "T1", time.Millisecond)
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("WaitForCompletion err = %v, want %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.wantStatus == "":
+				if tx != nil {
+					t.Fatalf("WaitForCompletion = %+v, want no transaction", tx)
+				}
+			case tx == nil || tx.Status != tt.wantStatus || tx.SerialNumber != tt.wantSN:
+				t.Fatalf("WaitForCompletion = %+v, want %s with serial number %q", tx, tt.wantStatus, tt.wantSN)
+			}
+			if tt.wantStatus == orderkuota.TransactionFailed && tx.Message != "Nomor tujuan tidak aktif" {
+				t.Fatalf("failure reason = %q", tx.Message)
+			}
+
+			polls := srv.count("/transaction")
+			if tt.wantPolls == 0 && polls < 2 || tt.wantPolls != 0 && polls != tt.wantPolls {
+				t.Fatalf("%d polls, want %d", polls, tt.wantPolls)
+			}
+			if srv.received()[0].Params["trx_id"] != "T1" {
+				t.Fatalf("polled %v, want trx_id T1", srv.received()[0].Params)
+			}
+		})
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/transaction": "transaction_success.json"})
+	c := newTestClient(t, srv)
+
+	tx, err := c.GetTransaction(context.Background(), "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 5, 1, 9, 0, 0, 0, tx.Date.Location())
+	if tx.TransactionID != "T1" || tx.RefID != "R1" || tx.Price != 10500 || tx.Status != orderkuota.TransactionSuccess || !tx.Date.Equal(want) {
+		t.Fatalf("GetTransaction = %+v", tx)
+	}
+
+	srv.setFixture("/transaction", "transaction_not_found.json")
+	var apiErr *orderkuota.APIError
+	if _, err := c.GetTransaction(context.Background(), "T9"); !errors.Is(err, orderkuota.ErrTransactionNotFound) || !errors.As(err, &apiErr) {
+		t.Fatalf("GetTransaction of an unknown ID = %v, want ErrTransactionNotFound and the APIError", err)
+	}
+	if _, err := c.GetTransaction(context.Background(), ""); err == nil {
+		t.Fatal("GetTransaction without an ID succeeded")
+	}
+}
@@ -0,0 +1,283 @@
+package orderkuota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// MinWithdrawalAmount is the smallest settlement the gateway accepts, in rupiah.
+// MinWithdrawalAmount adalah penarikan terkecil yang diterima gateway, dalam rupiah.
+const MinWithdrawalAmount int64 = 10000
+
+// Withdrawal endpoints; they require a Signer (see WithSigner).
+// Endpoint penarikan; memerlukan Signer (lihat WithSigner).
+const (
+	withdrawPath        = "/qris/withdraw"
+	withdrawStatusPath  = "/qris/withdraw/status"
+	withdrawHistoryPath = "/qris/withdraw/history"
+)
+
+// Withdrawal errors.
+// Error penarikan saldo.
+var (
+	ErrWithdrawalTooSmall  = errors.New("withdrawal amount below the minimum / nominal penarikan di bawah minimum")
+	ErrInsufficientBalance = errors.New("insufficient QRIS balance / saldo QRIS tidak mencukupi")
+)
+
+// InsufficientBalanceError is returned when the QRIS balance cannot cover a withdrawal.
+// It matches ErrInsufficientBalance with errors.Is.
+// InsufficientBalanceError dikembalikan jika saldo QRIS tidak cukup untuk penarikan.
+// Error ini cocok dengan ErrInsufficientBalance melalui errors.Is.
+type InsufficientBalanceError struct {
+	Requested int64  // Requested amount / Nominal yang diminta
+	Available int64  // Available balance, -1 if the gateway did not say / Saldo tersedia, -1 jika gateway tidak menyebutkan
+	Message   string // Gateway message / Pesan dari gateway
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	if e.Available < 0 {
+		return fmt.Sprintf("%v: requested %s / diminta %s", ErrInsufficientBalance, qris.FormatIDR(e.Requested), qris.FormatIDR(e.Requested))
+	}
+	return fmt.Sprintf("%v: requested %s, available %s / diminta %s, tersedia %s", ErrInsufficientBalance,
+		qris.FormatIDR(e.Requested), qris.FormatIDR(e.Available), qris.FormatIDR(e.Requested), qris.FormatIDR(e.Available))
+}
+
+// Is reports whether target is ErrInsufficientBalance.
+// Is melaporkan apakah target adalah ErrInsufficientBalance.
+func (e *InsufficientBalanceError) Is(target error) bool {
+	return target == ErrInsufficientBalance
+}
+
+// WithdrawalStatus is the state of a settlement to the merchant's bank account.
+// WithdrawalStatus adalah status penarikan ke rekening bank merchant.
+type WithdrawalStatus string
+
+// Withdrawal statuses. Processed and rejected are terminal.
+// Status penarikan. Processed dan rejected adalah status akhir.
+const (
+	WithdrawalPending   WithdrawalStatus = "pending"
+	WithdrawalProcessed WithdrawalStatus = "processed"
+	WithdrawalRejected  WithdrawalStatus = "rejected"
+)
+
+// Terminal reports whether the status will not change anymore.
+// Terminal melaporkan apakah status tidak akan berubah lagi.
+func (s WithdrawalStatus) Terminal() bool {
+	return s == WithdrawalProcessed || s == WithdrawalRejected
+}
+
+// parseWithdrawalStatus maps the gateway's status spellings to a WithdrawalStatus.
+// parseWithdrawalStatus memetakan berbagai penulisan status gateway ke WithdrawalStatus.
+func parseWithdrawalStatus(s string) WithdrawalStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "processed", "success", "sukses", "berhasil", "selesai", "1":
+		return WithdrawalProcessed
+	case "rejected", "failed", "gagal", "ditolak", "2":
+		return WithdrawalRejected
+	}
+	return WithdrawalPending
+}
+
+// Withdrawal is a settlement of the QRIS balance to the merchant's bank account.
+// Withdrawal adalah penarikan saldo QRIS ke rekening bank merchant.
+type Withdrawal struct {
+	ID            string           // Gateway withdrawal ID / ID penarikan gateway
+	Amount        int64            // Requested amount in rupiah / Nominal yang diminta dalam rupiah
+	Fee           int64            // Fee charged in rupiah / Biaya yang dipotong dalam rupiah
+	Status        WithdrawalStatus // Current status / Status saat ini
+	BankName      string           // Destination bank / Bank tujuan
+	AccountNumber string           // Destination account number / Nomor rekening tujuan
+	AccountName   string           // Destination account holder / Nama pemilik rekening tujuan
+	Message       string           // Gateway message, the reason if rejected / Pesan gateway, alasan jika ditolak
+	Date          time.Time        // Request time, zero if unknown / Waktu permintaan, nol jika tidak diketahui
+}
+
+// withdrawalRow is the gateway form of a Withdrawal.
+// withdrawalRow adalah bentuk Withdrawal dari gateway.
+type withdrawalRow struct {
+	ID            text   `json:"id"`
+	Amount        amount `json:"amount"`
+	Fee           amount `json:"fee"`
+	Status        text   `json:"status"`
+	BankName      string `json:"bank_name"`
+	AccountNumber text   `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Message       string `json:"message"`
+	Date          string `json:"date"`
+}
+
+func (r withdrawalRow) withdrawal(loc *time.Location) *Withdrawal {
+	date, _ := time.ParseInLocation(dateLayout, r.Date, loc)
+	return &Withdrawal{
+		ID:            string(r.ID),
+		Amount:        int64(r.Amount),
+		Fee:           int64(r.Fee),
+		Status:        parseWithdrawalStatus(string(r.Status)),
+		BankName:      r.BankName,
+		AccountNumber: string(r.AccountNumber),
+		AccountName:   r.AccountName,
+		Message:       r.Message,
+		Date:          date,
+	}
+}
+
+// RequestWithdrawal asks the gateway to settle amount rupiah of the QRIS balance to the bank
+// account registered for the merchant. Amounts below MinWithdrawalAmount fail with
+// ErrWithdrawalTooSmall before calling the gateway; a balance that cannot cover amount fails
+// with *InsufficientBalanceError. The client needs a Signer (see WithSigner).
+// RequestWithdrawal meminta gateway menarik amount rupiah dari saldo QRIS ke rekening bank
+// yang terdaftar untuk merchant. Nominal di bawah MinWithdrawalAmount gagal dengan
+// ErrWithdrawalTooSmall sebelum memanggil gateway; saldo yang tidak cukup untuk amount gagal
+// dengan *InsufficientBalanceError. Client memerlukan Signer (lihat WithSigner).
+func (c *Client) RequestWithdrawal(ctx context.Context, amount int64) (*Withdrawal, error) {
+	if amount < MinWithdrawalAmount {
+		return nil, fmt.Errorf("%w: %s is below %s / %s di bawah %s", ErrWithdrawalTooSmall,
+			qris.FormatIDR(amount), qris.FormatIDR(MinWithdrawalAmount), qris.FormatIDR(amount), qris.FormatIDR(MinWithdrawalAmount))
+	}
+
+	var row withdrawalRow
+	err := c.do(ctx, withdrawPath, map[string]string{"amount": strconv.FormatInt(amount, 10)}, &row)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && isInsufficientBalance(apiErr.Message) {
+		return nil, &InsufficientBalanceError{
+			Requested: amount,
+			Available: availableBalance(apiErr.Message),
+			Message:   apiErr.Message,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := row.withdrawal(c.loc)
+	if w.Amount == 0 {
+		w.Amount = amount
+	}
+	return w, nil
+}
+
+// GetWithdrawalStatus returns the current state of a withdrawal.
+// GetWithdrawalStatus mengembalikan status terkini sebuah penarikan.
+func (c *Client) GetWithdrawalStatus(ctx context.Context, id string) (*Withdrawal, error) {
+	if id == "" {
+		return nil, errors.New("withdrawal ID must be filled / ID penarikan harus diisi")
+	}
+
+	var row withdrawalRow
+	if err := c.do(ctx, withdrawStatusPath, map[string]string{"id": id}, &row); err != nil {
+		return nil, err
+	}
+	w := row.withdrawal(c.loc)
+	if w.ID == "" {
+		w.ID = id
+	}
+	return w, nil
+}
+
+// WithdrawalQuery narrows the withdrawal history fetched from the gateway.
+// WithdrawalQuery mempersempit riwayat penarikan yang diambil dari gateway.
+//
+// Zero values mean "gateway default". Filters are also applied locally,
+// so results are correct even if the gateway ignores them.
+// Nilai nol berarti "default gateway". Filter juga diterapkan secara lokal,
+// sehingga hasil tetap benar walaupun gateway mengabaikannya.
+type WithdrawalQuery struct {
+	From    time.Time        // Inclusive start / Awal (inklusif)
+	To      time.Time        // Exclusive end / Akhir (eksklusif)
+	Status  WithdrawalStatus // Empty for every status / Kosong untuk semua status
+	Page    int              // Page number starting at 1 / Nomor halaman mulai dari 1
+	PerPage int              // Rows per page / Jumlah baris per halaman
+}
+
+// matches reports whether w passes the filters of the query.
+// matches melaporkan apakah w lolos filter dari query.
+func (wq WithdrawalQuery) matches(w Withdrawal) bool {
+	if wq.Status != "" && w.Status != wq.Status {
+		return false
+	}
+	if !wq.From.IsZero() && (w.Date.IsZero() || w.Date.Before(wq.From)) {
+		return false
+	}
+	if !wq.To.IsZero() && (w.Date.IsZero() || !w.Date.Before(wq.To)) {
+		return false
+	}
+	return true
+}
+
+// ListWithdrawals fetches one page of the withdrawal history.
+// ListWithdrawals mengambil satu halaman riwayat penarikan.
+func (c *Client) ListWithdrawals(ctx context.Context, q WithdrawalQuery) ([]Withdrawal, error) {
+	params := map[string]string{}
+	if !q.From.IsZero() {
+		params["date_from"] = q.From.In(c.loc).Format(dateLayout)
+	}
+	if !q.To.IsZero() {
+		params["date_to"] = q.To.In(c.loc).Format(dateLayout)
+	}
+	if q.Status != "" {
+		params["status"] = string(q.Status)
+	}
+	if q.Page > 0 {
+		params["page"] = strconv.Itoa(q.Page)
+	}
+	if q.PerPage > 0 {
+		params["per_page"] = strconv.Itoa(q.PerPage)
+	}
+
+	var results struct {
+		Data []withdrawalRow `json:"data"`
+	}
+	if err := c.do(ctx, withdrawHistoryPath, params, &results); err != nil {
+		return nil, err
+	}
+
+	var withdrawals []Withdrawal
+	for _, row := range results.Data {
+		w := *row.withdrawal(c.loc)
+		if q.matches(w) {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	return withdrawals, nil
+}
+
+// isInsufficientBalance reports whether a failure message means the balance is too low.
+// isInsufficientBalance melaporkan apakah pesan kegagalan berarti saldo tidak cukup.
+func isInsufficientBalance(message string) bool {
+	m := strings.ToLower(message)
+	if !strings.Contains(m, "saldo") && !strings.Contains(m, "balance") {
+		return false
+	}
+	for _, s := range []string{"tidak cukup", "tidak mencukupi", "kurang", "insufficient", "not enough"} {
+		if strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rupiahPattern finds an amount such as "Rp 5.000" in a gateway message.
+// rupiahPattern mencari nominal seperti "Rp 5.000" di pesan gateway.
+var rupiahPattern = regexp.MustCompile(`(?i)rp\.?\s*(\d[\d.]*(?:,\d+)?)`)
+
+// availableBalance returns the last rupiah amount mentioned in message, or -1 if there is none.
+// availableBalance mengembalikan nominal rupiah terakhir yang disebut di message, atau -1 jika tidak ada.
+func availableBalance(message string) int64 {
+	matches := rupiahPattern.FindAllStringSubmatch(message, -1)
+	if len(matches) == 0 {
+		return -1
+	}
+	n, err := qris.ParseIDR(strings.TrimRight(matches[len(matches)-1][1], "."))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package orderkuota_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/orderkuota"
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// newSignedClient returns a client of srv that signs the withdrawal endpoints.
+func newSignedClient(t *testing.T, srv *fixtureServer) *orderkuota.Client {
+	t.Helper()
+	signer, err := orderkuota.NewSigner("M123", "KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestClient(t, srv, orderkuota.WithSigner(signer))
+}
+
+func TestRequestWithdrawal(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/qris/withdraw": "withdrawal.json"})
+	c := newSignedClient(t, srv)
+
+	w, err := c.RequestWithdrawal(context.Background(), 50000)
+	if err != nil {
+		t.Fatalf("RequestWithdrawal: %v", err)
+	}
+	want := &orderkuota.Withdrawal{
+		ID:            "WD1",
+		Amount:        50000,
+		Fee:           2500,
+		Status:        orderkuota.WithdrawalPending,
+		BankName:      "BCA",
+		AccountNumber: "1234567890",
+		AccountName:   "AutoFTbot Store",
+		Date:          time.Date(2024, 5, 1, 9, 0, 0, 0, qris.WIB),
+	}
+	if !reflect.DeepEqual(w, want) {
+		t.Fatalf("RequestWithdrawal = %+v, want %+v", w, want)
+	}
+	if got := srv.received()[0].Params["amount"]; got != "50000" {
+		t.Fatalf("amount sent = %q, want 50000", got)
+	}
+
+	// Below the minimum nothing is sent
+	if _, err := c.RequestWithdrawal(context.Background(), orderkuota.MinWithdrawalAmount-1); !errors.Is(err, orderkuota.ErrWithdrawalTooSmall) {
+		t.Fatalf("RequestWithdrawal below the minimum = %v, want ErrWithdrawalTooSmall", err)
+	}
+	if n := len(srv.received()); n != 1 {
+		t.Fatalf("%d requests sent, want 1", n)
+	}
+}
+
+func TestRequestWithdrawalInsufficientBalance(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		available int64
+	}{
+		{"withdrawal_insufficient.json", 7500},
+		{"withdrawal_insufficient_bare.json", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/qris/withdraw": tt.fixture})
+			c := newSignedClient(t, srv)
+
+			_, err := c.RequestWithdrawal(context.Background(), 50000)
+			var balanceErr *orderkuota.InsufficientBalanceError
+			if !errors.Is(err, orderkuota.ErrInsufficientBalance) || !errors.As(err, &balanceErr) {
+				t.Fatalf("RequestWithdrawal = %v, want an InsufficientBalanceError", err)
+			}
+			if balanceErr.Requested != 50000 || balanceErr.Available != tt.available || balanceErr.Message == "" {
+				t.Fatalf("error = %+v, want 50000 requested and %d available", balanceErr, tt.available)
+			}
+		})
+	}
+
+	// Other rejections keep the gateway error
+	srv := newFixtureServer(t, map[string]string{"/qris/withdraw": "rate_limited.json"})
+	_, err := newSignedClient(t, srv).RequestWithdrawal(context.Background(), 50000)
+	var apiErr *orderkuota.APIError
+	if !errors.As(err, &apiErr) || errors.Is(err, orderkuota.ErrInsufficientBalance) {
+		t.Fatalf("RequestWithdrawal = %v, want the gateway error", err)
+	}
+}
+
+func TestGetWithdrawalStatus(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{"/qris/withdraw/status": "withdrawal_rejected.json"})
+	c := newSignedClient(t, srv)
+
+	w, err := c.GetWithdrawalStatus(context.Background(), "WD1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != orderkuota.WithdrawalRejected || !w.Status.Terminal() || w.Message != "Rekening tujuan tidak valid" ||
+		w.AccountNumber != "1234567890" || w.Amount != 50000 {
+		t.Fatalf("GetWithdrawalStatus = %+v, want the rejected WD1", w)
+	}
+	if got := srv.received()[0].Params["id"]; got != "WD1" {
+		t.Fatalf("id sent = %q, want WD1", got)
+	}
+	if _, err := c.GetWithdrawalStatus(context.Background(), ""); err == nil {
+		t.Fatal("GetWithdrawalStatus without an ID succeeded")
+	}
+}
+
+func TestListWithdrawals(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, qris.WIB)
+	tests := []struct {
+		name  string
+		query orderkuota.WithdrawalQuery
+		want  []string
+	}{
+		{name: "all", want: []string{"WD1", "WD2", "WD3"}},
+		{name: "processed", query: orderkuota.WithdrawalQuery{Status: orderkuota.WithdrawalProcessed}, want: []string{"WD1"}},
+		{name: "rejected", query: orderkuota.WithdrawalQuery{Status: orderkuota.WithdrawalRejected}, want: []string{"WD3"}},
+		{name: "date range", query: orderkuota.WithdrawalQuery{From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2)}, want: []string{"WD2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFixtureServer(t, map[string]string{"/qris/withdraw/history": "withdrawals.json"})
+			got, err := newSignedClient(t, srv).ListWithdrawals(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, w := range got {
+				ids = append(ids, w.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Fatalf("ListWithdrawals = %v, want %v", ids, tt.want)
+			}
+			params := srv.received()[0].Params
+			if tt.query.Status != "" && params["status"] != string(tt.query.Status) ||
+				!tt.query.From.IsZero() && params["date_from"] != "2024-05-02 00:00:00" {
+				t.Fatalf("sent %v for %+v", params, tt.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package qris
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Context keys of the audit values. They are unexported so only WithActor and WithTenant set
+// them; read them with ActorFromContext and TenantFromContext.
+// Key context untuk nilai audit. Key tidak diekspor sehingga hanya WithActor dan WithTenant yang
+// mengaturnya; baca dengan ActorFromContext dan TenantFromContext.
+type (
+	actorKey  struct{}
+	tenantKey struct{}
+)
+
+// WithActor returns a copy of ctx naming the user or system that makes the calls with it, e.g.
+// "admin". Log lines, PaymentEvents and the requests seen by interceptors carry it.
+// WithActor mengembalikan salinan ctx yang menyebut pengguna atau sistem yang melakukan panggilan
+// dengannya, misalnya "admin". Baris log, PaymentEvent dan request yang dilihat interceptor membawanya.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithTenant returns a copy of ctx naming the tenant the calls with it are made for, e.g. "toko-a".
+// WithTenant mengembalikan salinan ctx yang menyebut tenant yang dilayani panggilan dengannya, misalnya "toko-a".
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// ActorFromContext returns the actor set by WithActor, or "".
+// ActorFromContext mengembalikan actor yang diatur WithActor, atau "".
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// TenantFromContext returns the tenant set by WithTenant, or "".
+// TenantFromContext mengembalikan tenant yang diatur WithTenant, atau "".
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// withAudit copies the tenant and actor of from onto ctx, for work that outlives the caller's context.
+// withAudit menyalin tenant dan actor dari from ke ctx, untuk pekerjaan yang melebihi umur context pemanggil.
+func withAudit(ctx, from context.Context) context.Context {
+	if tenant := TenantFromContext(from); tenant != "" {
+		ctx = WithTenant(ctx, tenant)
+	}
+	if actor := ActorFromContext(from); actor != "" {
+		ctx = WithActor(ctx, actor)
+	}
+	return ctx
+}
+
+// auditPrefix returns "tenant=… actor=… " for the values set on ctx, or "" if there are none.
+// auditPrefix mengembalikan "tenant=… actor=… " untuk nilai yang diatur di ctx, atau "" jika tidak ada.
+func auditPrefix(ctx context.Context) string {
+	var b strings.Builder
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		b.WriteString("tenant=" + tenant + " ")
+	}
+	if actor := ActorFromContext(ctx); actor != "" {
+		b.WriteString("actor=" + actor + " ")
+	}
+	return b.String()
+}
+
+// logf logs like log.Printf, prefixed with the tenant and actor of ctx.
+// logf mencatat seperti log.Printf, diawali tenant dan actor dari ctx.
+func logf(ctx context.Context, format string, args ...any) {
+	log.Print(auditPrefix(ctx) + fmt.Sprintf(format, args...))
+}
+
+// AuditInterceptor logs every gateway request attempt to logger (log.Default() if nil) with the
+// tenant and actor of its context, e.g. "tenant=toko-a actor=admin GET ftvpn.me/api/mutasi 200 (120ms)".
+// AuditInterceptor mencatat setiap percobaan request gateway ke logger (log.Default() jika nil) beserta
+// tenant dan actor dari context-nya, misalnya "tenant=toko-a actor=admin GET ftvpn.me/api/mutasi 200 (120ms)".
+func AuditInterceptor(logger *log.Logger) Interceptor {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			took := time.Since(start).Round(time.Millisecond)
+			target := req.URL.Host + req.URL.Path
+			if err != nil {
+				logger.Printf("%s%s %s failed (%s): %v", auditPrefix(req.Context()), req.Method, target, took, err)
+			} else {
+				logger.Printf("%s%s %s %d (%s)", auditPrefix(req.Context()), req.Method, target, resp.StatusCode, took)
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// DefaultUniqueSuffix is the default number of rupiah that may be added to a base amount
+// to make it unique among pending payments.
+// DefaultUniqueSuffix adalah jumlah rupiah default yang boleh ditambahkan ke nominal dasar
+// agar unik di antara pembayaran yang masih menunggu.
+const DefaultUniqueSuffix int64 = 999
+
+// ErrNoAmountAvailable is returned when every amount in the unique suffix space is reserved.
+// ErrNoAmountAvailable dikembalikan jika semua nominal dalam ruang suffix unik sudah dipesan.
+var ErrNoAmountAvailable = errors.New("no unique amount available / tidak ada nominal unik yang tersedia")
+
+// ErrAmountCollision is returned when the deterministic amount of a payment is already reserved by another one.
+// ErrAmountCollision dikembalikan jika nominal deterministik sebuah pembayaran sudah dipesan oleh pembayaran lain.
+var ErrAmountCollision = errors.New("deterministic amount collides with a pending payment / nominal deterministik bertabrakan dengan pembayaran yang menunggu")
+
+// DeterministicAmount derives the payable amount of transactionID from its hash: base plus a
+// suffix in [1, space]. The same ID always maps to the same amount, even across restarts,
+// but two IDs may collide; a space of 0 or less uses DefaultUniqueSuffix.
+// DeterministicAmount menurunkan nominal yang harus dibayar untuk transactionID dari hash-nya: base
+// ditambah suffix di [1, space]. ID yang sama selalu menghasilkan nominal yang sama, bahkan setelah restart,
+// tetapi dua ID bisa bertabrakan; space 0 atau kurang memakai DefaultUniqueSuffix.
+func DeterministicAmount(base int64, transactionID string, space int) int64 {
+	n := int64(space)
+	if n <= 0 {
+		n = DefaultUniqueSuffix
+	}
+	h := fnv.New64a()
+	h.Write([]byte(transactionID))
+	return base + 1 + int64(h.Sum64()%uint64(n))
+}
+
+// amountPool tracks payable amounts reserved by pending payments.
+// amountPool mencatat nominal yang sedang dipesan oleh pembayaran yang menunggu.
+type amountPool struct {
+	mu       sync.Mutex
+	reserved map[int64]string
+	rand     *randSource
+}
+
+func newAmountPool(rand *randSource) *amountPool {
+	return &amountPool{reserved: make(map[int64]string), rand: rand}
+}
+
+// reserve picks a free amount in [base, base+maxSuffix] for the given owner.
+// reserve memilih nominal kosong di [base, base+maxSuffix] untuk pemilik tertentu.
+func (p *amountPool) reserve(base, maxSuffix int64, owner string) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.reserveLocked(base, maxSuffix, owner)
+}
+
+func (p *amountPool) reserveLocked(base, maxSuffix int64, owner string) (int64, error) {
+	if maxSuffix <= 0 {
+		if _, taken := p.reserved[base]; taken {
+			return 0, ErrNoAmountAvailable
+		}
+		p.reserved[base] = owner
+		return base, nil
+	}
+
+	// Start at a random suffix so concurrent invoices spread over the space
+	start := p.rand.int63n(maxSuffix)
+	for i := int64(0); i < maxSuffix; i++ {
+		amount := base + 1 + (start+i)%maxSuffix
+		if _, taken := p.reserved[amount]; !taken {
+			p.reserved[amount] = owner
+			return amount, nil
+		}
+	}
+	return 0, ErrNoAmountAvailable
+}
+
+// reserveExact reserves amount for owner, failing with ErrAmountCollision if it is already held.
+// reserveExact memesan amount untuk owner, gagal dengan ErrAmountCollision jika sudah dipegang.
+func (p *amountPool) reserveExact(amount int64, owner string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.reserveExactLocked(amount, owner)
+}
+
+func (p *amountPool) reserveExactLocked(amount int64, owner string) error {
+	if holder, taken := p.reserved[amount]; taken {
+		return fmt.Errorf("%w: %s is reserved by %s / %s dipesan oleh %s", ErrAmountCollision, FormatIDR(amount), holder, FormatIDR(amount), holder)
+	}
+	p.reserved[amount] = owner
+	return nil
+}
+
+// release frees an amount, but only if it is still held by the given owner.
+// release melepas nominal, hanya jika masih dipegang oleh pemilik tersebut.
+func (p *amountPool) release(amount int64, owner string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.reserved[amount] == owner {
+		delete(p.reserved, amount)
+	}
+}
+
+// move releases old, held by owner, and reserves a new amount in one step: exact if exact > 0, as
+// reserveExact does, otherwise in [base, base+maxSuffix]. If no new amount is free, old stays reserved.
+// move melepas old, yang dipegang owner, dan memesan nominal baru dalam satu langkah: exact jika exact > 0,
+// seperti reserveExact, selain itu di [base, base+maxSuffix]. Jika tidak ada nominal baru yang kosong, old
+// tetap dipesan.
+func (p *amountPool) move(old int64, owner string, base, maxSuffix, exact int64) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	held := p.reserved[old] == owner
+	if held {
+		delete(p.reserved, old)
+	}
+	var amount int64
+	var err error
+	if exact > 0 {
+		amount, err = exact, p.reserveExactLocked(exact, owner)
+	} else {
+		amount, err = p.reserveLocked(base, maxSuffix, owner)
+	}
+	if err != nil {
+		if held {
+			p.reserved[old] = owner
+		}
+		return 0, err
+	}
+	return amount, nil
+}
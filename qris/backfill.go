@@ -0,0 +1,115 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BackfillOwner is the owner recorded for mutations claimed by BackfillClaims, so no invoice can claim them.
+// BackfillOwner adalah pemilik yang dicatat untuk mutasi yang diklaim BackfillClaims, sehingga tidak ada
+// invoice yang dapat mengklaimnya.
+const BackfillOwner = "(backfill)"
+
+// ErrBackfillUnsupported is returned by BackfillClaims when QRISConfig.InvoiceStore is not a BackfillStore.
+// ErrBackfillUnsupported dikembalikan BackfillClaims jika QRISConfig.InvoiceStore bukan BackfillStore.
+var ErrBackfillUnsupported = errors.New("invoice store cannot backfill claims / invoice store tidak dapat mengisi klaim lama")
+
+// BackfillCheckpoint records the progress of BackfillClaims.
+// BackfillCheckpoint mencatat progres BackfillClaims.
+type BackfillCheckpoint struct {
+	Through time.Time // Mutations before Through are all claimed / Mutasi sebelum Through sudah diklaim semua
+	Cutoff  time.Time // Cutoff of an unfinished run, zero if none / Batas dari proses yang belum selesai, nol jika tidak ada
+	Page    int       // Pages of the unfinished run already claimed / Halaman dari proses yang belum selesai yang sudah diklaim
+}
+
+// BackfillStore is implemented by invoice stores that can claim historical mutations and keep a
+// BackfillCheckpoint. SQLiteStore implements it.
+// BackfillStore diimplementasikan oleh invoice store yang dapat mengklaim mutasi lama dan menyimpan
+// BackfillCheckpoint. SQLiteStore mengimplementasikannya.
+type BackfillStore interface {
+	MutationClaimer
+
+	// LoadBackfillCheckpoint returns the saved checkpoint, or a zero one.
+	// LoadBackfillCheckpoint mengembalikan checkpoint yang tersimpan, atau checkpoint nol.
+	LoadBackfillCheckpoint(ctx context.Context) (BackfillCheckpoint, error)
+
+	// SaveBackfillCheckpoint replaces the saved checkpoint.
+	// SaveBackfillCheckpoint mengganti checkpoint yang tersimpan.
+	SaveBackfillCheckpoint(ctx context.Context, cp BackfillCheckpoint) error
+}
+
+// BackfillClaims claims every incoming mutation dated before since for BackfillOwner in the
+// InvoiceStore, which must be a BackfillStore, so that only newer mutations can pay invoices. Run it
+// once when adopting the claim store on an account that already has traffic. It returns the number of
+// mutations walked by this call that are claimed for BackfillOwner.
+// BackfillClaims mengklaim setiap mutasi masuk bertanggal sebelum since untuk BackfillOwner di
+// InvoiceStore, yang harus berupa BackfillStore, sehingga hanya mutasi yang lebih baru yang dapat membayar
+// invoice. Jalankan sekali saat mulai memakai claim store di akun yang sudah memiliki transaksi. Fungsi ini
+// mengembalikan jumlah mutasi yang ditelusuri panggilan ini dan diklaim untuk BackfillOwner.
+//
+// Progress is checkpointed after every page: an interrupted run resumes at the next page when called
+// again with the same since, and a later since only walks the mutations after the previous one. Running
+// it twice is safe, since mutations already claimed keep their owner.
+// Progres disimpan setelah setiap halaman: proses yang terputus dilanjutkan dari halaman berikutnya saat
+// dipanggil lagi dengan since yang sama, dan since yang lebih baru hanya menelusuri mutasi setelah since
+// sebelumnya. Menjalankannya dua kali aman, karena mutasi yang sudah diklaim tetap dengan pemiliknya.
+func (q *QRIS) BackfillClaims(ctx context.Context, since time.Time) (int, error) {
+	store, ok := q.config.InvoiceStore.(BackfillStore)
+	if !ok {
+		return 0, ErrBackfillUnsupported
+	}
+	cp, err := store.LoadBackfillCheckpoint(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load backfill checkpoint / gagal memuat checkpoint backfill: %w", err)
+	}
+	if !since.After(cp.Through) {
+		return 0, nil
+	}
+
+	query := MutationQuery{From: cp.Through, To: since, Type: "CR", Page: 1, PerPage: DefaultMutationsPerPage}
+	if cp.Cutoff.Equal(since) && cp.Page > 0 {
+		query.Page = cp.Page + 1
+	}
+
+	claimed := 0
+	var prevFirst string
+	for {
+		if err := ctx.Err(); err != nil {
+			return claimed, err
+		}
+		page, err := q.eachMutation(ctx, query, func(m Mutation) error {
+			if m.IssuerRef == "" {
+				return nil
+			}
+			owner, err := store.ClaimMutation(ctx, MutationKey(m.IssuerRef, m.Date), BackfillOwner)
+			if err != nil {
+				return err
+			}
+			if owner == BackfillOwner {
+				claimed++
+			}
+			return nil
+		})
+		if err != nil {
+			return claimed, err
+		}
+
+		// Same stop rules as eachAllMutations
+		if page.rows < query.PerPage || (page.totalPages > 0 && query.Page >= page.totalPages) || page.first == prevFirst {
+			break
+		}
+		err = store.SaveBackfillCheckpoint(ctx, BackfillCheckpoint{Through: cp.Through, Cutoff: since, Page: query.Page})
+		if err != nil {
+			return claimed, fmt.Errorf("failed to save backfill checkpoint / gagal menyimpan checkpoint backfill: %w", err)
+		}
+		prevFirst = page.first
+		query.Page++
+	}
+
+	if err := store.SaveBackfillCheckpoint(ctx, BackfillCheckpoint{Through: since}); err != nil {
+		return claimed, fmt.Errorf("failed to save backfill checkpoint / gagal menyimpan checkpoint backfill: %w", err)
+	}
+	return claimed, nil
+}
@@ -0,0 +1,120 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// backfillStore is an in-memory BackfillStore whose failSave-th checkpoint save fails.
+type backfillStore struct {
+	mu       sync.Mutex
+	claims   map[string]string
+	cp       qris.BackfillCheckpoint
+	saves    int
+	failSave int // number of the save that fails, 0 for none / nomor penyimpanan yang gagal, 0 jika tidak ada
+}
+
+// errInterrupted is the error of the failing checkpoint save.
+var errInterrupted = errors.New("interrupted")
+
+func (s *backfillStore) Save(context.Context, qris.Invoice) error                    { return nil }
+func (s *backfillStore) LoadPending(context.Context) ([]qris.Invoice, error)         { return nil, nil }
+func (s *backfillStore) MarkPaid(context.Context, string, *qris.PaymentStatus) error { return nil }
+func (s *backfillStore) MarkExpired(context.Context, string) error                   { return nil }
+
+func (s *backfillStore) ClaimMutation(_ context.Context, key, transactionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if owner, ok := s.claims[key]; ok {
+		return owner, nil
+	}
+	if s.claims == nil {
+		s.claims = make(map[string]string)
+	}
+	s.claims[key] = transactionID
+	return transactionID, nil
+}
+
+func (s *backfillStore) LoadBackfillCheckpoint(context.Context) (qris.BackfillCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cp, nil
+}
+
+func (s *backfillStore) SaveBackfillCheckpoint(_ context.Context, cp qris.BackfillCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saves++; s.saves == s.failSave {
+		return errInterrupted
+	}
+	s.cp = cp
+	return nil
+}
+
+func TestBackfillClaims(t *testing.T) {
+	store := &backfillStore{failSave: 2}
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.InvoiceStore = store })
+	ctx := testContext(t, 10*time.Second)
+
+	// 250 credits, three pages, before the cutoff; the newest one already paid an invoice
+	cutoff := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var old []qristest.Mutation
+	for i := 0; i < 250; i++ {
+		old = append(old, gw.AddMutation(int64(10000+i), cutoff.Add(-time.Duration(250-i)*time.Second)))
+	}
+	gw.AddMutation(50000, cutoff.Add(-time.Minute), qristest.WithType("DB"))
+	newer := gw.AddMutation(10000, cutoff.Add(time.Minute))
+	key := func(m qristest.Mutation) string {
+		return qris.MutationKey(m.IssuerRef, m.At.In(qris.WIB).Format("2006-01-02 15:04:05"))
+	}
+	if _, err := store.ClaimMutation(ctx, key(old[249]), "INV-OLD"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupted after two pages, the first of them checkpointed
+	n, err := q.BackfillClaims(ctx, cutoff)
+	if !errors.Is(err, errInterrupted) || n != 199 {
+		t.Fatalf("interrupted BackfillClaims = %d, %v, want 199 and the save error", n, err)
+	}
+
+	// Resumed at the second page
+	requests := gw.RequestCount()
+	if n, err := q.BackfillClaims(ctx, cutoff); err != nil || n != 150 {
+		t.Fatalf("resumed BackfillClaims = %d, %v, want 150", n, err)
+	}
+	if got := gw.RequestCount() - requests; got != 2 {
+		t.Fatalf("resumed run read %d pages, want 2", got)
+	}
+	if cp := store.cp; !cp.Through.Equal(cutoff) || !cp.Cutoff.IsZero() || cp.Page != 0 {
+		t.Fatalf("checkpoint = %+v, want through the cutoff", cp)
+	}
+
+	// Nothing is left to walk the second time
+	if n, err := q.BackfillClaims(ctx, cutoff); err != nil || n != 0 {
+		t.Fatalf("second BackfillClaims = %d, %v, want 0", n, err)
+	}
+
+	// Old credits are taken, the earlier claim is kept, and newer credits remain free
+	for _, tt := range []struct {
+		m     qristest.Mutation
+		owner string
+	}{{old[0], qris.BackfillOwner}, {old[120], qris.BackfillOwner}, {old[249], "INV-OLD"}, {newer, "INV-NEW"}} {
+		if owner, err := store.ClaimMutation(ctx, key(tt.m), "INV-NEW"); err != nil || owner != tt.owner {
+			t.Errorf("owner of %s = %q, %v, want %q", tt.m.IssuerRef, owner, err, tt.owner)
+		}
+	}
+}
+
+func TestBackfillClaimsUnsupported(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	if _, err := q.BackfillClaims(testContext(t, 5*time.Second), time.Now()); !errors.Is(err, qris.ErrBackfillUnsupported) {
+		t.Fatalf("BackfillClaims err = %v, want ErrBackfillUnsupported", err)
+	}
+}
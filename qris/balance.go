@@ -0,0 +1,75 @@
+package qris
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Gap is a break in the running balance of the mutation history: the balance moved from Before to
+// After by more or less than the amount of After, so mutations are missing between them.
+// Gap adalah putusnya saldo berjalan di riwayat mutasi: saldo berpindah dari Before ke After lebih atau
+// kurang dari nominal After, sehingga ada mutasi yang hilang di antara keduanya.
+type Gap struct {
+	Before   Mutation // Last row before the gap / Baris terakhir sebelum gap
+	After    Mutation // First row after the gap / Baris pertama setelah gap
+	Expected int64    // Balance After should have / Saldo yang seharusnya dimiliki After
+	Missing  int64    // Net amount of the missing mutations, negative for debits / Nominal bersih mutasi yang hilang, negatif untuk debit
+}
+
+func (g Gap) String() string {
+	return fmt.Sprintf("balance gap of %s between %s (%s) and %s (%s) / selisih saldo %s antara %s (%s) dan %s (%s)",
+		FormatIDR(g.Missing), g.Before.IssuerRef, g.Before.Date, g.After.IssuerRef, g.After.Date,
+		FormatIDR(g.Missing), g.Before.IssuerRef, g.Before.Date, g.After.IssuerRef, g.After.Date)
+}
+
+// VerifyBalanceContinuity walks mutations chronologically and returns a Gap wherever the balance of a
+// row differs from the balance of the previous row plus its amount (minus, for DB rows), which means
+// a mutation is missing from the history. Rows without a balance or a parsable date are skipped, and
+// rows of the same second are chained in the order their balances fit, whatever the gateway order.
+// VerifyBalanceContinuity menelusuri mutasi secara kronologis dan mengembalikan Gap di setiap baris yang
+// saldonya berbeda dari saldo baris sebelumnya ditambah nominalnya (dikurangi, untuk baris DB), yang berarti
+// ada mutasi yang hilang dari riwayat. Baris tanpa saldo atau tanggal yang valid dilewati, dan baris pada
+// detik yang sama dirangkai sesuai urutan saldonya cocok, apa pun urutan dari gateway.
+func VerifyBalanceContinuity(mutations []Mutation) []Gap {
+	rows := make([]Mutation, 0, len(mutations))
+	for _, m := range mutations {
+		if m.HasBalance && !m.Time.IsZero() {
+			rows = append(rows, m)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Time.Before(rows[j].Time)
+	})
+
+	var gaps []Gap
+	for i := 1; i < len(rows); i++ {
+		prev := rows[i-1].Balance
+		// Among the rows of the same second, continue with the one that fits the previous balance
+		for j := i; j < len(rows) && rows[j].Time.Equal(rows[i].Time); j++ {
+			if prev+signedAmount(rows[j]) == rows[j].Balance {
+				rows[i], rows[j] = rows[j], rows[i]
+				break
+			}
+		}
+
+		expected := prev + signedAmount(rows[i])
+		if rows[i].Balance != expected {
+			gaps = append(gaps, Gap{
+				Before:   rows[i-1],
+				After:    rows[i],
+				Expected: expected,
+				Missing:  rows[i].Balance - expected,
+			})
+		}
+	}
+	return gaps
+}
+
+// signedAmount returns the amount of m as it changes the balance: negative for debits.
+// signedAmount mengembalikan nominal m sesuai pengaruhnya pada saldo: negatif untuk debit.
+func signedAmount(m Mutation) int64 {
+	if m.Type == "DB" {
+		return -m.Amount
+	}
+	return m.Amount
+}
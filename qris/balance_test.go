@@ -0,0 +1,51 @@
+package qris_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestVerifyBalanceContinuity(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "balance", "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := staticGateway(t, body)
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+	mutations, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{})
+	if err != nil {
+		t.Fatalf("GetMutations: %v", err)
+	}
+
+	// REF8 has no balance, REF4 and REF5 share a second, and a credit of 50.000 is missing before REF7
+	gaps := qris.VerifyBalanceContinuity(mutations)
+	if len(gaps) != 1 {
+		t.Fatalf("gaps = %+v, want one", gaps)
+	}
+	g := gaps[0]
+	if g.Before.IssuerRef != "REF6" || g.After.IssuerRef != "REF7" || g.Expected != 1070000 || g.Missing != 50000 {
+		t.Fatalf("gap = %s, expected %d missing %d, want 50.000 between REF6 and REF7", g, g.Expected, g.Missing)
+	}
+	if s := g.String(); !strings.Contains(s, "REF6 (2024-05-01 10:00:00)") || !strings.Contains(s, "REF7 (2024-05-01 10:05:00)") || !strings.Contains(s, "Rp 50.000") {
+		t.Fatalf("String = %q, want the references, dates and amount", s)
+	}
+
+	// Without REF7 the history is continuous
+	var continuous []qris.Mutation
+	for _, m := range mutations {
+		if m.IssuerRef != "REF7" {
+			continuous = append(continuous, m)
+		}
+	}
+	if gaps := qris.VerifyBalanceContinuity(continuous); len(gaps) != 0 {
+		t.Fatalf("gaps = %+v, want none", gaps)
+	}
+	if gaps := qris.VerifyBalanceContinuity(nil); len(gaps) != 0 {
+		t.Fatalf("gaps of no mutations = %+v", gaps)
+	}
+}
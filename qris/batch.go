@@ -0,0 +1,55 @@
+package qris
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GenerateQRCodeBatch generates the QR codes of items on a pool of concurrency workers
+// (GOMAXPROCS if 0 or less). Both results have the length of items and follow its order:
+// codes[i] is nil when errs[i] is set. Items not started when ctx is done fail with ctx.Err().
+// GenerateQRCodeBatch men-generate QR code dari items dengan pool berisi concurrency worker
+// (GOMAXPROCS jika 0 atau kurang). Kedua hasil memiliki panjang yang sama dengan items dan mengikuti
+// urutannya: codes[i] bernilai nil jika errs[i] terisi. Item yang belum dimulai saat ctx selesai gagal dengan ctx.Err().
+func (q *QRIS) GenerateQRCodeBatch(ctx context.Context, items []QRISData, concurrency int) ([]*qrcode.QRCode, []error) {
+	codes := make([]*qrcode.QRCode, len(items))
+	errs := make([]error, len(items))
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	if concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				codes[i], errs[i] = q.GenerateQRCode(items[i])
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(items); next++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- next:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < len(items); i++ {
+		errs[i] = ctx.Err()
+	}
+	return codes, errs
+}
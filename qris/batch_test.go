@@ -0,0 +1,98 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// priceList returns n items with distinct amounts, like a nightly product list.
+func priceList(n int) []qris.QRISData {
+	items := make([]qris.QRISData, n)
+	for i := range items {
+		items[i] = qris.QRISData{Amount: int64(1000 + i*500), TransactionID: fmt.Sprintf("SKU%d", i)}
+	}
+	return items
+}
+
+func TestGenerateQRCodeBatchOrder(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	items := priceList(50)
+	items[7].Amount = -1
+
+	for _, concurrency := range []int{0, 1, 8, 100} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			codes, errs := q.GenerateQRCodeBatch(context.Background(), items, concurrency)
+			if len(codes) != len(items) || len(errs) != len(items) {
+				t.Fatalf("got %d codes and %d errors, want %d of each", len(codes), len(errs), len(items))
+			}
+			for i, item := range items {
+				want, wantErr := q.GenerateQRCode(item)
+				if wantErr != nil {
+					if errs[i] == nil || codes[i] != nil {
+						t.Errorf("item %d: code %v, err %v, want error %v", i, codes[i], errs[i], wantErr)
+					}
+					continue
+				}
+				if errs[i] != nil {
+					t.Fatalf("item %d: %v", i, errs[i])
+				}
+				if codes[i].Content != want.Content {
+					t.Errorf("item %d: content %q, want %q", i, codes[i].Content, want.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateQRCodeBatchCancelled(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	items := priceList(20)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	codes, errs := q.GenerateQRCodeBatch(ctx, items, 4)
+	for i := range items {
+		if codes[i] != nil || !errors.Is(errs[i], context.Canceled) {
+			t.Errorf("item %d: code %v, err %v, want %v", i, codes[i], errs[i], context.Canceled)
+		}
+	}
+}
+
+func TestGenerateQRCodeBatchEmpty(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	codes, errs := q.GenerateQRCodeBatch(context.Background(), nil, 8)
+	if len(codes) != 0 || len(errs) != 0 {
+		t.Fatalf("got %d codes and %d errors, want none", len(codes), len(errs))
+	}
+}
+
+func BenchmarkGenerateQRCodeBatch(b *testing.B) {
+	q := newTestQRIS(b, newGateway(b))
+	items := priceList(200)
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, item := range items {
+				if _, err := q.GenerateQRCode(item); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("concurrency=8", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_, errs := q.GenerateQRCodeBatch(context.Background(), items, 8)
+			for _, err := range errs {
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ErrPayloadTooLarge is returned when a QRIS string does not fit in the largest QR code
+// at the minimum recovery level.
+// ErrPayloadTooLarge dikembalikan jika string QRIS tidak muat dalam QR code terbesar
+// pada tingkat pemulihan minimum.
+var ErrPayloadTooLarge = errors.New("payload too large for a QR code / payload terlalu besar untuk QR code")
+
+// maxPayloadBytes is the byte-mode capacity of a version 40 QR code per recovery level.
+// maxPayloadBytes adalah kapasitas mode byte QR code versi 40 per tingkat pemulihan.
+var maxPayloadBytes = map[qrcode.RecoveryLevel]int{
+	qrcode.Low:     2953,
+	qrcode.Medium:  2331,
+	qrcode.High:    1663,
+	qrcode.Highest: 1273,
+}
+
+// NewQRCode encodes qrString as a black-on-white QR code with the highest recovery level up to
+// qrcode.High that fits, but never below minLevel. The chosen size and level are reported in the
+// VersionNumber and Level fields, so renderers can size modules appropriately.
+// NewQRCode meng-encode qrString sebagai QR code hitam di atas putih dengan tingkat pemulihan tertinggi
+// sampai qrcode.High yang muat, tetapi tidak pernah di bawah minLevel. Ukuran dan tingkat yang dipilih
+// dilaporkan di field VersionNumber dan Level, sehingga renderer dapat menyesuaikan ukuran modul.
+//
+// A payload longer than the capacity at minLevel returns ErrPayloadTooLarge.
+// Payload yang lebih panjang dari kapasitas pada minLevel mengembalikan ErrPayloadTooLarge.
+func NewQRCode(qrString string, minLevel qrcode.RecoveryLevel) (*qrcode.QRCode, error) {
+	if minLevel < qrcode.Low || minLevel > qrcode.Highest {
+		minLevel = qrcode.Low
+	}
+	if max := maxPayloadBytes[minLevel]; len(qrString) > max {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d / %d byte, paling banyak %d", ErrPayloadTooLarge, len(qrString), max, len(qrString), max)
+	}
+
+	level := qrcode.High
+	if minLevel > level {
+		level = minLevel
+	}
+
+	// Prefer stronger error correction, stepping down only when the payload does not fit
+	for level > minLevel && len(qrString) > maxPayloadBytes[level] {
+		level--
+	}
+	qrCode, err := qrcode.New(qrString, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code / gagal generate QR code: %v", err)
+	}
+
+	// Set QR code options
+	qrCode.DisableBorder = false
+	qrCode.ForegroundColor = color.Black
+	qrCode.BackgroundColor = color.White
+
+	return qrCode, nil
+}
+
+// newQRCode encodes qrString with the configured minimum recovery level.
+// newQRCode meng-encode qrString dengan tingkat pemulihan minimum yang dikonfigurasi.
+func (q *QRIS) newQRCode(qrString string) (*qrcode.QRCode, error) {
+	return NewQRCode(qrString, q.config.MinRecoveryLevel)
+}
@@ -0,0 +1,123 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by the methods of a QRIS after Close.
+// ErrClosed dikembalikan oleh method QRIS setelah Close.
+var ErrClosed = errors.New("qris instance is closed / instance qris sudah ditutup")
+
+// lifecycle tracks the background goroutines of a QRIS so Close can drain them.
+// lifecycle mencatat goroutine latar belakang QRIS agar Close dapat menunggunya selesai.
+type lifecycle struct {
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+
+	ctx    context.Context // Cancelled by Close / Dibatalkan oleh Close
+	cancel context.CancelFunc
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// track registers a background goroutine, or reports false once the instance is closed.
+// track mendaftarkan goroutine latar belakang, atau mengembalikan false jika instance sudah ditutup.
+func (l *lifecycle) track() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return false
+	}
+	l.wg.Add(1)
+	return true
+}
+
+// err returns ErrClosed once the instance is closed.
+// err mengembalikan ErrClosed jika instance sudah ditutup.
+func (l *lifecycle) err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Close shuts the instance down gracefully and returns when everything is drained or ctx is done.
+// Close mematikan instance dengan rapi dan kembali setelah semuanya selesai atau ctx selesai.
+//
+// New sessions and gateway calls are refused with ErrClosed. Session watchers finish their
+// current check, so a payment found in flight is still recorded as PAID, duplicate
+// detection stops, and queued events are flushed to the EventSink. Sessions that are still unpaid are saved to their InvoiceStore as UNPAID,
+// to be picked up by ResumePending, and their Wait returns ErrClosed. Calling Close again is a no-op.
+// Session baru dan panggilan gateway ditolak dengan ErrClosed. Watcher sesi menyelesaikan
+// pengecekan yang sedang berjalan, sehingga pembayaran yang ditemukan tetap dicatat sebagai PAID,
+// deteksi duplikat dihentikan, dan event dalam antrean dikirim ke EventSink. Sesi yang belum dibayar disimpan ke InvoiceStore-nya sebagai
+// UNPAID, untuk dilanjutkan oleh ResumePending, dan Wait-nya mengembalikan ErrClosed.
+// Memanggil Close lagi tidak berpengaruh.
+func (q *QRIS) Close(ctx context.Context) error {
+	q.life.mu.Lock()
+	if q.life.closed {
+		q.life.mu.Unlock()
+		return nil
+	}
+	q.life.closed = true
+	q.life.mu.Unlock()
+	q.life.cancel()
+
+	sessions := q.sessions.all()
+	for _, s := range sessions {
+		s.stopWatcher()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		q.life.wg.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// Sessions paid while draining are already finished, so this only ends unpaid ones
+	for _, s := range sessions {
+		s.finish("", s.unpaidStatus(StatusUnpaid), ErrClosed)
+	}
+
+	// Deliver the events queued so far to the sink and subscribers
+	if ferr := q.events.close(ctx); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// Close closes the QRIS instances of all profiles (see QRIS.Close).
+// Close menutup instance QRIS dari semua profil (lihat QRIS.Close).
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.RLock()
+	instances := make([]*QRIS, 0, len(m.profiles))
+	for _, q := range m.profiles {
+		instances = append(instances, q)
+	}
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, q := range instances {
+		if err := q.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
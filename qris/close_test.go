@@ -0,0 +1,201 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// qrisPackage prefixes the stack frames of functions in package qris; test and qristest frames
+// are excluded by the trailing dot.
+const qrisPackage = "github.com/AutoFTbot/OrderKuota-go/qris."
+
+// qrisGoroutines returns the stacks of the goroutines other than the caller that are running
+// code in package qris, keyed by goroutine ID.
+func qrisGoroutines() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[string]string)
+	// The first stack is the calling goroutine
+	for _, stack := range strings.Split(string(buf), "\n\n")[1:] {
+		if !strings.Contains(stack, qrisPackage) {
+			continue
+		}
+		id, _, _ := strings.Cut(strings.TrimPrefix(stack, "goroutine "), " ")
+		stacks[id] = stack
+	}
+	return stacks
+}
+
+// checkGoroutines fails t if goroutines running package qris code that were not in before are
+// still running after a grace period.
+func checkGoroutines(t *testing.T, before map[string]string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var leaked []string
+		for id, stack := range qrisGoroutines() {
+			if _, ok := before[id]; !ok {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running after Close:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseLeaksNoGoroutines(t *testing.T) {
+	before := qrisGoroutines()
+
+	func() {
+		gw := qristest.NewServer()
+		defer gw.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// An invoice left pending by an earlier process, to be resumed
+		store, err := qris.NewJSONFileStore(filepath.Join(t.TempDir(), "invoices.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		earlier, err := qris.NewQRIS(qris.QRISConfig{BaseQrString: testBaseQR, AuthToken: "token", AuthUsername: "user", GatewayURL: gw.URL, InvoiceStore: store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := earlier.CreatePayment(ctx, 10000, qris.WithTransactionID("OLD-1")); err != nil {
+			t.Fatal(err)
+		}
+		if err := earlier.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		q, err := qris.NewQRIS(qris.QRISConfig{
+			BaseQrString: testBaseQR,
+			AuthToken:    "token",
+			AuthUsername: "user",
+			GatewayURL:   gw.URL,
+			InvoiceStore: store,
+			PollSchedule: qris.FixedSchedule(testPoll),
+			RandSource:   qristest.SeededRand(1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events := q.Events()
+		eventsDone := make(chan struct{})
+		go func() {
+			defer close(eventsDone)
+			for range events {
+			}
+		}()
+
+		resumed, err := q.ResumePending(ctx, store)
+		if err != nil || len(resumed) != 1 {
+			t.Fatalf("ResumePending = %d sessions, %v", len(resumed), err)
+		}
+		waiting, err := q.CreatePayment(ctx, 20000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		poller, err := q.NewPoller(ctx, qris.PollerConfig{Interval: testPoll})
+		if err != nil {
+			t.Fatal(err)
+		}
+		polled, err := q.CreatePayment(ctx, 30000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := poller.Register(polled); err != nil {
+			t.Fatal(err)
+		}
+
+		waits := make(chan error, 3)
+		for _, s := range append(resumed, waiting, polled) {
+			go func(s *qris.PaymentSession) {
+				_, err := s.Wait(context.Background())
+				waits <- err
+			}(s)
+		}
+		// Let the watchers poll a few times
+		time.Sleep(5 * testPoll)
+
+		if err := q.Close(ctx); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			if err := <-waits; !errors.Is(err, qris.ErrClosed) {
+				t.Errorf("Wait after Close = %v, want ErrClosed", err)
+			}
+		}
+		<-eventsDone
+	}()
+
+	checkGoroutines(t, before)
+}
+
+func TestMethodsAfterClose(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	ctx := testContext(t, 5*time.Second)
+	if err := q.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := q.Close(ctx); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"CreatePayment", func() error { _, err := q.CreatePayment(ctx, 10000); return err }},
+		{"GenerateQRCode", func() error {
+			_, err := q.GenerateQRCode(qris.QRISData{Amount: 10000, TransactionID: "INV-1"})
+			return err
+		}},
+		{"CheckPaymentStatus", func() error { _, err := q.CheckPaymentStatus("INV-1", 10000); return err }},
+		{"WaitForPayment", func() error { _, err := q.WaitForPayment(ctx, "INV-1", 10000); return err }},
+		{"NewPoller", func() error { _, err := q.NewPoller(ctx, qris.PollerConfig{}); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, qris.ErrClosed) {
+				t.Fatalf("err = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestCloseDeadline(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// An expired context still closes the instance
+	if err := q.Close(ctx); err != nil && !strings.Contains(err.Error(), "context canceled") {
+		t.Fatalf("Close = %v", err)
+	}
+	if _, err := q.CreatePayment(context.Background(), 10000); !errors.Is(err, qris.ErrClosed) {
+		t.Fatalf("CreatePayment after Close = %v, want ErrClosed", err)
+	}
+}
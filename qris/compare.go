@@ -0,0 +1,126 @@
+package qris
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagChange is one difference found by CompareQRIS.
+// TagChange adalah satu perbedaan yang ditemukan CompareQRIS.
+type TagChange struct {
+	Path string // Tag path such as "54" or "62.05" / Path tag seperti "54" atau "62.05"
+	Name string // EMV name of the tag / Nama EMV dari tag
+	Old  string // Value in a, empty if added / Nilai di a, kosong jika ditambahkan
+	New  string // Value in b, empty if removed / Nilai di b, kosong jika dihapus
+}
+
+// QRISDiff lists the semantic differences between two QRIS payloads.
+// QRISDiff berisi perbedaan semantik antara dua payload QRIS.
+type QRISDiff struct {
+	Added   []TagChange // Tags only in b / Tag yang hanya ada di b
+	Removed []TagChange // Tags only in a / Tag yang hanya ada di a
+	Changed []TagChange // Tags whose value differs / Tag yang nilainya berbeda
+}
+
+// Equal reports whether the payloads have no semantic differences.
+// Equal melaporkan apakah kedua payload tidak memiliki perbedaan semantik.
+func (d *QRISDiff) Equal() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String renders the differences one per line, suitable for test failure messages.
+// String menampilkan perbedaan satu per baris, cocok untuk pesan kegagalan tes.
+func (d *QRISDiff) String() string {
+	if d.Equal() {
+		return "no differences / tidak ada perbedaan"
+	}
+
+	var b strings.Builder
+	for _, c := range d.Removed {
+		fmt.Fprintf(&b, "- %s %s: %q\n", c.Path, c.Name, c.Old)
+	}
+	for _, c := range d.Added {
+		fmt.Fprintf(&b, "+ %s %s: %q\n", c.Path, c.Name, c.New)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %s %s: %q -> %q\n", c.Path, c.Name, c.Old, c.New)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// CompareQRIS parses both payloads and returns the tags added, removed, and changed from a to b.
+// The CRC and the order of tags are ignored; template tags are compared by their sub-tags.
+// CompareQRIS mem-parse kedua payload dan mengembalikan tag yang ditambahkan, dihapus, dan diubah dari a ke b.
+// CRC dan urutan tag diabaikan; tag template dibandingkan berdasarkan sub-tag-nya.
+func CompareQRIS(a, b string) (*QRISDiff, error) {
+	pa, err := ParseQRISString(strings.TrimSpace(a))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse first payload / gagal parse payload pertama: %w", err)
+	}
+	pb, err := ParseQRISString(strings.TrimSpace(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse second payload / gagal parse payload kedua: %w", err)
+	}
+
+	va, names := flattenFields(pa.Fields)
+	vb, namesB := flattenFields(pb.Fields)
+	for path, name := range namesB {
+		names[path] = name
+	}
+
+	diff := &QRISDiff{}
+	for path, old := range va {
+		nv, ok := vb[path]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, TagChange{Path: path, Name: names[path], Old: old})
+		case nv != old:
+			diff.Changed = append(diff.Changed, TagChange{Path: path, Name: names[path], Old: old, New: nv})
+		}
+	}
+	for path, nv := range vb {
+		if _, ok := va[path]; !ok {
+			diff.Added = append(diff.Added, TagChange{Path: path, Name: names[path], New: nv})
+		}
+	}
+
+	for _, list := range [][]TagChange{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	return diff, nil
+}
+
+// flattenFields maps the path of every primitive field to its value, skipping the CRC.
+// A repeated tag gets a "#n" suffix so duplicates are compared too.
+// flattenFields memetakan path setiap field primitif ke nilainya, tanpa CRC.
+// Tag yang berulang diberi akhiran "#n" agar duplikat ikut dibandingkan.
+func flattenFields(fields []QRISField) (values, names map[string]string) {
+	values = make(map[string]string)
+	names = make(map[string]string)
+
+	var walk func(fields []QRISField, parent, prefix string)
+	walk = func(fields []QRISField, parent, prefix string) {
+		seen := make(map[string]int)
+		for _, f := range fields {
+			if parent == "" && f.Tag == "63" {
+				continue
+			}
+
+			path := prefix + f.Tag
+			seen[f.Tag]++
+			if n := seen[f.Tag]; n > 1 {
+				path = fmt.Sprintf("%s#%d", path, n)
+			}
+
+			if len(f.SubFields) > 0 {
+				walk(f.SubFields, f.Tag, path+".")
+				continue
+			}
+			values[path] = f.Value
+			names[path], _ = tagName(parent, f.Tag)
+		}
+	}
+	walk(fields, "", "")
+	return values, names
+}
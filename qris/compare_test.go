@@ -0,0 +1,118 @@
+package qris_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestCompareQRIS(t *testing.T) {
+	reordered := rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+		for i := range f {
+			if f[i].Tag == "58" {
+				f[i], f[i+1] = f[i+1], f[i]
+				break
+			}
+		}
+		return f
+	})
+	swapped := rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+		for i := range f {
+			if f[i].Tag == "26" {
+				f[i].SubFields[1].Value = "936009150000000001"
+			}
+		}
+		return f
+	})
+	withoutPostal := rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+		var out []qris.QRISField
+		for _, field := range f {
+			if field.Tag != "61" {
+				out = append(out, field)
+			}
+		}
+		return out
+	})
+
+	tests := []struct {
+		name    string
+		a, b    string
+		added   []string
+		removed []string
+		changed []string
+	}{
+		{name: "identical", a: testBaseQR, b: testBaseQR},
+		{name: "surrounding whitespace", a: testBaseQR, b: " " + testBaseQR + "\n"},
+		{name: "tag order", a: testBaseQR, b: reordered},
+		{name: "lowercase CRC", a: testDynamicQR, b: strings.TrimSuffix(testDynamicQR, "7D44") + "7d44"},
+		{name: "static to dynamic", a: testBaseQR, b: testDynamicQR, added: []string{"54"}, changed: []string{"01"}},
+		{name: "dynamic to static", a: testDynamicQR, b: testBaseQR, removed: []string{"54"}, changed: []string{"01"}},
+		{name: "merchant swapped", a: testBaseQR, b: swapped, changed: []string{"26.01"}},
+		{name: "tag removed", a: testBaseQR, b: withoutPostal, removed: []string{"61"}},
+	}
+	paths := func(changes []qris.TagChange) []string {
+		var out []string
+		for _, c := range changes {
+			out = append(out, c.Path)
+		}
+		return out
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := qris.CompareQRIS(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("CompareQRIS: %v", err)
+			}
+			if got := paths(diff.Added); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("Added = %v, want %v", got, tt.added)
+			}
+			if got := paths(diff.Removed); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("Removed = %v, want %v", got, tt.removed)
+			}
+			if got := paths(diff.Changed); !reflect.DeepEqual(got, tt.changed) {
+				t.Errorf("Changed = %v, want %v", got, tt.changed)
+			}
+			if want := tt.added == nil && tt.removed == nil && tt.changed == nil; diff.Equal() != want {
+				t.Errorf("Equal = %v, want %v\n%s", diff.Equal(), want, diff)
+			}
+		})
+	}
+}
+
+func TestQRISDiffString(t *testing.T) {
+	diff, err := qris.CompareQRIS(testBaseQR, testDynamicQR)
+	if err != nil {
+		t.Fatalf("CompareQRIS: %v", err)
+	}
+	want := `+ 54 Transaction Amount: "25000"
+~ 01 Point of Initiation Method: "11" -> "12"`
+	if got := diff.String(); got != want {
+		t.Fatalf("String =\n%s\nwant\n%s", got, want)
+	}
+
+	same, _ := qris.CompareQRIS(testBaseQR, testBaseQR)
+	if got := same.String(); got != "no differences / tidak ada perbedaan" {
+		t.Fatalf("String of equal payloads = %q", got)
+	}
+}
+
+func TestCompareQRISMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"first", "0002", testBaseQR, "first payload"},
+		{"second", testBaseQR, "0002", "second payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := qris.CompareQRIS(tt.a, tt.b)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to name the %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package qris_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+)
+
+// TestConcurrentUsage shares one instance between 50 goroutines generating QR codes, checking
+// payments, waiting for and regenerating sessions, and calling the HTTP handlers. Run it with -race.
+func TestConcurrentUsage(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	srv := httptest.NewServer(qrishttp.Handler(q))
+	t.Cleanup(srv.Close)
+	ctx := testContext(t, 20*time.Second)
+
+	get := func(path string) error {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET %s: status %d", path, resp.StatusCode)
+		}
+		return nil
+	}
+
+	scenarios := []func(i int) error{
+		// Stateless generation and configuration reads
+		func(i int) error {
+			data := qris.QRISData{Amount: int64(10000 + i), TransactionID: fmt.Sprintf("GEN-%d", i)}
+			if _, err := q.GenerateQRCode(data); err != nil {
+				return err
+			}
+			if _, err := q.GetQRISString(data); err != nil {
+				return err
+			}
+			q.UpdateCredentials("token", "user")
+			_ = q.Config()
+			return nil
+		},
+		// Amount-only checks and mutation reads
+		func(i int) error {
+			if _, err := q.CheckPaymentStatusContext(ctx, fmt.Sprintf("CHK-%d", i), 20000); err != nil {
+				return err
+			}
+			_, err := q.GetMutations(ctx, qris.MutationQuery{From: time.Now().Add(-time.Hour)})
+			return err
+		},
+		// A session polled over HTTP, then paid while it is being waited on
+		func(i int) error {
+			s, err := q.CreatePayment(ctx, 30000)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < 3; j++ {
+				if err := get("/payments/" + s.TransactionID); err != nil {
+					return err
+				}
+			}
+			gw.AddMutation(s.Amount(), time.Now())
+			status, err := s.Wait(ctx)
+			if err != nil {
+				return err
+			}
+			if status.Status != qris.StatusPaid {
+				return fmt.Errorf("session %s: status %s", s.TransactionID, status.Status)
+			}
+			return nil
+		},
+		// A session regenerated while its QR code and status are served
+		func(i int) error {
+			s, err := q.CreatePayment(ctx, 40000)
+			if err != nil {
+				return err
+			}
+			defer s.Cancel()
+			done := make(chan error, 1)
+			go func() {
+				for j := 0; j < 3; j++ {
+					if err := get("/payments/" + s.TransactionID + "/qr.png"); err != nil {
+						done <- err
+						return
+					}
+					if err := get("/payments/" + s.TransactionID); err != nil {
+						done <- err
+						return
+					}
+				}
+				done <- nil
+			}()
+			for j := 1; j <= 3; j++ {
+				if _, err := s.Regenerate(int64(40000 + j*1000)); err != nil {
+					return err
+				}
+				_ = s.Snapshot()
+			}
+			return <-done
+		},
+		// The HTTP API alone
+		func(i int) error {
+			body := fmt.Sprintf(`{"amount": 50000, "transaction_id": "HTTP-%d"}`, i)
+			resp, err := http.Post(srv.URL+"/payments", "application/json", bytes.NewBufferString(body))
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				return fmt.Errorf("POST /payments: status %d", resp.StatusCode)
+			}
+			for _, path := range []string{
+				fmt.Sprintf("/payments/HTTP-%d", i),
+				fmt.Sprintf("/payments/HTTP-%d/qr.png", i),
+				fmt.Sprintf("/qr.png?amount=%d&transaction_id=QR-%d", 50000+i, i),
+				"/mutations",
+			} {
+				if err := get(path); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := scenarios[i%len(scenarios)](i); err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package qris
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+)
+
+// Validate checks the configuration without creating a QRIS instance.
+// Validate memeriksa konfigurasi tanpa membuat instance QRIS.
+func (c QRISConfig) Validate() error {
+	return c.ValidateDetailed().Err()
+}
+
+// transport returns the connection settings of the config.
+// transport mengembalikan pengaturan koneksi dari config.
+func (c QRISConfig) transport() transport.Config {
+	return transport.Config{ProxyURL: c.ProxyURL, TLSConfig: c.TLSConfig, RootCAs: c.RootCAs}
+}
+
+// normalizeBaseQR returns the normalized form of a base QRIS string (see NormalizeQRISString).
+// normalizeBaseQR mengembalikan bentuk normal dari base QRIS string (lihat NormalizeQRISString).
+func normalizeBaseQR(base string) (string, error) {
+	normalized, err := NormalizeQRISString(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid baseQrString format / format baseQrString tidak valid: %w", err)
+	}
+	return normalized, nil
+}
+
+// checkCurrency returns an error wrapping ErrUnsupportedCurrency unless the normalized base is in rupiah.
+// checkCurrency mengembalikan error yang membungkus ErrUnsupportedCurrency kecuali base yang sudah dinormalisasi dalam rupiah.
+func checkCurrency(base string) error {
+	payload, err := ParseQRISString(base)
+	if err != nil {
+		return err
+	}
+	if c := payload.Currency(); c != CurrencyIDR {
+		return fmt.Errorf("%w: base QRIS currency is %s, want %s (IDR) / mata uang base QRIS %s, seharusnya %s (IDR)",
+			ErrUnsupportedCurrency, c, CurrencyIDR, c, CurrencyIDR)
+	}
+	return nil
+}
+
+// ConfigFromEnv reads a QRISConfig from environment variables named prefix + "_" + NAME,
+// for example QRIS_BASE_QR_STRING with prefix "QRIS". An empty prefix uses the bare names.
+// ConfigFromEnv membaca QRISConfig dari environment variable bernama prefix + "_" + NAMA,
+// misalnya QRIS_BASE_QR_STRING dengan prefix "QRIS". Prefix kosong memakai nama tanpa awalan.
+//
+// Recognized names: BASE_QR_STRING, AUTH_TOKEN, AUTH_USERNAME, GATEWAY, GATEWAY_URL, PROXY_URL, USER_AGENT, LANGUAGE,
+// MATCH_WINDOW, REQUEST_TIMEOUT, and SWEEP_INTERVAL (durations such as "10m"), MIN_AMOUNT and MAX_AMOUNT (see ParseIDR), TIMEZONE ("WIB", "WITA", "WIT", or an IANA name), QRIS_TYPES (comma-separated), and SANDBOX.
+// The returned error names the missing or malformed variable; the config is validated with Validate.
+// Nama yang dikenali: BASE_QR_STRING, AUTH_TOKEN, AUTH_USERNAME, GATEWAY, GATEWAY_URL, PROXY_URL, USER_AGENT, LANGUAGE,
+// MATCH_WINDOW, REQUEST_TIMEOUT, dan SWEEP_INTERVAL (durasi seperti "10m"), MIN_AMOUNT dan MAX_AMOUNT (lihat ParseIDR), TIMEZONE ("WIB", "WITA", "WIT", atau nama IANA), QRIS_TYPES (dipisah koma), dan SANDBOX.
+// Error yang dikembalikan menyebut variable yang hilang atau salah format; config divalidasi dengan Validate.
+func ConfigFromEnv(prefix string) (QRISConfig, error) {
+	name := func(s string) string {
+		if prefix == "" {
+			return s
+		}
+		return prefix + "_" + s
+	}
+
+	var c QRISConfig
+	c.BaseQrString = strings.TrimSpace(os.Getenv(name("BASE_QR_STRING")))
+	c.AuthToken = strings.TrimSpace(os.Getenv(name("AUTH_TOKEN")))
+	c.AuthUsername = strings.TrimSpace(os.Getenv(name("AUTH_USERNAME")))
+	c.Gateway = strings.TrimSpace(os.Getenv(name("GATEWAY")))
+	c.GatewayURL = strings.TrimSpace(os.Getenv(name("GATEWAY_URL")))
+	c.ProxyURL = strings.TrimSpace(os.Getenv(name("PROXY_URL")))
+	c.UserAgent = strings.TrimSpace(os.Getenv(name("USER_AGENT")))
+	c.Language = strings.TrimSpace(os.Getenv(name("LANGUAGE")))
+
+	if v := os.Getenv(name("SANDBOX")); v != "" {
+		sandbox, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: invalid boolean %q / boolean %q tidak valid", name("SANDBOX"), v, v)
+		}
+		c.Sandbox = sandbox
+	}
+
+	if v := os.Getenv(name("MATCH_WINDOW")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c, fmt.Errorf("%s: invalid duration %q / durasi %q tidak valid", name("MATCH_WINDOW"), v, v)
+		}
+		c.MatchWindow = d
+	}
+
+	if v := os.Getenv(name("REQUEST_TIMEOUT")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c, fmt.Errorf("%s: invalid duration %q / durasi %q tidak valid", name("REQUEST_TIMEOUT"), v, v)
+		}
+		c.RequestTimeout = d
+	}
+
+	if v := os.Getenv(name("SWEEP_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c, fmt.Errorf("%s: invalid duration %q / durasi %q tidak valid", name("SWEEP_INTERVAL"), v, v)
+		}
+		c.SweepInterval = d
+	}
+
+	for _, limit := range []struct {
+		env string
+		dst *int64
+	}{{"MIN_AMOUNT", &c.MinAmount}, {"MAX_AMOUNT", &c.MaxAmount}} {
+		if v := os.Getenv(name(limit.env)); v != "" {
+			n, err := ParseIDR(v)
+			if err != nil {
+				return c, fmt.Errorf("%s: invalid amount %q / nominal %q tidak valid", name(limit.env), v, v)
+			}
+			*limit.dst = n
+		}
+	}
+
+	if v := os.Getenv(name("TIMEZONE")); v != "" {
+		loc, err := parseTimezone(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: invalid timezone %q / zona waktu %q tidak valid", name("TIMEZONE"), v, v)
+		}
+		c.Location = loc
+	}
+
+	if v := os.Getenv(name("QRIS_TYPES")); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				c.QRISTypes = append(c.QRISTypes, t)
+			}
+		}
+	}
+
+	required := []string{"BASE_QR_STRING"}
+	if !c.Sandbox {
+		required = append(required, "AUTH_TOKEN", "AUTH_USERNAME")
+	}
+	for _, r := range required {
+		if strings.TrimSpace(os.Getenv(name(r))) == "" {
+			return c, fmt.Errorf("%s must be set / %s harus diisi", name(r), name(r))
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// parseTimezone resolves the Indonesian zone abbreviations or an IANA zone name.
+// parseTimezone mengenali singkatan zona waktu Indonesia atau nama zona IANA.
+func parseTimezone(name string) (*time.Location, error) {
+	switch strings.ToUpper(name) {
+	case "WIB":
+		return WIB, nil
+	case "WITA":
+		return time.FixedZone("WITA", 8*60*60), nil
+	case "WIT":
+		return time.FixedZone("WIT", 9*60*60), nil
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,164 @@
+package qris_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// envPrefix keeps the tests clear of QRIS_ variables of the environment.
+const envPrefix = "QRISTEST"
+
+func TestConfigFromEnv(t *testing.T) {
+	complete := map[string]string{
+		"BASE_QR_STRING": testBaseQR,
+		"AUTH_TOKEN":     "token",
+		"AUTH_USERNAME":  "user",
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		drop    []string
+		wantErr string
+		check   func(*testing.T, qris.QRISConfig)
+	}{
+		{
+			name: "required only",
+			check: func(t *testing.T, c qris.QRISConfig) {
+				if c.BaseQrString != testBaseQR || c.AuthToken != "token" || c.AuthUsername != "user" {
+					t.Fatalf("config = %+v", c)
+				}
+				if c.MatchWindow != 0 || c.Location != nil || c.Sandbox {
+					t.Fatalf("unset fields filled: %+v", c)
+				}
+			},
+		},
+		{
+			name: "optional fields",
+			env: map[string]string{
+				"GATEWAY_URL":  "https://gateway.example/api",
+				"MATCH_WINDOW": "10m",
+				"TIMEZONE":     "WITA",
+				"MIN_AMOUNT":   "Rp 1.000",
+				"MAX_AMOUNT":   "5000000",
+				"QRIS_TYPES":   "static, dynamic",
+				"AUTH_TOKEN":   "  token  ",
+			},
+			check: func(t *testing.T, c qris.QRISConfig) {
+				if c.GatewayURL != "https://gateway.example/api" || c.MatchWindow != 10*time.Minute {
+					t.Fatalf("config = %+v", c)
+				}
+				if c.Location == nil || c.Location.String() != "WITA" {
+					t.Fatalf("Location = %v, want WITA", c.Location)
+				}
+				if c.MinAmount != 1000 || c.MaxAmount != 5000000 {
+					t.Fatalf("limits = %d..%d", c.MinAmount, c.MaxAmount)
+				}
+				if len(c.QRISTypes) != 2 || c.QRISTypes[1] != "dynamic" {
+					t.Fatalf("QRISTypes = %q", c.QRISTypes)
+				}
+				if c.AuthToken != "token" {
+					t.Fatalf("AuthToken = %q, want it trimmed", c.AuthToken)
+				}
+			},
+		},
+		{
+			name: "sandbox without credentials",
+			env:  map[string]string{"SANDBOX": "true"},
+			drop: []string{"AUTH_TOKEN", "AUTH_USERNAME"},
+			check: func(t *testing.T, c qris.QRISConfig) {
+				if !c.Sandbox {
+					t.Fatal("Sandbox not set")
+				}
+			},
+		},
+		{name: "missing base", drop: []string{"BASE_QR_STRING"}, wantErr: "QRISTEST_BASE_QR_STRING must be set"},
+		{name: "missing token", drop: []string{"AUTH_TOKEN"}, wantErr: "QRISTEST_AUTH_TOKEN must be set"},
+		{name: "blank username", env: map[string]string{"AUTH_USERNAME": "   "}, wantErr: "QRISTEST_AUTH_USERNAME must be set"},
+		{name: "bad duration", env: map[string]string{"MATCH_WINDOW": "ten minutes"}, wantErr: "QRISTEST_MATCH_WINDOW: invalid duration"},
+		{name: "negative duration", env: map[string]string{"REQUEST_TIMEOUT": "-1s"}, wantErr: "QRISTEST_REQUEST_TIMEOUT: invalid duration"},
+		{name: "bad timezone", env: map[string]string{"TIMEZONE": "Mars/Olympus"}, wantErr: "QRISTEST_TIMEZONE: invalid timezone"},
+		{name: "bad amount", env: map[string]string{"MIN_AMOUNT": "lots"}, wantErr: "QRISTEST_MIN_AMOUNT: invalid amount"},
+		{name: "bad boolean", env: map[string]string{"SANDBOX": "maybe"}, wantErr: "QRISTEST_SANDBOX: invalid boolean"},
+		{name: "invalid config", env: map[string]string{"GATEWAY_URL": "ftp://gateway"}, wantErr: "gatewayURL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{}
+			for k, v := range complete {
+				env[k] = v
+			}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			for _, k := range tt.drop {
+				delete(env, k)
+			}
+			for _, k := range []string{"BASE_QR_STRING", "AUTH_TOKEN", "AUTH_USERNAME", "GATEWAY_URL", "MATCH_WINDOW",
+				"REQUEST_TIMEOUT", "TIMEZONE", "MIN_AMOUNT", "MAX_AMOUNT", "QRIS_TYPES", "SANDBOX"} {
+				t.Setenv(envPrefix+"_"+k, env[k])
+			}
+
+			c, err := qris.ConfigFromEnv(envPrefix)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConfigFromEnv: %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestConfigFromEnvNoPrefix(t *testing.T) {
+	t.Setenv("BASE_QR_STRING", testBaseQR)
+	t.Setenv("AUTH_TOKEN", "token")
+	t.Setenv("AUTH_USERNAME", "user")
+
+	c, err := qris.ConfigFromEnv("")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if c.AuthUsername != "user" {
+		t.Fatalf("AuthUsername = %q, want user", c.AuthUsername)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := qris.QRISConfig{BaseQrString: testBaseQR, AuthToken: "token", AuthUsername: "user"}
+	tests := []struct {
+		name   string
+		modify func(*qris.QRISConfig)
+		valid  bool
+		issue  string
+	}{
+		{"valid", func(*qris.QRISConfig) {}, true, ""},
+		{"sandbox without credentials", func(c *qris.QRISConfig) { c.Sandbox, c.AuthToken, c.AuthUsername = true, "", "" }, true, ""},
+		{"missing token", func(c *qris.QRISConfig) { c.AuthToken = "" }, false, qris.IssueMissingRequiredField},
+		{"unknown gateway", func(c *qris.QRISConfig) { c.Gateway = "acme" }, false, qris.IssueUnknownGateway},
+		{"bad gateway URL", func(c *qris.QRISConfig) { c.GatewayURL = "gateway.example" }, false, qris.IssueInvalidGatewayURL},
+		{"negative window", func(c *qris.QRISConfig) { c.MatchWindow = -time.Minute }, false, qris.IssueNegativeMatchWindow},
+		{"inverted limits", func(c *qris.QRISConfig) { c.MinAmount, c.MaxAmount = 5000, 1000 }, false, qris.IssueInvalidAmountRange},
+		{"unknown QRIS type", func(c *qris.QRISConfig) { c.QRISTypes = []string{"printed"} }, false, qris.IssueInvalidQRISType},
+		{"truncated base", func(c *qris.QRISConfig) { c.BaseQrString = testBaseQR[:40] }, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate()
+			if (err == nil) != tt.valid {
+				t.Fatalf("Validate = %v, want valid %v", err, tt.valid)
+			}
+			if tt.issue != "" && !c.ValidateDetailed().Has(tt.issue) {
+				t.Fatalf("ValidateDetailed lacks issue %s", tt.issue)
+			}
+		})
+	}
+}
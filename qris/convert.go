@@ -0,0 +1,31 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// ConvertStaticToDynamic turns the static QRIS of a merchant into a dynamic one asking for amount
+// rupiah, without a QRIS instance or gateway credentials. staticQR is cleaned up and verified like
+// NormalizeQRISString; the result carries the amount in tag 54, POI 12, and a new CRC. An amount
+// already present is replaced. QRIS.GenerateQRCode builds the same payload for QRISData{Amount: amount}.
+// ConvertStaticToDynamic mengubah QRIS statis milik merchant menjadi QRIS dinamis yang meminta amount
+// rupiah, tanpa instance QRIS atau kredensial gateway. staticQR dirapikan dan diverifikasi seperti
+// NormalizeQRISString; hasilnya membawa nominal di tag 54, POI 12, dan CRC baru. Nominal yang sudah ada
+// diganti. QRIS.GenerateQRCode menyusun payload yang sama untuk QRISData{Amount: amount}.
+func ConvertStaticToDynamic(staticQR string, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+	}
+	qrString, err := NormalizeQRISString(staticQR)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(qrString, "5802ID") {
+		return "", errors.New("invalid QRIS format: country ID not found / format QRIS tidak valid: ID negara tidak ditemukan")
+	}
+	return emv.SetAmount(qrString, amount)
+}
@@ -0,0 +1,71 @@
+package qris_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestConvertStaticToDynamic(t *testing.T) {
+	tests := []struct {
+		name    string
+		qr      string
+		amount  int64
+		want    string
+		wantErr error  // Matched with errors.Is
+		wantMsg string // Or contained in the error message
+	}{
+		{name: "static", qr: testBaseQR, amount: 25000, want: testDynamicQR},
+		{name: "pasted with whitespace", qr: "  " + testBaseQR + "\n", amount: 25000, want: testDynamicQR},
+		{name: "dynamic gets a new amount", qr: testDynamicQR, amount: 25000, want: testDynamicQR},
+		{name: "zero amount", qr: testBaseQR, amount: 0, wantErr: qris.ErrInvalidAmount},
+		{name: "negative amount", qr: testBaseQR, amount: -5, wantErr: qris.ErrInvalidAmount},
+		{name: "bad CRC", qr: testBaseQR[:len(testBaseQR)-4] + "0000", amount: 25000, wantErr: qris.ErrInvalidChecksum},
+		{name: "URL", qr: "https://example.com/qris.png", amount: 25000, wantMsg: "URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qris.ConvertStaticToDynamic(tt.qr, tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %q, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("got %q, %v, want error containing %q", got, err, tt.wantMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+			if err := qris.VerifyCRC(got); err != nil {
+				t.Errorf("result fails its CRC: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertStaticToDynamicMatchesGenerate(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+	for _, amount := range []int64{1, 25000, 1500000} {
+		want, err := q.BuildPayload(qris.QRISData{Amount: amount, TransactionID: "TX1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := qris.ConvertStaticToDynamic(testBaseQR, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("amount %d: got %s, want %s", amount, got, want)
+		}
+	}
+}
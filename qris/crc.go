@@ -0,0 +1,25 @@
+package qris
+
+import (
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// ErrInvalidChecksum is returned when the CRC of a payload does not match its content.
+// ErrInvalidChecksum dikembalikan jika CRC payload tidak sesuai dengan isinya.
+var ErrInvalidChecksum = emv.ErrInvalidChecksum
+
+// ChecksumCRC16 computes the CRC16-CCITT (polynomial 0x1021, initial 0xFFFF) of payload
+// as four uppercase hex digits. payload must include the trailing "6304" but not the CRC value.
+// ChecksumCRC16 menghitung CRC16-CCITT (polinomial 0x1021, awal 0xFFFF) dari payload
+// sebagai empat digit hex huruf besar. payload harus menyertakan "6304" di akhir tetapi tanpa nilai CRC.
+func ChecksumCRC16(payload string) string {
+	return emv.CRC16(payload)
+}
+
+// VerifyCRC checks the trailing "6304xxxx" CRC field of a full payload.
+// Hex digits are compared case-insensitively, since some acquirers emit lowercase.
+// VerifyCRC memeriksa field CRC "6304xxxx" di akhir payload lengkap.
+// Digit hex dibandingkan tanpa membedakan huruf besar/kecil, karena beberapa acquirer memakai huruf kecil.
+func VerifyCRC(fullPayload string) error {
+	return emv.VerifyCRC(fullPayload)
+}
@@ -0,0 +1,64 @@
+package qris_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// emvcoSample is the sample payload of the EMVCo merchant-presented QR specification, appendix A.
+const emvcoSample = "00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678520441115802CN5914BEST TRANSPORT6007BEIJING64200002ZH0104最佳运输0202北京540523.7253031565502016233030412340603***0708A60086670902ME91320016A0112233449988770708123456786304A13A"
+
+func TestChecksumCRC16(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"empty", "", "FFFF"},
+		{"single byte", "A", "B915"},
+		{"check string", "123456789", "29B1"},
+		{"EMVCo sample", strings.TrimSuffix(emvcoSample, "A13A"), "A13A"},
+		{"static QRIS", testBaseQR[:len(testBaseQR)-4], "FF47"},
+		{"dynamic QRIS", testDynamicQR[:len(testDynamicQR)-4], "7D44"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qris.ChecksumCRC16(tt.payload); got != tt.want {
+				t.Fatalf("ChecksumCRC16 = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCRC(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		valid   bool
+	}{
+		{"EMVCo sample", emvcoSample, true},
+		{"static QRIS", testBaseQR, true},
+		{"dynamic QRIS", testDynamicQR, true},
+		{"lowercase hex", strings.TrimSuffix(testDynamicQR, "7D44") + "7d44", true},
+		{"wrong checksum", strings.TrimSuffix(testBaseQR, "FF47") + "FF48", false},
+		{"changed content", strings.Replace(testDynamicQR, "25000", "35000", 1), false},
+		{"missing CRC field", testBaseQR[:len(testBaseQR)-8], false},
+		{"truncated CRC", testBaseQR[:len(testBaseQR)-1], false},
+		{"too short", "6304", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := qris.VerifyCRC(tt.payload)
+			if tt.valid && err != nil {
+				t.Fatalf("VerifyCRC: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, qris.ErrInvalidChecksum) {
+				t.Fatalf("VerifyCRC err = %v, want ErrInvalidChecksum", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package qris
+
+import "errors"
+
+// UpdateCredentials atomically replaces the gateway credentials.
+// UpdateCredentials mengganti kredensial gateway secara atomik.
+//
+// Requests already sent finish with the old credentials; later requests, including those of
+// running watchers, use the new ones. Sessions, reservations, and watchers are kept.
+// Request yang sudah terkirim selesai dengan kredensial lama; request berikutnya, termasuk dari
+// watcher yang sedang berjalan, memakai kredensial baru. Sesi, pemesanan, dan watcher tetap dipertahankan.
+//
+// Empty values are ignored so a half-filled rotation never wipes working credentials.
+// Nilai kosong diabaikan agar rotasi yang tidak lengkap tidak menghapus kredensial yang masih berlaku.
+func (q *QRIS) UpdateCredentials(token, username string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if token != "" {
+		q.config.AuthToken = token
+	}
+	if username != "" {
+		q.config.AuthUsername = username
+	}
+}
+
+// UpdateBaseQR validates and atomically replaces the base QRIS string used for new QR codes.
+// UpdateBaseQR memvalidasi dan mengganti base QRIS string untuk QR code baru secara atomik.
+func (q *QRIS) UpdateBaseQR(base string) error {
+	if base == "" {
+		return errors.New("baseQrString must be filled / baseQrString harus diisi")
+	}
+	base, err := normalizeBaseQR(base)
+	if err != nil {
+		return err
+	}
+	if !q.Config().AllowAnyCurrency {
+		if err := checkCurrency(base); err != nil {
+			return err
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.config.BaseQrString = base
+	return nil
+}
+
+// Config returns a snapshot of the current configuration.
+// Config mengembalikan salinan konfigurasi saat ini.
+func (q *QRIS) Config() QRISConfig {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.config
+}
+
+// credentials returns the current gateway credentials.
+// credentials mengembalikan kredensial gateway saat ini.
+func (q *QRIS) credentials() (token, username string) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.config.AuthToken, q.config.AuthUsername
+}
+
+// baseQR returns the current base QRIS string.
+// baseQR mengembalikan base QRIS string saat ini.
+func (q *QRIS) baseQR() string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.config.BaseQrString
+}
@@ -0,0 +1,142 @@
+package qris_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// credentialRecorder records the credentials of every gateway request.
+type credentialRecorder struct {
+	mu    sync.Mutex
+	pairs [][2]string
+}
+
+func (r *credentialRecorder) intercept(next qris.RoundTripFunc) qris.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		var params map[string]string
+		json.Unmarshal(body, &params)
+
+		r.mu.Lock()
+		r.pairs = append(r.pairs, [2]string{params["auth_token"], params["auth_username"]})
+		r.mu.Unlock()
+		return next(req)
+	}
+}
+
+func (r *credentialRecorder) last() [2]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pairs[len(r.pairs)-1]
+}
+
+func TestUpdateCredentialsConcurrent(t *testing.T) {
+	gw := newGateway(t)
+	gw.AddMutation(10001, time.Now())
+	rec := &credentialRecorder{}
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) {
+		c.AuthToken, c.AuthUsername = "token-0", "user-0"
+		c.Interceptors = []qris.Interceptor{rec.intercept}
+	})
+
+	const rotations = 50
+	stop := make(chan struct{})
+	errs := make(chan error, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				status, err := q.CheckPaymentStatus("INV-1", 10001)
+				if err != nil || status.Status != qris.StatusPaid {
+					errs <- fmt.Errorf("CheckPaymentStatus = %v, %v", status, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= rotations; i++ {
+		q.UpdateCredentials(fmt.Sprintf("token-%d", i), fmt.Sprintf("user-%d", i))
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// Token and username always change together
+	rec.mu.Lock()
+	for _, p := range rec.pairs {
+		var token, user int
+		fmt.Sscanf(p[0], "token-%d", &token)
+		fmt.Sscanf(p[1], "user-%d", &user)
+		if token != user {
+			t.Fatalf("request sent %s with %s", p[0], p[1])
+		}
+	}
+	rec.mu.Unlock()
+
+	if _, err := q.CheckPaymentStatus("INV-1", 10001); err != nil {
+		t.Fatalf("CheckPaymentStatus: %v", err)
+	}
+	if got, want := rec.last(), [2]string{fmt.Sprintf("token-%d", rotations), fmt.Sprintf("user-%d", rotations)}; got != want {
+		t.Fatalf("credentials after rotation = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateCredentialsKeepsEmpty(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+
+	q.UpdateCredentials("new-token", "")
+	c := q.Config()
+	if c.AuthToken != "new-token" || c.AuthUsername != "user" {
+		t.Fatalf("credentials = %q, %q, want new-token, user", c.AuthToken, c.AuthUsername)
+	}
+}
+
+func TestUpdateBaseQR(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		wantErr bool
+	}{
+		{"valid", testBaseQR, false},
+		{"empty", "", true},
+		{"truncated", testBaseQR[:60], true},
+		{"foreign currency", emv.AppendCRC(strings.Replace(testBaseQR[:len(testBaseQR)-8], "5303360", "5303458", 1)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQRIS(t, newGateway(t))
+			err := q.UpdateBaseQR(tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateBaseQR err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && q.Config().BaseQrString != testBaseQR {
+				t.Fatal("a rejected base QRIS replaced the old one")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// mutationSource hands the mutations matching query to fn, like eachMutation.
+// mutationSource meneruskan mutasi yang cocok dengan query ke fn, seperti eachMutation.
+type mutationSource func(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error)
+
+// creditSnapshot is the result of one shared fetch of credits.
+// creditSnapshot adalah hasil satu pengambilan kredit bersama.
+type creditSnapshot struct {
+	started time.Time // When the request was sent / Waktu request dikirim
+	from    time.Time // Start of the fetched range / Awal rentang yang diambil
+	credits []Mutation
+	page    mutationPage
+}
+
+// covers reports whether the snapshot was fetched after since and includes every credit from from on.
+// covers melaporkan apakah snapshot diambil setelah since dan mencakup semua kredit mulai from.
+func (s *creditSnapshot) covers(from, since time.Time) bool {
+	return s.started.After(since) && !s.from.After(from)
+}
+
+// creditFetch is a shared fetch in flight.
+// creditFetch adalah pengambilan bersama yang sedang berjalan.
+type creditFetch struct {
+	snap *creditSnapshot
+	err  error
+	done chan struct{}
+}
+
+// creditCache lets the pollers of an instance share their credit fetches: a poller reuses any
+// fetch started after its own previous check, so the gateway sees the rate of the fastest poller.
+// creditCache membuat poller dalam satu instance berbagi pengambilan kredit: poller memakai ulang
+// pengambilan yang dimulai setelah pengecekan sebelumnya, sehingga gateway hanya melihat laju poller tercepat.
+type creditCache struct {
+	mu       sync.Mutex
+	last     *creditSnapshot
+	inflight *creditFetch
+}
+
+// get returns a snapshot covering from that was fetched after since, fetching one if needed.
+// get mengembalikan snapshot yang mencakup from dan diambil setelah since, dan mengambilnya jika perlu.
+func (c *creditCache) get(ctx context.Context, q *QRIS, from, since time.Time) (*creditSnapshot, error) {
+	for {
+		c.mu.Lock()
+		if c.last != nil && c.last.covers(from, since) {
+			snap := c.last
+			c.mu.Unlock()
+			return snap, nil
+		}
+		if f := c.inflight; f != nil && f.snap.covers(from, since) {
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// The fetch may have failed only because its owner gave up; try again then
+			if f.err != nil && (errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+				continue
+			}
+			return f.snap, f.err
+		}
+
+		// Fetch far enough back for every session, so their next checks can share it
+		start := from
+		for _, s := range q.sessions.all() {
+			if created := s.invoice().CreatedAt; created.Before(start) {
+				start = created
+			}
+		}
+		f := &creditFetch{snap: &creditSnapshot{started: time.Now(), from: start}, done: make(chan struct{})}
+		shared := c.inflight == nil
+		if shared {
+			c.inflight = f
+		}
+		c.mu.Unlock()
+
+		f.snap.page, f.err = q.eachMutation(ctx, MutationQuery{From: start, Type: "CR"}, func(m Mutation) error {
+			f.snap.credits = append(f.snap.credits, m)
+			return nil
+		})
+
+		c.mu.Lock()
+		if f.err == nil && (c.last == nil || f.snap.started.After(c.last.started)) {
+			c.last = f.snap
+		}
+		if shared {
+			c.inflight = nil
+		}
+		c.mu.Unlock()
+		close(f.done)
+		return f.snap, f.err
+	}
+}
+
+// sharedCredits returns a mutationSource for a single poller that serves credit queries from
+// the instance's credit cache. Other queries go straight to the gateway.
+// sharedCredits mengembalikan mutationSource untuk satu poller yang melayani query kredit dari
+// cache kredit instance. Query lain langsung dikirim ke gateway.
+func (q *QRIS) sharedCredits() mutationSource {
+	var last time.Time
+	return func(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+		if query.Type != "CR" || query.From.IsZero() || !query.To.IsZero() || query.Page > 0 || query.PerPage > 0 {
+			return q.eachMutation(ctx, query, fn)
+		}
+		since := last
+		last = time.Now()
+
+		snap, err := q.credits.get(ctx, q, query.From, since)
+		if err != nil {
+			return mutationPage{}, err
+		}
+		for _, m := range snap.credits {
+			if !query.matches(m) {
+				continue
+			}
+			if err := fn(m); err != nil {
+				return snap.page, err
+			}
+		}
+		return snap.page, nil
+	}
+}
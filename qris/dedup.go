@@ -0,0 +1,152 @@
+package qris
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Bounds of the per-instance set of claimed mutations.
+// Batas set mutasi yang sudah diklaim per instance.
+const (
+	DefaultClaimTTL  = 24 * time.Hour // How long a claim is remembered / Berapa lama klaim diingat
+	DefaultMaxClaims = 10000          // Claims kept before the oldest is forgotten / Klaim yang disimpan sebelum yang terlama dilupakan
+)
+
+// MutationClaimer is implemented by invoice stores that record which invoice a mutation paid, so the
+// guarantee of one PAID event per mutation also holds across restarts and processes sharing the store.
+// MutationClaimer diimplementasikan oleh invoice store yang mencatat invoice mana yang dibayar sebuah
+// mutasi, sehingga jaminan satu event PAID per mutasi juga berlaku setelah restart dan antar proses yang
+// memakai store yang sama.
+type MutationClaimer interface {
+	// ClaimMutation records transactionID as the invoice paid by the mutation with key (see MutationKey)
+	// unless another invoice already claimed it, and returns the invoice that owns the claim.
+	// ClaimMutation mencatat transactionID sebagai invoice yang dibayar mutasi dengan key (lihat MutationKey)
+	// kecuali invoice lain sudah mengklaimnya, dan mengembalikan invoice pemilik klaim.
+	ClaimMutation(ctx context.Context, key, transactionID string) (owner string, err error)
+}
+
+// MutationKey returns the key identifying a gateway mutation: its issuer reference and date.
+// MutationKey mengembalikan kunci yang mengidentifikasi mutasi gateway: referensi issuer dan tanggalnya.
+func MutationKey(issuerRef, date string) string {
+	return issuerRef + "|" + date
+}
+
+// claimSet remembers which invoice each recent mutation paid, bounded in size and age.
+// claimSet mengingat invoice mana yang dibayar setiap mutasi terbaru, dibatasi ukuran dan umurnya.
+type claimSet struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Oldest first / Yang terlama lebih dulu
+}
+
+type claimEntry struct {
+	key   string
+	owner string
+	at    time.Time
+}
+
+func newClaimSet() *claimSet {
+	return &claimSet{entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// claim records owner for key unless an unexpired claim exists, and returns the current owner.
+// claim mencatat owner untuk key kecuali ada klaim yang belum kedaluwarsa, dan mengembalikan pemilik saat ini.
+func (c *claimSet) claim(key, owner string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.pruneLocked(now)
+	if el, ok := c.entries[key]; ok {
+		return el.Value.(*claimEntry).owner
+	}
+	c.entries[key] = c.order.PushBack(&claimEntry{key: key, owner: owner, at: now})
+	return owner
+}
+
+// set records owner for key, replacing any claim.
+// set mencatat owner untuk key, menggantikan klaim yang ada.
+func (c *claimSet) set(key, owner string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*claimEntry).owner = owner
+		return
+	}
+	c.entries[key] = c.order.PushBack(&claimEntry{key: key, owner: owner, at: time.Now()})
+}
+
+// claimedByOther reports whether key is claimed by an invoice other than owner.
+// claimedByOther melaporkan apakah key diklaim oleh invoice selain owner.
+func (c *claimSet) claimedByOther(key, owner string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	e := el.Value.(*claimEntry)
+	return time.Since(e.at) < DefaultClaimTTL && e.owner != owner
+}
+
+func (c *claimSet) pruneLocked(now time.Time) {
+	for el := c.order.Front(); el != nil; el = c.order.Front() {
+		e := el.Value.(*claimEntry)
+		if c.order.Len() <= DefaultMaxClaims && now.Sub(e.at) < DefaultClaimTTL {
+			return
+		}
+		c.order.Remove(el)
+		delete(c.entries, e.key)
+	}
+}
+
+// excludeClaimed wraps source so mutations claimed by other invoices are skipped.
+// excludeClaimed membungkus source sehingga mutasi yang diklaim invoice lain dilewati.
+func (q *QRIS) excludeClaimed(source mutationSource, transactionID string) mutationSource {
+	return func(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+		return source(ctx, query, func(m Mutation) error {
+			if m.IssuerRef != "" && q.claims.claimedByOther(MutationKey(m.IssuerRef, m.Date), transactionID) {
+				return nil
+			}
+			return fn(m)
+		})
+	}
+}
+
+// claimPayment claims the mutation of a PAID status for transactionID, in memory and in store if it
+// is a MutationClaimer, and reports whether transactionID owns it. Payments without an issuer reference
+// cannot be told apart and are always accepted.
+// claimPayment mengklaim mutasi dari status PAID untuk transactionID, di memori dan di store jika store
+// adalah MutationClaimer, dan melaporkan apakah transactionID pemiliknya. Pembayaran tanpa referensi
+// issuer tidak dapat dibedakan dan selalu diterima.
+func (q *QRIS) claimPayment(ctx context.Context, status *PaymentStatus, transactionID string, store InvoiceStore) bool {
+	if status.Reference == "" {
+		return true
+	}
+	key := MutationKey(status.Reference, status.Date)
+	if owner := q.claims.claim(key, transactionID); owner != transactionID {
+		logf(ctx, "Payment %s already claimed by %s, skipping for %s", status.Reference, owner, transactionID)
+		return false
+	}
+
+	claimer, ok := store.(MutationClaimer)
+	if !ok {
+		return true
+	}
+	owner, err := claimer.ClaimMutation(ctx, key, transactionID)
+	if err != nil {
+		// The in-memory claim still holds for this process
+		logf(ctx, "Failed to claim payment %s in store: %v", status.Reference, err)
+		return true
+	}
+	if owner != transactionID {
+		q.claims.set(key, owner)
+		logf(ctx, "Payment %s already claimed by %s, skipping for %s", status.Reference, owner, transactionID)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package qris_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// anyCredit matches every credit, so two sessions always compete for the same mutation.
+var anyCredit = qris.MatchPolicyFunc(func(inv qris.Invoice, m qris.Mutation) qris.MatchResult {
+	return qris.MatchResult{Matched: m.Type == "CR" && !m.Time.Before(inv.CreatedAt)}
+})
+
+// countPaid drains the events of sessions until they finish or d passes, and returns the PAID events per session.
+func countPaid(t *testing.T, d time.Duration, sessions ...*qris.PaymentSession) map[string]int {
+	t.Helper()
+	paid := make(map[string]int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	timeout := time.After(d)
+	stop := make(chan struct{})
+	for _, s := range sessions {
+		wg.Add(1)
+		go func(s *qris.PaymentSession) {
+			defer wg.Done()
+			events := s.Events()
+			for {
+				select {
+				case ev, ok := <-events:
+					if !ok {
+						return
+					}
+					if ev.Type == qris.EventPaid {
+						mu.Lock()
+						paid[s.TransactionID]++
+						mu.Unlock()
+					}
+				case <-stop:
+					return
+				}
+			}
+		}(s)
+	}
+	<-timeout
+	close(stop)
+	wg.Wait()
+	return paid
+}
+
+func TestOneMutationOnePaidEvent(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		gw := newGateway(t)
+		q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.MatchPolicy = anyCredit })
+		ctx := testContext(t, 5*time.Second)
+
+		a, err := q.CreatePayment(ctx, 10000, qris.WithTransactionID("A"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := q.CreatePayment(ctx, 10000, qris.WithTransactionID("B"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gw.AddMutation(a.Amount(), time.Now().Add(time.Second))
+
+		paid := countPaid(t, 20*testPoll, a, b)
+		if paid["A"]+paid["B"] != 1 {
+			t.Fatalf("run %d: PAID events %v, want exactly one across both watchers", i, paid)
+		}
+		a.Cancel()
+		b.Cancel()
+	}
+}
+
+func TestOneMutationOnePaidEventAcrossInstances(t *testing.T) {
+	gw := newGateway(t)
+	store, err := qris.NewJSONFileStore(filepath.Join(t.TempDir(), "invoices.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure := func(c *qris.QRISConfig) {
+		c.MatchPolicy = anyCredit
+		c.InvoiceStore = store
+	}
+	first := newTestQRIS(t, gw, configure)
+	second := newTestQRIS(t, gw, configure)
+	ctx := testContext(t, 5*time.Second)
+
+	a, err := first.CreatePayment(ctx, 10000, qris.WithTransactionID("A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := second.CreatePayment(ctx, 10000, qris.WithTransactionID("B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.AddMutation(10000, time.Now().Add(time.Second))
+
+	paid := countPaid(t, 20*testPoll, a, b)
+	if paid["A"]+paid["B"] != 1 {
+		t.Fatalf("PAID events %v, want exactly one across both instances", paid)
+	}
+}
@@ -0,0 +1,211 @@
+package qris
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of a Delivery.
+// Status dari sebuah Delivery.
+const (
+	DeliveryPending   = "pending"   // Not delivered yet, attempts may follow / Belum terkirim, percobaan mungkin masih berlanjut
+	DeliveryDelivered = "delivered" // The receiver answered 2xx / Penerima menjawab 2xx
+	DeliveryFailed    = "failed"    // Attempts used up, see Webhook.Redeliver / Percobaan habis, lihat Webhook.Redeliver
+)
+
+// DefaultDeliveryRetention is how long the stores of this package keep delivered deliveries.
+// Failed and pending ones are kept until delivered.
+// DefaultDeliveryRetention adalah lama store dari paket ini menyimpan pengiriman yang sudah terkirim.
+// Pengiriman yang gagal dan pending disimpan sampai terkirim.
+const DefaultDeliveryRetention = 7 * 24 * time.Hour
+
+// Delivery is one webhook event sent, or to be sent, to a URL.
+// Delivery adalah satu event webhook yang dikirim, atau akan dikirim, ke sebuah URL.
+type Delivery struct {
+	ID        string    `json:"id"`         // Event ID / ID event
+	Type      string    `json:"type"`       // Event type / Tipe event
+	URL       string    `json:"url"`        // Receiver / Penerima
+	Payload   string    `json:"payload"`    // Body, a WebhookPayload kept byte for byte so the signature holds / Body, sebuah WebhookPayload yang disimpan persis agar tanda tangannya tetap valid
+	Signature string    `json:"signature"`  // WebhookSignatureHeader value / Nilai WebhookSignatureHeader
+	Status    string    `json:"status"`     // DeliveryPending, DeliveryDelivered or DeliveryFailed / DeliveryPending, DeliveryDelivered atau DeliveryFailed
+	Attempts  int       `json:"attempts"`   // Attempts so far / Jumlah percobaan sejauh ini
+	LastError string    `json:"last_error"` // Error of the last attempt / Error dari percobaan terakhir
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeliveryStore keeps the deliveries of a Webhook.
+// DeliveryStore menyimpan pengiriman dari sebuah Webhook.
+type DeliveryStore interface {
+	// Save inserts or replaces a delivery.
+	// Save menambahkan atau mengganti pengiriman.
+	Save(ctx context.Context, d Delivery) error
+
+	// Get returns the delivery id, or an error wrapping ErrNotFound.
+	// Get mengembalikan pengiriman id, atau error yang membungkus ErrNotFound.
+	Get(ctx context.Context, id string) (Delivery, error)
+
+	// List returns the deliveries created since since, oldest first.
+	// List mengembalikan pengiriman yang dibuat sejak since, yang terlama lebih dulu.
+	List(ctx context.Context, since time.Time) ([]Delivery, error)
+}
+
+// MemoryDeliveryStore is a DeliveryStore kept in memory; deliveries are lost on restart.
+// MemoryDeliveryStore adalah DeliveryStore yang disimpan di memori; pengiriman hilang saat restart.
+type MemoryDeliveryStore struct {
+	mu         sync.Mutex
+	deliveries map[string]Delivery
+}
+
+// NewMemoryDeliveryStore creates an empty MemoryDeliveryStore.
+// NewMemoryDeliveryStore membuat MemoryDeliveryStore kosong.
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{deliveries: make(map[string]Delivery)}
+}
+
+// Save implements DeliveryStore, dropping deliveries delivered more than DefaultDeliveryRetention ago.
+// Save mengimplementasikan DeliveryStore, membuang pengiriman yang terkirim lebih dari DefaultDeliveryRetention lalu.
+func (s *MemoryDeliveryStore) Save(ctx context.Context, d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveLocked(d)
+	return nil
+}
+
+func (s *MemoryDeliveryStore) saveLocked(d Delivery) {
+	s.deliveries[d.ID] = d
+	cutoff := time.Now().Add(-DefaultDeliveryRetention)
+	for id, d := range s.deliveries {
+		if d.Status == DeliveryDelivered && d.UpdatedAt.Before(cutoff) {
+			delete(s.deliveries, id)
+		}
+	}
+}
+
+// Get implements DeliveryStore.
+// Get mengimplementasikan DeliveryStore.
+func (s *MemoryDeliveryStore) Get(ctx context.Context, id string) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return Delivery{}, fmt.Errorf("%w: webhook delivery %s", ErrNotFound, id)
+	}
+	return d, nil
+}
+
+// List implements DeliveryStore.
+// List mengimplementasikan DeliveryStore.
+func (s *MemoryDeliveryStore) List(ctx context.Context, since time.Time) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.listLocked(since), nil
+}
+
+func (s *MemoryDeliveryStore) listLocked(since time.Time) []Delivery {
+	var list []Delivery
+	for _, d := range s.deliveries {
+		if !d.CreatedAt.Before(since) {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// JSONDeliveryStore is a DeliveryStore that keeps all deliveries in a single JSON file, rewritten
+// atomically on every change, so failed deliveries survive a restart and the qris command can
+// replay them.
+// JSONDeliveryStore adalah DeliveryStore yang menyimpan semua pengiriman dalam satu file JSON, yang
+// ditulis ulang secara atomik pada setiap perubahan, sehingga pengiriman yang gagal bertahan saat
+// restart dan dapat diulang oleh perintah qris.
+type JSONDeliveryStore struct {
+	path string
+	mem  *MemoryDeliveryStore
+}
+
+// NewJSONDeliveryStore opens the store at path, loading existing deliveries if the file exists.
+// NewJSONDeliveryStore membuka store di path, memuat pengiriman yang ada jika file sudah ada.
+func NewJSONDeliveryStore(path string) (*JSONDeliveryStore, error) {
+	s := &JSONDeliveryStore{path: path, mem: NewMemoryDeliveryStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery store / gagal membaca delivery store: %v", err)
+	}
+	var deliveries []Delivery
+	if err := json.Unmarshal(data, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to parse delivery store / gagal parse delivery store: %v", err)
+	}
+	for _, d := range deliveries {
+		s.mem.deliveries[d.ID] = d
+	}
+	return s, nil
+}
+
+// Save implements DeliveryStore, dropping deliveries delivered more than DefaultDeliveryRetention ago.
+// Save mengimplementasikan DeliveryStore, membuang pengiriman yang terkirim lebih dari DefaultDeliveryRetention lalu.
+func (s *JSONDeliveryStore) Save(ctx context.Context, d Delivery) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.saveLocked(d)
+	return s.flushLocked()
+}
+
+// Get implements DeliveryStore.
+// Get mengimplementasikan DeliveryStore.
+func (s *JSONDeliveryStore) Get(ctx context.Context, id string) (Delivery, error) {
+	return s.mem.Get(ctx, id)
+}
+
+// List implements DeliveryStore.
+// List mengimplementasikan DeliveryStore.
+func (s *JSONDeliveryStore) List(ctx context.Context, since time.Time) ([]Delivery, error) {
+	return s.mem.List(ctx, since)
+}
+
+// flushLocked writes all deliveries to a temporary file and renames it over the store file.
+// flushLocked menulis semua pengiriman ke file sementara lalu mengganti file store dengannya.
+func (s *JSONDeliveryStore) flushLocked() error {
+	data, err := json.MarshalIndent(s.mem.listLocked(time.Time{}), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deliveries / gagal marshal pengiriman: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write delivery store / gagal menulis delivery store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write delivery store / gagal menulis delivery store: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write delivery store / gagal menulis delivery store: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write delivery store / gagal menulis delivery store: %v", err)
+	}
+	return nil
+}
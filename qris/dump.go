@@ -0,0 +1,181 @@
+package qris
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// emvNames are the EMVCo names of the top-level tags of a merchant-presented QR code.
+// emvNames adalah nama EMVCo dari tag tingkat atas QR code yang ditampilkan merchant.
+var emvNames = map[string]string{
+	"00": "Payload Format Indicator",
+	"01": "Point of Initiation Method",
+	"52": "Merchant Category Code",
+	"53": "Transaction Currency",
+	"54": "Transaction Amount",
+	"55": "Tip or Convenience Indicator",
+	"56": "Value of Convenience Fee Fixed",
+	"57": "Value of Convenience Fee Percentage",
+	"58": "Country Code",
+	"59": "Merchant Name",
+	"60": "Merchant City",
+	"61": "Postal Code",
+	"62": "Additional Data Field Template",
+	"63": "CRC",
+	"64": "Merchant Information - Language Template",
+}
+
+// emvSubNames are the EMVCo names of nested tags, by template tag.
+// emvSubNames adalah nama EMVCo dari tag bersarang, berdasarkan tag template.
+var emvSubNames = map[string]map[string]string{
+	"merchant": {
+		"00": "Globally Unique Identifier",
+		"01": "Merchant PAN",
+		"02": "Merchant ID",
+		"03": "Merchant Criteria",
+	},
+	"62": {
+		"01": "Bill Number",
+		"02": "Mobile Number",
+		"03": "Store Label",
+		"04": "Loyalty Number",
+		"05": "Reference Label",
+		"06": "Customer Label",
+		"07": "Terminal Label",
+		"08": "Purpose of Transaction",
+		"09": "Additional Consumer Data Request",
+	},
+	"64": {
+		"00": "Language Preference",
+		"01": "Merchant Name - Alternate Language",
+		"02": "Merchant City - Alternate Language",
+	},
+}
+
+// emvLengths are the fixed lengths required by EMVCo for some top-level tags.
+// emvLengths adalah panjang tetap yang diwajibkan EMVCo untuk beberapa tag tingkat atas.
+var emvLengths = map[string]int{
+	"00": 2,
+	"01": 2,
+	"52": 4,
+	"53": 3,
+	"58": 2,
+	"63": 4,
+}
+
+// tagName returns the EMVCo name of tag nested in parent ("" for top-level tags).
+// tagName mengembalikan nama EMVCo dari tag yang berada di dalam parent ("" untuk tag tingkat atas).
+func tagName(parent, tag string) (string, bool) {
+	n, _ := strconv.Atoi(tag)
+	if parent == "" {
+		switch {
+		case n >= 2 && n <= 51:
+			return "Merchant Account Information", true
+		case n >= 80 && n <= 99:
+			return "Unreserved Template", true
+		}
+		name, ok := emvNames[tag]
+		return name, ok
+	}
+
+	p, _ := strconv.Atoi(parent)
+	switch {
+	case p >= 26 && p <= 51:
+		parent = "merchant"
+	case p >= 80 && p <= 99:
+		if tag == "00" {
+			return "Globally Unique Identifier", true
+		}
+		return "Context Specific Data", true
+	case parent == "62" && n >= 50:
+		return "Payment System Specific", true
+	}
+	name, ok := emvSubNames[parent][tag]
+	return name, ok
+}
+
+// Dump writes a human-readable TLV tree of the payload to w: tags, EMV names, lengths,
+// values, and nested sub-tags, followed by the CRC validity. Anomalies such as duplicate
+// tags, unknown tags, and length mismatches are flagged inline with "!".
+// Dump menulis pohon TLV payload yang mudah dibaca ke w: tag, nama EMV, panjang,
+// nilai, dan sub-tag bersarang, diikuti validitas CRC. Anomali seperti tag duplikat,
+// tag tidak dikenal, dan panjang yang tidak sesuai ditandai langsung dengan "!".
+func (p *QRISPayload) Dump(w io.Writer) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	dumpFields(tw, p.Fields, "", "")
+	tw.Flush()
+
+	// Empty trailing columns leave padding behind
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "CRC: %s\n", p.crcStatus())
+}
+
+// DumpQRISString parses s and writes its TLV tree to w (see QRISPayload.Dump).
+// DumpQRISString mem-parse s dan menulis pohon TLV-nya ke w (lihat QRISPayload.Dump).
+func DumpQRISString(w io.Writer, s string) error {
+	p, err := ParseQRISString(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	p.Dump(w)
+	return nil
+}
+
+func dumpFields(w io.Writer, fields []QRISField, parent, indent string) {
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		var anomalies []string
+		if seen[f.Tag] {
+			anomalies = append(anomalies, "duplicate tag / tag duplikat")
+		}
+		seen[f.Tag] = true
+
+		name, known := tagName(parent, f.Tag)
+		if !known {
+			name = "?"
+			anomalies = append(anomalies, "unknown tag / tag tidak dikenal")
+		}
+		if want, ok := emvLengths[f.Tag]; ok && parent == "" && f.Length != want {
+			anomalies = append(anomalies, fmt.Sprintf("length mismatch, want %d / panjang tidak sesuai, seharusnya %d", want, want))
+		}
+
+		value := f.Value
+		if len(f.SubFields) > 0 {
+			value = ""
+		}
+		note := ""
+		if len(anomalies) > 0 {
+			note = "! " + strings.Join(anomalies, "; ")
+		}
+		fmt.Fprintf(w, "%s%s\t%02d\t%s\t%s\t%s\n", indent, f.Tag, f.Length, name, value, note)
+
+		if len(f.SubFields) > 0 {
+			dumpFields(w, f.SubFields, f.Tag, indent+"  ")
+		}
+	}
+}
+
+// crcStatus describes whether the CRC field matches the rest of the payload.
+// crcStatus menjelaskan apakah field CRC sesuai dengan isi payload lainnya.
+func (p *QRISPayload) crcStatus() string {
+	if len(p.Fields) == 0 || p.Fields[len(p.Fields)-1].Tag != "63" {
+		return "missing, tag 63 is not the last field / tidak ada, tag 63 bukan field terakhir"
+	}
+
+	crc := p.Fields[len(p.Fields)-1]
+	want := ChecksumCRC16(emv.EncodeFields(p.Fields[:len(p.Fields)-1]) + emv.CRCTag)
+	if !strings.EqualFold(crc.Value, want) {
+		return fmt.Sprintf("invalid, got %s, want %s / tidak valid, didapat %s, seharusnya %s", crc.Value, want, crc.Value, want)
+	}
+	return fmt.Sprintf("valid (%s) / valid (%s)", crc.Value, crc.Value)
+}
@@ -0,0 +1,39 @@
+package qris_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestDumpGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		golden  string
+	}{
+		{"static", testBaseQR, "dump_static.txt"},
+		{"dynamic", "00020101021226600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605405100015802ID5915AutoFTbot Store6012Kota Jakarta61051234562150811Order #12346304D5F3", "dump_dynamic.txt"},
+		{"anomalies", "00020101021253033605803IDN5904Toko5904Toko7002ab62090505INV-16304ABCD", "dump_anomalies.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := qris.DumpQRISString(&buf, tt.payload+"\n"); err != nil {
+				t.Fatalf("DumpQRISString: %v", err)
+			}
+			checkGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestDumpQRISStringMalformed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := qris.DumpQRISString(&buf, "000201010"); err == nil {
+		t.Fatal("DumpQRISString accepted a truncated payload")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("DumpQRISString wrote %q for a malformed payload", buf.String())
+	}
+}
@@ -0,0 +1,72 @@
+package emv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidChecksum is returned when the CRC of a payload does not match its content.
+// ErrInvalidChecksum dikembalikan jika CRC payload tidak sesuai dengan isinya.
+var ErrInvalidChecksum = errors.New("invalid checksum / checksum tidak valid")
+
+// CRCTag is the tag and length of the CRC field that ends every QRIS payload.
+// CRCTag adalah tag dan panjang field CRC yang mengakhiri setiap payload QRIS.
+const CRCTag = "6304"
+
+// CRC16 computes the CRC16-CCITT (polynomial 0x1021, initial 0xFFFF) of payload
+// as four uppercase hex digits. payload must include the trailing "6304" but not the CRC value.
+// CRC16 menghitung CRC16-CCITT (polinomial 0x1021, awal 0xFFFF) dari payload
+// sebagai empat digit hex huruf besar. payload harus menyertakan "6304" di akhir tetapi tanpa nilai CRC.
+func CRC16(payload string) string {
+	var crc uint16 = 0xFFFF
+	for i := 0; i < len(payload); i++ {
+		crc ^= uint16(payload[i]) << 8
+		for j := 0; j < 8; j++ {
+			if (crc & 0x8000) != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc = crc << 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+// AppendCRC appends the CRC field to body, a payload without it.
+// AppendCRC menambahkan field CRC ke body, payload tanpa field tersebut.
+func AppendCRC(body string) string {
+	return body + CRCTag + CRC16(body+CRCTag)
+}
+
+// VerifyCRC checks the trailing "6304xxxx" CRC field of a full payload.
+// Hex digits are compared case-insensitively, since some acquirers emit lowercase.
+// VerifyCRC memeriksa field CRC "6304xxxx" di akhir payload lengkap.
+// Digit hex dibandingkan tanpa membedakan huruf besar/kecil, karena beberapa acquirer memakai huruf kecil.
+func VerifyCRC(fullPayload string) error {
+	if !hasCRCField(fullPayload) {
+		return fmt.Errorf("%w: CRC field 6304 not found at the end / field CRC 6304 tidak ditemukan di akhir", ErrInvalidChecksum)
+	}
+
+	got := fullPayload[len(fullPayload)-4:]
+	want := CRC16(fullPayload[:len(fullPayload)-4])
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: got %s, want %s / didapat %s, seharusnya %s", ErrInvalidChecksum, got, want, got, want)
+	}
+	return nil
+}
+
+// hasCRCField reports whether s ends with a "6304xxxx" field.
+// hasCRCField melaporkan apakah s diakhiri field "6304xxxx".
+func hasCRCField(s string) bool {
+	return len(s) >= 8 && s[len(s)-8:len(s)-4] == CRCTag
+}
+
+// stripCRC returns s without its CRC field. The old CRC is not verified, since it is replaced.
+// stripCRC mengembalikan s tanpa field CRC-nya. CRC lama tidak diverifikasi, karena akan diganti.
+func stripCRC(s string) (string, error) {
+	if !hasCRCField(s) {
+		return "", fmt.Errorf("%w: CRC field 6304 not found at the end / field CRC 6304 tidak ditemukan di akhir", ErrInvalidChecksum)
+	}
+	return s[:len(s)-8], nil
+}
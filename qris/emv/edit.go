@@ -0,0 +1,199 @@
+package emv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MaxAdditionalDataLength is the longest value of a tag 62 sub-field, such as the bill number.
+// MaxAdditionalDataLength adalah nilai terpanjang dari sub-field tag 62, seperti nomor tagihan.
+const MaxAdditionalDataLength = 25
+
+// The point of initiation method (tag 01) of static and dynamic QR codes.
+// Metode point of initiation (tag 01) dari QR code statis dan dinamis.
+const (
+	POIStatic  = "11"
+	POIDynamic = "12"
+)
+
+// SetAmount sets the transaction amount (tag 54) of the full payload s and makes it a dynamic QR
+// code (POI 12). A new tag 54 is placed before the first higher tag. The old CRC is replaced, not verified.
+// SetAmount mengatur nominal transaksi (tag 54) dari payload lengkap s dan menjadikannya QR code
+// dinamis (POI 12). Tag 54 baru ditempatkan sebelum tag pertama yang lebih tinggi. CRC lama diganti, tidak diverifikasi.
+func SetAmount(s string, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("amount must be positive, got %d / nominal harus positif, didapat %d", amount, amount)
+	}
+	return edit(s, func(fields []QRISField) ([]QRISField, error) {
+		fields = setField(fields, "54", strconv.FormatInt(amount, 10))
+		for i := range fields {
+			if fields[i].Tag == "01" {
+				fields[i].Value = POIDynamic
+			}
+		}
+		return fields, nil
+	})
+}
+
+// SetOpenAmount removes the transaction amount (tag 54) of the full payload s so the customer types
+// it, and makes it a static QR code (POI 11). The old CRC is replaced, not verified.
+// SetOpenAmount menghapus nominal transaksi (tag 54) dari payload lengkap s sehingga customer
+// mengetiknya, dan menjadikannya QR code statis (POI 11). CRC lama diganti, tidak diverifikasi.
+func SetOpenAmount(s string) (string, error) {
+	return edit(s, func(fields []QRISField) ([]QRISField, error) {
+		kept := fields[:0]
+		for _, f := range fields {
+			switch f.Tag {
+			case "54":
+				continue
+			case "01":
+				f.Value = POIStatic
+			}
+			kept = append(kept, f)
+		}
+		return kept, nil
+	})
+}
+
+// SetBillNumber sets the bill number (tag 62, sub-tag 01) of the full payload s. ref holds
+// 1 to MaxAdditionalDataLength characters. The old CRC is replaced, not verified.
+// SetBillNumber mengatur nomor tagihan (tag 62, sub-tag 01) dari payload lengkap s. ref berisi
+// 1 sampai MaxAdditionalDataLength karakter. CRC lama diganti, tidak diverifikasi.
+func SetBillNumber(s, ref string) (string, error) {
+	if ref == "" || len(ref) > MaxAdditionalDataLength {
+		return "", fmt.Errorf("bill number must be 1-%d characters / nomor tagihan harus 1-%d karakter", MaxAdditionalDataLength, MaxAdditionalDataLength)
+	}
+	return SetAdditionalData(s, "01", ref)
+}
+
+// SetPurpose sets the purpose of transaction (tag 62, sub-tag 08) of the full payload s, which some
+// issuer apps show to the payer. purpose holds 1 to MaxAdditionalDataLength characters. The old CRC
+// is replaced, not verified.
+// SetPurpose mengatur tujuan transaksi (tag 62, sub-tag 08) dari payload lengkap s, yang ditampilkan
+// sebagian aplikasi issuer ke pembayar. purpose berisi 1 sampai MaxAdditionalDataLength karakter. CRC
+// lama diganti, tidak diverifikasi.
+func SetPurpose(s, purpose string) (string, error) {
+	if purpose == "" || len(purpose) > MaxAdditionalDataLength {
+		return "", fmt.Errorf("purpose must be 1-%d characters / tujuan transaksi harus 1-%d karakter", MaxAdditionalDataLength, MaxAdditionalDataLength)
+	}
+	return SetAdditionalData(s, "08", purpose)
+}
+
+// SetAdditionalData sets a sub-field of tag 62 (additional data) of the full payload s, adding the
+// template if s has none. The old CRC is replaced, not verified.
+// SetAdditionalData mengatur sub-field tag 62 (data tambahan) dari payload lengkap s, dan menambahkan
+// template jika s belum memilikinya. CRC lama diganti, tidak diverifikasi.
+func SetAdditionalData(s, subTag, value string) (string, error) {
+	return edit(s, func(fields []QRISField) ([]QRISField, error) {
+		idx := -1
+		for i, f := range fields {
+			if f.Tag == "62" {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			fields = append(fields, QRISField{Tag: "62"})
+			idx = len(fields) - 1
+		} else if fields[idx].Value != "" {
+			sub, err := ParseFields(fields[idx].Value, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template tag 62 / tag template 62 tidak valid: %v", err)
+			}
+			fields[idx].SubFields = sub
+		}
+
+		replaced := false
+		for i := range fields[idx].SubFields {
+			if fields[idx].SubFields[i].Tag == subTag {
+				fields[idx].SubFields[i].Value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields[idx].SubFields = append(fields[idx].SubFields, QRISField{Tag: subTag, Value: value})
+		}
+		return fields, nil
+	})
+}
+
+// Normalize re-encodes the full payload s with its tags, and the sub-tags of its templates, in
+// ascending order, drops zero-length fields and templates left empty, and appends a fresh uppercase
+// CRC. The old CRC is replaced, not verified.
+// Normalize meng-encode ulang payload lengkap s dengan tag, dan sub-tag dari template-nya, berurutan
+// naik, membuang field dengan panjang nol dan template yang menjadi kosong, lalu menambahkan CRC baru
+// berhuruf besar. CRC lama diganti, tidak diverifikasi.
+func Normalize(s string) (string, error) {
+	return edit(s, func(fields []QRISField) ([]QRISField, error) {
+		for i := range fields {
+			if !IsTemplateTag(fields[i].Tag) || fields[i].Value == "" {
+				continue
+			}
+			sub, err := ParseFields(fields[i].Value, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template tag %s / tag template %s tidak valid: %v", fields[i].Tag, fields[i].Tag, err)
+			}
+			if sub = sortFields(sub); len(sub) == 0 {
+				// Only empty sub-fields: drop the template below
+				fields[i].Value = ""
+			}
+			fields[i].SubFields = sub
+		}
+		return sortFields(fields), nil
+	})
+}
+
+// sortFields drops the zero-length fields and stably sorts the rest by tag. Tags are two digits,
+// so comparing them as strings orders them numerically.
+// sortFields membuang field dengan panjang nol dan mengurutkan sisanya berdasarkan tag secara stabil.
+// Tag terdiri dari dua digit, sehingga membandingkannya sebagai string mengurutkannya secara numerik.
+func sortFields(fields []QRISField) []QRISField {
+	kept := fields[:0]
+	for _, f := range fields {
+		if f.Value != "" || len(f.SubFields) > 0 {
+			kept = append(kept, f)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Tag < kept[j].Tag })
+	return kept
+}
+
+// edit applies fn to the top-level fields of the full payload s and re-encodes them with a fresh CRC.
+// edit menerapkan fn pada field tingkat atas dari payload lengkap s lalu meng-encode ulang dengan CRC baru.
+func edit(s string, fn func([]QRISField) ([]QRISField, error)) (string, error) {
+	body, err := stripCRC(s)
+	if err != nil {
+		return "", err
+	}
+	fields, err := ParseFields(body, 0)
+	if err != nil {
+		return "", err
+	}
+	if fields, err = fn(fields); err != nil {
+		return "", err
+	}
+	return Build(QRISPayload{Fields: fields})
+}
+
+// setField replaces the value of tag, or inserts it before the first higher top-level tag.
+// setField mengganti nilai tag, atau menyisipkannya sebelum tag tingkat atas pertama yang lebih tinggi.
+func setField(fields []QRISField, tag, value string) []QRISField {
+	for i := range fields {
+		if fields[i].Tag == tag {
+			fields[i].Value = value
+			return fields
+		}
+	}
+	at := len(fields)
+	for i, f := range fields {
+		if f.Tag > tag {
+			at = i
+			break
+		}
+	}
+	fields = append(fields, QRISField{})
+	copy(fields[at+1:], fields[at:])
+	fields[at] = QRISField{Tag: tag, Value: value}
+	return fields
+}
@@ -0,0 +1,229 @@
+package emv_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// body returns the payload s without its CRC field, after checking the CRC.
+func body(t *testing.T, s string) string {
+	t.Helper()
+	if err := emv.VerifyCRC(s); err != nil {
+		t.Fatalf("%q: %v", s, err)
+	}
+	if crc := s[len(s)-4:]; crc != strings.ToUpper(crc) {
+		t.Fatalf("%q: CRC is not uppercase", s)
+	}
+	return s[:len(s)-8]
+}
+
+func TestSetAmount(t *testing.T) {
+	static := emv.AppendCRC(testPayload)
+	tests := []struct {
+		name   string
+		in     string
+		amount int64
+		want   string
+	}{
+		{
+			name:   "static",
+			in:     static,
+			amount: 25000,
+			want:   strings.Replace(strings.Replace(testPayload, "010211", "010212", 1), "5802ID", "540525000"+"5802ID", 1),
+		},
+		{
+			name:   "replace the amount",
+			in:     emv.AppendCRC("000201010212" + "540510000" + "5802ID"),
+			amount: 7,
+			want:   "000201010212" + "54017" + "5802ID",
+		},
+		{
+			name:   "no higher tag",
+			in:     emv.AppendCRC("000201010211"),
+			amount: 1000,
+			want:   "000201010212" + "54041000",
+		},
+		{
+			name:   "old CRC not verified",
+			in:     "000201010211" + emv.CRCTag + "0000",
+			amount: 1000,
+			want:   "000201010212" + "54041000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := emv.SetAmount(tt.in, tt.amount)
+			if err != nil {
+				t.Fatalf("SetAmount: %v", err)
+			}
+			if b := body(t, got); b != tt.want {
+				t.Fatalf("SetAmount = %q, want %q", b, tt.want)
+			}
+		})
+	}
+
+	for _, amount := range []int64{0, -1} {
+		if _, err := emv.SetAmount(static, amount); err == nil {
+			t.Errorf("SetAmount(%d) succeeded", amount)
+		}
+	}
+	if _, err := emv.SetAmount(testPayload, 1000); !errors.Is(err, emv.ErrInvalidChecksum) {
+		t.Errorf("SetAmount without a CRC field = %v, want ErrInvalidChecksum", err)
+	}
+}
+
+func TestSetOpenAmount(t *testing.T) {
+	dynamic, err := emv.SetAmount(emv.AppendCRC(testPayload), 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := emv.SetOpenAmount(dynamic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != emv.AppendCRC(testPayload) {
+		t.Fatalf("SetOpenAmount(SetAmount(s)) = %q, want s", got)
+	}
+}
+
+func TestSetAdditionalData(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		set  func(string) (string, error)
+		want string
+	}{
+		{
+			name: "bill number added",
+			in:   testPayload,
+			set:  func(s string) (string, error) { return emv.SetBillNumber(s, "INV-7") },
+			want: "6216" + "0503A01" + "0105INV-7",
+		},
+		{
+			name: "purpose added",
+			in:   testPayload,
+			set:  func(s string) (string, error) { return emv.SetPurpose(s, "Order 7") },
+			want: "6218" + "0503A01" + "0807Order 7",
+		},
+		{
+			name: "sub-field replaced",
+			in:   strings.Replace(testPayload, "62070503A01", "62090105INV-1", 1),
+			set:  func(s string) (string, error) { return emv.SetBillNumber(s, "INV-22") },
+			want: "6210" + "0106INV-22",
+		},
+		{
+			name: "template added",
+			in:   strings.TrimSuffix(testPayload, "62070503A01"),
+			set:  func(s string) (string, error) { return emv.SetAdditionalData(s, "07", "T1") },
+			want: "6206" + "0702T1",
+		},
+		{
+			name: "empty template",
+			in:   strings.Replace(testPayload, "62070503A01", "6200", 1),
+			set:  func(s string) (string, error) { return emv.SetAdditionalData(s, "07", "T1") },
+			want: "6206" + "0702T1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.set(emv.AppendCRC(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := body(t, got); !strings.HasSuffix(b, tt.want) {
+				t.Fatalf("payload = %q, want it to end with %q", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAdditionalDataInvalid(t *testing.T) {
+	full := emv.AppendCRC(testPayload)
+	long := strings.Repeat("A", emv.MaxAdditionalDataLength+1)
+	tests := []struct {
+		name string
+		set  func() (string, error)
+	}{
+		{"empty bill number", func() (string, error) { return emv.SetBillNumber(full, "") }},
+		{"long bill number", func() (string, error) { return emv.SetBillNumber(full, long) }},
+		{"empty purpose", func() (string, error) { return emv.SetPurpose(full, "") }},
+		{"long purpose", func() (string, error) { return emv.SetPurpose(full, long) }},
+		{"broken template", func() (string, error) {
+			return emv.SetBillNumber(emv.AppendCRC(strings.Replace(testPayload, "62070503A01", "62040599", 1)), "INV-1")
+		}},
+		{"template too long", func() (string, error) {
+			s, err := emv.SetAdditionalData(full, "01", strings.Repeat("A", 80))
+			if err != nil {
+				return "", err
+			}
+			return emv.SetAdditionalData(s, "08", strings.Repeat("B", 20))
+		}},
+	}
+	for _, tt := range tests {
+		if got, err := tt.set(); err == nil {
+			t.Errorf("%s: got %q, want an error", tt.name, got)
+		}
+	}
+	if got, err := emv.SetPurpose(full, strings.Repeat("A", emv.MaxAdditionalDataLength)); err != nil || emv.VerifyCRC(got) != nil {
+		t.Errorf("purpose of %d characters = %q, %v", emv.MaxAdditionalDataLength, got, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ordered", in: testPayload, want: testPayload},
+		{
+			name: "tags out of order",
+			in:   "000201" + "5802ID" + "010211" + "5303360",
+			want: "000201" + "010211" + "5303360" + "5802ID",
+		},
+		{
+			name: "sub-tags out of order",
+			in:   "000201" + "6214" + "0702T1" + "0104AB12",
+			want: "000201" + "6214" + "0104AB12" + "0702T1",
+		},
+		{
+			name: "same tags keep their order",
+			in:   "000201" + "5902B1" + "5902A1",
+			want: "000201" + "5902B1" + "5902A1",
+		},
+		{
+			name: "empty fields dropped",
+			in:   "000201" + "5400" + "6215" + "0500" + "0807Order 7",
+			want: "000201" + "6211" + "0807Order 7",
+		},
+		{
+			name: "template left empty dropped",
+			in:   "000201" + "62040500" + "5802ID",
+			want: "000201" + "5802ID",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := emv.AppendCRC(tt.in)
+			// Lowercase CRC of the input comes back uppercase
+			in = in[:len(in)-4] + strings.ToLower(in[len(in)-4:])
+			got, err := emv.Normalize(in)
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if b := body(t, got); b != tt.want {
+				t.Fatalf("Normalize = %q, want %q", b, tt.want)
+			}
+			if again, err := emv.Normalize(got); err != nil || again != got {
+				t.Fatalf("Normalize is not idempotent: %q, %v", again, err)
+			}
+		})
+	}
+
+	if _, err := emv.Normalize(emv.AppendCRC("000201" + "62040599")); err == nil {
+		t.Error("Normalize of a broken template succeeded")
+	}
+}
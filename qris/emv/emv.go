@@ -0,0 +1,229 @@
+// Package emv builds, parses and edits QRIS payloads (EMV merchant-presented QR strings) with pure
+// functions: no configuration, no network and no dependencies beyond the standard library.
+// Package emv menyusun, mem-parse dan mengubah payload QRIS (string QR EMV merchant-presented) dengan
+// fungsi murni: tanpa konfigurasi, tanpa jaringan dan tanpa dependensi selain standard library.
+//
+// The qris package is built on it; use emv directly where only the string math is needed.
+// Paket qris dibangun di atasnya; gunakan emv langsung jika hanya perhitungan string yang dibutuhkan.
+package emv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxValueLength is the longest value a field can carry, as its length has two digits.
+// MaxValueLength adalah nilai terpanjang yang dapat dibawa field, karena panjangnya dua digit.
+const MaxValueLength = 99
+
+// QRISField is a single TLV (tag-length-value) field of a QRIS payload.
+// QRISField adalah satu field TLV (tag-length-value) dari payload QRIS.
+type QRISField struct {
+	Tag       string      // Two-digit tag / Tag dua digit
+	Length    int         // Declared value length / Panjang nilai yang dideklarasikan
+	Value     string      // Raw value / Nilai mentah
+	Offset    int         // Offset of the tag in the payload / Posisi tag di dalam payload
+	SubFields []QRISField // Nested fields of template tags / Field bersarang dari tag template
+}
+
+// QRISPayload is a parsed QRIS payload.
+// QRISPayload adalah payload QRIS yang sudah di-parse.
+type QRISPayload struct {
+	Fields []QRISField // Top-level fields in payload order / Field tingkat atas sesuai urutan payload
+}
+
+// Field returns the first top-level field with the given tag.
+// Field mengembalikan field tingkat atas pertama dengan tag tertentu.
+func (p *QRISPayload) Field(tag string) (QRISField, bool) {
+	return findField(p.Fields, tag)
+}
+
+// Value returns the value of the first top-level field with the given tag, or "".
+// Value mengembalikan nilai field tingkat atas pertama dengan tag tertentu, atau "".
+func (p *QRISPayload) Value(tag string) string {
+	f, _ := p.Field(tag)
+	return f.Value
+}
+
+// Currency returns the ISO 4217 numeric code of tag 53 (transaction currency), such as "360", or "".
+// Currency mengembalikan kode numerik ISO 4217 dari tag 53 (mata uang transaksi), misalnya "360", atau "".
+func (p *QRISPayload) Currency() string {
+	return p.Value("53")
+}
+
+// Purpose returns the purpose of transaction (tag 62, sub-tag 08), or "".
+// Purpose mengembalikan tujuan transaksi (tag 62, sub-tag 08), atau "".
+func (p *QRISPayload) Purpose() string {
+	f, _ := p.Field("62")
+	sub, _ := f.SubField("08")
+	return sub.Value
+}
+
+// SubField returns the first nested field of a template tag.
+// SubField mengembalikan field bersarang pertama dari tag template.
+func (f QRISField) SubField(tag string) (QRISField, bool) {
+	return findField(f.SubFields, tag)
+}
+
+func findField(fields []QRISField, tag string) (QRISField, bool) {
+	for _, f := range fields {
+		if f.Tag == tag {
+			return f, true
+		}
+	}
+	return QRISField{}, false
+}
+
+// Parse parses a QRIS payload into its TLV fields.
+// Parse mem-parse payload QRIS menjadi field-field TLV.
+//
+// It never panics: malformed input such as truncated lengths, non-numeric lengths,
+// or nested templates overflowing their parent returns an error. The CRC is not verified; see VerifyCRC.
+// Fungsi ini tidak pernah panic: input rusak seperti panjang terpotong, panjang non-numerik,
+// atau template bersarang yang melebihi induknya mengembalikan error. CRC tidak diverifikasi; lihat VerifyCRC.
+func Parse(s string) (QRISPayload, error) {
+	fields, err := ParseFields(s, 0)
+	if err != nil {
+		return QRISPayload{}, err
+	}
+	if len(fields) == 0 {
+		return QRISPayload{}, fmt.Errorf("empty QRIS payload / payload QRIS kosong")
+	}
+
+	for i := range fields {
+		if !IsTemplateTag(fields[i].Tag) {
+			continue
+		}
+		sub, err := ParseFields(fields[i].Value, fields[i].Offset+4)
+		if err != nil {
+			return QRISPayload{}, fmt.Errorf("invalid template tag %s / tag template %s tidak valid: %v", fields[i].Tag, fields[i].Tag, err)
+		}
+		fields[i].SubFields = sub
+	}
+	return QRISPayload{Fields: fields}, nil
+}
+
+// ParseFields splits s into TLV fields without descending into templates; offset is the position
+// of s in the full payload, reported in Offset and in errors.
+// ParseFields memecah s menjadi field TLV tanpa masuk ke template; offset adalah posisi s di dalam
+// payload lengkap, yang dilaporkan di Offset dan di error.
+func ParseFields(s string, offset int) ([]QRISField, error) {
+	var fields []QRISField
+	for pos := 0; pos < len(s); {
+		if len(s)-pos < 4 {
+			return nil, fmt.Errorf("truncated field at offset %d / field terpotong di posisi %d", offset+pos, offset+pos)
+		}
+
+		tag := s[pos : pos+2]
+		if !isDigits(tag) {
+			return nil, fmt.Errorf("non-numeric tag %q at offset %d / tag non-numerik %q di posisi %d", tag, offset+pos, tag, offset+pos)
+		}
+
+		lengthStr := s[pos+2 : pos+4]
+		if !isDigits(lengthStr) {
+			return nil, fmt.Errorf("non-numeric length %q for tag %s at offset %d / panjang non-numerik %q untuk tag %s di posisi %d", lengthStr, tag, offset+pos, lengthStr, tag, offset+pos)
+		}
+		length, _ := strconv.Atoi(lengthStr)
+
+		start := pos + 4
+		if length > len(s)-start {
+			return nil, fmt.Errorf("tag %s at offset %d declares length %d but only %d characters remain / tag %s di posisi %d mendeklarasikan panjang %d tetapi hanya tersisa %d karakter",
+				tag, offset+pos, length, len(s)-start, tag, offset+pos, length, len(s)-start)
+		}
+
+		fields = append(fields, QRISField{
+			Tag:    tag,
+			Length: length,
+			Value:  s[start : start+length],
+			Offset: offset + pos,
+		})
+		pos = start + length
+	}
+	return fields, nil
+}
+
+// IsTemplateTag reports whether a top-level tag holds nested TLV fields.
+// IsTemplateTag melaporkan apakah tag tingkat atas berisi field TLV bersarang.
+func IsTemplateTag(tag string) bool {
+	n, err := strconv.Atoi(tag)
+	if err != nil {
+		return false
+	}
+	return (n >= 26 && n <= 51) || n == 62 || n == 64 || n >= 80
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeTLV encodes a single field as tag, two-digit length, and value.
+// EncodeTLV meng-encode satu field sebagai tag, panjang dua digit, dan nilai.
+func EncodeTLV(tag, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+// EncodeFields encodes fields in order, re-encoding nested fields of templates. Length and Offset
+// are ignored; lengths are taken from the values.
+// EncodeFields meng-encode field secara berurutan, termasuk field bersarang dari template. Length dan
+// Offset diabaikan; panjang diambil dari nilainya.
+func EncodeFields(fields []QRISField) string {
+	var b strings.Builder
+	for _, f := range fields {
+		value := f.Value
+		if len(f.SubFields) > 0 {
+			value = EncodeFields(f.SubFields)
+		}
+		b.WriteString(EncodeTLV(f.Tag, value))
+	}
+	return b.String()
+}
+
+// Build encodes payload and appends a fresh CRC. Any tag 63 in payload is dropped, and values
+// longer than MaxValueLength or tags that are not two digits are rejected.
+// Build meng-encode payload dan menambahkan CRC baru. Tag 63 di payload dibuang, dan nilai yang
+// lebih panjang dari MaxValueLength atau tag yang bukan dua digit ditolak.
+func Build(payload QRISPayload) (string, error) {
+	fields := make([]QRISField, 0, len(payload.Fields))
+	for _, f := range payload.Fields {
+		if f.Tag != "63" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty QRIS payload / payload QRIS kosong")
+	}
+	if err := checkFields(fields); err != nil {
+		return "", err
+	}
+	return AppendCRC(EncodeFields(fields)), nil
+}
+
+// checkFields reports the first field that cannot be encoded.
+// checkFields melaporkan field pertama yang tidak dapat di-encode.
+func checkFields(fields []QRISField) error {
+	for _, f := range fields {
+		if len(f.Tag) != 2 || !isDigits(f.Tag) {
+			return fmt.Errorf("invalid tag %q / tag %q tidak valid", f.Tag, f.Tag)
+		}
+		value := f.Value
+		if len(f.SubFields) > 0 {
+			if err := checkFields(f.SubFields); err != nil {
+				return err
+			}
+			value = EncodeFields(f.SubFields)
+		}
+		if len(value) > MaxValueLength {
+			return fmt.Errorf("tag %s exceeds %d characters / tag %s melebihi %d karakter", f.Tag, MaxValueLength, f.Tag, MaxValueLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package emv_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// testPayload is a static QRIS payload without its CRC field.
+const testPayload = "000201" + "010211" +
+	"26280014ID.CO.QRIS.WWW0106123456" +
+	"5204581253033605802ID5910TOKO TESTS6007JAKARTA" +
+	"62070503A01"
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// CRC-16/CCITT-FALSE check value
+		{"123456789", "29B1"},
+		{"", "FFFF"},
+		{"A", "B915"},
+		{"000201010211" + emv.CRCTag, emv.CRC16("000201010211" + emv.CRCTag)},
+	}
+	for _, tt := range tests {
+		if got := emv.CRC16(tt.in); got != tt.want {
+			t.Errorf("CRC16(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyCRC(t *testing.T) {
+	full := emv.AppendCRC(testPayload)
+	crc := full[len(full)-4:]
+	if full[:len(full)-8] != testPayload || full[len(full)-8:len(full)-4] != emv.CRCTag {
+		t.Fatalf("AppendCRC = %q, want the body then 6304 and the CRC", full)
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "valid", in: full},
+		{name: "lowercase CRC", in: full[:len(full)-4] + strings.ToLower(crc)},
+		{name: "wrong CRC", in: full[:len(full)-4] + "0000", wantErr: true},
+		{name: "body changed", in: strings.Replace(full, "JAKARTA", "BANDUNG", 1), wantErr: true},
+		{name: "no CRC field", in: testPayload, wantErr: true},
+		{name: "CRC field not at the end", in: full + "00", wantErr: true},
+		{name: "short", in: "6304", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := emv.VerifyCRC(tt.in)
+			if tt.wantErr != errors.Is(err, emv.ErrInvalidChecksum) || (!tt.wantErr && err != nil) {
+				t.Fatalf("VerifyCRC = %v, want error = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	full := emv.AppendCRC(testPayload)
+	p, err := emv.Parse(full)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var tags []string
+	for _, f := range p.Fields {
+		tags = append(tags, f.Tag)
+		if full[f.Offset:f.Offset+2] != f.Tag || f.Length != len(f.Value) {
+			t.Errorf("field %s: offset %d, length %d of %q", f.Tag, f.Offset, f.Length, f.Value)
+		}
+	}
+	if want := []string{"00", "01", "26", "52", "53", "58", "59", "60", "62", "63"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("tags = %v, want %v", tags, want)
+	}
+	if p.Value("59") != "TOKO TESTS" || p.Currency() != "360" || p.Value("99") != "" {
+		t.Fatalf("values = %q %q %q", p.Value("59"), p.Currency(), p.Value("99"))
+	}
+
+	// Sub-fields of templates carry offsets into the full payload
+	merchant, _ := p.Field("26")
+	sub, ok := merchant.SubField("01")
+	if !ok || sub.Value != "123456" || full[sub.Offset:sub.Offset+4] != "0106" {
+		t.Fatalf("sub-field 26/01 = %+v, %v", sub, ok)
+	}
+	additional, _ := p.Field("62")
+	if sub, _ := additional.SubField("05"); sub.Value != "A01" || p.Purpose() != "" {
+		t.Fatalf("sub-field 62/05 = %+v, purpose %q", sub, p.Purpose())
+	}
+	if f, _ := p.Field("52"); len(f.SubFields) != 0 {
+		t.Fatalf("non-template tag 52 has sub-fields %+v", f.SubFields)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // part of the error / bagian dari error
+	}{
+		{name: "empty", in: "", want: "empty"},
+		{name: "truncated header", in: "000201010", want: "truncated field at offset 6"},
+		{name: "non-numeric tag", in: "0002010A0211", want: `non-numeric tag "0A" at offset 6`},
+		{name: "non-numeric length", in: "00020101X211", want: `non-numeric length "X2" for tag 01`},
+		{name: "negative length", in: "00020101-111", want: `non-numeric length "-1"`},
+		{name: "length past the end", in: "0002010109ABC", want: "declares length 9 but only 3 characters remain"},
+		{name: "template overflows its parent", in: "0002012608" + "0010ABCD", want: "invalid template tag 26"},
+		{name: "template offset", in: "0002016208" + "05X1ABCD", want: "for tag 05 at offset 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := emv.Parse(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse = %v, want an error containing %q", err, tt.want)
+			}
+			if p.Fields != nil {
+				t.Fatalf("Parse returned fields %+v with the error", p.Fields)
+			}
+		})
+	}
+}
+
+func TestParseFieldsOffset(t *testing.T) {
+	fields, err := emv.ParseFields("0003ABC0100", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []emv.QRISField{
+		{Tag: "00", Length: 3, Value: "ABC", Offset: 10},
+		{Tag: "01", Length: 0, Value: "", Offset: 17},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("ParseFields = %+v, want %+v", fields, want)
+	}
+	if fields, err := emv.ParseFields("", 0); err != nil || fields != nil {
+		t.Fatalf("ParseFields of \"\" = %+v, %v, want no fields", fields, err)
+	}
+}
+
+func TestIsTemplateTag(t *testing.T) {
+	tests := map[string]bool{
+		"00": false, "25": false, "26": true, "51": true, "52": false,
+		"54": false, "62": true, "63": false, "64": true, "65": false,
+		"79": false, "80": true, "99": true, "XX": false, "": false,
+	}
+	for tag, want := range tests {
+		if got := emv.IsTemplateTag(tag); got != want {
+			t.Errorf("IsTemplateTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestEncodeTLV(t *testing.T) {
+	tests := []struct {
+		tag, value, want string
+	}{
+		{"54", "25000", "540525000"},
+		{"62", "", "6200"},
+		{"59", strings.Repeat("A", 12), "5912" + strings.Repeat("A", 12)},
+		{"26", strings.Repeat("B", 99), "2699" + strings.Repeat("B", 99)},
+	}
+	for _, tt := range tests {
+		if got := emv.EncodeTLV(tt.tag, tt.value); got != tt.want {
+			t.Errorf("EncodeTLV(%s, %d characters) = %q, want %q", tt.tag, len(tt.value), got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	full := emv.AppendCRC(testPayload)
+	p, err := emv.Parse(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parse then Build gives the same payload back: the old tag 63 is dropped and re-signed
+	got, err := emv.Build(p)
+	if err != nil || got != full {
+		t.Fatalf("Build(Parse(s)) = %q, %v, want %q", got, err, full)
+	}
+
+	// Sub-fields win over the raw value, and lengths are recomputed
+	for i := range p.Fields {
+		if p.Fields[i].Tag == "62" {
+			p.Fields[i].SubFields = append(p.Fields[i].SubFields, emv.QRISField{Tag: "08", Value: "Order 7", Length: 1})
+		}
+	}
+	got, err = emv.Build(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := emv.VerifyCRC(got); err != nil {
+		t.Fatal(err)
+	}
+	if reparsed, err := emv.Parse(got); err != nil || reparsed.Purpose() != "Order 7" {
+		t.Fatalf("purpose after Build = %q, %v", reparsed.Purpose(), err)
+	}
+	if !strings.Contains(got, "6218"+"0503A01"+"0807Order 7") {
+		t.Fatalf("Build = %q, want tag 62 re-encoded with length 18", got)
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []emv.QRISField
+	}{
+		{name: "no fields"},
+		{name: "only the CRC", fields: []emv.QRISField{{Tag: "63", Value: "ABCD"}}},
+		{name: "one-digit tag", fields: []emv.QRISField{{Tag: "0", Value: "01"}}},
+		{name: "non-numeric tag", fields: []emv.QRISField{{Tag: "0A", Value: "01"}}},
+		{name: "value too long", fields: []emv.QRISField{{Tag: "59", Value: strings.Repeat("A", emv.MaxValueLength+1)}}},
+		{name: "sub-field tag invalid", fields: []emv.QRISField{{Tag: "62", SubFields: []emv.QRISField{{Tag: "123", Value: "A"}}}}},
+		{name: "template too long", fields: []emv.QRISField{{Tag: "62", SubFields: []emv.QRISField{
+			{Tag: "01", Value: strings.Repeat("A", 50)},
+			{Tag: "08", Value: strings.Repeat("B", 50)},
+		}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := emv.Build(emv.QRISPayload{Fields: tt.fields}); err == nil {
+				t.Fatalf("Build = %q, want an error", got)
+			}
+		})
+	}
+
+	got, err := emv.Build(emv.QRISPayload{Fields: []emv.QRISField{{Tag: "59", Value: strings.Repeat("A", emv.MaxValueLength)}}})
+	if err != nil || emv.VerifyCRC(got) != nil {
+		t.Fatalf("Build of a %d-character value = %q, %v", emv.MaxValueLength, got, err)
+	}
+}
@@ -0,0 +1,33 @@
+package qris
+
+import (
+	"github.com/AutoFTbot/OrderKuota-go/internal/sealed"
+)
+
+// EncryptionKeySize is the length in bytes of the AES-256 keys of file-backed stores.
+// EncryptionKeySize adalah panjang dalam byte dari kunci AES-256 untuk store berbasis file.
+const EncryptionKeySize = sealed.KeySize
+
+// ErrDecryptionFailed is returned when an encrypted file cannot be opened with the given keys,
+// or when it is encrypted and no key was given.
+// ErrDecryptionFailed dikembalikan jika file terenkripsi tidak dapat dibuka dengan kunci yang diberikan,
+// atau jika file terenkripsi dan tidak ada kunci yang diberikan.
+var ErrDecryptionFailed = sealed.ErrDecrypt
+
+// Keyring supplies the AES-256-GCM keys of file-backed stores, e.g. from a secret manager.
+// Keys returns the keys tried when decrypting, in order. The first one encrypts new writes,
+// so listing the new key before the old one rotates keys on the next write.
+// Keyring menyediakan kunci AES-256-GCM untuk store berbasis file, misalnya dari secret manager.
+// Keys mengembalikan kunci yang dicoba saat mendekripsi, secara berurutan. Kunci pertama mengenkripsi
+// tulisan baru, sehingga menaruh kunci baru sebelum kunci lama merotasi kunci pada tulisan berikutnya.
+type Keyring = sealed.Keyring
+
+// StaticKeyring is a Keyring with a fixed list of EncryptionKeySize-byte keys, current key first.
+// StaticKeyring adalah Keyring dengan daftar kunci tetap berukuran EncryptionKeySize byte, kunci saat ini lebih dulu.
+type StaticKeyring [][]byte
+
+// Keys returns the keys of the keyring.
+// Keys mengembalikan kunci-kunci dari keyring.
+func (k StaticKeyring) Keys() ([][]byte, error) {
+	return k, nil
+}
@@ -0,0 +1,278 @@
+package qris
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultEventBuffer is the number of events buffered for the EventSink and for every subscriber.
+// An event arriving while a buffer is full is dropped, logged for the EventSink, and counted in
+// DroppedEvents; the payment logic never waits for a buffer.
+// DefaultEventBuffer adalah jumlah event yang di-buffer untuk EventSink dan setiap subscriber.
+// Event yang datang saat buffer penuh dibuang, dicatat di log untuk EventSink, dan dihitung di
+// DroppedEvents; logika pembayaran tidak pernah menunggu buffer.
+const DefaultEventBuffer = 256
+
+// EventSink receives the events of every PaymentSession of a QRIS instance, in order per session.
+// EventSink menerima event dari setiap PaymentSession pada instance QRIS, berurutan per sesi.
+//
+// Delivery is best-effort unless the sink is a DurableSink. WriteEvent is called from a single
+// goroutine fed by a queue of DefaultEventBuffer events, so a slow sink delays other sink writes
+// but never the payment logic; once the queue is full, further events are dropped until it
+// drains. Errors are logged and the event is skipped. Sessions are the source of truth: use the
+// InvoiceStore for state that must not be lost.
+// Pengiriman bersifat best-effort kecuali sink adalah DurableSink. WriteEvent dipanggil dari satu
+// goroutine yang diisi antrean DefaultEventBuffer event, sehingga sink yang lambat menunda
+// penulisan sink lainnya tetapi tidak pernah logika pembayaran; setelah antrean penuh, event
+// berikutnya dibuang sampai antrean berkurang. Error dicatat di log dan event dilewati. Sesi
+// adalah sumber kebenaran: gunakan InvoiceStore untuk state yang tidak boleh hilang.
+type EventSink interface {
+	WriteEvent(ev PaymentEvent) error
+}
+
+// DurableSink is an EventSink that must not lose events, such as Webhook. RecordEvent is called
+// instead of WriteEvent, synchronously as each event is emitted and before any queue, so no event
+// is dropped. It runs while the session is locked: keep it to saving the event and hand slow work
+// to a goroutine. Errors are logged.
+// DurableSink adalah EventSink yang tidak boleh kehilangan event, seperti Webhook. RecordEvent
+// dipanggil sebagai ganti WriteEvent, secara sinkron saat setiap event dikirim dan sebelum antrean
+// apa pun, sehingga tidak ada event yang dibuang. Fungsi ini berjalan saat sesi terkunci: batasi
+// pada menyimpan event dan serahkan pekerjaan lambat ke goroutine. Error dicatat di log.
+type DurableSink interface {
+	EventSink
+	RecordEvent(ev PaymentEvent) error
+}
+
+// eventBus fans session events out to the EventSink and the subscribers of QRIS.Events.
+// eventBus meneruskan event sesi ke EventSink dan subscriber QRIS.Events.
+type eventBus struct {
+	mu      sync.Mutex
+	sink    EventSink // nil for a DurableSink, which is called by publish / nil untuk DurableSink, yang dipanggil oleh publish
+	durable DurableSink
+	queue   chan PaymentEvent
+	subs    []chan PaymentEvent
+	closed  bool
+	started bool
+	dropped uint64
+	done    chan struct{}
+}
+
+func newEventBus(sink EventSink) *eventBus {
+	b := &eventBus{
+		sink:  sink,
+		queue: make(chan PaymentEvent, DefaultEventBuffer),
+		done:  make(chan struct{}),
+	}
+	if durable, ok := sink.(DurableSink); ok {
+		b.sink, b.durable = nil, durable
+	}
+	return b
+}
+
+// publish records ev to a DurableSink, then queues it without blocking; it is dropped, logged and
+// counted when the queue is full.
+// publish mencatat ev ke DurableSink, lalu mengantrekannya tanpa memblokir; event dibuang, dicatat
+// dan dihitung jika antrean penuh.
+func (b *eventBus) publish(ev PaymentEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	if b.durable != nil {
+		if err := b.durable.RecordEvent(ev); err != nil {
+			logf(eventContext(ev), "Error recording event %s of %s: %v", ev.Type, ev.SessionID, err)
+		}
+	}
+	if b.sink == nil && len(b.subs) == 0 {
+		return
+	}
+	if !b.started {
+		b.started = true
+		go b.run()
+	}
+	select {
+	case b.queue <- ev:
+	default:
+		b.dropped++
+		logf(eventContext(ev), "Event %s of %s dropped, event queue full", ev.Type, ev.SessionID)
+	}
+}
+
+// subscribe returns a new channel receiving every event published from now on.
+// subscribe mengembalikan channel baru yang menerima setiap event yang dikirim mulai sekarang.
+func (b *eventBus) subscribe() <-chan PaymentEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan PaymentEvent, DefaultEventBuffer)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subs = append(b.subs, ch)
+	return ch
+}
+
+func (b *eventBus) run() {
+	defer close(b.done)
+
+	for ev := range b.queue {
+		if b.sink != nil {
+			if err := b.sink.WriteEvent(ev); err != nil {
+				logf(eventContext(ev), "Error writing event %s of %s: %v", ev.Type, ev.SessionID, err)
+			}
+		}
+
+		b.mu.Lock()
+		for _, ch := range b.subs {
+			select {
+			case ch <- ev:
+			default:
+				b.dropped++
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	for _, ch := range b.subs {
+		close(ch)
+	}
+	b.subs = nil
+	b.mu.Unlock()
+}
+
+// eventContext returns a context carrying the tenant and actor of ev, for logf.
+// eventContext mengembalikan context yang membawa tenant dan actor dari ev, untuk logf.
+func eventContext(ev PaymentEvent) context.Context {
+	ctx := context.Background()
+	if ev.Tenant != "" {
+		ctx = WithTenant(ctx, ev.Tenant)
+	}
+	if ev.Actor != "" {
+		ctx = WithActor(ctx, ev.Actor)
+	}
+	return ctx
+}
+
+// close stops accepting events and waits until the queued ones are delivered or ctx is done.
+// close berhenti menerima event dan menunggu sampai event dalam antrean terkirim atau ctx selesai.
+func (b *eventBus) close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	if !b.started {
+		for _, ch := range b.subs {
+			close(ch)
+		}
+		b.subs = nil
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events subscribes to the events of all sessions of this instance. Each call returns a new
+// channel, closed by Close. Events for a subscriber whose buffer (DefaultEventBuffer) is full
+// are dropped, as are events arriving while the sink queue is full; see DroppedEvents.
+// Events berlangganan event dari semua sesi instance ini. Setiap panggilan mengembalikan channel
+// baru, yang ditutup oleh Close. Event untuk subscriber yang buffer-nya (DefaultEventBuffer) penuh
+// dibuang, begitu juga event yang datang saat antrean sink penuh; lihat DroppedEvents.
+func (q *QRIS) Events() <-chan PaymentEvent {
+	return q.events.subscribe()
+}
+
+// DroppedEvents returns how many events were dropped because a buffer was full.
+// DroppedEvents mengembalikan jumlah event yang dibuang karena buffer penuh.
+func (q *QRIS) DroppedEvents() uint64 {
+	q.events.mu.Lock()
+	defer q.events.mu.Unlock()
+
+	return q.events.dropped
+}
+
+// JSONLSink is an EventSink appending one JSON object per line to a file.
+// JSONLSink adalah EventSink yang menambahkan satu objek JSON per baris ke sebuah file.
+type JSONLSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONLSink opens path for appending, creating it if needed.
+// NewJSONLSink membuka path untuk ditambahkan, dan membuatnya jika belum ada.
+func NewJSONLSink(path string) (*JSONLSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log / gagal membuka log event: %v", err)
+	}
+	return &JSONLSink{file: f}, nil
+}
+
+// eventRecord is the JSON form of a PaymentEvent.
+// eventRecord adalah bentuk JSON dari PaymentEvent.
+type eventRecord struct {
+	SessionID string            `json:"session_id"`
+	Type      string            `json:"type"`
+	At        time.Time         `json:"at"`
+	Detail    string            `json:"detail,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Status    *PaymentStatus    `json:"status,omitempty"`
+	Duplicate *DuplicatePayment `json:"duplicate,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Tenant    string            `json:"tenant,omitempty"`
+	Actor     string            `json:"actor,omitempty"`
+}
+
+// WriteEvent appends ev as one JSON line.
+// WriteEvent menambahkan ev sebagai satu baris JSON.
+func (s *JSONLSink) WriteEvent(ev PaymentEvent) error {
+	rec := eventRecord{
+		SessionID: ev.SessionID,
+		Type:      ev.Type,
+		At:        ev.At,
+		Detail:    ev.Detail,
+		Status:    ev.Status,
+		Duplicate: ev.Duplicate,
+		Metadata:  ev.Metadata,
+		Tenant:    ev.Tenant,
+		Actor:     ev.Actor,
+	}
+	if ev.Err != nil {
+		rec.Error = ev.Err.Error()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event / gagal marshal event: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+// Close menutup file yang dipakai.
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
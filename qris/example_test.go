@@ -0,0 +1,87 @@
+package qris_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// merchantQR is the static QRIS printed at the merchant's counter.
+const merchantQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+func ExampleQRIS_GenerateQRCode() {
+	gw := qristest.NewServer()
+	defer gw.Close()
+
+	q, err := qris.NewQRIS(qris.QRISConfig{
+		BaseQrString: merchantQR,
+		AuthToken:    "token",
+		AuthUsername: "user",
+		GatewayURL:   gw.URL,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer q.Close(context.Background())
+
+	qrCode, err := q.GenerateQRCode(qris.QRISData{Amount: 25000, TransactionID: "ORDER1"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	payload, err := qris.ParseQRISString(qrCode.Content)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("amount:", payload.Value("54"))
+	fmt.Println("merchant:", payload.Value("59"))
+	fmt.Println("CRC:", payload.Value("63"))
+	// Output:
+	// amount: 25000
+	// merchant: AutoFTbot Store
+	// CRC: 7D44
+}
+
+func ExampleQRIS_WaitForPayment() {
+	gw := qristest.NewServer()
+	defer gw.Close()
+
+	q, err := qris.NewQRIS(qris.QRISConfig{
+		BaseQrString: merchantQR,
+		AuthToken:    "token",
+		AuthUsername: "user",
+		GatewayURL:   gw.URL,
+		PollSchedule: qris.FixedSchedule(10 * time.Millisecond),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer q.Close(context.Background())
+
+	// The customer scans the QR code a moment later
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		gw.AddMutation(25000, time.Now(), qristest.WithIssuerRef("REF123"), qristest.WithBrand("DANA"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := q.WaitForPayment(ctx, "ORDER1", 25000)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(status.Status, qris.FormatIDR(status.Amount), status.Reference, status.BrandName)
+	// Output: PAID Rp 25.000 REF123 DANA
+}
+
+func ExampleConvertStaticToDynamic() {
+	dynamicQR, err := qris.ConvertStaticToDynamic(merchantQR, 25000)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(dynamicQR)
+	// Output: 00020101021226600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605405250005802ID5915AutoFTbot Store6012Kota Jakarta61051234563047D44
+}
@@ -0,0 +1,234 @@
+package qris
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportFormat selects the output format of ExportMutations.
+// ExportFormat memilih format output ExportMutations.
+type ExportFormat string
+
+// Supported export formats.
+// Format ekspor yang didukung.
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+)
+
+// Export column names.
+// Nama kolom ekspor.
+const (
+	ColumnDate      = "date"
+	ColumnAmount    = "amount"
+	ColumnType      = "type"
+	ColumnQRIS      = "qris"
+	ColumnBrandName = "brand_name"
+	ColumnIssuerRef = "issuer_reff"
+	ColumnBuyerRef  = "buyer_reff"
+)
+
+// DefaultExportColumns is the column set used when ExportOptions.Columns is empty.
+// DefaultExportColumns adalah kolom yang dipakai jika ExportOptions.Columns kosong.
+var DefaultExportColumns = []string{
+	ColumnDate, ColumnAmount, ColumnType, ColumnQRIS, ColumnBrandName, ColumnIssuerRef, ColumnBuyerRef,
+}
+
+// ExportOptions configures ExportMutations.
+// ExportOptions mengatur ExportMutations.
+type ExportOptions struct {
+	Format  ExportFormat // Output format, default CSV / Format output, default CSV
+	From    time.Time    // Inclusive start, zero for no limit / Awal (inklusif), nol berarti tanpa batas
+	To      time.Time    // Exclusive end, zero for no limit / Akhir (eksklusif), nol berarti tanpa batas
+	Type    string       // CR or DB, empty for both / CR atau DB, kosong untuk keduanya
+	Columns []string     // Columns in output order / Kolom sesuai urutan output
+}
+
+// ExportMutations writes the mutation history to w as CSV or JSON.
+// ExportMutations menulis riwayat mutasi ke w dalam format CSV atau JSON.
+//
+// Rows are written one by one as pages are read, amounts as plain integers
+// and dates as RFC3339 in the configured timezone.
+// Baris ditulis satu per satu saat halaman dibaca, nominal sebagai bilangan bulat biasa
+// dan tanggal sebagai RFC3339 dalam zona waktu yang dikonfigurasi.
+func (q *QRIS) ExportMutations(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultExportColumns
+	}
+	for _, c := range columns {
+		if !isExportColumn(c) {
+			return fmt.Errorf("unknown export column %q / kolom ekspor %q tidak dikenal", c, c)
+		}
+	}
+	if opts.Type != "" && opts.Type != "CR" && opts.Type != "DB" {
+		return fmt.Errorf("invalid mutation type %q / tipe mutasi %q tidak valid", opts.Type, opts.Type)
+	}
+
+	var enc mutationEncoder
+	switch opts.Format {
+	case ExportCSV, "":
+		enc = newCSVMutationEncoder(w, columns)
+	case ExportJSON:
+		enc = newJSONMutationEncoder(w, columns)
+	default:
+		return fmt.Errorf("unsupported export format %q / format ekspor %q tidak didukung", opts.Format, opts.Format)
+	}
+
+	if err := enc.begin(); err != nil {
+		return err
+	}
+	loc := q.location()
+	query := MutationQuery{From: opts.From, To: opts.To, Type: opts.Type}
+	err := q.eachAllMutations(ctx, query, func(m Mutation) error {
+		return enc.row(exportValues(m, columns, loc))
+	})
+	if err != nil {
+		return err
+	}
+	return enc.end()
+}
+
+func isExportColumn(c string) bool {
+	for _, known := range DefaultExportColumns {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// exportValue is a single cell; number marks values written unquoted in JSON.
+// exportValue adalah satu sel; number menandai nilai yang ditulis tanpa kutip di JSON.
+type exportValue struct {
+	text   string
+	number bool
+}
+
+func exportValues(m Mutation, columns []string, loc *time.Location) []exportValue {
+	values := make([]exportValue, len(columns))
+	for i, c := range columns {
+		switch c {
+		case ColumnDate:
+			if !m.Time.IsZero() {
+				values[i].text = m.Time.In(loc).Format(time.RFC3339)
+			}
+		case ColumnAmount:
+			values[i] = exportValue{text: strconv.FormatInt(m.Amount, 10), number: true}
+		case ColumnType:
+			values[i].text = m.Type
+		case ColumnQRIS:
+			values[i].text = m.QRIS
+		case ColumnBrandName:
+			values[i].text = m.BrandName
+		case ColumnIssuerRef:
+			values[i].text = m.IssuerRef
+		case ColumnBuyerRef:
+			values[i].text = m.BuyerRef
+		}
+	}
+	return values
+}
+
+type mutationEncoder interface {
+	begin() error
+	row(values []exportValue) error
+	end() error
+}
+
+type csvMutationEncoder struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVMutationEncoder(w io.Writer, columns []string) *csvMutationEncoder {
+	return &csvMutationEncoder{w: csv.NewWriter(w), columns: columns}
+}
+
+func (e *csvMutationEncoder) begin() error {
+	return e.write(e.columns)
+}
+
+func (e *csvMutationEncoder) row(values []exportValue) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = v.text
+	}
+	return e.write(record)
+}
+
+func (e *csvMutationEncoder) end() error {
+	return nil
+}
+
+func (e *csvMutationEncoder) write(record []string) error {
+	if err := e.w.Write(record); err != nil {
+		return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+	}
+	return nil
+}
+
+// jsonMutationEncoder writes a JSON array incrementally, keeping keys in column order.
+// jsonMutationEncoder menulis array JSON secara bertahap, dengan urutan key sesuai kolom.
+type jsonMutationEncoder struct {
+	w       *bufio.Writer
+	columns []string
+	rows    int
+}
+
+func newJSONMutationEncoder(w io.Writer, columns []string) *jsonMutationEncoder {
+	return &jsonMutationEncoder{w: bufio.NewWriter(w), columns: columns}
+}
+
+func (e *jsonMutationEncoder) begin() error {
+	e.w.WriteString("[")
+	return nil
+}
+
+func (e *jsonMutationEncoder) row(values []exportValue) error {
+	if e.rows > 0 {
+		e.w.WriteString(",")
+	}
+	e.rows++
+	e.w.WriteString("\n  {")
+	for i, v := range values {
+		if i > 0 {
+			e.w.WriteString(",")
+		}
+		key, _ := json.Marshal(e.columns[i])
+		e.w.Write(key)
+		e.w.WriteString(":")
+		if v.number {
+			e.w.WriteString(v.text)
+		} else {
+			val, _ := json.Marshal(v.text)
+			e.w.Write(val)
+		}
+	}
+	e.w.WriteString("}")
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+	}
+	return nil
+}
+
+func (e *jsonMutationEncoder) end() error {
+	if e.rows > 0 {
+		e.w.WriteString("\n")
+	}
+	e.w.WriteString("]\n")
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export / gagal menulis ekspor: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package qris_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// exportGateway serves a fixed day of mutations.
+func exportGateway(t *testing.T) *qristest.Server {
+	gw := newGateway(t)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, qris.WIB)
+	gw.AddMutation(15000, day.Add(9*time.Hour), qristest.WithBrand("DANA"), qristest.WithIssuerRef("REF1"))
+	gw.AddMutation(250000, day.Add(10*time.Hour+30*time.Minute), qristest.WithBrand("OVO"), qristest.WithIssuerRef("REF2"), qristest.WithBuyerRef("TRX2"))
+	gw.AddMutation(5000, day.Add(11*time.Hour), qristest.WithType("DB"), qristest.WithBrand("Penarikan, saldo"), qristest.WithIssuerRef("REF3"))
+	gw.AddMutation(99000, day.Add(30*time.Hour), qristest.WithIssuerRef("NEXTDAY"))
+	return gw
+}
+
+func TestExportMutationsGolden(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, qris.WIB)
+	tests := []struct {
+		name   string
+		opts   qris.ExportOptions
+		golden string
+	}{
+		{"csv", qris.ExportOptions{Format: qris.ExportCSV}, "export.csv"},
+		{"json", qris.ExportOptions{Format: qris.ExportJSON}, "export.json"},
+		{"csv credits with columns", qris.ExportOptions{Format: qris.ExportCSV, Type: "CR", Columns: []string{qris.ColumnAmount, qris.ColumnDate}}, "export_credits.csv"},
+		{"json credits with columns", qris.ExportOptions{Format: qris.ExportJSON, Type: "CR", Columns: []string{qris.ColumnIssuerRef, qris.ColumnAmount}}, "export_credits.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQRIS(t, exportGateway(t))
+			tt.opts.From, tt.opts.To = day, day.AddDate(0, 0, 1)
+
+			var buf bytes.Buffer
+			if err := q.ExportMutations(context.Background(), &buf, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestExportMutationsInvalidOptions(t *testing.T) {
+	q := newTestQRIS(t, exportGateway(t))
+	tests := []struct {
+		name string
+		opts qris.ExportOptions
+		want string
+	}{
+		{"column", qris.ExportOptions{Columns: []string{"balance"}}, "unknown export column"},
+		{"type", qris.ExportOptions{Type: "XX"}, "invalid mutation type"},
+		{"format", qris.ExportOptions{Format: "xml"}, "unsupported export format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.ExportMutations(context.Background(), &bytes.Buffer{}, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultLookback is how far back FindPaymentByReference searches when no lookback is given.
+// DefaultLookback adalah rentang waktu ke belakang yang dicari FindPaymentByReference jika lookback tidak diberikan.
+const DefaultLookback = 24 * time.Hour
+
+// ErrNotFound is returned when no payment matches a reference.
+// ErrNotFound dikembalikan jika tidak ada pembayaran yang cocok dengan referensi.
+var ErrNotFound = errors.New("payment not found / pembayaran tidak ditemukan")
+
+// FindPaymentByReference looks for a credit received in the last lookback (DefaultLookback if 0 or less)
+// whose issuer reference or buyer reference equals ref, ignoring case, for example the reference a
+// customer reads from their banking app. It returns the PAID status of the latest such credit, or ErrNotFound.
+// FindPaymentByReference mencari kredit yang diterima dalam lookback terakhir (DefaultLookback jika 0 atau kurang)
+// yang referensi issuer atau referensi pembelinya sama dengan ref, tanpa membedakan huruf besar/kecil, misalnya
+// referensi yang dibaca customer dari aplikasi banknya. Fungsi ini mengembalikan status PAID dari kredit tersebut
+// yang terbaru, atau ErrNotFound.
+func (q *QRIS) FindPaymentByReference(ctx context.Context, ref string, lookback time.Duration) (*PaymentStatus, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("ref must be filled / ref harus diisi")
+	}
+	if lookback <= 0 {
+		lookback = DefaultLookback
+	}
+
+	var (
+		found   *Mutation
+		prev    time.Time
+		ordered = true
+	)
+	query := MutationQuery{From: time.Now().Add(-lookback), Type: "CR"}
+	err := q.eachAllMutations(ctx, query, func(m Mutation) error {
+		// A row newer than the one before means the gateway does not sort newest first
+		if !prev.IsZero() && m.Time.After(prev) {
+			ordered = false
+		}
+		prev = m.Time
+		// Rows come newest first and this one is older than the hit, so no later row is newer
+		if ordered && found != nil && m.Time.Before(found.Time) {
+			return errStopMutations
+		}
+		if (strings.EqualFold(m.IssuerRef, ref) || strings.EqualFold(m.BuyerRef, ref)) && (found == nil || m.Time.After(found.Time)) {
+			found = &m
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopMutations) {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+
+	return &PaymentStatus{
+		Status:    StatusPaid,
+		Amount:    found.Amount,
+		Reference: found.IssuerRef,
+		Date:      found.Date,
+		BrandName: found.BrandName,
+		Issuer:    found.Issuer,
+		BuyerRef:  found.BuyerRef,
+		PaidAt:    found.Time,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package qris_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestFindPaymentByReference(t *testing.T) {
+	srv := staticGateway(t, mutationFixture(t, "find/oldest_first.json"))
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+
+	tests := []struct {
+		name      string
+		ref       string
+		lookback  time.Duration
+		reference string // issuer reference found, "" for ErrNotFound / referensi issuer yang ditemukan, "" untuk ErrNotFound
+		amount    int64
+	}{
+		{name: "issuer reference", ref: "ref2", reference: "REF2", amount: 20000},
+		{name: "buyer reference, latest of two", ref: " inv-9 ", reference: "REF9", amount: 15000},
+		{name: "debit", ref: "REFD"},
+		{name: "unknown", ref: "REF5"},
+		{name: "before the lookback", ref: "REF2", lookback: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := q.FindPaymentByReference(testContext(t, 5*time.Second), tt.ref, tt.lookback)
+			if tt.reference == "" {
+				if !errors.Is(err, qris.ErrNotFound) {
+					t.Fatalf("FindPaymentByReference = %+v, %v, want ErrNotFound", status, err)
+				}
+				return
+			}
+			if err != nil || status.Status != qris.StatusPaid || status.Reference != tt.reference || status.Amount != tt.amount || status.PaidAt.IsZero() {
+				t.Fatalf("FindPaymentByReference = %+v, %v, want %s paid %d", status, err, tt.reference, tt.amount)
+			}
+		})
+	}
+
+	if _, err := q.FindPaymentByReference(testContext(t, 5*time.Second), " ", 0); err == nil || errors.Is(err, qris.ErrNotFound) {
+		t.Fatalf("FindPaymentByReference of an empty ref err = %v, want an input error", err)
+	}
+}
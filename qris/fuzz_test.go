@@ -0,0 +1,64 @@
+package qris_test
+
+import (
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// fuzzSeeds are added to the corpus of testdata/fuzz: truncated and overflowing variants of the test QRIS.
+var fuzzSeeds = []string{
+	"",
+	"00",
+	"0002",
+	"000201",
+	"0002010102",
+	"00020101021226",
+	"0002010102122660",
+	"00020101021262990105INV-1",
+	"0002010102126205010599999",
+	"00AB01",
+	"0002010102126304",
+	testBaseQR[:len(testBaseQR)-1],
+}
+
+func FuzzParseQRISString(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		payload, err := qris.ParseQRISString(s)
+		if err != nil {
+			return
+		}
+		// Parsing is lossless: the fields encode back to the input
+		if got := emv.EncodeFields(payload.Fields); got != s {
+			t.Fatalf("re-encoded %q, want %q", got, s)
+		}
+		for _, field := range payload.Fields {
+			if len(field.Value) != field.Length || s[field.Offset+4:field.Offset+4+field.Length] != field.Value {
+				t.Fatalf("field %s at %d does not match the input", field.Tag, field.Offset)
+			}
+		}
+	})
+}
+
+func FuzzValidateQRISString(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		report := qris.ValidateQRISStringDetailed(s)
+		if report.Err() != nil {
+			return
+		}
+		// A valid payload parses and carries a good checksum
+		if _, err := qris.ParseQRISString(s); err != nil {
+			t.Fatalf("valid payload does not parse: %v", err)
+		}
+		if err := emv.VerifyCRC(s); err != nil {
+			t.Fatalf("valid payload fails the CRC: %v", err)
+		}
+	})
+}
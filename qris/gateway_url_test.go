@@ -0,0 +1,64 @@
+package qris_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestGatewayURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		gateway    string
+		gatewayURL string
+		want       string
+	}{
+		{"ftvpn default", qris.GatewayFTVPN, "", qris.DefaultMutationURL},
+		{"ftvpn override", qris.GatewayFTVPN, "https://mirror.example/mutasi", "https://mirror.example/mutasi"},
+		{"okeconnect default", qris.GatewayOkeconnect, "", qris.DefaultOkeconnectURL + "/user/token"},
+		{"okeconnect override", qris.GatewayOkeconnect, "https://mirror.example/oke/", "https://mirror.example/oke/user/token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Answer every request locally, so the defaults are never dialled
+			var got []string
+			record := func(next qris.RoundTripFunc) qris.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					got = append(got, req.URL.String())
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(bytes.NewReader(largeResponse(t, 1, 15000))),
+						Request:    req,
+					}, nil
+				}
+			}
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.Gateway = tt.gateway
+				c.GatewayURL = tt.gatewayURL
+				c.Interceptors = []qris.Interceptor{record}
+			})
+
+			status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "ORDER1", 15000)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if status.Status != qris.StatusPaid {
+				t.Errorf("Status = %s, want PAID", status.Status)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("requested %q, want [%q]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeprecatedGatewayURL(t *testing.T) {
+	if qris.DefaultGatewayURL != qris.DefaultMutationURL {
+		t.Fatalf("DefaultGatewayURL = %q, want DefaultMutationURL %q", qris.DefaultGatewayURL, qris.DefaultMutationURL)
+	}
+}
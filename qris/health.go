@@ -0,0 +1,66 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultHealthTTL is how long HealthCheck reuses its last result.
+// DefaultHealthTTL adalah lamanya HealthCheck memakai ulang hasil terakhirnya.
+const DefaultHealthTTL = 10 * time.Second
+
+var (
+	// ErrGatewayUnauthorized is wrapped by errors of gateway requests rejected because of the credentials.
+	// ErrGatewayUnauthorized dibungkus oleh error request gateway yang ditolak karena kredensial.
+	ErrGatewayUnauthorized = errors.New("gateway rejected the credentials / gateway menolak kredensial")
+
+	// ErrGatewayUnreachable is wrapped by errors of gateway requests that could not be sent.
+	// ErrGatewayUnreachable dibungkus oleh error request gateway yang tidak dapat dikirim.
+	ErrGatewayUnreachable = errors.New("gateway unreachable / gateway tidak dapat dihubungi")
+)
+
+// healthCache remembers the last HealthCheck result.
+// healthCache menyimpan hasil HealthCheck terakhir.
+type healthCache struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// HealthCheck reports whether the gateway is reachable and accepts the credentials, by fetching
+// a single mutation. The result is cached for DefaultHealthTTL, so it is cheap to call from
+// liveness and readiness probes; concurrent calls share one request.
+// HealthCheck melaporkan apakah gateway dapat dihubungi dan menerima kredensial, dengan mengambil
+// satu mutasi. Hasilnya di-cache selama DefaultHealthTTL, sehingga murah dipanggil dari probe
+// liveness dan readiness; panggilan bersamaan memakai satu request yang sama.
+//
+// Failures wrap ErrGatewayUnauthorized, ErrGatewayUnreachable, ErrGatewayTimeout, or ErrClosed
+// when the cause is known. In sandbox mode the gateway is not called.
+// Kegagalan membungkus ErrGatewayUnauthorized, ErrGatewayUnreachable, ErrGatewayTimeout, atau ErrClosed
+// jika penyebabnya diketahui. Dalam mode sandbox gateway tidak dipanggil.
+func (q *QRIS) HealthCheck(ctx context.Context) error {
+	if err := q.life.err(); err != nil {
+		return err
+	}
+
+	h := q.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.checked.IsZero() && time.Since(h.checked) < DefaultHealthTTL {
+		return h.err
+	}
+
+	_, err := q.eachMutation(ctx, MutationQuery{Page: 1, PerPage: 1}, func(Mutation) error { return errStopMutations })
+	if errors.Is(err, errStopMutations) {
+		err = nil
+	}
+	// The caller giving up says nothing about the gateway
+	if ctx.Err() != nil {
+		return err
+	}
+	h.checked, h.err = time.Now(), err
+	return err
+}
@@ -0,0 +1,102 @@
+package qris_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+func TestHealthCheck(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	tests := []struct {
+		name    string
+		setup   func(gw *qristest.Server, c *qris.QRISConfig)
+		wantErr error
+	}{
+		{
+			name:  "healthy",
+			setup: func(*qristest.Server, *qris.QRISConfig) {},
+		},
+		{
+			name:    "unauthorized",
+			setup:   func(gw *qristest.Server, _ *qris.QRISConfig) { gw.FailNext(1, http.StatusUnauthorized) },
+			wantErr: qris.ErrGatewayUnauthorized,
+		},
+		{
+			name:    "unreachable",
+			setup:   func(_ *qristest.Server, c *qris.QRISConfig) { c.GatewayURL = closed.URL },
+			wantErr: qris.ErrGatewayUnreachable,
+		},
+		{
+			name: "timeout",
+			setup: func(_ *qristest.Server, c *qris.QRISConfig) {
+				c.GatewayURL = slow.URL
+				c.RequestTimeout = 50 * time.Millisecond
+			},
+			wantErr: qris.ErrGatewayTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newGateway(t)
+			q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { tt.setup(gw, c) })
+
+			err := q.HealthCheck(testContext(t, 5*time.Second))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("HealthCheck = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HealthCheck = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthCheckCachesResult(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	ctx := testContext(t, 5*time.Second)
+
+	gw.FailNext(1, http.StatusUnauthorized)
+	for i := 0; i < 3; i++ {
+		if err := q.HealthCheck(ctx); !errors.Is(err, qris.ErrGatewayUnauthorized) {
+			t.Fatalf("HealthCheck #%d = %v, want %v", i, err, qris.ErrGatewayUnauthorized)
+		}
+	}
+	if n := gw.RequestCount(); n != 1 {
+		t.Errorf("gateway requests = %d, want 1 within the TTL", n)
+	}
+}
+
+func TestHealthCheckAfterClose(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	ctx := testContext(t, 5*time.Second)
+
+	if err := q.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := q.HealthCheck(ctx); !errors.Is(err, qris.ErrClosed) {
+		t.Fatalf("HealthCheck = %v, want %v", err, qris.ErrClosed)
+	}
+	if n := gw.RequestCount(); n != 0 {
+		t.Errorf("gateway requests = %d, want 0", n)
+	}
+}
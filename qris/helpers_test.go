@@ -0,0 +1,131 @@
+package qris_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// update rewrites the golden files of testdata.
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testBaseQR is a static QRIS of a test merchant.
+const testBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+// testDynamicQR is testBaseQR turned into a dynamic QRIS of Rp 25.000.
+const testDynamicQR = "00020101021226600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605405250005802ID5915AutoFTbot Store6012Kota Jakarta61051234563047D44"
+
+// testPoll is the poll interval of test instances.
+const testPoll = 10 * time.Millisecond
+
+// newTestQRIS returns an instance reading mutations from gw, closed when the test ends.
+func newTestQRIS(t testing.TB, gw *qristest.Server, configure ...func(*qris.QRISConfig)) *qris.QRIS {
+	t.Helper()
+	config := qris.QRISConfig{
+		BaseQrString: testBaseQR,
+		AuthToken:    "token",
+		AuthUsername: "user",
+		GatewayURL:   gw.URL,
+		PollSchedule: qris.FixedSchedule(testPoll),
+		RandSource:   qristest.SeededRand(1),
+	}
+	for _, fn := range configure {
+		fn(&config)
+	}
+	q, err := qris.NewQRIS(config)
+	if err != nil {
+		t.Fatalf("NewQRIS: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		q.Close(ctx)
+	})
+	return q
+}
+
+// newGateway starts a fake gateway stopped when the test ends.
+func newGateway(t testing.TB) *qristest.Server {
+	t.Helper()
+	gw := qristest.NewServer()
+	t.Cleanup(gw.Close)
+	return gw
+}
+
+// staticGateway serves body to every request.
+func staticGateway(tb testing.TB, body []byte) *httptest.Server {
+	tb.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+// mutationFixture reads testdata/name, dating its rows now: "{{now}}" becomes the current time in WIB
+// and "{{earlier}}" a minute before.
+func mutationFixture(tb testing.TB, name string) []byte {
+	tb.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	now := time.Now().In(qris.WIB)
+	body = bytes.ReplaceAll(body, []byte("{{earlier}}"), []byte(now.Add(-time.Minute).Format("2006-01-02 15:04:05")))
+	return bytes.ReplaceAll(body, []byte("{{now}}"), []byte(now.Format("2006-01-02 15:04:05")))
+}
+
+// testContext returns a context cancelled after d or when the test ends.
+func testContext(t testing.TB, d time.Duration) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// checkGolden compares got with testdata/name, rewriting the file with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// rebuild parses s, lets edit change its fields, and encodes them with a fresh CRC.
+func rebuild(t *testing.T, s string, edit func([]qris.QRISField) []qris.QRISField) string {
+	t.Helper()
+	payload, err := emv.Parse(s)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	payload.Fields = edit(payload.Fields)
+	out, err := emv.Build(payload)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package qris
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatIDR formats a rupiah amount the Indonesian way, e.g. 1500000 as "Rp 1.500.000".
+// FormatIDR memformat nominal rupiah dengan cara Indonesia, misalnya 1500000 menjadi "Rp 1.500.000".
+func FormatIDR(amount int64) string {
+	sign := ""
+	u := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		u = uint64(-(amount + 1)) + 1
+	}
+
+	digits := strconv.FormatUint(u, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + "Rp " + b.String()
+}
+
+// idrSuffixes are the multipliers typed after amounts, longest first.
+// idrSuffixes adalah pengali yang diketik setelah nominal, dari yang terpanjang.
+var idrSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"juta", 1000000},
+	{"ribu", 1000},
+	{"jt", 1000000},
+	{"rb", 1000},
+	{"k", 1000},
+}
+
+// ParseIDR parses a user-typed rupiah amount such as "Rp 25.000", "150k", "150rb", or "1,5jt".
+// ParseIDR mem-parse nominal rupiah yang diketik pengguna seperti "Rp 25.000", "150k", "150rb", atau "1,5jt".
+//
+// Dots are thousand separators and a comma is the decimal separator; a single dot before
+// a suffix ("1.5jt") is read as a decimal point too. Negative and fractional rupiah are rejected.
+// Titik adalah pemisah ribuan dan koma adalah pemisah desimal; satu titik sebelum akhiran
+// ("1.5jt") juga dibaca sebagai titik desimal. Nominal negatif dan pecahan rupiah ditolak.
+func ParseIDR(s string) (int64, error) {
+	v := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	v = strings.TrimPrefix(v, "rp")
+	v = strings.TrimPrefix(v, ".")
+	if strings.HasPrefix(v, "-") {
+		return 0, fmt.Errorf("amount %q must not be negative / nominal %q tidak boleh negatif", s, s)
+	}
+
+	mult := int64(1)
+	for _, suf := range idrSuffixes {
+		if strings.HasSuffix(v, suf.suffix) {
+			v = strings.TrimSuffix(v, suf.suffix)
+			mult = suf.mult
+			break
+		}
+	}
+
+	intPart, frac := v, ""
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		intPart, frac = v[:i], v[i+1:]
+	} else if mult > 1 && strings.Count(v, ".") == 1 && len(v)-strings.IndexByte(v, '.')-1 != 3 {
+		i := strings.IndexByte(v, '.')
+		intPart, frac = v[:i], v[i+1:]
+	}
+
+	groups := strings.Split(intPart, ".")
+	for i, g := range groups {
+		if !isDigits(g) || (len(groups) > 1 && (len(g) > 3 || (i > 0 && len(g) != 3))) {
+			return 0, fmt.Errorf("invalid amount %q / nominal %q tidak valid", s, s)
+		}
+	}
+	if frac != "" && !isDigits(frac) {
+		return 0, fmt.Errorf("invalid amount %q / nominal %q tidak valid", s, s)
+	}
+
+	n, err := strconv.ParseInt(strings.Join(groups, ""), 10, 64)
+	if err != nil || n > math.MaxInt64/mult {
+		return 0, fmt.Errorf("amount %q is too large / nominal %q terlalu besar", s, s)
+	}
+	n *= mult
+
+	// Keep only the significant fraction digits, then require a whole rupiah
+	frac = strings.TrimRight(frac, "0")
+	if frac != "" {
+		if len(frac) > 6 {
+			return 0, fmt.Errorf("amount %q is not a whole rupiah / nominal %q bukan rupiah bulat", s, s)
+		}
+		f, _ := strconv.ParseInt(frac, 10, 64)
+		scale := int64(math.Pow10(len(frac)))
+		if (f*mult)%scale != 0 {
+			return 0, fmt.Errorf("amount %q is not a whole rupiah / nominal %q bukan rupiah bulat", s, s)
+		}
+		n += f * mult / scale
+	}
+	return n, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
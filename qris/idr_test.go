@@ -0,0 +1,112 @@
+package qris_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestFormatIDR(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "Rp 0"},
+		{5, "Rp 5"},
+		{999, "Rp 999"},
+		{1000, "Rp 1.000"},
+		{25000, "Rp 25.000"},
+		{150000, "Rp 150.000"},
+		{1500000, "Rp 1.500.000"},
+		{1000000000, "Rp 1.000.000.000"},
+		{-25000, "-Rp 25.000"},
+		{math.MaxInt64, "Rp 9.223.372.036.854.775.807"},
+		{math.MinInt64, "-Rp 9.223.372.036.854.775.808"},
+	}
+	for _, tt := range tests {
+		if got := qris.FormatIDR(tt.amount); got != tt.want {
+			t.Errorf("FormatIDR(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestParseIDR(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"0", 0},
+		{"25000", 25000},
+		{"25.000", 25000},
+		{"1.500.000", 1500000},
+		{"Rp 25.000", 25000},
+		{"Rp25.000", 25000},
+		{"rp 25.000", 25000},
+		{"Rp. 25.000", 25000},
+		{"  25 000 ", 25000},
+		{"25.000,00", 25000},
+		{"150k", 150000},
+		{"150K", 150000},
+		{"150 k", 150000},
+		{"150rb", 150000},
+		{"150 ribu", 150000},
+		{"2jt", 2000000},
+		{"2 juta", 2000000},
+		{"1,5jt", 1500000},
+		{"1.5jt", 1500000},
+		{"1,25jt", 1250000},
+		{"2,5k", 2500},
+		{"1.500k", 1500000},
+		{"Rp 1,5jt", 1500000},
+		{"0,001jt", 1000},
+		{"9.223.372.036.854.775.807", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := qris.ParseIDR(tt.input)
+		if err != nil {
+			t.Errorf("ParseIDR(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseIDR(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseIDRInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"Rp",
+		"abc",
+		"-25000",
+		"Rp -25.000",
+		"-1,5jt",
+		"25,5",
+		"1,0005k",
+		"0,0000001jt",
+		"25.00",
+		"2.50.000",
+		"1.2345",
+		"12,3,4",
+		"1,5x",
+		"25k5",
+		"k",
+		"10.000.000.000.000.000jt",
+		"9.223.372.036.854.775.808",
+	}
+	for _, input := range tests {
+		if got, err := qris.ParseIDR(input); err == nil {
+			t.Errorf("ParseIDR(%q) = %d, want an error", input, got)
+		}
+	}
+}
+
+func TestParseIDRRoundTrip(t *testing.T) {
+	for _, amount := range []int64{0, 1, 999, 1000, 25000, 1500000, 123456789} {
+		got, err := qris.ParseIDR(qris.FormatIDR(amount))
+		if err != nil || got != amount {
+			t.Errorf("ParseIDR(FormatIDR(%d)) = %d, %v", amount, got, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxTransactionIDLength is the longest transaction ID that fits the reference label of tag 62.
+// MaxTransactionIDLength adalah ID transaksi terpanjang yang muat di reference label tag 62.
+const MaxTransactionIDLength = 25
+
+// MaxNoteLength is the longest QRISData.Note, the length of the purpose of transaction in tag 62.
+// MaxNoteLength adalah QRISData.Note terpanjang, yaitu panjang tujuan transaksi di tag 62.
+const MaxNoteLength = 25
+
+var (
+	// ErrInvalidAmount is returned for a negative amount, or a zero amount of a fixed-amount QR code.
+	// ErrInvalidAmount dikembalikan untuk nominal negatif, atau nominal nol pada QR code nominal tetap.
+	ErrInvalidAmount = errors.New("amount must be greater than 0 / nominal harus lebih besar dari 0")
+
+	// ErrInvalidTransactionID is returned for an empty transaction ID or one with characters outside
+	// printable ASCII, which banks reject in tag 62.
+	// ErrInvalidTransactionID dikembalikan untuk ID transaksi kosong atau yang berisi karakter di luar
+	// ASCII yang dapat dicetak, yang ditolak bank di tag 62.
+	ErrInvalidTransactionID = errors.New("invalid transactionID / transactionID tidak valid")
+
+	// ErrTransactionIDTooLong is returned for a transaction ID longer than MaxTransactionIDLength,
+	// unless QRISData.TruncateIDs is set.
+	// ErrTransactionIDTooLong dikembalikan untuk ID transaksi yang lebih panjang dari MaxTransactionIDLength,
+	// kecuali QRISData.TruncateIDs diaktifkan.
+	ErrTransactionIDTooLong = errors.New("transactionID too long / transactionID terlalu panjang")
+
+	// ErrNoteTooLong is returned for a QRISData.Note longer than MaxNoteLength once cleaned, unless
+	// QRISData.TruncateNote is set.
+	// ErrNoteTooLong dikembalikan untuk QRISData.Note yang lebih panjang dari MaxNoteLength setelah
+	// dibersihkan, kecuali QRISData.TruncateNote diaktifkan.
+	ErrNoteTooLong = errors.New("note too long / catatan terlalu panjang")
+)
+
+// checkInput validates data before a QR code is generated and returns it with the transaction ID
+// trimmed, and truncated to MaxTransactionIDLength if TruncateIDs is set, and the note cleaned.
+// checkInput memvalidasi data sebelum QR code di-generate dan mengembalikannya dengan ID transaksi
+// yang di-trim, dan dipotong menjadi MaxTransactionIDLength jika TruncateIDs diaktifkan, serta catatan
+// yang sudah dibersihkan.
+func (q *QRIS) checkInput(data QRISData) (QRISData, error) {
+	if data.Amount < 0 || (data.Mode != ModeOpenAmount && data.Amount == 0) {
+		return data, fmt.Errorf("%w: %d", ErrInvalidAmount, data.Amount)
+	}
+	if data.Mode != ModeOpenAmount {
+		if err := q.checkAmount(data.Amount); err != nil {
+			return data, err
+		}
+	}
+
+	id, err := checkTransactionID(data.TransactionID, data.TruncateIDs)
+	if err != nil {
+		return data, err
+	}
+	data.TransactionID = id
+
+	if data.Note, err = checkNote(data.Note, data.TruncateNote); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// checkNote keeps the printable ASCII characters of note with runs of spaces collapsed, as issuer
+// apps show nothing else reliably, and checks that the result has at most MaxNoteLength characters,
+// truncating it if truncate is set.
+// checkNote menyimpan karakter ASCII yang dapat dicetak dari note dengan spasi berturut-turut
+// digabung, karena aplikasi issuer tidak menampilkan karakter lain dengan andal, dan memeriksa bahwa
+// hasilnya maksimal MaxNoteLength karakter, memotongnya jika truncate diaktifkan.
+func checkNote(note string, truncate bool) (string, error) {
+	var b strings.Builder
+	for _, r := range note {
+		switch {
+		case r >= 0x20 && r <= 0x7e:
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteByte(' ')
+		}
+	}
+	// Collapse after filtering, so dropped characters leave no double spaces
+	note = strings.Join(strings.Fields(b.String()), " ")
+	if len(note) > MaxNoteLength {
+		if !truncate {
+			return "", fmt.Errorf("%w: %d characters, at most %d / %d karakter, maksimal %d", ErrNoteTooLong,
+				len(note), MaxNoteLength, len(note), MaxNoteLength)
+		}
+		note = strings.TrimSpace(note[:MaxNoteLength])
+	}
+	return note, nil
+}
+
+// checkTransactionID trims surrounding spaces from id and checks that it is printable ASCII of at
+// most MaxTransactionIDLength characters, truncating longer IDs if truncate is set.
+// checkTransactionID menghapus spasi di awal dan akhir id dan memeriksa bahwa id berupa ASCII yang
+// dapat dicetak dengan panjang maksimal MaxTransactionIDLength karakter, memotong ID yang lebih panjang
+// jika truncate diaktifkan.
+func checkTransactionID(id string, truncate bool) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("%w: transactionID must be filled / transactionID harus diisi", ErrInvalidTransactionID)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x20 || id[i] > 0x7e {
+			return "", fmt.Errorf("%w: character at position %d is not printable ASCII / karakter di posisi %d bukan ASCII yang dapat dicetak",
+				ErrInvalidTransactionID, i, i)
+		}
+	}
+	if len(id) > MaxTransactionIDLength {
+		if !truncate {
+			return "", fmt.Errorf("%w: %d characters, at most %d / %d karakter, maksimal %d", ErrTransactionIDTooLong,
+				len(id), MaxTransactionIDLength, len(id), MaxTransactionIDLength)
+		}
+		id = strings.TrimSpace(id[:MaxTransactionIDLength])
+	}
+	return id, nil
+}
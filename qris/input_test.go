@@ -0,0 +1,69 @@
+package qris_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestBuildPayloadInput(t *testing.T) {
+	id := func(n int) string { return strings.Repeat("A", n) }
+	tests := []struct {
+		name    string
+		data    qris.QRISData
+		wantErr error
+		ref     string // expected reference label / reference label yang diharapkan
+		note    string // expected purpose of transaction / tujuan transaksi yang diharapkan
+	}{
+		{name: "amount -1", data: qris.QRISData{Amount: -1, TransactionID: "INV-1"}, wantErr: qris.ErrInvalidAmount},
+		{name: "amount 0 fixed", data: qris.QRISData{TransactionID: "INV-1"}, wantErr: qris.ErrInvalidAmount},
+		{name: "amount 0 open", data: qris.QRISData{TransactionID: "INV-1", Mode: qris.ModeOpenAmount}, ref: "INV-1"},
+		{name: "amount 1", data: qris.QRISData{Amount: 1, TransactionID: "INV-1"}, ref: "INV-1"},
+
+		{name: "ID 1", data: qris.QRISData{Amount: 1000, TransactionID: id(1)}, ref: id(1)},
+		{name: "ID 24", data: qris.QRISData{Amount: 1000, TransactionID: id(24)}, ref: id(24)},
+		{name: "ID 25", data: qris.QRISData{Amount: 1000, TransactionID: id(25)}, ref: id(25)},
+		{name: "ID 26", data: qris.QRISData{Amount: 1000, TransactionID: id(26)}, wantErr: qris.ErrTransactionIDTooLong},
+		{name: "ID 60", data: qris.QRISData{Amount: 1000, TransactionID: id(60)}, wantErr: qris.ErrTransactionIDTooLong},
+		{name: "ID 26 truncated", data: qris.QRISData{Amount: 1000, TransactionID: id(26), TruncateIDs: true}, ref: id(25)},
+		{name: "ID 60 truncated", data: qris.QRISData{Amount: 1000, TransactionID: id(60), TruncateIDs: true}, ref: id(25)},
+		{name: "ID 25 inside spaces", data: qris.QRISData{Amount: 1000, TransactionID: "  " + id(25) + "  "}, ref: id(25)},
+		{name: "ID empty", data: qris.QRISData{Amount: 1000}, wantErr: qris.ErrInvalidTransactionID},
+		{name: "ID spaces", data: qris.QRISData{Amount: 1000, TransactionID: "   "}, wantErr: qris.ErrInvalidTransactionID},
+		{name: "ID tab", data: qris.QRISData{Amount: 1000, TransactionID: "INV\t1"}, wantErr: qris.ErrInvalidTransactionID},
+		{name: "ID non-ASCII", data: qris.QRISData{Amount: 1000, TransactionID: "INVé1"}, wantErr: qris.ErrInvalidTransactionID},
+
+		{name: "note 25", data: qris.QRISData{Amount: 1000, TransactionID: "INV-1", Note: id(25)}, ref: "INV-1", note: id(25)},
+		{name: "note 26", data: qris.QRISData{Amount: 1000, TransactionID: "INV-1", Note: id(26)}, wantErr: qris.ErrNoteTooLong},
+		{name: "note 26 truncated", data: qris.QRISData{Amount: 1000, TransactionID: "INV-1", Note: id(26), TruncateNote: true}, ref: "INV-1", note: id(25)},
+		{name: "note 26 with a collapsed space", data: qris.QRISData{Amount: 1000, TransactionID: "INV-1", Note: id(12) + "  " + id(12)}, ref: "INV-1", note: id(12) + " " + id(12)},
+	}
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.MatchPolicy = qris.MatchByBuyerRef() })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := q.PreviewPayload(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if _, err := q.GenerateQRCode(tt.data); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GenerateQRCode err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PreviewPayload: %v", err)
+			}
+			f, _ := payload.Field("62")
+			ref, _ := f.SubField("05")
+			if ref.Value != tt.ref {
+				t.Errorf("reference label = %q, want %q", ref.Value, tt.ref)
+			}
+			if got := payload.Note(); got != tt.note {
+				t.Errorf("purpose = %q, want %q", got, tt.note)
+			}
+		})
+	}
+}
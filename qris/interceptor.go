@@ -0,0 +1,66 @@
+package qris
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+)
+
+// DefaultRequestIDHeader is the header set by RequestIDInterceptor when none is given.
+// DefaultRequestIDHeader adalah header yang diatur RequestIDInterceptor jika tidak diberikan.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// RoundTripFunc sends one HTTP request to the gateway.
+// RoundTripFunc mengirim satu request HTTP ke gateway.
+type RoundTripFunc = transport.RoundTripFunc
+
+// Interceptor wraps every gateway request. It sees each attempt separately, including retries,
+// and can read the caller's context through req.Context(). Interceptors run in the order they are
+// registered, the first one being the outermost.
+// Interceptor membungkus setiap request gateway. Interceptor melihat setiap percobaan secara terpisah,
+// termasuk pengulangan, dan dapat membaca context pemanggil melalui req.Context(). Interceptor dijalankan
+// sesuai urutan pendaftaran, yang pertama adalah yang paling luar.
+type Interceptor = transport.Interceptor
+
+// HeaderInterceptor sets header key to value on every request, e.g. a signature required by a panel.
+// HeaderInterceptor mengatur header key ke value pada setiap request, misalnya tanda tangan yang diwajibkan panel.
+func HeaderInterceptor(key, value string) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set(key, value)
+			return next(req)
+		}
+	}
+}
+
+// RequestIDInterceptor gives every request attempt a random ID in header (DefaultRequestIDHeader if empty),
+// unless an outer interceptor already set one.
+// RequestIDInterceptor memberi setiap percobaan request ID acak di header (DefaultRequestIDHeader jika kosong),
+// kecuali interceptor luar sudah mengaturnya.
+func RequestIDInterceptor(header string) Interceptor {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				b := make([]byte, 8)
+				if _, err := rand.Read(b); err != nil {
+					return nil, err
+				}
+				req = req.Clone(req.Context())
+				req.Header.Set(header, hex.EncodeToString(b))
+			}
+			return next(req)
+		}
+	}
+}
+
+// send sends req through the configured interceptors, waiting out gateway rate limits (see sendWithQuota).
+// send mengirim req melalui interceptor yang dikonfigurasi, menunggu batas laju gateway (lihat sendWithQuota).
+func (q *QRIS) send(req *http.Request) (*http.Response, error) {
+	return q.sendWithQuota(req, transport.Chain(q.client.Do, q.config.Interceptors))
+}
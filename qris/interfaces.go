@@ -0,0 +1,38 @@
+package qris
+
+import (
+	"context"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QRGenerator builds QRIS payloads and QR codes. *QRIS implements it; accept it instead of *QRIS
+// in code that only renders QR codes, so its tests can pass a fake.
+// QRGenerator membuat payload QRIS dan QR code. *QRIS mengimplementasikannya; terima interface ini
+// alih-alih *QRIS di kode yang hanya menampilkan QR code, sehingga test-nya dapat memakai tiruan.
+type QRGenerator interface {
+	GenerateQRCode(data QRISData) (*qrcode.QRCode, error)
+	BuildPayload(data QRISData) (string, error)
+}
+
+// PaymentStatusChecker checks and waits for payments by reference and amount. *QRIS implements
+// it, as does qristest.StubChecker.
+// PaymentStatusChecker mengecek dan menunggu pembayaran berdasarkan reference dan nominal. *QRIS
+// mengimplementasikannya, begitu juga qristest.StubChecker.
+type PaymentStatusChecker interface {
+	CheckPaymentStatus(reference string, amount int64) (*PaymentStatus, error)
+	CheckPaymentStatusContext(ctx context.Context, reference string, amount int64) (*PaymentStatus, error)
+	WaitForPayment(ctx context.Context, reference string, amount int64, opts ...WaitOption) (*PaymentStatus, error)
+}
+
+// MutationReader reads the account mutation history. *QRIS implements it.
+// MutationReader membaca riwayat mutasi rekening. *QRIS mengimplementasikannya.
+type MutationReader interface {
+	GetMutations(ctx context.Context, query MutationQuery) ([]Mutation, error)
+}
+
+var (
+	_ QRGenerator          = (*QRIS)(nil)
+	_ PaymentStatusChecker = (*QRIS)(nil)
+	_ MutationReader       = (*QRIS)(nil)
+)
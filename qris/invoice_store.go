@@ -0,0 +1,120 @@
+package qris
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Invoice is the persisted form of a PaymentSession.
+// Invoice adalah bentuk tersimpan dari PaymentSession.
+type Invoice struct {
+	TransactionID string            `json:"transaction_id"`     // Transaction ID / ID transaksi
+	Merchant      string            `json:"merchant,omitempty"` // Manager profile name, if any / Nama profil Manager, jika ada
+	BaseAmount    int64             `json:"base_amount"`        // Requested amount / Nominal yang diminta
+	Amount        int64             `json:"amount"`             // Unique payable amount / Nominal unik yang harus dibayar
+	QRString      string            `json:"qr_string"`          // Encoded QRIS string / String QRIS yang di-encode
+	Status        string            `json:"status"`             // Invoice status / Status invoice
+	CreatedAt     time.Time         `json:"created_at"`         // Creation time / Waktu pembuatan
+	ExpiresAt     time.Time         `json:"expires_at"`         // Expiry time / Waktu kedaluwarsa
+	Payment       *PaymentStatus    `json:"payment,omitempty"`  // Matched payment (if PAID) / Pembayaran yang cocok (jika PAID)
+	Code          string            `json:"code,omitempty"`     // Payment link code, see PaymentSession.Code / Kode link pembayaran, lihat PaymentSession.Code
+	Metadata      map[string]string `json:"metadata,omitempty"` // Metadata of WithMetadata / Metadata dari WithMetadata
+}
+
+// InvoiceStore persists PaymentSessions so pending invoices survive a restart.
+// InvoiceStore menyimpan PaymentSession agar invoice yang menunggu tetap ada setelah restart.
+//
+// Implementations must be safe for concurrent use.
+// Implementasi harus aman dipakai secara bersamaan.
+type InvoiceStore interface {
+	// Save inserts or replaces an invoice.
+	// Save menambahkan atau mengganti invoice.
+	Save(ctx context.Context, inv Invoice) error
+
+	// LoadPending returns all UNPAID invoices ordered by creation time.
+	// LoadPending mengembalikan semua invoice UNPAID berurutan sesuai waktu pembuatan.
+	LoadPending(ctx context.Context) ([]Invoice, error)
+
+	// MarkPaid marks an invoice as PAID with the matched payment. Marking an invoice that is
+	// already PAID is a no-op, so the first payment is never overwritten by a duplicate.
+	// MarkPaid menandai invoice sebagai PAID beserta pembayaran yang cocok. Menandai invoice yang
+	// sudah PAID tidak berpengaruh, sehingga pembayaran pertama tidak tertimpa oleh duplikat.
+	MarkPaid(ctx context.Context, transactionID string, payment *PaymentStatus) error
+
+	// MarkExpired marks an UNPAID invoice as EXPIRED; other invoices are left unchanged.
+	// MarkExpired menandai invoice UNPAID sebagai EXPIRED; invoice lain tidak diubah.
+	MarkExpired(ctx context.Context, transactionID string) error
+}
+
+// InvoiceLoader is implemented by invoice stores that load one invoice by transaction ID, so
+// CheckPaymentStatus and WaitForPayment can scope a check to the invoice of its reference.
+// JSONFileStore and SQLiteStore implement it.
+// InvoiceLoader diimplementasikan oleh invoice store yang memuat satu invoice berdasarkan ID
+// transaksi, sehingga CheckPaymentStatus dan WaitForPayment dapat membatasi pengecekan ke invoice
+// dari reference-nya. JSONFileStore dan SQLiteStore mengimplementasikannya.
+type InvoiceLoader interface {
+	// LoadInvoice returns the invoice transactionID, or an error wrapping ErrNotFound.
+	// LoadInvoice mengembalikan invoice transactionID, atau error yang membungkus ErrNotFound.
+	LoadInvoice(ctx context.Context, transactionID string) (Invoice, error)
+}
+
+// ResumePending reloads pending invoices from store and restarts their watchers.
+// ResumePending memuat ulang invoice yang menunggu dari store dan menjalankan ulang watcher-nya.
+//
+// Resumed sessions keep their original creation time, so mutations made while the process
+// was down still match. Invoices that expired in the meantime are marked EXPIRED and skipped.
+// Sesi yang dilanjutkan tetap memakai waktu pembuatan aslinya, sehingga mutasi yang terjadi
+// saat proses mati tetap cocok. Invoice yang sudah kedaluwarsa ditandai EXPIRED dan dilewati.
+func (q *QRIS) ResumePending(ctx context.Context, store InvoiceStore) ([]*PaymentSession, error) {
+	if err := q.life.err(); err != nil {
+		return nil, err
+	}
+
+	invoices, err := store.LoadPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending invoices / gagal memuat invoice yang menunggu: %v", err)
+	}
+
+	var sessions []*PaymentSession
+	now := time.Now()
+	for _, inv := range invoices {
+		// A store shared through a Manager holds invoices of other merchants too
+		if inv.Merchant != q.merchant {
+			continue
+		}
+
+		if !now.Before(inv.ExpiresAt) {
+			if err := store.MarkExpired(ctx, inv.TransactionID); err != nil {
+				return sessions, fmt.Errorf("failed to mark invoice expired / gagal menandai invoice kedaluwarsa: %v", err)
+			}
+			continue
+		}
+
+		if _, err := q.amounts.reserve(inv.Amount, 0, inv.TransactionID); err != nil {
+			logf(ctx, "Amount %d of invoice %s is already reserved, resuming anyway", inv.Amount, inv.TransactionID)
+		}
+
+		qrCode, err := q.newQRCode(inv.QRString)
+		if err != nil {
+			q.amounts.release(inv.Amount, inv.TransactionID)
+			return sessions, err
+		}
+
+		// Invoices saved before payment links existed get their code now
+		if inv.Code == "" {
+			if inv.Code, err = q.mintCode(ctx, inv.TransactionID, inv.Amount, inv.ExpiresAt, store); err == nil {
+				err = store.Save(ctx, inv)
+			}
+			if err != nil {
+				logf(ctx, "Failed to give invoice %s a payment code: %v", inv.TransactionID, err)
+				inv.Code = ""
+			}
+		}
+
+		s := q.newSession(ctx, inv, qrCode, q.pollSchedule(), store)
+		s.startWatcher()
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,101 @@
+package qris_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestResumePendingAfterRestart(t *testing.T) {
+	gw := newGateway(t)
+	path := filepath.Join(t.TempDir(), "invoices.json")
+
+	store, err := qris.NewJSONFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.InvoiceStore = store })
+	ctx := testContext(t, 5*time.Second)
+	session, err := first.CreatePayment(ctx, 10000, qris.WithTransactionID("ORDER1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := session.CreatedAt
+	amount := session.Amount()
+	if err := first.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The payment arrives while no process is running
+	gw.AddMutation(amount, time.Now())
+
+	reopened, err := qris.NewJSONFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.InvoiceStore = reopened })
+	sessions, err := second.ResumePending(ctx, reopened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("resumed %d sessions, want 1", len(sessions))
+	}
+	resumed := sessions[0]
+	if resumed.TransactionID != "ORDER1" || resumed.Amount() != amount || !resumed.CreatedAt.Equal(createdAt) {
+		t.Fatalf("resumed %s amount %d created %v, want ORDER1 amount %d created %v",
+			resumed.TransactionID, resumed.Amount(), resumed.CreatedAt, amount, createdAt)
+	}
+
+	status, err := resumed.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != qris.StatusPaid {
+		t.Fatalf("status %s, want PAID", status.Status)
+	}
+	pending, err := reopened.LoadPending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("%d invoices still pending after payment", len(pending))
+	}
+}
+
+func TestResumePendingSkipsExpired(t *testing.T) {
+	gw := newGateway(t)
+	store, err := qris.NewJSONFileStore(filepath.Join(t.TempDir(), "invoices.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := testContext(t, 5*time.Second)
+	now := time.Now()
+	invoices := []qris.Invoice{
+		{TransactionID: "LIVE", BaseAmount: 5000, Amount: 5012, QRString: testBaseQR, Status: qris.StatusUnpaid, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{TransactionID: "OLD", BaseAmount: 5000, Amount: 5034, QRString: testBaseQR, Status: qris.StatusUnpaid, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}
+	for _, inv := range invoices {
+		if err := store.Save(ctx, inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := newTestQRIS(t, gw)
+	sessions, err := q.ResumePending(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].TransactionID != "LIVE" {
+		t.Fatalf("resumed %v, want only LIVE", sessions)
+	}
+	old, err := store.LoadInvoice(ctx, "OLD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Status != qris.StatusExpired {
+		t.Fatalf("OLD has status %s, want EXPIRED", old.Status)
+	}
+}
@@ -0,0 +1,127 @@
+package qris
+
+import (
+	"strings"
+	"sync"
+)
+
+// Issuer is the canonical payer issuer of a payment, derived from the gateway brand_name.
+// Issuer adalah issuer pembayar kanonis dari sebuah pembayaran, diturunkan dari brand_name gateway.
+type Issuer string
+
+// Canonical issuers returned by NormalizeIssuer.
+// Issuer kanonis yang dikembalikan NormalizeIssuer.
+const (
+	IssuerBCA       Issuer = "BCA"
+	IssuerBRI       Issuer = "BRI"
+	IssuerMandiri   Issuer = "MANDIRI"
+	IssuerBNI       Issuer = "BNI"
+	IssuerGoPay     Issuer = "GOPAY"
+	IssuerOVO       Issuer = "OVO"
+	IssuerDana      Issuer = "DANA"
+	IssuerShopeePay Issuer = "SHOPEEPAY"
+	IssuerLinkAja   Issuer = "LINKAJA"
+	IssuerOther     Issuer = "OTHER" // Unknown or empty brand / Brand tidak dikenal atau kosong
+)
+
+// minContainedAlias is the shortest alias matched inside a longer brand name; shorter aliases such
+// as "BCA" only match the whole brand, so they do not match by accident.
+// minContainedAlias adalah alias terpendek yang dicocokkan di dalam nama brand yang lebih panjang; alias
+// yang lebih pendek seperti "BCA" hanya cocok dengan seluruh brand, agar tidak cocok secara kebetulan.
+const minContainedAlias = 5
+
+var issuerAliases = struct {
+	sync.RWMutex
+	m map[string]Issuer
+}{m: map[string]Issuer{
+	"BCA":                   IssuerBCA,
+	"BANKCENTRALASIA":       IssuerBCA,
+	"BCAMOBILE":             IssuerBCA,
+	"BRI":                   IssuerBRI,
+	"BANKRAKYATINDONESIA":   IssuerBRI,
+	"BRIMO":                 IssuerBRI,
+	"MANDIRI":               IssuerMandiri,
+	"BANKMANDIRI":           IssuerMandiri,
+	"LIVIN":                 IssuerMandiri,
+	"BNI":                   IssuerBNI,
+	"BANKNEGARAINDONESIA":   IssuerBNI,
+	"GOPAY":                 IssuerGoPay,
+	"GOJEK":                 IssuerGoPay,
+	"OVO":                   IssuerOVO,
+	"VISIONETINTERNASIONAL": IssuerOVO,
+	"DANA":                  IssuerDana,
+	"ESPAYDEBITINDONESIA":   IssuerDana,
+	"SHOPEEPAY":             IssuerShopeePay,
+	"AIRPAY":                IssuerShopeePay,
+	"LINKAJA":               IssuerLinkAja,
+	"FINNET":                IssuerLinkAja,
+}}
+
+// issuerKey reduces a brand name to upper-case letters and digits, so "GO-PAY" and "GoPay" share a key.
+// issuerKey menyederhanakan nama brand menjadi huruf kapital dan angka, sehingga "GO-PAY" dan "GoPay" memiliki key yang sama.
+func issuerKey(brand string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(brand) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NormalizeIssuer maps a raw gateway brand name to its canonical Issuer. Case, spaces, and
+// punctuation are ignored, and aliases of at least five characters also match inside a longer
+// name such as "PT BANK CENTRAL ASIA TBK". Unknown and empty names return IssuerOther.
+// NormalizeIssuer memetakan nama brand mentah dari gateway ke Issuer kanonis. Huruf besar/kecil, spasi,
+// dan tanda baca diabaikan, dan alias minimal lima karakter juga cocok di dalam nama yang lebih panjang
+// seperti "PT BANK CENTRAL ASIA TBK". Nama yang tidak dikenal dan kosong mengembalikan IssuerOther.
+func NormalizeIssuer(brand string) Issuer {
+	key := issuerKey(brand)
+	if key == "" {
+		return IssuerOther
+	}
+
+	issuerAliases.RLock()
+	defer issuerAliases.RUnlock()
+	if issuer, ok := issuerAliases.m[key]; ok {
+		return issuer
+	}
+	best, issuer := "", IssuerOther
+	for alias, candidate := range issuerAliases.m {
+		if len(alias) < minContainedAlias || !strings.Contains(key, alias) {
+			continue
+		}
+		if len(alias) > len(best) || (len(alias) == len(best) && alias < best) {
+			best, issuer = alias, candidate
+		}
+	}
+	return issuer
+}
+
+// RegisterIssuerAlias maps brand to issuer in NormalizeIssuer, replacing any existing mapping. The
+// issuer need not be one of the predefined constants. It is safe to call while payments are checked.
+// RegisterIssuerAlias memetakan brand ke issuer dalam NormalizeIssuer, menggantikan pemetaan yang ada.
+// Issuer tidak harus salah satu konstanta bawaan. Aman dipanggil saat pembayaran sedang dicek.
+func RegisterIssuerAlias(brand string, issuer Issuer) {
+	key := issuerKey(brand)
+	if key == "" {
+		return
+	}
+	issuerAliases.Lock()
+	issuerAliases.m[key] = issuer
+	issuerAliases.Unlock()
+}
+
+// IssuerAliases returns a copy of the alias table used by NormalizeIssuer, keyed by the brand reduced
+// to upper-case letters and digits.
+// IssuerAliases mengembalikan salinan tabel alias yang dipakai NormalizeIssuer, dengan key berupa brand
+// yang disederhanakan menjadi huruf kapital dan angka.
+func IssuerAliases() map[string]Issuer {
+	issuerAliases.RLock()
+	defer issuerAliases.RUnlock()
+	aliases := make(map[string]Issuer, len(issuerAliases.m))
+	for k, v := range issuerAliases.m {
+		aliases[k] = v
+	}
+	return aliases
+}
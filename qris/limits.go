@@ -0,0 +1,43 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Practical QRIS amount limits of static merchants. They are not applied unless set on
+// QRISConfig.MinAmount and QRISConfig.MaxAmount, where zero means no limit.
+// Batas nominal QRIS yang umum untuk merchant statis. Batas ini tidak diterapkan kecuali diatur
+// di QRISConfig.MinAmount dan QRISConfig.MaxAmount, di mana nol berarti tanpa batas.
+const (
+	DefaultMinAmount int64 = 1
+	DefaultMaxAmount int64 = 10000000
+)
+
+// ErrAmountOutOfRange is returned when an amount is outside QRISConfig.MinAmount and MaxAmount.
+// ErrAmountOutOfRange dikembalikan jika nominal berada di luar QRISConfig.MinAmount dan MaxAmount.
+var ErrAmountOutOfRange = errors.New("amount out of range / nominal di luar batas")
+
+// checkAmount enforces the configured amount limits.
+// checkAmount menerapkan batas nominal yang dikonfigurasi.
+func (q *QRIS) checkAmount(amount int64) error {
+	lower, upper := q.config.MinAmount, q.config.MaxAmount
+	if (lower > 0 && amount < lower) || (upper > 0 && amount > upper) {
+		return fmt.Errorf("%w: %s not within %s / %s tidak di antara %s", ErrAmountOutOfRange,
+			FormatIDR(amount), limitRange(lower, upper), FormatIDR(amount), limitRange(lower, upper))
+	}
+	return nil
+}
+
+// limitRange describes the limits for error messages, e.g. "Rp 1 .. Rp 10.000.000".
+// limitRange menjelaskan batas untuk pesan error, misalnya "Rp 1 .. Rp 10.000.000".
+func limitRange(lower, upper int64) string {
+	lo, hi := "-", "-"
+	if lower > 0 {
+		lo = FormatIDR(lower)
+	}
+	if upper > 0 {
+		hi = FormatIDR(upper)
+	}
+	return lo + " .. " + hi
+}
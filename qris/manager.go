@@ -0,0 +1,121 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+)
+
+// MerchantProfile stores the merchant-specific part of a QRISConfig.
+// MerchantProfile menyimpan bagian QRISConfig yang khusus untuk satu merchant.
+type MerchantProfile struct {
+	BaseQrString string // Base QRIS string from merchant / Base QRIS string dari merchant
+	AuthToken    string // Authentication token for API calls / Token autentikasi untuk panggilan API
+	AuthUsername string // Authentication username for API calls / Username autentikasi untuk panggilan API
+}
+
+// Manager serves several merchants from one set of shared infrastructure.
+// Manager melayani beberapa merchant dengan satu set infrastruktur bersama.
+//
+// Every profile gets its own QRIS instance, so amount reservations, sessions, and
+// fetched mutations are never shared between merchants. Manager is safe for concurrent use.
+// Setiap profil mendapat instance QRIS sendiri, sehingga pemesanan nominal, sesi, dan
+// mutasi yang diambil tidak pernah tercampur antar merchant. Manager aman dipakai bersamaan.
+type Manager struct {
+	shared    QRISConfig
+	client    *http.Client
+	clientErr error // Why the shared client could not be built, reported by AddProfile / Alasan client bersama gagal dibuat, dilaporkan oleh AddProfile
+
+	mu       sync.RWMutex
+	profiles map[string]*QRIS
+}
+
+// NewManager creates a Manager whose profiles inherit every setting of shared except the
+// BaseQrString, AuthToken, and AuthUsername fields, which come from each MerchantProfile.
+// NewManager membuat Manager yang profilnya mewarisi semua pengaturan shared kecuali
+// BaseQrString, AuthToken, dan AuthUsername, yang diambil dari setiap MerchantProfile.
+//
+// Every profile sends its requests through one client built from the ProxyURL, TLSConfig, and
+// RootCAs of shared; when they are invalid, AddProfile reports it and no profile is added.
+// Setiap profil mengirim request lewat satu client yang dibuat dari ProxyURL, TLSConfig, dan
+// RootCAs milik shared; jika tidak valid, AddProfile melaporkannya dan tidak ada profil yang ditambahkan.
+func NewManager(shared QRISConfig) *Manager {
+	client, err := transport.NewClient(shared.transport())
+	return &Manager{
+		shared:    shared,
+		client:    client,
+		clientErr: err,
+		profiles:  make(map[string]*QRIS),
+	}
+}
+
+// AddProfile validates and registers a merchant profile, replacing any profile with the same name.
+// AddProfile memvalidasi dan mendaftarkan profil merchant, menggantikan profil dengan nama yang sama.
+//
+// Sessions created from a replaced profile keep running with the old credentials.
+// Sesi yang dibuat dari profil yang diganti tetap berjalan dengan kredensial lama.
+func (m *Manager) AddProfile(name string, profile MerchantProfile) error {
+	if name == "" {
+		return errors.New("profile name must be filled / nama profil harus diisi")
+	}
+
+	config := m.shared
+	config.BaseQrString = profile.BaseQrString
+	config.AuthToken = profile.AuthToken
+	config.AuthUsername = profile.AuthUsername
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid profile %s / profil %s tidak valid: %w", name, name, err)
+	}
+	if m.clientErr != nil {
+		return fmt.Errorf("invalid profile %s / profil %s tidak valid: %w", name, name, m.clientErr)
+	}
+
+	q := newQRIS(config, m.client)
+	q.merchant = name
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[name] = q
+	return nil
+}
+
+// RemoveProfile unregisters a merchant profile. Running sessions are not cancelled.
+// RemoveProfile menghapus profil merchant. Sesi yang sedang berjalan tidak dibatalkan.
+func (m *Manager) RemoveProfile(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.profiles, name)
+}
+
+// For returns the QRIS instance bound to the named profile.
+// For mengembalikan instance QRIS yang terikat ke profil dengan nama tersebut.
+func (m *Manager) For(name string) (*QRIS, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	q, ok := m.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %s / profil %s tidak dikenal", name, name)
+	}
+	return q, nil
+}
+
+// Profiles returns the names of all registered profiles in sorted order.
+// Profiles mengembalikan nama semua profil yang terdaftar secara berurutan.
+func (m *Manager) Profiles() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.profiles))
+	for name := range m.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
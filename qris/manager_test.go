@@ -0,0 +1,141 @@
+package qris_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// newTestManager returns a Manager reading gw with profiles a and b, whose instances are closed when the test ends.
+func newTestManager(t *testing.T, gw *qristest.Server, shared qris.QRISConfig) *qris.Manager {
+	t.Helper()
+	shared.GatewayURL = gw.URL
+	shared.PollSchedule = qris.FixedSchedule(testPoll)
+	m := qris.NewManager(shared)
+	for _, name := range []string{"a", "b"} {
+		if err := m.AddProfile(name, qris.MerchantProfile{BaseQrString: testBaseQR, AuthToken: "token-" + name, AuthUsername: "user-" + name}); err != nil {
+			t.Fatalf("AddProfile(%s): %v", name, err)
+		}
+		q, _ := m.For(name)
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			q.Close(ctx)
+		})
+	}
+	return m
+}
+
+func TestManagerIsolation(t *testing.T) {
+	gw := newGateway(t)
+	m := newTestManager(t, gw, qris.QRISConfig{DeterministicAmounts: true})
+	a, err := m.For("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.For("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Profiles(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("Profiles = %v, want [a b]", got)
+	}
+
+	// The same transaction ID gives both merchants the same amount
+	ctx := testContext(t, 5*time.Second)
+	sa, err := a.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := b.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa.Amount() != sb.Amount() {
+		t.Fatalf("amounts %d and %d, want the same", sa.Amount(), sb.Amount())
+	}
+
+	// Paid to the account of merchant a only
+	gw.AddMutation(sa.Amount(), time.Now(), qristest.ForAccount("user-a"))
+	status, err := sa.Wait(ctx)
+	if err != nil || status.Status != qris.StatusPaid {
+		t.Fatalf("session of a = %+v, %v, want PAID", status, err)
+	}
+	for i := 0; i < 3; i++ {
+		status, err := sb.Status(ctx)
+		if err != nil || status.Status != qris.StatusUnpaid {
+			t.Fatalf("session of b = %+v, %v, want UNPAID", status, err)
+		}
+		time.Sleep(testPoll)
+	}
+	if status, err := b.CheckPaymentStatusContext(ctx, "INV-2", 25000); err != nil || status.Status != qris.StatusUnpaid {
+		t.Fatalf("amount check of b = %+v, %v, want UNPAID", status, err)
+	}
+}
+
+func TestManagerAddProfileInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		shared   qris.QRISConfig
+		merchant qris.MerchantProfile
+		wantErr  string
+	}{
+		{
+			name:     "no name",
+			merchant: qris.MerchantProfile{BaseQrString: testBaseQR, AuthToken: "token", AuthUsername: "user"},
+			wantErr:  "name",
+		},
+		{
+			name:     "missing token",
+			profile:  "a",
+			merchant: qris.MerchantProfile{BaseQrString: testBaseQR, AuthUsername: "user"},
+			wantErr:  "authToken",
+		},
+		{
+			name:     "bad proxy URL",
+			profile:  "a",
+			shared:   qris.QRISConfig{ProxyURL: "ftp://proxy.example:21"},
+			merchant: qris.MerchantProfile{BaseQrString: testBaseQR, AuthToken: "token", AuthUsername: "user"},
+			wantErr:  "proxy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := qris.NewManager(tt.shared)
+			err := m.AddProfile(tt.profile, tt.merchant)
+			if err == nil || !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(tt.wantErr)) {
+				t.Fatalf("AddProfile err = %v, want one about %s", err, tt.wantErr)
+			}
+			if got := m.Profiles(); len(got) != 0 {
+				t.Fatalf("Profiles = %v after a rejected profile", got)
+			}
+			if _, err := m.For(tt.profile); err == nil {
+				t.Fatal("For of a rejected profile succeeded")
+			}
+		})
+	}
+}
+
+func TestManagerSharedProxy(t *testing.T) {
+	proxy := newRelayProxy(t)
+	gw := newGateway(t)
+	m := newTestManager(t, gw, qris.QRISConfig{ProxyURL: proxy.URL})
+
+	for _, name := range []string{"a", "b"} {
+		q, err := m.For(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "INV-1", 10001); err != nil {
+			t.Fatalf("CheckPaymentStatus of %s: %v", name, err)
+		}
+	}
+	if relayed := proxy.urls(); len(relayed) != 2 {
+		t.Fatalf("proxy relayed %q, want both profiles", relayed)
+	}
+}
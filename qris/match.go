@@ -0,0 +1,240 @@
+package qris
+
+import (
+	"strings"
+)
+
+// MatchPolicy decides whether a mutation pays an invoice.
+// MatchPolicy menentukan apakah sebuah mutasi membayar sebuah invoice.
+//
+// CheckPaymentStatus and PaymentSession hand every credit dated inside the match window to the
+// policy. Invoice.CreatedAt is the start of that window; Invoice.Amount is the payable amount.
+// CheckPaymentStatus dan PaymentSession meneruskan setiap kredit yang bertanggal di dalam rentang
+// pencarian ke policy. Invoice.CreatedAt adalah awal rentang tersebut; Invoice.Amount adalah nominal yang harus dibayar.
+type MatchPolicy interface {
+	Match(invoice Invoice, m Mutation) MatchResult
+}
+
+// MatchResult is the decision of a MatchPolicy for one mutation.
+// MatchResult adalah keputusan MatchPolicy untuk satu mutasi.
+type MatchResult struct {
+	Matched bool   // The mutation pays the invoice / Mutasi membayar invoice
+	Score   int    // Higher scores win when several mutations match; ties go to the latest / Skor lebih tinggi menang jika beberapa mutasi cocok; jika sama, yang terbaru
+	Reason  string // Why the mutation did or did not match / Alasan mutasi cocok atau tidak
+}
+
+// MatchPolicyFunc adapts an ordinary function to a MatchPolicy.
+// MatchPolicyFunc mengubah fungsi biasa menjadi MatchPolicy.
+type MatchPolicyFunc func(invoice Invoice, m Mutation) MatchResult
+
+// Match calls f(invoice, m).
+// Match memanggil f(invoice, m).
+func (f MatchPolicyFunc) Match(invoice Invoice, m Mutation) MatchResult {
+	return f(invoice, m)
+}
+
+// QRIS types reported in Mutation.QRIS, and QRISTypeAny to accept every type in QRISConfig.QRISTypes.
+// Tipe QRIS yang dilaporkan di Mutation.QRIS, dan QRISTypeAny untuk menerima semua tipe di QRISConfig.QRISTypes.
+const (
+	QRISTypeStatic  = "static"
+	QRISTypeDynamic = "dynamic"
+	QRISTypeAny     = "any"
+)
+
+// DefaultMatchPolicy matches a static QRIS credit of exactly the invoice amount.
+// DefaultMatchPolicy mencocokkan kredit QRIS statis dengan nominal persis sama dengan invoice.
+func DefaultMatchPolicy() MatchPolicy {
+	return amountPolicy{types: []string{QRISTypeStatic}}
+}
+
+// MatchDynamicQRIS matches a dynamic QRIS credit of exactly the invoice amount.
+// MatchDynamicQRIS mencocokkan kredit QRIS dinamis dengan nominal persis sama dengan invoice.
+func MatchDynamicQRIS() MatchPolicy {
+	return amountPolicy{types: []string{QRISTypeDynamic}}
+}
+
+// MatchQRISTypes matches credits of the given QRIS types ("static", "dynamic", or "any"); no types
+// means static only. When dynamic credits are accepted, amounts alone are ambiguous, so QR codes carry
+// the transaction ID in tag 62 (reference label) and a credit echoing it as buyer_reff wins over one
+// that only matches the amount.
+// MatchQRISTypes mencocokkan kredit dengan tipe QRIS yang diberikan ("static", "dynamic", atau "any");
+// tanpa tipe berarti hanya statis. Jika kredit dinamis diterima, nominal saja tidak cukup, sehingga QR code
+// membawa ID transaksi di tag 62 (reference label) dan kredit yang mengembalikannya sebagai buyer_reff
+// menang atas kredit yang hanya cocok nominalnya.
+func MatchQRISTypes(types ...string) MatchPolicy {
+	if len(types) == 0 {
+		return DefaultMatchPolicy()
+	}
+	amount := amountPolicy{types: types}
+	if !acceptsQRISType(types, QRISTypeDynamic) {
+		return amount
+	}
+	return referenceFirstPolicy{amount: amount}
+}
+
+// MatchWithTolerance matches a static QRIS credit whose amount differs from the invoice
+// by at most tolerance rupiah. Closer amounts score higher.
+// MatchWithTolerance mencocokkan kredit QRIS statis yang nominalnya berbeda dari invoice
+// paling banyak tolerance rupiah. Nominal yang lebih dekat mendapat skor lebih tinggi.
+func MatchWithTolerance(tolerance int64) MatchPolicy {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	return amountPolicy{types: []string{QRISTypeStatic}, tolerance: tolerance}
+}
+
+// MatchByBuyerRef matches a credit whose buyer reference contains the transaction ID.
+// QR codes generated under this policy carry the transaction ID in tag 62 (reference label),
+// so most e-wallets echo it back as buyer_reff.
+// MatchByBuyerRef mencocokkan kredit yang referensi pembelinya memuat ID transaksi.
+// QR code yang di-generate dengan policy ini membawa ID transaksi di tag 62 (reference label),
+// sehingga sebagian besar e-wallet mengembalikannya sebagai buyer_reff.
+func MatchByBuyerRef() MatchPolicy {
+	return buyerRefPolicy{}
+}
+
+// amountPolicy matches credits by QRIS type and amount.
+// amountPolicy mencocokkan kredit berdasarkan tipe QRIS dan nominal.
+type amountPolicy struct {
+	types     []string
+	tolerance int64
+}
+
+func (p amountPolicy) Match(inv Invoice, m Mutation) MatchResult {
+	if r, ok := credited(inv, m); !ok {
+		return r
+	}
+	if !acceptsQRISType(p.types, m.QRIS) {
+		return wrongQRISType(p.types)
+	}
+
+	diff := m.Amount - inv.Amount
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > p.tolerance {
+		return MatchResult{Reason: "amount differs / nominal berbeda"}
+	}
+	return MatchResult{Matched: true, Score: -int(diff), Reason: "amount matches / nominal cocok"}
+}
+
+func (amountPolicy) topScore() int { return 0 }
+
+// buyerRefPolicy matches credits whose buyer reference carries the transaction ID.
+// buyerRefPolicy mencocokkan kredit yang referensi pembelinya membawa ID transaksi.
+type buyerRefPolicy struct{}
+
+func (buyerRefPolicy) Match(inv Invoice, m Mutation) MatchResult {
+	if r, ok := credited(inv, m); !ok {
+		return r
+	}
+	if inv.TransactionID == "" || !strings.Contains(strings.ToUpper(m.BuyerRef), strings.ToUpper(inv.TransactionID)) {
+		return MatchResult{Reason: "buyer reference differs / referensi pembeli berbeda"}
+	}
+	if inv.Amount > 0 && m.Amount != inv.Amount {
+		return MatchResult{Reason: "amount differs / nominal berbeda"}
+	}
+	return MatchResult{Matched: true, Reason: "buyer reference matches / referensi pembeli cocok"}
+}
+
+func (buyerRefPolicy) embedsReference() bool { return true }
+
+func (buyerRefPolicy) topScore() int { return 0 }
+
+// referenceFirstPolicy matches by buyer reference first and falls back to amount.
+// referenceFirstPolicy mencocokkan berdasarkan referensi pembeli terlebih dahulu, lalu nominal.
+type referenceFirstPolicy struct {
+	amount amountPolicy
+}
+
+func (p referenceFirstPolicy) Match(inv Invoice, m Mutation) MatchResult {
+	if r, ok := credited(inv, m); !ok {
+		return r
+	}
+	if !acceptsQRISType(p.amount.types, m.QRIS) {
+		return wrongQRISType(p.amount.types)
+	}
+	if r := (buyerRefPolicy{}).Match(inv, m); r.Matched {
+		// Above every amount-only match, whose scores are never positive
+		r.Score = 1
+		return r
+	}
+	if inv.Amount == 0 {
+		return MatchResult{Reason: "buyer reference differs / referensi pembeli berbeda"}
+	}
+	return p.amount.Match(inv, m)
+}
+
+func (referenceFirstPolicy) embedsReference() bool { return true }
+
+func (referenceFirstPolicy) topScore() int { return 1 }
+
+// acceptsQRISType reports whether types accepts a credit of QRIS type t.
+// acceptsQRISType melaporkan apakah types menerima kredit dengan tipe QRIS t.
+func acceptsQRISType(types []string, t string) bool {
+	for _, want := range types {
+		if strings.EqualFold(want, QRISTypeAny) || strings.EqualFold(want, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func wrongQRISType(types []string) MatchResult {
+	want := strings.Join(types, "/")
+	return MatchResult{Reason: "not a " + want + " QRIS payment / bukan pembayaran QRIS " + want}
+}
+
+// referenceEmbedder is implemented by policies that need the transaction ID inside the QR code.
+// referenceEmbedder diimplementasikan oleh policy yang membutuhkan ID transaksi di dalam QR code.
+type referenceEmbedder interface {
+	embedsReference() bool
+}
+
+// embedsReference reports whether QR codes for policy must carry the transaction ID.
+// embedsReference melaporkan apakah QR code untuk policy harus membawa ID transaksi.
+func embedsReference(policy MatchPolicy) bool {
+	e, ok := policy.(referenceEmbedder)
+	return ok && e.embedsReference()
+}
+
+// topScorer is implemented by policies that know the highest score they ever give.
+// topScorer diimplementasikan oleh policy yang mengetahui skor tertinggi yang pernah diberikannya.
+type topScorer interface {
+	topScore() int
+}
+
+// topScore returns the highest score policy ever gives, if it is known.
+// topScore mengembalikan skor tertinggi yang pernah diberikan policy, jika diketahui.
+func topScore(policy MatchPolicy) (int, bool) {
+	t, ok := policy.(topScorer)
+	if !ok {
+		return 0, false
+	}
+	return t.topScore(), true
+}
+
+// credited checks the conditions shared by every built-in policy: a dated credit inside the window.
+// credited memeriksa syarat yang sama untuk semua policy bawaan: kredit bertanggal di dalam rentang.
+func credited(inv Invoice, m Mutation) (MatchResult, bool) {
+	if m.Type != "CR" {
+		return MatchResult{Reason: "not a credit / bukan kredit"}, false
+	}
+	// Skip transactions without a valid date
+	if m.Time.IsZero() {
+		return MatchResult{Reason: "missing date / tanggal kosong"}, false
+	}
+	if m.Time.Before(inv.CreatedAt) {
+		return MatchResult{Reason: "before the invoice / sebelum invoice"}, false
+	}
+	return MatchResult{}, true
+}
+
+// matchPolicy returns the configured MatchPolicy, or MatchQRISTypes over QRISConfig.QRISTypes.
+// matchPolicy mengembalikan MatchPolicy yang dikonfigurasi, atau MatchQRISTypes atas QRISConfig.QRISTypes.
+func (q *QRIS) matchPolicy() MatchPolicy {
+	if q.config.MatchPolicy != nil {
+		return q.config.MatchPolicy
+	}
+	return MatchQRISTypes(q.config.QRISTypes...)
+}
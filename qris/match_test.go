@@ -0,0 +1,72 @@
+package qris_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestQRISTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		types     []string
+		amount    int64
+		reference string // issuer reference of the match, "" for none / referensi issuer yang cocok, "" jika tidak ada
+	}{
+		{name: "static by default", amount: 30000, reference: "REF1"},
+		{name: "dynamic not accepted by default", amount: 25000},
+		{name: "dynamic by buyer reference", types: []string{qris.QRISTypeDynamic}, amount: 25000, reference: "REF2"},
+		{name: "static not accepted", types: []string{qris.QRISTypeDynamic}, amount: 30000},
+		{name: "static and dynamic", types: []string{qris.QRISTypeStatic, qris.QRISTypeDynamic}, amount: 25000, reference: "REF2"},
+		{name: "any", types: []string{qris.QRISTypeAny}, amount: 30000, reference: "REF1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := staticGateway(t, mutationFixture(t, "dynamic/mutations.json"))
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.GatewayURL = srv.URL
+				c.QRISTypes = tt.types
+			})
+
+			// REF3 is newer with the same amount, but the buyer reference of REF2 carries INV-7
+			status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "INV-7", tt.amount)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if tt.reference == "" {
+				if status.Status != qris.StatusUnpaid {
+					t.Fatalf("status = %+v, want UNPAID", status)
+				}
+				return
+			}
+			if status.Status != qris.StatusPaid || status.Reference != tt.reference {
+				t.Fatalf("status = %+v, want PAID by %s", status, tt.reference)
+			}
+		})
+	}
+}
+
+func TestQRISTypesReferenceLabel(t *testing.T) {
+	data := qris.QRISData{Amount: 25000, TransactionID: "INV-7"}
+	for _, tt := range []struct {
+		types []string
+		want  bool
+	}{
+		{nil, false},
+		{[]string{qris.QRISTypeStatic}, false},
+		{[]string{qris.QRISTypeDynamic}, true},
+		{[]string{qris.QRISTypeAny}, true},
+	} {
+		q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.QRISTypes = tt.types })
+		payload, err := q.BuildPayload(data)
+		if err != nil {
+			t.Fatalf("BuildPayload with %q: %v", tt.types, err)
+		}
+		// Tag 62 carries the transaction ID as reference label (05)
+		if got := strings.Contains(payload, "0505INV-7"); got != tt.want {
+			t.Errorf("QRISTypes %q: reference label embedded = %v, want %v", tt.types, got, tt.want)
+		}
+	}
+}
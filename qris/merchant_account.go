@@ -0,0 +1,147 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// GUIQRIS is the globally unique identifier of the Indonesian QRIS merchant account templates.
+// GUIQRIS adalah globally unique identifier dari template akun merchant QRIS Indonesia.
+const GUIQRIS = "ID.CO.QRIS.WWW"
+
+// MerchantAccount is a merchant account information template (tags 26–51) of a QRIS payload.
+// MerchantAccount adalah template informasi akun merchant (tag 26–51) dari payload QRIS.
+type MerchantAccount struct {
+	Tag        string // Template tag, "26" to "51" / Tag template, "26" sampai "51"
+	GUI        string // Reverse-domain identifier, sub-tag 00, e.g. GUIQRIS / Identifier reverse-domain, sub-tag 00, misalnya GUIQRIS
+	MPAN       string // Merchant PAN, sub-tag 01 (required for tags 26–45) / PAN merchant, sub-tag 01 (wajib untuk tag 26–45)
+	MerchantID string // Merchant ID or NMID, sub-tag 02 / ID merchant atau NMID, sub-tag 02
+	Criteria   string // Merchant criteria such as UMI, UKE, UME, UBE, sub-tag 03 / Kriteria merchant seperti UMI, UKE, UME, UBE, sub-tag 03
+}
+
+// Validate checks the tag and the mandatory sub-tags of the account.
+// Validate memeriksa tag dan sub-tag wajib dari akun.
+func (a MerchantAccount) Validate() error {
+	n, err := strconv.Atoi(a.Tag)
+	if err != nil || len(a.Tag) != 2 || n < 26 || n > 51 {
+		return fmt.Errorf("merchant account tag %q must be 26-51 / tag akun merchant %q harus 26-51", a.Tag, a.Tag)
+	}
+	if a.GUI == "" {
+		return fmt.Errorf("merchant account %s needs a GUI (sub-tag 00) / akun merchant %s membutuhkan GUI (sub-tag 00)", a.Tag, a.Tag)
+	}
+	if n <= 45 && a.MPAN == "" {
+		return fmt.Errorf("merchant account %s needs a merchant PAN (sub-tag 01) / akun merchant %s membutuhkan PAN merchant (sub-tag 01)", a.Tag, a.Tag)
+	}
+	return nil
+}
+
+// Encode returns the account as a complete TLV field under a.Tag.
+// Encode mengembalikan akun sebagai field TLV lengkap di bawah a.Tag.
+func (a MerchantAccount) Encode() (string, error) {
+	if err := a.Validate(); err != nil {
+		return "", err
+	}
+	value := emv.EncodeFields(a.subFields())
+	if len(value) > 99 {
+		return "", fmt.Errorf("merchant account %s exceeds 99 characters / akun merchant %s melebihi 99 karakter", a.Tag, a.Tag)
+	}
+	return emv.EncodeTLV(a.Tag, value), nil
+}
+
+func (a MerchantAccount) subFields() []QRISField {
+	var fields []QRISField
+	for _, f := range []QRISField{{Tag: "00", Value: a.GUI}, {Tag: "01", Value: a.MPAN}, {Tag: "02", Value: a.MerchantID}, {Tag: "03", Value: a.Criteria}} {
+		if f.Value != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// MerchantAccounts returns the merchant account templates of the payload in payload order.
+// Templates that are not valid TLV are skipped.
+// MerchantAccounts mengembalikan template akun merchant dari payload sesuai urutan payload.
+// Template yang bukan TLV valid dilewati.
+func (p *QRISPayload) MerchantAccounts() []MerchantAccount {
+	var accounts []MerchantAccount
+	for _, f := range p.Fields {
+		if !isMerchantAccountTag(f.Tag) {
+			continue
+		}
+		sub := f.SubFields
+		if sub == nil {
+			var err error
+			if sub, err = emv.ParseFields(f.Value, 0); err != nil {
+				continue
+			}
+		}
+		a := MerchantAccount{Tag: f.Tag}
+		for _, s := range sub {
+			switch s.Tag {
+			case "00":
+				a.GUI = s.Value
+			case "01":
+				a.MPAN = s.Value
+			case "02":
+				a.MerchantID = s.Value
+			case "03":
+				a.Criteria = s.Value
+			}
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts
+}
+
+// isMerchantAccountTag reports whether tag is a merchant account information template.
+// isMerchantAccountTag melaporkan apakah tag adalah template informasi akun merchant.
+func isMerchantAccountTag(tag string) bool {
+	return len(tag) == 2 && tag >= "26" && tag <= "51"
+}
+
+// setMerchantAccounts replaces every merchant account template of a full payload with accounts,
+// keeping the top-level tags in ascending order, and recomputes the CRC.
+// setMerchantAccounts mengganti setiap template akun merchant dari payload lengkap dengan accounts,
+// dengan tag tingkat atas tetap berurutan naik, lalu menghitung ulang CRC.
+func setMerchantAccounts(payload string, accounts []MerchantAccount) (string, error) {
+	if len(payload) < 8 {
+		return "", fmt.Errorf("%w: CRC field 6304 not found at the end / field CRC 6304 tidak ditemukan di akhir", ErrInvalidChecksum)
+	}
+	fields, err := emv.ParseFields(payload[:len(payload)-8], 0)
+	if err != nil {
+		return "", err
+	}
+
+	seen := make(map[string]bool, len(accounts))
+	kept := fields[:0]
+	for _, f := range fields {
+		if !isMerchantAccountTag(f.Tag) {
+			kept = append(kept, f)
+		}
+	}
+	for _, a := range accounts {
+		if err := a.Validate(); err != nil {
+			return "", err
+		}
+		if seen[a.Tag] {
+			return "", fmt.Errorf("duplicate merchant account tag %s / tag akun merchant %s duplikat", a.Tag, a.Tag)
+		}
+		seen[a.Tag] = true
+		kept = append(kept, QRISField{Tag: a.Tag, SubFields: a.subFields()})
+	}
+	if len(accounts) == 0 {
+		return "", errors.New("at least one merchant account is required / minimal satu akun merchant diperlukan")
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Tag < kept[j].Tag })
+	for _, f := range kept {
+		if len(f.SubFields) > 0 && len(emv.EncodeFields(f.SubFields)) > 99 {
+			return "", fmt.Errorf("merchant account %s exceeds 99 characters / akun merchant %s melebihi 99 karakter", f.Tag, f.Tag)
+		}
+	}
+	return emv.Build(emv.QRISPayload{Fields: kept})
+}
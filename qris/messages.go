@@ -0,0 +1,236 @@
+package qris
+
+import (
+	"errors"
+	"strings"
+)
+
+// Languages supported by UserMessage and StatusMessage.
+// Bahasa yang didukung oleh UserMessage dan StatusMessage.
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+)
+
+// message is one catalog entry, translated into every supported language.
+// message adalah satu entri katalog, diterjemahkan ke setiap bahasa yang didukung.
+type message struct {
+	en string
+	id string
+}
+
+func (m message) in(lang string) string {
+	if normalizeLanguage(lang) == LanguageIndonesian {
+		return m.id
+	}
+	return m.en
+}
+
+// messageCatalog holds the customer-facing text of errors and payment statuses, by code. It covers
+// the codes of ErrorCode, not the text of the errors themselves, which mostly stays bilingual.
+// messageCatalog menyimpan teks untuk customer dari error dan status pembayaran, berdasarkan kode.
+// Katalog mencakup kode dari ErrorCode, bukan teks error itu sendiri, yang sebagian besar tetap dwibahasa.
+var messageCatalog = map[string]message{
+	"AMOUNT_OUT_OF_RANGE":        {"The amount is outside the allowed range.", "Nominal di luar batas yang diizinkan."},
+	"INVALID_AMOUNT":             {"The amount must be greater than zero.", "Nominal harus lebih besar dari nol."},
+	"INVALID_TRANSACTION_ID":     {"The order number is not valid.", "Nomor pesanan tidak valid."},
+	"TRANSACTION_ID_TOO_LONG":    {"The order number is too long.", "Nomor pesanan terlalu panjang."},
+	"NOTE_TOO_LONG":              {"The payment note is too long.", "Catatan pembayaran terlalu panjang."},
+	"METADATA_TOO_LARGE":         {"The order details are too large.", "Detail pesanan terlalu besar."},
+	"NO_AMOUNT_AVAILABLE":        {"Too many payments are pending, please try again in a moment.", "Terlalu banyak pembayaran yang menunggu, silakan coba lagi sebentar lagi."},
+	"AMOUNT_COLLISION":           {"This invoice cannot be paid right now, please try again in a moment.", "Invoice ini belum dapat dibayar, silakan coba lagi sebentar lagi."},
+	"PAYMENT_EXPIRED":            {"The payment has expired, please create a new one.", "Pembayaran sudah kedaluwarsa, silakan buat pembayaran baru."},
+	"PAYMENT_CANCELLED":          {"The payment was cancelled.", "Pembayaran dibatalkan."},
+	"GATEWAY_TIMEOUT":            {"The payment service is slow to respond, please try again.", "Layanan pembayaran lambat merespons, silakan coba lagi."},
+	"GATEWAY_UNREACHABLE":        {"The payment service cannot be reached, please try again later.", "Layanan pembayaran tidak dapat dihubungi, silakan coba lagi nanti."},
+	"GATEWAY_RATE_LIMITED":       {"The payment service is busy, please try again in a minute.", "Layanan pembayaran sedang sibuk, silakan coba lagi dalam satu menit."},
+	"GATEWAY_MAINTENANCE":        {"The payment service is under maintenance, please try again later.", "Layanan pembayaran sedang dalam pemeliharaan, silakan coba lagi nanti."},
+	"GATEWAY_INVALID_RESPONSE":   {"The payment service sent an unexpected response, please try again.", "Layanan pembayaran mengirim response yang tidak terduga, silakan coba lagi."},
+	"GATEWAY_UNAUTHORIZED":       {"The merchant account needs to be reconnected.", "Akun merchant perlu dihubungkan ulang."},
+	"INVALID_REFERENCE":          {"The payment reference or amount is not valid.", "Referensi atau nominal pembayaran tidak valid."},
+	"NOT_FOUND":                  {"No payment with this reference was found.", "Pembayaran dengan referensi ini tidak ditemukan."},
+	"PAYLOAD_TOO_LARGE":          {"The QRIS data is too long for a QR code.", "Data QRIS terlalu panjang untuk QR code."},
+	"INVALID_CHECKSUM":           {"The QRIS code is damaged, please scan it again.", "Kode QRIS rusak, silakan scan ulang."},
+	"UNSUPPORTED_CURRENCY":       {"This QRIS code is not in rupiah.", "Kode QRIS ini bukan dalam rupiah."},
+	"CLOSED":                     {"The payment service is shutting down, please try again later.", "Layanan pembayaran sedang dihentikan, silakan coba lagi nanti."},
+	"NOT_SANDBOX":                {"This action is only available in sandbox mode.", "Aksi ini hanya tersedia dalam mode sandbox."},
+	"GATEWAY_RESPONSE_TOO_LARGE": {"The payment service sent an unexpected response, please try again.", "Layanan pembayaran mengirim response yang tidak terduga, silakan coba lagi."},
+	"GATEWAY_EMPTY_RESPONSE":     {"The payment service sent an empty response, please try again.", "Layanan pembayaran mengirim response kosong, silakan coba lagi."},
+	"GATEWAY_SCHEMA_MISMATCH":    {"The payment service sent an unexpected response, please try again.", "Layanan pembayaran mengirim response yang tidak terduga, silakan coba lagi."},
+	"TOKEN_INVALID":              {"This payment link is not valid.", "Link pembayaran ini tidak valid."},
+	"TOKEN_EXPIRED":              {"This payment link has expired.", "Link pembayaran ini sudah kedaluwarsa."},
+	"DECRYPTION_FAILED":          {"The stored credentials cannot be read.", "Kredensial yang disimpan tidak dapat dibaca."},
+	"BACKFILL_UNSUPPORTED":       {"The invoice storage does not support this action.", "Penyimpanan invoice tidak mendukung aksi ini."},
+	"WEBHOOK_SIGNATURE_INVALID":  {"The notification could not be verified.", "Notifikasi tidak dapat diverifikasi."},
+	"SESSION_WATCHED":            {"The payment is already being watched.", "Pembayaran ini sudah dipantau."},
+	"UNKNOWN":                    {"Something went wrong, please try again.", "Terjadi kesalahan, silakan coba lagi."},
+	"STATUS_" + StatusPaid:       {"Payment received.", "Pembayaran diterima."},
+	"STATUS_" + StatusUnpaid:     {"Waiting for payment.", "Menunggu pembayaran."},
+	"STATUS_" + StatusExpired:    {"The payment has expired.", "Pembayaran sudah kedaluwarsa."},
+	"STATUS_" + StatusCancelled:  {"The payment was cancelled.", "Pembayaran dibatalkan."},
+}
+
+// errorCodes maps the sentinel errors of the package to catalog codes, most specific first.
+// errorCodes memetakan sentinel error paket ke kode katalog, dari yang paling spesifik.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrAmountOutOfRange, "AMOUNT_OUT_OF_RANGE"},
+	{ErrInvalidAmount, "INVALID_AMOUNT"},
+	{ErrInvalidTransactionID, "INVALID_TRANSACTION_ID"},
+	{ErrTransactionIDTooLong, "TRANSACTION_ID_TOO_LONG"},
+	{ErrNoteTooLong, "NOTE_TOO_LONG"},
+	{ErrMetadataTooLarge, "METADATA_TOO_LARGE"},
+	{ErrNoAmountAvailable, "NO_AMOUNT_AVAILABLE"},
+	{ErrAmountCollision, "AMOUNT_COLLISION"},
+	{ErrPaymentExpired, "PAYMENT_EXPIRED"},
+	{ErrPaymentCancelled, "PAYMENT_CANCELLED"},
+	{ErrGatewayTimeout, "GATEWAY_TIMEOUT"},
+	{ErrGatewayUnauthorized, "GATEWAY_UNAUTHORIZED"},
+	{ErrRateLimitedByGateway, "GATEWAY_RATE_LIMITED"},
+	{ErrGatewayUnreachable, "GATEWAY_UNREACHABLE"},
+	{ErrGatewayMaintenance, "GATEWAY_MAINTENANCE"},
+	{ErrResponseTooLarge, "GATEWAY_RESPONSE_TOO_LARGE"},
+	{ErrEmptyResponse, "GATEWAY_EMPTY_RESPONSE"},
+	{ErrInvalidResponse, "GATEWAY_INVALID_RESPONSE"},
+	{ErrNotFound, "NOT_FOUND"},
+	{ErrPayloadTooLarge, "PAYLOAD_TOO_LARGE"},
+	{ErrInvalidChecksum, "INVALID_CHECKSUM"},
+	{ErrUnsupportedCurrency, "UNSUPPORTED_CURRENCY"},
+	{ErrClosed, "CLOSED"},
+	{ErrNotSandbox, "NOT_SANDBOX"},
+	{ErrTokenExpired, "TOKEN_EXPIRED"},
+	{ErrTokenInvalid, "TOKEN_INVALID"},
+	{ErrDecryptionFailed, "DECRYPTION_FAILED"},
+	{ErrBackfillUnsupported, "BACKFILL_UNSUPPORTED"},
+	{ErrSessionWatched, "SESSION_WATCHED"},
+	{ErrWebhookSignature, "WEBHOOK_SIGNATURE_INVALID"},
+}
+
+// ErrorCodes returns a new map of the sentinel errors of the package to their codes of ErrorCode,
+// for documentation and lookups. Other codes are GATEWAY_SCHEMA_MISMATCH for a *SchemaError,
+// INVALID_REFERENCE for a check without reference or amount, and UNKNOWN.
+// ErrorCodes mengembalikan map baru dari sentinel error paket ke kodenya dari ErrorCode, untuk
+// dokumentasi dan pencarian. Kode lainnya adalah GATEWAY_SCHEMA_MISMATCH untuk *SchemaError,
+// INVALID_REFERENCE untuk pengecekan tanpa reference atau nominal, dan UNKNOWN.
+func ErrorCodes() map[error]string {
+	codes := make(map[error]string, len(errorCodes))
+	for _, e := range errorCodes {
+		codes[e.err] = e.code
+	}
+	return codes
+}
+
+// CodedError is implemented by errors carrying their own code for ErrorCode, such as those of WithCode.
+// CodedError diimplementasikan oleh error yang membawa kodenya sendiri untuk ErrorCode, seperti error dari WithCode.
+type CodedError interface {
+	error
+	Code() string
+}
+
+// codedError is the error of WithCode.
+// codedError adalah error dari WithCode.
+type codedError struct {
+	err  error
+	code string
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+func (e *codedError) Code() string  { return e.code }
+
+// WithCode returns err with code, which ErrorCode then returns for it and every error wrapping it.
+// WithCode mengembalikan err dengan code, yang kemudian dikembalikan ErrorCode untuk err dan setiap error yang membungkusnya.
+func WithCode(err error, code string) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{err: err, code: code}
+}
+
+// ErrorCode returns a stable machine-readable code for err, e.g. "GATEWAY_TIMEOUT" or
+// "AMOUNT_OUT_OF_RANGE", to return to API clients: the code of the outermost CodedError in the chain,
+// else the code of the most specific sentinel of ErrorCodes() it wraps, else "UNKNOWN". It returns ""
+// for a nil err.
+// ErrorCode mengembalikan kode stabil yang dapat dibaca mesin untuk err, misalnya "GATEWAY_TIMEOUT"
+// atau "AMOUNT_OUT_OF_RANGE", untuk dikembalikan ke klien API: kode dari CodedError terluar dalam
+// rantai, jika tidak kode sentinel paling spesifik dari ErrorCodes() yang dibungkusnya, jika tidak
+// "UNKNOWN". Mengembalikan "" untuk err nil.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return "UNKNOWN"
+}
+
+// UserMessage returns friendly text for err in lang ("id" or "en", default English) to show to
+// customers or support staff. Errors of the package keep their values, so handle them with errors.Is
+// and use UserMessage only for display. The text of err itself is not localized: errors without a
+// catalog entry fall back to the matching half of their bilingual "english / indonesian" message.
+// UserMessage mengembalikan teks yang ramah untuk err dalam lang ("id" atau "en", default bahasa Inggris)
+// untuk ditampilkan ke customer atau tim support. Error paket tetap memakai nilainya, jadi tangani dengan
+// errors.Is dan pakai UserMessage hanya untuk tampilan. Teks err sendiri tidak dilokalkan: error tanpa entri
+// katalog memakai bagian yang sesuai dari pesan dwibahasa "english / indonesian"-nya.
+func UserMessage(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	if code := ErrorCode(err); code != "UNKNOWN" {
+		if m, ok := messageCatalog[code]; ok {
+			return m.in(lang)
+		}
+	}
+
+	// Only the outermost message is shown; wrapped causes are technical details
+	head := err.Error()
+	if i := strings.Index(head, ": "); i >= 0 {
+		head = head[:i]
+	}
+	if en, id, ok := strings.Cut(head, " / "); ok {
+		return message{en: capitalize(en), id: capitalize(id)}.in(lang)
+	}
+	return messageCatalog["UNKNOWN"].in(lang)
+}
+
+// StatusMessage returns friendly text for a PaymentStatus.Status value in lang ("id" or "en").
+// StatusMessage mengembalikan teks yang ramah untuk nilai PaymentStatus.Status dalam lang ("id" atau "en").
+func StatusMessage(status, lang string) string {
+	if m, ok := messageCatalog["STATUS_"+status]; ok {
+		return m.in(lang)
+	}
+	return status
+}
+
+// UserMessage is like the package-level UserMessage in QRISConfig.Language.
+// UserMessage sama seperti UserMessage tingkat paket dalam QRISConfig.Language.
+func (q *QRIS) UserMessage(err error) string {
+	return UserMessage(err, q.config.Language)
+}
+
+// normalizeLanguage maps lang to a supported language, defaulting to English.
+// normalizeLanguage memetakan lang ke bahasa yang didukung, dengan default bahasa Inggris.
+func normalizeLanguage(lang string) string {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "id", "id-id", "in", "indonesian", "bahasa":
+		return LanguageIndonesian
+	default:
+		return LanguageEnglish
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,51 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxMetadataSize is the largest total size, in bytes of keys and values, of the metadata of a payment.
+// MaxMetadataSize adalah ukuran total terbesar, dalam byte key dan value, dari metadata sebuah pembayaran.
+const MaxMetadataSize = 4096
+
+// ErrMetadataTooLarge is returned by CreatePayment when the metadata of WithMetadata exceeds MaxMetadataSize.
+// ErrMetadataTooLarge dikembalikan CreatePayment jika metadata dari WithMetadata melebihi MaxMetadataSize.
+var ErrMetadataTooLarge = errors.New("metadata too large / metadata terlalu besar")
+
+// WithMetadata attaches metadata, such as order or customer IDs, to the payment. It is kept on the
+// session, saved in the InvoiceStore, and carried on PaymentEvent and PaymentStatus, but never sent to
+// the gateway. Keys and values may total at most MaxMetadataSize bytes; md is copied.
+// WithMetadata melampirkan metadata, seperti ID pesanan atau pelanggan, ke pembayaran. Metadata disimpan
+// di sesi, di InvoiceStore, dan dibawa PaymentEvent dan PaymentStatus, tetapi tidak pernah dikirim ke
+// gateway. Total key dan value maksimal MaxMetadataSize byte; md disalin.
+func WithMetadata(md map[string]string) PaymentOption {
+	return func(o *paymentOptions) {
+		o.metadata = copyMetadata(md)
+	}
+}
+
+// checkMetadata returns an error wrapping ErrMetadataTooLarge if md exceeds MaxMetadataSize.
+// checkMetadata mengembalikan error yang membungkus ErrMetadataTooLarge jika md melebihi MaxMetadataSize.
+func checkMetadata(md map[string]string) error {
+	size := 0
+	for k, v := range md {
+		size += len(k) + len(v)
+	}
+	if size > MaxMetadataSize {
+		return fmt.Errorf("%w: %d bytes, at most %d / %d byte, maksimal %d", ErrMetadataTooLarge,
+			size, MaxMetadataSize, size, MaxMetadataSize)
+	}
+	return nil
+}
+
+func copyMetadata(md map[string]string) map[string]string {
+	if len(md) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(md))
+	for k, v := range md {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,120 @@
+package qris_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+func TestGenerateModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		data   qris.QRISData
+		poi    string
+		amount string
+		ref    string
+	}{
+		{"fixed", testBaseQR, qris.QRISData{Amount: 25000, TransactionID: "INV-1"}, "12", "25000", ""},
+		{"fixed over a dynamic base", testDynamicQR, qris.QRISData{Amount: 15000, TransactionID: "INV-1"}, "12", "15000", ""},
+		{"open", testBaseQR, qris.QRISData{TransactionID: "INV-1", Mode: qris.ModeOpenAmount}, "11", "", "INV-1"},
+		{"open ignores amount", testBaseQR, qris.QRISData{Amount: 25000, TransactionID: "INV-1", Mode: qris.ModeOpenAmount}, "11", "", "INV-1"},
+		{"open strips base amount", testDynamicQR, qris.QRISData{TransactionID: "INV-1", Mode: qris.ModeOpenAmount}, "11", "", "INV-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.BaseQrString = tt.base })
+			payload, err := q.PreviewPayload(tt.data)
+			if err != nil {
+				t.Fatalf("PreviewPayload: %v", err)
+			}
+			if got := payload.Value("01"); got != tt.poi {
+				t.Errorf("POI = %q, want %q", got, tt.poi)
+			}
+			if got := payload.Value("54"); got != tt.amount {
+				t.Errorf("amount = %q, want %q", got, tt.amount)
+			}
+			var ref string
+			if f, ok := payload.Field("62"); ok {
+				if sub, ok := f.SubField("05"); ok {
+					ref = sub.Value
+				}
+			}
+			if ref != tt.ref {
+				t.Errorf("reference label = %q, want %q", ref, tt.ref)
+			}
+			if _, err := q.GenerateQRCode(tt.data); err != nil {
+				t.Errorf("GenerateQRCode: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateModesInvalidAmount(t *testing.T) {
+	tests := []struct {
+		name string
+		data qris.QRISData
+	}{
+		{"fixed without amount", qris.QRISData{TransactionID: "INV-1"}},
+		{"fixed negative", qris.QRISData{Amount: -1, TransactionID: "INV-1"}},
+		{"open negative", qris.QRISData{Amount: -1, TransactionID: "INV-1", Mode: qris.ModeOpenAmount}},
+	}
+	q := newTestQRIS(t, newGateway(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := q.GenerateQRCode(tt.data); !errors.Is(err, qris.ErrInvalidAmount) {
+				t.Fatalf("err = %v, want ErrInvalidAmount", err)
+			}
+		})
+	}
+}
+
+func TestCheckOpenAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		script func(*qristest.Server)
+		status string
+		amount int64
+	}{
+		{
+			name:   "paid with the reference",
+			script: func(gw *qristest.Server) { gw.AddMutation(37500, time.Now(), qristest.WithBuyerRef("INV-1")) },
+			status: qris.StatusPaid,
+			amount: 37500,
+		},
+		{
+			name:   "other reference",
+			script: func(gw *qristest.Server) { gw.AddMutation(37500, time.Now(), qristest.WithBuyerRef("INV-2")) },
+			status: qris.StatusUnpaid,
+		},
+		{
+			name:   "no reference",
+			script: func(gw *qristest.Server) { gw.AddMutation(37500, time.Now()) },
+			status: qris.StatusUnpaid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newGateway(t)
+			tt.script(gw)
+			q := newTestQRIS(t, gw)
+
+			status, err := q.CheckPaymentStatus("INV-1", 0)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if status.Status != tt.status {
+				t.Fatalf("Status = %s, want %s", status.Status, tt.status)
+			}
+			if tt.status == qris.StatusPaid && status.Amount != tt.amount {
+				t.Fatalf("Amount = %d, want %d", status.Amount, tt.amount)
+			}
+			if status.Warning != "" {
+				t.Fatalf("Warning = %q, want none for a reference match", status.Warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,658 @@
+package qris
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mutationDateLayout is the date layout used by the mutation API.
+// mutationDateLayout adalah format tanggal yang dipakai API mutasi.
+const mutationDateLayout = "2006-01-02 15:04:05"
+
+// parseMutationDate parses a gateway date in mutationDateLayout or ISO 8601 ("2006-01-02T15:04:05",
+// as some okeconnect accounts send), read in loc unless it carries its own offset. Unparsable dates
+// are zero.
+// parseMutationDate mem-parse tanggal gateway dalam mutationDateLayout atau ISO 8601
+// ("2006-01-02T15:04:05", seperti yang dikirim sebagian akun okeconnect), dibaca dalam loc kecuali
+// memiliki offset sendiri. Tanggal yang tidak valid bernilai nol.
+func parseMutationDate(s string, loc *time.Location) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{mutationDateLayout, "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc)
+	}
+	return time.Time{}
+}
+
+// DefaultMutationURL is the mutation endpoint of GatewayFTVPN when QRISConfig.GatewayURL is empty.
+// DefaultMutationURL adalah endpoint mutasi GatewayFTVPN jika QRISConfig.GatewayURL kosong.
+const DefaultMutationURL = "https://ftvpn.me/api/mutasi"
+
+// DefaultGatewayURL is the former name of DefaultMutationURL.
+// DefaultGatewayURL adalah nama lama dari DefaultMutationURL.
+//
+// Deprecated: Use DefaultMutationURL, or QRISConfig.GatewayURL to change the endpoint of one instance.
+// Gunakan DefaultMutationURL, atau QRISConfig.GatewayURL untuk mengganti endpoint satu instance.
+const DefaultGatewayURL = DefaultMutationURL
+
+// WIB is Western Indonesian Time (UTC+7), the default timezone of gateway dates.
+// WIB adalah Waktu Indonesia Barat (UTC+7), zona waktu default tanggal dari gateway.
+var WIB = time.FixedZone("WIB", 7*60*60)
+
+// Mutation is a single row of the account mutation history.
+// Mutation adalah satu baris riwayat mutasi akun.
+type Mutation struct {
+	Amount    int64     // Mutation amount / Nominal mutasi
+	Date      string    // Raw gateway date / Tanggal mentah dari gateway
+	Time      time.Time // Parsed date, zero if unparsable / Tanggal yang di-parse, nol jika tidak valid
+	QRIS      string    // QRIS type (static/dynamic) / Tipe QRIS (static/dynamic)
+	Type      string    // CR (credit) or DB (debit) / CR (kredit) atau DB (debit)
+	IssuerRef string    // Issuer reference / Referensi issuer
+	BrandName string    // Payer brand name as sent by the gateway / Nama brand pembayar sesuai kiriman gateway
+	Issuer    Issuer    // Canonical issuer of BrandName, see NormalizeIssuer / Issuer kanonis dari BrandName, lihat NormalizeIssuer
+	BuyerRef  string    // Buyer reference / Referensi pembeli
+
+	Balance    int64 // Account balance after the mutation, if HasBalance / Saldo akun setelah mutasi, jika HasBalance
+	HasBalance bool  // The gateway sent a balance (okeconnect does) / Gateway mengirim saldo (okeconnect mengirimnya)
+
+	Raw json.RawMessage // Raw gateway row (if IncludeRaw) / Baris mentah dari gateway (jika IncludeRaw)
+}
+
+// DefaultMutationsPerPage is the page size used by GetAllMutations when none is set.
+// DefaultMutationsPerPage adalah ukuran halaman yang dipakai GetAllMutations jika tidak diatur.
+const DefaultMutationsPerPage = 100
+
+// MutationQuery narrows the mutation history fetched from the gateway.
+// MutationQuery mempersempit riwayat mutasi yang diambil dari gateway.
+//
+// Zero values mean "gateway default". Filters are also applied locally,
+// so results are correct even if the gateway ignores them.
+// Nilai nol berarti "default gateway". Filter juga diterapkan secara lokal,
+// sehingga hasil tetap benar walaupun gateway mengabaikannya.
+type MutationQuery struct {
+	From    time.Time // Inclusive start / Awal (inklusif)
+	To      time.Time // Exclusive end / Akhir (eksklusif)
+	Page    int       // Page number starting at 1 / Nomor halaman mulai dari 1
+	PerPage int       // Rows per page / Jumlah baris per halaman
+	Type    string    // CR or DB, empty for both / CR atau DB, kosong untuk keduanya
+}
+
+// matches reports whether m passes the date and type filters of the query.
+// matches melaporkan apakah m lolos filter tanggal dan tipe dari query.
+func (mq MutationQuery) matches(m Mutation) bool {
+	if mq.Type != "" && m.Type != mq.Type {
+		return false
+	}
+	if !mq.From.IsZero() && (m.Time.IsZero() || m.Time.Before(mq.From)) {
+		return false
+	}
+	if !mq.To.IsZero() && (m.Time.IsZero() || !m.Time.Before(mq.To)) {
+		return false
+	}
+	return true
+}
+
+// GetMutations fetches one page of the account mutation history from the gateway.
+// GetMutations mengambil satu halaman riwayat mutasi akun dari gateway.
+func (q *QRIS) GetMutations(ctx context.Context, query MutationQuery) ([]Mutation, error) {
+	var mutations []Mutation
+	_, err := q.eachMutation(ctx, query, func(m Mutation) error {
+		mutations = append(mutations, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mutations, nil
+}
+
+// GetAllMutations follows the pages of the mutation history until they are exhausted.
+// GetAllMutations mengikuti halaman riwayat mutasi sampai habis.
+//
+// If ctx is done first, the mutations fetched so far are returned together with the context error.
+// Jika ctx selesai lebih dulu, mutasi yang sudah diambil dikembalikan bersama error context.
+func (q *QRIS) GetAllMutations(ctx context.Context, query MutationQuery) ([]Mutation, error) {
+	var mutations []Mutation
+	err := q.eachAllMutations(ctx, query, func(m Mutation) error {
+		mutations = append(mutations, m)
+		return nil
+	})
+	return mutations, err
+}
+
+// eachAllMutations walks every page starting at query.Page and hands each row to fn.
+// eachAllMutations menelusuri setiap halaman mulai dari query.Page dan meneruskan setiap baris ke fn.
+func (q *QRIS) eachAllMutations(ctx context.Context, query MutationQuery, fn func(Mutation) error) error {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = DefaultMutationsPerPage
+	}
+
+	var prevFirst string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := q.eachMutation(ctx, query, fn)
+		if err != nil {
+			return err
+		}
+
+		// Stop on a short page, past the last page, or when the gateway ignores paging
+		if page.rows < query.PerPage || (page.totalPages > 0 && query.Page >= page.totalPages) || page.first == prevFirst {
+			return nil
+		}
+		prevFirst = page.first
+		query.Page++
+	}
+}
+
+// mutationPage describes a fetched page of mutations.
+// mutationPage menggambarkan satu halaman mutasi yang sudah diambil.
+type mutationPage struct {
+	rows       int    // Rows returned by the gateway before local filtering / Baris dari gateway sebelum filter lokal
+	totalPages int    // Total pages if reported, otherwise 0 / Total halaman jika dilaporkan, selain itu 0
+	first      string // Key of the first row, to detect repeated pages / Kunci baris pertama, untuk mendeteksi halaman berulang
+	raw        []byte // Raw response body (if IncludeRaw) / Body response mentah (jika IncludeRaw)
+}
+
+// mutationRow is a mutation as encoded by the gateway, see decodeMutationRow.
+// mutationRow adalah mutasi sesuai encoding gateway, lihat decodeMutationRow.
+type mutationRow struct {
+	Amount    int64
+	Date      string
+	QRIS      string
+	Type      string
+	IssuerRef string
+	BrandName string
+	BuyerRef  string
+
+	Balance    int64
+	HasBalance bool
+
+	TruncatedAmount string // Amount as written when a fraction of a rupiah was truncated / Nominal seperti tertulis jika pecahan rupiah dipotong
+}
+
+// thousandsSeparators strips the separators of an amount; building it once keeps row decoding cheap.
+// thousandsSeparators menghapus pemisah dari nominal; dibuat sekali agar decode baris tetap ringan.
+var thousandsSeparators = strings.NewReplacer(".", "", ",", "")
+
+// parseMutationAmount parses an amount written by a gateway or acquirer, such as "15000",
+// "15000.00", "15.000", "15,000.00" or "Rp 15.000,00", and reports whether a fraction of a rupiah
+// was truncated. With both "." and "," the last one is the decimal separator; a lone separator
+// followed by three digits after at most three digits groups thousands, otherwise it is the decimal
+// separator.
+// parseMutationAmount mem-parse nominal yang ditulis gateway atau acquirer, seperti "15000",
+// "15000.00", "15.000", "15,000.00" atau "Rp 15.000,00", dan melaporkan apakah pecahan rupiah
+// dipotong. Jika ada "." dan "," sekaligus, yang terakhir adalah pemisah desimal; satu pemisah yang
+// diikuti tiga digit setelah paling banyak tiga digit adalah pemisah ribuan, selain itu pemisah desimal.
+func parseMutationAmount(s string) (n int64, frac bool, err error) {
+	v := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	v = strings.TrimPrefix(v, "idr")
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "rp"), ".")
+	neg := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+
+	intPart, fracPart := v, ""
+	if last := strings.LastIndexAny(v, ".,"); last >= 0 {
+		sep, other := v[last], byte(',')
+		if sep == ',' {
+			other = '.'
+		}
+		thousands := strings.IndexByte(v, other) < 0 &&
+			(strings.Count(v, string(sep)) > 1 || (len(v)-last-1 == 3 && last <= 3))
+		if !thousands {
+			intPart, fracPart = v[:last], v[last+1:]
+		}
+	}
+	intPart = thousandsSeparators.Replace(intPart)
+
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		// JSON numbers may come in exponent form, e.g. 1.5e4
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= math.MaxInt64 {
+			return 0, false, fmt.Errorf("invalid amount %q / nominal %q tidak valid", s, s)
+		}
+		return int64(f), f != math.Trunc(f), nil
+	}
+	if n, err = strconv.ParseInt(intPart, 10, 64); err != nil {
+		return 0, false, fmt.Errorf("invalid amount %q / nominal %q tidak valid", s, s)
+	}
+	if neg {
+		n = -n
+	}
+	return n, strings.Trim(fracPart, "0") != "", nil
+}
+
+// eachMutation fetches one page of the mutation history and hands every matching row to fn in gateway order.
+// eachMutation mengambil satu halaman riwayat mutasi dan meneruskan setiap baris yang cocok ke fn sesuai urutan gateway.
+func (q *QRIS) eachMutation(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+	if err := q.life.err(); err != nil {
+		return mutationPage{}, err
+	}
+	if q.config.Sandbox {
+		return q.sandbox.eachMutation(query, fn)
+	}
+	if q.config.Provider != nil {
+		return q.eachProviderMutation(ctx, query, fn)
+	}
+
+	var page mutationPage
+	loc := q.location()
+
+	reqCtx, cancel := q.requestContext(ctx)
+	defer cancel()
+
+	req, err := q.newMutationRequest(reqCtx, query)
+	if err != nil {
+		return page, err
+	}
+
+	// Send request
+	resp, err := q.send(req)
+	if err != nil {
+		return page, gatewayError(ctx, reqCtx, fmt.Errorf("failed to send request / gagal mengirim request: %w: %v", ErrGatewayUnreachable, err))
+	}
+	defer resp.Body.Close()
+	limited := &limitedBody{r: resp.Body, limit: q.maxResponseSize()}
+	resp.Body = limited
+	if q.config.OnResponseSize != nil {
+		defer func() { q.config.OnResponseSize(limited.n) }()
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return page, fmt.Errorf("%w: status %d", ErrGatewayUnauthorized, resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return page, rateLimitError(resp)
+	}
+	token, _ := q.credentials()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		// Quote the maintenance page if there is one
+		if _, err := checkJSONBody(resp, token); errors.Is(err, ErrGatewayMaintenance) {
+			return page, err
+		}
+		return page, fmt.Errorf("%w: status %d", ErrGatewayMaintenance, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("unexpected gateway status %d / status gateway tidak terduga %d", resp.StatusCode, resp.StatusCode)
+	}
+
+	// Maintenance pages and empty bodies get their own errors instead of a JSON syntax error
+	body, err := checkJSONBody(resp, token)
+	if err != nil {
+		if errors.Is(err, errReadResponse) {
+			return page, gatewayError(ctx, reqCtx, err)
+		}
+		return page, err
+	}
+
+	// Decode the rows one at a time so large responses are never held in memory at once
+	var raw bytes.Buffer
+	r := body
+	if q.config.IncludeRaw {
+		r = io.TeeReader(body, &raw)
+	}
+
+	status, err := decodeMutationResponse(r, &page, func(data json.RawMessage) error {
+		tx, err := decodeMutationRow(data, page.rows, q.config.StrictDecoding)
+		if err != nil {
+			var schemaErr *SchemaError
+			if errors.As(err, &schemaErr) {
+				return err
+			}
+			return fmt.Errorf("failed to parse mutation / gagal parse mutasi: %v", err)
+		}
+		if page.rows == 0 {
+			page.first = tx.IssuerRef + "|" + tx.Date
+		}
+		page.rows++
+		if tx.TruncatedAmount != "" {
+			logf(ctx, "Mutation amount %s has a fraction of a rupiah, truncated to %d", tx.TruncatedAmount, tx.Amount)
+		}
+
+		txTime := parseMutationDate(tx.Date, loc)
+		m := Mutation{
+			Amount:    tx.Amount,
+			Date:      tx.Date,
+			Time:      txTime,
+			QRIS:      tx.QRIS,
+			Type:      tx.Type,
+			IssuerRef: tx.IssuerRef,
+			BrandName: tx.BrandName,
+			Issuer:    NormalizeIssuer(tx.BrandName),
+			BuyerRef:  tx.BuyerRef,
+
+			Balance:    tx.Balance,
+			HasBalance: tx.HasBalance,
+		}
+		if q.config.IncludeRaw {
+			m.Raw = data
+		}
+		if reason := skipReason(m); reason != "" {
+			row := SkippedRow{Reason: reason, Date: m.Date, Amount: m.Amount, IssuerRef: m.IssuerRef, Raw: m.Raw}
+			q.skipped.record(ctx, string(data), row)
+			return nil
+		}
+		if !query.matches(m) {
+			return nil
+		}
+		return fn(m)
+	})
+	if q.config.IncludeRaw {
+		page.raw = raw.Bytes()
+	}
+	if err != nil {
+		if errors.Is(err, errReadResponse) {
+			return page, gatewayError(ctx, reqCtx, err)
+		}
+		return page, err
+	}
+	if status != "success" {
+		page.rows, page.first = 0, ""
+	}
+	// Okeconnect returns the whole recent history at once
+	if q.gateway() == GatewayOkeconnect && page.totalPages == 0 {
+		page.totalPages = 1
+	}
+	return page, nil
+}
+
+// newMutationRequest builds the gateway request of query for the configured gateway.
+// newMutationRequest membuat request gateway untuk query sesuai gateway yang dikonfigurasi.
+func (q *QRIS) newMutationRequest(ctx context.Context, query MutationQuery) (*http.Request, error) {
+	token, username := q.credentials()
+
+	var req *http.Request
+	var err error
+	switch q.gateway() {
+	case GatewayOkeconnect:
+		// Credentials are part of the path; filters are applied locally
+		endpoint := strings.TrimSuffix(q.gatewayURL(), "/") + "/" + url.PathEscape(username) + "/" + url.PathEscape(token)
+		req, err = http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	default:
+		requestBody := map[string]string{
+			"auth_token":    token,
+			"auth_username": username,
+		}
+		loc := q.location()
+		if !query.From.IsZero() {
+			requestBody["date_from"] = query.From.In(loc).Format(mutationDateLayout)
+		}
+		if !query.To.IsZero() {
+			requestBody["date_to"] = query.To.In(loc).Format(mutationDateLayout)
+		}
+		if query.Page > 0 {
+			requestBody["page"] = strconv.Itoa(query.Page)
+		}
+		if query.PerPage > 0 {
+			requestBody["per_page"] = strconv.Itoa(query.PerPage)
+		}
+		if query.Type != "" {
+			requestBody["type"] = query.Type
+		}
+
+		jsonBody, merr := json.Marshal(requestBody)
+		if merr != nil {
+			return nil, fmt.Errorf("failed to marshal request body / gagal marshal request body: %v", merr)
+		}
+		req, err = http.NewRequestWithContext(ctx, "POST", q.gatewayURL(), strings.NewReader(string(jsonBody)))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request / gagal membuat request: %v", err)
+	}
+	req.Header.Set("User-Agent", q.userAgent())
+	return req, nil
+}
+
+// errStopMutations is returned by a row callback to stop reading mutations once it has what it needs.
+// errStopMutations dikembalikan oleh callback baris untuk berhenti membaca mutasi setelah mendapatkan yang dibutuhkan.
+var errStopMutations = errors.New("stop reading mutations / berhenti membaca mutasi")
+
+// errReadResponse is wrapped by errors reading the response body, as opposed to parsing it.
+// errReadResponse dibungkus oleh error saat membaca body response, berbeda dengan saat mem-parse-nya.
+var errReadResponse = errors.New("failed to read response / gagal membaca response")
+
+// decodeMutationResponse walks a mutation response token by token and hands every row of "data"
+// to row as soon as it is decoded, stopping at the first error of row. Rows are only handed over
+// once "status" is known to be "success"; rows arriving before it are held back until then.
+// The returned status is the "status" field of the response.
+// decodeMutationResponse menelusuri response mutasi token demi token dan meneruskan setiap baris "data"
+// ke row begitu selesai di-decode, berhenti pada error pertama dari row. Baris hanya diteruskan
+// setelah "status" diketahui bernilai "success"; baris yang datang sebelumnya ditahan sampai saat itu.
+// Status yang dikembalikan adalah field "status" dari response.
+func decodeMutationResponse(r io.Reader, page *mutationPage, row func(json.RawMessage) error) (string, error) {
+	body := &bodyReader{r: r}
+	dec := json.NewDecoder(body)
+	parseErr := func(err error) error {
+		if body.err != nil {
+			return fmt.Errorf("%w: %w", errReadResponse, body.err)
+		}
+		return fmt.Errorf("failed to parse response / gagal parse response: %v", err)
+	}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", parseErr(err)
+	}
+
+	var status string
+	var held []json.RawMessage
+	statusKnown := false
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return status, parseErr(err)
+		}
+		switch tok {
+		case "status":
+			if err := dec.Decode(&status); err != nil {
+				return status, parseErr(err)
+			}
+			statusKnown = true
+			if status == "success" {
+				for _, data := range held {
+					if err := row(data); err != nil {
+						return status, err
+					}
+				}
+			}
+			held = nil
+		case "total_pages":
+			if err := dec.Decode(&page.totalPages); err != nil {
+				return status, parseErr(err)
+			}
+		case "data":
+			tok, err := dec.Token()
+			if err != nil {
+				return status, parseErr(err)
+			}
+			if tok == nil {
+				continue
+			}
+			emit := func(data json.RawMessage) error {
+				switch {
+				case !statusKnown:
+					held = append(held, data)
+				case status == "success":
+					return row(data)
+				}
+				return nil
+			}
+			if tok == json.Delim('{') {
+				// Some accounts send the rows as an object keyed by index, or a lone row as an object
+				rows, err := decodeDataObject(dec)
+				if err != nil {
+					return status, parseErr(err)
+				}
+				for _, data := range rows {
+					if err := emit(data); err != nil {
+						return status, err
+					}
+				}
+				continue
+			}
+			if tok != json.Delim('[') {
+				return status, parseErr(fmt.Errorf("data is %v, want an array / data bernilai %v, seharusnya array", tok, tok))
+			}
+			for dec.More() {
+				var data json.RawMessage
+				if err := dec.Decode(&data); err != nil {
+					return status, parseErr(err)
+				}
+				if err := emit(data); err != nil {
+					return status, err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return status, parseErr(err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return status, parseErr(err)
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return status, parseErr(err)
+	}
+	return status, nil
+}
+
+// decodeDataObject reads the rest of a "data" object after its '{': an object whose values are all
+// objects holds rows keyed by index, returned in order; any other object is a single row.
+// decodeDataObject membaca sisa objek "data" setelah '{'-nya: objek yang semua nilainya objek berisi
+// baris dengan key indeks, dikembalikan sesuai urutan; objek lainnya adalah satu baris.
+func decodeDataObject(dec *json.Decoder) ([]json.RawMessage, error) {
+	var (
+		keys   []string
+		values []json.RawMessage
+	)
+	allObjects := true
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+		if !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+			allObjects = false
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if allObjects {
+		return values, nil
+	}
+	var row bytes.Buffer
+	row.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			row.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		row.Write(k)
+		row.WriteByte(':')
+		row.Write(values[i])
+	}
+	row.WriteByte('}')
+	return []json.RawMessage{row.Bytes()}, nil
+}
+
+// expectDelim reads the next token and checks that it is delim.
+// expectDelim membaca token berikutnya dan memeriksa bahwa token tersebut adalah delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected %v, want %v / %v tidak terduga, seharusnya %v", tok, delim, tok, delim)
+	}
+	return nil
+}
+
+// bodyReader remembers the first error reading the response body, to tell it apart from invalid JSON.
+// bodyReader mengingat error pertama saat membaca body response, untuk membedakannya dari JSON yang tidak valid.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// gatewayURL returns the configured mutation endpoint.
+// gatewayURL mengembalikan endpoint mutasi yang dikonfigurasi.
+func (q *QRIS) gatewayURL() string {
+	if q.config.GatewayURL != "" {
+		return q.config.GatewayURL
+	}
+	if q.gateway() == GatewayOkeconnect {
+		return DefaultOkeconnectURL
+	}
+	return DefaultMutationURL
+}
+
+// gateway returns the configured built-in gateway.
+// gateway mengembalikan gateway bawaan yang dikonfigurasi.
+func (q *QRIS) gateway() string {
+	if g := strings.ToLower(q.config.Gateway); g != "" {
+		return g
+	}
+	return GatewayFTVPN
+}
+
+// userAgent returns the configured User-Agent header.
+// userAgent mengembalikan header User-Agent yang dikonfigurasi.
+func (q *QRIS) userAgent() string {
+	if q.config.UserAgent != "" {
+		return q.config.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+// location returns the configured timezone of gateway dates.
+// location mengembalikan zona waktu tanggal gateway yang dikonfigurasi.
+func (q *QRIS) location() *time.Location {
+	if q.config.Location != nil {
+		return q.config.Location
+	}
+	return WIB
+}
@@ -0,0 +1,55 @@
+package qris_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestIncludeRaw(t *testing.T) {
+	for _, fixture := range []string{"string_amounts.json", "numeric_amounts.json"} {
+		for _, includeRaw := range []bool{false, true} {
+			name := fixture
+			if includeRaw {
+				name += "/IncludeRaw"
+			}
+			t.Run(name, func(t *testing.T) {
+				body := mutationFixture(t, "raw/"+fixture)
+				srv := staticGateway(t, body)
+				q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+					c.GatewayURL = srv.URL
+					c.IncludeRaw = includeRaw
+				})
+				ctx := testContext(t, 5*time.Second)
+
+				// Unknown fields are ignored and both amount encodings decode alike
+				mutations, err := q.GetMutations(ctx, qris.MutationQuery{})
+				if err != nil {
+					t.Fatalf("GetMutations: %v", err)
+				}
+				if len(mutations) != 2 || mutations[0].Amount != 25000 || mutations[1].Amount != 10500 {
+					t.Fatalf("mutations = %+v, want 25000 and 10500", mutations)
+				}
+				for _, m := range mutations {
+					if includeRaw != (m.Raw != nil) {
+						t.Fatalf("Raw of %s = %s, want it kept %v", m.IssuerRef, m.Raw, includeRaw)
+					}
+					if includeRaw && (!json.Valid(m.Raw) || !bytes.Contains(body, m.Raw) || !bytes.Contains(m.Raw, []byte(m.IssuerRef))) {
+						t.Fatalf("Raw of %s = %s, want its row of the response", m.IssuerRef, m.Raw)
+					}
+				}
+
+				status, err := q.CheckPaymentStatusContext(ctx, "INV-1", 10500)
+				if err != nil || status.Status != qris.StatusPaid || status.Reference != "REF2" {
+					t.Fatalf("CheckPaymentStatus = %+v, %v, want REF2 paid", status, err)
+				}
+				if includeRaw != bytes.Equal(status.RawResponse, body) {
+					t.Fatalf("RawResponse = %s, want the response kept %v", status.RawResponse, includeRaw)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// CurrencyIDR is the ISO 4217 numeric code of the rupiah, carried in tag 53.
+// CurrencyIDR adalah kode numerik ISO 4217 untuk rupiah, dibawa di tag 53.
+const CurrencyIDR = "360"
+
+// ErrUnsupportedCurrency is returned when a base QRIS string is not in rupiah (tag 53 is not 360),
+// since amounts are always injected in rupiah. QRISConfig.AllowAnyCurrency skips the check.
+// ErrUnsupportedCurrency dikembalikan jika base QRIS string bukan rupiah (tag 53 bukan 360),
+// karena nominal selalu disisipkan dalam rupiah. QRISConfig.AllowAnyCurrency melewati pemeriksaan ini.
+var ErrUnsupportedCurrency = errors.New("unsupported currency / mata uang tidak didukung")
+
+// mandatoryTags are the top-level tags every QRIS payload must carry.
+// mandatoryTags adalah tag tingkat atas yang wajib ada di setiap payload QRIS.
+var mandatoryTags = []struct {
+	tag  string
+	name string
+}{
+	{"00", "payload format indicator"},
+	{"52", "merchant category code"},
+	{"53", "transaction currency"},
+	{"58", "country code"},
+	{"59", "merchant name"},
+	{"60", "merchant city"},
+	{"63", "CRC"},
+}
+
+// NormalizeQRISString cleans up a pasted QRIS string and checks that it is usable.
+// NormalizeQRISString merapikan string QRIS yang di-paste dan memeriksa bahwa string tersebut dapat dipakai.
+//
+// Surrounding whitespace and quotes and embedded line breaks are removed and a lowercase CRC
+// is uppercased. The CRC, the TLV structure, and the mandatory tags are then verified.
+// URLs and base64 images get an error explaining what was pasted instead.
+// Spasi dan tanda kutip di awal/akhir serta baris baru di dalamnya dihapus dan CRC huruf kecil
+// diubah ke huruf besar. Lalu CRC, struktur TLV, dan tag wajib diverifikasi.
+// URL dan gambar base64 mendapat error yang menjelaskan apa yang sebenarnya di-paste.
+func NormalizeQRISString(s string) (string, error) {
+	normalized, report := NormalizeQRISStringDetailed(s)
+	return normalized, report.Err()
+}
+
+// NormalizeQRISPayload cleans up and verifies s like NormalizeQRISString, then re-encodes it
+// with its tags and template sub-tags in ascending order and without zero-length fields, and
+// re-signs it with an uppercase CRC. The result differs from s only in the dropped empty fields
+// (see CompareQRIS). QRISConfig.NormalizeOutput does the same for generated payloads.
+// NormalizeQRISPayload merapikan dan memverifikasi s seperti NormalizeQRISString, lalu meng-encode
+// ulang dengan tag dan sub-tag template berurutan naik dan tanpa field dengan panjang nol, dan
+// menandatanganinya ulang dengan CRC berhuruf besar. Hasilnya hanya berbeda dari s pada field kosong
+// yang dibuang (lihat CompareQRIS). QRISConfig.NormalizeOutput melakukan hal yang sama untuk payload
+// yang di-generate.
+func NormalizeQRISPayload(s string) (string, error) {
+	cleaned, err := NormalizeQRISString(s)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := emv.Normalize(cleaned)
+	if err != nil {
+		return "", fmt.Errorf("failed to normalize QRIS payload / gagal menormalkan payload QRIS: %w", err)
+	}
+	return normalized, nil
+}
+
+// hasMerchantAccount reports whether the payload carries a domestic merchant account template.
+// hasMerchantAccount melaporkan apakah payload membawa template akun merchant domestik.
+func hasMerchantAccount(p *QRISPayload) bool {
+	for _, f := range p.Fields {
+		if isMerchantAccountTag(f.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func isQuote(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
@@ -0,0 +1,242 @@
+package qris_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestNormalizeQRISString(t *testing.T) {
+	without := func(tags ...string) string {
+		drop := make(map[string]bool)
+		for _, tag := range tags {
+			drop[tag] = true
+		}
+		return rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+			var out []qris.QRISField
+			for _, field := range f {
+				if !drop[field.Tag] {
+					out = append(out, field)
+				}
+			}
+			return out
+		})
+	}
+	lowerCRC := strings.TrimSuffix(testDynamicQR, "7D44") + "7d44"
+
+	tests := []struct {
+		name  string
+		input string
+		want  string // normalized string, empty if rejected
+		issue string // issue code reported
+	}{
+		{"clean", testBaseQR, testBaseQR, ""},
+		{"trailing newline", testBaseQR + "\n", testBaseQR, qris.IssueCleanedUp},
+		{"CRLF and spaces", "  " + testBaseQR + "\r\n", testBaseQR, qris.IssueCleanedUp},
+		{"double quotes", `"` + testBaseQR + `"`, testBaseQR, qris.IssueCleanedUp},
+		{"single quotes in spaces", ` '` + testBaseQR + `' `, testBaseQR, qris.IssueCleanedUp},
+		{"wrapped over lines", testBaseQR[:60] + "\n" + testBaseQR[60:], testBaseQR, qris.IssueCleanedUp},
+		{"lowercase CRC", lowerCRC, testDynamicQR, qris.IssueCRCLowercase},
+		{"empty", " \n", "", qris.IssueEmpty},
+		{"URL", "https://qris.example/pay/123", "", qris.IssueLooksLikeURL},
+		{"www URL", "www.qris.example", "", qris.IssueLooksLikeURL},
+		{"data URI", "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg", "", qris.IssueLooksLikeImage},
+		{"bare PNG base64", "iVBORw0KGgoAAAANSUhEUgAAAAEAAAAB", "", qris.IssueLooksLikeImage},
+		{"bare JPEG base64", "/9j/4AAQSkZJRgABAQAAAQABAAD", "", qris.IssueLooksLikeImage},
+		{"wrong CRC", strings.TrimSuffix(testBaseQR, "FF47") + "FF48", "", qris.IssueCRCMismatch},
+		{"bad prefix", "1" + testBaseQR[1:], "", qris.IssueBadPrefix},
+		{"truncated", testBaseQR[:50], "", qris.IssueCRCMissing},
+		{"missing merchant name", without("59"), "", qris.IssueMissingTag("59")},
+		{"missing merchant accounts", without("26", "51"), "", qris.IssueMissingMerchantAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report := qris.NormalizeQRISStringDetailed(tt.input)
+			if got != tt.want {
+				t.Fatalf("normalized = %q, want %q\n%v", got, tt.want, report.Issues)
+			}
+			if tt.issue != "" && !report.Has(tt.issue) {
+				t.Fatalf("issues %v lack %s", report.Issues, tt.issue)
+			}
+			_, err := qris.NormalizeQRISString(tt.input)
+			if (err == nil) != (tt.want != "") {
+				t.Fatalf("NormalizeQRISString err = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewQRISStoresNormalizedBase(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.BaseQrString = "\"" + testBaseQR + "\"\n" })
+	if got := q.Config().BaseQrString; got != testBaseQR {
+		t.Fatalf("BaseQrString = %q, want the normalized string", got)
+	}
+}
+
+func TestNewQRISExplainsPastedURL(t *testing.T) {
+	_, err := qris.NewQRIS(qris.QRISConfig{BaseQrString: "https://qris.example/pay/123", AuthToken: "token", AuthUsername: "user"})
+	if err == nil || !strings.Contains(err.Error(), "looks like a URL") {
+		t.Fatalf("err = %v, want it to explain that a URL was pasted", err)
+	}
+}
+
+func TestNormalizeQRISPayload(t *testing.T) {
+	// Tag 00 must stay first: a payload starting with anything else is rejected
+	reversed := rebuild(t, testDynamicQR, func(f []qris.QRISField) []qris.QRISField {
+		for i, j := 1, len(f)-1; i < j; i, j = i+1, j-1 {
+			f[i], f[j] = f[j], f[i]
+		}
+		return f
+	})
+	subTags := rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+		for i := range f {
+			if sub := f[i].SubFields; f[i].Tag == "26" {
+				sub[0], sub[len(sub)-1] = sub[len(sub)-1], sub[0]
+			}
+		}
+		return f
+	})
+	withEmpty := func(fields ...qris.QRISField) string {
+		return rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+			return append(append(f[:1:1], fields...), f[1:]...)
+		})
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string   // normalized payload / payload yang dinormalkan
+		removed []string // empty fields dropped, as CompareQRIS paths / field kosong yang dibuang, sebagai path CompareQRIS
+	}{
+		{name: "static in order", input: testBaseQR, want: testBaseQR},
+		{name: "dynamic in order", input: testDynamicQR, want: testDynamicQR},
+		{name: "pasted", input: ` "` + testBaseQR[:60] + "\n" + testBaseQR[60:] + `" `, want: testBaseQR},
+		{name: "lowercase CRC", input: strings.TrimSuffix(testDynamicQR, "7D44") + "7d44", want: testDynamicQR},
+		{name: "tags reversed", input: reversed, want: testDynamicQR},
+		{name: "sub-tags out of order", input: subTags, want: testBaseQR},
+		{name: "empty tag", input: withEmpty(qris.QRISField{Tag: "55"}), want: testBaseQR, removed: []string{"55"}},
+		{name: "empty sub-tag", input: withEmpty(qris.QRISField{Tag: "62", SubFields: []qris.QRISField{{Tag: "05"}, {Tag: "08", Value: "Order 1"}}}),
+			want: rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+				return append(f, qris.QRISField{Tag: "62", SubFields: []qris.QRISField{{Tag: "08", Value: "Order 1"}}})
+			}),
+			removed: []string{"62.05"}},
+		{name: "template left empty", input: withEmpty(qris.QRISField{Tag: "62", SubFields: []qris.QRISField{{Tag: "05"}}}), want: testBaseQR, removed: []string{"62.05"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qris.NormalizeQRISPayload(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeQRISPayload: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("normalized = %q, want %q", got, tt.want)
+			}
+			if crc := got[len(got)-4:]; crc != strings.ToUpper(crc) {
+				t.Fatalf("CRC %s is not uppercase", crc)
+			}
+			if err := qris.VerifyCRC(got); err != nil {
+				t.Fatalf("VerifyCRC: %v", err)
+			}
+
+			// Only the dropped empty fields may differ from the cleaned-up input
+			cleaned, err := qris.NormalizeQRISString(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeQRISString: %v", err)
+			}
+			diff, err := qris.CompareQRIS(cleaned, got)
+			if err != nil {
+				t.Fatalf("CompareQRIS: %v", err)
+			}
+			var removed []string
+			for _, c := range diff.Removed {
+				if c.Old != "" {
+					t.Errorf("removed %s = %q, want only empty fields", c.Path, c.Old)
+				}
+				removed = append(removed, c.Path)
+			}
+			if len(diff.Added) > 0 || len(diff.Changed) > 0 || strings.Join(removed, ",") != strings.Join(tt.removed, ",") {
+				t.Fatalf("diff:\n%s\nwant only %v removed", diff, tt.removed)
+			}
+
+			again, err := qris.NormalizeQRISPayload(got)
+			if err != nil || again != got {
+				t.Fatalf("second NormalizeQRISPayload = %q, %v, want it unchanged", again, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeQRISPayloadInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"https://qris.example/pay/123",
+		strings.TrimSuffix(testBaseQR, "FF47") + "FF48",
+		testBaseQR[:50],
+	} {
+		if got, err := qris.NormalizeQRISPayload(input); err == nil {
+			t.Errorf("NormalizeQRISPayload(%q) = %q, want an error", input, got)
+		}
+	}
+}
+
+func TestNormalizeOutput(t *testing.T) {
+	// A base QR with its merchant name before its category code
+	outOfOrder := rebuild(t, testBaseQR, func(f []qris.QRISField) []qris.QRISField {
+		for i := range f {
+			if f[i].Tag == "52" {
+				for j := range f {
+					if f[j].Tag == "59" {
+						f[i], f[j] = f[j], f[i]
+						break
+					}
+				}
+				break
+			}
+		}
+		return f
+	})
+	data := qris.QRISData{Amount: 25000, TransactionID: "INV-1", Note: "Order 1"}
+	payload := func(base string, normalize bool) string {
+		q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+			c.BaseQrString = base
+			c.NormalizeOutput = normalize
+			c.MatchPolicy = qris.MatchByBuyerRef()
+		})
+		s, err := q.BuildPayload(data)
+		if err != nil {
+			t.Fatalf("BuildPayload: %v", err)
+		}
+		return s
+	}
+
+	want := payload(testBaseQR, false)
+	tests := []struct {
+		name      string
+		base      string
+		normalize bool
+		ordered   bool
+	}{
+		{"ordered base", testBaseQR, true, true},
+		{"out of order base", outOfOrder, true, true},
+		{"out of order base kept", outOfOrder, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := payload(tt.base, tt.normalize)
+			if (got == want) != tt.ordered {
+				t.Fatalf("payload = %q, want ordered = %v (%q)", got, tt.ordered, want)
+			}
+			diff, err := qris.CompareQRIS(want, got)
+			if err != nil {
+				t.Fatalf("CompareQRIS: %v", err)
+			}
+			if !diff.Equal() {
+				t.Fatalf("payload differs from the ordered one:\n%s", diff)
+			}
+			if normalized, err := qris.NormalizeQRISPayload(got); err != nil || normalized != want {
+				t.Fatalf("NormalizeQRISPayload = %q, %v, want %q", normalized, err, want)
+			}
+		})
+	}
+}
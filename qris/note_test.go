@@ -0,0 +1,93 @@
+package qris_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+func TestNoteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*qris.QRISConfig)
+		data      qris.QRISData
+		want      string
+		wantRef   string
+	}{
+		{name: "plain", data: qris.QRISData{Note: "Order #1234"}, want: "Order #1234"},
+		{name: "spaces collapsed", data: qris.QRISData{Note: "  Order \t #1234\n"}, want: "Order #1234"},
+		{name: "non-ASCII dropped", data: qris.QRISData{Note: "Pesanan ✓ #12 – kopi"}, want: "Pesanan #12 kopi"},
+		{name: "only non-ASCII", data: qris.QRISData{Note: "✓✓"}, want: ""},
+		{name: "empty", data: qris.QRISData{}, want: ""},
+		{name: "25 characters", data: qris.QRISData{Note: strings.Repeat("N", 25)}, want: strings.Repeat("N", 25)},
+		{name: "truncated", data: qris.QRISData{Note: "Order #1234 for Budi Santoso", TruncateNote: true}, want: "Order #1234 for Budi Sant"},
+		{name: "truncated at a space", data: qris.QRISData{Note: "Order #1234 for Budi and Siti", TruncateNote: true}, want: "Order #1234 for Budi and"},
+		{
+			name:      "next to the reference label",
+			configure: func(c *qris.QRISConfig) { c.MatchPolicy = qris.MatchByBuyerRef() },
+			data:      qris.QRISData{Note: "Order #1234"},
+			want:      "Order #1234",
+			wantRef:   "INV-1",
+		},
+		{
+			name:    "open amount",
+			data:    qris.QRISData{Note: "Order #1234", Mode: qris.ModeOpenAmount},
+			want:    "Order #1234",
+			wantRef: "INV-1",
+		},
+		{
+			name:      "normalized output",
+			configure: func(c *qris.QRISConfig) { c.NormalizeOutput = true },
+			data:      qris.QRISData{Note: "Order #1234"},
+			want:      "Order #1234",
+		},
+		{
+			name:      "sandbox",
+			configure: func(c *qris.QRISConfig) { c.Sandbox = true },
+			data:      qris.QRISData{Note: "Order #1234"},
+			want:      qris.SandboxMarker + " Order #1234",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var configure []func(*qris.QRISConfig)
+			if tt.configure != nil {
+				configure = append(configure, tt.configure)
+			}
+			q := newTestQRIS(t, newGateway(t), configure...)
+			data := tt.data
+			if data.Mode != qris.ModeOpenAmount {
+				data.Amount = 25000
+			}
+			data.TransactionID = "INV-1"
+
+			payload, err := q.BuildPayload(data)
+			if err != nil {
+				t.Fatalf("BuildPayload: %v", err)
+			}
+			parsed, err := qris.ParseQRISString(payload)
+			if err != nil {
+				t.Fatalf("ParseQRISString: %v", err)
+			}
+			if got := parsed.Note(); got != tt.want {
+				t.Errorf("Note = %q, want %q", got, tt.want)
+			}
+			f, _ := parsed.Field("62")
+			ref, _ := f.SubField("05")
+			if ref.Value != tt.wantRef {
+				t.Errorf("reference label = %q, want %q", ref.Value, tt.wantRef)
+			}
+
+			// The emv package reads the same field
+			raw, err := emv.Parse(payload)
+			if err != nil {
+				t.Fatalf("emv.Parse: %v", err)
+			}
+			if got := raw.Purpose(); got != tt.want {
+				t.Errorf("emv Purpose = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package qris_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestOkeconnectFixtures(t *testing.T) {
+	paidAt := time.Date(2024, 5, 1, 10, 0, 0, 0, qris.WIB)
+	tests := []struct {
+		fixture  string
+		wantDate string
+	}{
+		{"space.json", "2024-05-01 10:00:00"},
+		{"iso.json", "2024-05-01T10:00:00"},
+		{"offset.json", "2024-05-01T03:00:00Z"},
+		{"object.json", "2024-05-01 10:00:00"},
+		{"single.json", "2024-05-01 10:00:00"},
+	}
+	for _, tt := range tests {
+		body, err := os.ReadFile(filepath.Join("testdata", "okeconnect", tt.fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := staticGateway(t, body)
+
+		// Both gateways share the decoding and matching path, so they must agree
+		for _, gateway := range []string{qris.GatewayOkeconnect, qris.GatewayFTVPN} {
+			t.Run(tt.fixture+"/"+gateway, func(t *testing.T) {
+				q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+					c.Gateway = gateway
+					c.GatewayURL = srv.URL + "/"
+					c.MatchWindow = time.Since(paidAt) + 24*time.Hour // Reach back to the fixture dates
+				})
+				status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "ORDER1", 15000)
+				if err != nil {
+					t.Fatalf("CheckPaymentStatus: %v", err)
+				}
+				want := qris.PaymentStatus{
+					Status:    qris.StatusPaid,
+					Amount:    15000,
+					Reference: "REF2",
+					Date:      tt.wantDate,
+					BrandName: "OVO",
+					Issuer:    qris.NormalizeIssuer("OVO"),
+					PaidAt:    paidAt,
+					Warning:   qris.WarningAmountOnly,
+				}
+				if !status.PaidAt.Equal(want.PaidAt) || status.PaidAt.Location() != qris.WIB {
+					t.Errorf("PaidAt = %v, want %v in WIB", status.PaidAt, want.PaidAt)
+				}
+				status.PaidAt = want.PaidAt
+				if !reflect.DeepEqual(*status, want) {
+					t.Errorf("status = %+v, want %+v", *status, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package qris
+
+import (
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// QRISField is a single TLV (tag-length-value) field of a QRIS payload; see emv.QRISField.
+// QRISField adalah satu field TLV (tag-length-value) dari payload QRIS; lihat emv.QRISField.
+type QRISField = emv.QRISField
+
+// QRISPayload is a parsed QRIS payload.
+// QRISPayload adalah payload QRIS yang sudah di-parse.
+type QRISPayload emv.QRISPayload
+
+// Field returns the first top-level field with the given tag.
+// Field mengembalikan field tingkat atas pertama dengan tag tertentu.
+func (p *QRISPayload) Field(tag string) (QRISField, bool) {
+	return (*emv.QRISPayload)(p).Field(tag)
+}
+
+// Value returns the value of the first top-level field with the given tag, or "".
+// Value mengembalikan nilai field tingkat atas pertama dengan tag tertentu, atau "".
+func (p *QRISPayload) Value(tag string) string {
+	return (*emv.QRISPayload)(p).Value(tag)
+}
+
+// Currency returns the ISO 4217 numeric code of tag 53 (transaction currency), such as CurrencyIDR, or "".
+// Currency mengembalikan kode numerik ISO 4217 dari tag 53 (mata uang transaksi), misalnya CurrencyIDR, atau "".
+func (p *QRISPayload) Currency() string {
+	return (*emv.QRISPayload)(p).Currency()
+}
+
+// Note returns the customer-visible note (tag 62, sub-tag 08) set by QRISData.Note, or "".
+// In sandbox mode it starts with SandboxMarker.
+// Note mengembalikan catatan yang terlihat customer (tag 62, sub-tag 08) yang diatur QRISData.Note,
+// atau "". Dalam mode sandbox catatan diawali SandboxMarker.
+func (p *QRISPayload) Note() string {
+	return (*emv.QRISPayload)(p).Purpose()
+}
+
+// ParseQRISString parses a QRIS payload into its TLV fields.
+// ParseQRISString mem-parse payload QRIS menjadi field-field TLV.
+//
+// It never panics: malformed input such as truncated lengths, non-numeric lengths,
+// or nested templates overflowing their parent returns an error. The CRC is not verified.
+// Fungsi ini tidak pernah panic: input rusak seperti panjang terpotong, panjang non-numerik,
+// atau template bersarang yang melebihi induknya mengembalikan error. CRC tidak diverifikasi.
+func ParseQRISString(s string) (*QRISPayload, error) {
+	payload, err := emv.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return (*QRISPayload)(&payload), nil
+}
@@ -0,0 +1,191 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PaymentCodeLength is the length of the short codes minted for payment links.
+// PaymentCodeLength adalah panjang kode pendek yang dibuat untuk link pembayaran.
+const PaymentCodeLength = 8
+
+// paymentCodeAlphabet holds URL-safe characters that are hard to confuse when read aloud or retyped.
+// paymentCodeAlphabet berisi karakter aman-URL yang sulit tertukar saat dibacakan atau diketik ulang.
+const paymentCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// codeRetention is how long an expired code keeps resolving to ErrPaymentExpired instead of ErrNotFound.
+// codeRetention adalah lamanya kode yang kedaluwarsa tetap di-resolve menjadi ErrPaymentExpired, bukan ErrNotFound.
+const codeRetention = 24 * time.Hour
+
+// maxCodeAttempts bounds the retries after a code collision.
+// maxCodeAttempts membatasi percobaan ulang setelah kode bertabrakan.
+const maxCodeAttempts = 5
+
+// CodeStore is implemented by invoice stores that keep payment link codes, so codes are unique across
+// processes sharing the store and still resolve after a restart. SQLiteStore and JSONFileStore implement it.
+// CodeStore diimplementasikan oleh invoice store yang menyimpan kode link pembayaran, sehingga kode unik antar
+// proses yang memakai store yang sama dan tetap dapat di-resolve setelah restart. SQLiteStore dan JSONFileStore
+// mengimplementasikannya.
+type CodeStore interface {
+	// ReserveCode records code for transactionID and reports false if the code is already taken.
+	// ReserveCode mencatat code untuk transactionID dan melaporkan false jika kode sudah dipakai.
+	ReserveCode(ctx context.Context, code, transactionID string, expiresAt time.Time) (bool, error)
+
+	// LookupCode returns the invoice of code, or ErrNotFound.
+	// LookupCode mengembalikan invoice dari code, atau ErrNotFound.
+	LookupCode(ctx context.Context, code string) (transactionID string, expiresAt time.Time, err error)
+}
+
+// codeEntry is a code minted by this instance.
+// codeEntry adalah kode yang dibuat oleh instance ini.
+type codeEntry struct {
+	session   *PaymentSession
+	expiresAt time.Time
+}
+
+// codeRegistry maps the payment link codes of an instance to their sessions.
+// codeRegistry memetakan kode link pembayaran dari sebuah instance ke sesinya.
+type codeRegistry struct {
+	mu    sync.Mutex
+	codes map[string]codeEntry
+}
+
+func newCodeRegistry() *codeRegistry {
+	return &codeRegistry{codes: make(map[string]codeEntry)}
+}
+
+// add registers the code of s, forgetting codes expired longer than codeRetention ago.
+// add mendaftarkan kode dari s, dan melupakan kode yang kedaluwarsa lebih dari codeRetention.
+func (r *codeRegistry) add(s *PaymentSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for code, e := range r.codes {
+		if time.Since(e.expiresAt) > codeRetention {
+			delete(r.codes, code)
+		}
+	}
+	r.codes[s.code] = codeEntry{session: s, expiresAt: s.ExpiresAt}
+}
+
+func (r *codeRegistry) get(code string) (codeEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.codes[code]
+	return e, ok
+}
+
+// newPaymentCode returns a random code of PaymentCodeLength characters.
+// newPaymentCode mengembalikan kode acak sepanjang PaymentCodeLength karakter.
+func (q *QRIS) newPaymentCode() (string, error) {
+	// Bytes at or above limit are skipped so every character is equally likely
+	limit := 256 - 256%len(paymentCodeAlphabet)
+	code := make([]byte, 0, PaymentCodeLength)
+	buf := make([]byte, PaymentCodeLength)
+	for len(code) < PaymentCodeLength {
+		if err := q.rand.read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate payment code / gagal generate kode pembayaran: %v", err)
+		}
+		for _, c := range buf {
+			if int(c) < limit && len(code) < PaymentCodeLength {
+				code = append(code, paymentCodeAlphabet[int(c)%len(paymentCodeAlphabet)])
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// mintCode returns a new code for transactionID that is unused in this instance and in store if
+// it is a CodeStore, or its signed token with QRISConfig.LinkTokens.
+// mintCode mengembalikan kode baru untuk transactionID yang belum dipakai di instance ini dan di store
+// jika store adalah CodeStore, atau token bertanda tangannya dengan QRISConfig.LinkTokens.
+func (q *QRIS) mintCode(ctx context.Context, transactionID string, amount int64, expiresAt time.Time, store InvoiceStore) (string, error) {
+	if q.config.LinkTokens != nil {
+		return q.config.LinkTokens.Encode(PaymentToken{TransactionID: transactionID, Amount: amount, ExpiresAt: expiresAt})
+	}
+
+	codes, _ := store.(CodeStore)
+	for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+		code, err := q.newPaymentCode()
+		if err != nil {
+			return "", err
+		}
+		if _, taken := q.codes.get(code); taken {
+			continue
+		}
+		if codes != nil {
+			ok, err := codes.ReserveCode(ctx, code, transactionID, expiresAt)
+			if err != nil {
+				return "", fmt.Errorf("failed to reserve payment code / gagal memesan kode pembayaran: %v", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		return code, nil
+	}
+	return "", errors.New("no unused payment code found / tidak ada kode pembayaran yang belum dipakai")
+}
+
+// Code returns the short URL-safe code of the session for payment links; see QRIS.ResolveCode.
+// With QRISConfig.LinkTokens it is a signed token of the transaction ID, amount and expiry instead.
+// Code mengembalikan kode pendek aman-URL dari sesi untuk link pembayaran; lihat QRIS.ResolveCode.
+// Dengan QRISConfig.LinkTokens, kode ini berupa token bertanda tangan dari ID transaksi, nominal dan waktu kedaluwarsa.
+func (s *PaymentSession) Code() string {
+	return s.code
+}
+
+// ResolveCode returns the session of a code from PaymentSession.Code. Codes expire with their invoice:
+// expired codes fail with ErrPaymentExpired and unknown ones with ErrNotFound. Codes of sessions
+// created by another process sharing the InvoiceStore resolve only if this instance resumed them.
+// ResolveCode mengembalikan sesi dari kode PaymentSession.Code. Kode kedaluwarsa bersama invoice-nya:
+// kode yang kedaluwarsa gagal dengan ErrPaymentExpired dan kode yang tidak dikenal dengan ErrNotFound. Kode
+// dari sesi yang dibuat proses lain yang memakai InvoiceStore yang sama hanya dapat di-resolve jika instance
+// ini melanjutkannya.
+//
+// With QRISConfig.LinkTokens, tokens are verified without the store: tampered ones fail with
+// ErrNotFound and ErrTokenInvalid, expired ones with ErrPaymentExpired and ErrTokenExpired.
+// Dengan QRISConfig.LinkTokens, token diverifikasi tanpa store: token yang diubah gagal dengan
+// ErrNotFound dan ErrTokenInvalid, token kedaluwarsa dengan ErrPaymentExpired dan ErrTokenExpired.
+func (q *QRIS) ResolveCode(ctx context.Context, code string) (*PaymentSession, error) {
+	if e, ok := q.codes.get(code); ok {
+		if !time.Now().Before(e.expiresAt) {
+			return nil, fmt.Errorf("%w: code %s / kode %s", ErrPaymentExpired, code, code)
+		}
+		return e.session, nil
+	}
+
+	if q.config.LinkTokens != nil {
+		t, err := q.config.LinkTokens.Decode(code)
+		switch {
+		case errors.Is(err, ErrTokenExpired):
+			return nil, fmt.Errorf("%w: %w", ErrPaymentExpired, err)
+		case err != nil:
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		if s, ok := q.sessions.get(t.TransactionID); ok {
+			return s, nil
+		}
+		return nil, ErrNotFound
+	}
+
+	codes, ok := q.config.InvoiceStore.(CodeStore)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	transactionID, expiresAt, err := codes.LookupCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(expiresAt) {
+		return nil, fmt.Errorf("%w: code %s / kode %s", ErrPaymentExpired, code, code)
+	}
+	if s, ok := q.sessions.get(transactionID); ok {
+		return s, nil
+	}
+	return nil, ErrNotFound
+}
@@ -1,25 +1,67 @@
 package qris
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"io"
-	"log"
+	"errors"
 	"net/http"
-	"strconv"
-	"strings"
+	"sort"
 	"time"
 )
 
+// Payment status values reported in PaymentStatus.Status.
+// Nilai status pembayaran yang dilaporkan di PaymentStatus.Status.
+const (
+	StatusPaid      = "PAID"      // Payment received / Pembayaran diterima
+	StatusUnpaid    = "UNPAID"    // Payment not received yet / Pembayaran belum diterima
+	StatusExpired   = "EXPIRED"   // Invoice expired before payment / Invoice kedaluwarsa sebelum dibayar
+	StatusCancelled = "CANCELLED" // Invoice cancelled by the merchant / Invoice dibatalkan oleh merchant
+)
+
+// WarningAmountOnly is the PaymentStatus.Warning of a check whose reference is neither a session
+// of the instance nor an invoice of its InvoiceStore: any credit of the amount within MatchWindow
+// matches, whoever paid it.
+// WarningAmountOnly adalah PaymentStatus.Warning dari pengecekan yang reference-nya bukan sesi dari
+// instance maupun invoice dari InvoiceStore-nya: kredit apa pun dengan nominal tersebut dalam
+// MatchWindow cocok, siapa pun yang membayarnya.
+const WarningAmountOnly = "reference is not a known invoice, matched by amount only / reference bukan invoice yang dikenal, dicocokkan hanya berdasarkan nominal"
+
+// DefaultMatchWindow is how far back CheckPaymentStatus looks for a matching mutation.
+// DefaultMatchWindow adalah rentang waktu ke belakang yang dipakai CheckPaymentStatus untuk mencari mutasi yang cocok.
+const DefaultMatchWindow = 5 * time.Minute
+
 // PaymentStatus stores the payment status information.
 // PaymentStatus menyimpan informasi status pembayaran.
 type PaymentStatus struct {
-	Status    string // Payment status (PAID/UNPAID) / Status pembayaran (PAID/UNPAID)
+	Status    string // Payment status (PAID/UNPAID/EXPIRED/CANCELLED) / Status pembayaran (PAID/UNPAID/EXPIRED/CANCELLED)
 	Amount    int64  // Payment amount / Nominal pembayaran
 	Reference string // Payment reference / Referensi pembayaran
-	Date      string // Payment date (if PAID) / Tanggal pembayaran (jika PAID)
-	BrandName string // Payer brand name (if PAID) / Nama brand pembayar (jika PAID)
+	Date      string // Payment date as sent by the gateway (if PAID) / Tanggal pembayaran sesuai kiriman gateway (jika PAID)
+	BrandName string // Payer brand name as sent by the gateway (if PAID) / Nama brand pembayar sesuai kiriman gateway (jika PAID)
+	Issuer    Issuer // Canonical issuer of BrandName (if PAID) / Issuer kanonis dari BrandName (jika PAID)
 	BuyerRef  string // Buyer reference (if PAID) / Referensi pembeli (jika PAID)
+
+	PaidAt time.Time // Date parsed in QRISConfig.Location, zero if not paid or unparsable / Date yang di-parse dalam QRISConfig.Location, nol jika belum dibayar atau tidak valid
+
+	Metadata map[string]string `json:",omitempty"` // Metadata of the PaymentSession, see WithMetadata / Metadata dari PaymentSession, lihat WithMetadata
+	Warning  string            `json:",omitempty"` // Caveat of the check, e.g. WarningAmountOnly / Catatan dari pengecekan, misalnya WarningAmountOnly
+
+	RawResponse json.RawMessage // Raw gateway response (if IncludeRaw) / Response mentah gateway (jika IncludeRaw)
+}
+
+// MarshalJSON encodes the status with PaidAt as RFC3339, or null if it is zero.
+// MarshalJSON meng-encode status dengan PaidAt sebagai RFC3339, atau null jika nol.
+func (s PaymentStatus) MarshalJSON() ([]byte, error) {
+	type plain PaymentStatus
+	var paidAt *string
+	if !s.PaidAt.IsZero() {
+		v := s.PaidAt.Format(time.RFC3339)
+		paidAt = &v
+	}
+	return json.Marshal(struct {
+		plain
+		PaidAt *string
+	}{plain(s), paidAt})
 }
 
 // PaymentCheckerConfig stores the configuration for payment checking.
@@ -27,7 +69,12 @@ type PaymentStatus struct {
 type PaymentCheckerConfig struct {
 	MerchantID string // Merchant ID from payment gateway / ID merchant dari payment gateway
 	APIKey     string // API key for authentication / API key untuk autentikasi
-	BaseURL    string // Base URL for API calls / URL dasar untuk panggilan API
+
+	// Deprecated: BaseURL is not used. Set QRISConfig.GatewayURL, which defaults to
+	// DefaultMutationURL or DefaultOkeconnectURL, to change the endpoint of one instance.
+	// BaseURL tidak dipakai. Atur QRISConfig.GatewayURL, yang default-nya DefaultMutationURL atau
+	// DefaultOkeconnectURL, untuk mengganti endpoint satu instance.
+	BaseURL string
 }
 
 // PaymentChecker is the main struct for payment checking operations.
@@ -53,138 +100,242 @@ func NewPaymentChecker(config PaymentCheckerConfig) *PaymentChecker {
 //
 // It returns a PaymentStatus struct containing the payment information.
 // Fungsi ini mengembalikan struct PaymentStatus yang berisi informasi pembayaran.
+//
+// An amount of 0 checks an open-amount QR code (ModeOpenAmount) generated with reference as
+// transaction ID; it is matched by buyer reference and the paid amount is reported.
+// Nominal 0 mengecek QR code nominal terbuka (ModeOpenAmount) yang di-generate dengan reference
+// sebagai ID transaksi; pembayaran dicocokkan berdasarkan referensi pembeli dan nominal yang dibayar dilaporkan.
+//
+// With QRISConfig.DeterministicAmounts, amount is the base amount and the payable amount is
+// recomputed from reference with DeterministicAmount over QRISConfig.UniqueSuffix.
+// Dengan QRISConfig.DeterministicAmounts, amount adalah nominal dasar dan nominal yang harus dibayar
+// dihitung ulang dari reference dengan DeterministicAmount di atas QRISConfig.UniqueSuffix.
+//
+// When reference is the transaction ID of a session of this instance, or of an invoice of an
+// InvoiceStore implementing InvoiceLoader, the invoice decides: amount is ignored, the unique
+// amount of the invoice is matched from its creation on, and a finished invoice reports its status.
+// Otherwise only the amount is matched and the status carries WarningAmountOnly.
+// Jika reference adalah ID transaksi dari sesi instance ini, atau dari invoice di InvoiceStore yang
+// mengimplementasikan InvoiceLoader, invoice tersebut yang menentukan: amount diabaikan, nominal unik
+// invoice dicocokkan sejak invoice dibuat, dan invoice yang sudah selesai melaporkan statusnya.
+// Selain itu hanya nominal yang dicocokkan dan status membawa WarningAmountOnly.
 func (q *QRIS) CheckPaymentStatus(reference string, amount int64) (*PaymentStatus, error) {
-	if reference == "" || amount <= 0 {
-		return nil, fmt.Errorf("reference and amount must be filled correctly / reference dan amount harus diisi dengan benar")
-	}
+	return q.CheckPaymentStatusContext(context.Background(), reference, amount)
+}
 
-	// Create API URL
-	url := "https://ftvpn.me/api/mutasi"
-	log.Printf("Checking payment status for amount: %d", amount)
+// CheckPaymentStatusContext is like CheckPaymentStatus but carries a context for cancellation.
+// CheckPaymentStatusContext sama seperti CheckPaymentStatus tetapi membawa context untuk pembatalan.
+func (q *QRIS) CheckPaymentStatusContext(ctx context.Context, reference string, amount int64) (*PaymentStatus, error) {
+	return q.checkPaymentStatus(ctx, reference, amount, q.eachMutation)
+}
 
-	// Create request body
-	requestBody := map[string]string{
-		"auth_token":    q.config.AuthToken,
-		"auth_username": q.config.AuthUsername,
+// checkPaymentStatus implements CheckPaymentStatusContext on top of source.
+// checkPaymentStatus mengimplementasikan CheckPaymentStatusContext di atas source.
+func (q *QRIS) checkPaymentStatus(ctx context.Context, reference string, amount int64, source mutationSource) (*PaymentStatus, error) {
+	if s, ok := q.sessions.get(reference); ok {
+		return s.check(ctx, source)
 	}
-	
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body / gagal marshal request body: %v", err)
+	if inv, ok, err := q.loadInvoice(ctx, reference); err != nil {
+		return nil, err
+	} else if ok {
+		return q.checkInvoice(ctx, inv, source)
 	}
 
-	// Create request
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request / gagal membuat request: %v", err)
+	if amount != 0 {
+		if err := q.checkAmount(amount); err != nil {
+			return nil, err
+		}
+		if q.config.DeterministicAmounts {
+			amount = DeterministicAmount(amount, reference, int(q.uniqueSuffix()))
+		}
+	}
+	inv := Invoice{
+		TransactionID: reference,
+		Merchant:      q.merchant,
+		Amount:        amount,
+		Status:        StatusUnpaid,
+		CreatedAt:     time.Now().Add(-q.matchWindow()),
 	}
-	
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
+	policy := q.matchPolicy()
+	if amount == 0 {
+		policy = MatchByBuyerRef()
+	}
+	status, err := q.checkPayment(ctx, inv, policy, source)
+	if err == nil && amount != 0 && !q.config.DeterministicAmounts {
+		status.Warning = WarningAmountOnly
+	}
+	return status, err
+}
 
-	// Send request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+// loadInvoice returns the invoice of reference from the InvoiceStore, if it is an InvoiceLoader.
+// loadInvoice mengembalikan invoice dari reference dari InvoiceStore, jika store tersebut adalah InvoiceLoader.
+func (q *QRIS) loadInvoice(ctx context.Context, reference string) (Invoice, bool, error) {
+	loader, ok := q.config.InvoiceStore.(InvoiceLoader)
+	if !ok || reference == "" {
+		return Invoice{}, false, nil
+	}
+	inv, err := loader.LoadInvoice(ctx, reference)
+	if errors.Is(err, ErrNotFound) {
+		return Invoice{}, false, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request / gagal mengirim request: %v", err)
+		return Invoice{}, false, err
 	}
-	defer resp.Body.Close()
+	// Like sessions, allow for the gateway clock running behind
+	inv.CreatedAt = inv.CreatedAt.Add(-clockSkew)
+	return inv, true, nil
+}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+// checkInvoice checks a known invoice: a finished one reports its status, an unpaid one is matched
+// by its own amount since its creation.
+// checkInvoice mengecek invoice yang dikenal: invoice yang sudah selesai melaporkan statusnya, invoice
+// yang belum dibayar dicocokkan dengan nominalnya sendiri sejak dibuat.
+func (q *QRIS) checkInvoice(ctx context.Context, inv Invoice, source mutationSource) (*PaymentStatus, error) {
+	switch {
+	case inv.Status == StatusPaid && inv.Payment != nil:
+		status := *inv.Payment
+		status.Metadata = inv.Metadata
+		return &status, nil
+	case inv.Status != StatusUnpaid && inv.Status != "":
+		return &PaymentStatus{Status: inv.Status, Amount: inv.Amount, Reference: inv.TransactionID, Metadata: inv.Metadata}, nil
+	}
+
+	policy := q.matchPolicy()
+	if inv.Amount == 0 {
+		policy = MatchByBuyerRef()
+	}
+	status, err := q.checkPayment(ctx, inv, policy, source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response / gagal membaca response: %v", err)
+		return nil, err
 	}
+	status.Metadata = inv.Metadata
+	return status, nil
+}
 
-	// Parse response
-	var response struct {
-		Status string `json:"status"`
-		Data   []struct {
-			Amount      string `json:"amount"`
-			Date        string `json:"date"`
-			QRIS        string `json:"qris"`
-			Type        string `json:"type"`
-			IssuerRef   string `json:"issuer_reff"`
-			BrandName   string `json:"brand_name"`
-			BuyerRef    string `json:"buyer_reff"`
-		} `json:"data"`
+// matchWindow returns the configured look-back window of CheckPaymentStatus.
+// matchWindow mengembalikan rentang pencarian CheckPaymentStatus yang dikonfigurasi.
+func (q *QRIS) matchWindow() time.Duration {
+	if q.config.MatchWindow > 0 {
+		return q.config.MatchWindow
 	}
+	return DefaultMatchWindow
+}
 
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response / gagal parse response: %v", err)
+// uniqueSuffix returns the configured largest suffix of unique amounts.
+// uniqueSuffix mengembalikan suffix terbesar nominal unik yang dikonfigurasi.
+func (q *QRIS) uniqueSuffix() int64 {
+	if q.config.UniqueSuffix > 0 {
+		return q.config.UniqueSuffix
 	}
+	return DefaultUniqueSuffix
+}
 
-	if response.Status != "success" || len(response.Data) == 0 {
-		return &PaymentStatus{
-			Status:    "UNPAID",
-			Amount:    amount,
-			Reference: reference,
-		}, nil
+// checkPayment looks for a mutation that pays inv according to policy.
+// inv.CreatedAt is the start of the match window; mutations are read from source.
+// checkPayment mencari mutasi yang membayar inv sesuai policy.
+// inv.CreatedAt adalah awal rentang pencarian; mutasi dibaca dari source.
+func (q *QRIS) checkPayment(ctx context.Context, inv Invoice, policy MatchPolicy, source mutationSource) (*PaymentStatus, error) {
+	if inv.TransactionID == "" || inv.Amount < 0 || (inv.Amount == 0 && !embedsReference(policy)) {
+		return nil, WithCode(errors.New("reference and amount must be filled correctly / reference dan amount harus diisi dengan benar"), "INVALID_REFERENCE")
 	}
 
-	// Find matching transactions
-	var matchingTransactions []struct {
-		Amount      string `json:"amount"`
-		Date        string `json:"date"`
-		QRIS        string `json:"qris"`
-		Type        string `json:"type"`
-		IssuerRef   string `json:"issuer_reff"`
-		BrandName   string `json:"brand_name"`
-		BuyerRef    string `json:"buyer_reff"`
-	}
-
-	now := time.Now()
-	for _, tx := range response.Data {
-		txAmount, _ := strconv.Atoi(tx.Amount)
-		
-		// Parse transaction date
-		txDate, err := time.Parse("2006-01-02 15:04:05", tx.Date)
-		if err != nil {
-			continue
-		}
+	if inv.Amount == 0 {
+		logf(ctx, "Checking payment status for reference: %s", inv.TransactionID)
+	} else {
+		logf(ctx, "Checking payment status for amount: %d", inv.Amount)
+	}
 
-		timeDiff := now.Sub(txDate)
-		
-		// Check if transaction matches criteria
-		if int64(txAmount) == amount &&
-			tx.QRIS == "static" &&
-			tx.Type == "CR" &&
-			timeDiff <= 5*time.Minute {
-			matchingTransactions = append(matchingTransactions, tx)
-		}
+	matchingTransactions, page, err := q.matchingPayments(ctx, inv, policy, source, true)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(matchingTransactions) > 0 {
-		// Get latest transaction
-		latestTx := matchingTransactions[0]
-		latestDate, _ := time.Parse("2006-01-02 15:04:05", latestTx.Date)
-		
-		for _, tx := range matchingTransactions[1:] {
-			txDate, _ := time.Parse("2006-01-02 15:04:05", tx.Date)
-			if txDate.After(latestDate) {
-				latestTx = tx
-				latestDate = txDate
-			}
-		}
+		// Best match first
+		bestTx := matchingTransactions[0]
 
-		txAmount, _ := strconv.Atoi(latestTx.Amount)
-		log.Printf("Payment found: Amount=%d, Date=%s, Brand=%s", 
-			txAmount, latestTx.Date, latestTx.BrandName)
+		logf(ctx, "Payment found: Amount=%d, Date=%s, Brand=%s",
+			bestTx.Amount, bestTx.Date, bestTx.BrandName)
 
 		return &PaymentStatus{
-			Status:    "PAID",
-			Amount:    int64(txAmount),
-			Reference: latestTx.IssuerRef,
-			Date:      latestTx.Date,
-			BrandName: latestTx.BrandName,
-			BuyerRef:  latestTx.BuyerRef,
+			Status:      StatusPaid,
+			Amount:      bestTx.Amount,
+			Reference:   bestTx.IssuerRef,
+			Date:        bestTx.Date,
+			BrandName:   bestTx.BrandName,
+			Issuer:      bestTx.Issuer,
+			BuyerRef:    bestTx.BuyerRef,
+			RawResponse: page.raw,
+			PaidAt:      bestTx.Time,
 		}, nil
 	}
 
-	log.Printf("No matching payment found for amount: %d", amount)
+	logf(ctx, "No matching payment found for amount: %d", inv.Amount)
 	return &PaymentStatus{
-		Status:    "UNPAID",
-		Amount:    amount,
-		Reference: reference,
+		Status:      StatusUnpaid,
+		Amount:      inv.Amount,
+		Reference:   inv.TransactionID,
+		RawResponse: page.raw,
 	}, nil
-}
\ No newline at end of file
+}
+
+// matchingPayments returns the credits dated since inv.CreatedAt that policy accepts,
+// ordered best match first: by score, then latest first. With bestOnly it stops reading after the
+// first match of the policy's top score at the first older row, since no later row can beat it
+// when rows come newest first. Rows out of that order are read to the end.
+// matchingPayments mengembalikan kredit sejak inv.CreatedAt yang diterima policy,
+// diurutkan dari yang paling cocok: berdasarkan skor, lalu yang terbaru. Dengan bestOnly, fungsi ini
+// berhenti membaca setelah kecocokan pertama dengan skor tertinggi policy pada baris pertama yang lebih lama,
+// karena tidak ada baris berikutnya yang dapat mengalahkannya jika baris datang dari yang terbaru. Baris yang
+// tidak berurutan seperti itu dibaca sampai habis.
+func (q *QRIS) matchingPayments(ctx context.Context, inv Invoice, policy MatchPolicy, source mutationSource, bestOnly bool) ([]Mutation, mutationPage, error) {
+	type match struct {
+		m     Mutation
+		score int
+	}
+
+	// A stopped read leaves the raw response cut short
+	top, stopAtTop := topScore(policy)
+	stopAtTop = stopAtTop && bestOnly && !q.config.IncludeRaw
+
+	// Only fetch credits inside the match window
+	var (
+		matches []match
+		prev    time.Time
+		topAt   time.Time // time of the first match of the top score / waktu kecocokan pertama dengan skor tertinggi
+	)
+	page, err := source(ctx, MutationQuery{From: inv.CreatedAt, Type: "CR"}, func(m Mutation) error {
+		// A row newer than the one before means the gateway does not sort newest first
+		if !prev.IsZero() && m.Time.After(prev) {
+			stopAtTop = false
+		}
+		prev = m.Time
+		// Rows come newest first and this one is older than the top match, so no later row ties it
+		if stopAtTop && !topAt.IsZero() && m.Time.Before(topAt) {
+			return errStopMutations
+		}
+		if r := policy.Match(inv, m); r.Matched {
+			matches = append(matches, match{m: m, score: r.Score})
+			if r.Score >= top && topAt.IsZero() {
+				topAt = m.Time
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopMutations) {
+		return nil, page, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].m.Time.After(matches[j].m.Time)
+	})
+
+	mutations := make([]Mutation, len(matches))
+	for i, mt := range matches {
+		mutations[i] = mt.m
+	}
+	return mutations, page, nil
+}
@@ -0,0 +1,231 @@
+package qris_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+func TestCheckPaymentStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  func(*qristest.Server)
+		amount  int64
+		status  string
+		wantErr error
+	}{
+		{
+			name:   "paid",
+			script: func(gw *qristest.Server) { gw.AddMutation(10001, time.Now(), qristest.WithBrand("OVO")) },
+			amount: 10001,
+			status: qris.StatusPaid,
+		},
+		{
+			name:   "other amount",
+			script: func(gw *qristest.Server) { gw.AddMutation(10002, time.Now()) },
+			amount: 10001,
+			status: qris.StatusUnpaid,
+		},
+		{
+			name:   "outside the match window",
+			script: func(gw *qristest.Server) { gw.AddMutation(10001, time.Now().Add(-2*qris.DefaultMatchWindow)) },
+			amount: 10001,
+			status: qris.StatusUnpaid,
+		},
+		{
+			name:   "debit",
+			script: func(gw *qristest.Server) { gw.AddMutation(10001, time.Now(), qristest.WithType("DB")) },
+			amount: 10001,
+			status: qris.StatusUnpaid,
+		},
+		{
+			name:    "unauthorized",
+			script:  func(gw *qristest.Server) { gw.FailNext(1, http.StatusUnauthorized) },
+			amount:  10001,
+			wantErr: qris.ErrGatewayUnauthorized,
+		},
+		{
+			name:    "maintenance",
+			script:  func(gw *qristest.Server) { gw.MaintenanceNext(1) },
+			amount:  10001,
+			wantErr: qris.ErrGatewayMaintenance,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newGateway(t)
+			tt.script(gw)
+			q := newTestQRIS(t, gw)
+
+			status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "INV-1", tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if status.Status != tt.status {
+				t.Fatalf("Status = %s, want %s", status.Status, tt.status)
+			}
+			if status.Warning != qris.WarningAmountOnly {
+				t.Fatalf("Warning = %q, want %q", status.Warning, qris.WarningAmountOnly)
+			}
+			if tt.status == qris.StatusPaid && (status.Amount != tt.amount || status.BrandName != "OVO" || status.PaidAt.IsZero()) {
+				t.Fatalf("status = %+v", status)
+			}
+		})
+	}
+}
+
+func TestCheckPaymentStatusAmountFormats(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		amount    int64
+		truncated bool
+	}{
+		{"thousands_dot.json", 1234567, false},
+		{"thousands_comma.json", 1234567, false},
+		{"decimal_comma.json", 1234, true},
+		{"fraction.json", 25000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			srv := staticGateway(t, mutationFixture(t, "amounts/"+tt.fixture))
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			ctx := qris.WithTenant(testContext(t, 5*time.Second), "toko-a")
+			status, err := q.CheckPaymentStatusContext(ctx, "INV-1", tt.amount)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if status.Status != qris.StatusPaid || status.Amount != tt.amount {
+				t.Fatalf("status = %+v, want PAID %d", status, tt.amount)
+			}
+
+			// The truncation is logged with the tenant of the call
+			var truncation string
+			for _, line := range strings.Split(logs.String(), "\n") {
+				if strings.Contains(line, "fraction of a rupiah") {
+					truncation = line
+				}
+			}
+			if (truncation != "") != tt.truncated || (tt.truncated && !strings.Contains(truncation, "tenant=toko-a")) {
+				t.Fatalf("truncation log = %q, want logged %v with the tenant", truncation, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestCheckPaymentStatusUniqueSuffix(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) {
+		c.DeterministicAmounts = true
+		c.UniqueSuffix = 50
+	})
+	ctx := testContext(t, 5*time.Second)
+
+	// Sessions and amount checks derive the same amount from the configured range
+	s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := qris.DeterministicAmount(25000, "INV-1", 50)
+	if s.Amount() != want || want == qris.DeterministicAmount(25000, "INV-1", int(qris.DefaultUniqueSuffix)) {
+		t.Fatalf("session amount = %d, want %d", s.Amount(), want)
+	}
+	gw.AddMutation(want, time.Now())
+	status, err := q.CheckPaymentStatusContext(ctx, "INV-1", 25000)
+	if err != nil || status.Status != qris.StatusPaid || status.Amount != want {
+		t.Fatalf("CheckPaymentStatus = %+v, %v, want %d paid", status, err, want)
+	}
+
+	if _, err := qris.NewQRIS(qris.QRISConfig{BaseQrString: testBaseQR, AuthToken: "token", AuthUsername: "user", UniqueSuffix: -1}); err == nil {
+		t.Fatal("NewQRIS with a negative UniqueSuffix succeeded")
+	}
+}
+
+func TestCheckPaymentStatusRowOrder(t *testing.T) {
+	for _, fixture := range []string{"newest_first.json", "oldest_first.json"} {
+		t.Run(fixture, func(t *testing.T) {
+			srv := staticGateway(t, mutationFixture(t, "order/"+fixture))
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+
+			// REF1 and REF3 pay the same amount; the latest wins whatever order the gateway sends
+			status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "INV-1", 25000)
+			if err != nil || status.Status != qris.StatusPaid || status.Reference != "REF3" {
+				t.Fatalf("CheckPaymentStatus = %+v, %v, want REF3 paid", status, err)
+			}
+		})
+	}
+}
+
+func TestCheckPaymentStatusInvalid(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+
+	_, err := q.CheckPaymentStatus("", 10001)
+	if code := qris.ErrorCode(err); code != "INVALID_REFERENCE" {
+		t.Fatalf("ErrorCode = %q, want INVALID_REFERENCE (err %v)", code, err)
+	}
+}
+
+func TestWaitForPayment(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+
+	go func() {
+		time.Sleep(5 * testPoll)
+		gw.AddMutation(10001, time.Now())
+	}()
+	status, err := q.WaitForPayment(testContext(t, 5*time.Second), "INV-1", 10001, qris.WithPollInterval(testPoll))
+	if err != nil {
+		t.Fatalf("WaitForPayment: %v", err)
+	}
+	if status.Status != qris.StatusPaid || status.Amount != 10001 {
+		t.Fatalf("status = %+v", status)
+	}
+	if n := gw.RequestCount(); n < 2 {
+		t.Fatalf("RequestCount = %d, want polling before the payment", n)
+	}
+}
+
+func TestWaitForPaymentRetriesFailures(t *testing.T) {
+	gw := newGateway(t)
+	gw.FailNext(3, http.StatusBadGateway)
+	gw.AddMutation(10001, time.Now())
+	q := newTestQRIS(t, gw)
+
+	status, err := q.WaitForPayment(testContext(t, 5*time.Second), "INV-1", 10001, qris.WithPollInterval(testPoll))
+	if err != nil {
+		t.Fatalf("WaitForPayment: %v", err)
+	}
+	if status.Status != qris.StatusPaid {
+		t.Fatalf("Status = %s, want PAID", status.Status)
+	}
+}
+
+func TestWaitForPaymentTimeout(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*testPoll)
+	defer cancel()
+	_, err := q.WaitForPayment(ctx, "INV-1", 10001, qris.WithPollInterval(testPoll))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,289 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSessionWatched is returned by Poller.Register for a session that already has a watcher,
+// started by Wait, Events, ResumePending or another Poller.
+// ErrSessionWatched dikembalikan oleh Poller.Register untuk sesi yang sudah memiliki watcher,
+// yang dijalankan oleh Wait, Events, ResumePending atau Poller lain.
+var ErrSessionWatched = errors.New("session already has a watcher / sesi sudah memiliki watcher")
+
+// PollerConfig configures NewPoller.
+// PollerConfig mengatur NewPoller.
+type PollerConfig struct {
+	Interval time.Duration      // Time between gateway requests, default DefaultPollInterval / Jeda antar request gateway, default DefaultPollInterval
+	OnTick   func(sessions int) // Called after every tick with the registered sessions, e.g. to set a gauge / Dipanggil setelah setiap tick dengan jumlah sesi terdaftar, misalnya untuk mengatur gauge
+	Logger   *log.Logger        // Receives fetch failures, default log.Default() / Menerima kegagalan pengambilan, default log.Default()
+}
+
+// Poller watches many sessions with one polling loop: every Interval it fetches the credits once,
+// whatever the number of sessions, and checks each registered session against them, oldest session
+// first, so a mutation that could pay two sessions pays the older one. Results reach the sessions
+// as usual, through Wait, Events and the EventSink.
+// Poller memantau banyak sesi dengan satu loop polling: setiap Interval poller mengambil kredit satu
+// kali, berapa pun jumlah sesinya, dan memeriksa setiap sesi terdaftar terhadap kredit tersebut, sesi
+// terlama lebih dulu, sehingga mutasi yang dapat membayar dua sesi membayar sesi yang lebih lama. Hasilnya
+// sampai ke sesi seperti biasa, melalui Wait, Events dan EventSink.
+//
+// Registered sessions expire at the first tick after ExpiresAt. Status and the duplicate detection of
+// WithDetectDuplicates still query the gateway on their own.
+// Sesi terdaftar kedaluwarsa pada tick pertama setelah ExpiresAt. Status dan deteksi duplikat dari
+// WithDetectDuplicates tetap melakukan query ke gateway sendiri.
+type Poller struct {
+	q      *QRIS
+	config PollerConfig
+	logger *log.Logger
+
+	// ctx runs the fetches and checks; it is not cancelled by QRIS.Close so a check in flight completes
+	// ctx menjalankan pengambilan dan pengecekan; tidak dibatalkan oleh QRIS.Close sehingga pengecekan yang berjalan selesai
+	ctx context.Context
+
+	mu       sync.Mutex
+	sessions map[*PaymentSession]*pollerEntry
+	seq      uint64
+
+	closeOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+type pollerEntry struct {
+	seq    uint64 // Registration order, to order sessions created at the same time / Urutan pendaftaran, untuk mengurutkan sesi yang dibuat bersamaan
+	checks int
+}
+
+// NewPoller starts a Poller that runs until ctx is done, Close is called or q is closed. The
+// fetches keep the tenant and actor of ctx.
+// NewPoller menjalankan Poller sampai ctx selesai, Close dipanggil atau q ditutup. Pengambilan
+// mutasi menyimpan tenant dan actor dari ctx.
+func (q *QRIS) NewPoller(ctx context.Context, config PollerConfig) (*Poller, error) {
+	if config.Interval <= 0 {
+		config.Interval = DefaultPollInterval
+	}
+	p := &Poller{
+		q:        q,
+		config:   config,
+		logger:   config.Logger,
+		ctx:      withAudit(context.Background(), ctx),
+		sessions: make(map[*PaymentSession]*pollerEntry),
+		done:     make(chan struct{}),
+	}
+	if p.logger == nil {
+		p.logger = log.Default()
+	}
+	if !q.life.track() {
+		return nil, ErrClosed
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	go func() {
+		defer q.life.wg.Done()
+		p.run(ctx)
+	}()
+	return p, nil
+}
+
+// Register hands the watching of s to the poller, instead of the watcher Wait and Events would
+// start. It fails with ErrSessionWatched if s is already watched.
+// Register menyerahkan pemantauan s ke poller, menggantikan watcher yang akan dijalankan Wait dan
+// Events. Gagal dengan ErrSessionWatched jika s sudah dipantau.
+func (p *Poller) Register(s *PaymentSession) error {
+	if s.q != p.q {
+		return errors.New("session belongs to another QRIS instance / sesi milik instance QRIS lain")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return errors.New("poller is closed / poller sudah ditutup")
+	default:
+	}
+	owned := false
+	s.start.Do(func() { owned = true })
+	if !owned {
+		return fmt.Errorf("%w: %s", ErrSessionWatched, s.TransactionID)
+	}
+	p.seq++
+	p.sessions[s] = &pollerEntry{seq: p.seq}
+	return nil
+}
+
+// Deregister stops watching s and reports whether it was registered. A check already in progress
+// may still finish s; afterwards only Status checks it.
+// Deregister berhenti memantau s dan melaporkan apakah s terdaftar. Pengecekan yang sedang berjalan
+// masih dapat menyelesaikan s; setelahnya s hanya diperiksa oleh Status.
+func (p *Poller) Deregister(s *PaymentSession) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.sessions[s]
+	delete(p.sessions, s)
+	return ok
+}
+
+// Len returns the number of registered sessions that are not finished yet.
+// Len mengembalikan jumlah sesi terdaftar yang belum selesai.
+func (p *Poller) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pruneLocked()
+	return len(p.sessions)
+}
+
+// Close stops the poller and waits for a tick in progress to finish. Registered sessions are not
+// watched anymore.
+// Close menghentikan poller dan menunggu tick yang sedang berjalan selesai. Sesi terdaftar tidak
+// dipantau lagi.
+func (p *Poller) Close() error {
+	p.closeOnce.Do(p.cancel)
+	<-p.done
+	return nil
+}
+
+// run ticks every interval until ctx is done or the instance is closed.
+// run menjalankan tick setiap interval sampai ctx selesai atau instance ditutup.
+func (p *Poller) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	var resume time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.q.life.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if now.Before(resume) {
+				continue
+			}
+			if err := p.tick(); errors.Is(err, ErrGatewayMaintenance) {
+				resume = now.Add(MaintenanceBackoff)
+			}
+		}
+	}
+}
+
+// tick fetches the credits once and checks every registered session against them, oldest first.
+// tick mengambil kredit satu kali dan memeriksa setiap sesi terdaftar terhadapnya, yang terlama lebih dulu.
+func (p *Poller) tick() error {
+	sessions := p.due()
+	if p.config.OnTick != nil {
+		defer func() { p.config.OnTick(p.Len()) }()
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	from := sessions[0].invoice().CreatedAt
+	var credits []Mutation
+	page, err := p.q.eachMutation(p.ctx, MutationQuery{From: from, Type: "CR"}, func(m Mutation) error {
+		credits = append(credits, m)
+		return nil
+	})
+	if err != nil && p.ctx.Err() == nil {
+		p.logger.Printf("%sError fetching mutations for %d sessions: %v", auditPrefix(p.ctx), len(sessions), err)
+	}
+	source := func(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+		if err != nil {
+			return page, err
+		}
+		for _, m := range credits {
+			if !query.matches(m) {
+				continue
+			}
+			if err := fn(m); err != nil {
+				return page, err
+			}
+		}
+		return page, nil
+	}
+
+	for _, s := range sessions {
+		checks, ok := p.checked(s)
+		if !ok {
+			continue
+		}
+		// Checking in order lets an older session claim a mutation before a newer one sees it
+		status, cerr := s.checkClaimed(p.ctx, source)
+		s.emit(PaymentEvent{Type: EventChecked, Status: status, Err: cerr, Detail: fmt.Sprintf("check %d", checks)})
+		if cerr == nil && status.Status == StatusPaid {
+			s.finish(EventPaid, status, nil)
+		}
+	}
+	return err
+}
+
+// due returns the registered sessions still waiting for a payment, oldest first, expiring and
+// dropping the others.
+// due mengembalikan sesi terdaftar yang masih menunggu pembayaran, yang terlama lebih dulu, dan
+// mengedaluwarsakan serta membuang sesi lainnya.
+func (p *Poller) due() []*PaymentSession {
+	now := time.Now()
+	p.mu.Lock()
+	p.pruneLocked()
+	var sessions, expired []*PaymentSession
+	for s := range p.sessions {
+		if now.Before(s.ExpiresAt) {
+			sessions = append(sessions, s)
+		} else {
+			expired = append(expired, s)
+			delete(p.sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return p.sessions[a].seq < p.sessions[b].seq
+	})
+	p.mu.Unlock()
+
+	for _, s := range expired {
+		s.finish(EventExpired, s.unpaidStatus(StatusExpired), ErrPaymentExpired)
+	}
+	return sessions
+}
+
+// checked counts a check of s and returns the count, or false if s was deregistered or finished meanwhile.
+// checked menghitung satu pengecekan s dan mengembalikan jumlahnya, atau false jika s sudah dilepas atau selesai.
+func (p *Poller) checked(s *PaymentSession) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.sessions[s]
+	if !ok {
+		return 0, false
+	}
+	select {
+	case <-s.done:
+		return 0, false
+	default:
+	}
+	e.checks++
+	return e.checks, true
+}
+
+// pruneLocked drops the sessions that finished, e.g. paid through Status or cancelled.
+// pruneLocked membuang sesi yang sudah selesai, misalnya dibayar melalui Status atau dibatalkan.
+func (p *Poller) pruneLocked() {
+	for s := range p.sessions {
+		select {
+		case <-s.done:
+			delete(p.sessions, s)
+		default:
+		}
+	}
+}
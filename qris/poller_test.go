@@ -0,0 +1,210 @@
+package qris_test
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// createSessions creates n sessions of q, oldest first.
+func createSessions(t *testing.T, q *qris.QRIS, n int, opts ...qris.PaymentOption) []*qris.PaymentSession {
+	t.Helper()
+	ctx := testContext(t, 5*time.Second)
+	sessions := make([]*qris.PaymentSession, n)
+	for i := range sessions {
+		s, err := q.CreatePayment(ctx, 10000, opts...)
+		if err != nil {
+			t.Fatalf("CreatePayment %d: %v", i, err)
+		}
+		sessions[i] = s
+	}
+	return sessions
+}
+
+// registerAll registers sessions with p from concurrent goroutines, in random order.
+func registerAll(t *testing.T, p *qris.Poller, sessions []*qris.PaymentSession) {
+	t.Helper()
+	shuffled := append([]*qris.PaymentSession(nil), sessions...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	errs := make(chan error, len(shuffled))
+	var wg sync.WaitGroup
+	for _, s := range shuffled {
+		wg.Add(1)
+		go func(s *qris.PaymentSession) {
+			defer wg.Done()
+			errs <- p.Register(s)
+		}(s)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+}
+
+func TestPollerOneRequestPerTick(t *testing.T) {
+	const n = 300
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	sessions := createSessions(t, q, n, qris.WithUniqueSuffix(999))
+
+	var ticks, gauge atomic.Int64
+	poller, err := q.NewPoller(testContext(t, 10*time.Second), qris.PollerConfig{
+		Interval: testPoll,
+		OnTick: func(sessions int) {
+			ticks.Add(1)
+			gauge.Store(int64(sessions))
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	baseline := gw.RequestCount()
+	registerAll(t, poller, sessions)
+	if got := poller.Len(); got != n {
+		t.Fatalf("Len = %d, want %d", got, n)
+	}
+
+	// Pay every other session, then let the poller run a few more ticks
+	for i := 0; i < n; i += 2 {
+		gw.AddMutation(sessions[i].Amount(), time.Now())
+	}
+	ctx := testContext(t, 10*time.Second)
+	for i := 0; i < n; i += 2 {
+		status, err := sessions[i].Wait(ctx)
+		if err != nil || status.Status != qris.StatusPaid {
+			t.Fatalf("session %d: %v, %v", i, status, err)
+		}
+	}
+	for start := ticks.Load(); ticks.Load() < start+3; {
+		time.Sleep(testPoll)
+	}
+	if err := poller.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got := poller.Len(); got != n/2 {
+		t.Errorf("Len = %d, want %d unpaid", got, n/2)
+	}
+	if got := gauge.Load(); got != n/2 {
+		t.Errorf("OnTick gauge = %d, want %d", got, n/2)
+	}
+	// Ticks before the first registration have nothing to fetch
+	if requests := int64(gw.RequestCount() - baseline); requests > ticks.Load() || requests == 0 {
+		t.Errorf("%d gateway requests in %d ticks, want at most one per tick", requests, ticks.Load())
+	}
+	for i := 1; i < n; i += 2 {
+		select {
+		case <-sessions[i].Done():
+			t.Fatalf("unpaid session %d finished", i)
+		default:
+		}
+	}
+}
+
+func TestPollerOldestSessionWins(t *testing.T) {
+	const n = 200
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.MatchPolicy = anyCredit })
+	sessions := createSessions(t, q, n, qris.WithUniqueSuffix(999))
+
+	poller, err := q.NewPoller(testContext(t, 10*time.Second), qris.PollerConfig{Interval: testPoll})
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	t.Cleanup(func() { poller.Close() })
+	registerAll(t, poller, sessions)
+
+	// Every credit could pay every session; each one must go to the oldest still waiting
+	ctx := testContext(t, 10*time.Second)
+	for i := 0; i < 5; i++ {
+		gw.AddMutation(int64(50000+i), time.Now())
+		status, err := sessions[i].Wait(ctx)
+		if err != nil || status.Status != qris.StatusPaid {
+			t.Fatalf("session %d: %v, %v", i, status, err)
+		}
+		if status.Amount != int64(50000+i) {
+			t.Fatalf("session %d paid by %d, want %d", i, status.Amount, 50000+i)
+		}
+		for _, s := range sessions[i+1:] {
+			select {
+			case <-s.Done():
+				t.Fatalf("credit %d also finished the newer session %s", i, s.TransactionID)
+			default:
+			}
+		}
+	}
+	if got := poller.Len(); got != n-5 {
+		t.Fatalf("Len = %d, want %d", got, n-5)
+	}
+}
+
+func TestPollerRegisterDeregister(t *testing.T) {
+	const n = 500
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	sessions := createSessions(t, q, n, qris.WithUniqueSuffix(999))
+
+	poller, err := q.NewPoller(testContext(t, 10*time.Second), qris.PollerConfig{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	t.Cleanup(func() { poller.Close() })
+
+	// Register and deregister while the poller ticks, checking Len concurrently
+	var wg sync.WaitGroup
+	var deregistered atomic.Int64
+	for i, s := range sessions {
+		wg.Add(1)
+		go func(i int, s *qris.PaymentSession) {
+			defer wg.Done()
+			if err := poller.Register(s); err != nil {
+				t.Errorf("Register %d: %v", i, err)
+				return
+			}
+			poller.Len()
+			if i%2 == 0 {
+				if !poller.Deregister(s) {
+					t.Errorf("Deregister %d = false, want true", i)
+				}
+				deregistered.Add(1)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	if got := poller.Len(); got != n-int(deregistered.Load()) {
+		t.Fatalf("Len = %d, want %d", got, n-int(deregistered.Load()))
+	}
+	if poller.Deregister(sessions[0]) {
+		t.Error("second Deregister = true, want false")
+	}
+	if err := poller.Register(sessions[1]); !errors.Is(err, qris.ErrSessionWatched) {
+		t.Errorf("second Register = %v, want ErrSessionWatched", err)
+	}
+
+	// A deregistered session keeps its watch slot, so Wait does not start a second watcher
+	if err := poller.Register(sessions[0]); !errors.Is(err, qris.ErrSessionWatched) {
+		t.Errorf("Register after Deregister = %v, want ErrSessionWatched", err)
+	}
+
+	other := newTestQRIS(t, newGateway(t))
+	foreign := createSessions(t, other, 1)[0]
+	if err := poller.Register(foreign); err == nil {
+		t.Error("Register of another instance's session succeeded")
+	}
+
+	poller.Close()
+	late := createSessions(t, q, 1)[0]
+	if err := poller.Register(late); err == nil {
+		t.Error("Register after Close succeeded")
+	}
+}
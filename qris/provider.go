@@ -0,0 +1,97 @@
+package qris
+
+import (
+	"context"
+	"fmt"
+)
+
+// Built-in mutation gateways, selected with QRISConfig.Gateway.
+// Gateway mutasi bawaan, dipilih dengan QRISConfig.Gateway.
+const (
+	GatewayFTVPN      = "ftvpn"      // POST JSON with auth_token and auth_username (default) / POST JSON dengan auth_token dan auth_username (default)
+	GatewayOkeconnect = "okeconnect" // GET {GatewayURL}/{AuthUsername}/{AuthToken} / GET {GatewayURL}/{AuthUsername}/{AuthToken}
+)
+
+// DefaultOkeconnectURL is the mutation endpoint of GatewayOkeconnect when QRISConfig.GatewayURL is empty.
+// DefaultOkeconnectURL adalah endpoint mutasi GatewayOkeconnect jika QRISConfig.GatewayURL kosong.
+const DefaultOkeconnectURL = "https://gateway.okeconnect.com/api/mutasi/qris"
+
+// MutationProvider fetches the mutation history from a QRIS mutation source other than the built-in
+// gateways, such as another PJSP aggregator. Set it as QRISConfig.Provider; payment checks, sessions,
+// and GetMutations then read mutations only through it.
+// MutationProvider mengambil riwayat mutasi dari sumber mutasi QRIS selain gateway bawaan, misalnya
+// agregator PJSP lain. Atur sebagai QRISConfig.Provider; pengecekan pembayaran, sesi, dan GetMutations
+// lalu hanya membaca mutasi melaluinya.
+//
+// Fetch returns the rows for query, preferably newest first, which lets payment checks stop reading early. Filters the provider cannot apply may be ignored,
+// since they are applied again locally. A Mutation needs at least Amount, Type ("CR" or "DB"), and
+// QRIS; Time is parsed from Date in QRISConfig.Location and Issuer from BrandName when left zero.
+// Fetch runs under QRISConfig.RequestTimeout and must be safe for concurrent use.
+// Fetch mengembalikan baris untuk query, sebaiknya dari yang terbaru agar pengecekan pembayaran dapat berhenti membaca lebih awal. Filter yang tidak dapat diterapkan provider
+// boleh diabaikan, karena diterapkan lagi secara lokal. Mutation minimal membutuhkan Amount, Type ("CR"
+// atau "DB"), dan QRIS; Time di-parse dari Date dalam QRISConfig.Location dan Issuer dari BrandName jika
+// dibiarkan nol. Fetch berjalan di bawah QRISConfig.RequestTimeout dan harus aman dipakai secara bersamaan.
+type MutationProvider interface {
+	Fetch(ctx context.Context, query MutationQuery) ([]Mutation, error)
+}
+
+// MutationProviderFunc adapts an ordinary function to a MutationProvider.
+// MutationProviderFunc mengubah fungsi biasa menjadi MutationProvider.
+type MutationProviderFunc func(ctx context.Context, query MutationQuery) ([]Mutation, error)
+
+// Fetch calls f(ctx, query).
+// Fetch memanggil f(ctx, query).
+func (f MutationProviderFunc) Fetch(ctx context.Context, query MutationQuery) ([]Mutation, error) {
+	return f(ctx, query)
+}
+
+// Provider returns the MutationProvider the instance reads from: QRISConfig.Provider, or the
+// built-in gateway (or the sandbox) behind the same interface.
+// Provider mengembalikan MutationProvider yang dibaca instance: QRISConfig.Provider, atau gateway
+// bawaan (atau sandbox) di balik interface yang sama.
+func (q *QRIS) Provider() MutationProvider {
+	if q.config.Provider != nil {
+		return q.config.Provider
+	}
+	return MutationProviderFunc(q.GetMutations)
+}
+
+// eachProviderMutation fetches one page from QRISConfig.Provider and hands every matching row to fn.
+// eachProviderMutation mengambil satu halaman dari QRISConfig.Provider dan meneruskan setiap baris yang cocok ke fn.
+func (q *QRIS) eachProviderMutation(ctx context.Context, query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+	var page mutationPage
+
+	reqCtx, cancel := q.requestContext(ctx)
+	defer cancel()
+
+	mutations, err := q.config.Provider.Fetch(reqCtx, query)
+	if err != nil {
+		return page, gatewayError(ctx, reqCtx, err)
+	}
+
+	page.rows = len(mutations)
+	loc := q.location()
+	for i, m := range mutations {
+		if i == 0 {
+			page.first = m.IssuerRef + "|" + m.Date
+		}
+		if m.Issuer == "" {
+			m.Issuer = NormalizeIssuer(m.BrandName)
+		}
+		if m.Time.IsZero() && m.Date != "" {
+			m.Time = parseMutationDate(m.Date, loc)
+		}
+		if reason := skipReason(m); reason != "" {
+			row := SkippedRow{Reason: reason, Date: m.Date, Amount: m.Amount, IssuerRef: m.IssuerRef, Raw: m.Raw}
+			q.skipped.record(ctx, fmt.Sprintf("%s|%s|%d|%s", m.IssuerRef, m.Date, m.Amount, m.BuyerRef), row)
+			continue
+		}
+		if !query.matches(m) {
+			continue
+		}
+		if err := fn(m); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,171 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// fakeProvider serves fixed mutations and records the queries it gets.
+type fakeProvider struct {
+	mu        sync.Mutex
+	mutations []qris.Mutation
+	err       error
+	queries   []qris.MutationQuery
+}
+
+func (p *fakeProvider) Fetch(ctx context.Context, query qris.MutationQuery) ([]qris.Mutation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queries = append(p.queries, query)
+	return append([]qris.Mutation(nil), p.mutations...), p.err
+}
+
+func (p *fakeProvider) add(m qris.Mutation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mutations = append([]qris.Mutation{m}, p.mutations...)
+}
+
+func (p *fakeProvider) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queries)
+}
+
+// credit returns a static QRIS credit of amount dated now, in the gateway date format.
+func credit(amount int64, ref string) qris.Mutation {
+	return qris.Mutation{
+		Amount:    amount,
+		Date:      time.Now().In(qris.WIB).Format("2006-01-02 15:04:05"),
+		QRIS:      qris.QRISTypeStatic,
+		Type:      "CR",
+		IssuerRef: ref,
+		BrandName: "ShopeePay",
+	}
+}
+
+func TestProviderDispatch(t *testing.T) {
+	gw := newGateway(t)
+	provider := &fakeProvider{}
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.Provider = provider })
+	ctx := testContext(t, 5*time.Second)
+
+	if q.Provider() != qris.MutationProvider(provider) {
+		t.Fatal("Provider() does not return QRISConfig.Provider")
+	}
+
+	debit := credit(15000, "DEBIT")
+	debit.Type = "DB"
+	provider.add(debit)
+	provider.add(credit(15000, "REF1"))
+
+	status, err := q.CheckPaymentStatusContext(ctx, "ORDER1", 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != qris.StatusPaid || status.Reference != "REF1" || status.Issuer != qris.NormalizeIssuer("ShopeePay") || status.PaidAt.IsZero() {
+		t.Fatalf("status = %+v, want PAID by REF1 with issuer and time filled in", status)
+	}
+
+	// Filters the provider ignores are applied locally
+	credits, err := q.GetMutations(ctx, qris.MutationQuery{Type: "CR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(credits) != 1 || credits[0].IssuerRef != "REF1" {
+		t.Errorf("GetMutations(CR) = %+v, want only REF1", credits)
+	}
+
+	// Sessions read through the provider too
+	session, err := q.CreatePayment(ctx, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.add(credit(session.Amount(), "REF2"))
+	paid, err := session.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Reference != "REF2" {
+		t.Errorf("session paid by %s, want REF2", paid.Reference)
+	}
+
+	if n := gw.RequestCount(); n != 0 {
+		t.Errorf("built-in gateway got %d requests, want none", n)
+	}
+	if provider.calls() < 3 {
+		t.Errorf("provider got %d calls, want at least 3", provider.calls())
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	provider := &fakeProvider{err: errors.New("aggregator down")}
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.Provider = provider })
+
+	_, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "ORDER1", 15000)
+	if err == nil || err.Error() != "aggregator down" {
+		t.Fatalf("CheckPaymentStatus = %v, want the provider error", err)
+	}
+}
+
+func TestProviderFunc(t *testing.T) {
+	var got qris.MutationQuery
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.Provider = qris.MutationProviderFunc(func(ctx context.Context, query qris.MutationQuery) ([]qris.Mutation, error) {
+			got = query
+			return []qris.Mutation{credit(15000, "REF1")}, nil
+		})
+	})
+	from := time.Now().Add(-time.Hour)
+	mutations, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{From: from, Type: "CR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutations) != 1 || !got.From.Equal(from) || got.Type != "CR" {
+		t.Errorf("got %d mutations for query %+v, want 1 for the query passed in", len(mutations), got)
+	}
+}
+
+func TestBuiltinGateways(t *testing.T) {
+	tests := []struct {
+		gateway    string
+		wantMethod string
+		wantPath   string
+	}{
+		{qris.GatewayFTVPN, http.MethodPost, "/"},
+		{qris.GatewayOkeconnect, http.MethodGet, "/user/token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gateway, func(t *testing.T) {
+			var method, path string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(largeResponse(t, 1, 15000))
+			}))
+			t.Cleanup(srv.Close)
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.Gateway = tt.gateway
+				c.GatewayURL = srv.URL + "/"
+			})
+
+			status, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "ORDER1", 15000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Status != qris.StatusPaid {
+				t.Errorf("status %s, want PAID", status.Status)
+			}
+			if method != tt.wantMethod || path != tt.wantPath {
+				t.Errorf("request %s %s, want %s %s", method, path, tt.wantMethod, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package qris_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// relayProxy is a forward proxy recording the URLs it relays.
+type relayProxy struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	relayed []string
+}
+
+func newRelayProxy(t *testing.T) *relayProxy {
+	t.Helper()
+	p := &relayProxy{}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.relayed = append(p.relayed, r.URL.String())
+		p.mu.Unlock()
+
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *relayProxy) urls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.relayed...)
+}
+
+func TestProxyURL(t *testing.T) {
+	proxy := newRelayProxy(t)
+	gw := newGateway(t)
+	gw.AddMutation(10001, time.Now())
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.ProxyURL = proxy.URL })
+
+	status, err := q.CheckPaymentStatus("INV-1", 10001)
+	if err != nil || status.Status != qris.StatusPaid {
+		t.Fatalf("CheckPaymentStatus = %v, %v", status, err)
+	}
+	if relayed := proxy.urls(); len(relayed) != 1 || relayed[0] != gw.URL+"/" {
+		t.Fatalf("proxy relayed %q, want %s/", relayed, gw.URL)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{"default", "", qris.DefaultUserAgent},
+		{"custom", "toko-bot/2.0", "toko-bot/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			record := func(next qris.RoundTripFunc) qris.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					got = req.Header.Get("User-Agent")
+					return next(req)
+				}
+			}
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.UserAgent = tt.userAgent
+				c.Interceptors = []qris.Interceptor{record}
+			})
+			if _, err := q.CheckPaymentStatus("INV-1", 10001); err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package qris
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// DefaultQRCacheEntries is the capacity of a QRCache created with maxEntries <= 0.
+// DefaultQRCacheEntries adalah kapasitas QRCache yang dibuat dengan maxEntries <= 0.
+const DefaultQRCacheEntries = 256
+
+// QRCache keeps rendered QR code PNGs so identical renders, such as reloads of an invoice page,
+// skip encoding. Entries are keyed by the SHA-256 of the payload, size, and every render option,
+// so changing an option never serves a stale image. Concurrent renders of the same key run once.
+// QRCache menyimpan PNG QR code yang sudah di-render sehingga render yang sama, seperti muat ulang
+// halaman invoice, tidak perlu encode ulang. Entri dikunci dengan SHA-256 dari payload, ukuran, dan
+// setiap opsi render, sehingga mengubah opsi tidak pernah menyajikan gambar lama. Render bersamaan
+// untuk kunci yang sama hanya dijalankan sekali.
+//
+// A QRCache is safe for concurrent use and may be shared by several QRIS instances.
+// QRCache aman dipakai bersamaan dan boleh dipakai bersama oleh beberapa instance QRIS.
+type QRCache struct {
+	dir string
+	max int
+
+	mu       sync.Mutex
+	order    *list.List // most recently used first / yang terakhir dipakai lebih dulu
+	entries  map[string]*list.Element
+	inflight map[string]*renderCall
+}
+
+// qrCacheEntry is one cached image; data is nil for entries kept on disk.
+// qrCacheEntry adalah satu gambar dalam cache; data bernilai nil untuk entri yang disimpan di disk.
+type qrCacheEntry struct {
+	key  string
+	data []byte
+}
+
+// renderCall is a render in flight that later callers of the same key wait for.
+// renderCall adalah render yang sedang berjalan yang ditunggu pemanggil berikutnya dengan kunci yang sama.
+type renderCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewQRCache creates a cache of at most maxEntries images (DefaultQRCacheEntries if <= 0),
+// least recently used first out. With an empty dir images are kept in memory; otherwise they
+// are stored as files in dir, which is created if needed and reused across restarts.
+// NewQRCache membuat cache berisi paling banyak maxEntries gambar (DefaultQRCacheEntries jika <= 0),
+// yang paling lama tidak dipakai dikeluarkan lebih dulu. Dengan dir kosong gambar disimpan di memori;
+// jika tidak, gambar disimpan sebagai file di dir, yang dibuat bila perlu dan dipakai ulang setelah restart.
+func NewQRCache(dir string, maxEntries int) (*QRCache, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultQRCacheEntries
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create QR cache directory / gagal membuat direktori cache QR: %v", err)
+		}
+	}
+	return &QRCache{
+		dir:      dir,
+		max:      maxEntries,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*renderCall),
+	}, nil
+}
+
+// qrCacheKey hashes everything that affects the rendered image.
+// qrCacheKey meng-hash semua hal yang memengaruhi gambar hasil render.
+func qrCacheKey(qr *qrcode.QRCode, size int) string {
+	fr, fg, fb, fa := qr.ForegroundColor.RGBA()
+	br, bg, bb, ba := qr.BackgroundColor.RGBA()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d\x00%d\x00%t\x00%d,%d,%d,%d\x00%d,%d,%d,%d",
+		qr.Content, size, qr.Level, qr.VersionNumber, qr.DisableBorder, fr, fg, fb, fa, br, bg, bb, ba)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PNG returns qr rendered at size pixels, from the cache when possible. The returned slice
+// may be shared with other callers and must not be modified.
+// PNG mengembalikan qr yang di-render dengan ukuran size piksel, dari cache bila memungkinkan.
+// Slice yang dikembalikan dapat dipakai bersama pemanggil lain dan tidak boleh diubah.
+func (c *QRCache) PNG(qr *qrcode.QRCode, size int) ([]byte, error) {
+	return c.get(qrCacheKey(qr, size), func() ([]byte, error) {
+		return qr.PNG(size)
+	})
+}
+
+// get returns the image of key, calling render once on a miss.
+// get mengembalikan gambar untuk key, memanggil render satu kali jika tidak ada di cache.
+func (c *QRCache) get(key string, render func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if data, ok := c.lookupLocked(key); ok {
+		c.mu.Unlock()
+		return data, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.data, call.err
+	}
+	call := &renderCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	call.data, call.err = c.load(key)
+	if call.data == nil && call.err == nil {
+		call.data, call.err = render()
+		if call.err == nil {
+			call.err = c.store(key, call.data)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil {
+		c.addLocked(key, call.data)
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.data, call.err
+}
+
+// lookupLocked returns a cached image, marking it as recently used.
+// lookupLocked mengembalikan gambar dari cache dan menandainya baru dipakai.
+func (c *QRCache) lookupLocked(key string) ([]byte, bool) {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*qrCacheEntry)
+	if entry.data == nil {
+		// Disk entries are read outside the lock by load
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.data, true
+}
+
+// load reads a disk entry, returning nil data if there is none.
+// load membaca entri dari disk, mengembalikan data nil jika tidak ada.
+func (c *QRCache) load(key string) ([]byte, error) {
+	if c.dir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read QR cache / gagal membaca cache QR: %v", err)
+	}
+	return data, nil
+}
+
+// store writes a disk entry atomically.
+// store menulis entri ke disk secara atomik.
+func (c *QRCache) store(key string, data []byte) error {
+	if c.dir == "" {
+		return nil
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write QR cache / gagal menulis cache QR: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write QR cache / gagal menulis cache QR: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write QR cache / gagal menulis cache QR: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("failed to write QR cache / gagal menulis cache QR: %v", err)
+	}
+	return nil
+}
+
+// addLocked records key as most recently used and evicts the oldest entries beyond the capacity.
+// addLocked mencatat key sebagai yang terakhir dipakai dan mengeluarkan entri terlama di atas kapasitas.
+func (c *QRCache) addLocked(key string, data []byte) {
+	entry := &qrCacheEntry{key: key}
+	if c.dir == "" {
+		entry.data = data
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[key] = c.order.PushFront(entry)
+	}
+
+	for c.order.Len() > c.max {
+		el := c.order.Back()
+		old := c.order.Remove(el).(*qrCacheEntry)
+		delete(c.entries, old.key)
+		if c.dir != "" {
+			os.Remove(c.path(old.key))
+		}
+	}
+}
+
+func (c *QRCache) path(key string) string {
+	return filepath.Join(c.dir, key+".png")
+}
+
+// Len returns the number of cached images.
+// Len mengembalikan jumlah gambar dalam cache.
+func (c *QRCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// RenderPNG encodes qrString and renders it at size pixels. With QRISConfig.QRCache set,
+// cache hits skip the encoding too.
+// RenderPNG meng-encode qrString dan me-render-nya dengan ukuran size piksel. Dengan QRISConfig.QRCache
+// diatur, cache hit juga melewati proses encode.
+func (q *QRIS) RenderPNG(qrString string, size int) ([]byte, error) {
+	render := func() ([]byte, error) {
+		qrCode, err := q.newQRCode(qrString)
+		if err != nil {
+			return nil, err
+		}
+		return qrCode.PNG(size)
+	}
+	if q.config.QRCache == nil {
+		return render()
+	}
+
+	// newQRCode fixes every option except the minimum recovery level
+	h := sha256.New()
+	fmt.Fprintf(h, "render\x00%s\x00%d\x00%d", qrString, size, q.config.MinRecoveryLevel)
+	return q.config.QRCache.get(hex.EncodeToString(h.Sum(nil)), render)
+}
+
+// PNG renders the session's QR code at size pixels, through QRISConfig.QRCache if set. After
+// Regenerate it renders the new QR code; the cache keys on the payload, so old renders are not reused.
+// PNG me-render QR code sesi dengan ukuran size piksel, melalui QRISConfig.QRCache jika diatur. Setelah
+// Regenerate yang di-render adalah QR code baru; cache memakai payload sebagai key, jadi render lama tidak dipakai ulang.
+func (s *PaymentSession) PNG(size int) ([]byte, error) {
+	_, png, err := s.SnapshotPNG(size)
+	return png, err
+}
+
+// SnapshotPNG is like PNG but also returns the Snapshot the image belongs to, for pages showing the
+// amount next to the QR code.
+// SnapshotPNG sama seperti PNG tetapi juga mengembalikan Snapshot milik gambar tersebut, untuk halaman
+// yang menampilkan nominal di samping QR code.
+func (s *PaymentSession) SnapshotPNG(size int) (PaymentSnapshot, []byte, error) {
+	snap := s.Snapshot()
+	if s.q.config.QRCache == nil {
+		png, err := snap.QRCode.PNG(size)
+		return snap, png, err
+	}
+	png, err := s.q.config.QRCache.PNG(snap.QRCode, size)
+	return snap, png, err
+}
@@ -0,0 +1,244 @@
+package qris_test
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/skip2/go-qrcode"
+)
+
+func newTestQRCode(t testing.TB, content string) *qrcode.QRCode {
+	t.Helper()
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qr
+}
+
+func TestQRCacheHit(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		cache, err := qris.NewQRCache(dir, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := newTestQRCode(t, testDynamicQR)
+		first, err := cache.PNG(qr, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := qr.PNG(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, want) {
+			t.Fatalf("dir %q: cached PNG differs from a direct render", dir)
+		}
+		again, err := cache.PNG(newTestQRCode(t, testDynamicQR), 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, first) || cache.Len() != 1 {
+			t.Errorf("dir %q: second render missed the cache, %d entries", dir, cache.Len())
+		}
+	}
+}
+
+func TestQRCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(qr *qrcode.QRCode) int // Returns the size to render at
+	}{
+		{"size", func(*qrcode.QRCode) int { return 512 }},
+		{"payload", func(qr *qrcode.QRCode) int { *qr = *newTestQRCode(t, testBaseQR); return 256 }},
+		{"foreground", func(qr *qrcode.QRCode) int { qr.ForegroundColor = color.RGBA{R: 0x20, A: 0xff}; return 256 }},
+		{"background", func(qr *qrcode.QRCode) int { qr.BackgroundColor = color.RGBA{G: 0xf0, A: 0xff}; return 256 }},
+		{"border", func(qr *qrcode.QRCode) int { qr.DisableBorder = true; return 256 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := qris.NewQRCache("", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qr := newTestQRCode(t, testDynamicQR)
+			if _, err := cache.PNG(qr, 256); err != nil {
+				t.Fatal(err)
+			}
+
+			size := tt.edit(qr)
+			after, err := cache.PNG(qr, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := qr.PNG(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(after, want) {
+				t.Errorf("changing the %s served a stale image", tt.name)
+			}
+			// Some options, like the border at a fixed size, do not change the image but still get their own key
+			if cache.Len() != 2 {
+				t.Errorf("changing the %s kept the old key, %d entries, want 2", tt.name, cache.Len())
+			}
+		})
+	}
+}
+
+func TestQRCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := qris.NewQRCache(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qr := newTestQRCode(t, testDynamicQR)
+	for _, size := range []int{128, 256, 128, 512} {
+		if _, err := cache.PNG(qr, size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("%d entries, want 2", cache.Len())
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("%d files on disk, want 2 after evicting the least recently used", len(files))
+	}
+}
+
+func TestQRCacheDiskSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	first, err := qris.NewQRCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qr := newTestQRCode(t, testDynamicQR)
+	want, err := first.PNG(qr, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.png"))
+	if len(files) != 1 {
+		t.Fatalf("%d files on disk, want 1", len(files))
+	}
+	// A hit from disk returns the stored file, even if it no longer matches a fresh render
+	if err := os.WriteFile(files[0], []byte("stored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := qris.NewQRCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := second.PNG(qr, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "stored" {
+		t.Errorf("restarted cache rendered %d bytes again instead of reading the file (%d bytes)", len(got), len(want))
+	}
+}
+
+func TestQRCacheSingleFlight(t *testing.T) {
+	cache, err := qris.NewQRCache("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qr := newTestQRCode(t, testDynamicQR)
+
+	const n = 20
+	results := make([][]byte, n)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			png, err := cache.PNG(qr, 1024)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = png
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// Every caller shares the image of the single render
+	for i, png := range results {
+		if len(png) == 0 || &png[0] != &results[0][0] {
+			t.Fatalf("caller %d got its own render", i)
+		}
+	}
+}
+
+func TestRenderPNGCache(t *testing.T) {
+	cache, err := qris.NewQRCache("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.QRCache = cache })
+	plain := newTestQRIS(t, newGateway(t))
+
+	got, err := cached.RenderPNG(testDynamicQR, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := plain.RenderPNG(testDynamicQR, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("cached RenderPNG differs from an uncached one")
+	}
+	if _, err := cached.RenderPNG(testDynamicQR, 256); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("%d entries, want 1", cache.Len())
+	}
+}
+
+func BenchmarkQRCache(b *testing.B) {
+	qr := newTestQRCode(b, testDynamicQR)
+	b.Run("render", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := qr.PNG(512); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, dir := range []string{"", b.TempDir()} {
+		name := "memory hit"
+		if dir != "" {
+			name = "disk hit"
+		}
+		b.Run(name, func(b *testing.B) {
+			cache, err := qris.NewQRCache(dir, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := cache.PNG(qr, 512); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := cache.PNG(qr, 512); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
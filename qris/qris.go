@@ -1,174 +1,304 @@
-// Package qris provides QRIS (Quick Response Code Indonesian Standard) payment integration for Go applications.
-// Package qris menyediakan integrasi pembayaran QRIS (Quick Response Code Indonesian Standard) untuk aplikasi Go.
-package qris
-
-import (
-	"errors"
-	"fmt"
-	"strings"
-	"github.com/skip2/go-qrcode"
-	"image/color"
-)
-
-// QRISConfig stores the configuration for QRIS operations.
-// QRISConfig menyimpan konfigurasi untuk operasi QRIS.
-type QRISConfig struct {
-	BaseQrString string // Base QRIS string from merchant / Base QRIS string dari merchant
-	AuthToken    string // Authentication token for API calls / Token autentikasi untuk panggilan API
-	AuthUsername string // Authentication username for API calls / Username autentikasi untuk panggilan API
-}
-
-// QRISData stores the data needed to generate a QR code.
-// QRISData menyimpan data yang diperlukan untuk generate QR code.
-type QRISData struct {
-	Amount        int64  // Payment amount / Nominal pembayaran
-	TransactionID string // Unique transaction ID / ID transaksi unik
-}
-
-// QRIS is the main struct for QRIS operations.
-// QRIS adalah struct utama untuk operasi QRIS.
-type QRIS struct {
-	config QRISConfig
-}
-
-// NewQRIS creates a new instance of QRIS.
-// NewQRIS membuat instance baru dari QRIS.
-//
-// It validates the configuration and returns an error if the configuration is invalid.
-// Fungsi ini memvalidasi konfigurasi dan mengembalikan error jika konfigurasi tidak valid.
-func NewQRIS(config QRISConfig) (*QRIS, error) {
-	if config.BaseQrString == "" || config.AuthToken == "" || config.AuthUsername == "" {
-		return nil, errors.New("baseQrString, authToken, and authUsername must be filled / baseQrString, authToken, dan authUsername harus diisi")
-	}
-
-	if !strings.Contains(config.BaseQrString, "5802ID") {
-		return nil, errors.New("invalid baseQrString format / format baseQrString tidak valid")
-	}
-
-	return &QRIS{
-		config: config,
-	}, nil
-}
-
-// GenerateQRCode generates a QR code for QRIS payment.
-// GenerateQRCode menghasilkan QR code untuk pembayaran QRIS.
-//
-// It returns a QR code that can be saved as an image file.
-// Fungsi ini mengembalikan QR code yang dapat disimpan sebagai file gambar.
-func (q *QRIS) GenerateQRCode(data QRISData) (*qrcode.QRCode, error) {
-	if data.Amount <= 0 {
-		return nil, errors.New("amount must be greater than 0 / nominal harus lebih besar dari 0")
-	}
-
-	if data.TransactionID == "" {
-		return nil, errors.New("transactionID must be filled / transactionID harus diisi")
-	}
-
-	// Generate QRIS string
-	qrString, err := q.generateQRISString(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate QRIS string / gagal generate QRIS string: %v", err)
-	}
-
-	// Generate QR code with high error correction level
-	qrCode, err := qrcode.New(qrString, qrcode.High)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate QR code / gagal generate QR code: %v", err)
-	}
-
-	// Set QR code options
-	qrCode.DisableBorder = false
-	qrCode.ForegroundColor = color.Black
-	qrCode.BackgroundColor = color.White
-
-	return qrCode, nil
-}
-
-// generateQRISString generates a QRIS string according to the standard format.
-// generateQRISString menghasilkan string QRIS sesuai format standar.
-func (q *QRIS) generateQRISString(data QRISData) (string, error) {
-	// Format amount
-	amountStr := fmt.Sprintf("%d", data.Amount)
-	amountTag := fmt.Sprintf("54%02d%s", len(amountStr), amountStr)
-
-	// Remove existing CRC and replace 010211 with 010212
-	baseString := q.config.BaseQrString[:len(q.config.BaseQrString)-4]
-	baseString = strings.Replace(baseString, "010211", "010212", 1)
-
-	// Insert amount into base string
-	insertPosition := strings.Index(baseString, "5802ID")
-	if insertPosition == -1 {
-		return "", errors.New("invalid QRIS format: country ID not found / format QRIS tidak valid: ID negara tidak ditemukan")
-	}
-
-	qrString := baseString[:insertPosition] + amountTag + baseString[insertPosition:]
-
-	// Generate CRC
-	crc := q.generateCRC(qrString)
-	return qrString + crc, nil
-}
-
-// generateCRC generates CRC16-CCITT for QRIS string.
-// generateCRC menghasilkan CRC16-CCITT untuk string QRIS.
-func (q *QRIS) generateCRC(data string) string {
-	var crc uint16 = 0xFFFF
-	for i := 0; i < len(data); i++ {
-		crc ^= uint16(data[i]) << 8
-		for j := 0; j < 8; j++ {
-			if (crc & 0x8000) != 0 {
-				crc = (crc << 1) ^ 0x1021
-			} else {
-				crc = crc << 1
-			}
-		}
-	}
-	return fmt.Sprintf("%04X", crc)
-}
-
-// ValidateQRISString validates the QRIS string format.
-// ValidateQRISString memvalidasi format string QRIS.
-//
-// It checks the string length, country ID, merchant ID, amount format, and CRC.
-// Fungsi ini memeriksa panjang string, ID negara, ID merchant, format nominal, dan CRC.
-func (q *QRIS) ValidateQRISString(qrString string) error {
-	if len(qrString) < 20 {
-		return errors.New("QRIS string too short / string QRIS terlalu pendek")
-	}
-
-	// Basic format validation
-	if !strings.Contains(qrString, "5802ID") {
-		return errors.New("invalid QRIS format: country ID not found / format QRIS tidak valid: ID negara tidak ditemukan")
-	}
-
-	// Merchant ID validation removed as it's no longer required
-
-	// Amount format validation
-	if !strings.Contains(qrString, "54") {
-		return errors.New("invalid amount format / format nominal tidak valid")
-	}
-
-	// CRC validation
-	crc := q.generateCRC(qrString[:len(qrString)-4])
-	if crc != qrString[len(qrString)-4:] {
-		return errors.New("invalid checksum / checksum tidak valid")
-	}
-
-	return nil
-}
-
-// GetQRISString generates a QRIS string without creating a QR code.
-// GetQRISString menghasilkan string QRIS tanpa membuat QR code.
-//
-// It's useful when you only need the QRIS string for other purposes.
-// Fungsi ini berguna ketika Anda hanya membutuhkan string QRIS untuk keperluan lain.
-func (q *QRIS) GetQRISString(data QRISData) (string, error) {
-	if data.Amount <= 0 {
-		return "", errors.New("amount must be greater than 0 / nominal harus lebih besar dari 0")
-	}
-
-	if data.TransactionID == "" {
-		return "", errors.New("transactionID must be filled / transactionID harus diisi")
-	}
-
-	return q.generateQRISString(data)
-}
\ No newline at end of file
+// Package qris provides QRIS (Quick Response Code Indonesian Standard) payment integration for Go applications.
+// Package qris menyediakan integrasi pembayaran QRIS (Quick Response Code Indonesian Standard) untuk aplikasi Go.
+package qris
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+	"github.com/skip2/go-qrcode"
+)
+
+// QRISConfig stores the configuration for QRIS operations.
+// QRISConfig menyimpan konfigurasi untuk operasi QRIS.
+type QRISConfig struct {
+	BaseQrString string // Base QRIS string from merchant / Base QRIS string dari merchant
+	AuthToken    string // Authentication token for API calls / Token autentikasi untuk panggilan API
+	AuthUsername string // Authentication username for API calls / Username autentikasi untuk panggilan API
+
+	GatewayURL     string         // Mutation endpoint, default DefaultMutationURL or DefaultOkeconnectURL / Endpoint mutasi, default DefaultMutationURL atau DefaultOkeconnectURL
+	Location       *time.Location // Timezone of gateway dates, default WIB / Zona waktu tanggal gateway, default WIB
+	MatchWindow    time.Duration  // Look-back window of CheckPaymentStatus, default DefaultMatchWindow / Rentang pencarian CheckPaymentStatus, default DefaultMatchWindow
+	IncludeRaw     bool           // Keep raw gateway responses on results / Simpan response mentah gateway di hasil
+	StrictDecoding bool           // Fail with *SchemaError on unexpected or missing mutation fields / Gagal dengan *SchemaError jika field mutasi tidak terduga atau tidak ada
+	MatchPolicy    MatchPolicy    // Decides which mutation pays an invoice, default DefaultMatchPolicy / Menentukan mutasi yang membayar invoice, default DefaultMatchPolicy
+	QRISTypes      []string       // QRIS types accepted without MatchPolicy, default ["static"] (see MatchQRISTypes) / Tipe QRIS yang diterima tanpa MatchPolicy, default ["static"] (lihat MatchQRISTypes)
+
+	Gateway  string           // Built-in mutation gateway, GatewayFTVPN (default) or GatewayOkeconnect / Gateway mutasi bawaan, GatewayFTVPN (default) atau GatewayOkeconnect
+	Provider MutationProvider // Custom mutation source replacing the gateway, see MutationProvider / Sumber mutasi khusus pengganti gateway, lihat MutationProvider
+
+	MinAmount int64 // Smallest accepted amount, 0 for no limit (see DefaultMinAmount) / Nominal terkecil yang diterima, 0 untuk tanpa batas (lihat DefaultMinAmount)
+	MaxAmount int64 // Largest accepted amount, 0 for no limit (see DefaultMaxAmount) / Nominal terbesar yang diterima, 0 untuk tanpa batas (lihat DefaultMaxAmount)
+
+	DeterministicAmounts bool  // Derive unique amounts from transaction IDs (see DeterministicAmount) / Turunkan nominal unik dari ID transaksi (lihat DeterministicAmount)
+	UniqueSuffix         int64 // Largest rupiah suffix of unique amounts, default DefaultUniqueSuffix (see WithUniqueSuffix) / Suffix rupiah terbesar dari nominal unik, default DefaultUniqueSuffix (lihat WithUniqueSuffix)
+	AllowAnyCurrency     bool  // Accept a base QRIS whose tag 53 is not IDR (360) / Terima base QRIS yang tag 53-nya bukan IDR (360)
+	NormalizeOutput      bool  // Emit generated payloads with tags in ascending order and no empty fields, see NormalizeQRISPayload / Hasilkan payload dengan tag berurutan naik dan tanpa field kosong, lihat NormalizeQRISPayload
+
+	RequestTimeout time.Duration // Limit of one gateway request, default DefaultRequestTimeout / Batas satu request gateway, default DefaultRequestTimeout
+	SweepInterval  time.Duration // How often expired sessions are swept, default DefaultSweepInterval / Seberapa sering sesi kedaluwarsa disapu, default DefaultSweepInterval
+	PollSchedule   PollSchedule  // Default schedule of WaitForPayment and session watchers, default every DefaultPollInterval / Jadwal default WaitForPayment dan watcher sesi, default setiap DefaultPollInterval
+
+	ProxyURL         string               // Egress proxy for gateway requests / Proxy keluar untuk request gateway
+	TLSConfig        *tls.Config          // Custom TLS settings, e.g. certificate pinning / Pengaturan TLS khusus, misalnya pinning sertifikat
+	RootCAs          *x509.CertPool       // Trusted roots replacing the system pool / Root yang dipercaya, menggantikan pool sistem
+	UserAgent        string               // User-Agent header, default DefaultUserAgent / Header User-Agent, default DefaultUserAgent
+	Interceptors     []Interceptor        // Wrap every gateway request, first is outermost / Membungkus setiap request gateway, yang pertama paling luar
+	MinRecoveryLevel qrcode.RecoveryLevel // Lowest QR error correction allowed when the payload is long / Koreksi error QR terendah yang diizinkan jika payload panjang
+	Language         string               // Language of UserMessage, "id" or "en" (default) / Bahasa UserMessage, "id" atau "en" (default)
+	QRCache          *QRCache             // Optional cache of rendered PNGs, see NewQRCache / Cache opsional PNG hasil render, lihat NewQRCache
+	LinkTokens       *TokenCodec          // Sign payment link codes instead of minting random ones, see PaymentSession.Code / Tandatangani kode link pembayaran alih-alih membuat kode acak, lihat PaymentSession.Code
+
+	MaxRateLimitWait time.Duration     // Total Retry-After wait per request, default DefaultMaxRateLimitWait / Total jeda Retry-After per request, default DefaultMaxRateLimitWait
+	OnQuota          func(QuotaStatus) // Called with the quota headers of every gateway response that has them / Dipanggil dengan header kuota dari setiap response gateway yang memilikinya
+	MaxResponseSize  int64             // Longest mutation response body, default DefaultMaxResponseSize; longer ones fail with ErrResponseTooLarge / Body response mutasi terpanjang, default DefaultMaxResponseSize; yang lebih panjang gagal dengan ErrResponseTooLarge
+	OnResponseSize   func(bytes int64) // Called with the bytes read of every mutation response body, e.g. for a histogram / Dipanggil dengan jumlah byte yang dibaca dari setiap body response mutasi, misalnya untuk histogram
+
+	InvoiceStore InvoiceStore // Optional store for PaymentSessions / Store opsional untuk PaymentSession
+	RandSource   io.Reader    // Source of IDs, payment codes and amount suffixes, e.g. qristest.SeededRand in tests; default crypto/rand and math/rand / Sumber ID, kode pembayaran dan suffix nominal, misalnya qristest.SeededRand di test; default crypto/rand dan math/rand
+	EventSink    EventSink    // Optional audit trail of session events / Jejak audit opsional dari event sesi
+
+	Sandbox bool // Simulate payments locally instead of calling the gateway / Simulasikan pembayaran secara lokal tanpa memanggil gateway
+}
+
+// QRISMode selects whether a generated QR code carries a fixed amount.
+// QRISMode menentukan apakah QR code yang di-generate membawa nominal tetap.
+type QRISMode int
+
+const (
+	// ModeFixedAmount embeds QRISData.Amount (tag 54) in a dynamic QR code (POI 12).
+	// ModeFixedAmount menyisipkan QRISData.Amount (tag 54) dalam QR code dinamis (POI 12).
+	ModeFixedAmount QRISMode = iota
+
+	// ModeOpenAmount lets the customer type the amount, e.g. for donations: tag 54 is removed,
+	// the POI is set to 11 and the transaction ID is carried as reference label (tag 62, sub-tag 05).
+	// Such payments can only be recognized by that reference, so check them with
+	// CheckPaymentStatus(transactionID, 0), which matches by buyer reference instead of amount.
+	// ModeOpenAmount membiarkan customer mengetik nominal, misalnya untuk donasi: tag 54 dihapus,
+	// POI diatur ke 11 dan ID transaksi dibawa sebagai reference label (tag 62, sub-tag 05).
+	// Pembayaran seperti ini hanya dapat dikenali dari referensi tersebut, jadi cek dengan
+	// CheckPaymentStatus(transactionID, 0), yang mencocokkan berdasarkan referensi pembeli, bukan nominal.
+	ModeOpenAmount
+)
+
+// QRISData stores the data needed to generate a QR code.
+// QRISData menyimpan data yang diperlukan untuk generate QR code.
+type QRISData struct {
+	Amount        int64    // Payment amount, ignored in ModeOpenAmount / Nominal pembayaran, diabaikan di ModeOpenAmount
+	TransactionID string   // Unique transaction ID / ID transaksi unik
+	Mode          QRISMode // Fixed or open amount, default ModeFixedAmount / Nominal tetap atau terbuka, default ModeFixedAmount
+	TruncateIDs   bool     // Cut TransactionID to MaxTransactionIDLength instead of failing / Potong TransactionID menjadi MaxTransactionIDLength alih-alih gagal
+	Note          string   // Shown to the payer by some issuer apps, e.g. "Order #1234", at most MaxNoteLength / Ditampilkan ke pembayar oleh sebagian aplikasi issuer, misalnya "Order #1234", maksimal MaxNoteLength
+	TruncateNote  bool     // Cut Note to MaxNoteLength instead of failing / Potong Note menjadi MaxNoteLength alih-alih gagal
+
+	Accounts []MerchantAccount // Replace the merchant accounts of the base QRIS, if set / Ganti akun merchant dari base QRIS, jika diisi
+}
+
+// QRIS is the main struct for QRIS operations.
+// QRIS adalah struct utama untuk operasi QRIS.
+//
+// A QRIS is safe for concurrent use by multiple goroutines; share one instance per merchant.
+// QRIS aman dipakai bersamaan oleh banyak goroutine; gunakan satu instance per merchant.
+type QRIS struct {
+	mu       sync.RWMutex // guards the credential and base QR fields of config / menjaga field kredensial dan base QR di config
+	config   QRISConfig
+	client   *http.Client
+	amounts  *amountPool
+	sessions *sessionRegistry
+	sandbox  *sandbox
+	merchant string
+	life     *lifecycle
+	events   *eventBus
+	health   *healthCache
+	credits  creditCache
+	quota    quotaTracker
+	skipped  skippedRows
+	claims   *claimSet
+	codes    *codeRegistry
+	rand     *randSource
+
+	sweepOnce sync.Once
+}
+
+// NewQRIS creates a new instance of QRIS.
+// NewQRIS membuat instance baru dari QRIS.
+//
+// It validates the configuration and returns an error if the configuration is invalid.
+// Fungsi ini memvalidasi konfigurasi dan mengembalikan error jika konfigurasi tidak valid.
+// BaseQrString is stored in the form returned by NormalizeQRISString.
+// BaseQrString disimpan dalam bentuk yang dikembalikan NormalizeQRISString.
+//
+// In sandbox mode AuthToken and AuthUsername may be left empty.
+// Dalam mode sandbox AuthToken dan AuthUsername boleh dikosongkan.
+func NewQRIS(config QRISConfig) (*QRIS, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Timeouts are applied per request through the context, see requestContext
+	client, err := transport.NewClient(config.transport())
+	if err != nil {
+		return nil, err
+	}
+	return newQRIS(config, client), nil
+}
+
+// newQRIS builds an instance around an already validated config and a (possibly shared) HTTP client.
+// newQRIS membuat instance dari config yang sudah divalidasi dan HTTP client (yang mungkin dipakai bersama).
+func newQRIS(config QRISConfig, client *http.Client) *QRIS {
+	// Validation already accepted the base, so only the cleanup is kept
+	if base, err := normalizeBaseQR(config.BaseQrString); err == nil {
+		config.BaseQrString = base
+	}
+	rand := newRandSource(config.RandSource)
+	return &QRIS{
+		config:   config,
+		client:   client,
+		amounts:  newAmountPool(rand),
+		sessions: newSessionRegistry(),
+		sandbox:  &sandbox{},
+		life:     newLifecycle(),
+		events:   newEventBus(config.EventSink),
+		health:   &healthCache{},
+		claims:   newClaimSet(),
+		codes:    newCodeRegistry(),
+		rand:     rand,
+	}
+}
+
+// GenerateQRCode generates a QR code for QRIS payment.
+// GenerateQRCode menghasilkan QR code untuk pembayaran QRIS.
+//
+// It returns a QR code that can be saved as an image file. The code encodes the payload of BuildPayload.
+// Fungsi ini mengembalikan QR code yang dapat disimpan sebagai file gambar. QR code berisi payload dari BuildPayload.
+func (q *QRIS) GenerateQRCode(data QRISData) (*qrcode.QRCode, error) {
+	qrString, err := q.BuildPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	return q.newQRCode(qrString)
+}
+
+// BuildPayload checks data and returns the CRC-signed EMV string GenerateQRCode would encode,
+// without rendering an image, e.g. to log it for review.
+// BuildPayload memeriksa data dan mengembalikan string EMV bertanda CRC yang akan di-encode oleh
+// GenerateQRCode, tanpa me-render gambar, misalnya untuk dicatat dan ditinjau.
+func (q *QRIS) BuildPayload(data QRISData) (string, error) {
+	data, err := q.checkInput(data)
+	if err != nil {
+		return "", err
+	}
+
+	qrString, err := q.generateQRISString(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate QRIS string / gagal generate QRIS string: %w", err)
+	}
+	return qrString, nil
+}
+
+// PreviewPayload returns the payload of BuildPayload parsed into its fields, for display.
+// PreviewPayload mengembalikan payload dari BuildPayload yang sudah di-parse menjadi field, untuk ditampilkan.
+func (q *QRIS) PreviewPayload(data QRISData) (*QRISPayload, error) {
+	qrString, err := q.BuildPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseQRISString(qrString)
+}
+
+// generateQRISString generates a QRIS string according to the standard format.
+// generateQRISString menghasilkan string QRIS sesuai format standar.
+func (q *QRIS) generateQRISString(data QRISData) (string, error) {
+	return q.encodeQRIS(data, embedsReference(q.matchPolicy()))
+}
+
+// encodeQRIS builds the QRIS string for data, carrying the transaction ID in tag 62 if embedRef is set.
+// encodeQRIS menyusun string QRIS untuk data, membawa ID transaksi di tag 62 jika embedRef diaktifkan.
+func (q *QRIS) encodeQRIS(data QRISData, embedRef bool) (string, error) {
+	if err := q.life.err(); err != nil {
+		return "", err
+	}
+
+	qrString := q.baseQR()
+	if len(data.Accounts) > 0 {
+		var err error
+		if qrString, err = setMerchantAccounts(qrString, data.Accounts); err != nil {
+			return "", err
+		}
+	}
+
+	if data.Mode == ModeOpenAmount {
+		var err error
+		if qrString, err = emv.SetOpenAmount(qrString); err != nil {
+			return "", err
+		}
+		embedRef = true
+	} else {
+		if !strings.Contains(qrString, "5802ID") {
+			return "", errors.New("invalid QRIS format: country ID not found / format QRIS tidak valid: ID negara tidak ditemukan")
+		}
+		var err error
+		if qrString, err = emv.SetAmount(qrString, data.Amount); err != nil {
+			return "", err
+		}
+	}
+
+	// Carry the transaction ID as reference label for MatchByBuyerRef
+	if embedRef {
+		if len(data.TransactionID) > MaxTransactionIDLength {
+			return "", fmt.Errorf("%w: exceeds %d characters of the reference label / melebihi %d karakter reference label",
+				ErrTransactionIDTooLong, MaxTransactionIDLength, MaxTransactionIDLength)
+		}
+		var err error
+		if qrString, err = emv.SetAdditionalData(qrString, "05", data.TransactionID); err != nil {
+			return "", err
+		}
+	}
+
+	// Mark sandbox QR codes so they can never be mistaken for production ones; the note follows the marker
+	purpose := data.Note
+	if q.config.Sandbox {
+		purpose = strings.TrimSpace(SandboxMarker + " " + purpose)
+		if len(purpose) > emv.MaxAdditionalDataLength {
+			purpose = strings.TrimSpace(purpose[:emv.MaxAdditionalDataLength])
+		}
+	}
+	if purpose != "" {
+		var err error
+		if qrString, err = emv.SetPurpose(qrString, purpose); err != nil {
+			return "", err
+		}
+	}
+	// Some downstream systems reject tags out of order, e.g. a base QR with tag 62 before tag 58
+	if q.config.NormalizeOutput {
+		return emv.Normalize(qrString)
+	}
+	return qrString, nil
+}
+
+// ValidateQRISString validates the QRIS string format.
+// ValidateQRISString memvalidasi format string QRIS.
+//
+// It checks the string length, country ID, merchant ID, amount format, and CRC.
+// Fungsi ini memeriksa panjang string, ID negara, ID merchant, format nominal, dan CRC.
+func (q *QRIS) ValidateQRISString(qrString string) error {
+	return ValidateQRISStringDetailed(qrString).Err()
+}
+
+// GetQRISString generates a QRIS string without creating a QR code.
+// GetQRISString menghasilkan string QRIS tanpa membuat QR code.
+//
+// It's useful when you only need the QRIS string for other purposes. It is the same as BuildPayload.
+// Fungsi ini berguna ketika Anda hanya membutuhkan string QRIS untuk keperluan lain. Fungsi ini sama dengan BuildPayload.
+func (q *QRIS) GetQRISString(data QRISData) (string, error) {
+	return q.BuildPayload(data)
+}
@@ -0,0 +1,75 @@
+// Package qrishttp provides HTTP handlers for services built on the qris package.
+// Package qrishttp menyediakan handler HTTP untuk layanan yang memakai paket qris.
+package qrishttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// healthResponse is the JSON body written by HealthHandler.
+// healthResponse adalah body JSON yang ditulis HealthHandler.
+type healthResponse struct {
+	Status string `json:"status"`
+	Class  string `json:"class,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Code   string `json:"code,omitempty"` // See qris.ErrorCode / Lihat qris.ErrorCode
+}
+
+// HealthChecker checks the gateway, like qris.QRIS.HealthCheck. *qris.QRIS implements it.
+// HealthChecker memeriksa gateway, seperti qris.QRIS.HealthCheck. *qris.QRIS mengimplementasikannya.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthHandler serves q.HealthCheck for liveness and readiness probes. It answers
+// 200 {"status":"ok"} when the gateway is healthy and 503 otherwise, with the failure class
+// ("unauthorized", "rate_limited", "timeout", "unreachable", "closed", or "gateway"), the error message and its qris.ErrorCode.
+// HealthHandler melayani q.HealthCheck untuk probe liveness dan readiness. Handler menjawab
+// 200 {"status":"ok"} jika gateway sehat dan 503 jika tidak, beserta kelas kegagalan
+// ("unauthorized", "rate_limited", "timeout", "unreachable", "closed", atau "gateway"), pesan error dan qris.ErrorCode-nya.
+func HealthHandler(q HealthChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := q.HealthCheck(r.Context())
+		if err == nil {
+			writeJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+			return
+		}
+		writeJSON(w, http.StatusServiceUnavailable, healthResponse{
+			Status: "unavailable",
+			Class:  failureClass(err),
+			Error:  err.Error(),
+			Code:   qris.ErrorCode(err),
+		})
+	})
+}
+
+// failureClass names the cause of a failed health check.
+// failureClass menamai penyebab health check yang gagal.
+func failureClass(err error) string {
+	switch {
+	case errors.Is(err, qris.ErrGatewayUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, qris.ErrRateLimitedByGateway):
+		return "rate_limited"
+	case errors.Is(err, qris.ErrGatewayTimeout):
+		return "timeout"
+	case errors.Is(err, qris.ErrGatewayUnreachable):
+		return "unreachable"
+	case errors.Is(err, qris.ErrClosed):
+		return "closed"
+	default:
+		return "gateway"
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
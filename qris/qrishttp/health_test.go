@@ -0,0 +1,91 @@
+package qrishttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+)
+
+// checkerFunc adapts a function to qrishttp.HealthChecker.
+type checkerFunc func(ctx context.Context) error
+
+func (f checkerFunc) HealthCheck(ctx context.Context) error { return f(ctx) }
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   map[string]string
+	}{
+		{
+			name:       "healthy",
+			wantStatus: http.StatusOK,
+			wantBody:   map[string]string{"status": "ok"},
+		},
+		{
+			name:       "unauthorized",
+			err:        fmt.Errorf("%w: status 401", qris.ErrGatewayUnauthorized),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]string{"status": "unavailable", "class": "unauthorized", "code": "GATEWAY_UNAUTHORIZED"},
+		},
+		{
+			name:       "timeout",
+			err:        qris.ErrGatewayTimeout,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]string{"status": "unavailable", "class": "timeout", "code": "GATEWAY_TIMEOUT"},
+		},
+		{
+			name:       "unreachable",
+			err:        qris.ErrGatewayUnreachable,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]string{"status": "unavailable", "class": "unreachable", "code": "GATEWAY_UNREACHABLE"},
+		},
+		{
+			name:       "closed",
+			err:        qris.ErrClosed,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]string{"status": "unavailable", "class": "closed"},
+		},
+		{
+			name:       "other",
+			err:        fmt.Errorf("unexpected"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]string{"status": "unavailable", "class": "gateway"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := qrishttp.HealthHandler(checkerFunc(func(context.Context) error { return tt.err }))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body, err)
+			}
+			if tt.err != nil && body["error"] != tt.err.Error() {
+				t.Errorf("error = %q, want %q", body["error"], tt.err.Error())
+			}
+			delete(body, "error")
+			if tt.wantBody["code"] == "" {
+				delete(body, "code")
+			}
+			if fmt.Sprint(body) != fmt.Sprint(tt.wantBody) {
+				t.Errorf("body = %v, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package qrishttp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// sseKeepAlive is how often the event stream sends a comment so proxies keep the connection open.
+// sseKeepAlive adalah seberapa sering stream event mengirim komentar agar proxy tetap membuka koneksi.
+const sseKeepAlive = 15 * time.Second
+
+// payPage is the payment link page: the QR code, the amount, and the status kept current by
+// the events endpoint of the same routes.
+// payPage adalah halaman link pembayaran: QR code, nominal, dan status yang diperbarui oleh
+// endpoint events dari route yang sama.
+var payPage = template.Must(template.New("pay").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>body{font-family:sans-serif;text-align:center;margin:2em}img{width:256px;height:256px}</style>
+</head>
+<body>
+{{if .QR}}
+<h1>{{.Amount}}</h1>
+<img src="{{.QR}}" alt="QRIS">
+<p id="status">{{.Status}}</p>
+<p>{{.Expires}}</p>
+<script>
+var es = new EventSource({{.Events}});
+es.addEventListener("status", function (e) {
+	var s = JSON.parse(e.data);
+	document.getElementById("status").textContent = s.message;
+	if (s.status !== "UNPAID") { es.close(); }
+});
+</script>
+{{else}}
+<h1>{{.Title}}</h1>
+<p>{{.Status}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+// payPageData fills payPage.
+// payPageData mengisi payPage.
+type payPageData struct {
+	Title   string
+	Amount  string
+	QR      template.URL
+	Status  string
+	Expires string
+	Events  string
+}
+
+// statusEvent is the data of a "status" server-sent event.
+// statusEvent adalah data dari server-sent event "status".
+type statusEvent struct {
+	qris.PaymentStatusView
+	Message string `json:"message"` // qris.StatusMessage in QRISConfig.Language / qris.StatusMessage dalam QRISConfig.Language
+}
+
+// MarshalJSON adds message to the JSON of the view.
+// MarshalJSON menambahkan message ke JSON dari view.
+func (e statusEvent) MarshalJSON() ([]byte, error) {
+	view, err := json.Marshal(e.PaymentStatusView)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(view, &fields); err != nil {
+		return nil, err
+	}
+	fields["message"], _ = json.Marshal(e.Message)
+	return json.Marshal(fields)
+}
+
+// pay serves the payment link page of the {code} parameter: 404 for unknown codes, 410 once the
+// invoice expired.
+// pay melayani halaman link pembayaran dari parameter {code}: 404 untuk kode yang tidak dikenal, 410
+// setelah invoice kedaluwarsa.
+func (h *invoiceHandlers) pay(w http.ResponseWriter, r *http.Request) {
+	lang := h.language()
+	s, err := h.sq.ResolveCode(r.Context(), PathParam(r, "code"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, qris.ErrPaymentExpired):
+			status = http.StatusGone
+		case errors.Is(err, qris.ErrNotFound):
+			status = http.StatusNotFound
+		}
+		writeHTML(w, status, payPageData{Title: h.userMessage(err), Status: http.StatusText(status)})
+		return
+	}
+
+	// The amount shown is the one the QR code pays, even while Regenerate runs
+	snap, png, err := s.SnapshotPNG(DefaultQRSize)
+	if err != nil {
+		writeHTML(w, http.StatusInternalServerError, payPageData{Title: h.userMessage(err)})
+		return
+	}
+	writeHTML(w, http.StatusOK, payPageData{
+		Title:   "QRIS " + qris.FormatIDR(snap.Amount),
+		Amount:  qris.FormatIDR(snap.Amount),
+		QR:      template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+		Status:  qris.StatusMessage(qris.StatusUnpaid, lang),
+		Expires: snap.ExpiresAt.Format("15:04 MST"),
+		Events:  "../payments/" + url.PathEscape(snap.TransactionID) + "/events",
+	})
+}
+
+// events streams the status of the {id} payment as server-sent "status" events: the current status
+// right away, then the final one when the session finishes.
+// events mengalirkan status pembayaran {id} sebagai server-sent event "status": status saat ini
+// langsung, lalu status akhir ketika sesi selesai.
+func (h *invoiceHandlers) events(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, qris.WithCode(errors.New("streaming unsupported / streaming tidak didukung"), "STREAMING_UNSUPPORTED"), "")
+		return
+	}
+
+	ctx := r.Context()
+	final := make(chan *qris.PaymentStatus, 1)
+	go func() {
+		status, _ := s.Wait(ctx)
+		final <- status
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	lang := h.language()
+	send := func(status *qris.PaymentStatus) {
+		data, _ := json.Marshal(statusEvent{status.APIView(), qris.StatusMessage(status.Status, lang)})
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+	select {
+	case <-s.Done():
+	default:
+		send(&qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: s.Amount(), Reference: s.TransactionID})
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case status := <-final:
+			if status != nil {
+				send(status)
+			}
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeHTML(w http.ResponseWriter, status int, data payPageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	payPage.Execute(w, data)
+}
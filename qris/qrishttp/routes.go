@@ -0,0 +1,549 @@
+package qrishttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// Route is one endpoint of the invoice API, ready to be mounted on any router.
+// Route adalah satu endpoint API invoice, siap dipasang di router apa pun.
+//
+// Pattern uses {name} for path parameters, as chi and Go 1.22+ ServeMux do; use ColonPattern
+// for gin and echo. Handlers read their parameters with PathParam, which works whatever the router.
+// Pattern memakai {name} untuk parameter path, seperti chi dan ServeMux Go 1.22+; gunakan ColonPattern
+// untuk gin dan echo. Handler membaca parameternya dengan PathParam, yang berfungsi di router apa pun.
+type Route struct {
+	Method  string
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+// ColonPattern returns Pattern with {name} written as :name, as gin and echo expect.
+// ColonPattern mengembalikan Pattern dengan {name} ditulis sebagai :name, sesuai yang diharapkan gin dan echo.
+func (rt Route) ColonPattern() string {
+	segments := strings.Split(rt.Pattern, "/")
+	for i, seg := range segments {
+		if name, ok := paramName(seg); ok {
+			segments[i] = ":" + name
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// DefaultQRSize is the PNG size of GET /payments/{id}/qr.png without a size query parameter.
+// DefaultQRSize adalah ukuran PNG dari GET /payments/{id}/qr.png tanpa parameter query size.
+const DefaultQRSize = 256
+
+// maxQRSize bounds the size query parameter so a request cannot make the server render huge images.
+// maxQRSize membatasi parameter query size agar request tidak membuat server me-render gambar raksasa.
+const maxQRSize = 2048
+
+// finishedRetention is how long a session created through the routes stays readable after it expires.
+// finishedRetention adalah lamanya sesi yang dibuat melalui route tetap dapat dibaca setelah kedaluwarsa.
+const finishedRetention = time.Hour
+
+// Service is what Routes and Handler need from q. *qris.QRIS implements it; in tests a fake built
+// on qristest.StubChecker does too.
+// Service adalah yang dibutuhkan Routes dan Handler dari q. *qris.QRIS mengimplementasikannya; di test
+// tiruan yang dibangun di atas qristest.StubChecker juga bisa.
+type Service interface {
+	qris.QRGenerator
+	qris.PaymentStatusChecker
+	qris.MutationReader
+}
+
+// SessionService is a Service that also creates and tracks PaymentSessions, like *qris.QRIS.
+// The routes of sessions are only served for a SessionService.
+// SessionService adalah Service yang juga membuat dan melacak PaymentSession, seperti *qris.QRIS.
+// Route untuk sesi hanya dilayani untuk SessionService.
+type SessionService interface {
+	Service
+	CreatePayment(ctx context.Context, amount int64, opts ...qris.PaymentOption) (*qris.PaymentSession, error)
+	Session(transactionID string) (*qris.PaymentSession, bool)
+	ResolveCode(ctx context.Context, code string) (*qris.PaymentSession, error)
+}
+
+var _ SessionService = (*qris.QRIS)(nil)
+
+// Routes returns the invoice API of q:
+// Routes mengembalikan API invoice dari q:
+//
+//	GET    /health               HealthHandler, if q is a HealthChecker / HealthHandler, jika q adalah HealthChecker
+//	GET    /qr.png               QR code PNG of ?amount=, ?transaction_id= and ?size= / PNG QR code dari ?amount=, ?transaction_id= dan ?size=
+//	GET    /mutations            mutation history, ?from= and ?to= as RFC3339, ?type=, ?page=, ?per_page= / riwayat mutasi
+//	GET    /payments/{id}        payment status as qris.PaymentStatusView / status pembayaran sebagai qris.PaymentStatusView
+//
+// and, if q is a SessionService:
+// dan, jika q adalah SessionService:
+//
+//	POST   /payments             create a payment from {"amount", "transaction_id", "expiry_seconds", "metadata"} / buat pembayaran
+//	DELETE /payments/{id}        cancel a pending payment / batalkan pembayaran yang menunggu
+//	GET    /payments/{id}/qr.png QR code PNG, ?size= in pixels / PNG QR code, ?size= dalam piksel
+//	GET    /payments/{id}/events status as server-sent events / status sebagai server-sent event
+//	GET    /pay/{code}           payment link page of PaymentSession.Code / halaman link pembayaran dari PaymentSession.Code
+//
+// GET /payments/{id} answers from the session {id} if there is one, and otherwise checks {id} as
+// reference with q.CheckPaymentStatusContext when ?amount= is given. Payments created through the
+// routes stay readable for an hour after they expire; other sessions of q are found while they are pending.
+// GET /payments/{id} menjawab dari sesi {id} jika ada, dan jika tidak mengecek {id} sebagai reference
+// dengan q.CheckPaymentStatusContext jika ?amount= diberikan. Pembayaran yang dibuat melalui route tetap
+// dapat dibaca selama satu jam setelah kedaluwarsa; sesi lain dari q ditemukan selama masih menunggu.
+func Routes(q Service) []Route {
+	h := &invoiceHandlers{q: q, sessions: make(map[string]*qris.PaymentSession)}
+	h.sq, _ = q.(SessionService)
+
+	var routes []Route
+	if hc, ok := q.(HealthChecker); ok {
+		routes = append(routes, Route{http.MethodGet, "/health", HealthHandler(hc).ServeHTTP})
+	}
+	routes = append(routes,
+		Route{http.MethodGet, "/qr.png", h.generate},
+		Route{http.MethodGet, "/mutations", h.mutations},
+		Route{http.MethodGet, "/payments/{id}", h.status},
+	)
+	if h.sq != nil {
+		routes = append(routes,
+			Route{http.MethodPost, "/payments", h.create},
+			Route{http.MethodDelete, "/payments/{id}", h.cancel},
+			Route{http.MethodGet, "/payments/{id}/qr.png", h.png},
+			Route{http.MethodGet, "/payments/{id}/events", h.events},
+			Route{http.MethodGet, "/pay/{code}", h.pay},
+		)
+	}
+	for i := range routes {
+		routes[i].Handler = withPathParams(routes[i].Pattern, routes[i].Handler)
+	}
+	return routes
+}
+
+// Handler serves Routes(q) without any router, for mounting on a plain http.ServeMux:
+// Handler melayani Routes(q) tanpa router, untuk dipasang di http.ServeMux biasa:
+//
+//	mux.Handle("/qris/", http.StripPrefix("/qris", qrishttp.Handler(q)))
+func Handler(q Service) http.Handler {
+	routes := Routes(q)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, rt := range routes {
+			params, ok := matchPattern(rt.Pattern, r.URL.Path, false)
+			if !ok {
+				continue
+			}
+			if rt.Method != r.Method {
+				allowed = append(allowed, rt.Method)
+				continue
+			}
+			rt.Handler(w, r.WithContext(context.WithValue(r.Context(), paramsKey{}, params)))
+			return
+		}
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeError(w, http.StatusMethodNotAllowed, qris.WithCode(errors.New("method not allowed / method tidak diizinkan"), "METHOD_NOT_ALLOWED"), "")
+			return
+		}
+		writeError(w, http.StatusNotFound, qris.WithCode(errors.New("not found / tidak ditemukan"), "ROUTE_NOT_FOUND"), "")
+	})
+}
+
+// paramsKey is the context key of the path parameters set by WithPathParam.
+// paramsKey adalah key context dari parameter path yang diatur oleh WithPathParam.
+type paramsKey struct{}
+
+// PathParam returns the path parameter name of r: the value set with WithPathParam or by Handler,
+// or else the path segment at the position of {name} in the route pattern, counted from the end of
+// the path so routes mounted under any prefix work.
+// PathParam mengembalikan parameter path name dari r: nilai yang diatur dengan WithPathParam atau oleh
+// Handler, atau segmen path di posisi {name} dalam pattern route, dihitung dari akhir path sehingga
+// route yang dipasang di bawah prefix apa pun tetap berfungsi.
+func PathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+// WithPathParam returns r carrying value as the path parameter name, for routers whose
+// parameters cannot be found in the path, e.g. after rewriting it.
+// WithPathParam mengembalikan r yang membawa value sebagai parameter path name, untuk router yang
+// parameternya tidak dapat ditemukan di path, misalnya setelah path ditulis ulang.
+func WithPathParam(r *http.Request, name, value string) *http.Request {
+	old, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	params := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		params[k] = v
+	}
+	params[name] = value
+	return r.WithContext(context.WithValue(r.Context(), paramsKey{}, params))
+}
+
+// withPathParams wraps a handler so the parameters of pattern missing from the context are read
+// from the end of the request path.
+// withPathParams membungkus handler sehingga parameter pattern yang tidak ada di context dibaca
+// dari akhir path request.
+func withPathParams(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	if !strings.Contains(pattern, "{") {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		matched, _ := matchPattern(pattern, r.URL.Path, true)
+		set, _ := r.Context().Value(paramsKey{}).(map[string]string)
+		for name, value := range matched {
+			if _, ok := set[name]; !ok {
+				r = WithPathParam(r, name, value)
+			}
+		}
+		next(w, r)
+	}
+}
+
+// matchPattern matches path against pattern and returns its parameters. With suffix set, pattern
+// only has to match the end of path.
+// matchPattern mencocokkan path dengan pattern dan mengembalikan parameternya. Dengan suffix aktif,
+// pattern cukup cocok dengan akhir path.
+func matchPattern(pattern, path string, suffix bool) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(got) < len(want) || (!suffix && len(got) != len(want)) {
+		return nil, false
+	}
+	got = got[len(got)-len(want):]
+
+	params := make(map[string]string)
+	for i, seg := range want {
+		if name, ok := paramName(seg); ok {
+			if got[i] == "" {
+				return nil, false
+			}
+			params[name] = got[i]
+		} else if seg != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// paramName returns name if seg is "{name}".
+// paramName mengembalikan name jika seg adalah "{name}".
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
+
+// invoiceHandlers serves the payment routes of one QRIS instance.
+// invoiceHandlers melayani route pembayaran dari satu instance QRIS.
+type invoiceHandlers struct {
+	q  Service
+	sq SessionService // q, if it is one / q, jika q adalah SessionService
+
+	mu       sync.Mutex
+	sessions map[string]*qris.PaymentSession // Created through the routes / Dibuat melalui route
+}
+
+// createRequest is the JSON body of POST /payments.
+// createRequest adalah body JSON dari POST /payments.
+type createRequest struct {
+	Amount        int64  `json:"amount"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	ExpirySeconds int64  `json:"expiry_seconds,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// paymentResponse is the JSON body of a created payment.
+// paymentResponse adalah body JSON dari pembayaran yang dibuat.
+type paymentResponse struct {
+	TransactionID string     `json:"transaction_id"`
+	BaseAmount    qris.Money `json:"base_amount"`
+	Amount        qris.Money `json:"amount"`
+	QRString      string     `json:"qr_string"`
+	Code          string     `json:"code"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// errorResponse is the JSON body of failed requests.
+// errorResponse adalah body JSON dari request yang gagal.
+type errorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`              // Stable code, see qris.ErrorCode / Kode stabil, lihat qris.ErrorCode
+	Message string `json:"message,omitempty"` // Customer-facing text, see qris.UserMessage / Teks untuk customer, lihat qris.UserMessage
+}
+
+func (h *invoiceHandlers) create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, qris.WithCode(errors.New("invalid JSON body / body JSON tidak valid"), "INVALID_REQUEST"), "")
+		return
+	}
+
+	var opts []qris.PaymentOption
+	if req.TransactionID != "" {
+		opts = append(opts, qris.WithTransactionID(req.TransactionID))
+	}
+	if req.ExpirySeconds > 0 {
+		opts = append(opts, qris.WithExpiry(time.Duration(req.ExpirySeconds)*time.Second))
+	}
+	if len(req.Metadata) > 0 {
+		opts = append(opts, qris.WithMetadata(req.Metadata))
+	}
+	s, err := h.sq.CreatePayment(r.Context(), req.Amount, opts...)
+	if err != nil {
+		writeError(w, errorStatus(err), err, h.userMessage(err))
+		return
+	}
+
+	h.mu.Lock()
+	for id, old := range h.sessions {
+		if time.Since(old.ExpiresAt) > finishedRetention {
+			delete(h.sessions, id)
+		}
+	}
+	h.sessions[s.TransactionID] = s
+	h.mu.Unlock()
+
+	snap := s.Snapshot()
+	writeJSON(w, http.StatusCreated, paymentResponse{
+		TransactionID: snap.TransactionID,
+		BaseAmount:    qris.Money{Value: snap.BaseAmount, Currency: "IDR", Formatted: qris.FormatIDR(snap.BaseAmount)},
+		Amount:        qris.Money{Value: snap.Amount, Currency: "IDR", Formatted: qris.FormatIDR(snap.Amount)},
+		QRString:      snap.QRString,
+		Code:          snap.Code,
+		CreatedAt:     snap.CreatedAt,
+		ExpiresAt:     snap.ExpiresAt,
+		Metadata:      snap.Metadata,
+	})
+}
+
+func (h *invoiceHandlers) status(w http.ResponseWriter, r *http.Request) {
+	var status *qris.PaymentStatus
+	var err error
+	if s, ok := h.findSession(PathParam(r, "id")); ok {
+		status, err = s.Status(r.Context())
+	} else if v := r.URL.Query().Get("amount"); v != "" {
+		amount, perr := strconv.ParseInt(v, 10, 64)
+		if perr != nil || amount < 0 {
+			writeError(w, http.StatusBadRequest, qris.WithCode(errors.New("amount must be a whole number / amount harus bilangan bulat"), "INVALID_REQUEST"), "")
+			return
+		}
+		status, err = h.q.CheckPaymentStatusContext(r.Context(), PathParam(r, "id"), amount)
+	} else {
+		writeError(w, http.StatusNotFound, qris.ErrNotFound, h.userMessage(qris.ErrNotFound))
+		return
+	}
+	if err != nil {
+		writeError(w, errorStatus(err), err, h.userMessage(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, status.APIView())
+}
+
+func (h *invoiceHandlers) cancel(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	s.Cancel()
+	status, err := s.Status(r.Context())
+	if err != nil {
+		writeError(w, errorStatus(err), err, h.userMessage(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, status.APIView())
+}
+
+func (h *invoiceHandlers) png(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	size, ok := qrSize(w, r)
+	if !ok {
+		return
+	}
+	png, err := s.PNG(size)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err, h.userMessage(err))
+		return
+	}
+	writePNG(w, png)
+}
+
+// generate serves GET /qr.png through q.GenerateQRCode, without a session.
+// generate melayani GET /qr.png melalui q.GenerateQRCode, tanpa sesi.
+func (h *invoiceHandlers) generate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	amount, err := strconv.ParseInt(query.Get("amount"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, qris.WithCode(errors.New("amount must be a whole number / amount harus bilangan bulat"), "INVALID_REQUEST"), "")
+		return
+	}
+	size, ok := qrSize(w, r)
+	if !ok {
+		return
+	}
+	qr, err := h.q.GenerateQRCode(qris.QRISData{Amount: amount, TransactionID: query.Get("transaction_id")})
+	if err != nil {
+		writeError(w, errorStatus(err), err, h.userMessage(err))
+		return
+	}
+	png, err := qr.PNG(size)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err, h.userMessage(err))
+		return
+	}
+	writePNG(w, png)
+}
+
+// mutationView is one mutation of GET /mutations.
+// mutationView adalah satu mutasi dari GET /mutations.
+type mutationView struct {
+	Amount    qris.Money  `json:"amount"`
+	Date      string      `json:"date"`
+	Time      *time.Time  `json:"time"`
+	QRIS      string      `json:"qris"`
+	Type      string      `json:"type"`
+	IssuerRef string      `json:"issuer_ref"`
+	BrandName string      `json:"brand_name"`
+	Issuer    qris.Issuer `json:"issuer"`
+	BuyerRef  string      `json:"buyer_ref"`
+}
+
+// mutations serves GET /mutations through q.GetMutations.
+// mutations melayani GET /mutations melalui q.GetMutations.
+func (h *invoiceHandlers) mutations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	mq := qris.MutationQuery{Type: strings.ToUpper(query.Get("type"))}
+	var err error
+	for name, t := range map[string]*time.Time{"from": &mq.From, "to": &mq.To} {
+		if v := query.Get(name); v != "" {
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				writeError(w, http.StatusBadRequest, qris.WithCode(errors.New(name+" must be RFC3339 / "+name+" harus RFC3339"), "INVALID_REQUEST"), "")
+				return
+			}
+		}
+	}
+	for name, n := range map[string]*int{"page": &mq.Page, "per_page": &mq.PerPage} {
+		if v := query.Get(name); v != "" {
+			if *n, err = strconv.Atoi(v); err != nil || *n <= 0 {
+				writeError(w, http.StatusBadRequest, qris.WithCode(errors.New(name+" must be a positive number / "+name+" harus bilangan positif"), "INVALID_REQUEST"), "")
+				return
+			}
+		}
+	}
+
+	list, err := h.q.GetMutations(r.Context(), mq)
+	if err != nil {
+		writeError(w, errorStatus(err), err, h.userMessage(err))
+		return
+	}
+	views := make([]mutationView, 0, len(list))
+	for _, m := range list {
+		v := mutationView{
+			Amount:    qris.Money{Value: m.Amount, Currency: "IDR", Formatted: qris.FormatIDR(m.Amount)},
+			Date:      m.Date,
+			QRIS:      m.QRIS,
+			Type:      m.Type,
+			IssuerRef: m.IssuerRef,
+			BrandName: m.BrandName,
+			Issuer:    m.Issuer,
+			BuyerRef:  m.BuyerRef,
+		}
+		if !m.Time.IsZero() {
+			t := m.Time
+			v.Time = &t
+		}
+		views = append(views, v)
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+// qrSize reads the size query parameter, answering 400 when it is invalid.
+// qrSize membaca parameter query size, dan menjawab 400 jika tidak valid.
+func qrSize(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("size")
+	if v == "" {
+		return DefaultQRSize, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 || n > maxQRSize {
+		writeError(w, http.StatusBadRequest, qris.WithCode(errors.New("size must be 1-2048 / size harus 1-2048"), "INVALID_REQUEST"), "")
+		return 0, false
+	}
+	return n, true
+}
+
+func writePNG(w http.ResponseWriter, png []byte) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// session finds the session of the {id} parameter, answering 404 when there is none.
+// session mencari sesi dari parameter {id}, dan menjawab 404 jika tidak ada.
+func (h *invoiceHandlers) session(w http.ResponseWriter, r *http.Request) (*qris.PaymentSession, bool) {
+	s, ok := h.findSession(PathParam(r, "id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, qris.ErrNotFound, h.userMessage(qris.ErrNotFound))
+	}
+	return s, ok
+}
+
+// findSession returns the session id, created through the routes or tracked by q.
+// findSession mengembalikan sesi id, yang dibuat melalui route atau dilacak oleh q.
+func (h *invoiceHandlers) findSession(id string) (*qris.PaymentSession, bool) {
+	h.mu.Lock()
+	s, ok := h.sessions[id]
+	h.mu.Unlock()
+	if !ok && h.sq != nil {
+		s, ok = h.sq.Session(id)
+	}
+	return s, ok
+}
+
+// language returns QRISConfig.Language of q, if q has a config.
+// language mengembalikan QRISConfig.Language dari q, jika q memiliki config.
+func (h *invoiceHandlers) language() string {
+	if c, ok := h.q.(interface{ Config() qris.QRISConfig }); ok {
+		return c.Config().Language
+	}
+	return ""
+}
+
+// userMessage is qris.UserMessage in the language of q.
+// userMessage adalah qris.UserMessage dalam bahasa q.
+func (h *invoiceHandlers) userMessage(err error) string {
+	return qris.UserMessage(err, h.language())
+}
+
+// errorStatus maps an error of the qris package to an HTTP status.
+// errorStatus memetakan error dari paket qris ke status HTTP.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, qris.ErrInvalidAmount), errors.Is(err, qris.ErrAmountOutOfRange),
+		errors.Is(err, qris.ErrInvalidTransactionID), errors.Is(err, qris.ErrTransactionIDTooLong),
+		errors.Is(err, qris.ErrMetadataTooLarge), qris.ErrorCode(err) == "INVALID_REFERENCE":
+		return http.StatusBadRequest
+	case errors.Is(err, qris.ErrNoAmountAvailable), errors.Is(err, qris.ErrAmountCollision):
+		return http.StatusConflict
+	case errors.Is(err, qris.ErrClosed), errors.Is(err, qris.ErrGatewayMaintenance):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, qris.ErrGatewayTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error, message string) {
+	writeJSON(w, status, errorResponse{Error: err.Error(), Code: qris.ErrorCode(err), Message: message})
+}
@@ -0,0 +1,306 @@
+package qrishttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// testBaseQR is a static QRIS of a test merchant.
+const testBaseQR = "00020101021126600014ID.CO.QRIS.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5915AutoFTbot Store6012Kota Jakarta6105123456304FF47"
+
+// newTestQRIS returns an instance reading mutations from a fake gateway, closed when the test ends.
+func newTestQRIS(t *testing.T) (*qris.QRIS, *qristest.Server) {
+	t.Helper()
+	gw := qristest.NewServer()
+	t.Cleanup(gw.Close)
+	q, err := qris.NewQRIS(qris.QRISConfig{
+		BaseQrString: testBaseQR,
+		AuthToken:    "token",
+		AuthUsername: "user",
+		GatewayURL:   gw.URL,
+		PollSchedule: qris.FixedSchedule(10 * time.Millisecond),
+		RandSource:   qristest.SeededRand(1),
+	})
+	if err != nil {
+		t.Fatalf("NewQRIS: %v", err)
+	}
+	t.Cleanup(func() { q.Close(context.Background()) })
+	return q, gw
+}
+
+// patternRouter is a minimal router in the style of chi and gin: it matches whole paths against
+// prefix+pattern, {name} or :name matching one segment. With setParams it passes the parameters
+// with WithPathParam, as a gin adapter does; otherwise handlers find them in the path, as with chi.
+// It only imitates those routers, so the library needs neither: the real chi and gin adapters are
+// tested in the examples/routers module.
+type patternRouter struct {
+	prefix    string
+	colon     bool
+	setParams bool
+	routes    []qrishttp.Route
+}
+
+func (pr *patternRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, rt := range pr.routes {
+		pattern := rt.Pattern
+		if pr.colon {
+			pattern = rt.ColonPattern()
+		}
+		params, ok := matchSegments(pr.prefix+pattern, r.URL.Path)
+		if !ok || rt.Method != r.Method {
+			continue
+		}
+		if pr.setParams {
+			for name, value := range params {
+				r = qrishttp.WithPathParam(r, name, value)
+			}
+		}
+		rt.Handler(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func matchSegments(pattern, path string) (map[string]string, bool) {
+	want := strings.Split(pattern, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range want {
+		switch {
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			params[seg[1:len(seg)-1]] = got[i]
+		case strings.HasPrefix(seg, ":"):
+			params[seg[1:]] = got[i]
+		case seg != got[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// serveMux mounts routes on a Go 1.21 http.ServeMux, which has neither methods nor parameters in its
+// patterns: each route is registered at its pattern up to the first parameter, and routes sharing
+// a registration are told apart by method and number of segments.
+func serveMux(prefix string, routes []qrishttp.Route) *http.ServeMux {
+	byPath := make(map[string][]qrishttp.Route)
+	for _, rt := range routes {
+		path := prefix + rt.Pattern
+		if i := strings.Index(path, "{"); i >= 0 {
+			path = path[:i]
+		}
+		byPath[path] = append(byPath[path], rt)
+	}
+	mux := http.NewServeMux()
+	for path, rts := range byPath {
+		rts := rts
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			for _, rt := range rts {
+				if rt.Method == r.Method && strings.Count(r.URL.Path, "/") == strings.Count(prefix+rt.Pattern, "/") {
+					rt.Handler(w, r)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		})
+	}
+	return mux
+}
+
+func TestRoutesMounting(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount func(q *qris.QRIS) http.Handler
+	}{
+		{
+			name: "ServeMux",
+			mount: func(q *qris.QRIS) http.Handler {
+				mux := http.NewServeMux()
+				mux.Handle("/qris/", http.StripPrefix("/qris", qrishttp.Handler(q)))
+				return mux
+			},
+		},
+		{
+			name: "ServeMux with Routes",
+			mount: func(q *qris.QRIS) http.Handler {
+				return serveMux("/qris", qrishttp.Routes(q))
+			},
+		},
+		{
+			name: "chi style",
+			mount: func(q *qris.QRIS) http.Handler {
+				return &patternRouter{prefix: "/qris", routes: qrishttp.Routes(q)}
+			},
+		},
+		{
+			name: "gin style",
+			mount: func(q *qris.QRIS) http.Handler {
+				return &patternRouter{prefix: "/qris", colon: true, setParams: true, routes: qrishttp.Routes(q)}
+			},
+		},
+		{
+			name: "gin style without parameters",
+			mount: func(q *qris.QRIS) http.Handler {
+				return &patternRouter{prefix: "/qris", colon: true, routes: qrishttp.Routes(q)}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, gw := newTestQRIS(t)
+			srv := httptest.NewServer(tt.mount(q))
+			t.Cleanup(srv.Close)
+
+			resp := do(t, http.MethodPost, srv.URL+"/qris/payments", `{"amount":25000,"transaction_id":"INV-1"}`)
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("POST /payments = %d, want 201", resp.StatusCode)
+			}
+			var created struct {
+				TransactionID string     `json:"transaction_id"`
+				Amount        qris.Money `json:"amount"`
+				Code          string     `json:"code"`
+			}
+			decode(t, resp, &created)
+			if created.TransactionID != "INV-1" || created.Code == "" {
+				t.Fatalf("created = %+v, want INV-1 with a code", created)
+			}
+
+			requests := []struct {
+				method, path string
+				status       int
+				contentType  string
+			}{
+				{http.MethodGet, "/qris/payments/INV-1", http.StatusOK, "application/json"},
+				{http.MethodGet, "/qris/payments/INV-1/qr.png", http.StatusOK, "image/png"},
+				{http.MethodGet, "/qris/pay/" + created.Code, http.StatusOK, "text/html"},
+				{http.MethodGet, "/qris/payments/INV-2", http.StatusNotFound, "application/json"},
+				{http.MethodGet, "/qris/qr.png?amount=1000&transaction_id=INV-3", http.StatusOK, "image/png"},
+			}
+			for _, req := range requests {
+				resp := do(t, req.method, srv.URL+req.path, "")
+				resp.Body.Close()
+				if resp.StatusCode != req.status {
+					t.Errorf("%s %s = %d, want %d", req.method, req.path, resp.StatusCode, req.status)
+				}
+				if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, req.contentType) {
+					t.Errorf("%s %s Content-Type = %q, want %s", req.method, req.path, ct, req.contentType)
+				}
+			}
+
+			gw.AddMutation(created.Amount.Value, time.Now())
+			var view qris.PaymentStatusView
+			deadline := time.Now().Add(5 * time.Second)
+			for view.Status != qris.StatusPaid && time.Now().Before(deadline) {
+				decode(t, do(t, http.MethodGet, srv.URL+"/qris/payments/INV-1", ""), &view)
+				time.Sleep(10 * time.Millisecond)
+			}
+			if view.Status != qris.StatusPaid || view.Amount.Value != created.Amount.Value {
+				t.Fatalf("status = %s %d, want PAID %d", view.Status, view.Amount.Value, created.Amount.Value)
+			}
+
+			resp = do(t, http.MethodPost, srv.URL+"/qris/payments", `{"amount":30000,"transaction_id":"INV-4"}`)
+			resp.Body.Close()
+			decode(t, do(t, http.MethodDelete, srv.URL+"/qris/payments/INV-4", ""), &view)
+			if view.Status != qris.StatusCancelled {
+				t.Fatalf("DELETE /payments/INV-4 status = %s, want CANCELLED", view.Status)
+			}
+		})
+	}
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	q, _ := newTestQRIS(t)
+	srv := httptest.NewServer(qrishttp.Handler(q))
+	t.Cleanup(srv.Close)
+
+	resp := do(t, http.MethodPut, srv.URL+"/payments/INV-1", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("PUT = %d, want 405", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodDelete) {
+		t.Fatalf("Allow = %q, want GET and DELETE", allow)
+	}
+}
+
+func TestColonPattern(t *testing.T) {
+	tests := []struct{ pattern, want string }{
+		{"/payments", "/payments"},
+		{"/payments/{id}", "/payments/:id"},
+		{"/payments/{id}/qr.png", "/payments/:id/qr.png"},
+		{"/pay/{code}", "/pay/:code"},
+	}
+	for _, tt := range tests {
+		if got := (qrishttp.Route{Pattern: tt.pattern}).ColonPattern(); got != tt.want {
+			t.Errorf("ColonPattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestPathParamFromContext(t *testing.T) {
+	q, _ := newTestQRIS(t)
+	if _, err := q.CreatePayment(context.Background(), 25000, qris.WithTransactionID("INV-9")); err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	var status qrishttp.Route
+	for _, rt := range qrishttp.Routes(q) {
+		if rt.Method == http.MethodGet && rt.Pattern == "/payments/{id}" {
+			status = rt
+		}
+	}
+
+	// A router that rewrote the path passes the parameter explicitly
+	tests := []struct {
+		name   string
+		param  string
+		status int
+	}{
+		{"from the path", "", http.StatusNotFound},
+		{"from the context", "INV-9", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/internal/lookup", nil)
+			if tt.param != "" {
+				r = qrishttp.WithPathParam(r, "id", tt.param)
+			}
+			rec := httptest.NewRecorder()
+			status.Handler(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func do(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", resp.Request.URL, err)
+	}
+}
@@ -0,0 +1,193 @@
+package qrishttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qrishttp"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+	"github.com/skip2/go-qrcode"
+)
+
+// stubService is a qrishttp.Service without a gateway: statuses come from a StubChecker and the
+// QR codes and mutations are fixed.
+type stubService struct {
+	*qristest.StubChecker
+
+	mu        sync.Mutex
+	generated []qris.QRISData
+	queries   []qris.MutationQuery
+	mutations []qris.Mutation
+	err       error
+}
+
+func (s *stubService) GenerateQRCode(data qris.QRISData) (*qrcode.QRCode, error) {
+	payload, err := s.BuildPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.New(payload, qrcode.Medium)
+}
+
+func (s *stubService) BuildPayload(data qris.QRISData) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generated = append(s.generated, data)
+	if data.Amount <= 0 {
+		return "", qris.ErrInvalidAmount
+	}
+	return testBaseQR, nil
+}
+
+func (s *stubService) GetMutations(ctx context.Context, query qris.MutationQuery) ([]qris.Mutation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+	return s.mutations, s.err
+}
+
+var _ qrishttp.Service = (*stubService)(nil)
+
+func TestRoutesStubService(t *testing.T) {
+	stub := &stubService{
+		StubChecker: qristest.NewStubChecker().
+			On("INV-1", qristest.Unpaid("INV-1", 25000), qristest.Paid("INV-1", 25000)).
+			On("INV-2", qristest.Failure(qris.ErrGatewayTimeout)).
+			On("INV-3", qristest.Failure(qris.ErrGatewayMaintenance)).
+			On("INV-4", qristest.Failure(errors.New("connection reset"))),
+		mutations: []qris.Mutation{
+			{Amount: 25000, Date: "2024-01-15 10:00", Time: time.Date(2024, 1, 15, 10, 0, 0, 0, qris.WIB), QRIS: "static", Type: "CR", IssuerRef: "REF1", BrandName: "DANA"},
+		},
+	}
+
+	var patterns []string
+	for _, rt := range qrishttp.Routes(stub) {
+		patterns = append(patterns, rt.Method+" "+rt.Pattern)
+	}
+	sort.Strings(patterns)
+	want := []string{"GET /mutations", "GET /payments/{id}", "GET /qr.png"}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Fatalf("routes = %v, want %v without session routes", patterns, want)
+	}
+
+	srv := httptest.NewServer(qrishttp.Handler(stub))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		contentType string
+		wantStatus  string // status of the payment, for 200 /payments answers
+		wantCode    string // error code, for error answers
+	}{
+		{name: "unpaid", method: http.MethodGet, path: "/payments/INV-1?amount=25000", status: http.StatusOK, contentType: "application/json", wantStatus: qris.StatusUnpaid},
+		{name: "paid", method: http.MethodGet, path: "/payments/INV-1?amount=25000", status: http.StatusOK, contentType: "application/json", wantStatus: qris.StatusPaid},
+		{name: "paid repeats", method: http.MethodGet, path: "/payments/INV-1?amount=25000", status: http.StatusOK, contentType: "application/json", wantStatus: qris.StatusPaid},
+		{name: "unscripted", method: http.MethodGet, path: "/payments/INV-9?amount=1000", status: http.StatusOK, contentType: "application/json", wantStatus: qris.StatusUnpaid},
+		{name: "timeout", method: http.MethodGet, path: "/payments/INV-2?amount=1000", status: http.StatusGatewayTimeout, contentType: "application/json"},
+		{name: "maintenance", method: http.MethodGet, path: "/payments/INV-3?amount=1000", status: http.StatusServiceUnavailable, contentType: "application/json"},
+		{name: "gateway error", method: http.MethodGet, path: "/payments/INV-4?amount=1000", status: http.StatusBadGateway, contentType: "application/json"},
+		{name: "without amount", method: http.MethodGet, path: "/payments/INV-1", status: http.StatusNotFound, contentType: "application/json", wantCode: "NOT_FOUND"},
+		{name: "bad amount", method: http.MethodGet, path: "/payments/INV-1?amount=abc", status: http.StatusBadRequest, contentType: "application/json", wantCode: "INVALID_REQUEST"},
+		{name: "qr.png", method: http.MethodGet, path: "/qr.png?amount=1000&transaction_id=INV-5", status: http.StatusOK, contentType: "image/png"},
+		{name: "qr.png invalid amount", method: http.MethodGet, path: "/qr.png?amount=0&transaction_id=INV-6", status: http.StatusBadRequest, contentType: "application/json"},
+		{name: "mutations", method: http.MethodGet, path: "/mutations?type=cr&per_page=10", status: http.StatusOK, contentType: "application/json"},
+		{name: "no create", method: http.MethodPost, path: "/payments", status: http.StatusNotFound, contentType: "application/json", wantCode: "ROUTE_NOT_FOUND"},
+		{name: "no cancel", method: http.MethodDelete, path: "/payments/INV-1", status: http.StatusMethodNotAllowed, contentType: "application/json", wantCode: "METHOD_NOT_ALLOWED"},
+		{name: "no pay page", method: http.MethodGet, path: "/pay/ABC", status: http.StatusNotFound, contentType: "application/json", wantCode: "ROUTE_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		resp := do(t, tt.method, srv.URL+tt.path, "")
+		if resp.StatusCode != tt.status {
+			resp.Body.Close()
+			t.Fatalf("%s: %s %s = %d, want %d", tt.name, tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("%s: Content-Type = %q, want %s", tt.name, ct, tt.contentType)
+		}
+		switch {
+		case tt.wantStatus != "":
+			var view qris.PaymentStatusView
+			decode(t, resp, &view)
+			if view.Status != tt.wantStatus {
+				t.Errorf("%s: status = %s, want %s", tt.name, view.Status, tt.wantStatus)
+			}
+		case tt.wantCode != "":
+			var body struct{ Code string }
+			decode(t, resp, &body)
+			if body.Code != tt.wantCode {
+				t.Errorf("%s: code = %q, want %q", tt.name, body.Code, tt.wantCode)
+			}
+		default:
+			resp.Body.Close()
+		}
+	}
+
+	// Every status request with an amount reached the stub once, in order, and nothing else did
+	var refs []string
+	for _, c := range stub.Calls() {
+		if c.Method != "CheckPaymentStatus" {
+			t.Errorf("call %+v, want CheckPaymentStatus", c)
+		}
+		refs = append(refs, c.Reference)
+	}
+	if want := []string{"INV-1", "INV-1", "INV-1", "INV-9", "INV-2", "INV-3", "INV-4"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("checked references = %v, want %v", refs, want)
+	}
+	if len(stub.generated) != 2 || stub.generated[0].Amount != 1000 || stub.generated[0].TransactionID != "INV-5" || stub.generated[1].TransactionID != "INV-6" {
+		t.Errorf("generated = %+v, want INV-5 then INV-6", stub.generated)
+	}
+	if len(stub.queries) != 1 || stub.queries[0].Type != "CR" || stub.queries[0].PerPage != 10 {
+		t.Errorf("mutation queries = %+v, want one CR query of 10", stub.queries)
+	}
+}
+
+func TestRoutesStubServiceMutations(t *testing.T) {
+	tests := []struct {
+		name   string
+		stub   *stubService
+		status int
+		want   int // number of mutations / jumlah mutasi
+	}{
+		{"list", &stubService{mutations: []qris.Mutation{{Amount: 1000, Type: "CR"}, {Amount: 2000, Type: "CR"}}}, http.StatusOK, 2},
+		{"empty", &stubService{}, http.StatusOK, 0},
+		{"timeout", &stubService{err: qris.ErrGatewayTimeout}, http.StatusGatewayTimeout, 0},
+		{"closed", &stubService{err: qris.ErrClosed}, http.StatusServiceUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.stub.StubChecker = qristest.NewStubChecker()
+			srv := httptest.NewServer(qrishttp.Handler(tt.stub))
+			t.Cleanup(srv.Close)
+
+			resp := do(t, http.MethodGet, srv.URL+"/mutations", "")
+			if resp.StatusCode != tt.status {
+				resp.Body.Close()
+				t.Fatalf("GET /mutations = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				resp.Body.Close()
+				return
+			}
+			var views []struct {
+				Amount qris.Money `json:"amount"`
+				Time   *time.Time `json:"time"`
+			}
+			decode(t, resp, &views)
+			if views == nil || len(views) != tt.want {
+				t.Fatalf("mutations = %+v, want a list of %d", views, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package qristest
+
+import (
+	"io"
+	"math/rand"
+)
+
+// SeededRand returns a deterministic random source for QRISConfig.RandSource: instances built with
+// the same seed create the same transaction IDs, amounts, payment codes and QR payloads.
+// SeededRand mengembalikan sumber acak deterministik untuk QRISConfig.RandSource: instance yang dibuat
+// dengan seed yang sama menghasilkan ID transaksi, nominal, kode pembayaran dan payload QR yang sama.
+//
+// The source is predictable; never use it outside tests.
+// Sumber ini mudah ditebak; jangan pernah memakainya di luar test.
+func SeededRand(seed int64) io.Reader {
+	return rand.New(rand.NewSource(seed))
+}
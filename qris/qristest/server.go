@@ -0,0 +1,310 @@
+// Package qristest provides a fake mutation gateway and a stub payment checker for testing code
+// built on the qris package.
+// Package qristest menyediakan gateway mutasi palsu dan payment checker tiruan untuk menguji kode
+// yang memakai paket qris.
+package qristest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dateLayout is the date layout used by the mutation API.
+// dateLayout adalah format tanggal yang dipakai API mutasi.
+const dateLayout = "2006-01-02 15:04:05"
+
+// wib is the timezone the gateway reports dates in.
+// wib adalah zona waktu tanggal yang dilaporkan gateway.
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// Mutation is a scripted mutation row served by the Server.
+// Mutation adalah baris mutasi yang diskenariokan dan dilayani oleh Server.
+type Mutation struct {
+	Amount    int64
+	At        time.Time
+	QRIS      string
+	Type      string
+	IssuerRef string
+	BrandName string
+	BuyerRef  string
+	Account   string // Username listing the mutation, "" for every account / Username yang melihat mutasi, "" untuk semua akun
+}
+
+// MutationOption customizes a mutation added with AddMutation.
+// MutationOption mengatur mutasi yang ditambahkan dengan AddMutation.
+type MutationOption func(*Mutation)
+
+// WithBrand sets the payer brand name.
+// WithBrand mengatur nama brand pembayar.
+func WithBrand(name string) MutationOption {
+	return func(m *Mutation) { m.BrandName = name }
+}
+
+// WithIssuerRef sets the issuer reference.
+// WithIssuerRef mengatur referensi issuer.
+func WithIssuerRef(ref string) MutationOption {
+	return func(m *Mutation) { m.IssuerRef = ref }
+}
+
+// WithBuyerRef sets the buyer reference.
+// WithBuyerRef mengatur referensi pembeli.
+func WithBuyerRef(ref string) MutationOption {
+	return func(m *Mutation) { m.BuyerRef = ref }
+}
+
+// WithType sets the mutation type (CR or DB).
+// WithType mengatur tipe mutasi (CR atau DB).
+func WithType(t string) MutationOption {
+	return func(m *Mutation) { m.Type = t }
+}
+
+// ForAccount lists the mutation only to requests of username, so one Server can stand for the
+// gateway accounts of several merchants.
+// ForAccount hanya menampilkan mutasi ke request dari username, sehingga satu Server dapat mewakili
+// akun gateway beberapa merchant.
+func ForAccount(username string) MutationOption {
+	return func(m *Mutation) { m.Account = username }
+}
+
+// WithQRISType sets the QRIS type (static or dynamic).
+// WithQRISType mengatur tipe QRIS (static atau dynamic).
+func WithQRISType(t string) MutationOption {
+	return func(m *Mutation) { m.QRIS = t }
+}
+
+// Server is a fake mutation gateway backed by httptest.Server.
+// Server adalah gateway mutasi palsu yang memakai httptest.Server.
+//
+// Point QRISConfig.GatewayURL at Server.URL. All methods are safe for concurrent use.
+// Arahkan QRISConfig.GatewayURL ke Server.URL. Semua method aman dipakai bersamaan.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	mutations  []Mutation
+	failLeft   int
+	failStatus int
+	limitLeft  int
+	retryAfter int
+	maintLeft  int
+	requests   int
+	nextRef    int
+}
+
+// NewServer starts a fake gateway. Call Close when done.
+// NewServer menjalankan gateway palsu. Panggil Close setelah selesai.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// AddMutation adds a credit mutation from a static QRIS at the given time.
+// AddMutation menambahkan mutasi kredit dari QRIS statis pada waktu tertentu.
+func (s *Server) AddMutation(amount int64, at time.Time, opts ...MutationOption) Mutation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRef++
+	m := Mutation{
+		Amount:    amount,
+		At:        at,
+		QRIS:      "static",
+		Type:      "CR",
+		IssuerRef: fmt.Sprintf("REF%08d", s.nextRef),
+		BrandName: "DANA",
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	s.mutations = append(s.mutations, m)
+	return m
+}
+
+// AddDynamicPayment adds a credit from a dynamic QRIS at the given time, as acquirers report a scan of
+// an amount-embedded QR code: qris is "dynamic" and buyer_reff echoes the reference label of tag 62.
+// AddDynamicPayment menambahkan kredit dari QRIS dinamis pada waktu tertentu, seperti acquirer melaporkan
+// scan QR code bernominal: qris bernilai "dynamic" dan buyer_reff mengembalikan reference label dari tag 62.
+func (s *Server) AddDynamicPayment(amount int64, at time.Time, reference string, opts ...MutationOption) Mutation {
+	return s.AddMutation(amount, at, append([]MutationOption{WithQRISType("dynamic"), WithBuyerRef(reference)}, opts...)...)
+}
+
+// FailNext makes the next n requests fail with the given HTTP status.
+// FailNext membuat n request berikutnya gagal dengan status HTTP tertentu.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failLeft = n
+	s.failStatus = status
+}
+
+// RateLimitNext makes the next n requests fail with 429 Too Many Requests and a Retry-After
+// header of retryAfter, rounded up to whole seconds.
+// RateLimitNext membuat n request berikutnya gagal dengan 429 Too Many Requests dan header
+// Retry-After sebesar retryAfter, dibulatkan ke atas menjadi detik penuh.
+func (s *Server) RateLimitNext(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limitLeft = n
+	s.retryAfter = int((retryAfter + time.Second - 1) / time.Second)
+}
+
+// MaintenanceNext makes the next n requests answer with an HTML maintenance page and status 200,
+// like the real gateway during maintenance.
+// MaintenanceNext membuat n request berikutnya dijawab dengan halaman HTML maintenance dan status 200,
+// seperti gateway asli saat maintenance.
+func (s *Server) MaintenanceNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maintLeft = n
+}
+
+// RequestCount returns the number of requests received so far.
+// RequestCount mengembalikan jumlah request yang sudah diterima.
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// Reset removes all mutations and pending failures and zeroes the request count.
+// Reset menghapus semua mutasi dan kegagalan yang tertunda serta mengosongkan jumlah request.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mutations = nil
+	s.failLeft = 0
+	s.limitLeft = 0
+	s.maintLeft = 0
+	s.requests = 0
+}
+
+// maintenancePage is served by MaintenanceNext.
+// maintenancePage dilayani oleh MaintenanceNext.
+const maintenancePage = `<!DOCTYPE html>
+<html><head><title>Maintenance</title></head>
+<body><h1>Sedang maintenance</h1><p>Server is under maintenance, please try again later.</p></body></html>
+`
+
+// handle serves the mutation endpoint, honoring the date, type, and paging parameters.
+// handle melayani endpoint mutasi, dengan memperhatikan parameter tanggal, tipe, dan halaman.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	if s.failLeft > 0 {
+		s.failLeft--
+		status := s.failStatus
+		s.mu.Unlock()
+		writeJSON(w, status, map[string]string{"status": "error", "message": http.StatusText(status)})
+		return
+	}
+	if s.limitLeft > 0 {
+		s.limitLeft--
+		w.Header().Set("Retry-After", strconv.Itoa(s.retryAfter))
+		s.mu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"status": "error", "message": http.StatusText(http.StatusTooManyRequests)})
+		return
+	}
+	if s.maintLeft > 0 {
+		s.maintLeft--
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, maintenancePage)
+		return
+	}
+	mutations := append([]Mutation(nil), s.mutations...)
+	s.mu.Unlock()
+
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"status": "error", "message": "method not allowed"})
+		return
+	}
+
+	var params map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"status": "error", "message": err.Error()})
+		return
+	}
+	if params["auth_token"] == "" || params["auth_username"] == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"status": "error", "message": "unauthorized"})
+		return
+	}
+
+	from, _ := time.ParseInLocation(dateLayout, params["date_from"], wib)
+	to, _ := time.ParseInLocation(dateLayout, params["date_to"], wib)
+	var rows []Mutation
+	for _, m := range mutations {
+		if m.Account != "" && m.Account != params["auth_username"] {
+			continue
+		}
+		if params["type"] != "" && m.Type != params["type"] {
+			continue
+		}
+		if !from.IsZero() && m.At.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !m.At.Before(to) {
+			continue
+		}
+		rows = append(rows, m)
+	}
+
+	// Newest first, like the real gateway
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].At.After(rows[j].At) })
+
+	page, _ := strconv.Atoi(params["page"])
+	perPage, _ := strconv.Atoi(params["per_page"])
+	totalPages := 1
+	if perPage > 0 {
+		if page < 1 {
+			page = 1
+		}
+		totalPages = (len(rows) + perPage - 1) / perPage
+		start := (page - 1) * perPage
+		if start > len(rows) {
+			start = len(rows)
+		}
+		end := start + perPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+		rows = rows[start:end]
+	}
+
+	data := make([]map[string]string, 0, len(rows))
+	for _, m := range rows {
+		data = append(data, map[string]string{
+			"amount":      strconv.FormatInt(m.Amount, 10),
+			"date":        m.At.In(wib).Format(dateLayout),
+			"qris":        m.QRIS,
+			"type":        m.Type,
+			"issuer_reff": m.IssuerRef,
+			"brand_name":  m.BrandName,
+			"buyer_reff":  m.BuyerRef,
+		})
+	}
+	// Status first, like the real gateway
+	writeJSON(w, http.StatusOK, struct {
+		Status     string              `json:"status"`
+		TotalPages int                 `json:"total_pages"`
+		Data       []map[string]string `json:"data"`
+	}{"success", totalPages, data})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
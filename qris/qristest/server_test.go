@@ -0,0 +1,202 @@
+package qristest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// post sends params to the server and decodes the JSON answer.
+func post(t *testing.T, s *Server, params map[string]string) (int, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(params)
+	resp, err := http.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	var out map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp.StatusCode, out
+}
+
+var auth = map[string]string{"auth_token": "token", "auth_username": "user"}
+
+func TestServerRows(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, wib)
+	s.AddMutation(10001, base)
+	s.AddMutation(10002, base.Add(2*time.Minute))
+	s.AddMutation(10003, base.Add(time.Minute), WithType("DB"))
+	s.AddDynamicPayment(10004, base.Add(3*time.Minute), "INV-1")
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		amounts []string
+	}{
+		{"all newest first", nil, []string{"10004", "10002", "10003", "10001"}},
+		{"credits only", map[string]string{"type": "CR"}, []string{"10004", "10002", "10001"}},
+		{"date range", map[string]string{"date_from": "2024-05-01 10:01:00", "date_to": "2024-05-01 10:03:00"}, []string{"10002", "10003"}},
+		{"first page", map[string]string{"page": "1", "per_page": "3"}, []string{"10004", "10002", "10003"}},
+		{"second page", map[string]string{"page": "2", "per_page": "3"}, []string{"10001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]string{}
+			for k, v := range auth {
+				params[k] = v
+			}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+			status, out := post(t, s, params)
+			if status != http.StatusOK || out["status"] != "success" {
+				t.Fatalf("got %d %v, want 200 success", status, out["status"])
+			}
+			data, _ := out["data"].([]interface{})
+			var got []string
+			for _, row := range data {
+				got = append(got, row.(map[string]interface{})["amount"].(string))
+			}
+			if len(got) != len(tt.amounts) {
+				t.Fatalf("amounts = %v, want %v", got, tt.amounts)
+			}
+			for i := range got {
+				if got[i] != tt.amounts[i] {
+					t.Fatalf("amounts = %v, want %v", got, tt.amounts)
+				}
+			}
+		})
+	}
+}
+
+func TestServerDynamicPayment(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddDynamicPayment(25000, time.Now(), "INV-9")
+	_, out := post(t, s, auth)
+	row := out["data"].([]interface{})[0].(map[string]interface{})
+	if row["qris"] != "dynamic" || row["buyer_reff"] != "INV-9" || row["type"] != "CR" {
+		t.Fatalf("row = %v", row)
+	}
+}
+
+func TestServerAccounts(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddMutation(10000, time.Now(), ForAccount("user"))
+	s.AddMutation(20000, time.Now(), ForAccount("other"))
+	s.AddMutation(30000, time.Now())
+	for username, want := range map[string][]string{"user": {"10000", "30000"}, "other": {"20000", "30000"}, "third": {"30000"}} {
+		_, out := post(t, s, map[string]string{"auth_token": "token", "auth_username": username})
+		var got []string
+		for _, row := range out["data"].([]interface{}) {
+			got = append(got, row.(map[string]interface{})["amount"].(string))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("amounts listed to %s = %v, want %v", username, got, want)
+		}
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		script func(*Server)
+		first  int
+	}{
+		{"fail next", func(s *Server) { s.FailNext(2, http.StatusBadGateway) }, http.StatusBadGateway},
+		{"rate limit", func(s *Server) { s.RateLimitNext(2, 1500*time.Millisecond) }, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+			tt.script(s)
+
+			for i := 0; i < 2; i++ {
+				if status, _ := post(t, s, auth); status != tt.first {
+					t.Fatalf("request %d: status %d, want %d", i, status, tt.first)
+				}
+			}
+			if status, _ := post(t, s, auth); status != http.StatusOK {
+				t.Fatalf("after failures: status %d, want 200", status)
+			}
+			if n := s.RequestCount(); n != 3 {
+				t.Fatalf("RequestCount = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestServerRetryAfter(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.RateLimitNext(1, 1500*time.Millisecond)
+	resp, err := http.Post(s.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2", got)
+	}
+}
+
+func TestServerMaintenance(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.MaintenanceNext(1)
+	resp, err := http.Post(s.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("got %d %q, want an HTML page with 200", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestServerUnauthorized(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	if status, _ := post(t, s, map[string]string{"auth_username": "user"}); status != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", status)
+	}
+}
+
+func TestServerReset(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddMutation(10001, time.Now())
+	s.FailNext(5, http.StatusInternalServerError)
+	post(t, s, auth)
+	s.Reset()
+
+	if n := s.RequestCount(); n != 0 {
+		t.Fatalf("RequestCount after Reset = %d, want 0", n)
+	}
+	status, out := post(t, s, auth)
+	if status != http.StatusOK {
+		t.Fatalf("status %d, want 200", status)
+	}
+	if data, _ := out["data"].([]interface{}); len(data) != 0 {
+		t.Fatalf("rows after Reset = %v, want none", data)
+	}
+}
@@ -0,0 +1,138 @@
+package qristest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// StubResponse is one scripted answer of a StubChecker: a status, or an error.
+// StubResponse adalah satu jawaban yang diskenariokan dari StubChecker: sebuah status, atau error.
+type StubResponse struct {
+	Status *qris.PaymentStatus
+	Err    error
+}
+
+// Paid returns a response reporting reference as PAID for amount.
+// Paid mengembalikan response yang melaporkan reference sebagai PAID untuk amount.
+func Paid(reference string, amount int64) StubResponse {
+	return StubResponse{Status: &qris.PaymentStatus{Status: qris.StatusPaid, Amount: amount, Reference: reference}}
+}
+
+// Unpaid returns a response reporting reference as UNPAID for amount.
+// Unpaid mengembalikan response yang melaporkan reference sebagai UNPAID untuk amount.
+func Unpaid(reference string, amount int64) StubResponse {
+	return StubResponse{Status: &qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: amount, Reference: reference}}
+}
+
+// Failure returns a response failing with err.
+// Failure mengembalikan response yang gagal dengan err.
+func Failure(err error) StubResponse {
+	return StubResponse{Err: err}
+}
+
+// StubCall is a call received by a StubChecker.
+// StubCall adalah panggilan yang diterima StubChecker.
+type StubCall struct {
+	Method    string // "CheckPaymentStatus" or "WaitForPayment" / "CheckPaymentStatus" atau "WaitForPayment"
+	Reference string
+	Amount    int64
+}
+
+// StubChecker is a qris.PaymentStatusChecker answering from a script instead of a gateway, for
+// testing code that takes a qris.PaymentStatusChecker. Every check of a reference takes the next
+// scripted response and the last one repeats; references without a script are UNPAID. All methods
+// are safe for concurrent use.
+// StubChecker adalah qris.PaymentStatusChecker yang menjawab dari skenario, bukan dari gateway, untuk
+// menguji kode yang menerima qris.PaymentStatusChecker. Setiap pengecekan sebuah reference mengambil
+// response berikutnya dari skenario dan response terakhir diulang; reference tanpa skenario bernilai
+// UNPAID. Semua method aman dipakai bersamaan.
+type StubChecker struct {
+	mu      sync.Mutex
+	scripts map[string][]StubResponse
+	calls   []StubCall
+}
+
+// NewStubChecker creates a StubChecker without scripts.
+// NewStubChecker membuat StubChecker tanpa skenario.
+func NewStubChecker() *StubChecker {
+	return &StubChecker{scripts: make(map[string][]StubResponse)}
+}
+
+// On appends responses to the script of reference.
+// On menambahkan responses ke skenario reference.
+func (s *StubChecker) On(reference string, responses ...StubResponse) *StubChecker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scripts[reference] = append(s.scripts[reference], responses...)
+	return s
+}
+
+// Calls returns the calls received so far, in order.
+// Calls mengembalikan panggilan yang sudah diterima, sesuai urutan.
+func (s *StubChecker) Calls() []StubCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]StubCall(nil), s.calls...)
+}
+
+// next records a call and returns the next response for reference.
+// next mencatat sebuah panggilan dan mengembalikan response berikutnya untuk reference.
+func (s *StubChecker) next(method, reference string, amount int64) (StubResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, StubCall{Method: method, Reference: reference, Amount: amount})
+	script := s.scripts[reference]
+	if len(script) == 0 {
+		return Unpaid(reference, amount), true
+	}
+	r := script[0]
+	last := len(script) == 1
+	if !last {
+		s.scripts[reference] = script[1:]
+	}
+	if r.Status != nil {
+		status := *r.Status
+		r.Status = &status
+	}
+	return r, last
+}
+
+// CheckPaymentStatus returns the next response for reference.
+// CheckPaymentStatus mengembalikan response berikutnya untuk reference.
+func (s *StubChecker) CheckPaymentStatus(reference string, amount int64) (*qris.PaymentStatus, error) {
+	return s.CheckPaymentStatusContext(context.Background(), reference, amount)
+}
+
+// CheckPaymentStatusContext returns the next response for reference, or ctx.Err() if ctx is done.
+// CheckPaymentStatusContext mengembalikan response berikutnya untuk reference, atau ctx.Err() jika ctx selesai.
+func (s *StubChecker) CheckPaymentStatusContext(ctx context.Context, reference string, amount int64) (*qris.PaymentStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, _ := s.next("CheckPaymentStatus", reference, amount)
+	return r.Status, r.Err
+}
+
+// WaitForPayment goes through the script of reference without delay and returns the first PAID
+// status. Like qris.QRIS.WaitForPayment it skips errors; once the script is used up without a
+// payment, it waits for ctx and returns ctx.Err(). The options are ignored.
+// WaitForPayment menjalankan skenario reference tanpa jeda dan mengembalikan status PAID pertama.
+// Seperti qris.QRIS.WaitForPayment, error dilewati; setelah skenario habis tanpa pembayaran, fungsi
+// ini menunggu ctx dan mengembalikan ctx.Err(). Opsi diabaikan.
+func (s *StubChecker) WaitForPayment(ctx context.Context, reference string, amount int64, opts ...qris.WaitOption) (*qris.PaymentStatus, error) {
+	for ctx.Err() == nil {
+		r, last := s.next("WaitForPayment", reference, amount)
+		if r.Err == nil && r.Status != nil && r.Status.Status == qris.StatusPaid {
+			return r.Status, nil
+		}
+		if last {
+			<-ctx.Done()
+		}
+	}
+	return nil, ctx.Err()
+}
@@ -0,0 +1,173 @@
+package qristest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+var _ qris.PaymentStatusChecker = (*StubChecker)(nil)
+
+func TestStubCheckerScript(t *testing.T) {
+	errGateway := errors.New("gateway down")
+	tests := []struct {
+		name   string
+		script []StubResponse
+		want   []string // status or error of each check / status atau error dari setiap pengecekan
+	}{
+		{"unscripted", nil, []string{qris.StatusUnpaid, qris.StatusUnpaid}},
+		{"one response repeats", []StubResponse{Paid("INV-1", 25000)}, []string{qris.StatusPaid, qris.StatusPaid, qris.StatusPaid}},
+		{"in order", []StubResponse{Unpaid("INV-1", 25000), Unpaid("INV-1", 25000), Paid("INV-1", 25000)}, []string{qris.StatusUnpaid, qris.StatusUnpaid, qris.StatusPaid, qris.StatusPaid}},
+		{"failure then paid", []StubResponse{Failure(errGateway), Paid("INV-1", 25000)}, []string{"gateway down", qris.StatusPaid, qris.StatusPaid}},
+		{"failure repeats", []StubResponse{Unpaid("INV-1", 25000), Failure(errGateway)}, []string{qris.StatusUnpaid, "gateway down", "gateway down"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStubChecker().On("INV-1", tt.script...)
+			for i, want := range tt.want {
+				status, err := s.CheckPaymentStatus("INV-1", 25000)
+				got := ""
+				if err != nil {
+					got = err.Error()
+				} else {
+					got = status.Status
+					if status.Reference != "INV-1" || status.Amount != 25000 {
+						t.Errorf("check %d: %+v, want INV-1 of 25000", i, status)
+					}
+					// Changing a returned status must not change the script
+					status.Status = "CHANGED"
+				}
+				if got != want {
+					t.Fatalf("check %d = %s, want %s", i, got, want)
+				}
+			}
+			if calls := s.Calls(); len(calls) != len(tt.want) {
+				t.Fatalf("%d calls, want %d", len(calls), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestStubCheckerOnAppends(t *testing.T) {
+	s := NewStubChecker().On("INV-1", Unpaid("INV-1", 1000))
+	s.On("INV-1", Paid("INV-1", 1000)).On("INV-2", Failure(qris.ErrGatewayTimeout))
+
+	if status, _ := s.CheckPaymentStatus("INV-1", 1000); status.Status != qris.StatusUnpaid {
+		t.Fatalf("first check = %s, want UNPAID", status.Status)
+	}
+	if status, _ := s.CheckPaymentStatus("INV-1", 1000); status.Status != qris.StatusPaid {
+		t.Fatalf("second check = %s, want PAID", status.Status)
+	}
+	if _, err := s.CheckPaymentStatusContext(context.Background(), "INV-2", 1000); !errors.Is(err, qris.ErrGatewayTimeout) {
+		t.Fatalf("INV-2 err = %v, want ErrGatewayTimeout", err)
+	}
+
+	want := []StubCall{
+		{"CheckPaymentStatus", "INV-1", 1000},
+		{"CheckPaymentStatus", "INV-1", 1000},
+		{"CheckPaymentStatus", "INV-2", 1000},
+	}
+	if got := s.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Calls = %+v, want %+v", got, want)
+	}
+}
+
+func TestStubCheckerCancelledContext(t *testing.T) {
+	s := NewStubChecker().On("INV-1", Paid("INV-1", 1000))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.CheckPaymentStatusContext(ctx, "INV-1", 1000); !errors.Is(err, context.Canceled) {
+		t.Fatalf("CheckPaymentStatusContext err = %v, want context.Canceled", err)
+	}
+	if _, err := s.WaitForPayment(ctx, "INV-1", 1000); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitForPayment err = %v, want context.Canceled", err)
+	}
+	if calls := s.Calls(); len(calls) != 0 {
+		t.Fatalf("Calls = %+v, want none after cancellation", calls)
+	}
+}
+
+func TestStubCheckerWaitForPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  []StubResponse
+		wantErr error
+		calls   int
+	}{
+		{"paid at once", []StubResponse{Paid("INV-1", 1000)}, nil, 1},
+		{"skips unpaid and failures", []StubResponse{Unpaid("INV-1", 1000), Failure(qris.ErrGatewayTimeout), Unpaid("INV-1", 1000), Paid("INV-1", 1000)}, nil, 4},
+		{"never paid", []StubResponse{Unpaid("INV-1", 1000), Unpaid("INV-1", 1000)}, context.DeadlineExceeded, 2},
+		{"failing", []StubResponse{Failure(qris.ErrGatewayTimeout)}, context.DeadlineExceeded, 1},
+		{"unscripted", nil, context.DeadlineExceeded, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStubChecker().On("INV-1", tt.script...)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			// The script runs without delay: an hour between checks would outlast ctx
+			status, err := s.WaitForPayment(ctx, "INV-1", 1000, qris.WithPollInterval(time.Hour))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || status != nil {
+					t.Fatalf("WaitForPayment = %v, %v, want %v", status, err, tt.wantErr)
+				}
+			} else {
+				if err != nil || status.Status != qris.StatusPaid {
+					t.Fatalf("WaitForPayment = %v, %v, want PAID", status, err)
+				}
+			}
+
+			calls := s.Calls()
+			if len(calls) != tt.calls {
+				t.Fatalf("%d calls, want %d: once per response, then waiting for ctx", len(calls), tt.calls)
+			}
+			for _, c := range calls {
+				if c.Method != "WaitForPayment" || c.Reference != "INV-1" || c.Amount != 1000 {
+					t.Fatalf("call %+v, want WaitForPayment of INV-1", c)
+				}
+			}
+		})
+	}
+}
+
+func TestStubCheckerConcurrent(t *testing.T) {
+	const n = 50
+	s := NewStubChecker()
+	for i := 0; i < n; i++ {
+		s.On("INV-1", Unpaid("INV-1", 1000))
+	}
+	s.On("INV-1", Paid("INV-1", 1000))
+
+	// Every scripted response is handed out exactly once before the last one repeats
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	for i := 0; i < 2*n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := s.CheckPaymentStatus("INV-1", 1000)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			counts[status.Status]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if counts[qris.StatusUnpaid] != n || counts[qris.StatusPaid] != n {
+		t.Fatalf("counts = %v, want %d UNPAID and %d PAID", counts, n, n)
+	}
+	if got := len(s.Calls()); got != 2*n {
+		t.Fatalf("%d calls, want %d", got, 2*n)
+	}
+}
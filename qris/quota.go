@@ -0,0 +1,189 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRateLimitWait is how long a gateway request may wait in total for Retry-After
+// delays when QRISConfig.MaxRateLimitWait is not set.
+// DefaultMaxRateLimitWait adalah total waktu maksimum sebuah request gateway menunggu jeda
+// Retry-After jika QRISConfig.MaxRateLimitWait tidak diatur.
+const DefaultMaxRateLimitWait = 30 * time.Second
+
+// ErrRateLimitedByGateway is returned when the gateway keeps answering 429 Too Many Requests
+// beyond MaxRateLimitWait, or without saying when to retry.
+// ErrRateLimitedByGateway dikembalikan jika gateway terus menjawab 429 Too Many Requests
+// melewati MaxRateLimitWait, atau tanpa menyebutkan kapan harus mencoba lagi.
+var ErrRateLimitedByGateway = errors.New("rate limited by gateway / dibatasi oleh gateway")
+
+// QuotaStatus is the request quota last reported by the gateway.
+// QuotaStatus adalah kuota request yang terakhir dilaporkan gateway.
+//
+// Fields the gateway did not send keep their zero value; Limit and Remaining are -1 when unknown.
+// Field yang tidak dikirim gateway bernilai nol; Limit dan Remaining bernilai -1 jika tidak diketahui.
+type QuotaStatus struct {
+	Limit      int       // Requests allowed per window / Request yang diizinkan per window
+	Remaining  int       // Requests left in the window / Sisa request dalam window
+	Reset      time.Time // When the window resets / Waktu window di-reset
+	RetryAfter time.Time // When a rate limited client may retry / Waktu klien yang dibatasi boleh mencoba lagi
+	UpdatedAt  time.Time // Time of the response, zero if none was seen / Waktu response, nol jika belum ada
+}
+
+// quotaTracker keeps the latest QuotaStatus of an instance.
+// quotaTracker menyimpan QuotaStatus terbaru dari sebuah instance.
+type quotaTracker struct {
+	mu     sync.Mutex
+	status QuotaStatus
+}
+
+// observe records the quota headers of resp, if any, and returns the updated status.
+// observe mencatat header kuota dari resp, jika ada, dan mengembalikan status terbaru.
+func (t *quotaTracker) observe(resp *http.Response, now time.Time) (QuotaStatus, bool) {
+	limit, hasLimit := headerInt(resp.Header, "X-RateLimit-Limit", "RateLimit-Limit")
+	remaining, hasRemaining := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	reset, hasReset := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset")
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !hasLimit && !hasRemaining && !hasReset && !hasRetryAfter {
+		return QuotaStatus{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := QuotaStatus{Limit: -1, Remaining: -1, UpdatedAt: now}
+	if hasLimit {
+		s.Limit = limit
+	}
+	if hasRemaining {
+		s.Remaining = remaining
+	}
+	if hasReset {
+		// Large values are Unix timestamps, small ones seconds from now
+		if reset > 1e9 {
+			s.Reset = time.Unix(int64(reset), 0)
+		} else {
+			s.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	if hasRetryAfter {
+		s.RetryAfter = now.Add(retryAfter)
+	}
+	t.status = s
+	return s, true
+}
+
+// headerInt returns the first of names present in h as a non-negative integer.
+// headerInt mengembalikan header pertama dari names yang ada di h sebagai bilangan bulat non-negatif.
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if v := strings.TrimSpace(h.Get(name)); v != "" {
+			n, err := strconv.Atoi(v)
+			return n, err == nil && n >= 0
+		}
+	}
+	return 0, false
+}
+
+// parseRetryAfter reads a Retry-After value in seconds or as an HTTP date.
+// parseRetryAfter membaca nilai Retry-After dalam detik atau sebagai tanggal HTTP.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// QuotaStatus returns the request quota last reported by the gateway. UpdatedAt is zero
+// until a response carrying rate limit headers has been received.
+// QuotaStatus mengembalikan kuota request yang terakhir dilaporkan gateway. UpdatedAt bernilai nol
+// sampai ada response yang membawa header rate limit.
+func (q *QRIS) QuotaStatus() QuotaStatus {
+	q.quota.mu.Lock()
+	defer q.quota.mu.Unlock()
+
+	return q.quota.status
+}
+
+// maxRateLimitWait returns the configured limit of Retry-After waits per request.
+// maxRateLimitWait mengembalikan batas waktu tunggu Retry-After per request yang dikonfigurasi.
+func (q *QRIS) maxRateLimitWait() time.Duration {
+	if q.config.MaxRateLimitWait > 0 {
+		return q.config.MaxRateLimitWait
+	}
+	return DefaultMaxRateLimitWait
+}
+
+// sendWithQuota sends req with send, records the quota headers of every response, and retries
+// after 429 responses as long as the Retry-After delays fit in MaxRateLimitWait. The last 429
+// response is returned when they do not.
+// sendWithQuota mengirim req dengan send, mencatat header kuota dari setiap response, dan mencoba
+// lagi setelah response 429 selama jeda Retry-After masih muat dalam MaxRateLimitWait. Response 429
+// terakhir dikembalikan jika tidak muat.
+func (q *QRIS) sendWithQuota(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	var waited time.Duration
+	for {
+		resp, err := send(req)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if status, ok := q.quota.observe(resp, now); ok && q.config.OnQuota != nil {
+			q.config.OnQuota(status)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+		if !ok || waited+delay > q.maxRateLimitWait() || req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		waited += delay
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+}
+
+// rateLimitError describes a final 429 response as ErrRateLimitedByGateway.
+// rateLimitError menggambarkan response 429 terakhir sebagai ErrRateLimitedByGateway.
+func rateLimitError(resp *http.Response) error {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		return fmt.Errorf("%w: retry after %s / coba lagi setelah %s", ErrRateLimitedByGateway, v, v)
+	}
+	return ErrRateLimitedByGateway
+}
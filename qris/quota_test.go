@@ -0,0 +1,162 @@
+package qris_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		limited  int
+		maxWait  time.Duration
+		wantErr  error
+		wantReqs int
+	}{
+		{name: "waits out Retry-After", limited: 1, maxWait: 5 * time.Second, wantReqs: 2},
+		{name: "beyond the max wait", limited: 3, maxWait: time.Second, wantErr: qris.ErrRateLimitedByGateway, wantReqs: 2},
+		{name: "no waiting allowed", limited: 1, maxWait: time.Millisecond, wantErr: qris.ErrRateLimitedByGateway, wantReqs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newGateway(t)
+			gw.AddMutation(15000, time.Now())
+			q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.MaxRateLimitWait = tt.maxWait })
+			gw.RateLimitNext(tt.limited, time.Second)
+
+			start := time.Now()
+			mutations, err := q.GetMutations(testContext(t, 10*time.Second), qris.MutationQuery{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetMutations = %v, want %v", err, tt.wantErr)
+				}
+				if qris.ErrorCode(err) != "GATEWAY_RATE_LIMITED" {
+					t.Errorf("ErrorCode = %q, want GATEWAY_RATE_LIMITED", qris.ErrorCode(err))
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("GetMutations: %v", err)
+				}
+				if len(mutations) != 1 {
+					t.Fatalf("got %d mutations, want 1", len(mutations))
+				}
+				if waited := time.Since(start); waited < time.Second {
+					t.Errorf("returned after %v, before Retry-After", waited)
+				}
+			}
+			if n := gw.RequestCount(); n != tt.wantReqs {
+				t.Errorf("gateway requests = %d, want %d", n, tt.wantReqs)
+			}
+		})
+	}
+}
+
+func TestRateLimitedWithoutRetryAfter(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		http.Error(w, `{"status":"error"}`, http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+
+	_, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{})
+	if !errors.Is(err, qris.ErrRateLimitedByGateway) {
+		t.Fatalf("GetMutations = %v, want %v", err, qris.ErrRateLimitedByGateway)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("gateway requests = %d, want 1", requests)
+	}
+}
+
+func TestQuotaStatus(t *testing.T) {
+	body := largeResponse(t, 1, 15000)
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		want      qris.QuotaStatus
+		wantReset func(updated time.Time) time.Time
+	}{
+		{
+			name:      "x-ratelimit with relative reset",
+			headers:   map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "12", "X-RateLimit-Reset": "30"},
+			want:      qris.QuotaStatus{Limit: 60, Remaining: 12},
+			wantReset: func(updated time.Time) time.Time { return updated.Add(30 * time.Second) },
+		},
+		{
+			name:      "ratelimit with unix reset",
+			headers:   map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": strconv.FormatInt(reset.Unix(), 10)},
+			want:      qris.QuotaStatus{Limit: -1, Remaining: 0},
+			wantReset: func(time.Time) time.Time { return reset },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(body)
+			}))
+			t.Cleanup(srv.Close)
+
+			var reported []qris.QuotaStatus
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.GatewayURL = srv.URL
+				c.OnQuota = func(s qris.QuotaStatus) { reported = append(reported, s) }
+			})
+			if got := q.QuotaStatus(); !got.UpdatedAt.IsZero() {
+				t.Fatalf("QuotaStatus before any request = %+v, want zero UpdatedAt", got)
+			}
+
+			before := time.Now()
+			if _, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{}); err != nil {
+				t.Fatal(err)
+			}
+			got := q.QuotaStatus()
+			if got.UpdatedAt.Before(before) {
+				t.Errorf("UpdatedAt = %v, want after %v", got.UpdatedAt, before)
+			}
+			tt.want.UpdatedAt = got.UpdatedAt
+			tt.want.Reset = tt.wantReset(got.UpdatedAt)
+			if !got.Reset.Equal(tt.want.Reset) || got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.RetryAfter.IsZero() {
+				t.Errorf("QuotaStatus = %+v, want %+v", got, tt.want)
+			}
+			if len(reported) != 1 || reported[0] != got {
+				t.Errorf("OnQuota reported %+v, want [%+v]", reported, got)
+			}
+		})
+	}
+}
+
+func TestQuotaStatusRetryAfter(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.MaxRateLimitWait = time.Millisecond })
+	gw.RateLimitNext(1, 2*time.Second)
+
+	before := time.Now()
+	if _, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{}); !errors.Is(err, qris.ErrRateLimitedByGateway) {
+		t.Fatalf("GetMutations = %v, want %v", err, qris.ErrRateLimitedByGateway)
+	}
+	got := q.QuotaStatus()
+	if d := got.RetryAfter.Sub(before); d < 2*time.Second || d > 3*time.Second {
+		t.Errorf("RetryAfter is %v after the request, want 2s", d)
+	}
+	if got.Limit != -1 || got.Remaining != -1 {
+		t.Errorf("Limit %d and Remaining %d, want -1 when unknown", got.Limit, got.Remaining)
+	}
+}
@@ -0,0 +1,52 @@
+package qris
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"io"
+	mathrand "math/rand"
+	"sync"
+)
+
+// randSource is the random source of an instance. Without QRISConfig.RandSource, IDs and payment
+// codes, which must not be guessable, come from crypto/rand and amount suffixes, which only need to
+// spread, from math/rand. With RandSource every value comes from it, so a seeded source makes
+// CreatePayment reproducible.
+// randSource adalah sumber acak dari sebuah instance. Tanpa QRISConfig.RandSource, ID dan kode
+// pembayaran, yang tidak boleh mudah ditebak, berasal dari crypto/rand dan suffix nominal, yang cukup
+// tersebar, dari math/rand. Dengan RandSource semua nilai berasal darinya, sehingga sumber dengan seed
+// membuat CreatePayment dapat direproduksi.
+type randSource struct {
+	mu sync.Mutex // RandSource need not be safe for concurrent use / RandSource tidak harus aman dipakai bersamaan
+	r  io.Reader
+}
+
+func newRandSource(r io.Reader) *randSource {
+	return &randSource{r: r}
+}
+
+// read fills b with unpredictable bytes.
+// read mengisi b dengan byte yang tidak dapat ditebak.
+func (s *randSource) read(b []byte) error {
+	if s.r == nil {
+		_, err := io.ReadFull(cryptorand.Reader, b)
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := io.ReadFull(s.r, b)
+	return err
+}
+
+// int63n returns a number in [0, n), n > 0, for values that need not be unpredictable.
+// int63n mengembalikan angka di [0, n), n > 0, untuk nilai yang tidak harus sulit ditebak.
+func (s *randSource) int63n(n int64) int64 {
+	if s.r == nil {
+		return mathrand.Int63n(n)
+	}
+	var b [8]byte
+	if err := s.read(b[:]); err != nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) % uint64(n))
+}
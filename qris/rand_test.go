@@ -0,0 +1,66 @@
+package qris_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+// paymentCycle runs CreatePayment → QR payload → match on a new instance reading rand and
+// returns everything the cycle produced that should only depend on rand.
+func paymentCycle(t *testing.T, rand io.Reader, paidAt time.Time) []string {
+	t.Helper()
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.RandSource = rand })
+	ctx := testContext(t, 5*time.Second)
+
+	var out []string
+	var sessions []*qris.PaymentSession
+	for i := 0; i < 5; i++ {
+		s, err := q.CreatePayment(ctx, 25000, qris.WithUniqueSuffix(999))
+		if err != nil {
+			t.Fatalf("CreatePayment: %v", err)
+		}
+		snap := s.Snapshot()
+		out = append(out, fmt.Sprintf("%s %d %s %s", snap.TransactionID, snap.Amount, snap.Code, snap.QRString))
+		sessions = append(sessions, s)
+	}
+
+	gw.AddMutation(sessions[2].Amount(), paidAt, qristest.WithIssuerRef("REF1"), qristest.WithBrand("DANA"))
+	status, err := sessions[2].Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	out = append(out, fmt.Sprintf("%s %d %s %s", status.Status, status.Amount, status.Reference, status.Date))
+	return out
+}
+
+func TestSeededRandReproducible(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      func() io.Reader
+		wantEqual bool
+	}{
+		{"same seed", func() io.Reader { return qristest.SeededRand(7) }, func() io.Reader { return qristest.SeededRand(7) }, true},
+		{"other seed", func() io.Reader { return qristest.SeededRand(7) }, func() io.Reader { return qristest.SeededRand(8) }, false},
+		{"crypto/rand", func() io.Reader { return nil }, func() io.Reader { return nil }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paidAt := time.Now().Add(time.Minute)
+			a, b := paymentCycle(t, tt.a(), paidAt), paymentCycle(t, tt.b(), paidAt)
+			for i := range a[:len(a)-1] {
+				if equal := a[i] == b[i]; equal != tt.wantEqual {
+					t.Fatalf("payment %d: %q vs %q, want equal = %v", i, a[i], b[i], tt.wantEqual)
+				}
+			}
+			if paid := a[len(a)-1]; tt.wantEqual && paid != b[len(b)-1] {
+				t.Fatalf("paid %q vs %q, want equal", paid, b[len(b)-1])
+			}
+		})
+	}
+}
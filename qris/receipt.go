@@ -0,0 +1,224 @@
+package qris
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// ReceiptWidth is the number of characters per line of the default text receipt.
+// ReceiptWidth adalah jumlah karakter per baris dari struk teks default.
+const ReceiptWidth = 32
+
+// Receipt formats of RenderReceipt.
+// Format struk dari RenderReceipt.
+const (
+	ReceiptText ReceiptFormat = "text" // Monospace text for chat code blocks / Teks monospace untuk blok kode chat
+	ReceiptHTML ReceiptFormat = "html" // HTML page / Halaman HTML
+	ReceiptPNG  ReceiptFormat = "png"  // The text receipt drawn as an image / Struk teks yang digambar sebagai gambar
+)
+
+// ReceiptFormat is an output format of RenderReceipt.
+// ReceiptFormat adalah format keluaran dari RenderReceipt.
+type ReceiptFormat string
+
+// ReceiptData is what a receipt shows, filled from a PaymentStatus with PaymentReceipt or from
+// an orderkuota purchase with orderkuota.PurchaseReceipt or TransactionReceipt. Empty fields are left out.
+// ReceiptData adalah isi struk, diisi dari PaymentStatus dengan PaymentReceipt atau dari
+// pembelian orderkuota dengan orderkuota.PurchaseReceipt atau TransactionReceipt. Field kosong tidak ditampilkan.
+type ReceiptData struct {
+	Title        string    // Heading, e.g. "Struk Pembayaran" / Judul, misalnya "Struk Pembayaran"
+	Merchant     string    // Merchant name / Nama merchant
+	Product      string    // Product name or code / Nama atau kode produk
+	Destination  string    // Phone or customer number / Nomor HP atau nomor pelanggan
+	SerialNumber string    // Serial number or token / Nomor seri atau token
+	Amount       int64     // Total in rupiah / Total dalam rupiah
+	Date         time.Time // Transaction time / Waktu transaksi
+	Reference    string    // Transaction reference / Referensi transaksi
+	Status       string    // Transaction status / Status transaksi
+	Method       string    // Payment method, e.g. "QRIS DANA" / Metode pembayaran, misalnya "QRIS DANA"
+}
+
+// PaymentReceipt returns the receipt of a paid status from merchant.
+// PaymentReceipt mengembalikan struk dari status yang sudah dibayar dari merchant.
+func PaymentReceipt(status *PaymentStatus, merchant string) ReceiptData {
+	method := "QRIS"
+	if status.BrandName != "" {
+		method += " " + status.BrandName
+	}
+	date := status.PaidAt
+	if date.IsZero() {
+		date = time.Now()
+	}
+	return ReceiptData{
+		Title:     "Struk Pembayaran",
+		Merchant:  merchant,
+		Amount:    status.Amount,
+		Date:      date,
+		Reference: status.Reference,
+		Status:    status.Status,
+		Method:    method,
+	}
+}
+
+//go:embed templates/receipt.txt
+var defaultReceiptText string
+
+//go:embed templates/receipt.html
+var defaultReceiptHTML string
+
+// ReceiptRenderer renders receipts with its templates, which receive a ReceiptData and the
+// functions idr (FormatIDR), date, center, row and rule (for ReceiptWidth-wide text). A value too
+// long for the line of its row label is right-aligned on the next line.
+// ReceiptRenderer me-render struk dengan template-nya, yang menerima ReceiptData dan fungsi
+// idr (FormatIDR), date, center, row dan rule (untuk teks selebar ReceiptWidth). Nilai yang terlalu
+// panjang untuk baris label row-nya dirata kanan di baris berikutnya.
+//
+// Text renders ReceiptText and the lines of ReceiptPNG; HTML renders ReceiptHTML.
+// Text me-render ReceiptText dan baris-baris ReceiptPNG; HTML me-render ReceiptHTML.
+type ReceiptRenderer struct {
+	Text *template.Template
+	HTML *htmltemplate.Template
+}
+
+// ReceiptFuncs returns the functions available to receipt templates, for parsing your own.
+// ReceiptFuncs mengembalikan fungsi yang tersedia untuk template struk, untuk mem-parse template sendiri.
+func ReceiptFuncs() map[string]any {
+	return map[string]any{
+		"idr": FormatIDR,
+		"date": func(t time.Time) string {
+			return t.Format("02/01/2006 15:04 MST")
+		},
+		"center": func(s string) string {
+			if pad := (ReceiptWidth - utf8.RuneCountInString(s)) / 2; pad > 0 {
+				return strings.Repeat(" ", pad) + s
+			}
+			return s
+		},
+		"row": func(label, value string) string {
+			line := fmt.Sprintf("%-8s: ", label)
+			n := utf8.RuneCountInString(value)
+			pad := ReceiptWidth - len(line) - n
+			if pad < 0 {
+				// Too long for the line of the label, such as a PLN token: right-align it below
+				line, pad = strings.TrimRight(line, " ")+"\n", ReceiptWidth-n
+			}
+			if pad > 0 {
+				line += strings.Repeat(" ", pad)
+			}
+			return line + value
+		},
+		"rule": func() string {
+			return strings.Repeat("-", ReceiptWidth)
+		},
+	}
+}
+
+// NewReceiptRenderer returns a ReceiptRenderer with the default templates; replace either field
+// to customize that format.
+// NewReceiptRenderer mengembalikan ReceiptRenderer dengan template default; ganti salah satu field
+// untuk menyesuaikan format tersebut.
+func NewReceiptRenderer() *ReceiptRenderer {
+	return &ReceiptRenderer{
+		Text: template.Must(template.New("receipt.txt").Funcs(ReceiptFuncs()).Parse(defaultReceiptText)),
+		HTML: htmltemplate.Must(htmltemplate.New("receipt.html").Funcs(ReceiptFuncs()).Parse(defaultReceiptHTML)),
+	}
+}
+
+// defaultReceipts renders RenderReceipt.
+// defaultReceipts me-render RenderReceipt.
+var defaultReceipts = NewReceiptRenderer()
+
+// RenderReceipt renders t in format with the default templates; see ReceiptRenderer to change them.
+// RenderReceipt me-render t dalam format dengan template default; lihat ReceiptRenderer untuk mengubahnya.
+func RenderReceipt(t ReceiptData, format ReceiptFormat) ([]byte, error) {
+	return defaultReceipts.Render(t, format)
+}
+
+// Render renders t in format.
+// Render me-render t dalam format.
+func (r *ReceiptRenderer) Render(t ReceiptData, format ReceiptFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case ReceiptText, ReceiptPNG:
+		if err := r.Text.Execute(&buf, t); err != nil {
+			return nil, fmt.Errorf("failed to render receipt / gagal me-render struk: %v", err)
+		}
+		if format == ReceiptText {
+			return buf.Bytes(), nil
+		}
+		return drawReceipt(buf.String())
+	case ReceiptHTML:
+		if err := r.HTML.Execute(&buf, t); err != nil {
+			return nil, fmt.Errorf("failed to render receipt / gagal me-render struk: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown receipt format %q / format struk %q tidak dikenal", format, format)
+}
+
+// Layout of receipt images: glyphs are drawn at receiptScale pixels per font dot.
+// Tata letak gambar struk: glyph digambar dengan receiptScale piksel per titik font.
+const (
+	receiptScale   = 2
+	receiptMargin  = 8 * receiptScale
+	receiptAdvance = 6 * receiptScale  // Glyph and one dot of spacing / Glyph dan satu titik jarak
+	receiptLeading = 10 * receiptScale // Line height / Tinggi baris
+)
+
+// drawReceipt draws text as a black-on-white PNG with font5x7. Characters outside printable
+// ASCII are drawn as '?'.
+// drawReceipt menggambar text sebagai PNG hitam di atas putih dengan font5x7. Karakter di luar
+// ASCII yang dapat dicetak digambar sebagai '?'.
+func drawReceipt(text string) ([]byte, error) {
+	var lines []string
+	sc := bufio.NewScanner(strings.NewReader(strings.TrimRight(text, "\n")))
+	columns := 0
+	for sc.Scan() {
+		line := sc.Text()
+		lines = append(lines, line)
+		if n := utf8.RuneCountInString(line); n > columns {
+			columns = n
+		}
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, 2*receiptMargin+columns*receiptAdvance, 2*receiptMargin+len(lines)*receiptLeading),
+		color.Palette{color.White, color.Black})
+	for row, line := range lines {
+		col := 0
+		for _, ch := range line {
+			if ch < 0x20 || ch > 0x7E {
+				ch = '?'
+			}
+			x0, y0 := receiptMargin+col*receiptAdvance, receiptMargin+row*receiptLeading
+			for x, bits := range font5x7[ch-0x20] {
+				for y := 0; y < 8; y++ {
+					if bits&(1<<y) == 0 {
+						continue
+					}
+					for dx := 0; dx < receiptScale; dx++ {
+						for dy := 0; dy < receiptScale; dy++ {
+							img.SetColorIndex(x0+x*receiptScale+dx, y0+y*receiptScale+dy, 1)
+						}
+					}
+				}
+			}
+			col++
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode receipt / gagal meng-encode struk: %v", err)
+	}
+	return buf.Bytes(), nil
+}
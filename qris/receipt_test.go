@@ -0,0 +1,92 @@
+package qris_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// testReceipts are a QRIS payment and a purchase with a serial number.
+var testReceipts = map[string]qris.ReceiptData{
+	"payment": qris.PaymentReceipt(&qris.PaymentStatus{
+		Status:    qris.StatusPaid,
+		Amount:    25123,
+		Reference: "REF2",
+		BrandName: "DANA",
+		PaidAt:    time.Date(2024, 5, 2, 3, 30, 0, 0, qris.WIB),
+	}, "AutoFTbot Store"),
+	"purchase": {
+		Title:        "Struk Pembelian",
+		Product:      "PLN20",
+		Destination:  "12345678901",
+		SerialNumber: "1234-5678-9012-3456-7890",
+		Amount:       20150,
+		Date:         time.Date(2024, 5, 2, 9, 15, 0, 0, qris.WIB),
+		Reference:    "T1",
+		Status:       "success",
+	},
+}
+
+func TestRenderReceipt(t *testing.T) {
+	for name, data := range testReceipts {
+		t.Run(name, func(t *testing.T) {
+			text, err := qris.RenderReceipt(data, qris.ReceiptText)
+			if err != nil {
+				t.Fatalf("RenderReceipt text: %v", err)
+			}
+			for _, line := range strings.Split(strings.TrimRight(string(text), "\n"), "\n") {
+				if len(line) > qris.ReceiptWidth {
+					t.Errorf("line %q is wider than %d", line, qris.ReceiptWidth)
+				}
+			}
+			checkGolden(t, "receipt/"+name+".txt", text)
+
+			html, err := qris.RenderReceipt(data, qris.ReceiptHTML)
+			if err != nil {
+				t.Fatalf("RenderReceipt html: %v", err)
+			}
+			checkGolden(t, "receipt/"+name+".html", html)
+
+			// The image draws the text receipt
+			b, err := qris.RenderReceipt(data, qris.ReceiptPNG)
+			if err != nil {
+				t.Fatalf("RenderReceipt png: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("decoding the receipt image: %v", err)
+			}
+			lines := strings.Count(strings.TrimRight(string(text), "\n"), "\n") + 1
+			if size := img.Bounds().Size(); size.X < qris.ReceiptWidth*10 || size.Y < lines*20 {
+				t.Fatalf("image %v too small for %d lines of %d characters", size, lines, qris.ReceiptWidth)
+			}
+		})
+	}
+
+	if _, err := qris.RenderReceipt(testReceipts["payment"], "pdf"); err == nil {
+		t.Fatal("RenderReceipt of an unknown format succeeded")
+	}
+}
+
+func TestReceiptRendererTemplate(t *testing.T) {
+	r := qris.NewReceiptRenderer()
+	r.Text = template.Must(template.New("short").Funcs(qris.ReceiptFuncs()).Parse(`{{.Reference}} {{idr .Amount}} {{date .Date}}`))
+
+	got, err := r.Render(testReceipts["payment"], qris.ReceiptText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "REF2 Rp 25.123 02/05/2024 03:30 WIB"; string(got) != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+
+	// The default templates are left alone
+	if text, err := qris.RenderReceipt(testReceipts["payment"], qris.ReceiptText); err != nil || !strings.Contains(string(text), "Terima kasih") {
+		t.Fatalf("RenderReceipt = %q, %v, want the default template", text, err)
+	}
+}
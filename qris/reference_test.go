@@ -0,0 +1,167 @@
+package qris_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+	"github.com/AutoFTbot/OrderKuota-go/qris/qristest"
+)
+
+func TestCheckPaymentStatusKnownInvoice(t *testing.T) {
+	gw := newGateway(t)
+	store, err := qris.NewJSONFileStore(filepath.Join(t.TempDir(), "invoices.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := testContext(t, 5*time.Second)
+	now := time.Now()
+
+	// OLDER and NEWER ask for the same amount; the only credit came between their creations
+	invoices := []qris.Invoice{
+		{TransactionID: "OLDER", BaseAmount: 25000, Amount: 25000, Status: qris.StatusUnpaid, CreatedAt: now.Add(-10 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{TransactionID: "NEWER", BaseAmount: 25000, Amount: 25000, Status: qris.StatusUnpaid, CreatedAt: now.Add(-5 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{
+			TransactionID: "DONE", BaseAmount: 30000, Amount: 30000, Status: qris.StatusPaid, CreatedAt: now.Add(-time.Hour),
+			Payment:  &qris.PaymentStatus{Status: qris.StatusPaid, Amount: 30000, Reference: "REF9", BrandName: "OVO"},
+			Metadata: map[string]string{"order": "9"},
+		},
+		{TransactionID: "LAPSED", BaseAmount: 40000, Amount: 40000, Status: qris.StatusExpired, CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	for _, inv := range invoices {
+		if err := store.Save(ctx, inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gw.AddMutation(25000, now.Add(-8*time.Minute), qristest.WithIssuerRef("REF1"), qristest.WithBrand("DANA"))
+
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) {
+		c.InvoiceStore = store
+		c.MatchWindow = 15 * time.Minute
+	})
+
+	tests := []struct {
+		name      string
+		reference string
+		amount    int64
+		want      qris.PaymentStatus
+		requests  int // gateway requests of the check / request gateway dari pengecekan
+	}{
+		{
+			name:      "paid after creation",
+			reference: "OLDER",
+			want:      qris.PaymentStatus{Status: qris.StatusPaid, Amount: 25000, Reference: "REF1", BrandName: "DANA"},
+			requests:  1,
+		},
+		{
+			name:      "amount argument ignored",
+			reference: "OLDER",
+			amount:    99999,
+			want:      qris.PaymentStatus{Status: qris.StatusPaid, Amount: 25000, Reference: "REF1", BrandName: "DANA"},
+			requests:  1,
+		},
+		{
+			name:      "same amount paid before creation",
+			reference: "NEWER",
+			amount:    25000,
+			want:      qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: 25000, Reference: "NEWER"},
+			requests:  1,
+		},
+		{
+			name:      "paid invoice",
+			reference: "DONE",
+			want:      qris.PaymentStatus{Status: qris.StatusPaid, Amount: 30000, Reference: "REF9", BrandName: "OVO", Metadata: map[string]string{"order": "9"}},
+		},
+		{
+			name:      "expired invoice",
+			reference: "LAPSED",
+			amount:    40000,
+			want:      qris.PaymentStatus{Status: qris.StatusExpired, Amount: 40000, Reference: "LAPSED"},
+		},
+		{
+			name:      "unknown reference",
+			reference: "OTHER",
+			amount:    25000,
+			want:      qris.PaymentStatus{Status: qris.StatusPaid, Amount: 25000, Reference: "REF1", BrandName: "DANA", Warning: qris.WarningAmountOnly},
+			requests:  1,
+		},
+		{
+			name:      "unknown reference, other amount",
+			reference: "OTHER",
+			amount:    26000,
+			want:      qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: 26000, Reference: "OTHER", Warning: qris.WarningAmountOnly},
+			requests:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := gw.RequestCount()
+			status, err := q.CheckPaymentStatusContext(ctx, tt.reference, tt.amount)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			got := *status
+			got.Date, got.PaidAt, got.Issuer, got.RawResponse = "", time.Time{}, "", nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("status = %+v, want %+v", got, tt.want)
+			}
+			if n := gw.RequestCount() - before; n != tt.requests {
+				t.Fatalf("%d gateway requests, want %d", n, tt.requests)
+			}
+		})
+	}
+
+	// A credit after both creations pays the newer invoice too
+	gw.AddMutation(25000, now, qristest.WithIssuerRef("REF2"))
+	status, err := q.CheckPaymentStatusContext(ctx, "NEWER", 0)
+	if err != nil || status.Status != qris.StatusPaid || status.Reference != "REF2" || status.Warning != "" {
+		t.Fatalf("NEWER after REF2 = %+v, %v, want PAID by REF2", status, err)
+	}
+}
+
+func TestCheckPaymentStatusKnownSession(t *testing.T) {
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw)
+	ctx := testContext(t, 5*time.Second)
+
+	// Two sessions of the same base amount get their own unique amounts
+	first, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("S1"), qris.WithUniqueSuffix(999))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("S2"), qris.WithUniqueSuffix(999))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.AddMutation(second.Amount(), time.Now(), qristest.WithIssuerRef("REF1"))
+
+	tests := []struct {
+		reference string
+		amount    int64
+		status    string
+		want      int64
+	}{
+		{"S1", 0, qris.StatusUnpaid, first.Amount()},
+		{"S1", second.Amount(), qris.StatusUnpaid, first.Amount()},
+		{"S2", 0, qris.StatusPaid, second.Amount()},
+		{"S2", 25000, qris.StatusPaid, second.Amount()},
+	}
+	for _, tt := range tests {
+		status, err := q.CheckPaymentStatusContext(ctx, tt.reference, tt.amount)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.reference, err)
+		}
+		if status.Status != tt.status || status.Amount != tt.want || status.Warning != "" {
+			t.Fatalf("%s with amount %d = %s %d %q, want %s %d without a warning",
+				tt.reference, tt.amount, status.Status, status.Amount, status.Warning, tt.status, tt.want)
+		}
+	}
+
+	// The amount-only check of the same credit cannot tell whose it is
+	status, err := q.CheckPaymentStatusContext(ctx, "S3", second.Amount())
+	if err != nil || status.Status != qris.StatusPaid || status.Warning != qris.WarningAmountOnly {
+		t.Fatalf("S3 = %+v, %v, want PAID with WarningAmountOnly", status, err)
+	}
+}
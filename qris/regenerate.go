@@ -0,0 +1,91 @@
+package qris
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Regenerate changes the requested amount of an unpaid session, e.g. when the merchant adjusts the
+// price during checkout. In one step it releases the reserved amount, reserves a unique amount for
+// newAmount as CreatePayment did, and rebuilds the QR code with the same transaction ID; the old
+// amount stops paying the session at once. It saves the invoice and emits EventAmountChanged.
+// Regenerate mengubah nominal yang diminta dari sesi yang belum dibayar, misalnya saat merchant
+// menyesuaikan harga di tengah checkout. Dalam satu langkah fungsi ini melepas nominal yang dipesan,
+// memesan nominal unik untuk newAmount seperti CreatePayment, dan menyusun ulang QR code dengan ID transaksi
+// yang sama; nominal lama langsung tidak lagi membayar sesi. Invoice disimpan dan EventAmountChanged dikirim.
+//
+// The amounts and the QR code change together under the session lock; Snapshot reads them together.
+// On error the session keeps its amount and QR code.
+// Nominal dan QR code berubah bersamaan di bawah lock sesi; Snapshot membacanya bersamaan.
+// Jika error, sesi tetap dengan nominal dan QR code-nya.
+func (s *PaymentSession) Regenerate(newAmount int64) (*qrcode.QRCode, error) {
+	q := s.q
+	if err := q.life.err(); err != nil {
+		return nil, err
+	}
+	if newAmount <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidAmount, newAmount)
+	}
+	if err := q.checkAmount(newAmount); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, fmt.Errorf("session %s is already paid / sesi %s sudah dibayar", s.TransactionID, s.TransactionID)
+	default:
+	}
+
+	var exact int64
+	if s.deterministic && s.uniqueSuffix > 0 {
+		exact = DeterministicAmount(newAmount, s.TransactionID, int(s.uniqueSuffix))
+	}
+	oldAmount := s.amount
+	unique, err := q.amounts.move(oldAmount, s.TransactionID, newAmount, s.uniqueSuffix, exact)
+	if err != nil {
+		return nil, err
+	}
+	// undo gives the old amount back to the session
+	undo := func() {
+		q.amounts.move(unique, s.TransactionID, 0, 0, oldAmount)
+	}
+
+	qrString, qrCode, err := q.encodePayment(unique, s.TransactionID, s.policy)
+	if err != nil {
+		undo()
+		return nil, err
+	}
+	if s.store != nil {
+		err := s.store.Save(context.Background(), Invoice{
+			TransactionID: s.TransactionID,
+			Merchant:      q.merchant,
+			BaseAmount:    newAmount,
+			Amount:        unique,
+			QRString:      qrString,
+			Status:        StatusUnpaid,
+			CreatedAt:     s.CreatedAt,
+			ExpiresAt:     s.ExpiresAt,
+			Code:          s.code,
+			Metadata:      s.Metadata,
+		})
+		if err != nil {
+			undo()
+			return nil, fmt.Errorf("failed to save invoice / gagal menyimpan invoice: %v", err)
+		}
+	}
+
+	s.baseAmount = newAmount
+	s.amount = unique
+	s.qrString = qrString
+	s.qrCode = qrCode
+	s.emitLocked(PaymentEvent{Type: EventAmountChanged, Detail: fmt.Sprintf("amount %s -> %s", FormatIDR(oldAmount), FormatIDR(unique))})
+	return qrCode, nil
+}
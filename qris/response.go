@@ -0,0 +1,190 @@
+package qris
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrGatewayMaintenance is wrapped by errors of gateway responses that are a maintenance page,
+	// such as an HTML page sent with status 200 or 503. Pollers wait at least MaintenanceBackoff
+	// before checking again.
+	// ErrGatewayMaintenance dibungkus oleh error response gateway yang berupa halaman maintenance,
+	// misalnya halaman HTML yang dikirim dengan status 200 atau 503. Poller menunggu minimal
+	// MaintenanceBackoff sebelum mengecek lagi.
+	ErrGatewayMaintenance = errors.New("gateway is under maintenance / gateway sedang maintenance")
+
+	// ErrInvalidResponse is wrapped by errors of gateway responses that are not JSON.
+	// ErrInvalidResponse dibungkus oleh error response gateway yang bukan JSON.
+	ErrInvalidResponse = errors.New("gateway response is not JSON / response gateway bukan JSON")
+
+	// ErrEmptyResponse is wrapped, together with ErrInvalidResponse, by errors of gateway responses without a body.
+	// ErrEmptyResponse dibungkus, bersama ErrInvalidResponse, oleh error response gateway tanpa body.
+	ErrEmptyResponse = errors.New("gateway response is empty / response gateway kosong")
+
+	// ErrResponseTooLarge is wrapped by errors of gateway responses whose body is longer than
+	// QRISConfig.MaxResponseSize. Reading stops at the limit, so such a body is never held in memory.
+	// ErrResponseTooLarge dibungkus oleh error response gateway yang body-nya lebih panjang dari
+	// QRISConfig.MaxResponseSize. Pembacaan berhenti pada batas tersebut, sehingga body seperti itu
+	// tidak pernah disimpan di memori.
+	ErrResponseTooLarge = errors.New("gateway response is too large / response gateway terlalu besar")
+)
+
+// DefaultMaxResponseSize is the default limit of QRISConfig.MaxResponseSize, 5 MB.
+// DefaultMaxResponseSize adalah batas default QRISConfig.MaxResponseSize, 5 MB.
+const DefaultMaxResponseSize = 5 << 20
+
+// MaintenanceBackoff is the shortest delay before a poller checks again after ErrGatewayMaintenance,
+// whatever its PollSchedule says.
+// MaintenanceBackoff adalah jeda terpendek sebelum poller mengecek lagi setelah ErrGatewayMaintenance,
+// apa pun isi PollSchedule-nya.
+const MaintenanceBackoff = 30 * time.Second
+
+// responseSnippetLen is how many characters of an unexpected body are quoted in errors.
+// responseSnippetLen adalah jumlah karakter body tak terduga yang dikutip dalam error.
+const responseSnippetLen = 200
+
+// maintenanceMarkers are lower-case phrases of maintenance pages.
+// maintenanceMarkers adalah frasa huruf kecil dari halaman maintenance.
+var maintenanceMarkers = []string{"maintenance", "pemeliharaan", "perbaikan", "under construction", "temporarily unavailable"}
+
+// checkJSONBody returns a reader of resp.Body after making sure the body starts like JSON. Empty
+// bodies fail with ErrEmptyResponse, maintenance pages with ErrGatewayMaintenance, and other
+// non-JSON bodies with ErrInvalidResponse; the errors quote the start of the body with secrets
+// removed. The Content-Type is only used to classify a body that is not JSON, since some gateways
+// label JSON as text/html.
+// checkJSONBody mengembalikan reader dari resp.Body setelah memastikan body diawali seperti JSON. Body
+// kosong gagal dengan ErrEmptyResponse, halaman maintenance dengan ErrGatewayMaintenance, dan body non-JSON
+// lain dengan ErrInvalidResponse; error-nya mengutip awal body tanpa rahasia. Content-Type hanya dipakai
+// untuk mengklasifikasikan body yang bukan JSON, karena beberapa gateway melabeli JSON sebagai text/html.
+func checkJSONBody(resp *http.Response, secrets ...string) (io.Reader, error) {
+	br := bufio.NewReader(resp.Body)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("%w: %w", errReadResponse, err)
+	}
+
+	start := bytes.TrimLeftFunc(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), unicode.IsSpace)
+	if len(start) == 0 {
+		if len(head) == 512 {
+			// Only whitespace so far; let the decoder read the rest
+			return br, nil
+		}
+		return nil, fmt.Errorf("%w: %w: status %d", ErrInvalidResponse, ErrEmptyResponse, resp.StatusCode)
+	}
+	if start[0] == '{' || start[0] == '[' {
+		return br, nil
+	}
+
+	snippet := sanitizeSnippet(head, secrets)
+	if isMaintenancePage(resp, head) {
+		return nil, fmt.Errorf("%w: status %d: %q", ErrGatewayMaintenance, resp.StatusCode, snippet)
+	}
+	return nil, fmt.Errorf("%w: status %d, content type %q: %q", ErrInvalidResponse, resp.StatusCode, resp.Header.Get("Content-Type"), snippet)
+}
+
+// isMaintenancePage reports whether a non-JSON body is a maintenance page: a 503 response, or an
+// HTML or plain-text page mentioning maintenance.
+// isMaintenancePage melaporkan apakah body non-JSON adalah halaman maintenance: response 503, atau
+// halaman HTML atau teks biasa yang menyebut maintenance.
+func isMaintenancePage(resp *http.Response, head []byte) bool {
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "text/plain" {
+		return false
+	}
+	text := strings.ToLower(string(head))
+	for _, marker := range maintenanceMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeSnippet returns the first responseSnippetLen characters of body as a single line of
+// printable text, with every secret replaced by "***".
+// sanitizeSnippet mengembalikan responseSnippetLen karakter pertama dari body sebagai satu baris
+// teks yang dapat dicetak, dengan setiap rahasia diganti "***".
+func sanitizeSnippet(body []byte, secrets []string) string {
+	s := string(body)
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, "***")
+		}
+	}
+
+	var b strings.Builder
+	n := 0
+	space := false
+	for _, r := range s {
+		if n == responseSnippetLen {
+			break
+		}
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			n++
+			space = false
+			if n == responseSnippetLen {
+				break
+			}
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return b.String()
+}
+
+// limitedBody reads a response body up to limit bytes, failing with ErrResponseTooLarge beyond it,
+// and counts the bytes read.
+// limitedBody membaca body response sampai limit byte, gagal dengan ErrResponseTooLarge setelahnya,
+// dan menghitung byte yang dibaca.
+type limitedBody struct {
+	r     io.ReadCloser
+	limit int64
+	n     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n > b.limit {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, b.limit)
+	}
+	// Read one byte past the limit to tell a body of exactly limit bytes from a longer one
+	if rest := b.limit + 1 - b.n; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if b.n > b.limit {
+		return n - int(b.n-b.limit), fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, b.limit)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.r.Close()
+}
+
+// maxResponseSize returns the configured body limit.
+// maxResponseSize mengembalikan batas body yang dikonfigurasi.
+func (q *QRIS) maxResponseSize() int64 {
+	if q.config.MaxResponseSize > 0 {
+		return q.config.MaxResponseSize
+	}
+	return DefaultMaxResponseSize
+}
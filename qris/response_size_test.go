@@ -0,0 +1,128 @@
+package qris_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// endlessGateway serves a mutation response that never ends, like a misbehaving proxy.
+func endlessGateway(tb testing.TB) *httptest.Server {
+	tb.Helper()
+	row := []byte(`{"amount":"1000","date":"2024-01-15 10:00:00","qris":"static","type":"CR","issuer_reff":"REF","brand_name":"DANA","buyer_reff":""},`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","total_pages":1,"data":[`))
+		for r.Context().Err() == nil {
+			if _, err := w.Write(row); err != nil {
+				return
+			}
+		}
+	}))
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+// sizeRecorder collects the sizes reported to QRISConfig.OnResponseSize.
+type sizeRecorder struct {
+	mu    sync.Mutex
+	sizes []int64
+}
+
+func (r *sizeRecorder) record(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sizes = append(r.sizes, n)
+}
+
+func (r *sizeRecorder) get() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.sizes...)
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	body := largeResponse(t, 200, 25000)
+	size := int64(len(body))
+	tests := []struct {
+		name    string
+		endless bool
+		limit   int64
+		wantErr bool
+		want    int64 // size reported to OnResponseSize / ukuran yang dilaporkan ke OnResponseSize
+	}{
+		{name: "default limit", limit: 0, want: size},
+		{name: "under the limit", limit: size + 1, want: size},
+		{name: "exactly the limit", limit: size, want: size},
+		{name: "one byte over", limit: size - 1, wantErr: true, want: size},
+		{name: "far over", limit: 1024, wantErr: true, want: 1025},
+		{name: "over before the first row", limit: 100, wantErr: true, want: 101},
+		{name: "endless", endless: true, limit: 1 << 20, wantErr: true, want: 1<<20 + 1},
+		{name: "endless with the default limit", endless: true, wantErr: true, want: qris.DefaultMaxResponseSize + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			if tt.endless {
+				srv = endlessGateway(t)
+			} else {
+				srv = staticGateway(t, body)
+			}
+			var sizes sizeRecorder
+			q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.GatewayURL = srv.URL
+				c.MaxResponseSize = tt.limit
+				c.OnResponseSize = sizes.record
+			})
+
+			// Decoding DefaultMaxResponseSize of rows takes seconds under the race detector
+			mutations, err := q.GetMutations(testContext(t, time.Minute), qris.MutationQuery{})
+			if tt.wantErr {
+				if !errors.Is(err, qris.ErrResponseTooLarge) {
+					t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+				}
+				if code := qris.ErrorCode(err); code != "GATEWAY_RESPONSE_TOO_LARGE" {
+					t.Fatalf("ErrorCode = %q, want GATEWAY_RESPONSE_TOO_LARGE", code)
+				}
+				if mutations != nil {
+					t.Fatalf("%d mutations of a truncated body, want none", len(mutations))
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("GetMutations: %v", err)
+				}
+				if len(mutations) != 200 {
+					t.Fatalf("%d mutations, want 200", len(mutations))
+				}
+			}
+
+			// Reading stops one byte past the limit, whatever the body holds
+			if got := sizes.get(); len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("OnResponseSize got %v, want [%d]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxResponseSizeCheckPaymentStatus(t *testing.T) {
+	srv := endlessGateway(t)
+	var sizes sizeRecorder
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.GatewayURL = srv.URL
+		c.MaxResponseSize = 64 << 10
+		c.OnResponseSize = sizes.record
+	})
+
+	status, err := q.CheckPaymentStatusContext(testContext(t, 10*time.Second), "INV-1", 1000)
+	if !errors.Is(err, qris.ErrResponseTooLarge) || status != nil {
+		t.Fatalf("CheckPaymentStatus = %v, %v, want ErrResponseTooLarge", status, err)
+	}
+	if got := sizes.get(); len(got) != 1 || got[0] != 64<<10+1 {
+		t.Fatalf("OnResponseSize got %v, want [%d]", got, 64<<10+1)
+	}
+}
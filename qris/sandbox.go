@@ -0,0 +1,104 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SandboxMarker is written to tag 62 (purpose of transaction) of every QR generated in sandbox mode,
+// before QRISData.Note.
+// SandboxMarker ditulis ke tag 62 (tujuan transaksi) pada setiap QR yang di-generate dalam mode sandbox,
+// sebelum QRISData.Note.
+const SandboxMarker = "SANDBOX"
+
+// ErrNotSandbox is returned by the Simulate methods when Sandbox is not enabled.
+// ErrNotSandbox dikembalikan oleh method Simulate jika Sandbox tidak aktif.
+var ErrNotSandbox = errors.New("sandbox mode is not enabled / mode sandbox tidak aktif")
+
+// sandbox is an in-process mutation history used instead of the gateway in sandbox mode.
+// sandbox adalah riwayat mutasi di dalam proses yang dipakai menggantikan gateway dalam mode sandbox.
+type sandbox struct {
+	mu        sync.Mutex
+	mutations []Mutation
+}
+
+func (sb *sandbox) add(m Mutation) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	sb.mutations = append(sb.mutations, m)
+}
+
+// eachMutation hands every simulated mutation matching the query to fn, newest first.
+// eachMutation meneruskan setiap mutasi simulasi yang cocok dengan query ke fn, dari yang terbaru.
+func (sb *sandbox) eachMutation(query MutationQuery, fn func(Mutation) error) (mutationPage, error) {
+	sb.mu.Lock()
+	mutations := append([]Mutation(nil), sb.mutations...)
+	sb.mu.Unlock()
+
+	page := mutationPage{rows: len(mutations), totalPages: 1}
+	for i := len(mutations) - 1; i >= 0; i-- {
+		if !query.matches(mutations[i]) {
+			continue
+		}
+		if err := fn(mutations[i]); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// SimulatePayment records a simulated credit of amount, as if a customer paid reference.
+// SimulatePayment mencatat kredit simulasi sebesar amount, seolah-olah customer membayar reference.
+//
+// Paying a different amount than the invoice simulates a partial or wrong payment, which stays UNPAID.
+// Membayar nominal yang berbeda dari invoice menyimulasikan pembayaran sebagian atau salah, yang tetap UNPAID.
+func (q *QRIS) SimulatePayment(reference string, amount int64) error {
+	if !q.config.Sandbox {
+		return ErrNotSandbox
+	}
+	if reference == "" || amount <= 0 {
+		return fmt.Errorf("reference and amount must be filled correctly / reference dan amount harus diisi dengan benar")
+	}
+
+	now := time.Now().In(q.location())
+	q.sandbox.add(Mutation{
+		Amount:    amount,
+		Date:      now.Format(mutationDateLayout),
+		Time:      now.Truncate(time.Second),
+		QRIS:      q.sandboxQRISType(),
+		Type:      "CR",
+		IssuerRef: fmt.Sprintf("%s-%d", SandboxMarker, now.UnixNano()),
+		BrandName: SandboxMarker,
+		Issuer:    NormalizeIssuer(SandboxMarker),
+		BuyerRef:  reference,
+	})
+	return nil
+}
+
+// sandboxQRISType returns the QRIS type of simulated credits: the first configured type, or static.
+// sandboxQRISType mengembalikan tipe QRIS kredit simulasi: tipe pertama yang dikonfigurasi, atau statis.
+func (q *QRIS) sandboxQRISType() string {
+	if len(q.config.QRISTypes) > 0 && !strings.EqualFold(q.config.QRISTypes[0], QRISTypeAny) {
+		return strings.ToLower(q.config.QRISTypes[0])
+	}
+	return QRISTypeStatic
+}
+
+// SimulateExpiry immediately expires the pending PaymentSession with the given transaction ID.
+// SimulateExpiry langsung membuat PaymentSession dengan ID transaksi tersebut kedaluwarsa.
+func (q *QRIS) SimulateExpiry(transactionID string) error {
+	if !q.config.Sandbox {
+		return ErrNotSandbox
+	}
+
+	s, ok := q.sessions.get(transactionID)
+	if !ok {
+		return fmt.Errorf("no pending session %s / tidak ada sesi %s yang menunggu", transactionID, transactionID)
+	}
+	s.finish(EventExpired, s.unpaidStatus(StatusExpired), ErrPaymentExpired)
+	return nil
+}
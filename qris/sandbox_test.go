@@ -0,0 +1,146 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// newSandbox returns a sandbox instance, closed when the test ends.
+func newSandbox(t *testing.T) *qris.QRIS {
+	t.Helper()
+	q, err := qris.NewQRIS(qris.QRISConfig{
+		BaseQrString: testBaseQR,
+		Sandbox:      true,
+		PollSchedule: qris.FixedSchedule(testPoll),
+	})
+	if err != nil {
+		t.Fatalf("NewQRIS: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		q.Close(ctx)
+	})
+	return q
+}
+
+func TestSandboxPayments(t *testing.T) {
+	tests := []struct {
+		name   string
+		pay    func(amount int64) int64
+		status string
+	}{
+		{"exact amount", func(amount int64) int64 { return amount }, qris.StatusPaid},
+		{"partial payment", func(amount int64) int64 { return amount - 1000 }, qris.StatusUnpaid},
+		{"wrong amount", func(amount int64) int64 { return amount + 1 }, qris.StatusUnpaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSandbox(t)
+			ctx := testContext(t, 5*time.Second)
+			s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+			if err := q.SimulatePayment("INV-1", tt.pay(s.Amount())); err != nil {
+				t.Fatalf("SimulatePayment: %v", err)
+			}
+			status, err := q.CheckPaymentStatusContext(ctx, "INV-1", 0)
+			if err != nil {
+				t.Fatalf("CheckPaymentStatus: %v", err)
+			}
+			if status.Status != tt.status {
+				t.Fatalf("Status = %s, want %s", status.Status, tt.status)
+			}
+			if tt.status == qris.StatusPaid && status.BrandName != qris.SandboxMarker {
+				t.Fatalf("BrandName = %q, want %q", status.BrandName, qris.SandboxMarker)
+			}
+		})
+	}
+}
+
+func TestSandboxWait(t *testing.T) {
+	q := newSandbox(t)
+	ctx := testContext(t, 5*time.Second)
+	s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+
+	go func() {
+		time.Sleep(3 * testPoll)
+		q.SimulatePayment("INV-1", s.Amount())
+	}()
+	status, err := s.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if status.Status != qris.StatusPaid || status.Amount != s.Amount() {
+		t.Fatalf("status = %+v", status)
+	}
+}
+
+func TestSandboxExpiry(t *testing.T) {
+	q := newSandbox(t)
+	ctx := testContext(t, 5*time.Second)
+	s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-1"))
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+
+	if err := q.SimulateExpiry("INV-1"); err != nil {
+		t.Fatalf("SimulateExpiry: %v", err)
+	}
+	if _, err := s.Wait(ctx); !errors.Is(err, qris.ErrPaymentExpired) {
+		t.Fatalf("Wait err = %v, want ErrPaymentExpired", err)
+	}
+	if err := q.SimulateExpiry("INV-2"); err == nil {
+		t.Fatal("SimulateExpiry of an unknown session succeeded")
+	}
+}
+
+func TestSandboxMarker(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		want string
+	}{
+		{"no note", "", qris.SandboxMarker},
+		{"note", "Order #1234", qris.SandboxMarker + " Order #1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSandbox(t)
+			payload, err := q.PreviewPayload(qris.QRISData{Amount: 25000, TransactionID: "INV-1", Note: tt.note})
+			if err != nil {
+				t.Fatalf("PreviewPayload: %v", err)
+			}
+			if got := payload.Note(); got != tt.want {
+				t.Fatalf("Note = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulateOutsideSandbox(t *testing.T) {
+	q := newTestQRIS(t, newGateway(t))
+
+	if err := q.SimulatePayment("INV-1", 25000); !errors.Is(err, qris.ErrNotSandbox) {
+		t.Fatalf("SimulatePayment err = %v, want ErrNotSandbox", err)
+	}
+	if err := q.SimulateExpiry("INV-1"); !errors.Is(err, qris.ErrNotSandbox) {
+		t.Fatalf("SimulateExpiry err = %v, want ErrNotSandbox", err)
+	}
+	payload, err := q.PreviewPayload(qris.QRISData{Amount: 25000, TransactionID: "INV-1", Note: "Order"})
+	if err != nil {
+		t.Fatalf("PreviewPayload: %v", err)
+	}
+	if strings.Contains(payload.Note(), qris.SandboxMarker) {
+		t.Fatalf("production Note %q carries the sandbox marker", payload.Note())
+	}
+}
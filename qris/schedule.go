@@ -0,0 +1,90 @@
+package qris
+
+import (
+	"time"
+)
+
+// PollSchedule decides how long a poller waits before its next status check.
+// PollSchedule menentukan berapa lama poller menunggu sebelum pengecekan status berikutnya.
+//
+// NextDelay is called after every check with the number of checks done so far (starting at 1)
+// and the time elapsed since polling started. Pollers of one instance share their gateway
+// requests, so the request rate follows the fastest active schedule rather than their sum.
+// NextDelay dipanggil setelah setiap pengecekan dengan jumlah pengecekan sejauh ini (mulai dari 1)
+// dan waktu yang berlalu sejak polling dimulai. Poller dalam satu instance berbagi request gateway,
+// sehingga laju request mengikuti jadwal aktif yang paling cepat, bukan jumlah semuanya.
+type PollSchedule interface {
+	NextDelay(attempt int, elapsed time.Duration) time.Duration
+}
+
+// PollScheduleFunc adapts a function to a PollSchedule.
+// PollScheduleFunc mengadaptasi sebuah fungsi menjadi PollSchedule.
+type PollScheduleFunc func(attempt int, elapsed time.Duration) time.Duration
+
+// NextDelay calls f(attempt, elapsed).
+// NextDelay memanggil f(attempt, elapsed).
+func (f PollScheduleFunc) NextDelay(attempt int, elapsed time.Duration) time.Duration {
+	return f(attempt, elapsed)
+}
+
+// FixedSchedule waits interval between every two checks. A non-positive interval uses DefaultPollInterval.
+// FixedSchedule menunggu interval di antara setiap dua pengecekan. Interval tidak positif memakai DefaultPollInterval.
+func FixedSchedule(interval time.Duration) PollSchedule {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return PollScheduleFunc(func(int, time.Duration) time.Duration {
+		return interval
+	})
+}
+
+// ExponentialSchedule waits initial after the first check and doubles the delay after every
+// further check, up to max. ExponentialSchedule(5*time.Second, 30*time.Second) waits 5s, 10s, 20s, then 30s.
+// ExponentialSchedule menunggu initial setelah pengecekan pertama dan menggandakan jeda setelah setiap
+// pengecekan berikutnya, sampai max. ExponentialSchedule(5*time.Second, 30*time.Second) menunggu 5s, 10s, 20s, lalu 30s.
+func ExponentialSchedule(initial, max time.Duration) PollSchedule {
+	if initial <= 0 {
+		initial = DefaultPollInterval
+	}
+	if max < initial {
+		max = initial
+	}
+	return PollScheduleFunc(func(attempt int, _ time.Duration) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	})
+}
+
+// FrontLoadedSchedule checks every fast during the first window, when most payments arrive,
+// then every slow. FrontLoadedSchedule(2*time.Second, time.Minute, 15*time.Second) suits long-lived invoices.
+// FrontLoadedSchedule mengecek setiap fast selama window pertama, saat sebagian besar pembayaran masuk,
+// lalu setiap slow. FrontLoadedSchedule(2*time.Second, time.Minute, 15*time.Second) cocok untuk invoice berumur panjang.
+func FrontLoadedSchedule(fast, window, slow time.Duration) PollSchedule {
+	if fast <= 0 {
+		fast = DefaultPollInterval
+	}
+	if slow <= 0 {
+		slow = fast
+	}
+	return PollScheduleFunc(func(_ int, elapsed time.Duration) time.Duration {
+		if elapsed < window {
+			return fast
+		}
+		return slow
+	})
+}
+
+// pollSchedule returns the configured default schedule of WaitForPayment and session watchers.
+// pollSchedule mengembalikan jadwal default WaitForPayment dan watcher sesi yang dikonfigurasi.
+func (q *QRIS) pollSchedule() PollSchedule {
+	if q.config.PollSchedule != nil {
+		return q.config.PollSchedule
+	}
+	return FixedSchedule(DefaultPollInterval)
+}
@@ -0,0 +1,172 @@
+package qris
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaError reports a mutation row that does not have the expected fields. It is only
+// returned with QRISConfig.StrictDecoding; by default such rows are decoded as well as possible.
+// SchemaError melaporkan baris mutasi yang tidak memiliki field yang diharapkan. Error ini hanya
+// dikembalikan dengan QRISConfig.StrictDecoding; secara default baris tersebut di-decode sebisanya.
+type SchemaError struct {
+	Row        int      // Index of the row in the response data / Indeks baris di data response
+	Unexpected []string // Fields the decoder does not know / Field yang tidak dikenal decoder
+	Missing    []string // Expected fields that are absent / Field yang diharapkan tetapi tidak ada
+}
+
+func (e *SchemaError) Error() string {
+	var parts []string
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Unexpected, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	detail := strings.Join(parts, "; ")
+	return fmt.Sprintf("mutation row %d does not match the schema / baris mutasi %d tidak sesuai skema: %s", e.Row, e.Row, detail)
+}
+
+// Code returns "GATEWAY_SCHEMA_MISMATCH", see ErrorCode.
+// Code mengembalikan "GATEWAY_SCHEMA_MISMATCH", lihat ErrorCode.
+func (e *SchemaError) Code() string {
+	return "GATEWAY_SCHEMA_MISMATCH"
+}
+
+// mutationFields lists the fields of a mutation row with every spelling the gateway has used.
+// mutationFields berisi field baris mutasi dengan setiap ejaan yang pernah dipakai gateway.
+var mutationFields = []struct {
+	names []string
+	set   func(row *mutationRow, raw json.RawMessage) error
+}{
+	{[]string{"amount"}, func(row *mutationRow, raw json.RawMessage) error { return setAmount(row, raw) }},
+	{[]string{"date"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.Date, raw) }},
+	{[]string{"qris"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.QRIS, raw) }},
+	{[]string{"type"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.Type, raw) }},
+	{[]string{"issuer_reff", "issuer_ref"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.IssuerRef, raw) }},
+	{[]string{"brand_name"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.BrandName, raw) }},
+	{[]string{"buyer_reff", "buyer_ref"}, func(row *mutationRow, raw json.RawMessage) error { return setText(&row.BuyerRef, raw) }},
+	{[]string{"balance", "saldo"}, func(row *mutationRow, raw json.RawMessage) error { return setBalance(row, raw) }},
+}
+
+// optionalMutationFields are the mutationFields only some gateways send, which strict decoding does not require.
+// optionalMutationFields adalah mutationFields yang hanya dikirim sebagian gateway, yang tidak diwajibkan decoding ketat.
+var optionalMutationFields = map[string]bool{"balance": true}
+
+// decodeMutationRow decodes row number index of a mutation response. Every known spelling of a
+// field is accepted and unknown fields are ignored, unless strict is set: then unknown fields or
+// absent ones return a *SchemaError.
+// decodeMutationRow men-decode baris ke-index dari response mutasi. Setiap ejaan field yang dikenal
+// diterima dan field yang tidak dikenal diabaikan, kecuali strict diaktifkan: maka field yang tidak
+// dikenal atau tidak ada mengembalikan *SchemaError.
+func decodeMutationRow(data json.RawMessage, index int, strict bool) (mutationRow, error) {
+	var row mutationRow
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return row, err
+	}
+
+	known := make(map[string]bool, len(fields))
+	var missing []string
+	for _, f := range mutationFields {
+		found := false
+		for _, name := range f.names {
+			raw, ok := fields[name]
+			if !ok {
+				continue
+			}
+			known[name] = true
+			if found {
+				continue
+			}
+			found = true
+			if err := f.set(&row, raw); err != nil {
+				return row, fmt.Errorf("field %s: %v", name, err)
+			}
+		}
+		if !found && !optionalMutationFields[f.names[0]] {
+			missing = append(missing, f.names[0])
+		}
+	}
+
+	if !strict {
+		return row, nil
+	}
+	var unexpected []string
+	for name := range fields {
+		if !known[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if len(unexpected) > 0 || len(missing) > 0 {
+		sort.Strings(unexpected)
+		return row, &SchemaError{Row: index, Unexpected: unexpected, Missing: missing}
+	}
+	return row, nil
+}
+
+// setText decodes a text field, accepting numbers too; null, "null" and "undefined" leave it empty.
+// setText men-decode field teks, juga menerima angka; null, "null" dan "undefined" membiarkannya kosong.
+func setText(dst *string, raw json.RawMessage) error {
+	s := strings.TrimSpace(string(raw))
+	switch {
+	case s == "null":
+		*dst = ""
+	case strings.HasPrefix(s, `"`):
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return err
+		}
+		// Some gateways print a missing value as text
+		if v := strings.TrimSpace(*dst); strings.EqualFold(v, "null") || strings.EqualFold(v, "undefined") {
+			*dst = ""
+		}
+	default:
+		*dst = s
+	}
+	return nil
+}
+
+// setAmount decodes the amount of a row, sent either as a JSON string or a JSON number, in any form
+// parseMutationAmount accepts. Unparsable amounts decode as 0 so a single bad row never fails the
+// whole response.
+// setAmount men-decode nominal sebuah baris, yang dikirim sebagai string JSON atau angka JSON, dalam
+// bentuk apa pun yang diterima parseMutationAmount. Nominal yang tidak valid di-decode sebagai 0 agar
+// satu baris rusak tidak menggagalkan seluruh response.
+func setAmount(row *mutationRow, raw json.RawMessage) error {
+	var s string
+	if err := setText(&s, raw); err != nil {
+		return err
+	}
+	n, frac, err := parseMutationAmount(s)
+	if err != nil {
+		row.Amount = 0
+		return nil
+	}
+	row.Amount = n
+	if frac {
+		row.TruncatedAmount = s
+	}
+	return nil
+}
+
+// setBalance decodes the running balance of a row, written as a number or as rupiah text such as
+// "1.250.000" or "1,250,000.00". Null and unparsable balances leave the row without one.
+// setBalance men-decode saldo berjalan dari sebuah baris, ditulis sebagai angka atau teks rupiah seperti
+// "1.250.000" atau "1,250,000.00". Saldo null dan yang tidak valid membuat baris tidak memiliki saldo.
+func setBalance(row *mutationRow, raw json.RawMessage) error {
+	var s string
+	if err := setText(&s, raw); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	n, _, err := parseMutationAmount(s)
+	if err != nil {
+		return nil
+	}
+	row.Balance, row.HasBalance = n, true
+	return nil
+}
@@ -0,0 +1,98 @@
+package qris_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestMutationSchemaDrift(t *testing.T) {
+	want := qris.Mutation{
+		Amount:    15000,
+		Date:      "2024-05-01 09:00:00",
+		Time:      time.Date(2024, 5, 1, 9, 0, 0, 0, qris.WIB),
+		QRIS:      "static",
+		Type:      "CR",
+		IssuerRef: "REF1",
+		BrandName: "DANA",
+		Issuer:    qris.NormalizeIssuer("DANA"),
+		BuyerRef:  "ORDER1",
+	}
+	withBalance := want
+	withBalance.Balance, withBalance.HasBalance = 1250000, true
+
+	tests := []struct {
+		fixture string
+		want    qris.Mutation
+		schema  *qris.SchemaError // Returned with StrictDecoding, nil if the shape is known
+	}{
+		{fixture: "legacy.json", want: want},
+		{fixture: "renamed.json", want: want},
+		{fixture: "numeric.json", want: want},
+		{fixture: "balance.json", want: withBalance},
+		{
+			fixture: "added.json",
+			want:    want,
+			schema:  &qris.SchemaError{Row: 0, Unexpected: []string{"channel", "fee"}},
+		},
+		{
+			fixture: "missing.json",
+			want:    qris.Mutation{Amount: 15000, Date: want.Date, Time: want.Time, Type: "CR", IssuerRef: "REF1", BrandName: "DANA", Issuer: want.Issuer},
+			schema:  &qris.SchemaError{Row: 0, Missing: []string{"qris", "buyer_reff"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "schema", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := staticGateway(t, body)
+			ctx := testContext(t, 5*time.Second)
+
+			tolerant := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+			mutations, err := tolerant.GetMutations(ctx, qris.MutationQuery{})
+			if err != nil {
+				t.Fatalf("tolerant decoding: %v", err)
+			}
+			if len(mutations) != 1 || !reflect.DeepEqual(mutations[0], tt.want) {
+				t.Fatalf("tolerant decoding = %+v, want [%+v]", mutations, tt.want)
+			}
+
+			strict := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+				c.GatewayURL = srv.URL
+				c.StrictDecoding = true
+			})
+			_, err = strict.GetMutations(ctx, qris.MutationQuery{})
+			if tt.schema == nil {
+				if err != nil {
+					t.Fatalf("strict decoding: %v", err)
+				}
+				return
+			}
+			var schemaErr *qris.SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("strict decoding = %v, want a *SchemaError", err)
+			}
+			if !reflect.DeepEqual(schemaErr, tt.schema) {
+				t.Errorf("SchemaError = %+v, want %+v", schemaErr, tt.schema)
+			}
+			if got := qris.ErrorCode(err); got != "GATEWAY_SCHEMA_MISMATCH" {
+				t.Errorf("ErrorCode = %q, want GATEWAY_SCHEMA_MISMATCH", got)
+			}
+		})
+	}
+}
+
+func TestSchemaErrorString(t *testing.T) {
+	err := &qris.SchemaError{Row: 3, Unexpected: []string{"fee"}, Missing: []string{"qris"}}
+	want := "mutation row 3 does not match the schema / baris mutasi 3 tidak sesuai skema: unexpected fee; missing qris"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,883 @@
+package qris
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// DefaultPaymentExpiry is how long a PaymentSession waits for payment by default.
+// DefaultPaymentExpiry adalah lama default PaymentSession menunggu pembayaran.
+const DefaultPaymentExpiry = 30 * time.Minute
+
+// clockSkew tolerates gateway dates that are second-truncated or slightly behind the local clock.
+// clockSkew mentoleransi tanggal gateway yang dibulatkan ke detik atau sedikit tertinggal dari jam lokal.
+const clockSkew = 30 * time.Second
+
+var (
+	// ErrPaymentExpired is returned by Wait when the session expired before payment.
+	// ErrPaymentExpired dikembalikan oleh Wait jika sesi kedaluwarsa sebelum dibayar.
+	ErrPaymentExpired = errors.New("payment expired / pembayaran kedaluwarsa")
+
+	// ErrPaymentCancelled is returned by Wait when the session was cancelled.
+	// ErrPaymentCancelled dikembalikan oleh Wait jika sesi dibatalkan.
+	ErrPaymentCancelled = errors.New("payment cancelled / pembayaran dibatalkan")
+)
+
+// Payment event types emitted by PaymentSession.
+// Tipe event pembayaran yang dikirim oleh PaymentSession.
+const (
+	EventCreated     = "created"      // Session created and amount reserved / Sesi dibuat dan nominal dipesan
+	EventQRGenerated = "qr_generated" // QR code generated / QR code di-generate
+
+	EventChecked   = "checked"   // A status check finished / Pengecekan status selesai
+	EventPaid      = "paid"      // Payment received / Pembayaran diterima
+	EventExpired   = "expired"   // Session expired / Sesi kedaluwarsa
+	EventCancelled = "cancelled" // Session cancelled / Sesi dibatalkan
+
+	// EventAmountChanged reports a new amount and QR code set by Regenerate.
+	// EventAmountChanged melaporkan nominal dan QR code baru yang diatur oleh Regenerate.
+	EventAmountChanged = "amount_changed"
+
+	// EventDuplicatePayment reports a second payment for an already paid session (see WithDetectDuplicates).
+	// EventDuplicatePayment melaporkan pembayaran kedua untuk sesi yang sudah dibayar (lihat WithDetectDuplicates).
+	EventDuplicatePayment = "duplicate_payment"
+)
+
+// PaymentEvent describes a state change or status check of a PaymentSession.
+// PaymentEvent menggambarkan perubahan state atau pengecekan status dari PaymentSession.
+type PaymentEvent struct {
+	SessionID string         // Transaction ID of the session / ID transaksi dari sesi
+	Type      string         // Event type (created/qr_generated/checked/paid/expired/cancelled) / Tipe event
+	At        time.Time      // Time of the event / Waktu event
+	Status    *PaymentStatus // Status at the time of the event / Status saat event terjadi
+	Err       error          // Error of a failed check, if any / Error dari pengecekan yang gagal, jika ada
+	Detail    string         // Human-readable detail, e.g. "check 3" / Detail yang mudah dibaca, misalnya "check 3"
+
+	Duplicate *DuplicatePayment // Set for EventDuplicatePayment / Diisi untuk EventDuplicatePayment
+
+	Metadata map[string]string // Metadata of the session, see WithMetadata; do not modify / Metadata sesi, lihat WithMetadata; jangan diubah
+
+	Tenant string // Tenant of the context that caused the event, see WithTenant / Tenant dari context penyebab event, lihat WithTenant
+	Actor  string // Actor of the context that caused the event, see WithActor / Actor dari context penyebab event, lihat WithActor
+}
+
+// DuplicatePayment describes a payment received for a session that was already paid.
+// DuplicatePayment menggambarkan pembayaran yang diterima untuk sesi yang sudah dibayar.
+//
+// Both issuer references are kept so the merchant can refund the duplicate.
+// Kedua referensi issuer disimpan agar merchant dapat me-refund pembayaran ganda.
+type DuplicatePayment struct {
+	TransactionID string // Transaction ID of the session / ID transaksi dari sesi
+	Amount        int64  // Amount paid twice / Nominal yang dibayar dua kali
+	OriginalRef   string // Issuer reference of the payment that settled the session / Referensi issuer pembayaran yang melunasi sesi
+	DuplicateRef  string // Issuer reference of the duplicate payment / Referensi issuer pembayaran ganda
+	Date          string // Gateway date of the duplicate / Tanggal gateway pembayaran ganda
+	BrandName     string // Payer brand name of the duplicate / Nama brand pembayar ganda
+	BuyerRef      string // Buyer reference of the duplicate / Referensi pembeli pembayaran ganda
+}
+
+// PaymentOption customizes CreatePayment.
+// PaymentOption mengatur perilaku CreatePayment.
+type PaymentOption func(*paymentOptions)
+
+type paymentOptions struct {
+	transactionID string
+	expiry        time.Duration
+	uniqueSuffix  int64
+	schedule      PollSchedule
+	store         InvoiceStore
+	duplicates    time.Duration
+	policy        MatchPolicy
+	deterministic bool
+	metadata      map[string]string
+}
+
+func defaultPaymentOptions() paymentOptions {
+	return paymentOptions{
+		expiry:       DefaultPaymentExpiry,
+		uniqueSuffix: DefaultUniqueSuffix,
+	}
+}
+
+// WithTransactionID uses the given transaction ID instead of a generated one.
+// WithTransactionID memakai ID transaksi yang diberikan alih-alih ID yang di-generate.
+func WithTransactionID(id string) PaymentOption {
+	return func(o *paymentOptions) {
+		o.transactionID = id
+	}
+}
+
+// WithExpiry sets how long the session waits for payment.
+// WithExpiry mengatur berapa lama sesi menunggu pembayaran.
+func WithExpiry(d time.Duration) PaymentOption {
+	return func(o *paymentOptions) {
+		if d > 0 {
+			o.expiry = d
+		}
+	}
+}
+
+// WithUniqueSuffix sets the maximum number of rupiah added to make the amount unique.
+// Zero keeps the exact amount, which then cannot be shared by two pending sessions.
+// WithUniqueSuffix mengatur jumlah rupiah maksimum yang ditambahkan agar nominal unik.
+// Nol mempertahankan nominal persis, yang tidak dapat dipakai dua sesi sekaligus.
+func WithUniqueSuffix(max int64) PaymentOption {
+	return func(o *paymentOptions) {
+		if max >= 0 {
+			o.uniqueSuffix = max
+		}
+	}
+}
+
+// WithDeterministicAmount derives the unique amount from the transaction ID with DeterministicAmount
+// instead of picking a random free one, so the payable amount can be recomputed after a restart.
+// CreatePayment fails with ErrAmountCollision if another pending session already holds that amount.
+// WithDeterministicAmount menurunkan nominal unik dari ID transaksi dengan DeterministicAmount
+// alih-alih memilih nominal kosong secara acak, sehingga nominal yang harus dibayar dapat dihitung ulang setelah restart.
+// CreatePayment gagal dengan ErrAmountCollision jika sesi lain yang menunggu sudah memegang nominal tersebut.
+func WithDeterministicAmount() PaymentOption {
+	return func(o *paymentOptions) {
+		o.deterministic = true
+	}
+}
+
+// WithSessionPollInterval sets the delay between two status checks of the session watcher.
+// WithSessionPollInterval mengatur jeda antara dua pengecekan status oleh watcher sesi.
+func WithSessionPollInterval(d time.Duration) PaymentOption {
+	return func(o *paymentOptions) {
+		if d > 0 {
+			o.schedule = FixedSchedule(d)
+		}
+	}
+}
+
+// WithSessionPollSchedule sets the schedule of the session watcher instead of QRISConfig.PollSchedule.
+// WithSessionPollSchedule mengatur jadwal watcher sesi alih-alih QRISConfig.PollSchedule.
+func WithSessionPollSchedule(schedule PollSchedule) PaymentOption {
+	return func(o *paymentOptions) {
+		if schedule != nil {
+			o.schedule = schedule
+		}
+	}
+}
+
+// WithInvoiceStore persists the session in store instead of QRISConfig.InvoiceStore.
+// WithInvoiceStore menyimpan sesi di store alih-alih QRISConfig.InvoiceStore.
+func WithInvoiceStore(store InvoiceStore) PaymentOption {
+	return func(o *paymentOptions) {
+		o.store = store
+	}
+}
+
+// WithMatchPolicy decides the payment of this session with policy instead of QRISConfig.MatchPolicy.
+// WithMatchPolicy menentukan pembayaran sesi ini dengan policy alih-alih QRISConfig.MatchPolicy.
+func WithMatchPolicy(policy MatchPolicy) PaymentOption {
+	return func(o *paymentOptions) {
+		o.policy = policy
+	}
+}
+
+// WithDetectDuplicates keeps checking the gateway for window after the session is paid and emits
+// EventDuplicatePayment for every further matching payment. The amount stays reserved and the
+// Events channel stays open until window ends, so a repeated payment cannot settle another session.
+// WithDetectDuplicates tetap mengecek gateway selama window setelah sesi dibayar dan mengirim
+// EventDuplicatePayment untuk setiap pembayaran cocok berikutnya. Nominal tetap dipesan dan channel
+// Events tetap terbuka sampai window berakhir, sehingga pembayaran berulang tidak melunasi sesi lain.
+func WithDetectDuplicates(window time.Duration) PaymentOption {
+	return func(o *paymentOptions) {
+		if window > 0 {
+			o.duplicates = window
+		}
+	}
+}
+
+// PaymentSession ties a generated QR code to the status tracking of one payment.
+// PaymentSession menghubungkan QR code yang di-generate dengan pelacakan status satu pembayaran.
+//
+// A session is safe for concurrent use. The amounts and the QR code can change through Regenerate,
+// so they are read with methods; read them together with Snapshot.
+// Sesi aman dipakai secara bersamaan oleh banyak goroutine. Nominal dan QR code dapat berubah melalui
+// Regenerate, jadi keduanya dibaca dengan method; baca bersamaan dengan Snapshot.
+type PaymentSession struct {
+	TransactionID string    // Transaction ID of the payment / ID transaksi pembayaran
+	CreatedAt     time.Time // Creation time / Waktu pembuatan
+	ExpiresAt     time.Time // Expiry time / Waktu kedaluwarsa
+
+	Metadata map[string]string // Metadata of WithMetadata; do not modify / Metadata dari WithMetadata; jangan diubah
+
+	q             *QRIS
+	code          string
+	store         InvoiceStore
+	schedule      PollSchedule
+	duplicates    time.Duration
+	policy        MatchPolicy
+	uniqueSuffix  int64
+	deterministic bool
+
+	// ctx carries the tenant and actor of the context the session was created with
+	// ctx membawa tenant dan actor dari context yang dipakai untuk membuat sesi
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// pollCtx is cancelled by Close to stop the watcher after its current check
+	// pollCtx dibatalkan oleh Close untuk menghentikan watcher setelah pengecekan yang sedang berjalan
+	pollCtx     context.Context
+	stopWatcher context.CancelFunc
+
+	mu         sync.Mutex
+	baseAmount int64          // Requested amount / Nominal yang diminta
+	amount     int64          // Unique amount the customer must pay / Nominal unik yang harus dibayar
+	qrCode     *qrcode.QRCode // Generated QR code / QR code yang di-generate
+	qrString   string         // Encoded QRIS string / String QRIS yang di-encode
+	status     *PaymentStatus
+	err        error
+	events     chan PaymentEvent
+	done       chan struct{}
+	start      sync.Once
+}
+
+// CreatePayment reserves a unique amount, generates its QR code, and returns a session tracking it.
+// CreatePayment memesan nominal unik, men-generate QR code-nya, dan mengembalikan sesi yang melacaknya.
+//
+// This is the recommended high-level API; GenerateQRCode and CheckPaymentStatus remain available.
+// The session watcher is started lazily by Wait or Events and is independent of ctx.
+// Ini adalah API tingkat tinggi yang disarankan; GenerateQRCode dan CheckPaymentStatus tetap tersedia.
+// Watcher sesi dijalankan saat Wait atau Events dipanggil dan tidak bergantung pada ctx.
+func (q *QRIS) CreatePayment(ctx context.Context, amount int64, opts ...PaymentOption) (*PaymentSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := q.life.err(); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+	}
+
+	o := defaultPaymentOptions()
+	o.uniqueSuffix = q.uniqueSuffix()
+	o.deterministic = q.config.DeterministicAmounts
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	txID := o.transactionID
+	if txID == "" {
+		var err error
+		if txID, err = q.newTransactionID(); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if txID, err = checkTransactionID(txID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := q.checkAmount(amount); err != nil {
+		return nil, err
+	}
+	if err := checkMetadata(o.metadata); err != nil {
+		return nil, err
+	}
+
+	policy := o.policy
+	if policy == nil {
+		policy = q.matchPolicy()
+	}
+
+	var unique int64
+	var err error
+	if o.deterministic && o.uniqueSuffix > 0 {
+		unique = DeterministicAmount(amount, txID, int(o.uniqueSuffix))
+		err = q.amounts.reserveExact(unique, txID)
+	} else {
+		unique, err = q.amounts.reserve(amount, o.uniqueSuffix, txID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reservedAt := time.Now()
+
+	// The payload is built from the reserved amount, never from the requested one
+	qrString, qrCode, err := q.encodePayment(unique, txID, policy)
+	if err != nil {
+		q.amounts.release(unique, txID)
+		return nil, err
+	}
+	generatedAt := time.Now()
+
+	store := o.store
+	if store == nil {
+		store = q.config.InvoiceStore
+	}
+
+	now := time.Now()
+	inv := Invoice{
+		TransactionID: txID,
+		Merchant:      q.merchant,
+		BaseAmount:    amount,
+		Amount:        unique,
+		QRString:      qrString,
+		Status:        StatusUnpaid,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(o.expiry),
+		Metadata:      o.metadata,
+	}
+	if inv.Code, err = q.mintCode(ctx, txID, inv.Amount, inv.ExpiresAt, store); err != nil {
+		q.amounts.release(unique, txID)
+		return nil, err
+	}
+
+	if store != nil {
+		if err := store.Save(ctx, inv); err != nil {
+			q.amounts.release(unique, txID)
+			return nil, fmt.Errorf("failed to save invoice / gagal menyimpan invoice: %v", err)
+		}
+	}
+
+	schedule := o.schedule
+	if schedule == nil {
+		schedule = q.pollSchedule()
+	}
+	s := q.newSession(ctx, inv, qrCode, schedule, store)
+	s.duplicates = o.duplicates
+	s.policy = policy
+	s.uniqueSuffix = o.uniqueSuffix
+	s.deterministic = o.deterministic
+	s.emit(PaymentEvent{Type: EventCreated, At: reservedAt, Detail: "amount " + FormatIDR(unique)})
+	s.emit(PaymentEvent{Type: EventQRGenerated, At: generatedAt})
+	return s, nil
+}
+
+// encodePayment builds the QRIS string and QR code paying unique for txID.
+// encodePayment menyusun string QRIS dan QR code untuk membayar unique bagi txID.
+func (q *QRIS) encodePayment(unique int64, txID string, policy MatchPolicy) (string, *qrcode.QRCode, error) {
+	qrString, err := q.encodeQRIS(QRISData{Amount: unique, TransactionID: txID}, embedsReference(policy))
+	if err != nil {
+		return "", nil, err
+	}
+	// The unique suffix must not push the amount over MaxAmount
+	if err := q.checkAmount(unique); err != nil {
+		return "", nil, err
+	}
+	qrCode, err := q.newQRCode(qrString)
+	if err != nil {
+		return "", nil, err
+	}
+	return qrString, qrCode, nil
+}
+
+// Session returns the unfinished PaymentSession with the given transaction ID, if any.
+// Session mengembalikan PaymentSession yang belum selesai dengan ID transaksi tersebut, jika ada.
+func (q *QRIS) Session(transactionID string) (*PaymentSession, bool) {
+	return q.sessions.get(transactionID)
+}
+
+// PaymentSnapshot is a consistent copy of a PaymentSession, taken by Snapshot.
+// PaymentSnapshot adalah salinan konsisten dari PaymentSession, diambil oleh Snapshot.
+type PaymentSnapshot struct {
+	TransactionID string         // Transaction ID of the payment / ID transaksi pembayaran
+	BaseAmount    int64          // Requested amount / Nominal yang diminta
+	Amount        int64          // Unique amount the customer must pay / Nominal unik yang harus dibayar
+	QRCode        *qrcode.QRCode // QR code paying Amount / QR code yang membayar Amount
+	QRString      string         // Encoded QRIS string of QRCode / String QRIS yang di-encode dari QRCode
+	Code          string         // See PaymentSession.Code / Lihat PaymentSession.Code
+	CreatedAt     time.Time      // Creation time / Waktu pembuatan
+	ExpiresAt     time.Time      // Expiry time / Waktu kedaluwarsa
+
+	Metadata map[string]string // Metadata of WithMetadata; do not modify / Metadata dari WithMetadata; jangan diubah
+}
+
+// Snapshot returns the amounts and the QR code of the session in one step, so they always belong
+// together even while Regenerate runs, e.g. to render a payment page.
+// Snapshot mengembalikan nominal dan QR code sesi dalam satu langkah, sehingga keduanya selalu
+// sepasang meskipun Regenerate sedang berjalan, misalnya untuk me-render halaman pembayaran.
+func (s *PaymentSession) Snapshot() PaymentSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return PaymentSnapshot{
+		TransactionID: s.TransactionID,
+		BaseAmount:    s.baseAmount,
+		Amount:        s.amount,
+		QRCode:        s.qrCode,
+		QRString:      s.qrString,
+		Code:          s.code,
+		CreatedAt:     s.CreatedAt,
+		ExpiresAt:     s.ExpiresAt,
+		Metadata:      s.Metadata,
+	}
+}
+
+// BaseAmount returns the requested amount.
+// BaseAmount mengembalikan nominal yang diminta.
+func (s *PaymentSession) BaseAmount() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.baseAmount
+}
+
+// Amount returns the unique amount the customer must pay.
+// Amount mengembalikan nominal unik yang harus dibayar customer.
+func (s *PaymentSession) Amount() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.amount
+}
+
+// QRCode returns the QR code paying Amount.
+// QRCode mengembalikan QR code yang membayar Amount.
+func (s *PaymentSession) QRCode() *qrcode.QRCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.qrCode
+}
+
+// QRString returns the encoded QRIS string of QRCode.
+// QRString mengembalikan string QRIS yang di-encode dari QRCode.
+func (s *PaymentSession) QRString() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.qrString
+}
+
+// sessionRegistry tracks the unfinished sessions of a QRIS instance by transaction ID.
+// sessionRegistry mencatat sesi yang belum selesai pada instance QRIS berdasarkan ID transaksi.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*PaymentSession
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{sessions: make(map[string]*PaymentSession)}
+}
+
+func (r *sessionRegistry) add(s *PaymentSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[s.TransactionID] = s
+}
+
+func (r *sessionRegistry) remove(s *PaymentSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessions[s.TransactionID] == s {
+		delete(r.sessions, s.TransactionID)
+	}
+}
+
+func (r *sessionRegistry) all() []*PaymentSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions := make([]*PaymentSession, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+func (r *sessionRegistry) get(transactionID string) (*PaymentSession, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[transactionID]
+	return s, ok
+}
+
+// newSession builds a session for an invoice whose amount is already reserved. The watcher keeps
+// the tenant and actor of ctx but not its cancellation.
+// newSession membuat sesi untuk invoice yang nominalnya sudah dipesan. Watcher menyimpan tenant dan
+// actor dari ctx tetapi tidak pembatalannya.
+func (q *QRIS) newSession(ctx context.Context, inv Invoice, qrCode *qrcode.QRCode, schedule PollSchedule, store InvoiceStore) *PaymentSession {
+	watchCtx, cancel := context.WithDeadline(withAudit(context.Background(), ctx), inv.ExpiresAt)
+	pollCtx, stop := context.WithCancel(watchCtx)
+	s := &PaymentSession{
+		TransactionID: inv.TransactionID,
+		baseAmount:    inv.BaseAmount,
+		amount:        inv.Amount,
+		qrCode:        qrCode,
+		qrString:      inv.QRString,
+		CreatedAt:     inv.CreatedAt,
+		ExpiresAt:     inv.ExpiresAt,
+		Metadata:      inv.Metadata,
+		q:             q,
+		code:          inv.Code,
+		store:         store,
+		schedule:      schedule,
+		policy:        q.matchPolicy(),
+		uniqueSuffix:  q.uniqueSuffix(),
+		deterministic: q.config.DeterministicAmounts,
+		ctx:           watchCtx,
+		cancel:        cancel,
+		pollCtx:       pollCtx,
+		stopWatcher:   stop,
+		events:        make(chan PaymentEvent, 16),
+		done:          make(chan struct{}),
+	}
+	q.sessions.add(s)
+	if s.code != "" {
+		q.codes.add(s)
+	}
+	q.startSweeper()
+	return s
+}
+
+// Status checks the payment status once, or returns the final status if the session is finished.
+// Status mengecek status pembayaran sekali, atau mengembalikan status akhir jika sesi sudah selesai.
+func (s *PaymentSession) Status(ctx context.Context) (*PaymentStatus, error) {
+	s.mu.Lock()
+	if s.status != nil && s.status.Status != StatusUnpaid {
+		status := *s.status
+		s.mu.Unlock()
+		return &status, nil
+	}
+	s.mu.Unlock()
+
+	if !time.Now().Before(s.ExpiresAt) {
+		s.finish(EventExpired, s.unpaidStatus(StatusExpired), ErrPaymentExpired)
+		return s.Status(ctx)
+	}
+
+	status, err := s.checkClaimed(ctx, s.q.eachMutation)
+	s.emitFrom(ctx, PaymentEvent{Type: EventChecked, Status: status, Err: err, Detail: "status"})
+	if err != nil {
+		return nil, err
+	}
+	if status.Status == StatusPaid {
+		s.finish(EventPaid, status, nil)
+	}
+	return status, nil
+}
+
+// Wait blocks until the session is paid, expired, or cancelled, or ctx is done.
+// Wait menunggu sampai sesi dibayar, kedaluwarsa, atau dibatalkan, atau ctx selesai.
+//
+// It returns ErrPaymentExpired or ErrPaymentCancelled when the session ends without payment.
+// Fungsi ini mengembalikan ErrPaymentExpired atau ErrPaymentCancelled jika sesi berakhir tanpa pembayaran.
+func (s *PaymentSession) Wait(ctx context.Context) (*PaymentStatus, error) {
+	s.startWatcher()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := *s.status
+	return &status, s.err
+}
+
+// Events returns a channel of session events that is closed when the session finishes, or after
+// the duplicate window of WithDetectDuplicates. Events are dropped when the channel buffer is full,
+// so a slow reader never blocks the watcher.
+// Events mengembalikan channel event sesi yang ditutup ketika sesi selesai, atau setelah window
+// duplikat dari WithDetectDuplicates. Event dibuang jika buffer channel penuh, sehingga pembaca
+// yang lambat tidak memblokir watcher.
+func (s *PaymentSession) Events() <-chan PaymentEvent {
+	s.startWatcher()
+	return s.events
+}
+
+// Done returns a channel that is closed when the session finishes.
+// Done mengembalikan channel yang ditutup ketika sesi selesai.
+func (s *PaymentSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Cancel stops the watcher and releases the amount reservation.
+// Cancel menghentikan watcher dan melepas pemesanan nominal.
+func (s *PaymentSession) Cancel() {
+	s.finish(EventCancelled, s.unpaidStatus(StatusCancelled), ErrPaymentCancelled)
+}
+
+// check looks in source for a payment made since the session was created.
+// check mencari pembayaran yang dilakukan sejak sesi dibuat di source.
+func (s *PaymentSession) check(ctx context.Context, source mutationSource) (*PaymentStatus, error) {
+	inv := s.invoice()
+	status, err := s.q.checkPayment(ctx, inv, s.policy, source)
+	if err == nil && status.Status == StatusPaid && s.invoice().Amount != inv.Amount {
+		// Regenerate changed the amount during the check, so the old one no longer pays the session
+		return s.unpaidStatus(StatusUnpaid), nil
+	}
+	return status, err
+}
+
+// checkClaimed is like check but ignores mutations claimed by other invoices and claims the one
+// that pays the session, so one mutation never produces two PAID events across watchers.
+// checkClaimed sama seperti check tetapi mengabaikan mutasi yang diklaim invoice lain dan mengklaim
+// mutasi yang membayar sesi, sehingga satu mutasi tidak pernah menghasilkan dua event PAID antar watcher.
+func (s *PaymentSession) checkClaimed(ctx context.Context, source mutationSource) (*PaymentStatus, error) {
+	status, err := s.check(ctx, s.q.excludeClaimed(source, s.TransactionID))
+	if err != nil {
+		return status, err
+	}
+	status.Metadata = s.Metadata
+	if status.Status != StatusPaid {
+		return status, nil
+	}
+	if !s.q.claimPayment(ctx, status, s.TransactionID, s.store) {
+		return s.unpaidStatus(StatusUnpaid), nil
+	}
+	return status, nil
+}
+
+// invoice describes the session to its MatchPolicy, with CreatedAt moved back by clockSkew.
+// invoice menggambarkan sesi untuk MatchPolicy-nya, dengan CreatedAt dimundurkan sebesar clockSkew.
+func (s *PaymentSession) invoice() Invoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Invoice{
+		TransactionID: s.TransactionID,
+		Merchant:      s.q.merchant,
+		BaseAmount:    s.baseAmount,
+		Amount:        s.amount,
+		QRString:      s.qrString,
+		Status:        StatusUnpaid,
+		CreatedAt:     s.CreatedAt.Add(-clockSkew),
+		ExpiresAt:     s.ExpiresAt,
+		Metadata:      s.Metadata,
+	}
+}
+
+func (s *PaymentSession) startWatcher() {
+	s.start.Do(func() {
+		if !s.q.life.track() {
+			return
+		}
+		go func() {
+			defer s.q.life.wg.Done()
+			s.watch()
+		}()
+	})
+}
+
+// watch polls the payment status until the session is paid, expires, or is cancelled.
+// watch melakukan polling status sampai sesi dibayar, kedaluwarsa, atau dibatalkan.
+func (s *PaymentSession) watch() {
+	// Checks run on s.ctx so Close never aborts a check in flight
+	source := s.q.sharedCredits()
+	check := func(context.Context) (*PaymentStatus, error) {
+		return s.checkClaimed(s.ctx, source)
+	}
+	checks := 0
+	s.q.poll(s.pollCtx, s.schedule, check, func(status *PaymentStatus, err error) bool {
+		if s.ctx.Err() != nil {
+			return true
+		}
+		checks++
+		s.emit(PaymentEvent{Type: EventChecked, Status: status, Err: err, Detail: fmt.Sprintf("check %d", checks)})
+		if err == nil && status.Status == StatusPaid {
+			s.finish(EventPaid, status, nil)
+			return true
+		}
+		return false
+	})
+
+	if errors.Is(s.ctx.Err(), context.DeadlineExceeded) {
+		s.finish(EventExpired, s.unpaidStatus(StatusExpired), ErrPaymentExpired)
+	}
+}
+
+// finish records the final status once, releases the reservation, and closes the channels.
+// An empty eventType finishes the session without emitting an event.
+// finish mencatat status akhir satu kali, melepas pemesanan, dan menutup channel.
+// eventType kosong menyelesaikan sesi tanpa mengirim event.
+func (s *PaymentSession) finish(eventType string, status *PaymentStatus, err error) {
+	s.mu.Lock()
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		return
+	default:
+	}
+	s.status = status
+	s.err = err
+	if eventType != "" {
+		ev := PaymentEvent{Type: eventType, Status: status}
+		if eventType == EventPaid {
+			ev.Detail = "reference " + status.Reference
+		}
+		s.emitLocked(ev)
+	}
+	watchDuplicates := eventType == EventPaid && s.duplicates > 0 && s.q.life.track()
+	if !watchDuplicates {
+		close(s.events)
+	}
+	close(s.done)
+	amount := s.amount
+	s.mu.Unlock()
+
+	s.cancel()
+	s.q.sessions.remove(s)
+	s.persist(status)
+
+	if watchDuplicates {
+		go func() {
+			defer s.q.life.wg.Done()
+			s.watchDuplicates(status)
+		}()
+		return
+	}
+	s.q.amounts.release(amount, s.TransactionID)
+}
+
+// watchDuplicates reports further payments of a paid session until the duplicate window ends,
+// then closes the events channel and releases the amount.
+// watchDuplicates melaporkan pembayaran berikutnya dari sesi yang sudah dibayar sampai window
+// duplikat berakhir, lalu menutup channel event dan melepas nominal.
+func (s *PaymentSession) watchDuplicates(paid *PaymentStatus) {
+	ctx, cancel := context.WithTimeout(s.q.life.ctx, s.duplicates)
+	defer cancel()
+
+	seen := map[string]bool{paid.Reference: true}
+	source := s.q.sharedCredits()
+	check := func(ctx context.Context) (*PaymentStatus, error) {
+		matches, _, err := s.q.matchingPayments(ctx, s.invoice(), s.policy, source, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if m.IssuerRef == "" || seen[m.IssuerRef] {
+				continue
+			}
+			seen[m.IssuerRef] = true
+
+			logf(s.ctx, "Duplicate payment for %s: Amount=%d, Reference=%s", s.TransactionID, m.Amount, m.IssuerRef)
+			s.mu.Lock()
+			s.emitLocked(PaymentEvent{
+				Type:   EventDuplicatePayment,
+				Status: paid,
+				Detail: "reference " + m.IssuerRef + " duplicates " + paid.Reference,
+				Duplicate: &DuplicatePayment{
+					TransactionID: s.TransactionID,
+					Amount:        m.Amount,
+					OriginalRef:   paid.Reference,
+					DuplicateRef:  m.IssuerRef,
+					Date:          m.Date,
+					BrandName:     m.BrandName,
+					BuyerRef:      m.BuyerRef,
+				},
+			})
+			s.mu.Unlock()
+		}
+		return paid, nil
+	}
+	s.q.poll(ctx, s.schedule, check, func(*PaymentStatus, error) bool {
+		return false
+	})
+
+	s.mu.Lock()
+	close(s.events)
+	amount := s.amount
+	s.mu.Unlock()
+	s.q.amounts.release(amount, s.TransactionID)
+}
+
+// persist records the final status in the invoice store, if any.
+// persist mencatat status akhir di invoice store, jika ada.
+func (s *PaymentSession) persist(status *PaymentStatus) {
+	if s.store == nil {
+		return
+	}
+
+	ctx := context.Background()
+	var err error
+	switch status.Status {
+	case StatusPaid:
+		err = s.store.MarkPaid(ctx, s.TransactionID, status)
+	case StatusExpired:
+		err = s.store.MarkExpired(ctx, s.TransactionID)
+	default:
+		snap := s.Snapshot()
+		err = s.store.Save(ctx, Invoice{
+			TransactionID: s.TransactionID,
+			Merchant:      s.q.merchant,
+			BaseAmount:    snap.BaseAmount,
+			Amount:        snap.Amount,
+			QRString:      snap.QRString,
+			Status:        status.Status,
+			CreatedAt:     s.CreatedAt,
+			ExpiresAt:     s.ExpiresAt,
+			Code:          s.code,
+			Metadata:      s.Metadata,
+		})
+	}
+	if err != nil {
+		logf(s.ctx, "Error saving invoice %s: %v", s.TransactionID, err)
+	}
+}
+
+func (s *PaymentSession) emit(ev PaymentEvent) {
+	s.emitFrom(s.ctx, ev)
+}
+
+// emitFrom emits ev attributed to the tenant and actor of ctx.
+// emitFrom mengirim ev yang diatribusikan ke tenant dan actor dari ctx.
+func (s *PaymentSession) emitFrom(ctx context.Context, ev PaymentEvent) {
+	ev.Tenant, ev.Actor = TenantFromContext(ctx), ActorFromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	s.emitLocked(ev)
+}
+
+func (s *PaymentSession) emitLocked(ev PaymentEvent) {
+	ev.SessionID = s.TransactionID
+	ev.Metadata = s.Metadata
+	if ev.Tenant == "" && ev.Actor == "" {
+		ev.Tenant, ev.Actor = TenantFromContext(s.ctx), ActorFromContext(s.ctx)
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	select {
+	case s.events <- ev:
+	default:
+	}
+	s.q.events.publish(ev)
+}
+
+func (s *PaymentSession) unpaidStatus(status string) *PaymentStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &PaymentStatus{
+		Status:    status,
+		Amount:    s.amount,
+		Reference: s.TransactionID,
+		Metadata:  s.Metadata,
+	}
+}
+
+// newTransactionID generates a random transaction ID such as "TRX1A2B3C4D5E".
+// newTransactionID menghasilkan ID transaksi acak seperti "TRX1A2B3C4D5E".
+func (q *QRIS) newTransactionID() (string, error) {
+	b := make([]byte, 5)
+	if err := q.rand.read(b); err != nil {
+		return "", fmt.Errorf("failed to generate transaction ID / gagal generate ID transaksi: %v", err)
+	}
+	return "TRX" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
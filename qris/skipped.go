@@ -0,0 +1,96 @@
+package qris
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MaxSkippedRows is the number of recent rows kept by RecentSkippedRows.
+// MaxSkippedRows adalah jumlah baris terbaru yang disimpan RecentSkippedRows.
+const MaxSkippedRows = 20
+
+// maxSkippedKeys bounds the rows remembered to count each skipped row once.
+// maxSkippedKeys membatasi baris yang diingat agar setiap baris yang dilewati dihitung sekali.
+const maxSkippedKeys = 1000
+
+// SkippedRow is a mutation row left out because it cannot pay an invoice.
+// SkippedRow adalah baris mutasi yang dilewati karena tidak dapat membayar invoice.
+type SkippedRow struct {
+	Reason    string    // "missing date", "invalid date" or "missing amount" / "missing date", "invalid date" atau "missing amount"
+	Date      string    // Date as sent by the gateway / Tanggal seperti yang dikirim gateway
+	Amount    int64     // Amount as decoded / Nominal hasil decode
+	IssuerRef string    // Issuer reference, may be empty / Referensi issuer, boleh kosong
+	Raw       []byte    // Row as sent by the gateway, only with IncludeRaw / Baris seperti yang dikirim gateway, hanya dengan IncludeRaw
+	At        time.Time // When the row was first seen / Waktu baris pertama kali terlihat
+}
+
+// skippedRows counts the distinct rows left out of matching.
+// skippedRows menghitung baris berbeda yang dilewati dari pencocokan.
+type skippedRows struct {
+	mu     sync.Mutex
+	count  uint64
+	seen   map[string]bool
+	recent []SkippedRow
+}
+
+// skipReason returns why m cannot be matched, or "" if it can.
+// skipReason mengembalikan alasan m tidak dapat dicocokkan, atau "" jika bisa.
+func skipReason(m Mutation) string {
+	switch {
+	case m.Date == "":
+		return "missing date"
+	case m.Time.IsZero():
+		return "invalid date"
+	case m.Amount == 0:
+		return "missing amount"
+	}
+	return ""
+}
+
+// record counts row, identified by key, unless it was seen before. Every poll returns the same
+// rows again, so a row is counted and logged only once.
+// record menghitung row, yang diidentifikasi dengan key, kecuali sudah pernah terlihat. Setiap polling
+// mengembalikan baris yang sama lagi, sehingga sebuah baris hanya dihitung dan dicatat sekali.
+func (s *skippedRows) record(ctx context.Context, key string, row SkippedRow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[key] {
+		return
+	}
+	if s.seen == nil || len(s.seen) >= maxSkippedKeys {
+		s.seen = make(map[string]bool)
+	}
+	s.seen[key] = true
+	s.count++
+
+	row.At = time.Now()
+	s.recent = append(s.recent, row)
+	if len(s.recent) > MaxSkippedRows {
+		s.recent = append(s.recent[:0], s.recent[len(s.recent)-MaxSkippedRows:]...)
+	}
+	logf(ctx, "Skipping mutation row with %s: date=%q amount=%d ref=%q", row.Reason, row.Date, row.Amount, row.IssuerRef)
+}
+
+// SkippedRows returns how many distinct mutation rows were left out of matching because they
+// have no date, an unreadable date or no amount.
+// SkippedRows mengembalikan jumlah baris mutasi berbeda yang dilewati dari pencocokan karena tidak
+// memiliki tanggal, tanggalnya tidak terbaca atau tidak memiliki nominal.
+func (q *QRIS) SkippedRows() uint64 {
+	q.skipped.mu.Lock()
+	defer q.skipped.mu.Unlock()
+
+	return q.skipped.count
+}
+
+// RecentSkippedRows returns the last MaxSkippedRows rows counted by SkippedRows, oldest first,
+// to find out what the gateway sent.
+// RecentSkippedRows mengembalikan MaxSkippedRows baris terakhir yang dihitung SkippedRows, yang
+// terlama lebih dulu, untuk mengetahui apa yang dikirim gateway.
+func (q *QRIS) RecentSkippedRows() []SkippedRow {
+	q.skipped.mu.Lock()
+	defer q.skipped.mu.Unlock()
+
+	return append([]SkippedRow(nil), q.skipped.recent...)
+}
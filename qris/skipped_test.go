@@ -0,0 +1,124 @@
+package qris_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestMutationNullFields(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "schema", "nulls.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := staticGateway(t, body)
+	first := time.Date(2024, 5, 1, 9, 0, 0, 0, qris.WIB)
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.GatewayURL = srv.URL
+		c.MatchWindow = time.Since(first) + 24*time.Hour // Reach back to the fixture dates
+		c.QRISTypes = []string{qris.QRISTypeAny}         // Rows without a QRIS type pay too
+	})
+	ctx := testContext(t, 5*time.Second)
+
+	// Rows with null, empty, absent or "null" optional fields are kept with those fields empty
+	empty := func(amount int64, minutesBefore int) qris.Mutation {
+		date := first.Add(-time.Duration(minutesBefore) * time.Minute)
+		return qris.Mutation{
+			Amount: amount,
+			Date:   date.Format("2006-01-02 15:04:05"),
+			Time:   date,
+			Type:   "CR",
+			Issuer: qris.NormalizeIssuer(""),
+		}
+	}
+	want := []qris.Mutation{empty(15000, 0), empty(16000, 10), empty(17000, 20), empty(18000, 30)}
+
+	// Polling again must not count the skipped rows twice
+	for i := 0; i < 2; i++ {
+		mutations, err := q.GetMutations(ctx, qris.MutationQuery{})
+		if err != nil {
+			t.Fatalf("GetMutations: %v", err)
+		}
+		if !reflect.DeepEqual(mutations, want) {
+			t.Fatalf("mutations = %+v, want %+v", mutations, want)
+		}
+	}
+
+	if got := q.SkippedRows(); got != 7 {
+		t.Errorf("SkippedRows = %d, want 7", got)
+	}
+	var reasons []string
+	for _, row := range q.RecentSkippedRows() {
+		reasons = append(reasons, row.IssuerRef+": "+row.Reason)
+	}
+	wantReasons := []string{
+		"NODATE: missing date",
+		"NULLDATE: missing date",
+		"EMPTYDATE: missing date",
+		"BADDATE: invalid date",
+		"NULLAMOUNT: missing amount",
+		"NOAMOUNT: missing amount",
+		"EMPTYAMOUNT: missing amount",
+	}
+	if !reflect.DeepEqual(reasons, wantReasons) {
+		t.Errorf("RecentSkippedRows = %q, want %q", reasons, wantReasons)
+	}
+
+	// The kept rows pay with empty fields, never the literal "null"
+	for _, m := range want {
+		status, err := q.CheckPaymentStatusContext(ctx, "ORDER1", m.Amount)
+		if err != nil {
+			t.Fatalf("CheckPaymentStatus(%d): %v", m.Amount, err)
+		}
+		if status.Status != qris.StatusPaid || status.BrandName != "" || status.BuyerRef != "" {
+			t.Errorf("CheckPaymentStatus(%d) = %+v, want PAID with empty fields", m.Amount, status)
+		}
+		encoded, err := json.Marshal(status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(strings.ToLower(string(encoded)), `"null"`) || strings.Contains(string(encoded), "undefined") {
+			t.Errorf("status %d encodes a placeholder: %s", m.Amount, encoded)
+		}
+	}
+
+	// Skipped rows never pay, even for their own amount
+	status, err := q.CheckPaymentStatusContext(ctx, "ORDER1", 19000)
+	if err != nil {
+		t.Fatalf("CheckPaymentStatus(19000): %v", err)
+	}
+	if status.Status != qris.StatusUnpaid {
+		t.Errorf("CheckPaymentStatus(19000) = %s, want UNPAID", status.Status)
+	}
+}
+
+func TestProviderSkippedRows(t *testing.T) {
+	now := time.Now()
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.Provider = qris.MutationProviderFunc(func(ctx context.Context, query qris.MutationQuery) ([]qris.Mutation, error) {
+			return []qris.Mutation{
+				credit(15000, "OK"),
+				{Amount: 15000, Type: "CR", IssuerRef: "NODATE"},
+				{Amount: 15000, Date: "soon", Type: "CR", IssuerRef: "BADDATE"},
+				{Date: now.Format("2006-01-02 15:04:05"), Time: now, Type: "CR", IssuerRef: "NOAMOUNT"},
+			}, nil
+		})
+	})
+	mutations, err := q.GetMutations(testContext(t, 5*time.Second), qris.MutationQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutations) != 1 || mutations[0].IssuerRef != "OK" {
+		t.Fatalf("mutations = %+v, want only OK", mutations)
+	}
+	if got := q.SkippedRows(); got != 3 {
+		t.Errorf("SkippedRows = %d, want 3", got)
+	}
+}
@@ -0,0 +1,232 @@
+package qris_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// storeKey returns a test key of the invoice store filled with b.
+func storeKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, qris.EncryptionKeySize)
+}
+
+// writeStore saves one invoice INV-SECRET to a new store at path opened with opts.
+func writeStore(t *testing.T, path string, opts ...qris.JSONStoreOption) {
+	t.Helper()
+	store, err := qris.NewJSONFileStore(path, opts...)
+	if err != nil {
+		t.Fatalf("NewJSONFileStore: %v", err)
+	}
+	inv := qris.Invoice{TransactionID: "INV-SECRET", Amount: 25123, Status: qris.StatusUnpaid, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Save(testContext(t, 5*time.Second), inv); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+// readStore opens the store at path with opts and checks that it holds INV-SECRET.
+func readStore(t *testing.T, path string, opts ...qris.JSONStoreOption) error {
+	t.Helper()
+	store, err := qris.NewJSONFileStore(path, opts...)
+	if err != nil {
+		return err
+	}
+	inv, err := store.LoadInvoice(testContext(t, 5*time.Second), "INV-SECRET")
+	if err != nil || inv.Amount != 25123 {
+		t.Fatalf("LoadInvoice = %+v, %v, want INV-SECRET of 25123", inv, err)
+	}
+	return nil
+}
+
+func TestJSONFileStoreEncryption(t *testing.T) {
+	key1 := qris.WithEncryptionKey(storeKey(1))
+	key2 := qris.WithEncryptionKey(storeKey(2))
+	rotated := qris.WithKeyring(qris.StaticKeyring{storeKey(2), storeKey(1)})
+
+	tests := []struct {
+		name      string
+		write     []qris.JSONStoreOption
+		read      []qris.JSONStoreOption
+		encrypted bool
+		wantErr   error
+	}{
+		{name: "plaintext", encrypted: false},
+		{name: "same key", write: []qris.JSONStoreOption{key1}, read: []qris.JSONStoreOption{key1}, encrypted: true},
+		{name: "plaintext read with a key", read: []qris.JSONStoreOption{key1}, wantErr: qris.ErrDecryptionFailed},
+		{name: "plaintext allowed", read: []qris.JSONStoreOption{key1, qris.AllowPlaintext()}},
+		{name: "old key in the keyring", write: []qris.JSONStoreOption{key1}, read: []qris.JSONStoreOption{rotated}, encrypted: true},
+		{name: "new key first", write: []qris.JSONStoreOption{rotated}, read: []qris.JSONStoreOption{key2}, encrypted: true},
+		{name: "wrong key", write: []qris.JSONStoreOption{key1}, read: []qris.JSONStoreOption{key2}, encrypted: true, wantErr: qris.ErrDecryptionFailed},
+		{name: "rotated away", write: []qris.JSONStoreOption{rotated}, read: []qris.JSONStoreOption{key1}, encrypted: true, wantErr: qris.ErrDecryptionFailed},
+		{name: "no key", write: []qris.JSONStoreOption{key1}, encrypted: true, wantErr: qris.ErrDecryptionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "invoices.json")
+			writeStore(t, path, tt.write...)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if leaked := bytes.Contains(data, []byte("INV-SECRET")); leaked == tt.encrypted {
+				t.Fatalf("file holds the plaintext invoice = %v, want %v:\n%s", leaked, !tt.encrypted, data)
+			}
+
+			err = readStore(t, path, tt.read...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewJSONFileStore err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewJSONFileStore: %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONFileStoreEncryptsOnNextWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoices.json")
+	writeStore(t, path)
+	if _, err := qris.NewJSONFileStore(path, qris.WithEncryptionKey(storeKey(1))); !errors.Is(err, qris.ErrDecryptionFailed) {
+		t.Fatalf("keyed open of the plaintext file = %v, want ErrDecryptionFailed", err)
+	}
+	writeStore(t, path, qris.WithEncryptionKey(storeKey(1)), qris.AllowPlaintext())
+
+	if err := readStore(t, path); !errors.Is(err, qris.ErrDecryptionFailed) {
+		t.Fatalf("plaintext read after the keyed write = %v, want ErrDecryptionFailed", err)
+	}
+	if err := readStore(t, path, qris.WithEncryptionKey(storeKey(1))); err != nil {
+		t.Fatalf("keyed read: %v", err)
+	}
+}
+
+func TestJSONFileStoreInvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoices.json")
+	for _, key := range [][]byte{storeKey(1)[:16], append(storeKey(1), 0), {}} {
+		_, err := qris.NewJSONFileStore(path, qris.WithEncryptionKey(key))
+		if err == nil || errors.Is(err, qris.ErrDecryptionFailed) {
+			t.Errorf("NewJSONFileStore with a %d-byte key = %v, want a key size error", len(key), err)
+		}
+	}
+}
+
+func TestJSONFileStoreReencrypt(t *testing.T) {
+	tests := []struct {
+		name      string
+		write     []byte // key of the file, nil for plaintext / kunci file, nil untuk plaintext
+		old, new  []byte
+		wantErr   bool
+		readable  []byte // key that opens the file afterwards / kunci yang membuka file setelahnya
+		encrypted bool
+	}{
+		{name: "rotate", write: storeKey(1), old: storeKey(1), new: storeKey(2), readable: storeKey(2), encrypted: true},
+		{name: "encrypt", old: nil, new: storeKey(1), readable: storeKey(1), encrypted: true},
+		{name: "decrypt", write: storeKey(1), old: storeKey(1), new: nil, readable: nil},
+		{name: "wrong old key", write: storeKey(1), old: storeKey(3), new: storeKey(2), wantErr: true, readable: storeKey(1), encrypted: true},
+		{name: "plaintext expected", write: storeKey(1), old: nil, new: storeKey(2), wantErr: true, readable: storeKey(1), encrypted: true},
+		{name: "encrypted expected", old: storeKey(1), new: storeKey(2), wantErr: true, readable: nil},
+		{name: "invalid new key", write: storeKey(1), old: storeKey(1), new: storeKey(2)[:16], wantErr: true, readable: storeKey(1), encrypted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "invoices.json")
+			var opts []qris.JSONStoreOption
+			if tt.write != nil {
+				opts = append(opts, qris.WithEncryptionKey(tt.write))
+			}
+			writeStore(t, path, opts...)
+			store, err := qris.NewJSONFileStore(path, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = store.Reencrypt(tt.old, tt.new)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reencrypt err = %v, want error = %v", err, tt.wantErr)
+			}
+
+			// A failed Reencrypt leaves the file as it was
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if leaked := bytes.Contains(data, []byte("INV-SECRET")); leaked == tt.encrypted {
+				t.Fatalf("file holds the plaintext invoice = %v, want %v", leaked, !tt.encrypted)
+			}
+			var read []qris.JSONStoreOption
+			if tt.readable != nil {
+				read = append(read, qris.WithEncryptionKey(tt.readable))
+			}
+			if err := readStore(t, path, read...); err != nil {
+				t.Fatalf("reading the file afterwards: %v", err)
+			}
+			if !tt.wantErr && tt.old != nil && tt.new != nil {
+				if err := readStore(t, path, qris.WithEncryptionKey(tt.old)); !errors.Is(err, qris.ErrDecryptionFailed) {
+					t.Fatalf("old key after Reencrypt = %v, want ErrDecryptionFailed", err)
+				}
+			}
+		})
+	}
+}
+
+// switchKeyring is a Keyring whose keys and error can be changed, like one backed by a secret manager.
+type switchKeyring struct {
+	mu    sync.Mutex
+	keys  [][]byte
+	err   error
+	calls int
+}
+
+func (k *switchKeyring) Keys() ([][]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.calls++
+	return k.keys, k.err
+}
+
+func (k *switchKeyring) set(err error, keys ...[]byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys, k.err = keys, err
+}
+
+func TestJSONFileStoreReencryptKeepsKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoices.json")
+	kr := &switchKeyring{keys: [][]byte{storeKey(1)}}
+	writeStore(t, path, qris.WithKeyring(kr))
+	store, err := qris.NewJSONFileStore(path, qris.WithKeyring(kr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reencrypt(storeKey(1), storeKey(2)); err != nil {
+		t.Fatalf("Reencrypt: %v", err)
+	}
+
+	// New writes seal with the new key while the keyring is still asked
+	calls := kr.calls
+	inv := qris.Invoice{TransactionID: "INV-2", Amount: 30000, Status: qris.StatusUnpaid, CreatedAt: time.Now()}
+	if err := store.Save(testContext(t, 5*time.Second), inv); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if kr.calls == calls {
+		t.Fatal("Save after Reencrypt did not ask the keyring")
+	}
+	if err := readStore(t, path, qris.WithEncryptionKey(storeKey(2))); err != nil {
+		t.Fatalf("new key after Reencrypt: %v", err)
+	}
+
+	errSecrets := errors.New("secret manager down")
+	kr.set(errSecrets)
+	if err := store.Save(testContext(t, 5*time.Second), inv); !errors.Is(err, errSecrets) {
+		t.Fatalf("Save with a failing keyring = %v, want %v", err, errSecrets)
+	}
+}
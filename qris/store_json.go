@@ -0,0 +1,343 @@
+package qris
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/sealed"
+)
+
+// JSONFileStore is an InvoiceStore that keeps all invoices in a single JSON file.
+// JSONFileStore adalah InvoiceStore yang menyimpan semua invoice dalam satu file JSON.
+//
+// The file is rewritten atomically on every change, which suits bots with a modest number of invoices.
+// File ditulis ulang secara atomik pada setiap perubahan, cocok untuk bot dengan jumlah invoice sedang.
+type JSONFileStore struct {
+	path string
+
+	allowPlaintext bool
+
+	mu       sync.Mutex
+	keyring  Keyring
+	invoices map[string]Invoice
+}
+
+// JSONStoreOption configures a JSONFileStore.
+// JSONStoreOption mengatur JSONFileStore.
+type JSONStoreOption func(*JSONFileStore)
+
+// WithEncryptionKey encrypts the store file with AES-256-GCM under key, which must be
+// EncryptionKeySize bytes long. A plaintext file written without a key only loads with AllowPlaintext.
+// WithEncryptionKey mengenkripsi file store dengan AES-256-GCM memakai key, yang panjangnya harus
+// EncryptionKeySize byte. File plaintext yang ditulis tanpa kunci hanya bisa dimuat dengan AllowPlaintext.
+func WithEncryptionKey(key []byte) JSONStoreOption {
+	return WithKeyring(StaticKeyring{key})
+}
+
+// WithKeyring encrypts the store file with the keys of kr (see Keyring), asked on every read and write.
+// WithKeyring mengenkripsi file store dengan kunci dari kr (lihat Keyring), yang diminta pada setiap baca dan tulis.
+func WithKeyring(kr Keyring) JSONStoreOption {
+	return func(s *JSONFileStore) {
+		s.keyring = kr
+	}
+}
+
+// AllowPlaintext lets an encrypted store load a plaintext file, which is encrypted on the next write.
+// Without it a plaintext file fails with ErrDecryptionFailed once a key is set, so a file swapped
+// for a forged plaintext one is not trusted. Use it once to encrypt an existing store.
+// AllowPlaintext mengizinkan store terenkripsi memuat file plaintext, yang dienkripsi pada penulisan
+// berikutnya. Tanpa opsi ini file plaintext gagal dengan ErrDecryptionFailed jika kunci diatur, sehingga
+// file yang ditukar dengan file plaintext palsu tidak dipercaya. Pakai sekali untuk mengenkripsi store yang ada.
+func AllowPlaintext() JSONStoreOption {
+	return func(s *JSONFileStore) {
+		s.allowPlaintext = true
+	}
+}
+
+// NewJSONFileStore opens the store at path, loading existing invoices if the file exists.
+// NewJSONFileStore membuka store di path, memuat invoice yang ada jika file sudah ada.
+//
+// An encrypted file fails to load with ErrDecryptionFailed when the key is wrong or missing.
+// File terenkripsi gagal dimuat dengan ErrDecryptionFailed jika kuncinya salah atau tidak ada.
+func NewJSONFileStore(path string, opts ...JSONStoreOption) (*JSONFileStore, error) {
+	s := &JSONFileStore{
+		path:     path,
+		invoices: make(map[string]Invoice),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	keys, err := sealed.KeysOf(s.keyring)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice store key / kunci invoice store tidak valid: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invoice store / gagal membaca invoice store: %v", err)
+	}
+	if len(keys) > 0 && !sealed.IsSealed(data) {
+		if !s.allowPlaintext {
+			return nil, fmt.Errorf("%w: invoice store is not encrypted, see AllowPlaintext / invoice store tidak terenkripsi, lihat AllowPlaintext", ErrDecryptionFailed)
+		}
+		logf(context.Background(), "Invoice store %s is not encrypted, it is encrypted on the next write", path)
+	}
+	if data, err = sealed.Decode(keys, data); err != nil {
+		return nil, fmt.Errorf("failed to open invoice store / gagal membuka invoice store: %w", err)
+	}
+
+	var invoices []Invoice
+	if err := json.Unmarshal(data, &invoices); err != nil {
+		return nil, fmt.Errorf("failed to parse invoice store / gagal parse invoice store: %v", err)
+	}
+	for _, inv := range invoices {
+		s.invoices[inv.TransactionID] = inv
+	}
+	return s, nil
+}
+
+// Save inserts or replaces an invoice.
+// Save menambahkan atau mengganti invoice.
+func (s *JSONFileStore) Save(ctx context.Context, inv Invoice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.invoices[inv.TransactionID] = inv
+	return s.flushLocked()
+}
+
+// LoadPending returns all UNPAID invoices ordered by creation time.
+// LoadPending mengembalikan semua invoice UNPAID berurutan sesuai waktu pembuatan.
+func (s *JSONFileStore) LoadPending(ctx context.Context) ([]Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Invoice
+	for _, inv := range s.invoices {
+		if inv.Status == StatusUnpaid {
+			pending = append(pending, inv)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	return pending, nil
+}
+
+// LoadInvoice implements InvoiceLoader.
+// LoadInvoice mengimplementasikan InvoiceLoader.
+func (s *JSONFileStore) LoadInvoice(ctx context.Context, transactionID string) (Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[transactionID]
+	if !ok {
+		return Invoice{}, fmt.Errorf("%w: invoice %s", ErrNotFound, transactionID)
+	}
+	return inv, nil
+}
+
+// MarkPaid marks an invoice as PAID with the matched payment.
+// MarkPaid menandai invoice sebagai PAID beserta pembayaran yang cocok.
+func (s *JSONFileStore) MarkPaid(ctx context.Context, transactionID string, payment *PaymentStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[transactionID]
+	if !ok {
+		return fmt.Errorf("invoice %s not found / invoice %s tidak ditemukan", transactionID, transactionID)
+	}
+	if inv.Status == StatusPaid {
+		return nil
+	}
+	inv.Status = StatusPaid
+	inv.Payment = payment
+	s.invoices[transactionID] = inv
+	return s.flushLocked()
+}
+
+// MarkExpired marks an invoice as EXPIRED.
+// MarkExpired menandai invoice sebagai EXPIRED.
+func (s *JSONFileStore) MarkExpired(ctx context.Context, transactionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[transactionID]
+	if !ok {
+		return fmt.Errorf("invoice %s not found / invoice %s tidak ditemukan", transactionID, transactionID)
+	}
+	if inv.Status != StatusUnpaid {
+		return nil
+	}
+	inv.Status = StatusExpired
+	s.invoices[transactionID] = inv
+	return s.flushLocked()
+}
+
+// ClaimMutation returns the PAID invoice whose payment is the mutation with key, or transactionID
+// if there is none. The claim itself is recorded by MarkPaid.
+// ClaimMutation mengembalikan invoice PAID yang pembayarannya adalah mutasi dengan key, atau
+// transactionID jika tidak ada. Klaimnya sendiri dicatat oleh MarkPaid.
+func (s *JSONFileStore) ClaimMutation(ctx context.Context, key, transactionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range s.invoices {
+		if inv.Status == StatusPaid && inv.Payment != nil && inv.TransactionID != transactionID &&
+			MutationKey(inv.Payment.Reference, inv.Payment.Date) == key {
+			return inv.TransactionID, nil
+		}
+	}
+	return transactionID, nil
+}
+
+// ReserveCode reports whether code is free. The code is recorded when the invoice carrying it is saved.
+// ReserveCode melaporkan apakah code belum dipakai. Kode dicatat saat invoice yang membawanya disimpan.
+func (s *JSONFileStore) ReserveCode(ctx context.Context, code, transactionID string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range s.invoices {
+		if inv.Code == code && inv.TransactionID != transactionID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// LookupCode returns the invoice carrying code, or ErrNotFound.
+// LookupCode mengembalikan invoice yang membawa code, atau ErrNotFound.
+func (s *JSONFileStore) LookupCode(ctx context.Context, code string) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range s.invoices {
+		if inv.Code == code {
+			return inv.TransactionID, inv.ExpiresAt, nil
+		}
+	}
+	return "", time.Time{}, ErrNotFound
+}
+
+// flushLocked writes all invoices to a temporary file and renames it over the store file.
+// flushLocked menulis semua invoice ke file sementara lalu mengganti file store dengannya.
+func (s *JSONFileStore) flushLocked() error {
+	invoices := make([]Invoice, 0, len(s.invoices))
+	for _, inv := range s.invoices {
+		invoices = append(invoices, inv)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].CreatedAt.Before(invoices[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(invoices, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoices / gagal marshal invoice: %v", err)
+	}
+	keys, err := sealed.KeysOf(s.keyring)
+	if err != nil {
+		return fmt.Errorf("invalid invoice store key / kunci invoice store tidak valid: %w", err)
+	}
+	if data, err = sealed.Encode(keys, data); err != nil {
+		return fmt.Errorf("failed to encrypt invoice store / gagal mengenkripsi invoice store: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write invoice store / gagal menulis invoice store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write invoice store / gagal menulis invoice store: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write invoice store / gagal menulis invoice store: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write invoice store / gagal menulis invoice store: %v", err)
+	}
+	return nil
+}
+
+// Reencrypt rewrites the store file under newKey after checking that the file on disk opens with
+// oldKey. A nil oldKey expects a plaintext file and a nil newKey writes plaintext.
+// Reencrypt menulis ulang file store dengan newKey setelah memastikan file di disk dapat dibuka dengan
+// oldKey. oldKey nil mengharapkan file plaintext dan newKey nil menulis plaintext.
+//
+// Later writes seal with newKey until the next Reencrypt, even if the Keyring given with WithKeyring
+// lists another key first; the Keyring is kept and still asked on every write. A nil newKey drops it.
+// Penulisan berikutnya menyegel dengan newKey sampai Reencrypt berikutnya, meskipun Keyring dari
+// WithKeyring menaruh kunci lain lebih dulu; Keyring tetap disimpan dan tetap diminta pada setiap
+// penulisan. newKey nil membuangnya.
+func (s *JSONFileStore) Reencrypt(oldKey, newKey []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var oldKeys StaticKeyring
+	if oldKey != nil {
+		oldKeys = StaticKeyring{oldKey}
+	}
+	var keyring Keyring
+	if newKey != nil {
+		base := s.keyring
+		if k, ok := base.(sealingKeyring); ok {
+			base = k.keyring
+		}
+		keyring = sealingKeyring{key: newKey, keyring: base}
+	}
+	if _, err := sealed.KeysOf(keyring); err != nil {
+		return fmt.Errorf("invalid invoice store key / kunci invoice store tidak valid: %w", err)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read invoice store / gagal membaca invoice store: %v", err)
+	}
+	if err == nil {
+		if sealed.IsSealed(data) {
+			_, err = sealed.Open(oldKeys, data)
+		} else if oldKey != nil {
+			err = fmt.Errorf("%w: invoice store is not encrypted / invoice store tidak terenkripsi", ErrDecryptionFailed)
+		}
+		if err != nil {
+			return fmt.Errorf("old key does not open the invoice store / kunci lama tidak dapat membuka invoice store: %w", err)
+		}
+	}
+
+	s.keyring = keyring
+	return s.flushLocked()
+}
+
+// sealingKeyring puts key before the keys of keyring, so it seals every write.
+// sealingKeyring menaruh key sebelum kunci dari keyring, sehingga key menyegel setiap penulisan.
+type sealingKeyring struct {
+	key     []byte
+	keyring Keyring
+}
+
+func (k sealingKeyring) Keys() ([][]byte, error) {
+	keys, err := sealed.KeysOf(k.keyring)
+	if err != nil {
+		return nil, err
+	}
+	out := [][]byte{k.key}
+	for _, key := range keys {
+		if !bytes.Equal(key, k.key) {
+			out = append(out, key)
+		}
+	}
+	return out, nil
+}
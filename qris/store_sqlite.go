@@ -0,0 +1,328 @@
+package qris
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sqliteTimeLayout stores times in UTC with fixed width so they sort correctly as text.
+// sqliteTimeLayout menyimpan waktu dalam UTC dengan lebar tetap agar terurut dengan benar sebagai teks.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SQLiteStore is an InvoiceStore backed by a SQLite database.
+// SQLiteStore adalah InvoiceStore yang memakai database SQLite.
+//
+// The caller opens the *sql.DB with the SQLite driver of their choice (for example
+// modernc.org/sqlite or github.com/mattn/go-sqlite3), so this package stays free of cgo and extra dependencies.
+// Pemanggil membuka *sql.DB dengan driver SQLite pilihannya (misalnya
+// modernc.org/sqlite atau github.com/mattn/go-sqlite3), sehingga paket ini tetap bebas cgo dan dependensi tambahan.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the qris_invoices, qris_invoice_metadata, qris_mutation_claims, qris_payment_codes, and
+// qris_backfill tables if needed and returns the store.
+// NewSQLiteStore membuat tabel qris_invoices, qris_invoice_metadata, qris_mutation_claims, qris_payment_codes, dan
+// qris_backfill jika belum ada dan mengembalikan store.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS qris_invoices (
+		transaction_id TEXT PRIMARY KEY,
+		merchant       TEXT NOT NULL DEFAULT '',
+		base_amount    INTEGER NOT NULL,
+		amount         INTEGER NOT NULL,
+		qr_string      TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		created_at     TEXT NOT NULL,
+		expires_at     TEXT NOT NULL,
+		paid_reference TEXT NOT NULL DEFAULT '',
+		paid_amount    INTEGER NOT NULL DEFAULT 0,
+		paid_date      TEXT NOT NULL DEFAULT '',
+		brand_name     TEXT NOT NULL DEFAULT '',
+		buyer_ref      TEXT NOT NULL DEFAULT ''
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice table / gagal membuat tabel invoice: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS qris_invoice_metadata (
+		transaction_id TEXT PRIMARY KEY,
+		metadata       TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata table / gagal membuat tabel metadata: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS qris_mutation_claims (
+		mutation_key   TEXT PRIMARY KEY,
+		transaction_id TEXT NOT NULL,
+		claimed_at     TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create claim table / gagal membuat tabel klaim: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS qris_payment_codes (
+		code           TEXT PRIMARY KEY,
+		transaction_id TEXT NOT NULL,
+		expires_at     TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create code table / gagal membuat tabel kode: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS qris_backfill (
+		id      INTEGER PRIMARY KEY CHECK (id = 1),
+		through TEXT NOT NULL DEFAULT '',
+		cutoff  TEXT NOT NULL DEFAULT '',
+		page    INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backfill table / gagal membuat tabel backfill: %v", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Save inserts or replaces an invoice.
+// Save menambahkan atau mengganti invoice.
+func (s *SQLiteStore) Save(ctx context.Context, inv Invoice) error {
+	var p PaymentStatus
+	if inv.Payment != nil {
+		p = *inv.Payment
+	}
+
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO qris_invoices
+		(transaction_id, merchant, base_amount, amount, qr_string, status, created_at, expires_at,
+		 paid_reference, paid_amount, paid_date, brand_name, buyer_ref)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		inv.TransactionID, inv.Merchant, inv.BaseAmount, inv.Amount, inv.QRString, inv.Status,
+		inv.CreatedAt.UTC().Format(sqliteTimeLayout), inv.ExpiresAt.UTC().Format(sqliteTimeLayout),
+		p.Reference, p.Amount, p.Date, p.BrandName, p.BuyerRef)
+	if err != nil {
+		return fmt.Errorf("failed to save invoice / gagal menyimpan invoice: %v", err)
+	}
+
+	if len(inv.Metadata) > 0 {
+		md, err := json.Marshal(inv.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata / gagal marshal metadata: %v", err)
+		}
+		_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO qris_invoice_metadata (transaction_id, metadata) VALUES (?, ?)`,
+			inv.TransactionID, string(md))
+		if err != nil {
+			return fmt.Errorf("failed to save metadata / gagal menyimpan metadata: %v", err)
+		}
+	}
+	return nil
+}
+
+// sqliteInvoiceQuery selects the invoices of scanInvoices; append a WHERE clause.
+// sqliteInvoiceQuery memilih invoice untuk scanInvoices; tambahkan klausa WHERE.
+const sqliteInvoiceQuery = `SELECT i.transaction_id, i.merchant, i.base_amount, i.amount, i.qr_string, i.status, i.created_at, i.expires_at,
+		COALESCE((SELECT c.code FROM qris_payment_codes c WHERE c.transaction_id = i.transaction_id LIMIT 1), ''),
+		COALESCE((SELECT m.metadata FROM qris_invoice_metadata m WHERE m.transaction_id = i.transaction_id), ''),
+		i.paid_reference, i.paid_amount, i.paid_date, i.brand_name, i.buyer_ref
+		FROM qris_invoices i `
+
+// LoadPending returns all UNPAID invoices ordered by creation time.
+// LoadPending mengembalikan semua invoice UNPAID berurutan sesuai waktu pembuatan.
+func (s *SQLiteStore) LoadPending(ctx context.Context) ([]Invoice, error) {
+	rows, err := s.db.QueryContext(ctx, sqliteInvoiceQuery+`WHERE i.status = ? ORDER BY i.created_at`, StatusUnpaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices / gagal memuat invoice: %v", err)
+	}
+	defer rows.Close()
+
+	return scanInvoices(rows)
+}
+
+// LoadInvoice implements InvoiceLoader.
+// LoadInvoice mengimplementasikan InvoiceLoader.
+func (s *SQLiteStore) LoadInvoice(ctx context.Context, transactionID string) (Invoice, error) {
+	rows, err := s.db.QueryContext(ctx, sqliteInvoiceQuery+`WHERE i.transaction_id = ?`, transactionID)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("failed to load invoices / gagal memuat invoice: %v", err)
+	}
+	defer rows.Close()
+
+	invoices, err := scanInvoices(rows)
+	if err != nil {
+		return Invoice{}, err
+	}
+	if len(invoices) == 0 {
+		return Invoice{}, fmt.Errorf("%w: invoice %s", ErrNotFound, transactionID)
+	}
+	return invoices[0], nil
+}
+
+// scanInvoices reads the rows of sqliteInvoiceQuery.
+// scanInvoices membaca baris dari sqliteInvoiceQuery.
+func scanInvoices(rows *sql.Rows) ([]Invoice, error) {
+	var invoices []Invoice
+	for rows.Next() {
+		var inv Invoice
+		var p PaymentStatus
+		var createdAt, expiresAt, metadata string
+		if err := rows.Scan(&inv.TransactionID, &inv.Merchant, &inv.BaseAmount, &inv.Amount, &inv.QRString, &inv.Status, &createdAt, &expiresAt, &inv.Code, &metadata,
+			&p.Reference, &p.Amount, &p.Date, &p.BrandName, &p.BuyerRef); err != nil {
+			return nil, fmt.Errorf("failed to load invoices / gagal memuat invoice: %v", err)
+		}
+		var err error
+		if inv.CreatedAt, err = time.Parse(sqliteTimeLayout, createdAt); err != nil {
+			return nil, fmt.Errorf("invalid created_at for invoice %s / created_at invoice %s tidak valid: %v", inv.TransactionID, inv.TransactionID, err)
+		}
+		if inv.ExpiresAt, err = time.Parse(sqliteTimeLayout, expiresAt); err != nil {
+			return nil, fmt.Errorf("invalid expires_at for invoice %s / expires_at invoice %s tidak valid: %v", inv.TransactionID, inv.TransactionID, err)
+		}
+		if metadata != "" {
+			if err := json.Unmarshal([]byte(metadata), &inv.Metadata); err != nil {
+				return nil, fmt.Errorf("invalid metadata for invoice %s / metadata invoice %s tidak valid: %v", inv.TransactionID, inv.TransactionID, err)
+			}
+		}
+		if inv.Status == StatusPaid {
+			p.Status = StatusPaid
+			p.Issuer = NormalizeIssuer(p.BrandName)
+			inv.Payment = &p
+		}
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load invoices / gagal memuat invoice: %v", err)
+	}
+	return invoices, nil
+}
+
+// MarkPaid marks an invoice as PAID with the matched payment.
+// MarkPaid menandai invoice sebagai PAID beserta pembayaran yang cocok.
+func (s *SQLiteStore) MarkPaid(ctx context.Context, transactionID string, payment *PaymentStatus) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE qris_invoices
+		SET status = ?, paid_reference = ?, paid_amount = ?, paid_date = ?, brand_name = ?, buyer_ref = ?
+		WHERE transaction_id = ? AND status != ?`,
+		StatusPaid, payment.Reference, payment.Amount, payment.Date, payment.BrandName, payment.BuyerRef, transactionID, StatusPaid)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice / gagal memperbarui invoice: %v", err)
+	}
+	return nil
+}
+
+// MarkExpired marks an invoice as EXPIRED.
+// MarkExpired menandai invoice sebagai EXPIRED.
+func (s *SQLiteStore) MarkExpired(ctx context.Context, transactionID string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE qris_invoices SET status = ? WHERE transaction_id = ? AND status = ?`, StatusExpired, transactionID, StatusUnpaid)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice / gagal memperbarui invoice: %v", err)
+	}
+	return nil
+}
+
+// ClaimMutation records transactionID as the invoice paid by the mutation with key, unless another
+// invoice claimed it first, and returns the owner of the claim. Claims older than DefaultClaimTTL are
+// removed on the way.
+// ClaimMutation mencatat transactionID sebagai invoice yang dibayar mutasi dengan key, kecuali invoice
+// lain mengklaimnya lebih dulu, dan mengembalikan pemilik klaim. Klaim yang lebih tua dari DefaultClaimTTL
+// dihapus sekaligus.
+func (s *SQLiteStore) ClaimMutation(ctx context.Context, key, transactionID string) (string, error) {
+	now := time.Now().UTC()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM qris_mutation_claims WHERE claimed_at < ?`,
+		now.Add(-DefaultClaimTTL).Format(sqliteTimeLayout)); err != nil {
+		return "", fmt.Errorf("failed to prune claims / gagal membersihkan klaim: %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO qris_mutation_claims (mutation_key, transaction_id, claimed_at) VALUES (?, ?, ?)`,
+		key, transactionID, now.Format(sqliteTimeLayout)); err != nil {
+		return "", fmt.Errorf("failed to claim mutation / gagal mengklaim mutasi: %v", err)
+	}
+
+	var owner string
+	if err := s.db.QueryRowContext(ctx, `SELECT transaction_id FROM qris_mutation_claims WHERE mutation_key = ?`, key).Scan(&owner); err != nil {
+		return "", fmt.Errorf("failed to read claim / gagal membaca klaim: %v", err)
+	}
+	return owner, nil
+}
+
+// ReserveCode records code for transactionID unless it is taken. Codes expired longer than a day ago
+// are removed on the way.
+// ReserveCode mencatat code untuk transactionID kecuali sudah dipakai. Kode yang kedaluwarsa lebih dari
+// sehari yang lalu dihapus sekaligus.
+func (s *SQLiteStore) ReserveCode(ctx context.Context, code, transactionID string, expiresAt time.Time) (bool, error) {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM qris_payment_codes WHERE expires_at < ?`,
+		time.Now().UTC().Add(-codeRetention).Format(sqliteTimeLayout)); err != nil {
+		return false, fmt.Errorf("failed to prune codes / gagal membersihkan kode: %v", err)
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO qris_payment_codes (code, transaction_id, expires_at) VALUES (?, ?, ?)`,
+		code, transactionID, expiresAt.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve code / gagal memesan kode: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve code / gagal memesan kode: %v", err)
+	}
+	return n == 1, nil
+}
+
+// LookupCode returns the invoice of code, or ErrNotFound.
+// LookupCode mengembalikan invoice dari code, atau ErrNotFound.
+func (s *SQLiteStore) LookupCode(ctx context.Context, code string) (string, time.Time, error) {
+	var transactionID, expiresAt string
+	err := s.db.QueryRowContext(ctx, `SELECT transaction_id, expires_at FROM qris_payment_codes WHERE code = ?`, code).Scan(&transactionID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read code / gagal membaca kode: %v", err)
+	}
+	t, err := time.Parse(sqliteTimeLayout, expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid expires_at for code %s / expires_at kode %s tidak valid: %v", code, code, err)
+	}
+	return transactionID, t, nil
+}
+
+// LoadBackfillCheckpoint returns the checkpoint of BackfillClaims, or a zero one before the first run.
+// LoadBackfillCheckpoint mengembalikan checkpoint BackfillClaims, atau checkpoint nol sebelum proses pertama.
+func (s *SQLiteStore) LoadBackfillCheckpoint(ctx context.Context) (BackfillCheckpoint, error) {
+	var cp BackfillCheckpoint
+	var through, cutoff string
+	err := s.db.QueryRowContext(ctx, `SELECT through, cutoff, page FROM qris_backfill WHERE id = 1`).Scan(&through, &cutoff, &cp.Page)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("failed to read backfill checkpoint / gagal membaca checkpoint backfill: %v", err)
+	}
+	if cp.Through, err = parseSQLiteTime(through); err != nil {
+		return cp, fmt.Errorf("invalid backfill through / through backfill tidak valid: %v", err)
+	}
+	if cp.Cutoff, err = parseSQLiteTime(cutoff); err != nil {
+		return cp, fmt.Errorf("invalid backfill cutoff / cutoff backfill tidak valid: %v", err)
+	}
+	return cp, nil
+}
+
+// SaveBackfillCheckpoint replaces the checkpoint of BackfillClaims.
+// SaveBackfillCheckpoint mengganti checkpoint BackfillClaims.
+func (s *SQLiteStore) SaveBackfillCheckpoint(ctx context.Context, cp BackfillCheckpoint) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO qris_backfill (id, through, cutoff, page) VALUES (1, ?, ?, ?)`,
+		formatSQLiteTime(cp.Through), formatSQLiteTime(cp.Cutoff), cp.Page)
+	if err != nil {
+		return fmt.Errorf("failed to save backfill checkpoint / gagal menyimpan checkpoint backfill: %v", err)
+	}
+	return nil
+}
+
+// formatSQLiteTime formats t with sqliteTimeLayout, or as "" if t is zero.
+// formatSQLiteTime memformat t dengan sqliteTimeLayout, atau sebagai "" jika t nol.
+func formatSQLiteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// parseSQLiteTime parses a time written by formatSQLiteTime.
+// parseSQLiteTime mem-parse waktu yang ditulis oleh formatSQLiteTime.
+func parseSQLiteTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(sqliteTimeLayout, s)
+}
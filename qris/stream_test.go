@@ -0,0 +1,117 @@
+package qris_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// largeResponse returns a successful mutation response of n credits, newest first, whose newest
+// row pays newest.
+func largeResponse(tb testing.TB, n int, newest int64) []byte {
+	tb.Helper()
+	now := time.Now().In(qris.WIB)
+	data := make([]map[string]string, n)
+	for i := range data {
+		amount := int64(10000 + i)
+		if i == 0 {
+			amount = newest
+		}
+		data[i] = map[string]string{
+			"amount":      fmt.Sprint(amount),
+			"date":        now.Add(-time.Duration(i) * 100 * time.Millisecond).Format("2006-01-02 15:04:05"),
+			"qris":        "static",
+			"type":        "CR",
+			"issuer_reff": fmt.Sprintf("REF%05d", i),
+			"brand_name":  "DANA",
+			"buyer_reff":  "",
+		}
+	}
+	// Status first, like the real gateway
+	body, err := json.Marshal(struct {
+		Status     string              `json:"status"`
+		TotalPages int                 `json:"total_pages"`
+		Data       []map[string]string `json:"data"`
+	}{"success", 1, data})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return body
+}
+
+func TestLargeMutationResponse(t *testing.T) {
+	const n = 10000
+	body := largeResponse(t, n, 99999)
+	srv := staticGateway(t, body)
+
+	var read atomic.Int64
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.GatewayURL = srv.URL
+		c.OnResponseSize = func(bytes int64) { read.Store(bytes) }
+	})
+	ctx := testContext(t, 10*time.Second)
+
+	mutations, err := q.GetMutations(ctx, qris.MutationQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutations) != n {
+		t.Fatalf("got %d mutations, want %d", len(mutations), n)
+	}
+	if last := mutations[n-1]; last.IssuerRef != fmt.Sprintf("REF%05d", n-1) || last.Amount != int64(10000+n-1) {
+		t.Errorf("last mutation %s amount %d, want REF%05d amount %d", last.IssuerRef, last.Amount, n-1, 10000+n-1)
+	}
+	if got := read.Load(); got != int64(len(body)) {
+		t.Errorf("GetMutations read %d bytes, want the whole body of %d", got, len(body))
+	}
+
+	// The match is the first row, so the rest of the body is never decoded
+	status, err := q.CheckPaymentStatusContext(ctx, "ORDER1", 99999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != qris.StatusPaid || status.Reference != "REF00000" {
+		t.Fatalf("status %s reference %s, want PAID REF00000", status.Status, status.Reference)
+	}
+	if got := read.Load(); got >= int64(len(body))/10 {
+		t.Errorf("CheckPaymentStatus read %d of %d bytes, want it to stop early", got, len(body))
+	}
+}
+
+func BenchmarkMutations10k(b *testing.B) {
+	body := largeResponse(b, 10000, 99999)
+	srv := staticGateway(b, body)
+	q := newTestQRIS(b, newGateway(b), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+	ctx := testContext(b, time.Minute)
+
+	b.Run("GetMutations", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(body)))
+		for i := 0; i < b.N; i++ {
+			if _, err := q.GetMutations(ctx, qris.MutationQuery{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("CheckPaymentStatus", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := q.CheckPaymentStatusContext(ctx, "ORDER1", 99999); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("CheckPaymentStatus unpaid", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(body)))
+		for i := 0; i < b.N; i++ {
+			if _, err := q.CheckPaymentStatusContext(ctx, "ORDER2", 5000); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package qris
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Summary is the settlement summary of one day of credits, see DailySummary.
+// Summary adalah ringkasan settlement dari kredit satu hari, lihat DailySummary.
+type Summary struct {
+	Day      string              `json:"day"`      // Day as YYYY-MM-DD / Hari dalam format YYYY-MM-DD
+	Timezone string              `json:"timezone"` // Timezone the day is taken in / Zona waktu hari tersebut
+	Total    int64               `json:"total"`    // Sum of credited amounts / Jumlah nominal kredit
+	Count    int                 `json:"count"`    // Number of credits / Jumlah kredit
+	Brands   []BrandTotal        `json:"brands"`   // Per canonical issuer, largest total first / Per issuer kanonis, total terbesar lebih dulu
+	Largest  *SummaryTransaction `json:"largest,omitempty"`
+	Smallest *SummaryTransaction `json:"smallest,omitempty"`
+	Hourly   [24]int             `json:"hourly"` // Credits per hour of the day / Kredit per jam dalam hari tersebut
+}
+
+// BrandTotal is the share of one payer issuer in a Summary. Brand is the canonical Issuer, so
+// "BCA" and "PT BANK CENTRAL ASIA" are counted together.
+// BrandTotal adalah bagian satu issuer pembayar dalam Summary. Brand adalah Issuer kanonis, sehingga
+// "BCA" dan "PT BANK CENTRAL ASIA" dihitung bersama.
+type BrandTotal struct {
+	Brand  Issuer `json:"brand"`
+	Count  int    `json:"count"`
+	Amount int64  `json:"amount"`
+}
+
+// SummaryTransaction identifies a credit in a Summary.
+// SummaryTransaction mengidentifikasi sebuah kredit dalam Summary.
+type SummaryTransaction struct {
+	Amount    int64     `json:"amount"`
+	Date      string    `json:"date"`
+	BrandName string    `json:"brand_name"`
+	Issuer    Issuer    `json:"issuer"`
+	IssuerRef string    `json:"issuer_reff"`
+	PaidAt    time.Time `json:"paid_at"` // Date in the summary timezone / Date dalam zona waktu ringkasan
+}
+
+// DailySummary totals the credits of the calendar day of day in the configured timezone:
+// total amount, count, per-issuer breakdown, largest and smallest credit, and credits per hour.
+// DailySummary menjumlahkan kredit pada hari kalender dari day dalam zona waktu yang dikonfigurasi:
+// total nominal, jumlah, rincian per issuer, kredit terbesar dan terkecil, dan kredit per jam.
+func (q *QRIS) DailySummary(ctx context.Context, day time.Time) (*Summary, error) {
+	loc := q.location()
+	y, m, d := day.In(loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	s := &Summary{Day: start.Format("2006-01-02"), Timezone: loc.String(), Brands: []BrandTotal{}}
+	brands := make(map[Issuer]*BrandTotal)
+	query := MutationQuery{From: start, To: start.AddDate(0, 0, 1), Type: "CR"}
+	err := q.eachAllMutations(ctx, query, func(m Mutation) error {
+		s.Total += m.Amount
+		s.Count++
+		s.Hourly[m.Time.In(loc).Hour()]++
+
+		brand := m.Issuer
+		if brand == "" {
+			brand = NormalizeIssuer(m.BrandName)
+		}
+		b, ok := brands[brand]
+		if !ok {
+			b = &BrandTotal{Brand: brand}
+			brands[brand] = b
+		}
+		b.Count++
+		b.Amount += m.Amount
+
+		tx := &SummaryTransaction{Amount: m.Amount, Date: m.Date, BrandName: m.BrandName, Issuer: brand, IssuerRef: m.IssuerRef, PaidAt: m.Time.In(loc)}
+		if s.Largest == nil || m.Amount > s.Largest.Amount {
+			s.Largest = tx
+		}
+		if s.Smallest == nil || m.Amount < s.Smallest.Amount {
+			s.Smallest = tx
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range brands {
+		s.Brands = append(s.Brands, *b)
+	}
+	sort.Slice(s.Brands, func(i, j int) bool {
+		if s.Brands[i].Amount != s.Brands[j].Amount {
+			return s.Brands[i].Amount > s.Brands[j].Amount
+		}
+		return s.Brands[i].Brand < s.Brands[j].Brand
+	})
+	return s, nil
+}
+
+// String renders the summary as text tables, suitable for a terminal.
+// String menampilkan ringkasan sebagai tabel teks, cocok untuk terminal.
+func (s *Summary) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Summary %s (%s)\n", s.Day, s.Timezone)
+	fmt.Fprintf(&buf, "Total:    %s (%d transactions)\n", FormatIDR(s.Total), s.Count)
+	if s.Largest != nil {
+		fmt.Fprintf(&buf, "Largest:  %s %s %s\n", FormatIDR(s.Largest.Amount), s.Largest.BrandName, s.Largest.Date)
+		fmt.Fprintf(&buf, "Smallest: %s %s %s\n", FormatIDR(s.Smallest.Amount), s.Smallest.BrandName, s.Smallest.Date)
+	}
+
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if len(s.Brands) > 0 {
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(tw, "ISSUER\tCOUNT\tAMOUNT")
+		for _, b := range s.Brands {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", b.Brand, b.Count, FormatIDR(b.Amount))
+		}
+		tw.Flush()
+	}
+	if s.Count > 0 {
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(tw, "HOUR\tCOUNT")
+		for h, n := range s.Hourly {
+			if n > 0 {
+				fmt.Fprintf(tw, "%02d:00\t%d\n", h, n)
+			}
+		}
+		tw.Flush()
+	}
+	return buf.String()
+}
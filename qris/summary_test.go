@@ -0,0 +1,123 @@
+package qris_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// dayBoundaryQRIS returns an instance reading testdata/summary/day_boundary.json, whose REF2 was
+// paid on 2 May in WIB but on 1 May in UTC.
+func dayBoundaryQRIS(t *testing.T, configure ...func(*qris.QRISConfig)) *qris.QRIS {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "summary", "day_boundary.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := staticGateway(t, body)
+	return newTestQRIS(t, newGateway(t), append([]func(*qris.QRISConfig){func(c *qris.QRISConfig) { c.GatewayURL = srv.URL }}, configure...)...)
+}
+
+func TestDailySummaryTimezone(t *testing.T) {
+	q := dayBoundaryQRIS(t)
+	tests := []struct {
+		name  string
+		day   time.Time
+		want  string
+		total int64
+		refs  []string // largest, then smallest / terbesar, lalu terkecil
+		hours []int
+	}{
+		{name: "WIB day", day: time.Date(2024, 5, 2, 12, 0, 0, 0, qris.WIB), want: "2024-05-02", total: 50000, refs: []string{"REF3", "REF2"}, hours: []int{3, 8}},
+		{name: "UTC time of the same WIB day", day: time.Date(2024, 5, 1, 21, 0, 0, 0, time.UTC), want: "2024-05-02", total: 50000, refs: []string{"REF3", "REF2"}, hours: []int{3, 8}},
+		{name: "day before", day: time.Date(2024, 5, 1, 12, 0, 0, 0, qris.WIB), want: "2024-05-01", total: 10000, refs: []string{"REF1", "REF1"}, hours: []int{23}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := q.DailySummary(testContext(t, 5*time.Second), tt.day)
+			if err != nil {
+				t.Fatalf("DailySummary: %v", err)
+			}
+			if s.Day != tt.want || s.Timezone != qris.WIB.String() || s.Total != tt.total || s.Count != len(tt.hours) {
+				t.Fatalf("summary = %s %s total %d count %d, want %s total %d count %d", s.Day, s.Timezone, s.Total, s.Count, tt.want, tt.total, len(tt.hours))
+			}
+			if s.Largest.IssuerRef != tt.refs[0] || s.Smallest.IssuerRef != tt.refs[1] || s.Smallest.PaidAt.Location() != qris.WIB {
+				t.Fatalf("largest %+v, smallest %+v, want %v in WIB", s.Largest, s.Smallest, tt.refs)
+			}
+			var hourly [24]int
+			for _, h := range tt.hours {
+				hourly[h]++
+			}
+			if s.Hourly != hourly {
+				t.Fatalf("Hourly = %v, want %v", s.Hourly, hourly)
+			}
+		})
+	}
+}
+
+func TestDailySummary(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "summary", "brands.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := staticGateway(t, body)
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+
+	s, err := q.DailySummary(testContext(t, 5*time.Second), time.Date(2024, 5, 3, 0, 0, 0, 0, qris.WIB))
+	if err != nil {
+		t.Fatalf("DailySummary: %v", err)
+	}
+
+	// The debit is left out and both spellings of BCA are counted together
+	if s.Total != 117500 || s.Count != 4 || s.Largest.IssuerRef != "REF5" || s.Smallest.IssuerRef != "REF1" {
+		t.Fatalf("summary total %d count %d largest %+v smallest %+v", s.Total, s.Count, s.Largest, s.Smallest)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "summary/brands_summary.json", b)
+	checkGolden(t, "summary/brands_summary.txt", []byte(s.String()))
+}
+
+func TestPaidAtTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     time.Time
+		wantJSON string
+	}{
+		{name: "WIB by default", want: time.Date(2024, 5, 1, 20, 30, 0, 0, time.UTC), wantJSON: `"PaidAt":"2024-05-02T03:30:00+07:00"`},
+		{name: "configured location", location: time.UTC, want: time.Date(2024, 5, 2, 3, 30, 0, 0, time.UTC), wantJSON: `"PaidAt":"2024-05-02T03:30:00Z"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := dayBoundaryQRIS(t, func(c *qris.QRISConfig) { c.Location = tt.location })
+
+			status, err := q.FindPaymentByReference(testContext(t, 5*time.Second), "REF2", 10*365*24*time.Hour)
+			if err != nil {
+				t.Fatalf("FindPaymentByReference: %v", err)
+			}
+			if !status.PaidAt.Equal(tt.want) || status.Date != "2024-05-02 03:30:00" {
+				t.Fatalf("PaidAt = %v (Date %q), want %v", status.PaidAt, status.Date, tt.want)
+			}
+			b, err := json.Marshal(status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), tt.wantJSON) {
+				t.Fatalf("JSON = %s, want %s", b, tt.wantJSON)
+			}
+		})
+	}
+
+	b, err := json.Marshal(qris.PaymentStatus{Status: qris.StatusUnpaid})
+	if err != nil || !strings.Contains(string(b), `"PaidAt":null`) {
+		t.Fatalf("JSON of an unpaid status = %s, %v, want PaidAt null", b, err)
+	}
+}
@@ -0,0 +1,70 @@
+package qris
+
+import (
+	"time"
+)
+
+// DefaultSweepInterval is how often expired sessions are swept when QRISConfig.SweepInterval is not set.
+// DefaultSweepInterval adalah seberapa sering sesi kedaluwarsa disapu jika QRISConfig.SweepInterval tidak diatur.
+const DefaultSweepInterval = time.Minute
+
+// SweepExpired expires every session past its deadline, including sessions nobody is waiting on:
+// their amount reservation is released, the InvoiceStore marks them EXPIRED, and EventExpired is
+// emitted. It returns the number of sessions expired; sweeping again is harmless.
+// SweepExpired mengakhiri setiap sesi yang melewati batas waktunya, termasuk sesi yang tidak sedang ditunggu:
+// pemesanan nominalnya dilepas, InvoiceStore menandainya EXPIRED, dan EventExpired dikirim.
+// Fungsi ini mengembalikan jumlah sesi yang diakhiri; menyapu ulang tidak berbahaya.
+//
+// A background sweeper calls it every SweepInterval once the first session is created, until Close.
+// Sweeper latar belakang memanggilnya setiap SweepInterval sejak sesi pertama dibuat, sampai Close.
+func (q *QRIS) SweepExpired() int {
+	now := time.Now()
+	expired := 0
+	for _, s := range q.sessions.all() {
+		if now.Before(s.ExpiresAt) {
+			continue
+		}
+		select {
+		case <-s.done:
+			continue
+		default:
+		}
+		s.finish(EventExpired, s.unpaidStatus(StatusExpired), ErrPaymentExpired)
+		expired++
+	}
+	return expired
+}
+
+// startSweeper starts the background sweeper once.
+// startSweeper menjalankan sweeper latar belakang satu kali.
+func (q *QRIS) startSweeper() {
+	q.sweepOnce.Do(func() {
+		if !q.life.track() {
+			return
+		}
+		go func() {
+			defer q.life.wg.Done()
+			q.sweep()
+		}()
+	})
+}
+
+// sweep calls SweepExpired every sweep interval until the instance is closed.
+// sweep memanggil SweepExpired setiap interval sapuan sampai instance ditutup.
+func (q *QRIS) sweep() {
+	interval := q.config.SweepInterval
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.life.ctx.Done():
+			return
+		case <-ticker.C:
+			q.SweepExpired()
+		}
+	}
+}
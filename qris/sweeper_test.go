@@ -0,0 +1,149 @@
+package qris_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// expiryStore is an InvoiceStore recording which invoices were marked expired.
+type expiryStore struct {
+	mu      sync.Mutex
+	saved   int
+	expired map[string]int
+}
+
+func newExpiryStore() *expiryStore {
+	return &expiryStore{expired: make(map[string]int)}
+}
+
+func (s *expiryStore) Save(ctx context.Context, inv qris.Invoice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved++
+	return nil
+}
+
+func (s *expiryStore) LoadPending(ctx context.Context) ([]qris.Invoice, error) { return nil, nil }
+
+func (s *expiryStore) MarkPaid(ctx context.Context, transactionID string, payment *qris.PaymentStatus) error {
+	return nil
+}
+
+func (s *expiryStore) MarkExpired(ctx context.Context, transactionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expired[transactionID]++
+	return nil
+}
+
+func (s *expiryStore) counts() (saved, expired int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saved, len(s.expired)
+}
+
+func TestSweeperReleasesReservations(t *testing.T) {
+	const (
+		n    = 1000
+		base = 10000
+	)
+	store := newExpiryStore()
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.InvoiceStore = store
+		c.SweepInterval = testPoll
+	})
+	ctx := testContext(t, 30*time.Second)
+
+	// n invoices fill the whole suffix space of base
+	create := func(round int, expiry time.Duration) []*qris.PaymentSession {
+		sessions := make([]*qris.PaymentSession, n)
+		for i := range sessions {
+			s, err := q.CreatePayment(ctx, base,
+				qris.WithTransactionID(fmt.Sprintf("R%dINV%d", round, i)),
+				qris.WithUniqueSuffix(n),
+				qris.WithExpiry(expiry))
+			if err != nil {
+				t.Fatalf("round %d invoice %d: %v", round, i, err)
+			}
+			sessions[i] = s
+		}
+		return sessions
+	}
+	first := create(1, time.Second)
+	if _, err := q.CreatePayment(ctx, base, qris.WithUniqueSuffix(n)); !errors.Is(err, qris.ErrNoAmountAvailable) {
+		t.Fatalf("CreatePayment on a full pool = %v, want %v", err, qris.ErrNoAmountAvailable)
+	}
+
+	for _, s := range first {
+		select {
+		case <-s.Done():
+		case <-ctx.Done():
+			t.Fatalf("invoice %s was never swept", s.TransactionID)
+		}
+		if _, err := s.Wait(ctx); !errors.Is(err, qris.ErrPaymentExpired) {
+			t.Fatalf("invoice %s: Wait = %v, want %v", s.TransactionID, err, qris.ErrPaymentExpired)
+		}
+		var expired bool
+		for ev := range s.Events() {
+			expired = expired || ev.Type == qris.EventExpired
+		}
+		if !expired {
+			t.Errorf("invoice %s emitted no %s event", s.TransactionID, qris.EventExpired)
+		}
+	}
+	if saved, expired := store.counts(); saved != n || expired != n {
+		t.Fatalf("store saved %d and expired %d invoices, want %d of each", saved, expired, n)
+	}
+
+	// Every reservation was released, so the same space fits n invoices again;
+	// they outlive the test so a slow run cannot expire them before the check below
+	create(2, time.Hour)
+
+	// The sweeper already finished the first round
+	if got := q.SweepExpired(); got != 0 {
+		t.Errorf("SweepExpired right after round 2 = %d, want 0", got)
+	}
+}
+
+func TestSweepExpiredIdempotent(t *testing.T) {
+	store := newExpiryStore()
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) {
+		c.InvoiceStore = store
+		c.SweepInterval = time.Hour
+	})
+	ctx := testContext(t, 5*time.Second)
+
+	for i := 0; i < 3; i++ {
+		if _, err := q.CreatePayment(ctx, 10000, qris.WithExpiry(time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	live, err := q.CreatePayment(ctx, 10000, qris.WithExpiry(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if got := q.SweepExpired(); got != 3 {
+		t.Fatalf("first SweepExpired = %d, want 3", got)
+	}
+	if got := q.SweepExpired(); got != 0 {
+		t.Fatalf("second SweepExpired = %d, want 0", got)
+	}
+	store.mu.Lock()
+	for id, marks := range store.expired {
+		if marks != 1 {
+			t.Errorf("invoice %s marked expired %d times, want 1", id, marks)
+		}
+	}
+	store.mu.Unlock()
+	if _, ok := q.Session(live.TransactionID); !ok {
+		t.Errorf("unexpired invoice %s was swept", live.TransactionID)
+	}
+}
@@ -0,0 +1,48 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultRequestTimeout bounds a single gateway request when QRISConfig.RequestTimeout is not set.
+// DefaultRequestTimeout membatasi satu request gateway jika QRISConfig.RequestTimeout tidak diatur.
+const DefaultRequestTimeout = 10 * time.Second
+
+// ErrGatewayTimeout is wrapped by errors of gateway requests that ran out of time.
+// ErrGatewayTimeout dibungkus oleh error request gateway yang kehabisan waktu.
+var ErrGatewayTimeout = errors.New("gateway request timed out / request gateway melebihi batas waktu")
+
+type requestTimeoutKey struct{}
+
+// withRequestTimeout overrides the request timeout for gateway calls made with ctx.
+// withRequestTimeout mengganti batas waktu request untuk panggilan gateway yang memakai ctx.
+func withRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, d)
+}
+
+// requestContext layers the request timeout over the caller's context. The caller's own deadline
+// still applies when it is earlier.
+// requestContext menambahkan batas waktu request di atas context pemanggil. Deadline milik pemanggil
+// tetap berlaku jika lebih awal.
+func (q *QRIS) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := q.config.RequestTimeout
+	if override, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok && override > 0 {
+		d = override
+	}
+	if d <= 0 {
+		d = DefaultRequestTimeout
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// gatewayError wraps err in ErrGatewayTimeout when the request deadline, not the caller, stopped it.
+// gatewayError membungkus err dengan ErrGatewayTimeout jika yang menghentikannya adalah deadline request, bukan pemanggil.
+func gatewayError(ctx, reqCtx context.Context, err error) error {
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("%w: %v", ErrGatewayTimeout, err)
+	}
+	return err
+}
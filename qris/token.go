@@ -0,0 +1,200 @@
+package qris
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MinTokenKeySize is the shortest key accepted by NewTokenCodec, in bytes.
+// MinTokenKeySize adalah kunci terpendek yang diterima NewTokenCodec, dalam byte.
+const MinTokenKeySize = 16
+
+// Token errors. Both are returned for tokens that should not be trusted; ErrTokenExpired only
+// once the signature checked out.
+// Error token. Keduanya dikembalikan untuk token yang tidak boleh dipercaya; ErrTokenExpired hanya
+// setelah tanda tangannya valid.
+var (
+	ErrTokenInvalid = errors.New("invalid or tampered token / token tidak valid atau telah diubah")
+	ErrTokenExpired = errors.New("token expired / token kedaluwarsa")
+)
+
+// Token kinds, the first byte of a decoded token.
+// Jenis token, byte pertama dari token yang sudah di-decode.
+const (
+	tokenSigned    byte = 1
+	tokenEncrypted byte = 2
+)
+
+// tokenMACSize is the length of the truncated HMAC-SHA256 of signed tokens.
+// tokenMACSize adalah panjang HMAC-SHA256 terpotong dari token bertanda tangan.
+const tokenMACSize = 16
+
+// PaymentToken is the content of a token of TokenCodec.
+// PaymentToken adalah isi token dari TokenCodec.
+type PaymentToken struct {
+	TransactionID string    // Transaction ID / ID transaksi
+	Amount        int64     // Amount in rupiah / Nominal dalam rupiah
+	ExpiresAt     time.Time // Zero for a token that never expires / Nol untuk token yang tidak pernah kedaluwarsa
+}
+
+// TokenCodec turns PaymentTokens into compact URL-safe strings signed with HMAC-SHA256, or
+// encrypted with AES-256-GCM so the transaction ID and amount are not readable, and back. Receivers
+// holding a key verify a token without looking anything up.
+// TokenCodec mengubah PaymentToken menjadi string ringkas aman-URL yang ditandatangani dengan
+// HMAC-SHA256, atau dienkripsi dengan AES-256-GCM sehingga ID transaksi dan nominal tidak terbaca,
+// dan sebaliknya. Penerima yang memegang kunci memverifikasi token tanpa mencari apa pun.
+//
+// The first key signs new tokens and every key is accepted when decoding, so keys rotate by
+// putting the new key first and dropping the old one once its tokens expired.
+// Kunci pertama menandatangani token baru dan semua kunci diterima saat decode, sehingga kunci
+// dirotasi dengan menaruh kunci baru di depan dan membuang kunci lama setelah tokennya kedaluwarsa.
+type TokenCodec struct {
+	encrypt bool
+	keys    []tokenKey
+}
+
+// tokenKey holds the keys derived from one key given to NewTokenCodec.
+// tokenKey menyimpan kunci turunan dari satu kunci yang diberikan ke NewTokenCodec.
+type tokenKey struct {
+	mac  []byte
+	aead cipher.AEAD
+}
+
+// NewTokenCodec returns a TokenCodec with keys of at least MinTokenKeySize bytes, current key
+// first. With encrypt, tokens are encrypted rather than only signed.
+// NewTokenCodec mengembalikan TokenCodec dengan keys minimal MinTokenKeySize byte, kunci saat ini
+// lebih dulu. Dengan encrypt, token dienkripsi, bukan hanya ditandatangani.
+func NewTokenCodec(encrypt bool, keys ...[]byte) (*TokenCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("token codec needs a key / token codec membutuhkan kunci")
+	}
+	c := &TokenCodec{encrypt: encrypt}
+	for _, key := range keys {
+		if len(key) < MinTokenKeySize {
+			return nil, fmt.Errorf("token key must be at least %d bytes / kunci token minimal %d byte", MinTokenKeySize, MinTokenKeySize)
+		}
+		block, err := aes.NewCipher(deriveTokenKey(key, "encrypt"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys = append(c.keys, tokenKey{mac: deriveTokenKey(key, "sign"), aead: aead})
+	}
+	return c, nil
+}
+
+// deriveTokenKey derives the 32-byte key of purpose from key, so signing and encryption never share a key.
+// deriveTokenKey menurunkan kunci 32 byte untuk purpose dari key, sehingga tanda tangan dan enkripsi tidak berbagi kunci.
+func deriveTokenKey(key []byte, purpose string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte("qris token " + purpose))
+	return m.Sum(nil)
+}
+
+// Encode returns the token of t.
+// Encode mengembalikan token dari t.
+func (c *TokenCodec) Encode(t PaymentToken) (string, error) {
+	if t.TransactionID == "" {
+		return "", errors.New("transactionID must be filled / transactionID harus diisi")
+	}
+	var expires int64
+	if !t.ExpiresAt.IsZero() {
+		expires = t.ExpiresAt.Unix()
+	}
+	payload := binary.AppendVarint(nil, expires)
+	payload = binary.AppendVarint(payload, t.Amount)
+	payload = append(payload, t.TransactionID...)
+
+	key := c.keys[0]
+	var raw []byte
+	if c.encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("failed to generate nonce / gagal generate nonce: %v", err)
+		}
+		raw = append([]byte{tokenEncrypted}, nonce...)
+		raw = key.aead.Seal(raw, nonce, payload, raw[:1])
+	} else {
+		raw = append([]byte{tokenSigned}, payload...)
+		raw = append(raw, tokenMAC(key.mac, raw)...)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Decode verifies token and returns its content. A token that does not verify with any key
+// fails with ErrTokenInvalid; an expired one is returned together with ErrTokenExpired.
+// Decode memverifikasi token dan mengembalikan isinya. Token yang tidak valid dengan kunci mana
+// pun gagal dengan ErrTokenInvalid; token kedaluwarsa dikembalikan bersama ErrTokenExpired.
+func (c *TokenCodec) Decode(token string) (PaymentToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) == 0 {
+		return PaymentToken{}, ErrTokenInvalid
+	}
+
+	payload, ok := c.open(raw)
+	if !ok {
+		return PaymentToken{}, ErrTokenInvalid
+	}
+	expires, n := binary.Varint(payload)
+	if n <= 0 {
+		return PaymentToken{}, ErrTokenInvalid
+	}
+	amount, m := binary.Varint(payload[n:])
+	if m <= 0 || len(payload) == n+m {
+		return PaymentToken{}, ErrTokenInvalid
+	}
+
+	t := PaymentToken{TransactionID: string(payload[n+m:]), Amount: amount}
+	if expires != 0 {
+		t.ExpiresAt = time.Unix(expires, 0)
+		if !time.Now().Before(t.ExpiresAt) {
+			return t, fmt.Errorf("%w: %s", ErrTokenExpired, t.TransactionID)
+		}
+	}
+	return t, nil
+}
+
+// open returns the payload of raw if it verifies with one of the keys.
+// open mengembalikan payload dari raw jika valid dengan salah satu kunci.
+func (c *TokenCodec) open(raw []byte) ([]byte, bool) {
+	switch raw[0] {
+	case tokenSigned:
+		if len(raw) < 1+tokenMACSize {
+			return nil, false
+		}
+		body, mac := raw[:len(raw)-tokenMACSize], raw[len(raw)-tokenMACSize:]
+		for _, key := range c.keys {
+			if hmac.Equal(mac, tokenMAC(key.mac, body)) {
+				return body[1:], true
+			}
+		}
+	case tokenEncrypted:
+		for _, key := range c.keys {
+			size := key.aead.NonceSize()
+			if len(raw) < 1+size {
+				return nil, false
+			}
+			if payload, err := key.aead.Open(nil, raw[1:1+size], raw[1+size:], raw[:1]); err == nil {
+				return payload, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func tokenMAC(key, body []byte) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write(body)
+	return m.Sum(nil)[:tokenMACSize]
+}
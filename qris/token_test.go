@@ -0,0 +1,257 @@
+package qris_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// Keys of the token tests, oldest first.
+var (
+	tokenKeyOld = []byte("0123456789abcdef-old")
+	tokenKeyNew = []byte("0123456789abcdef-new")
+)
+
+func newTokenCodec(t *testing.T, encrypt bool, keys ...[]byte) *qris.TokenCodec {
+	t.Helper()
+	c, err := qris.NewTokenCodec(encrypt, keys...)
+	if err != nil {
+		t.Fatalf("NewTokenCodec: %v", err)
+	}
+	return c
+}
+
+// tamper changes one character in the middle of token.
+func tamper(token string) string {
+	i := len(token) / 2
+	c := byte('A')
+	if token[i] == 'A' {
+		c = 'B'
+	}
+	return token[:i] + string(c) + token[i+1:]
+}
+
+func TestTokenCodec(t *testing.T) {
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name    string
+		token   qris.PaymentToken
+		decoder [][]byte // keys decoding the token encoded with tokenKeyOld / kunci yang men-decode token yang di-encode dengan tokenKeyOld
+		edit    func(string) string
+		wantErr error
+	}{
+		{
+			name:    "round trip",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123, ExpiresAt: future},
+			decoder: [][]byte{tokenKeyOld},
+		},
+		{
+			name:    "never expires",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123},
+			decoder: [][]byte{tokenKeyOld},
+		},
+		{
+			name:    "negative amount",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: -5},
+			decoder: [][]byte{tokenKeyOld},
+		},
+		{
+			name:    "tampered",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123},
+			decoder: [][]byte{tokenKeyOld},
+			edit:    tamper,
+			wantErr: qris.ErrTokenInvalid,
+		},
+		{
+			name:    "signature cut",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123},
+			decoder: [][]byte{tokenKeyOld},
+			edit:    func(s string) string { return s[:len(s)-4] },
+			wantErr: qris.ErrTokenInvalid,
+		},
+		{
+			name:    "expired",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123, ExpiresAt: time.Now().Add(-time.Minute)},
+			decoder: [][]byte{tokenKeyOld},
+			wantErr: qris.ErrTokenExpired,
+		},
+		{
+			name:    "old key after rotation",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123, ExpiresAt: future},
+			decoder: [][]byte{tokenKeyNew, tokenKeyOld},
+		},
+		{
+			name:    "old key dropped",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123, ExpiresAt: future},
+			decoder: [][]byte{tokenKeyNew},
+			wantErr: qris.ErrTokenInvalid,
+		},
+		{
+			name:    "unknown key",
+			token:   qris.PaymentToken{TransactionID: "INV-7", Amount: 25123},
+			decoder: [][]byte{[]byte("some other key of 32 bytes......")},
+			wantErr: qris.ErrTokenInvalid,
+		},
+	}
+	for _, mode := range []struct {
+		name    string
+		encrypt bool
+	}{{"signed", false}, {"encrypted", true}} {
+		for _, tt := range tests {
+			t.Run(mode.name+"/"+tt.name, func(t *testing.T) {
+				token, err := newTokenCodec(t, mode.encrypt, tokenKeyOld).Encode(tt.token)
+				if err != nil {
+					t.Fatalf("Encode: %v", err)
+				}
+				if strings.ContainsAny(token, "+/=") {
+					t.Fatalf("token %q is not URL-safe", token)
+				}
+				if tt.edit != nil {
+					token = tt.edit(token)
+				}
+
+				got, err := newTokenCodec(t, mode.encrypt, tt.decoder...).Decode(token)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Decode = %+v, %v, want %v", got, err, tt.wantErr)
+					}
+					if tt.wantErr == qris.ErrTokenInvalid && got != (qris.PaymentToken{}) {
+						t.Fatalf("Decode returned %+v with an invalid token", got)
+					}
+					if tt.wantErr == qris.ErrTokenExpired && (got.TransactionID != tt.token.TransactionID || !got.ExpiresAt.Equal(tt.token.ExpiresAt.Truncate(time.Second))) {
+						t.Fatalf("Decode = %+v, want the expired token", got)
+					}
+					return
+				}
+				if err != nil || got.TransactionID != tt.token.TransactionID || got.Amount != tt.token.Amount || !got.ExpiresAt.Equal(tt.token.ExpiresAt) {
+					t.Fatalf("Decode = %+v, %v, want %+v", got, err, tt.token)
+				}
+			})
+		}
+	}
+}
+
+func TestTokenCodecEncrypted(t *testing.T) {
+	signed, err := newTokenCodec(t, false, tokenKeyOld).Encode(qris.PaymentToken{TransactionID: "INV-7", Amount: 25123})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := newTokenCodec(t, true, tokenKeyOld)
+	token, err := encrypted.Encode(qris.PaymentToken{TransactionID: "INV-7", Amount: 25123})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction ID is readable in signed tokens only
+	for _, tt := range []struct {
+		token    string
+		readable bool
+	}{{signed, true}, {token, false}} {
+		raw, err := base64.RawURLEncoding.DecodeString(tt.token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(raw, []byte("INV-7")) != tt.readable {
+			t.Errorf("token %q: transaction ID readable = %v, want %v", tt.token, !tt.readable, tt.readable)
+		}
+	}
+
+	// A fresh nonce makes every token different
+	again, err := encrypted.Encode(qris.PaymentToken{TransactionID: "INV-7", Amount: 25123})
+	if err != nil || again == token {
+		t.Fatalf("second token = %q, %v, want a different one", again, err)
+	}
+}
+
+func TestTokenCodecGarbage(t *testing.T) {
+	tokens := []string{
+		"",
+		"!!!",
+		"AQ", // a signed token kind and nothing else / jenis token bertanda tangan tanpa isi
+		"Ag", // an encrypted token kind and nothing else / jenis token terenkripsi tanpa isi
+		"Aw", // an unknown kind / jenis yang tidak dikenal
+		base64.RawURLEncoding.EncodeToString(append([]byte{1}, make([]byte, 16)...)),
+		base64.RawURLEncoding.EncodeToString(append([]byte{2}, make([]byte, 12)...)),
+		base64.RawURLEncoding.EncodeToString(bytes.Repeat([]byte{0xff}, 64)),
+		strings.Repeat("A", 4096),
+	}
+	for _, encrypt := range []bool{false, true} {
+		c := newTokenCodec(t, encrypt, tokenKeyNew, tokenKeyOld)
+		for _, token := range tokens {
+			if got, err := c.Decode(token); !errors.Is(err, qris.ErrTokenInvalid) {
+				t.Errorf("Decode(%.20q) = %+v, %v, want ErrTokenInvalid", token, got, err)
+			}
+		}
+	}
+}
+
+func TestNewTokenCodecInvalid(t *testing.T) {
+	if _, err := qris.NewTokenCodec(false); err == nil {
+		t.Error("NewTokenCodec without a key succeeded")
+	}
+	if _, err := qris.NewTokenCodec(true, tokenKeyNew, make([]byte, qris.MinTokenKeySize-1)); err == nil {
+		t.Error("NewTokenCodec with a short key succeeded")
+	}
+	if _, err := newTokenCodec(t, false, tokenKeyNew).Encode(qris.PaymentToken{Amount: 1}); err == nil {
+		t.Error("Encode without a transaction ID succeeded")
+	}
+}
+
+func TestResolveCodeLinkTokens(t *testing.T) {
+	codec := newTokenCodec(t, true, tokenKeyNew, tokenKeyOld)
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.LinkTokens = codec })
+
+	ctx := testContext(t, 5*time.Second)
+	s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The code is the token of the session
+	token, err := codec.Decode(s.Code())
+	if err != nil || token.TransactionID != "INV-7" || token.Amount != s.Amount() || !token.ExpiresAt.Equal(s.ExpiresAt.Truncate(time.Second)) {
+		t.Fatalf("token of the code = %+v, %v", token, err)
+	}
+	if got, err := q.ResolveCode(ctx, s.Code()); err != nil || got != s {
+		t.Fatalf("ResolveCode(Code) = %v, %v", got, err)
+	}
+
+	// Tokens not minted by this instance are verified without the store
+	old := newTokenCodec(t, true, tokenKeyOld)
+	valid, _ := old.Encode(qris.PaymentToken{TransactionID: "INV-7", Amount: s.Amount(), ExpiresAt: s.ExpiresAt})
+	expired, _ := old.Encode(qris.PaymentToken{TransactionID: "INV-7", Amount: s.Amount(), ExpiresAt: time.Now().Add(-time.Minute)})
+	unknown, _ := old.Encode(qris.PaymentToken{TransactionID: "INV-8", Amount: s.Amount()})
+	tests := []struct {
+		name    string
+		code    string
+		wantErr []error
+	}{
+		{name: "rotated key", code: valid},
+		{name: "tampered", code: tamper(valid), wantErr: []error{qris.ErrNotFound, qris.ErrTokenInvalid}},
+		{name: "expired", code: expired, wantErr: []error{qris.ErrPaymentExpired, qris.ErrTokenExpired}},
+		{name: "unknown session", code: unknown, wantErr: []error{qris.ErrNotFound}},
+		{name: "garbage", code: "!!!", wantErr: []error{qris.ErrNotFound, qris.ErrTokenInvalid}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.ResolveCode(ctx, tt.code)
+			if tt.wantErr == nil {
+				if err != nil || got != s {
+					t.Fatalf("ResolveCode = %v, %v, want the session", got, err)
+				}
+				return
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Fatalf("ResolveCode = %v, %v, want %v", got, err, want)
+				}
+			}
+		})
+	}
+}
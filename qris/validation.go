@@ -0,0 +1,441 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AutoFTbot/OrderKuota-go/internal/transport"
+	"github.com/AutoFTbot/OrderKuota-go/qris/emv"
+)
+
+// Severity tells whether an Issue makes the input unusable.
+// Severity menunjukkan apakah Issue membuat input tidak dapat dipakai.
+type Severity string
+
+// Issue severities.
+// Tingkat keparahan Issue.
+const (
+	SeverityError   Severity = "error"   // The input is rejected / Input ditolak
+	SeverityWarning Severity = "warning" // The input is accepted but worth a look / Input diterima tetapi perlu diperiksa
+)
+
+// Issue codes reported in Issue.Code. Codes are stable across releases; new codes may be added
+// but existing ones are never renamed or reused.
+// Kode issue yang dilaporkan di Issue.Code. Kode stabil antar rilis; kode baru dapat ditambahkan
+// tetapi kode yang ada tidak pernah diganti nama atau dipakai ulang.
+const (
+	IssueEmpty                  = "empty"
+	IssueTooShort               = "too_short"
+	IssueLooksLikeURL           = "looks_like_url"
+	IssueLooksLikeImage         = "looks_like_base64_image"
+	IssueBadPrefix              = "bad_prefix"
+	IssueCleanedUp              = "cleaned_up"
+	IssueCRCMissing             = "crc_missing"
+	IssueCRCMismatch            = "crc_mismatch"
+	IssueCRCLowercase           = "crc_lowercase"
+	IssueMalformedTLV           = "malformed_tlv"
+	IssueMissingMerchantAccount = "missing_merchant_account"
+	IssueInvalidMerchantAccount = "invalid_merchant_account"
+	IssueWrongCountry           = "wrong_country"
+	IssueMissingAmount          = "missing_amount"
+
+	IssueMissingRequiredField   = "missing_required_field"
+	IssueInvalidGatewayURL      = "invalid_gateway_url"
+	IssueInvalidProxyURL        = "invalid_proxy_url"
+	IssueInvalidAmountRange     = "invalid_amount_range"
+	IssueNegativeMatchWindow    = "negative_match_window"
+	IssueNegativeRequestTimeout = "negative_request_timeout"
+	IssueNegativeMaxRateLimit   = "negative_max_rate_limit_wait"
+	IssueNegativeSweepInterval  = "negative_sweep_interval"
+	IssueNegativeUniqueSuffix   = "negative_unique_suffix"
+	IssueInvalidQRISType        = "invalid_qris_type"
+	IssueUnknownGateway         = "unknown_gateway"
+	IssueUnsupportedCurrency    = "unsupported_currency"
+	issueMissingTagPrefix       = "missing_tag_"
+)
+
+// IssueMissingTag returns the code reported when a mandatory tag is absent, such as "missing_tag_59".
+// IssueMissingTag mengembalikan kode yang dilaporkan saat tag wajib tidak ada, misalnya "missing_tag_59".
+func IssueMissingTag(tag string) string {
+	return issueMissingTagPrefix + tag
+}
+
+// IssueCodes lists every code the package can report, in a stable order.
+// IssueCodes berisi semua kode yang dapat dilaporkan paket, dalam urutan yang stabil.
+var IssueCodes = func() []string {
+	codes := []string{
+		IssueEmpty, IssueTooShort, IssueLooksLikeURL, IssueLooksLikeImage, IssueBadPrefix, IssueCleanedUp,
+		IssueCRCMissing, IssueCRCMismatch, IssueCRCLowercase, IssueMalformedTLV,
+	}
+	for _, m := range mandatoryTags {
+		codes = append(codes, IssueMissingTag(m.tag))
+	}
+	return append(codes,
+		IssueMissingMerchantAccount, IssueWrongCountry, IssueMissingAmount,
+		IssueMissingRequiredField, IssueInvalidGatewayURL, IssueInvalidProxyURL, IssueInvalidAmountRange,
+		IssueNegativeMatchWindow, IssueNegativeRequestTimeout, IssueNegativeMaxRateLimit, IssueNegativeSweepInterval,
+		IssueNegativeUniqueSuffix, IssueInvalidQRISType, IssueUnknownGateway, IssueUnsupportedCurrency, IssueInvalidMerchantAccount,
+	)
+}()
+
+// Issue is one finding of a validation.
+// Issue adalah satu temuan dari validasi.
+type Issue struct {
+	Code     string   `json:"code"`            // Stable code, see IssueCodes / Kode stabil, lihat IssueCodes
+	Severity Severity `json:"severity"`        // Error or warning / Error atau peringatan
+	Tag      string   `json:"tag,omitempty"`   // QRIS tag concerned, if any / Tag QRIS terkait, jika ada
+	Offset   int      `json:"offset"`          // Position in the payload, -1 if unknown / Posisi di payload, -1 jika tidak diketahui
+	Field    string   `json:"field,omitempty"` // QRISConfig field concerned, if any / Field QRISConfig terkait, jika ada
+	Message  string   `json:"message"`         // Bilingual "english / indonesian" text / Teks dwibahasa "english / indonesian"
+
+	text message
+	err  error // Error returned by the error-based validators / Error yang dikembalikan validator berbasis error
+}
+
+// Localized returns the message of the issue in lang ("id" or "en", default English).
+// Localized mengembalikan pesan issue dalam lang ("id" atau "en", default bahasa Inggris).
+func (i Issue) Localized(lang string) string {
+	return i.text.in(lang)
+}
+
+// newIssue returns an error Issue with the given text; err defaults to the bilingual text.
+// newIssue mengembalikan Issue error dengan teks yang diberikan; err default-nya teks dwibahasa.
+func newIssue(code, en, id string, err error) Issue {
+	msg := en + " / " + id
+	if err == nil {
+		err = errors.New(msg)
+	}
+	return Issue{Code: code, Severity: SeverityError, Offset: -1, Message: msg, text: message{en: en, id: id}, err: err}
+}
+
+func (i Issue) warning() Issue {
+	i.Severity = SeverityWarning
+	return i
+}
+
+func (i Issue) at(tag string, offset int) Issue {
+	i.Tag, i.Offset = tag, offset
+	return i
+}
+
+// ValidationReport is the machine-readable result of a validation.
+// ValidationReport adalah hasil validasi yang dapat dibaca mesin.
+type ValidationReport struct {
+	Valid  bool    `json:"valid"`  // No issue has SeverityError / Tidak ada issue dengan SeverityError
+	Issues []Issue `json:"issues"` // Findings in check order / Temuan sesuai urutan pemeriksaan
+}
+
+func newReport() ValidationReport {
+	return ValidationReport{Valid: true, Issues: []Issue{}}
+}
+
+func (r *ValidationReport) add(i Issue) {
+	r.Issues = append(r.Issues, i)
+	if i.Severity == SeverityError {
+		r.Valid = false
+	}
+}
+
+// Err returns the error of the first issue with SeverityError, or nil if the report is valid.
+// It is the error the matching error-based validator returns.
+// Err mengembalikan error dari issue pertama dengan SeverityError, atau nil jika laporan valid.
+// Error ini sama dengan yang dikembalikan validator berbasis error yang bersesuaian.
+func (r ValidationReport) Err() error {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return i.err
+		}
+	}
+	return nil
+}
+
+// Has reports whether the report contains an issue with code.
+// Has melaporkan apakah laporan berisi issue dengan code.
+func (r ValidationReport) Has(code string) bool {
+	for _, i := range r.Issues {
+		if i.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeQRISStringDetailed is like NormalizeQRISString but reports every issue found instead of
+// the first error. The normalized string is empty when the report is not valid.
+// NormalizeQRISStringDetailed sama seperti NormalizeQRISString tetapi melaporkan semua issue yang
+// ditemukan, bukan hanya error pertama. String hasil normalisasi kosong jika laporan tidak valid.
+func NormalizeQRISStringDetailed(s string) (string, ValidationReport) {
+	r := newReport()
+	raw := s
+	s = strings.TrimSpace(s)
+	for len(s) >= 2 && isQuote(s[0]) && s[len(s)-1] == s[0] {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.NewReplacer("\r", "", "\n", "", "\t", "").Replace(s)
+
+	if s == "" {
+		r.add(newIssue(IssueEmpty, "QRIS string is empty", "string QRIS kosong", nil))
+		return "", r
+	}
+	if s != raw {
+		r.add(newIssue(IssueCleanedUp, "whitespace, quotes, or line breaks were removed", "spasi, tanda kutip, atau baris baru dihapus", nil).warning())
+	}
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "www."):
+		r.add(newIssue(IssueLooksLikeURL,
+			"this looks like a URL, not a QRIS string; scan the QR image and paste its text content",
+			"ini terlihat seperti URL, bukan string QRIS; scan gambar QR dan paste isi teksnya", nil))
+		return "", r
+	case strings.HasPrefix(lower, "data:image/") || strings.HasPrefix(s, "iVBORw0KGgo") || strings.HasPrefix(s, "/9j/"):
+		r.add(newIssue(IssueLooksLikeImage,
+			"this looks like a base64 image, not a QRIS string; decode the QR image and paste its text content",
+			"ini terlihat seperti gambar base64, bukan string QRIS; decode gambar QR dan paste isi teksnya", nil))
+		return "", r
+	case !strings.HasPrefix(s, "000201"):
+		r.add(newIssue(IssueBadPrefix, "QRIS string must start with 000201", "string QRIS harus diawali 000201", nil).at("00", 0))
+	}
+
+	// Some acquirers emit the CRC in lowercase hex
+	if len(s) >= 8 && s[len(s)-8:len(s)-4] == emv.CRCTag {
+		if upper := s[:len(s)-4] + strings.ToUpper(s[len(s)-4:]); upper != s {
+			r.add(newIssue(IssueCRCLowercase, "the CRC was uppercased", "CRC diubah ke huruf besar", nil).warning().at("63", len(s)-8))
+			s = upper
+		}
+	}
+	checkCRC(&r, s)
+
+	payload, err := ParseQRISString(s)
+	if err != nil {
+		r.add(malformedIssue(err))
+		return "", r
+	}
+	checkMandatoryTags(&r, payload, SeverityError)
+	if !hasMerchantAccount(payload) {
+		r.add(newIssue(IssueMissingMerchantAccount,
+			"missing merchant account information (tags 26-51)", "informasi akun merchant (tag 26-51) tidak ada", nil))
+	}
+	checkMerchantAccounts(&r, payload)
+	if f, ok := payload.Field("58"); ok && f.Value != "ID" {
+		r.add(newIssue(IssueWrongCountry,
+			fmt.Sprintf("country code is %q, want \"ID\"", f.Value), fmt.Sprintf("kode negara %q, seharusnya \"ID\"", f.Value), nil).at("58", f.Offset))
+	}
+
+	if !r.Valid {
+		return "", r
+	}
+	return s, r
+}
+
+// ValidateQRISStringDetailed checks a QRIS payload like QRIS.ValidateQRISString and reports every
+// issue found. Mandatory tags that ValidateQRISString does not require are reported as warnings.
+// ValidateQRISStringDetailed memeriksa payload QRIS seperti QRIS.ValidateQRISString dan melaporkan
+// semua issue yang ditemukan. Tag wajib yang tidak diharuskan ValidateQRISString dilaporkan sebagai peringatan.
+func ValidateQRISStringDetailed(qrString string) ValidationReport {
+	r := newReport()
+	if len(qrString) < 20 {
+		r.add(newIssue(IssueTooShort, "QRIS string too short", "string QRIS terlalu pendek", nil))
+		return r
+	}
+
+	payload, err := ParseQRISString(qrString)
+	if err != nil {
+		r.add(malformedIssue(err))
+		checkCRC(&r, qrString)
+		return r
+	}
+
+	if !strings.Contains(qrString, "5802ID") {
+		issue := newIssue(IssueWrongCountry,
+			"invalid QRIS format: country ID not found", "format QRIS tidak valid: ID negara tidak ditemukan", nil)
+		if f, ok := payload.Field("58"); ok {
+			issue = issue.at("58", f.Offset)
+		}
+		r.add(issue)
+	}
+
+	// Open-amount (POI 11) payloads have no amount
+	if _, ok := payload.Field("54"); !ok && payload.Value("01") != "11" {
+		r.add(newIssue(IssueMissingAmount, "invalid amount format", "format nominal tidak valid", nil).at("54", -1))
+	}
+
+	checkCRC(&r, qrString)
+	checkMandatoryTags(&r, payload, SeverityWarning)
+	checkMerchantAccounts(&r, payload)
+	return r
+}
+
+// checkMerchantAccounts adds a warning for every merchant account template missing a mandatory sub-tag.
+// checkMerchantAccounts menambahkan peringatan untuk setiap template akun merchant yang tidak memiliki sub-tag wajib.
+func checkMerchantAccounts(r *ValidationReport, payload *QRISPayload) {
+	for _, a := range payload.MerchantAccounts() {
+		err := a.Validate()
+		if err == nil {
+			continue
+		}
+		f, _ := payload.Field(a.Tag)
+		en, id, _ := strings.Cut(err.Error(), " / ")
+		r.add(newIssue(IssueInvalidMerchantAccount, en, id, nil).warning().at(a.Tag, f.Offset))
+	}
+}
+
+// malformedIssue returns the issue of a payload that ParseQRISString rejected with err.
+// malformedIssue mengembalikan issue dari payload yang ditolak ParseQRISString dengan err.
+func malformedIssue(err error) Issue {
+	issue := newIssue(IssueMalformedTLV, "invalid QRIS format", "format QRIS tidak valid",
+		fmt.Errorf("invalid QRIS format / format QRIS tidak valid: %v", err))
+	if m := offsetPattern.FindStringSubmatch(err.Error()); m != nil {
+		issue.Offset, _ = strconv.Atoi(m[1])
+	}
+	return issue
+}
+
+// offsetPattern finds the position reported by emv.ParseFields errors.
+// offsetPattern mencari posisi yang dilaporkan error emv.ParseFields.
+var offsetPattern = regexp.MustCompile(`at offset (\d+)`)
+
+// checkCRC adds an issue to r when the CRC of payload is missing or wrong.
+// checkCRC menambahkan issue ke r jika CRC payload tidak ada atau salah.
+func checkCRC(r *ValidationReport, payload string) {
+	err := VerifyCRC(payload)
+	if err == nil {
+		return
+	}
+	if len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != emv.CRCTag {
+		r.add(newIssue(IssueCRCMissing, "CRC field 6304 not found at the end", "field CRC 6304 tidak ditemukan di akhir", err).at("63", -1))
+		return
+	}
+	got, want := payload[len(payload)-4:], ChecksumCRC16(payload[:len(payload)-4])
+	r.add(newIssue(IssueCRCMismatch,
+		fmt.Sprintf("CRC is %s, want %s", got, want), fmt.Sprintf("CRC %s, seharusnya %s", got, want), err).at("63", len(payload)-8))
+}
+
+// checkMandatoryTags adds an issue with severity for every mandatory tag missing from payload.
+// checkMandatoryTags menambahkan issue dengan severity untuk setiap tag wajib yang tidak ada di payload.
+func checkMandatoryTags(r *ValidationReport, payload *QRISPayload, severity Severity) {
+	for _, m := range mandatoryTags {
+		if _, ok := payload.Field(m.tag); ok {
+			continue
+		}
+		issue := newIssue(IssueMissingTag(m.tag),
+			fmt.Sprintf("missing %s (tag %s)", m.name, m.tag), fmt.Sprintf("%s (tag %s) tidak ada", m.name, m.tag), nil).at(m.tag, -1)
+		issue.Severity = severity
+		r.add(issue)
+	}
+}
+
+// ValidateDetailed checks the config like Validate and reports every issue found. Issues of
+// BaseQrString are those of NormalizeQRISStringDetailed, with Field set to "BaseQrString".
+// ValidateDetailed memeriksa config seperti Validate dan melaporkan semua issue yang ditemukan.
+// Issue dari BaseQrString adalah issue NormalizeQRISStringDetailed, dengan Field "BaseQrString".
+func (c QRISConfig) ValidateDetailed() ValidationReport {
+	r := newReport()
+
+	required := []struct {
+		field string
+		value string
+		need  bool
+	}{
+		{"BaseQrString", c.BaseQrString, true},
+		{"AuthToken", c.AuthToken, !c.Sandbox && c.Provider == nil},
+		{"AuthUsername", c.AuthUsername, !c.Sandbox && c.Provider == nil},
+	}
+	missingErr := errors.New("baseQrString, authToken, and authUsername must be filled / baseQrString, authToken, dan authUsername harus diisi")
+	for _, f := range required {
+		if f.need && f.value == "" {
+			issue := newIssue(IssueMissingRequiredField, f.field+" must be filled", f.field+" harus diisi", missingErr)
+			issue.Field = f.field
+			r.add(issue)
+		}
+	}
+
+	if c.BaseQrString != "" {
+		normalized, base := NormalizeQRISStringDetailed(c.BaseQrString)
+		for _, i := range base.Issues {
+			i.Field = "BaseQrString"
+			i.err = fmt.Errorf("invalid baseQrString format / format baseQrString tidak valid: %w", i.err)
+			r.add(i)
+		}
+		if base.Valid && !c.AllowAnyCurrency {
+			if err := checkCurrency(normalized); err != nil {
+				payload, _ := ParseQRISString(normalized)
+				f, _ := payload.Field("53")
+				issue := configIssue(IssueUnsupportedCurrency, "BaseQrString",
+					fmt.Sprintf("base QRIS currency is %s, want %s (IDR)", f.Value, CurrencyIDR),
+					fmt.Sprintf("mata uang base QRIS %s, seharusnya %s (IDR)", f.Value, CurrencyIDR), err)
+				r.add(issue.at("53", f.Offset))
+			}
+		}
+	}
+
+	switch strings.ToLower(c.Gateway) {
+	case "", GatewayFTVPN, GatewayOkeconnect:
+	default:
+		r.add(configIssue(IssueUnknownGateway, "Gateway",
+			fmt.Sprintf("unknown gateway %q, want ftvpn or okeconnect", c.Gateway),
+			fmt.Sprintf("gateway %q tidak dikenal, seharusnya ftvpn atau okeconnect", c.Gateway), nil))
+	}
+
+	if c.GatewayURL != "" {
+		u, err := url.Parse(c.GatewayURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			r.add(configIssue(IssueInvalidGatewayURL, "GatewayURL",
+				fmt.Sprintf("invalid gatewayURL %q", c.GatewayURL), fmt.Sprintf("gatewayURL %q tidak valid", c.GatewayURL), nil))
+		}
+	}
+
+	if c.MatchWindow < 0 {
+		r.add(configIssue(IssueNegativeMatchWindow, "MatchWindow", "matchWindow must not be negative", "matchWindow tidak boleh negatif", nil))
+	}
+
+	if c.MinAmount < 0 || c.MaxAmount < 0 || (c.MaxAmount > 0 && c.MinAmount > c.MaxAmount) {
+		r.add(configIssue(IssueInvalidAmountRange, "MinAmount",
+			"minAmount and maxAmount must not be negative and minAmount must not exceed maxAmount",
+			"minAmount dan maxAmount tidak boleh negatif dan minAmount tidak boleh melebihi maxAmount", nil))
+	}
+
+	if c.UniqueSuffix < 0 {
+		r.add(configIssue(IssueNegativeUniqueSuffix, "UniqueSuffix", "uniqueSuffix must not be negative", "uniqueSuffix tidak boleh negatif", nil))
+	}
+
+	if c.RequestTimeout < 0 {
+		r.add(configIssue(IssueNegativeRequestTimeout, "RequestTimeout", "requestTimeout must not be negative", "requestTimeout tidak boleh negatif", nil))
+	}
+
+	if c.MaxRateLimitWait < 0 {
+		r.add(configIssue(IssueNegativeMaxRateLimit, "MaxRateLimitWait", "maxRateLimitWait must not be negative", "maxRateLimitWait tidak boleh negatif", nil))
+	}
+
+	if c.SweepInterval < 0 {
+		r.add(configIssue(IssueNegativeSweepInterval, "SweepInterval", "sweepInterval must not be negative", "sweepInterval tidak boleh negatif", nil))
+	}
+
+	for _, t := range c.QRISTypes {
+		switch strings.ToLower(t) {
+		case QRISTypeStatic, QRISTypeDynamic, QRISTypeAny:
+		default:
+			r.add(configIssue(IssueInvalidQRISType, "QRISTypes",
+				fmt.Sprintf("unknown QRIS type %q, want static, dynamic, or any", t),
+				fmt.Sprintf("tipe QRIS %q tidak dikenal, seharusnya static, dynamic, atau any", t), nil))
+		}
+	}
+
+	if c.ProxyURL != "" {
+		if _, err := transport.ParseProxyURL(c.ProxyURL); err != nil {
+			r.add(configIssue(IssueInvalidProxyURL, "ProxyURL",
+				fmt.Sprintf("invalid proxy URL %q", c.ProxyURL), fmt.Sprintf("URL proxy %q tidak valid", c.ProxyURL), err))
+		}
+	}
+
+	return r
+}
+
+func configIssue(code, field, en, id string, err error) Issue {
+	i := newIssue(code, en, id, err)
+	i.Field = field
+	return i
+}
@@ -0,0 +1,9 @@
+package qris
+
+// Version is the version of this module.
+// Version adalah versi modul ini.
+const Version = "1.0.5"
+
+// DefaultUserAgent is sent with every gateway request unless QRISConfig.UserAgent is set.
+// DefaultUserAgent dikirim pada setiap request gateway kecuali QRISConfig.UserAgent diatur.
+const DefaultUserAgent = "orderkuota-go/" + Version
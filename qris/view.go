@@ -0,0 +1,78 @@
+package qris
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Money is an amount of rupiah as exposed by APIView.
+// Money adalah nominal rupiah seperti yang ditampilkan APIView.
+type Money struct {
+	Value     int64  `json:"value"`     // Amount in rupiah / Nominal dalam rupiah
+	Currency  string `json:"currency"`  // Always "IDR" / Selalu "IDR"
+	Formatted string `json:"formatted"` // FormatIDR of Value / FormatIDR dari Value
+}
+
+// newMoney wraps an amount of rupiah.
+// newMoney membungkus nominal rupiah.
+func newMoney(amount int64) Money {
+	return Money{Value: amount, Currency: "IDR", Formatted: FormatIDR(amount)}
+}
+
+// PaymentStatusView is a PaymentStatus shaped for public API responses, free of gateway quirks.
+// PaymentStatusView adalah PaymentStatus yang dibentuk untuk response API publik, tanpa keanehan gateway.
+type PaymentStatusView struct {
+	Status    string    // PAID/UNPAID/EXPIRED/CANCELLED
+	Amount    Money     // Payment amount / Nominal pembayaran
+	Reference string    // Payment reference / Referensi pembayaran
+	Date      time.Time // Payment time, zero if not paid / Waktu pembayaran, nol jika belum dibayar
+	RawDate   string    // Date as sent by the gateway / Tanggal sesuai kiriman gateway
+	BrandName string    // Payer brand name / Nama brand pembayar
+	Issuer    Issuer    // Canonical issuer / Issuer kanonis
+	BuyerRef  string    // Buyer reference / Referensi pembeli
+
+	Metadata map[string]string // Metadata of the session, see WithMetadata / Metadata sesi, lihat WithMetadata
+}
+
+// APIView returns the status for public API responses: the date parsed into a time.Time and the
+// amount as a Money object. Dates of statuses not produced by this package are read in WIB.
+// APIView mengembalikan status untuk response API publik: tanggal di-parse menjadi time.Time dan
+// nominal sebagai objek Money. Tanggal dari status yang tidak dibuat paket ini dibaca dalam WIB.
+func (s *PaymentStatus) APIView() PaymentStatusView {
+	date := s.PaidAt
+	if date.IsZero() && s.Date != "" {
+		date = parseMutationDate(s.Date, WIB)
+	}
+	return PaymentStatusView{
+		Status:    s.Status,
+		Amount:    newMoney(s.Amount),
+		Reference: s.Reference,
+		Date:      date,
+		RawDate:   s.Date,
+		BrandName: s.BrandName,
+		Issuer:    s.Issuer,
+		BuyerRef:  s.BuyerRef,
+		Metadata:  s.Metadata,
+	}
+}
+
+// MarshalJSON encodes the view with snake_case keys and the date as RFC3339, or null if unknown.
+// MarshalJSON meng-encode view dengan key snake_case dan tanggal sebagai RFC3339, atau null jika tidak diketahui.
+func (v PaymentStatusView) MarshalJSON() ([]byte, error) {
+	var date *string
+	if !v.Date.IsZero() {
+		d := v.Date.Format(time.RFC3339)
+		date = &d
+	}
+	return json.Marshal(struct {
+		Status    string            `json:"status"`
+		Amount    Money             `json:"amount"`
+		Reference string            `json:"reference"`
+		Date      *string           `json:"date"`
+		RawDate   string            `json:"raw_date,omitempty"`
+		BrandName string            `json:"brand_name,omitempty"`
+		Issuer    Issuer            `json:"issuer,omitempty"`
+		BuyerRef  string            `json:"buyer_ref,omitempty"`
+		Metadata  map[string]string `json:"metadata,omitempty"`
+	}{v.Status, v.Amount, v.Reference, date, v.RawDate, v.BrandName, v.Issuer, v.BuyerRef, v.Metadata})
+}
@@ -0,0 +1,54 @@
+package qris_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+func TestAPIView(t *testing.T) {
+	srv := staticGateway(t, mutationFixture(t, "dynamic/mutations.json"))
+	q := newTestQRIS(t, newGateway(t), func(c *qris.QRISConfig) { c.GatewayURL = srv.URL })
+	paid, err := q.CheckPaymentStatusContext(testContext(t, 5*time.Second), "INV-1", 30000)
+	if err != nil || paid.Status != qris.StatusPaid {
+		t.Fatalf("CheckPaymentStatus = %+v, %v, want PAID", paid, err)
+	}
+	paidDate := paid.PaidAt.Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		status qris.PaymentStatus
+		want   string
+	}{
+		{
+			name:   "paid",
+			status: *paid,
+			want: `{"status":"PAID","amount":{"value":30000,"currency":"IDR","formatted":"Rp 30.000"},"reference":"REF1",` +
+				`"date":"` + paidDate + `","raw_date":"` + paid.Date + `","brand_name":"OVO","issuer":"OVO"}`,
+		},
+		{
+			name:   "unpaid",
+			status: qris.PaymentStatus{Status: qris.StatusUnpaid, Amount: 1500, Reference: "INV-1"},
+			want:   `{"status":"UNPAID","amount":{"value":1500,"currency":"IDR","formatted":"Rp 1.500"},"reference":"INV-1","date":null}`,
+		},
+		{
+			name:   "date without PaidAt read in WIB",
+			status: qris.PaymentStatus{Status: qris.StatusPaid, Amount: 25000, Reference: "REF2", Date: "2024-05-02 03:30:00", BuyerRef: "INV-7", Metadata: map[string]string{"order": "7"}},
+			want: `{"status":"PAID","amount":{"value":25000,"currency":"IDR","formatted":"Rp 25.000"},"reference":"REF2",` +
+				`"date":"2024-05-02T03:30:00+07:00","raw_date":"2024-05-02 03:30:00","buyer_ref":"INV-7","metadata":{"order":"7"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.status.APIView())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Fatalf("JSON = %s\nwant   %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package qris
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultPollInterval is the default delay between two payment status checks.
+// DefaultPollInterval adalah jeda default antara dua pengecekan status pembayaran.
+const DefaultPollInterval = 5 * time.Second
+
+// WaitOption customizes WaitForPayment.
+// WaitOption mengatur perilaku WaitForPayment.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	schedule       PollSchedule
+	requestTimeout time.Duration
+}
+
+// WithPollInterval sets the delay between two status checks.
+// WithPollInterval mengatur jeda antara dua pengecekan status.
+func WithPollInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		if d > 0 {
+			o.schedule = FixedSchedule(d)
+		}
+	}
+}
+
+// WithPollSchedule sets the schedule of status checks instead of QRISConfig.PollSchedule.
+// WithPollSchedule mengatur jadwal pengecekan status alih-alih QRISConfig.PollSchedule.
+func WithPollSchedule(schedule PollSchedule) WaitOption {
+	return func(o *waitOptions) {
+		if schedule != nil {
+			o.schedule = schedule
+		}
+	}
+}
+
+// WithRequestTimeout overrides QRISConfig.RequestTimeout for every status check of this wait.
+// WithRequestTimeout mengganti QRISConfig.RequestTimeout untuk setiap pengecekan status pada wait ini.
+func WithRequestTimeout(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		if d > 0 {
+			o.requestTimeout = d
+		}
+	}
+}
+
+// WaitForPayment polls the payment status until it is PAID or the context is done.
+// WaitForPayment melakukan polling status pembayaran sampai PAID atau context selesai.
+//
+// Failed checks are logged and retried on the next tick; once the instance is closed it returns
+// ErrClosed. Like CheckPaymentStatus, a reference of a known invoice decides the amount matched.
+// Pengecekan yang gagal dicatat di log dan diulang pada tick berikutnya; setelah instance ditutup
+// fungsi ini mengembalikan ErrClosed. Seperti CheckPaymentStatus, reference dari invoice yang dikenal
+// menentukan nominal yang dicocokkan.
+func (q *QRIS) WaitForPayment(ctx context.Context, reference string, amount int64, opts ...WaitOption) (*PaymentStatus, error) {
+	o := waitOptions{schedule: q.pollSchedule()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.requestTimeout > 0 {
+		ctx = withRequestTimeout(ctx, o.requestTimeout)
+	}
+
+	var paid *PaymentStatus
+	source := q.sharedCredits()
+	check := func(ctx context.Context) (*PaymentStatus, error) {
+		return q.checkPaymentStatus(ctx, reference, amount, source)
+	}
+	err := q.poll(ctx, o.schedule, check, func(status *PaymentStatus, err error) bool {
+		if err != nil {
+			logf(ctx, "Error checking payment status: %v", err)
+			return false
+		}
+		if status.Status == StatusPaid {
+			paid = status
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paid, nil
+}
+
+// poll runs check as often as schedule says and hands each result to onCheck
+// until onCheck returns true, ctx is done, or check fails with ErrClosed.
+// poll menjalankan check sesuai jadwal dan meneruskan hasilnya ke onCheck
+// sampai onCheck mengembalikan true, ctx selesai, atau check gagal dengan ErrClosed.
+func (q *QRIS) poll(ctx context.Context, schedule PollSchedule, check func(context.Context) (*PaymentStatus, error), onCheck func(*PaymentStatus, error) bool) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		checkedAt := time.Now()
+		status, err := check(ctx)
+		if onCheck(status, err) {
+			return nil
+		}
+		// A closed instance never recovers
+		if errors.Is(err, ErrClosed) {
+			return err
+		}
+
+		// Like a ticker, the delay counts from the start of the check
+		delay := schedule.NextDelay(attempt, checkedAt.Sub(start)) - time.Since(checkedAt)
+		if errors.Is(err, ErrGatewayMaintenance) && delay < MaintenanceBackoff {
+			delay = MaintenanceBackoff
+		}
+		if delay < 0 {
+			delay = 0
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
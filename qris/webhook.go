@@ -0,0 +1,383 @@
+package qris
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook defaults.
+// Default webhook.
+const (
+	DefaultWebhookAttempts = 5               // Attempts of a delivery before it is marked failed / Percobaan sebuah pengiriman sebelum ditandai gagal
+	DefaultWebhookBackoff  = 2 * time.Second // Delay after the first failed attempt, doubled after each one / Jeda setelah percobaan gagal pertama, digandakan setelah setiap percobaan
+)
+
+// Headers of webhook requests.
+// Header dari request webhook.
+const (
+	WebhookIDHeader        = "X-QRIS-Event-ID"  // Event ID, the same on every redelivery / ID event, sama pada setiap pengiriman ulang
+	WebhookSignatureHeader = "X-QRIS-Signature" // "sha256=" and the hex HMAC-SHA256 of the body / "sha256=" dan HMAC-SHA256 hex dari body
+)
+
+// ErrWebhookSignature is returned by VerifyWebhookSignature for a body that was not signed with the secret.
+// ErrWebhookSignature dikembalikan oleh VerifyWebhookSignature untuk body yang tidak ditandatangani dengan secret.
+var ErrWebhookSignature = errors.New("invalid webhook signature / tanda tangan webhook tidak valid")
+
+// WebhookConfig configures NewWebhook.
+// WebhookConfig mengatur NewWebhook.
+type WebhookConfig struct {
+	URL    string // Receiver of the events; empty to only redeliver stored deliveries / Penerima event; kosong untuk hanya mengirim ulang pengiriman yang tersimpan
+	Secret []byte // Key of the signature, required with URL / Kunci tanda tangan, wajib jika URL diisi
+
+	Events      []string      // Event types sent, default paid, expired, cancelled and duplicate_payment / Tipe event yang dikirim, default paid, expired, cancelled dan duplicate_payment
+	Store       DeliveryStore // Keeps every delivery for Redeliver, default NewMemoryDeliveryStore() / Menyimpan setiap pengiriman untuk Redeliver, default NewMemoryDeliveryStore()
+	Client      *http.Client  // HTTP client, default one with a DefaultRequestTimeout timeout / Client HTTP, default client dengan timeout DefaultRequestTimeout
+	MaxAttempts int           // Attempts per delivery, default DefaultWebhookAttempts / Percobaan per pengiriman, default DefaultWebhookAttempts
+	Backoff     time.Duration // First retry delay, default DefaultWebhookBackoff / Jeda percobaan ulang pertama, default DefaultWebhookBackoff
+	Tokens      *TokenCodec   // Adds a token of the payment that receivers verify without a lookup / Menambahkan token pembayaran yang diverifikasi penerima tanpa pencarian
+}
+
+// WebhookPayload is the JSON body of a webhook request.
+// WebhookPayload adalah body JSON dari request webhook.
+type WebhookPayload struct {
+	ID            string            `json:"id"`             // Event ID, also in WebhookIDHeader / ID event, juga di WebhookIDHeader
+	Type          string            `json:"type"`           // Event type, e.g. "paid" / Tipe event, misalnya "paid"
+	TransactionID string            `json:"transaction_id"` // Transaction ID of the session / ID transaksi dari sesi
+	At            time.Time         `json:"at"`             // Time of the event / Waktu event
+	Status        *PaymentStatus    `json:"status,omitempty"`
+	Duplicate     *DuplicatePayment `json:"duplicate,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	Token         string            `json:"token,omitempty"` // See WebhookConfig.Tokens / Lihat WebhookConfig.Tokens
+}
+
+// Webhook is a DurableSink posting session events to a URL, signed with HMAC-SHA256 over the whole
+// body, metadata included. Every delivery is saved to a DeliveryStore as its event is emitted, so
+// a full event queue never loses one; failed attempts are retried with a doubling backoff, and
+// deliveries that still fail can be replayed with Redeliver. Only a 2xx response marks a delivery
+// delivered.
+// Webhook adalah DurableSink yang mengirim event sesi ke sebuah URL, ditandatangani dengan
+// HMAC-SHA256 atas seluruh body, termasuk metadata. Setiap pengiriman disimpan di DeliveryStore saat
+// event-nya dikirim, sehingga antrean event yang penuh tidak pernah menghilangkannya; percobaan yang
+// gagal diulang dengan jeda yang digandakan, dan pengiriman yang tetap gagal dapat diulang dengan
+// Redeliver. Hanya response 2xx yang menandai pengiriman sebagai terkirim.
+type Webhook struct {
+	config WebhookConfig
+	client *http.Client
+	store  DeliveryStore
+	events map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewWebhook creates a Webhook; set it as QRISConfig.EventSink. Close it after the QRIS instance.
+// NewWebhook membuat Webhook; atur sebagai QRISConfig.EventSink. Tutup setelah instance QRIS.
+func NewWebhook(config WebhookConfig) (*Webhook, error) {
+	if config.URL != "" {
+		u, err := url.Parse(config.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL %q / URL webhook %q tidak valid", config.URL, config.URL)
+		}
+		if len(config.Secret) == 0 {
+			return nil, errors.New("webhook secret must be filled / secret webhook harus diisi")
+		}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultWebhookAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultWebhookBackoff
+	}
+	if len(config.Events) == 0 {
+		config.Events = []string{EventPaid, EventExpired, EventCancelled, EventDuplicatePayment}
+	}
+
+	w := &Webhook{
+		config:   config,
+		client:   config.Client,
+		store:    config.Store,
+		events:   make(map[string]bool, len(config.Events)),
+		inFlight: make(map[string]bool),
+	}
+	if w.client == nil {
+		w.client = &http.Client{Timeout: DefaultRequestTimeout}
+	}
+	if w.store == nil {
+		w.store = NewMemoryDeliveryStore()
+	}
+	for _, t := range config.Events {
+		w.events[t] = true
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	return w, nil
+}
+
+// RecordEvent implements DurableSink; it is WriteEvent, which returns once the delivery is saved.
+// RecordEvent mengimplementasikan DurableSink; sama dengan WriteEvent, yang kembali setelah
+// pengiriman disimpan.
+func (w *Webhook) RecordEvent(ev PaymentEvent) error {
+	return w.WriteEvent(ev)
+}
+
+// WriteEvent implements EventSink: it stores a delivery of ev, if its type is sent, and delivers
+// it in the background.
+// WriteEvent mengimplementasikan EventSink: menyimpan pengiriman ev, jika tipenya dikirim, dan
+// mengirimkannya di background.
+func (w *Webhook) WriteEvent(ev PaymentEvent) error {
+	if w.config.URL == "" {
+		return errors.New("webhook has no URL / webhook tidak memiliki URL")
+	}
+	if !w.events[ev.Type] {
+		return nil
+	}
+	if w.ctx.Err() != nil {
+		return errors.New("webhook is closed / webhook sudah ditutup")
+	}
+
+	id, err := newEventID()
+	if err != nil {
+		return err
+	}
+	payload := WebhookPayload{
+		ID:            id,
+		Type:          ev.Type,
+		TransactionID: ev.SessionID,
+		At:            ev.At,
+		Status:        ev.Status,
+		Duplicate:     ev.Duplicate,
+		Metadata:      ev.Metadata,
+	}
+	if w.config.Tokens != nil && ev.Status != nil {
+		token, err := w.config.Tokens.Encode(PaymentToken{TransactionID: ev.SessionID, Amount: ev.Status.Amount})
+		if err != nil {
+			return err
+		}
+		payload.Token = token
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook / gagal marshal webhook: %v", err)
+	}
+
+	now := time.Now()
+	d := Delivery{
+		ID:        id,
+		Type:      ev.Type,
+		URL:       w.config.URL,
+		Payload:   string(body),
+		Signature: SignWebhook(w.config.Secret, body),
+		Status:    DeliveryPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := w.store.Save(w.ctx, d); err != nil {
+		return fmt.Errorf("failed to save webhook delivery / gagal menyimpan pengiriman webhook: %w", err)
+	}
+
+	w.mu.Lock()
+	w.inFlight[d.ID] = true
+	w.mu.Unlock()
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.deliver(eventContext(ev), d)
+	}()
+	return nil
+}
+
+// deliver attempts d until it is delivered, MaxAttempts is reached or the webhook is closed. ctx
+// carries the tenant and actor of the event for the logs.
+// deliver mencoba d sampai terkirim, MaxAttempts tercapai atau webhook ditutup. ctx membawa tenant
+// dan actor dari event untuk log.
+func (w *Webhook) deliver(ctx context.Context, d Delivery) {
+	defer func() {
+		w.mu.Lock()
+		delete(w.inFlight, d.ID)
+		w.mu.Unlock()
+	}()
+
+	delay := w.config.Backoff
+	for {
+		err := w.attempt(withAudit(w.ctx, ctx), &d)
+		if err == nil {
+			return
+		}
+		logf(ctx, "Webhook %s attempt %d failed: %v", d.ID, d.Attempts, err)
+		if d.Attempts >= w.config.MaxAttempts {
+			w.markFailed(ctx, &d, d.LastError)
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			w.markFailed(ctx, &d, "webhook closed before delivery: "+d.LastError)
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// attempt posts d once and saves the outcome.
+// attempt mengirim d sekali dan menyimpan hasilnya.
+func (w *Webhook) attempt(ctx context.Context, d *Delivery) error {
+	err := w.post(ctx, *d)
+	d.Attempts++
+	d.UpdatedAt = time.Now()
+	if err != nil {
+		d.LastError = err.Error()
+	} else {
+		d.Status = DeliveryDelivered
+		d.LastError = ""
+	}
+	if serr := w.store.Save(withAudit(context.Background(), ctx), *d); serr != nil {
+		logf(ctx, "Error saving webhook delivery %s: %v", d.ID, serr)
+	}
+	return err
+}
+
+func (w *Webhook) markFailed(ctx context.Context, d *Delivery, reason string) {
+	d.Status = DeliveryFailed
+	d.LastError = reason
+	d.UpdatedAt = time.Now()
+	if err := w.store.Save(withAudit(context.Background(), ctx), *d); err != nil {
+		logf(ctx, "Error saving webhook delivery %s: %v", d.ID, err)
+	}
+}
+
+// post sends d as it was stored; any status but 2xx is an error.
+// post mengirim d seperti yang disimpan; status selain 2xx adalah error.
+func (w *Webhook) post(ctx context.Context, d Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, strings.NewReader(d.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request / gagal membuat request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", DefaultUserAgent)
+	req.Header.Set(WebhookIDHeader, d.ID)
+	req.Header.Set(WebhookSignatureHeader, d.Signature)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook / gagal mengirim webhook: %v", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook receiver answered %d / penerima webhook menjawab %d", resp.StatusCode, resp.StatusCode)
+	}
+	return nil
+}
+
+// ListFailedDeliveries returns the deliveries created since since that are not delivered and not
+// being attempted by this Webhook: those that used up their attempts, and those left pending by a
+// process that stopped. Oldest first.
+// ListFailedDeliveries mengembalikan pengiriman yang dibuat sejak since yang belum terkirim dan tidak
+// sedang dicoba oleh Webhook ini: yang sudah menghabiskan percobaannya, dan yang tertinggal pending
+// oleh proses yang berhenti. Yang terlama lebih dulu.
+func (w *Webhook) ListFailedDeliveries(ctx context.Context, since time.Time) ([]Delivery, error) {
+	all, err := w.store.List(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var failed []Delivery
+	for _, d := range all {
+		if d.Status != DeliveryDelivered && !w.inFlight[d.ID] {
+			failed = append(failed, d)
+		}
+	}
+	return failed, nil
+}
+
+// Redeliver posts the stored delivery id once more, with its original event ID, body and
+// signature so the receiver can drop it if it was already handled, and returns it updated. It
+// fails if the receiver does not answer 2xx; a delivered delivery is sent again too.
+// Redeliver mengirim pengiriman tersimpan id sekali lagi, dengan ID event, body dan tanda tangan
+// aslinya sehingga penerima dapat mengabaikannya jika sudah diproses, dan mengembalikannya yang sudah
+// diperbarui. Gagal jika penerima tidak menjawab 2xx; pengiriman yang sudah terkirim juga dikirim lagi.
+func (w *Webhook) Redeliver(ctx context.Context, id string) (Delivery, error) {
+	d, err := w.store.Get(ctx, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	w.mu.Lock()
+	if w.inFlight[id] {
+		w.mu.Unlock()
+		return d, fmt.Errorf("webhook delivery %s is being attempted / pengiriman webhook %s sedang dicoba", id, id)
+	}
+	w.inFlight[id] = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.inFlight, id)
+		w.mu.Unlock()
+	}()
+
+	if err := w.attempt(ctx, &d); err != nil {
+		if d.Status != DeliveryDelivered {
+			w.markFailed(ctx, &d, d.LastError)
+		}
+		return d, err
+	}
+	return d, nil
+}
+
+// Close stops the retries in progress, marking their deliveries failed so Redeliver can replay
+// them, and waits for them to stop.
+// Close menghentikan percobaan ulang yang sedang berjalan, menandai pengirimannya gagal agar dapat
+// diulang dengan Redeliver, dan menunggu semuanya berhenti.
+func (w *Webhook) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	return nil
+}
+
+// SignWebhook returns the WebhookSignatureHeader value of body under secret.
+// SignWebhook mengembalikan nilai WebhookSignatureHeader dari body dengan secret.
+func SignWebhook(secret, body []byte) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the WebhookSignatureHeader value of a received body, returning
+// ErrWebhookSignature if it does not match. Receivers should also skip event IDs they have seen.
+// VerifyWebhookSignature memeriksa nilai WebhookSignatureHeader dari body yang diterima, dan
+// mengembalikan ErrWebhookSignature jika tidak cocok. Penerima juga sebaiknya melewati ID event yang sudah pernah dilihat.
+func VerifyWebhookSignature(secret, body []byte, signature string) error {
+	if !hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(SignWebhook(secret, body))) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
+
+// newEventID returns a random event ID such as "evt_1f0c…".
+// newEventID mengembalikan ID event acak seperti "evt_1f0c…".
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate event ID / gagal generate ID event: %v", err)
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,463 @@
+package qris_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutoFTbot/OrderKuota-go/qris"
+)
+
+// webhookSecret signs the webhooks of the tests.
+var webhookSecret = []byte("whsec_test")
+
+// hookRequest is a webhook request received by a hookReceiver.
+type hookRequest struct {
+	id, signature string
+	body          []byte
+}
+
+// hookReceiver answers 500 to the first failFirst webhook requests and 204 after them.
+type hookReceiver struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	failFirst int
+	requests  []hookRequest
+}
+
+func newHookReceiver(t *testing.T, failFirst int) *hookReceiver {
+	t.Helper()
+	h := &hookReceiver{failFirst: failFirst}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		h.mu.Lock()
+		h.requests = append(h.requests, hookRequest{r.Header.Get(qris.WebhookIDHeader), r.Header.Get(qris.WebhookSignatureHeader), body})
+		fail := len(h.requests) <= h.failFirst
+		h.mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *hookReceiver) setFailFirst(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failFirst = n
+}
+
+func (h *hookReceiver) received() []hookRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]hookRequest(nil), h.requests...)
+}
+
+// newTestWebhook returns a webhook to url retrying quickly, closed when the test ends.
+func newTestWebhook(t *testing.T, url string, configure ...func(*qris.WebhookConfig)) (*qris.Webhook, qris.DeliveryStore) {
+	t.Helper()
+	config := qris.WebhookConfig{
+		URL:         url,
+		Secret:      webhookSecret,
+		Store:       qris.NewMemoryDeliveryStore(),
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+	for _, fn := range configure {
+		fn(&config)
+	}
+	wh, err := qris.NewWebhook(config)
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	t.Cleanup(func() { wh.Close() })
+	return wh, config.Store
+}
+
+// waitDelivery waits until the only delivery of store is no longer pending.
+func waitDelivery(t *testing.T, store qris.DeliveryStore) qris.Delivery {
+	t.Helper()
+	ctx := testContext(t, 5*time.Second)
+	for {
+		list, err := store.List(ctx, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) > 1 {
+			t.Fatalf("%d deliveries, want 1", len(list))
+		}
+		if len(list) == 1 && list[0].Status != qris.DeliveryPending {
+			return list[0]
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("delivery still pending: %+v", list)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func paidEvent() qris.PaymentEvent {
+	return qris.PaymentEvent{
+		SessionID: "INV-1",
+		Type:      qris.EventPaid,
+		At:        time.Date(2024, 1, 15, 10, 0, 0, 0, qris.WIB),
+		Status:    &qris.PaymentStatus{Status: qris.StatusPaid, Amount: 25123, Reference: "REF1"},
+		Metadata:  map[string]string{"order": "1"},
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// Well-known HMAC-SHA256 of the pangram with the key "key"
+	const want = "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	body := []byte("The quick brown fox jumps over the lazy dog")
+	if got := qris.SignWebhook([]byte("key"), body); got != want {
+		t.Fatalf("SignWebhook = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", secret: "key", body: string(body), signature: want},
+		{name: "surrounding spaces", secret: "key", body: string(body), signature: " " + want + "\n"},
+		{name: "wrong secret", secret: "other", body: string(body), signature: want, wantErr: true},
+		{name: "body changed", secret: "key", body: string(body) + ".", signature: want, wantErr: true},
+		{name: "without prefix", secret: "key", body: string(body), signature: strings.TrimPrefix(want, "sha256="), wantErr: true},
+		{name: "uppercase hex", secret: "key", body: string(body), signature: "sha256=" + strings.ToUpper(strings.TrimPrefix(want, "sha256=")), wantErr: true},
+		{name: "empty", secret: "key", body: string(body), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := qris.VerifyWebhookSignature([]byte(tt.secret), []byte(tt.body), tt.signature)
+			if tt.wantErr != errors.Is(err, qris.ErrWebhookSignature) || (!tt.wantErr && err != nil) {
+				t.Fatalf("VerifyWebhookSignature = %v, want error = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name      string
+		failFirst int
+		status    string
+		attempts  int
+	}{
+		{name: "first attempt", failFirst: 0, status: qris.DeliveryDelivered, attempts: 1},
+		{name: "after retries", failFirst: 2, status: qris.DeliveryDelivered, attempts: 3},
+		{name: "attempts used up", failFirst: 3, status: qris.DeliveryFailed, attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newHookReceiver(t, tt.failFirst)
+			wh, store := newTestWebhook(t, receiver.URL)
+
+			if err := wh.WriteEvent(paidEvent()); err != nil {
+				t.Fatalf("WriteEvent: %v", err)
+			}
+			d := waitDelivery(t, store)
+			if d.Status != tt.status || d.Attempts != tt.attempts {
+				t.Fatalf("delivery %s after %d attempts, want %s after %d (%s)", d.Status, d.Attempts, tt.status, tt.attempts, d.LastError)
+			}
+			if tt.status == qris.DeliveryFailed && !strings.Contains(d.LastError, "500") {
+				t.Fatalf("LastError = %q, want the receiver status", d.LastError)
+			}
+
+			// Every attempt carries the same event ID, body and signature
+			requests := receiver.received()
+			if len(requests) != tt.attempts {
+				t.Fatalf("%d requests, want %d", len(requests), tt.attempts)
+			}
+			for i, req := range requests {
+				if req.id != d.ID || string(req.body) != d.Payload || req.signature != d.Signature {
+					t.Fatalf("request %d = %+v, want the stored delivery %+v", i, req, d)
+				}
+				if err := qris.VerifyWebhookSignature(webhookSecret, req.body, req.signature); err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+			}
+
+			var payload qris.WebhookPayload
+			if err := json.Unmarshal(requests[0].body, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.ID != d.ID || payload.Type != qris.EventPaid || payload.TransactionID != "INV-1" ||
+				payload.Status.Amount != 25123 || payload.Metadata["order"] != "1" {
+				t.Fatalf("payload = %+v", payload)
+			}
+
+			failed, err := wh.ListFailedDeliveries(context.Background(), time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.status == qris.DeliveryFailed; (len(failed) == 1) != want {
+				t.Fatalf("ListFailedDeliveries = %+v, want the delivery listed = %v", failed, want)
+			}
+		})
+	}
+}
+
+func TestWebhookSignatureCoversMetadata(t *testing.T) {
+	receiver := newHookReceiver(t, 0)
+	wh, store := newTestWebhook(t, receiver.URL)
+	if err := wh.WriteEvent(paidEvent()); err != nil {
+		t.Fatal(err)
+	}
+	waitDelivery(t, store)
+	req := receiver.received()[0]
+
+	tampered := strings.Replace(string(req.body), `"order":"1"`, `"order":"2"`, 1)
+	if tampered == string(req.body) {
+		t.Fatalf("body %s lacks the metadata", req.body)
+	}
+	if err := qris.VerifyWebhookSignature(webhookSecret, []byte(tampered), req.signature); !errors.Is(err, qris.ErrWebhookSignature) {
+		t.Fatalf("tampered metadata verified: %v", err)
+	}
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+	receiver := newHookReceiver(t, 3)
+	wh, store := newTestWebhook(t, receiver.URL)
+	if err := wh.WriteEvent(paidEvent()); err != nil {
+		t.Fatal(err)
+	}
+	failed := waitDelivery(t, store)
+	ctx := testContext(t, 5*time.Second)
+
+	// Still failing: the delivery stays failed with one more attempt
+	receiver.setFailFirst(4)
+	d, err := wh.Redeliver(ctx, failed.ID)
+	if err == nil || d.Status != qris.DeliveryFailed || d.Attempts != 4 {
+		t.Fatalf("Redeliver = %s after %d attempts, %v, want failed after 4", d.Status, d.Attempts, err)
+	}
+
+	receiver.setFailFirst(0)
+	d, err = wh.Redeliver(ctx, failed.ID)
+	if err != nil || d.Status != qris.DeliveryDelivered || d.Attempts != 5 || d.LastError != "" {
+		t.Fatalf("Redeliver = %+v, %v, want delivered after 5 attempts", d, err)
+	}
+	if stored, _ := store.Get(ctx, failed.ID); stored.Status != qris.DeliveryDelivered {
+		t.Fatalf("stored delivery %s, want delivered", stored.Status)
+	}
+	if list, _ := wh.ListFailedDeliveries(ctx, time.Time{}); len(list) != 0 {
+		t.Fatalf("ListFailedDeliveries = %+v after redelivery, want none", list)
+	}
+
+	// The receiver can drop the replay: same event ID, body and signature as the first attempt
+	requests := receiver.received()
+	first, last := requests[0], requests[len(requests)-1]
+	if len(requests) != 5 || last.id != first.id || string(last.body) != string(first.body) || last.signature != first.signature {
+		t.Fatalf("replay %+v differs from the first attempt %+v", last, first)
+	}
+
+	// A delivered delivery is sent again on request
+	if d, err := wh.Redeliver(ctx, failed.ID); err != nil || d.Attempts != 6 {
+		t.Fatalf("Redeliver of a delivered delivery = %+v, %v", d, err)
+	}
+	if _, err := wh.Redeliver(ctx, "evt_unknown"); !errors.Is(err, qris.ErrNotFound) {
+		t.Fatalf("Redeliver of an unknown ID = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWebhookRedeliverFromStoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.json")
+	store, err := qris.NewJSONDeliveryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver := newHookReceiver(t, 3)
+	wh, _ := newTestWebhook(t, receiver.URL, func(c *qris.WebhookConfig) { c.Store = store })
+	if err := wh.WriteEvent(paidEvent()); err != nil {
+		t.Fatal(err)
+	}
+	failed := waitDelivery(t, store)
+	wh.Close()
+
+	// Another process replays the failed delivery without a URL or secret of its own
+	reopened, err := qris.NewJSONDeliveryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := qris.NewWebhook(qris.WebhookConfig{Store: reopened, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewWebhook without URL: %v", err)
+	}
+	defer replayer.Close()
+	ctx := testContext(t, 5*time.Second)
+	list, err := replayer.ListFailedDeliveries(ctx, time.Time{})
+	if err != nil || len(list) != 1 || list[0].ID != failed.ID {
+		t.Fatalf("ListFailedDeliveries = %+v, %v, want %s", list, err, failed.ID)
+	}
+	receiver.setFailFirst(0)
+	if d, err := replayer.Redeliver(ctx, failed.ID); err != nil || d.Status != qris.DeliveryDelivered {
+		t.Fatalf("Redeliver = %+v, %v", d, err)
+	}
+	last := receiver.received()[3]
+	if last.id != failed.ID || last.signature != failed.Signature {
+		t.Fatalf("replay %+v, want the stored ID and signature", last)
+	}
+	if err := replayer.WriteEvent(paidEvent()); err == nil {
+		t.Fatal("WriteEvent without URL succeeded")
+	}
+}
+
+func TestWebhookCloseDuringBackoff(t *testing.T) {
+	receiver := newHookReceiver(t, 100)
+	wh, store := newTestWebhook(t, receiver.URL, func(c *qris.WebhookConfig) { c.Backoff = time.Hour })
+	if err := wh.WriteEvent(paidEvent()); err != nil {
+		t.Fatal(err)
+	}
+	for len(receiver.received()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	wh.Close()
+
+	list, err := store.List(context.Background(), time.Time{})
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	if d := list[0]; d.Status != qris.DeliveryFailed || d.Attempts != 1 || !strings.HasPrefix(d.LastError, "webhook closed") {
+		t.Fatalf("delivery %+v, want failed after 1 attempt by Close", d)
+	}
+	if err := wh.WriteEvent(paidEvent()); err == nil {
+		t.Fatal("WriteEvent after Close succeeded")
+	}
+}
+
+func TestWebhookEventFilter(t *testing.T) {
+	receiver := newHookReceiver(t, 0)
+	wh, store := newTestWebhook(t, receiver.URL, func(c *qris.WebhookConfig) { c.Events = []string{qris.EventExpired} })
+
+	for _, typ := range []string{qris.EventCreated, qris.EventChecked, qris.EventPaid} {
+		ev := paidEvent()
+		ev.Type = typ
+		if err := wh.WriteEvent(ev); err != nil {
+			t.Fatalf("WriteEvent %s: %v", typ, err)
+		}
+	}
+	if list, _ := store.List(context.Background(), time.Time{}); len(list) != 0 {
+		t.Fatalf("%d deliveries of filtered events, want none", len(list))
+	}
+
+	ev := paidEvent()
+	ev.Type = qris.EventExpired
+	if err := wh.WriteEvent(ev); err != nil {
+		t.Fatal(err)
+	}
+	if d := waitDelivery(t, store); d.Type != qris.EventExpired {
+		t.Fatalf("delivery of %s, want expired", d.Type)
+	}
+}
+
+func TestNewWebhookInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config qris.WebhookConfig
+	}{
+		{"relative URL", qris.WebhookConfig{URL: "/hooks", Secret: webhookSecret}},
+		{"other scheme", qris.WebhookConfig{URL: "ftp://example.com/hooks", Secret: webhookSecret}},
+		{"without host", qris.WebhookConfig{URL: "https://", Secret: webhookSecret}},
+		{"without secret", qris.WebhookConfig{URL: "https://example.com/hooks"}},
+	}
+	for _, tt := range tests {
+		if _, err := qris.NewWebhook(tt.config); err == nil {
+			t.Errorf("%s: NewWebhook succeeded", tt.name)
+		}
+	}
+}
+
+func TestWebhookEventSink(t *testing.T) {
+	receiver := newHookReceiver(t, 0)
+	wh, store := newTestWebhook(t, receiver.URL)
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.EventSink = wh })
+
+	ctx := testContext(t, 5*time.Second)
+	s, err := q.CreatePayment(ctx, 25000, qris.WithTransactionID("INV-7"), qris.WithMetadata(map[string]string{"order": "7"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.AddMutation(s.Amount(), time.Now())
+	if status, err := s.Wait(ctx); err != nil || status.Status != qris.StatusPaid {
+		t.Fatalf("Wait = %v, %v", status, err)
+	}
+
+	d := waitDelivery(t, store)
+	var payload qris.WebhookPayload
+	if err := json.Unmarshal([]byte(d.Payload), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != qris.DeliveryDelivered || payload.Type != qris.EventPaid || payload.TransactionID != "INV-7" ||
+		payload.Status.Amount != s.Amount() || payload.Metadata["order"] != "7" {
+		t.Fatalf("delivery %s of %+v, want the paid event of INV-7", d.Status, payload)
+	}
+}
+
+// slowDeliveryStore is a MemoryDeliveryStore taking a while to save, slower than sessions emit events.
+type slowDeliveryStore struct {
+	*qris.MemoryDeliveryStore
+}
+
+func (s slowDeliveryStore) Save(ctx context.Context, d qris.Delivery) error {
+	time.Sleep(2 * time.Millisecond)
+	return s.MemoryDeliveryStore.Save(ctx, d)
+}
+
+func TestWebhookEventSinkQueueFull(t *testing.T) {
+	receiver := newHookReceiver(t, 0)
+	wh, store := newTestWebhook(t, receiver.URL, func(c *qris.WebhookConfig) {
+		c.Store = slowDeliveryStore{qris.NewMemoryDeliveryStore()}
+		c.Events = []string{qris.EventChecked, qris.EventPaid}
+	})
+	gw := newGateway(t)
+	q := newTestQRIS(t, gw, func(c *qris.QRISConfig) { c.EventSink = wh })
+
+	ctx := testContext(t, 30*time.Second)
+	s, err := q.CreatePayment(ctx, 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// More checks than the event queue holds, each saved slower than the next one is emitted
+	checks := qris.DefaultEventBuffer + 50
+	for i := 0; i < checks; i++ {
+		if _, err := s.Status(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gw.AddMutation(s.Amount(), time.Now())
+	if status, err := s.Status(ctx); err != nil || status.Status != qris.StatusPaid {
+		t.Fatalf("Status = %v, %v", status, err)
+	}
+
+	// Every delivery was saved when its event was emitted, the paid one included
+	list, err := store.List(ctx, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]int{}
+	for _, d := range list {
+		types[d.Type]++
+	}
+	if types[qris.EventPaid] != 1 || types[qris.EventChecked] < checks {
+		t.Fatalf("deliveries %v, want 1 paid and at least %d checked", types, checks)
+	}
+	if n := q.DroppedEvents(); n != 0 {
+		t.Fatalf("%d events dropped, want none", n)
+	}
+}